@@ -878,6 +878,16 @@ func deepCopy_api_BuildOutput(in buildapi.BuildOutput, out *buildapi.BuildOutput
 	} else {
 		out.PushSecret = nil
 	}
+	if in.ImageLabels != nil {
+		out.ImageLabels = make([]buildapi.ImageLabel, len(in.ImageLabels))
+		for i := range in.ImageLabels {
+			if err := deepCopy_api_ImageLabel(in.ImageLabels[i], &out.ImageLabels[i], c); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ImageLabels = nil
+	}
 	return nil
 }
 
@@ -1122,6 +1132,14 @@ func deepCopy_api_BuildStrategy(in buildapi.BuildStrategy, out *buildapi.BuildSt
 	} else {
 		out.CustomStrategy = nil
 	}
+	if in.JenkinsPipelineStrategy != nil {
+		out.JenkinsPipelineStrategy = new(buildapi.JenkinsPipelineBuildStrategy)
+		if err := deepCopy_api_JenkinsPipelineBuildStrategy(*in.JenkinsPipelineStrategy, out.JenkinsPipelineStrategy, c); err != nil {
+			return err
+		}
+	} else {
+		out.JenkinsPipelineStrategy = nil
+	}
 	return nil
 }
 
@@ -1278,6 +1296,12 @@ func deepCopy_api_ImageChangeTrigger(in buildapi.ImageChangeTrigger, out *builda
 	return nil
 }
 
+func deepCopy_api_ImageLabel(in buildapi.ImageLabel, out *buildapi.ImageLabel, c *conversion.Cloner) error {
+	out.Name = in.Name
+	out.Value = in.Value
+	return nil
+}
+
 func deepCopy_api_ImageSource(in buildapi.ImageSource, out *buildapi.ImageSource, c *conversion.Cloner) error {
 	if newVal, err := c.DeepCopy(in.From); err != nil {
 		return err
@@ -1312,6 +1336,12 @@ func deepCopy_api_ImageSourcePath(in buildapi.ImageSourcePath, out *buildapi.Ima
 	return nil
 }
 
+func deepCopy_api_JenkinsPipelineBuildStrategy(in buildapi.JenkinsPipelineBuildStrategy, out *buildapi.JenkinsPipelineBuildStrategy, c *conversion.Cloner) error {
+	out.JenkinsfilePath = in.JenkinsfilePath
+	out.Jenkinsfile = in.Jenkinsfile
+	return nil
+}
+
 func deepCopy_api_SecretBuildSource(in buildapi.SecretBuildSource, out *buildapi.SecretBuildSource, c *conversion.Cloner) error {
 	if newVal, err := c.DeepCopy(in.Secret); err != nil {
 		return err
@@ -1385,6 +1415,15 @@ func deepCopy_api_SourceRevision(in buildapi.SourceRevision, out *buildapi.Sourc
 
 func deepCopy_api_WebHookTrigger(in buildapi.WebHookTrigger, out *buildapi.WebHookTrigger, c *conversion.Cloner) error {
 	out.Secret = in.Secret
+	if in.SecretReference != nil {
+		if newVal, err := c.DeepCopy(in.SecretReference); err != nil {
+			return err
+		} else {
+			out.SecretReference = newVal.(*pkgapi.LocalObjectReference)
+		}
+	} else {
+		out.SecretReference = nil
+	}
 	return nil
 }
 
@@ -2002,6 +2041,7 @@ func deepCopy_api_DockerImage(in imageapi.DockerImage, out *imageapi.DockerImage
 		out.Config = nil
 	}
 	out.Architecture = in.Architecture
+	out.OS = in.OS
 	out.Size = in.Size
 	return nil
 }
@@ -2073,6 +2113,14 @@ func deepCopy_api_ImageImportStatus(in imageapi.ImageImportStatus, out *imageapi
 	} else {
 		out.Image = nil
 	}
+	if in.ImportSource != nil {
+		out.ImportSource = new(imageapi.TagImportSource)
+		if err := deepCopy_api_TagImportSource(*in.ImportSource, out.ImportSource, c); err != nil {
+			return err
+		}
+	} else {
+		out.ImportSource = nil
+	}
 	return nil
 }
 
@@ -2390,6 +2438,7 @@ func deepCopy_api_RepositoryImportStatus(in imageapi.RepositoryImportStatus, out
 	} else {
 		out.AdditionalTags = nil
 	}
+	out.TagsTruncated = in.TagsTruncated
 	return nil
 }
 
@@ -2402,6 +2451,14 @@ func deepCopy_api_TagEvent(in imageapi.TagEvent, out *imageapi.TagEvent, c *conv
 	out.DockerImageReference = in.DockerImageReference
 	out.Image = in.Image
 	out.Generation = in.Generation
+	if in.ImportSource != nil {
+		out.ImportSource = new(imageapi.TagImportSource)
+		if err := deepCopy_api_TagImportSource(*in.ImportSource, out.ImportSource, c); err != nil {
+			return err
+		}
+	} else {
+		out.ImportSource = nil
+	}
 	return nil
 }
 
@@ -2446,6 +2503,18 @@ func deepCopy_api_TagEventList(in imageapi.TagEventList, out *imageapi.TagEventL
 func deepCopy_api_TagImportPolicy(in imageapi.TagImportPolicy, out *imageapi.TagImportPolicy, c *conversion.Cloner) error {
 	out.Insecure = in.Insecure
 	out.Scheduled = in.Scheduled
+	out.Platform = in.Platform
+	return nil
+}
+
+func deepCopy_api_TagImportSource(in imageapi.TagImportSource, out *imageapi.TagImportSource, c *conversion.Cloner) error {
+	out.Registry = in.Registry
+	out.Secret = in.Secret
+	if newVal, err := c.DeepCopy(in.Retrieved); err != nil {
+		return err
+	} else {
+		out.Retrieved = newVal.(unversioned.Time)
+	}
 	return nil
 }
 
@@ -3328,8 +3397,10 @@ func init() {
 		deepCopy_api_GitBuildSource,
 		deepCopy_api_GitSourceRevision,
 		deepCopy_api_ImageChangeTrigger,
+		deepCopy_api_ImageLabel,
 		deepCopy_api_ImageSource,
 		deepCopy_api_ImageSourcePath,
+		deepCopy_api_JenkinsPipelineBuildStrategy,
 		deepCopy_api_SecretBuildSource,
 		deepCopy_api_SecretSpec,
 		deepCopy_api_SourceBuildStrategy,
@@ -3380,6 +3451,7 @@ func init() {
 		deepCopy_api_TagEventCondition,
 		deepCopy_api_TagEventList,
 		deepCopy_api_TagImportPolicy,
+		deepCopy_api_TagImportSource,
 		deepCopy_api_TagReference,
 		deepCopy_api_OAuthAccessToken,
 		deepCopy_api_OAuthAccessTokenList,
@@ -1169,6 +1169,16 @@ func autoConvert_api_BuildOutput_To_v1_BuildOutput(in *buildapi.BuildOutput, out
 	} else {
 		out.PushSecret = nil
 	}
+	if in.ImageLabels != nil {
+		out.ImageLabels = make([]v1.ImageLabel, len(in.ImageLabels))
+		for i := range in.ImageLabels {
+			if err := Convert_api_ImageLabel_To_v1_ImageLabel(&in.ImageLabels[i], &out.ImageLabels[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ImageLabels = nil
+	}
 	return nil
 }
 
@@ -1439,6 +1449,15 @@ func autoConvert_api_BuildStrategy_To_v1_BuildStrategy(in *buildapi.BuildStrateg
 	} else {
 		out.CustomStrategy = nil
 	}
+	// unable to generate simple pointer conversion for api.JenkinsPipelineBuildStrategy -> v1.JenkinsPipelineBuildStrategy
+	if in.JenkinsPipelineStrategy != nil {
+		out.JenkinsPipelineStrategy = new(v1.JenkinsPipelineBuildStrategy)
+		if err := Convert_api_JenkinsPipelineBuildStrategy_To_v1_JenkinsPipelineBuildStrategy(in.JenkinsPipelineStrategy, out.JenkinsPipelineStrategy, s); err != nil {
+			return err
+		}
+	} else {
+		out.JenkinsPipelineStrategy = nil
+	}
 	return nil
 }
 
@@ -1622,6 +1641,19 @@ func Convert_api_ImageChangeTrigger_To_v1_ImageChangeTrigger(in *buildapi.ImageC
 	return autoConvert_api_ImageChangeTrigger_To_v1_ImageChangeTrigger(in, out, s)
 }
 
+func autoConvert_api_ImageLabel_To_v1_ImageLabel(in *buildapi.ImageLabel, out *v1.ImageLabel, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*buildapi.ImageLabel))(in)
+	}
+	out.Name = in.Name
+	out.Value = in.Value
+	return nil
+}
+
+func Convert_api_ImageLabel_To_v1_ImageLabel(in *buildapi.ImageLabel, out *v1.ImageLabel, s conversion.Scope) error {
+	return autoConvert_api_ImageLabel_To_v1_ImageLabel(in, out, s)
+}
+
 func autoConvert_api_ImageSource_To_v1_ImageSource(in *buildapi.ImageSource, out *v1.ImageSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*buildapi.ImageSource))(in)
@@ -1668,6 +1700,19 @@ func Convert_api_ImageSourcePath_To_v1_ImageSourcePath(in *buildapi.ImageSourceP
 	return autoConvert_api_ImageSourcePath_To_v1_ImageSourcePath(in, out, s)
 }
 
+func autoConvert_api_JenkinsPipelineBuildStrategy_To_v1_JenkinsPipelineBuildStrategy(in *buildapi.JenkinsPipelineBuildStrategy, out *v1.JenkinsPipelineBuildStrategy, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*buildapi.JenkinsPipelineBuildStrategy))(in)
+	}
+	out.JenkinsfilePath = in.JenkinsfilePath
+	out.Jenkinsfile = in.Jenkinsfile
+	return nil
+}
+
+func Convert_api_JenkinsPipelineBuildStrategy_To_v1_JenkinsPipelineBuildStrategy(in *buildapi.JenkinsPipelineBuildStrategy, out *v1.JenkinsPipelineBuildStrategy, s conversion.Scope) error {
+	return autoConvert_api_JenkinsPipelineBuildStrategy_To_v1_JenkinsPipelineBuildStrategy(in, out, s)
+}
+
 func autoConvert_api_SecretBuildSource_To_v1_SecretBuildSource(in *buildapi.SecretBuildSource, out *v1.SecretBuildSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*buildapi.SecretBuildSource))(in)
@@ -1764,6 +1809,14 @@ func autoConvert_api_WebHookTrigger_To_v1_WebHookTrigger(in *buildapi.WebHookTri
 		defaulting.(func(*buildapi.WebHookTrigger))(in)
 	}
 	out.Secret = in.Secret
+	if in.SecretReference != nil {
+		out.SecretReference = new(apiv1.LocalObjectReference)
+		if err := Convert_api_LocalObjectReference_To_v1_LocalObjectReference(in.SecretReference, out.SecretReference, s); err != nil {
+			return err
+		}
+	} else {
+		out.SecretReference = nil
+	}
 	return nil
 }
 
@@ -2006,6 +2059,16 @@ func autoConvert_v1_BuildOutput_To_api_BuildOutput(in *v1.BuildOutput, out *buil
 	} else {
 		out.PushSecret = nil
 	}
+	if in.ImageLabels != nil {
+		out.ImageLabels = make([]buildapi.ImageLabel, len(in.ImageLabels))
+		for i := range in.ImageLabels {
+			if err := Convert_v1_ImageLabel_To_api_ImageLabel(&in.ImageLabels[i], &out.ImageLabels[i], s); err != nil {
+				return err
+			}
+		}
+	} else {
+		out.ImageLabels = nil
+	}
 	return nil
 }
 
@@ -2278,6 +2341,15 @@ func autoConvert_v1_BuildStrategy_To_api_BuildStrategy(in *v1.BuildStrategy, out
 	} else {
 		out.CustomStrategy = nil
 	}
+	// unable to generate simple pointer conversion for v1.JenkinsPipelineBuildStrategy -> api.JenkinsPipelineBuildStrategy
+	if in.JenkinsPipelineStrategy != nil {
+		out.JenkinsPipelineStrategy = new(buildapi.JenkinsPipelineBuildStrategy)
+		if err := Convert_v1_JenkinsPipelineBuildStrategy_To_api_JenkinsPipelineBuildStrategy(in.JenkinsPipelineStrategy, out.JenkinsPipelineStrategy, s); err != nil {
+			return err
+		}
+	} else {
+		out.JenkinsPipelineStrategy = nil
+	}
 	return nil
 }
 
@@ -2461,6 +2533,19 @@ func Convert_v1_ImageChangeTrigger_To_api_ImageChangeTrigger(in *v1.ImageChangeT
 	return autoConvert_v1_ImageChangeTrigger_To_api_ImageChangeTrigger(in, out, s)
 }
 
+func autoConvert_v1_ImageLabel_To_api_ImageLabel(in *v1.ImageLabel, out *buildapi.ImageLabel, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*v1.ImageLabel))(in)
+	}
+	out.Name = in.Name
+	out.Value = in.Value
+	return nil
+}
+
+func Convert_v1_ImageLabel_To_api_ImageLabel(in *v1.ImageLabel, out *buildapi.ImageLabel, s conversion.Scope) error {
+	return autoConvert_v1_ImageLabel_To_api_ImageLabel(in, out, s)
+}
+
 func autoConvert_v1_ImageSource_To_api_ImageSource(in *v1.ImageSource, out *buildapi.ImageSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*v1.ImageSource))(in)
@@ -2507,6 +2592,19 @@ func Convert_v1_ImageSourcePath_To_api_ImageSourcePath(in *v1.ImageSourcePath, o
 	return autoConvert_v1_ImageSourcePath_To_api_ImageSourcePath(in, out, s)
 }
 
+func autoConvert_v1_JenkinsPipelineBuildStrategy_To_api_JenkinsPipelineBuildStrategy(in *v1.JenkinsPipelineBuildStrategy, out *buildapi.JenkinsPipelineBuildStrategy, s conversion.Scope) error {
+	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
+		defaulting.(func(*v1.JenkinsPipelineBuildStrategy))(in)
+	}
+	out.JenkinsfilePath = in.JenkinsfilePath
+	out.Jenkinsfile = in.Jenkinsfile
+	return nil
+}
+
+func Convert_v1_JenkinsPipelineBuildStrategy_To_api_JenkinsPipelineBuildStrategy(in *v1.JenkinsPipelineBuildStrategy, out *buildapi.JenkinsPipelineBuildStrategy, s conversion.Scope) error {
+	return autoConvert_v1_JenkinsPipelineBuildStrategy_To_api_JenkinsPipelineBuildStrategy(in, out, s)
+}
+
 func autoConvert_v1_SecretBuildSource_To_api_SecretBuildSource(in *v1.SecretBuildSource, out *buildapi.SecretBuildSource, s conversion.Scope) error {
 	if defaulting, found := s.DefaultingInterface(reflect.TypeOf(*in)); found {
 		defaulting.(func(*v1.SecretBuildSource))(in)
@@ -2604,6 +2702,14 @@ func autoConvert_v1_WebHookTrigger_To_api_WebHookTrigger(in *v1.WebHookTrigger,
 		defaulting.(func(*v1.WebHookTrigger))(in)
 	}
 	out.Secret = in.Secret
+	if in.SecretReference != nil {
+		out.SecretReference = new(api.LocalObjectReference)
+		if err := Convert_v1_LocalObjectReference_To_api_LocalObjectReference(in.SecretReference, out.SecretReference, s); err != nil {
+			return err
+		}
+	} else {
+		out.SecretReference = nil
+	}
 	return nil
 }
 
@@ -3810,6 +3916,14 @@ func autoConvert_api_ImageImportStatus_To_v1_ImageImportStatus(in *imageapi.Imag
 	} else {
 		out.Image = nil
 	}
+	// unable to generate simple pointer conversion for api.TagImportSource -> v1.TagImportSource
+	if in.ImportSource != nil {
+		if err := s.Convert(&in.ImportSource, &out.ImportSource, 0); err != nil {
+			return err
+		}
+	} else {
+		out.ImportSource = nil
+	}
 	return nil
 }
 
@@ -4133,6 +4247,7 @@ func autoConvert_api_RepositoryImportStatus_To_v1_RepositoryImportStatus(in *ima
 	} else {
 		out.AdditionalTags = nil
 	}
+	out.TagsTruncated = in.TagsTruncated
 	return nil
 }
 
@@ -4165,6 +4280,7 @@ func autoConvert_api_TagImportPolicy_To_v1_TagImportPolicy(in *imageapi.TagImpor
 	}
 	out.Insecure = in.Insecure
 	out.Scheduled = in.Scheduled
+	out.Platform = in.Platform
 	return nil
 }
 
@@ -4279,6 +4395,14 @@ func autoConvert_v1_ImageImportStatus_To_api_ImageImportStatus(in *imageapiv1.Im
 	} else {
 		out.Image = nil
 	}
+	// unable to generate simple pointer conversion for v1.TagImportSource -> api.TagImportSource
+	if in.ImportSource != nil {
+		if err := s.Convert(&in.ImportSource, &out.ImportSource, 0); err != nil {
+			return err
+		}
+	} else {
+		out.ImportSource = nil
+	}
 	out.Tag = in.Tag
 	return nil
 }
@@ -4602,6 +4726,7 @@ func autoConvert_v1_RepositoryImportStatus_To_api_RepositoryImportStatus(in *ima
 	} else {
 		out.AdditionalTags = nil
 	}
+	out.TagsTruncated = in.TagsTruncated
 	return nil
 }
 
@@ -4634,6 +4759,7 @@ func autoConvert_v1_TagImportPolicy_To_api_TagImportPolicy(in *imageapiv1.TagImp
 	}
 	out.Insecure = in.Insecure
 	out.Scheduled = in.Scheduled
+	out.Platform = in.Platform
 	return nil
 }
 
@@ -8757,6 +8883,7 @@ func init() {
 		autoConvert_api_ImageChangeTrigger_To_v1_ImageChangeTrigger,
 		autoConvert_api_ImageImportSpec_To_v1_ImageImportSpec,
 		autoConvert_api_ImageImportStatus_To_v1_ImageImportStatus,
+		autoConvert_api_ImageLabel_To_v1_ImageLabel,
 		autoConvert_api_ImageList_To_v1_ImageList,
 		autoConvert_api_ImageSourcePath_To_v1_ImageSourcePath,
 		autoConvert_api_ImageSource_To_v1_ImageSource,
@@ -8773,6 +8900,7 @@ func init() {
 		autoConvert_api_ImageStream_To_v1_ImageStream,
 		autoConvert_api_Image_To_v1_Image,
 		autoConvert_api_IsPersonalSubjectAccessReview_To_v1_IsPersonalSubjectAccessReview,
+		autoConvert_api_JenkinsPipelineBuildStrategy_To_v1_JenkinsPipelineBuildStrategy,
 		autoConvert_api_LifecycleHook_To_v1_LifecycleHook,
 		autoConvert_api_Lifecycle_To_v1_Lifecycle,
 		autoConvert_api_LocalObjectReference_To_v1_LocalObjectReference,
@@ -8932,6 +9060,7 @@ func init() {
 		autoConvert_v1_ImageChangeTrigger_To_api_ImageChangeTrigger,
 		autoConvert_v1_ImageImportSpec_To_api_ImageImportSpec,
 		autoConvert_v1_ImageImportStatus_To_api_ImageImportStatus,
+		autoConvert_v1_ImageLabel_To_api_ImageLabel,
 		autoConvert_v1_ImageList_To_api_ImageList,
 		autoConvert_v1_ImageSourcePath_To_api_ImageSourcePath,
 		autoConvert_v1_ImageSource_To_api_ImageSource,
@@ -8948,6 +9077,7 @@ func init() {
 		autoConvert_v1_ImageStream_To_api_ImageStream,
 		autoConvert_v1_Image_To_api_Image,
 		autoConvert_v1_IsPersonalSubjectAccessReview_To_api_IsPersonalSubjectAccessReview,
+		autoConvert_v1_JenkinsPipelineBuildStrategy_To_api_JenkinsPipelineBuildStrategy,
 		autoConvert_v1_LifecycleHook_To_api_LifecycleHook,
 		autoConvert_v1_Lifecycle_To_api_Lifecycle,
 		autoConvert_v1_LocalObjectReference_To_api_LocalObjectReference,
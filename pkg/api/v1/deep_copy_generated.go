@@ -1144,6 +1144,14 @@ func deepCopy_v1_BuildStrategy(in apiv1.BuildStrategy, out *apiv1.BuildStrategy,
 	} else {
 		out.CustomStrategy = nil
 	}
+	if in.JenkinsPipelineStrategy != nil {
+		out.JenkinsPipelineStrategy = new(apiv1.JenkinsPipelineBuildStrategy)
+		if err := deepCopy_v1_JenkinsPipelineBuildStrategy(*in.JenkinsPipelineStrategy, out.JenkinsPipelineStrategy, c); err != nil {
+			return err
+		}
+	} else {
+		out.JenkinsPipelineStrategy = nil
+	}
 	return nil
 }
 
@@ -1334,6 +1342,12 @@ func deepCopy_v1_ImageSourcePath(in apiv1.ImageSourcePath, out *apiv1.ImageSourc
 	return nil
 }
 
+func deepCopy_v1_JenkinsPipelineBuildStrategy(in apiv1.JenkinsPipelineBuildStrategy, out *apiv1.JenkinsPipelineBuildStrategy, c *conversion.Cloner) error {
+	out.JenkinsfilePath = in.JenkinsfilePath
+	out.Jenkinsfile = in.Jenkinsfile
+	return nil
+}
+
 func deepCopy_v1_SecretBuildSource(in apiv1.SecretBuildSource, out *apiv1.SecretBuildSource, c *conversion.Cloner) error {
 	if newVal, err := c.DeepCopy(in.Secret); err != nil {
 		return err
@@ -1964,6 +1978,14 @@ func deepCopy_v1_ImageImportStatus(in imageapiv1.ImageImportStatus, out *imageap
 		out.Image = nil
 	}
 	out.Tag = in.Tag
+	if in.ImportSource != nil {
+		out.ImportSource = new(imageapiv1.TagImportSource)
+		if err := deepCopy_v1_TagImportSource(*in.ImportSource, out.ImportSource, c); err != nil {
+			return err
+		}
+	} else {
+		out.ImportSource = nil
+	}
 	return nil
 }
 
@@ -2313,6 +2335,14 @@ func deepCopy_v1_TagEvent(in imageapiv1.TagEvent, out *imageapiv1.TagEvent, c *c
 	out.DockerImageReference = in.DockerImageReference
 	out.Image = in.Image
 	out.Generation = in.Generation
+	if in.ImportSource != nil {
+		out.ImportSource = new(imageapiv1.TagImportSource)
+		if err := deepCopy_v1_TagImportSource(*in.ImportSource, out.ImportSource, c); err != nil {
+			return err
+		}
+	} else {
+		out.ImportSource = nil
+	}
 	return nil
 }
 
@@ -2336,6 +2366,17 @@ func deepCopy_v1_TagImportPolicy(in imageapiv1.TagImportPolicy, out *imageapiv1.
 	return nil
 }
 
+func deepCopy_v1_TagImportSource(in imageapiv1.TagImportSource, out *imageapiv1.TagImportSource, c *conversion.Cloner) error {
+	out.Registry = in.Registry
+	out.Secret = in.Secret
+	if newVal, err := c.DeepCopy(in.Retrieved); err != nil {
+		return err
+	} else {
+		out.Retrieved = newVal.(unversioned.Time)
+	}
+	return nil
+}
+
 func deepCopy_v1_TagReference(in imageapiv1.TagReference, out *imageapiv1.TagReference, c *conversion.Cloner) error {
 	out.Name = in.Name
 	if in.Annotations != nil {
@@ -3219,6 +3260,7 @@ func init() {
 		deepCopy_v1_ImageChangeTrigger,
 		deepCopy_v1_ImageSource,
 		deepCopy_v1_ImageSourcePath,
+		deepCopy_v1_JenkinsPipelineBuildStrategy,
 		deepCopy_v1_SecretBuildSource,
 		deepCopy_v1_SecretSpec,
 		deepCopy_v1_SourceBuildStrategy,
@@ -3267,6 +3309,7 @@ func init() {
 		deepCopy_v1_TagEvent,
 		deepCopy_v1_TagEventCondition,
 		deepCopy_v1_TagImportPolicy,
+		deepCopy_v1_TagImportSource,
 		deepCopy_v1_TagReference,
 		deepCopy_v1_OAuthAccessToken,
 		deepCopy_v1_OAuthAccessTokenList,
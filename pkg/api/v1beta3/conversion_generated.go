@@ -1177,6 +1177,7 @@ func autoConvert_api_BuildOutput_To_v1beta3_BuildOutput(in *buildapi.BuildOutput
 	} else {
 		out.PushSecret = nil
 	}
+	// in.ImageLabels has no peer in out
 	return nil
 }
 
@@ -1382,6 +1383,7 @@ func autoConvert_api_BuildStrategy_To_v1beta3_BuildStrategy(in *buildapi.BuildSt
 	} else {
 		out.CustomStrategy = nil
 	}
+	// in.JenkinsPipelineStrategy has no peer in out
 	return nil
 }
 
@@ -82,6 +82,8 @@ func resourceForStrategyType(strategy buildapi.BuildStrategy) string {
 		return authorizationapi.CustomBuildResource
 	case strategy.SourceStrategy != nil:
 		return authorizationapi.SourceBuildResource
+	case strategy.JenkinsPipelineStrategy != nil:
+		return authorizationapi.JenkinsPipelineBuildResource
 	}
 	return ""
 }
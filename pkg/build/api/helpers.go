@@ -1,6 +1,10 @@
 package api
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	kapi "k8s.io/kubernetes/pkg/api"
 )
 
@@ -50,3 +54,101 @@ func hasBuildConfigLabel(build Build, labelName, labelValue string) bool {
 	value, ok := build.Labels[labelName]
 	return ok && value == labelValue
 }
+
+// BuildHistoryLimits returns the number of successful and failed builds of config to keep, as set by the
+// BuildConfigSuccessfulBuildsHistoryLimitAnnotation and BuildConfigFailedBuildsHistoryLimitAnnotation
+// annotations. A limit that is not set is returned as -1.
+func BuildHistoryLimits(config *BuildConfig) (successful, failed int, err error) {
+	if successful, err = historyLimit(config, BuildConfigSuccessfulBuildsHistoryLimitAnnotation); err != nil {
+		return -1, -1, err
+	}
+	if failed, err = historyLimit(config, BuildConfigFailedBuildsHistoryLimitAnnotation); err != nil {
+		return -1, -1, err
+	}
+	return successful, failed, nil
+}
+
+// SetBuildHistoryLimits sets the history limit annotations on config. Nil limits are left unchanged.
+func SetBuildHistoryLimits(config *BuildConfig, successful, failed *int) {
+	for annotation, limit := range map[string]*int{
+		BuildConfigSuccessfulBuildsHistoryLimitAnnotation: successful,
+		BuildConfigFailedBuildsHistoryLimitAnnotation:     failed,
+	} {
+		if limit == nil {
+			continue
+		}
+		if config.Annotations == nil {
+			config.Annotations = map[string]string{}
+		}
+		config.Annotations[annotation] = strconv.Itoa(*limit)
+	}
+}
+
+func historyLimit(config *BuildConfig, annotation string) (int, error) {
+	value, ok := config.Annotations[annotation]
+	if !ok {
+		return -1, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 0 {
+		return -1, fmt.Errorf("the %s annotation of build config %s/%s must be a non-negative integer", annotation, config.Namespace, config.Name)
+	}
+	return limit, nil
+}
+
+// RunPolicyForConfig returns the BuildRunPolicy set by the BuildConfigRunPolicyAnnotation of config, which
+// defaults to BuildRunPolicyParallel.
+func RunPolicyForConfig(config *BuildConfig) (BuildRunPolicy, error) {
+	value, ok := config.Annotations[BuildConfigRunPolicyAnnotation]
+	if !ok {
+		return BuildRunPolicyParallel, nil
+	}
+	switch policy := BuildRunPolicy(value); policy {
+	case BuildRunPolicyParallel, BuildRunPolicySerial, BuildRunPolicySerialLatestOnly:
+		return policy, nil
+	}
+	return BuildRunPolicyParallel, fmt.Errorf("the %s annotation of build config %s/%s must be one of %s, %s or %s", BuildConfigRunPolicyAnnotation, config.Namespace, config.Name, BuildRunPolicyParallel, BuildRunPolicySerial, BuildRunPolicySerialLatestOnly)
+}
+
+// SetRunPolicy sets the run policy annotation on config.
+func SetRunPolicy(config *BuildConfig, policy BuildRunPolicy) {
+	if config.Annotations == nil {
+		config.Annotations = map[string]string{}
+	}
+	config.Annotations[BuildConfigRunPolicyAnnotation] = string(policy)
+}
+
+// OutputTagsForBuild returns the tags of the BuildConfigOutputTagsAnnotation of config for the build numbered
+// number.
+func OutputTagsForBuild(config *BuildConfig, number int) []string {
+	value := config.Annotations[BuildConfigOutputTagsAnnotation]
+	if len(value) == 0 {
+		return nil
+	}
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			continue
+		}
+		tags = append(tags, strings.Replace(tag, BuildNumberParameter, strconv.Itoa(number), -1))
+	}
+	return tags
+}
+
+// SetOutputTags sets the output tags annotation on config.
+func SetOutputTags(config *BuildConfig, tags []string) {
+	if config.Annotations == nil {
+		config.Annotations = map[string]string{}
+	}
+	config.Annotations[BuildConfigOutputTagsAnnotation] = strings.Join(tags, ",")
+}
+
+// BuildOutputTags returns the tags the output image of build is tagged into once it completes.
+func BuildOutputTags(build *Build) []string {
+	value := build.Annotations[BuildOutputTagsAnnotation]
+	if len(value) == 0 {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
@@ -170,3 +170,44 @@ func assertThatArrayIsEmpty(t *testing.T, array []Build) {
 		t.Errorf("expected empty array, got %v", array)
 	}
 }
+
+func TestRunPolicyForConfig(t *testing.T) {
+	tests := map[string]struct {
+		annotations map[string]string
+		policy      BuildRunPolicy
+		err         bool
+	}{
+		"default":     {policy: BuildRunPolicyParallel},
+		"serial":      {annotations: map[string]string{BuildConfigRunPolicyAnnotation: "Serial"}, policy: BuildRunPolicySerial},
+		"latest only": {annotations: map[string]string{BuildConfigRunPolicyAnnotation: "SerialLatestOnly"}, policy: BuildRunPolicySerialLatestOnly},
+		"invalid":     {annotations: map[string]string{BuildConfigRunPolicyAnnotation: "serial"}, policy: BuildRunPolicyParallel, err: true},
+	}
+	for name, test := range tests {
+		config := &BuildConfig{ObjectMeta: kapi.ObjectMeta{Annotations: test.annotations}}
+		policy, err := RunPolicyForConfig(config)
+		if policy != test.policy || (err != nil) != test.err {
+			t.Errorf("%s: unexpected policy %q and error %v", name, policy, err)
+		}
+	}
+}
+
+func TestOutputTagsForBuild(t *testing.T) {
+	tests := map[string]struct {
+		annotation string
+		tags       []string
+	}{
+		"none":      {},
+		"numbered":  {annotation: "v${build-number}", tags: []string{"v7"}},
+		"several":   {annotation: "build-${build-number}, stable,", tags: []string{"build-7", "stable"}},
+		"no number": {annotation: "stable", tags: []string{"stable"}},
+	}
+	for name, test := range tests {
+		config := &BuildConfig{}
+		if len(test.annotation) > 0 {
+			config.Annotations = map[string]string{BuildConfigOutputTagsAnnotation: test.annotation}
+		}
+		if tags := OutputTagsForBuild(config, 7); !reflect.DeepEqual(tags, test.tags) {
+			t.Errorf("%s: unexpected tags: %v", name, tags)
+		}
+	}
+}
@@ -171,8 +171,17 @@ const (
 	// StatusReasonExceededRetryTimeout is an error condition when the build has
 	// not completed and retrying the build times out.
 	StatusReasonExceededRetryTimeout = "ExceededRetryTimeout"
+
+	// StatusReasonPipelineControllerPending is a condition of a Jenkins pipeline
+	// build that waits for an external controller to run it.
+	StatusReasonPipelineControllerPending = "PipelineControllerPending"
 )
 
+// PipelineControllerPendingMessage is the status message of Jenkins pipeline
+// builds that wait for an external controller to run them.
+const PipelineControllerPendingMessage = "Pipeline builds are run by an external controller such as the Jenkins sync plugin"
+
+
 // BuildSource is the input used for the build.
 type BuildSource struct {
 	// Binary builds accept a binary as their input. The binary is generally assumed to be a tar,
@@ -327,6 +336,10 @@ type BuildStrategy struct {
 
 	// CustomStrategy holds the parameters to the Custom build strategy
 	CustomStrategy *CustomBuildStrategy
+
+	// JenkinsPipelineStrategy holds the parameters to the Jenkins Pipeline build strategy.
+	// This strategy is experimental.
+	JenkinsPipelineStrategy *JenkinsPipelineBuildStrategy
 }
 
 // BuildStrategyType describes a particular way of performing a build.
@@ -419,6 +432,21 @@ type SourceBuildStrategy struct {
 	ForcePull bool
 }
 
+// JenkinsPipelineBuildStrategy holds parameters specific to a Jenkins Pipeline build.
+// This strategy is experimental. The build controller does not run pipeline builds: they
+// require an external controller, such as the Jenkins sync plugin, that runs the pipeline in
+// Jenkins and updates the status of the build. Until then the build stays New with the
+// PipelineControllerPending status reason.
+type JenkinsPipelineBuildStrategy struct {
+	// JenkinsfilePath is the optional path of the Jenkinsfile that will be used to configure the pipeline
+	// relative to the root of the context (contextDir). If both JenkinsfilePath and Jenkinsfile are
+	// not specified, this defaults to Jenkinsfile in the root of the specified contextDir.
+	JenkinsfilePath string
+
+	// Jenkinsfile defines the optional raw contents of a Jenkinsfile which defines a Jenkins pipeline build.
+	Jenkinsfile string
+}
+
 // A BuildPostCommitSpec holds a build post commit hook specification. The hook
 // executes a command in a temporary container running the build output image,
 // immediately after the last layer of the image is committed and before the
@@ -516,6 +544,19 @@ type BuildOutput struct {
 	// up the authentication for executing the Docker push to authentication
 	// enabled Docker Registry (or Docker Hub).
 	PushSecret *kapi.LocalObjectReference
+
+	// ImageLabels define a list of labels that are applied to the resulting image. If there
+	// are multiple labels with the same name then the last one in the list is used.
+	ImageLabels []ImageLabel
+}
+
+// ImageLabel represents a label applied to the resulting image.
+type ImageLabel struct {
+	// Name defines the name of the label. It must have non-zero length.
+	Name string
+
+	// Value defines the literal value of the label.
+	Value string
 }
 
 const (
@@ -528,6 +569,38 @@ const (
 	// BuildConfigPausedAnnotation is an annotation that marks a BuildConfig as paused.
 	// New Builds cannot be instantiated from a paused BuildConfig.
 	BuildConfigPausedAnnotation = "openshift.io/build-config.paused"
+	// BuildConfigSuccessfulBuildsHistoryLimitAnnotation is an annotation on a BuildConfig that holds the
+	// number of completed builds of the BuildConfig to keep when pruning.
+	BuildConfigSuccessfulBuildsHistoryLimitAnnotation = "openshift.io/build-config.successful-builds-history-limit"
+	// BuildConfigFailedBuildsHistoryLimitAnnotation is an annotation on a BuildConfig that holds the number
+	// of failed, errored and cancelled builds of the BuildConfig to keep when pruning.
+	BuildConfigFailedBuildsHistoryLimitAnnotation = "openshift.io/build-config.failed-builds-history-limit"
+	// BuildConfigRunPolicyAnnotation is an annotation on a BuildConfig that holds the BuildRunPolicy of the
+	// builds instantiated from it.
+	BuildConfigRunPolicyAnnotation = "openshift.io/build-config.run-policy"
+	// BuildConfigOutputTagsAnnotation is an annotation on a BuildConfig that holds a comma separated list of
+	// tags of the output image stream that the image of each successful build is also tagged into. The
+	// BuildNumberParameter is replaced with the number of the build in each tag.
+	BuildConfigOutputTagsAnnotation = "openshift.io/build-config.output-tags"
+	// BuildOutputTagsAnnotation is an annotation on a Build that holds the tags of the
+	// BuildConfigOutputTagsAnnotation of its build config, with the number of the build substituted.
+	BuildOutputTagsAnnotation = "openshift.io/build.output-tags"
+	// BuildNumberParameter is replaced with the number of a build in the tags of the
+	// BuildConfigOutputTagsAnnotation.
+	BuildNumberParameter = "${build-number}"
+)
+
+// BuildRunPolicy defines how the builds of a BuildConfig are scheduled relative to each other.
+type BuildRunPolicy string
+
+const (
+	// BuildRunPolicyParallel runs builds as soon as they are created, regardless of the other builds.
+	BuildRunPolicyParallel BuildRunPolicy = "Parallel"
+	// BuildRunPolicySerial runs builds one at a time, in the order they were created.
+	BuildRunPolicySerial BuildRunPolicy = "Serial"
+	// BuildRunPolicySerialLatestOnly runs builds one at a time and cancels queued builds that have been
+	// superseded by a newer build.
+	BuildRunPolicySerialLatestOnly BuildRunPolicy = "SerialLatestOnly"
 )
 
 // BuildConfig is a template which can be used to create new builds.
@@ -562,8 +635,15 @@ type BuildConfigStatus struct {
 type WebHookTrigger struct {
 	// Secret used to validate requests.
 	Secret string
+	// SecretReference names a Secret in the namespace of the build config whose WebHookSecretKey value is used
+	// to validate requests instead of Secret.
+	SecretReference *kapi.LocalObjectReference
 }
 
+// WebHookSecretKey is the key of the value that validates webhook requests in a Secret referenced by a
+// WebHookTrigger.
+const WebHookSecretKey = "WebHookSecretKey"
+
 // ImageChangeTrigger allows builds to be triggered when an ImageStream changes
 type ImageChangeTrigger struct {
 	// LastTriggeredImageID is used internally by the ImageChangeController to save last
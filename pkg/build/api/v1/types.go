@@ -300,6 +300,10 @@ type BuildStrategy struct {
 
 	// CustomStrategy holds the parameters to the Custom build strategy
 	CustomStrategy *CustomBuildStrategy `json:"customStrategy,omitempty" description:"holds parameters to the Custom build strategy"`
+
+	// JenkinsPipelineStrategy holds the parameters to the Jenkins Pipeline build strategy.
+	// This strategy is experimental.
+	JenkinsPipelineStrategy *JenkinsPipelineBuildStrategy `json:"jenkinsPipelineStrategy,omitempty" description:"holds parameters to the Jenkins Pipeline build strategy; experimental, requires an external controller such as the Jenkins sync plugin to run the builds"`
 }
 
 // BuildStrategyType describes a particular way of performing a build.
@@ -316,6 +320,9 @@ const (
 
 	// CustomBuildStrategyType performs builds using custom builder Docker image.
 	CustomBuildStrategyType BuildStrategyType = "Custom"
+
+	// JenkinsPipelineBuildStrategyType indicates the build will run via Jenkins Pipeline.
+	JenkinsPipelineBuildStrategyType BuildStrategyType = "JenkinsPipeline"
 )
 
 // CustomBuildStrategy defines input parameters specific to Custom build.
@@ -399,6 +406,21 @@ type SourceBuildStrategy struct {
 	ForcePull bool `json:"forcePull,omitempty" description:"forces the source build to pull the image if true"`
 }
 
+// JenkinsPipelineBuildStrategy holds parameters specific to a Jenkins Pipeline build.
+// This strategy is experimental. The build controller does not run pipeline builds: they
+// require an external controller, such as the Jenkins sync plugin, that runs the pipeline in
+// Jenkins and updates the status of the build. Until then the build stays New with the
+// PipelineControllerPending status reason.
+type JenkinsPipelineBuildStrategy struct {
+	// JenkinsfilePath is the optional path of the Jenkinsfile that will be used to configure the pipeline
+	// relative to the root of the context (contextDir). If both JenkinsfilePath and Jenkinsfile are
+	// not specified, this defaults to Jenkinsfile in the root of the specified contextDir.
+	JenkinsfilePath string `json:"jenkinsfilePath,omitempty" description:"path of the Jenkinsfile that defines the pipeline, relative to the context directory; defaults to Jenkinsfile"`
+
+	// Jenkinsfile defines the optional raw contents of a Jenkinsfile which defines a Jenkins pipeline build.
+	Jenkinsfile string `json:"jenkinsfile,omitempty" description:"raw contents of a Jenkinsfile which defines a Jenkins pipeline build"`
+}
+
 // A BuildPostCommitSpec holds a build post commit hook specification. The hook
 // executes a command in a temporary container running the build output image,
 // immediately after the last layer of the image is committed and before the
@@ -496,6 +518,19 @@ type BuildOutput struct {
 	// up the authentication for executing the Docker push to authentication
 	// enabled Docker Registry (or Docker Hub).
 	PushSecret *kapi.LocalObjectReference `json:"pushSecret,omitempty" description:"supported type: dockercfg"`
+
+	// ImageLabels define a list of labels that are applied to the resulting image. If there
+	// are multiple labels with the same name then the last one in the list is used.
+	ImageLabels []ImageLabel `json:"imageLabels,omitempty" description:"labels applied to the resulting image; the last label with the same name is used"`
+}
+
+// ImageLabel represents a label applied to the resulting image.
+type ImageLabel struct {
+	// Name defines the name of the label. It must have non-zero length.
+	Name string `json:"name" description:"name of the label"`
+
+	// Value defines the literal value of the label.
+	Value string `json:"value,omitempty" description:"literal value of the label"`
 }
 
 // BuildConfig is a template which can be used to create new builds.
@@ -530,6 +565,9 @@ type BuildConfigStatus struct {
 type WebHookTrigger struct {
 	// Secret used to validate requests.
 	Secret string `json:"secret,omitempty" description:"secret used to validate requests"`
+	// SecretReference names a Secret in the namespace of the build config whose WebHookSecretKey value is used
+	// to validate requests instead of Secret.
+	SecretReference *kapi.LocalObjectReference `json:"secretReference,omitempty" description:"secret whose WebHookSecretKey value is used to validate requests instead of secret"`
 }
 
 // ImageChangeTrigger allows builds to be triggered when an ImageStream changes
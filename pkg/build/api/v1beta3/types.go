@@ -518,6 +518,9 @@ type BuildConfigStatus struct {
 type WebHookTrigger struct {
 	// Secret used to validate requests.
 	Secret string `json:"secret,omitempty"`
+	// SecretReference names a Secret in the namespace of the build config whose WebHookSecretKey value is used
+	// to validate requests instead of Secret.
+	SecretReference *kapi.LocalObjectReference `json:"secretReference,omitempty"`
 }
 
 // ImageChangeTrigger allows builds to be triggered when an ImageStream changes
@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	kapi "k8s.io/kubernetes/pkg/api"
@@ -103,7 +104,9 @@ func validateBuildSpec(spec *buildapi.BuildSpec, fldPath *field.Path) field.Erro
 	allErrs := field.ErrorList{}
 	s := spec.Strategy
 
-	if s.CustomStrategy == nil && spec.Source.Git == nil && spec.Source.Binary == nil && spec.Source.Dockerfile == nil {
+	// a pipeline defined inline needs no source
+	inlinePipeline := s.JenkinsPipelineStrategy != nil && len(s.JenkinsPipelineStrategy.Jenkinsfile) != 0
+	if s.CustomStrategy == nil && !inlinePipeline && spec.Source.Git == nil && spec.Source.Binary == nil && spec.Source.Dockerfile == nil {
 		allErrs = append(allErrs, field.Invalid(fldPath.Child("source"), spec.Source, "must provide a value for at least one of source, binary, or dockerfile"))
 	}
 
@@ -366,10 +369,30 @@ func validateOutput(output *buildapi.BuildOutput, fldPath *field.Path) field.Err
 	}
 
 	allErrs = append(allErrs, validateSecretRef(output.PushSecret, fldPath.Child("pushSecret"))...)
+	allErrs = append(allErrs, ValidateImageLabels(output.ImageLabels, fldPath.Child("imageLabels"))...)
 
 	return allErrs
 }
 
+// validImageLabelName matches the label names that can be written to a Dockerfile LABEL instruction without
+// quoting.
+var validImageLabelName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._/-]*$`)
+
+// ValidateImageLabels tests that the labels requested for the output image of a build have valid names.
+func ValidateImageLabels(labels []buildapi.ImageLabel, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, label := range labels {
+		namePath := fldPath.Index(i).Child("name")
+		switch {
+		case len(label.Name) == 0:
+			allErrs = append(allErrs, field.Required(namePath, ""))
+		case !validImageLabelName.MatchString(label.Name):
+			allErrs = append(allErrs, field.Invalid(namePath, label.Name, "must start with a letter or digit and contain only letters, digits, '.', '_', '/' and '-'"))
+		}
+	}
+	return allErrs
+}
+
 func validateStrategy(strategy *buildapi.BuildStrategy, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
@@ -383,8 +406,11 @@ func validateStrategy(strategy *buildapi.BuildStrategy, fldPath *field.Path) fie
 	if strategy.CustomStrategy != nil {
 		strategyCount++
 	}
+	if strategy.JenkinsPipelineStrategy != nil {
+		strategyCount++
+	}
 	if strategyCount != 1 {
-		return append(allErrs, field.Invalid(fldPath, strategy, "must provide a value for exactly one of sourceStrategy, customStrategy, or dockerStrategy"))
+		return append(allErrs, field.Invalid(fldPath, strategy, "must provide a value for exactly one of sourceStrategy, customStrategy, dockerStrategy, or jenkinsPipelineStrategy"))
 	}
 
 	if strategy.SourceStrategy != nil {
@@ -396,6 +422,9 @@ func validateStrategy(strategy *buildapi.BuildStrategy, fldPath *field.Path) fie
 	if strategy.CustomStrategy != nil {
 		allErrs = append(allErrs, validateCustomStrategy(strategy.CustomStrategy, fldPath.Child("customStrategy"))...)
 	}
+	if strategy.JenkinsPipelineStrategy != nil {
+		allErrs = append(allErrs, validateJenkinsPipelineStrategy(strategy.JenkinsPipelineStrategy, fldPath.Child("jenkinsPipelineStrategy"))...)
+	}
 
 	return allErrs
 }
@@ -445,6 +474,34 @@ func validateCustomStrategy(strategy *buildapi.CustomBuildStrategy, fldPath *fie
 	return allErrs
 }
 
+func validateJenkinsPipelineStrategy(strategy *buildapi.JenkinsPipelineBuildStrategy, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(strategy.JenkinsfilePath) != 0 && len(strategy.Jenkinsfile) != 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath, "", "must provide a value for at most one of jenkinsfilePath, or jenkinsfile"))
+	}
+	if len(strategy.Jenkinsfile) > maxDockerfileLengthBytes {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("jenkinsfile"), "", fmt.Sprintf("must be smaller than %d bytes", maxDockerfileLengthBytes)))
+	}
+
+	if len(strategy.JenkinsfilePath) != 0 {
+		cleaned := path.Clean(strategy.JenkinsfilePath)
+		switch {
+		case strings.HasPrefix(cleaned, "/"):
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("jenkinsfilePath"), strategy.JenkinsfilePath, "jenkinsfilePath must not be an absolute path"))
+		case strings.HasPrefix(cleaned, ".."):
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("jenkinsfilePath"), strategy.JenkinsfilePath, "jenkinsfilePath must not start with .."))
+		default:
+			if cleaned == "." {
+				cleaned = ""
+			}
+			strategy.JenkinsfilePath = cleaned
+		}
+	}
+
+	return allErrs
+}
+
 func validateTrigger(trigger *buildapi.BuildTriggerPolicy, buildFrom *kapi.ObjectReference, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if len(trigger.Type) == 0 {
@@ -502,7 +559,15 @@ func validateTrigger(trigger *buildapi.BuildTriggerPolicy, buildFrom *kapi.Objec
 
 func validateWebHook(webHook *buildapi.WebHookTrigger, fldPath *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	if len(webHook.Secret) == 0 {
+	switch {
+	case webHook.SecretReference != nil:
+		if len(webHook.Secret) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("secretReference"), webHook.SecretReference.Name, "may not be set together with secret"))
+		}
+		if len(webHook.SecretReference.Name) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("secretReference", "name"), ""))
+		}
+	case len(webHook.Secret) == 0:
 		allErrs = append(allErrs, field.Required(fldPath.Child("secret"), ""))
 	}
 	return allErrs
@@ -635,6 +635,26 @@ func TestBuildConfigValidationOutputFailure(t *testing.T) {
 	}
 }
 
+func TestValidateImageLabels(t *testing.T) {
+	labels := []buildapi.ImageLabel{
+		{Name: "io.openshift.build.team", Value: "payments"},
+		{Name: "generated-by", Value: ""},
+		{Name: ""},
+		{Name: "bad label", Value: "x"},
+		{Name: "-leading"},
+	}
+	errs := validateOutput(&buildapi.BuildOutput{ImageLabels: labels}, field.NewPath("output"))
+	expected := []string{"output.imageLabels[2].name", "output.imageLabels[3].name", "output.imageLabels[4].name"}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	for i, err := range errs {
+		if err.Field != expected[i] {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+}
+
 func TestValidateBuildRequest(t *testing.T) {
 	testCases := map[string]*buildapi.BuildRequest{
 		string(field.ErrorTypeRequired) + "metadata.namespace": {ObjectMeta: kapi.ObjectMeta{Name: "requestName"}},
@@ -1021,6 +1041,32 @@ func TestValidateStrategy(t *testing.T) {
 				CustomStrategy: &buildapi.CustomBuildStrategy{},
 			},
 		},
+		// 1
+		{
+			t:    field.ErrorTypeInvalid,
+			path: "jenkinsPipelineStrategy",
+			strategy: &buildapi.BuildStrategy{
+				JenkinsPipelineStrategy: &buildapi.JenkinsPipelineBuildStrategy{
+					JenkinsfilePath: "Jenkinsfile",
+					Jenkinsfile:     "node {}",
+				},
+			},
+		},
+		// 2
+		{
+			t:    field.ErrorTypeInvalid,
+			path: "jenkinsPipelineStrategy.jenkinsfilePath",
+			strategy: &buildapi.BuildStrategy{
+				JenkinsPipelineStrategy: &buildapi.JenkinsPipelineBuildStrategy{JenkinsfilePath: "../Jenkinsfile"},
+			},
+		},
+		// 3
+		{
+			ok: true,
+			strategy: &buildapi.BuildStrategy{
+				JenkinsPipelineStrategy: &buildapi.JenkinsPipelineBuildStrategy{JenkinsfilePath: "ci/Jenkinsfile"},
+			},
+		},
 	}
 	for i, tc := range errorCases {
 		errors := validateStrategy(tc.strategy, nil)
@@ -1702,6 +1748,25 @@ func TestValidateTrigger(t *testing.T) {
 			},
 			expected: []*field.Error{field.Required(field.NewPath("generic"), "")},
 		},
+		"GitHub trigger with secret and secret reference": {
+			trigger: buildapi.BuildTriggerPolicy{
+				Type: buildapi.GitHubWebHookBuildTriggerType,
+				GitHubWebHook: &buildapi.WebHookTrigger{
+					Secret:          "secret101",
+					SecretReference: &kapi.LocalObjectReference{Name: "webhook"},
+				},
+			},
+			expected: []*field.Error{field.Invalid(field.NewPath("github", "secretReference"), "webhook", "")},
+		},
+		"Generic trigger with unnamed secret reference": {
+			trigger: buildapi.BuildTriggerPolicy{
+				Type: buildapi.GenericWebHookBuildTriggerType,
+				GenericWebHook: &buildapi.WebHookTrigger{
+					SecretReference: &kapi.LocalObjectReference{},
+				},
+			},
+			expected: []*field.Error{field.Required(field.NewPath("generic", "secretReference", "name"), "")},
+		},
 		"ImageChange trigger without params": {
 			trigger: buildapi.BuildTriggerPolicy{
 				Type: buildapi.ImageChangeBuildTriggerType,
@@ -1724,6 +1789,14 @@ func TestValidateTrigger(t *testing.T) {
 				},
 			},
 		},
+		"valid Generic trigger with secret reference": {
+			trigger: buildapi.BuildTriggerPolicy{
+				Type: buildapi.GenericWebHookBuildTriggerType,
+				GenericWebHook: &buildapi.WebHookTrigger{
+					SecretReference: &kapi.LocalObjectReference{Name: "webhook"},
+				},
+			},
+		},
 		"valid ImageChange trigger": {
 			trigger: buildapi.BuildTriggerPolicy{
 				Type: buildapi.ImageChangeBuildTriggerType,
@@ -13,6 +13,7 @@ import (
 	"github.com/openshift/origin/pkg/build/api"
 	"github.com/openshift/origin/pkg/client"
 	"github.com/openshift/origin/pkg/generate/git"
+	"github.com/openshift/origin/pkg/util/docker/dockerfile"
 )
 
 const OriginalSourceURLAnnotationKey = "openshift.io/original-source-url"
@@ -61,6 +62,19 @@ func buildInfo(build *api.Build) []KeyValue {
 	return kv
 }
 
+// outputImageLabels returns the labels the build output requests for the resulting image, in order.
+func outputImageLabels(build *api.Build) []dockerfile.KeyValue {
+	labels := build.Spec.Output.ImageLabels
+	if len(labels) == 0 {
+		return nil
+	}
+	kv := make([]dockerfile.KeyValue, 0, len(labels))
+	for _, label := range labels {
+		kv = append(kv, dockerfile.KeyValue{Key: label.Name, Value: label.Value})
+	}
+	return kv
+}
+
 func updateBuildRevision(c client.BuildInterface, build *api.Build, sourceInfo *git.SourceInfo) {
 	if build.Spec.Revision != nil {
 		return
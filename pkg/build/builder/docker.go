@@ -246,7 +246,8 @@ func (d *DockerBuilder) buildLabels(dir string) []dockerfile.KeyValue {
 	for k, v := range labels {
 		kv = append(kv, dockerfile.KeyValue{Key: k, Value: v})
 	}
-	return kv
+	// the labels of the build output come last so that they override the labels of the source
+	return append(kv, outputImageLabels(d.build)...)
 }
 
 // setupPullSecret provides a Docker authentication configuration when the
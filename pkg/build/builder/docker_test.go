@@ -252,3 +252,28 @@ func TestDockerfilePath(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildLabelsOutputImageLabels(t *testing.T) {
+	build := &api.Build{
+		Spec: api.BuildSpec{
+			Output: api.BuildOutput{
+				ImageLabels: []api.ImageLabel{
+					{Name: "team", Value: "a"},
+					{Name: "generated-by", Value: "new-app"},
+					{Name: "team", Value: "b"},
+				},
+			},
+		},
+	}
+	d := &DockerBuilder{build: build}
+	got := d.buildLabels("")
+	want := []dockerfile.KeyValue{
+		{Key: "team", Value: "a"},
+		{Key: "generated-by", Value: "new-app"},
+		{Key: "team", Value: "b"},
+	}
+	// the labels of the output follow the labels of the source so that they take precedence
+	if len(got) < len(want) || !reflect.DeepEqual(got[len(got)-len(want):], want) {
+		t.Errorf("unexpected labels: %#v", got)
+	}
+}
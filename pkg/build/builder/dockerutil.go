@@ -3,7 +3,9 @@ package builder
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,6 +16,8 @@ import (
 	docker "github.com/fsouza/go-dockerclient"
 	"github.com/golang/glog"
 	"github.com/openshift/source-to-image/pkg/tar"
+
+	"github.com/openshift/origin/pkg/util/docker/dockerfile"
 )
 
 var (
@@ -128,6 +132,28 @@ func buildImage(client DockerClient, dir string, dockerfilePath string, noCache
 	return client.BuildImage(opts)
 }
 
+// labelImage adds labels to image by building a Dockerfile that only sets them on top of it, and tagging the
+// result as image.
+func labelImage(client DockerClient, image string, labels []dockerfile.KeyValue, cgLimits *s2iapi.CGroupLimits) error {
+	from, err := dockerfile.From(image)
+	if err != nil {
+		return err
+	}
+	label, err := dockerfile.Label(labels)
+	if err != nil {
+		return err
+	}
+	dir, err := ioutil.TempDir("", "image-labels")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, defaultDockerfilePath), []byte(from+"\n"+label+"\n"), 0600); err != nil {
+		return err
+	}
+	return buildImage(client, dir, defaultDockerfilePath, false, image, tar.New(), nil, false, cgLimits)
+}
+
 // tagImage uses the dockerClient to tag a Docker image with name. It is a
 // helper to facilitate the usage of dockerClient.TagImage, because the former
 // requires the name to be split into more explicit parts.
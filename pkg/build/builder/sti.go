@@ -221,6 +221,13 @@ func (s *S2IBuilder) Build() error {
 		return err
 	}
 
+	if labels := outputImageLabels(s.build); len(labels) > 0 {
+		glog.V(4).Infof("Applying the output image labels of the build to %s", buildTag)
+		if err := labelImage(s.dockerClient, buildTag, labels, s.cgLimits); err != nil {
+			return fmt.Errorf("unable to apply the output image labels: %v", err)
+		}
+	}
+
 	cname := containerName("s2i", s.build.Name, s.build.Namespace, "post-commit")
 	if err := execPostCommitHook(s.dockerClient, s.build.Spec.PostCommit, buildTag, cname); err != nil {
 		return err
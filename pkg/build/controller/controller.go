@@ -10,10 +10,12 @@ import (
 	"k8s.io/kubernetes/pkg/api/unversioned"
 	"k8s.io/kubernetes/pkg/client/cache"
 	"k8s.io/kubernetes/pkg/client/record"
+	"k8s.io/kubernetes/pkg/labels"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
 	buildclient "github.com/openshift/origin/pkg/build/client"
 	strategy "github.com/openshift/origin/pkg/build/controller/strategy"
+	"github.com/openshift/origin/pkg/build/prune"
 	buildutil "github.com/openshift/origin/pkg/build/util"
 	imageapi "github.com/openshift/origin/pkg/image/api"
 )
@@ -25,6 +27,29 @@ type BuildController struct {
 	BuildStrategy     BuildStrategy
 	ImageStreamClient imageStreamClient
 	Recorder          record.EventRecorder
+	// BuildConfigStore and BuildStore, if set, are used to hold back new builds as required by the run policy of
+	// their build config. BuildStore must index builds with BuildConfigIndexFunc.
+	BuildConfigStore cache.Store
+	BuildStore       cache.Indexer
+	// BuildQueue, if set, receives the builds held back by the run policy as soon as a build of their build
+	// config completes.
+	BuildQueue buildQueue
+}
+
+// BuildConfigIndex is the name of the index of builds by the namespace/name key of their build config.
+const BuildConfigIndex = "buildconfig"
+
+// BuildConfigIndexFunc indexes builds by the namespace/name key of their build config.
+func BuildConfigIndexFunc(obj interface{}) ([]string, error) {
+	build, ok := obj.(*buildapi.Build)
+	if !ok {
+		return nil, fmt.Errorf("object %#v is not a build", obj)
+	}
+	name := buildutil.ConfigNameForBuild(build)
+	if len(name) == 0 {
+		return []string{}, nil
+	}
+	return []string{build.Namespace + "/" + name}, nil
 }
 
 // BuildStrategy knows how to create a pod spec for a pod which can execute a build.
@@ -42,6 +67,26 @@ type imageStreamClient interface {
 	GetImageStream(namespace, name string) (*imageapi.ImageStream, error)
 }
 
+type buildConfigGetter interface {
+	GetBuildConfig(namespace, name string) (*buildapi.BuildConfig, error)
+}
+
+type buildLister interface {
+	ListBuilds(namespace string, selector labels.Selector) (*buildapi.BuildList, error)
+}
+
+type buildDeleter interface {
+	DeleteBuild(namespace, name string) error
+}
+
+type buildQueue interface {
+	AddIfNotPresent(obj interface{}) error
+}
+
+type imageStreamTagger interface {
+	TagImage(namespace, stream, fromTag string, tags []string) error
+}
+
 // CancelBuild updates a build status to Cancelled, after its associated pod is deleted.
 func (bc *BuildController) CancelBuild(build *buildapi.Build) error {
 	if !isBuildCancellable(build) {
@@ -91,9 +136,34 @@ func (bc *BuildController) HandleBuild(build *buildapi.Build) error {
 
 	// Handle new builds
 	if build.Status.Phase != buildapi.BuildPhaseNew {
+		if buildutil.IsBuildComplete(build) {
+			bc.queueHeldBackBuilds(build)
+		}
+		return nil
+	}
+
+	// Pipeline builds are run by an external controller, which updates their status. Record that the build
+	// waits for it so that it does not silently remain new.
+	if build.Spec.Strategy.JenkinsPipelineStrategy != nil {
+		if build.Status.Reason == buildapi.StatusReasonPipelineControllerPending {
+			return nil
+		}
+		glog.V(4).Infof("Build %s/%s with the Jenkins pipeline strategy waits for an external controller", build.Namespace, build.Name)
+		build.Status.Reason = buildapi.StatusReasonPipelineControllerPending
+		build.Status.Message = buildapi.PipelineControllerPendingMessage
+		if err := bc.BuildUpdater.Update(build.Namespace, build); err != nil {
+			// clear the reason so that the update is retried
+			build.Status.Reason = ""
+			build.Status.Message = ""
+			return fmt.Errorf("failed to update the status of build %s/%s: %v", build.Namespace, build.Name, err)
+		}
 		return nil
 	}
 
+	if runnable, err := bc.runnable(build); err != nil || !runnable {
+		return err
+	}
+
 	if err := bc.nextBuildPhase(build); err != nil {
 		return err
 	}
@@ -108,6 +178,116 @@ func (bc *BuildController) HandleBuild(build *buildapi.Build) error {
 	return nil
 }
 
+// runnable returns whether the run policy of the build config of build allows the build to start now. Builds
+// that are held back remain new and are queued again when a build of their build config completes. Under the
+// SerialLatestOnly policy, a build that has been superseded by a newer new build is cancelled.
+func (bc *BuildController) runnable(build *buildapi.Build) (bool, error) {
+	config, policy, err := bc.runPolicy(build)
+	if err != nil {
+		return false, err
+	}
+	if config == nil || policy == buildapi.BuildRunPolicyParallel {
+		return true, nil
+	}
+
+	builds, err := bc.configBuilds(config.Namespace, config.Name)
+	if err != nil {
+		return false, err
+	}
+	version := buildutil.VersionForBuild(build)
+	runnable := true
+	for _, other := range builds {
+		if other.Name == build.Name {
+			continue
+		}
+		switch other.Status.Phase {
+		case buildapi.BuildPhasePending, buildapi.BuildPhaseRunning:
+			runnable = false
+		case buildapi.BuildPhaseNew:
+			if other.Status.Cancelled {
+				continue
+			}
+			otherVersion := buildutil.VersionForBuild(other)
+			if otherVersion > version && policy == buildapi.BuildRunPolicySerialLatestOnly {
+				glog.V(4).Infof("Build %s/%s has been superseded by %s and will be cancelled", build.Namespace, build.Name, other.Name)
+				build.Status.Cancelled = true
+				return false, bc.CancelBuild(build)
+			}
+			// earlier new builds are cancelled rather than run under the SerialLatestOnly policy
+			if otherVersion < version && policy == buildapi.BuildRunPolicySerial {
+				runnable = false
+			}
+		}
+	}
+	if !runnable {
+		glog.V(4).Infof("Build %s/%s is waiting for earlier builds of %s/%s to complete", build.Namespace, build.Name, config.Namespace, config.Name)
+	}
+	return runnable, nil
+}
+
+// runPolicy returns the build config of build from the build config store and its run policy, or a nil build
+// config if the build has none or the run policy is not enforced.
+func (bc *BuildController) runPolicy(build *buildapi.Build) (*buildapi.BuildConfig, buildapi.BuildRunPolicy, error) {
+	name := buildutil.ConfigNameForBuild(build)
+	if len(name) == 0 || bc.BuildConfigStore == nil || bc.BuildStore == nil {
+		return nil, "", nil
+	}
+	obj, exists, err := bc.BuildConfigStore.GetByKey(build.Namespace + "/" + name)
+	if err != nil || !exists {
+		return nil, "", err
+	}
+	config := obj.(*buildapi.BuildConfig)
+	policy, err := buildapi.RunPolicyForConfig(config)
+	if err != nil {
+		glog.V(2).Infof("Ignoring the run policy of build config %s/%s: %v", config.Namespace, config.Name, err)
+		return nil, "", nil
+	}
+	return config, policy, nil
+}
+
+// configBuilds returns the builds of the build config namespace/name from the build store.
+func (bc *BuildController) configBuilds(namespace, name string) ([]*buildapi.Build, error) {
+	objs, err := bc.BuildStore.ByIndex(BuildConfigIndex, namespace+"/"+name)
+	if err != nil {
+		return nil, err
+	}
+	builds := make([]*buildapi.Build, 0, len(objs))
+	for _, obj := range objs {
+		builds = append(builds, obj.(*buildapi.Build))
+	}
+	return builds, nil
+}
+
+// queueHeldBackBuilds queues copies of the new builds of the build config of build, which has completed, so
+// that the builds held back by the run policy start without waiting for the build queue to be resynced.
+func (bc *BuildController) queueHeldBackBuilds(build *buildapi.Build) {
+	if bc.BuildQueue == nil {
+		return
+	}
+	config, policy, err := bc.runPolicy(build)
+	if err != nil || config == nil || policy == buildapi.BuildRunPolicyParallel {
+		return
+	}
+	builds, err := bc.configBuilds(config.Namespace, config.Name)
+	if err != nil {
+		glog.V(4).Infof("Unable to find the builds of build config %s/%s: %v", config.Namespace, config.Name, err)
+		return
+	}
+	for _, other := range builds {
+		if other.Status.Phase != buildapi.BuildPhaseNew {
+			continue
+		}
+		copied, err := kapi.Scheme.Copy(other)
+		if err != nil {
+			glog.V(4).Infof("Unable to copy build %s/%s: %v", other.Namespace, other.Name, err)
+			continue
+		}
+		if err := bc.BuildQueue.AddIfNotPresent(copied); err != nil {
+			glog.V(4).Infof("Unable to queue build %s/%s: %v", other.Namespace, other.Name, err)
+		}
+	}
+}
+
 // nextBuildPhase updates build with any appropriate changes, or returns an error if
 // the change cannot occur. When returning nil, be sure to set build.Status and optionally
 // build.Message.
@@ -235,6 +415,13 @@ type BuildPodController struct {
 	BuildStore   cache.Store
 	BuildUpdater buildclient.BuildUpdater
 	PodManager   podManager
+	// ImageStreamTagger, if set, tags the output image of completed builds into their output tags.
+	ImageStreamTagger imageStreamTagger
+	// BuildConfigGetter, BuildLister and BuildDeleter, if set, delete the builds of a build config that exceed
+	// the history limits of the build config whenever one of its builds completes.
+	BuildConfigGetter buildConfigGetter
+	BuildLister       buildLister
+	BuildDeleter      buildDeleter
 }
 
 // HandlePod updates the state of the build based on the pod state
@@ -293,10 +480,75 @@ func (bc *BuildPodController) HandlePod(pod *kapi.Pod) error {
 			return fmt.Errorf("failed to update build %s/%s: %v", build.Namespace, build.Name, err)
 		}
 		glog.V(4).Infof("Build %s/%s status was updated %s -> %s", build.Namespace, build.Name, build.Status.Phase, nextStatus)
+		if build.Status.Phase == buildapi.BuildPhaseComplete {
+			bc.tagOutput(build)
+		}
+		if buildutil.IsBuildComplete(build) {
+			bc.pruneHistory(build)
+		}
 	}
 	return nil
 }
 
+// pruneHistory deletes the completed builds of the build config of build that exceed its history limits.
+// Failing to prune does not fail the handling of the build, which is pruned again when the next build completes.
+func (bc *BuildPodController) pruneHistory(build *buildapi.Build) {
+	name := buildutil.ConfigNameForBuild(build)
+	if len(name) == 0 || bc.BuildConfigGetter == nil || bc.BuildLister == nil || bc.BuildDeleter == nil {
+		return
+	}
+	config, err := bc.BuildConfigGetter.GetBuildConfig(build.Namespace, name)
+	if err != nil {
+		glog.V(4).Infof("Unable to get build config %s/%s to prune its builds: %v", build.Namespace, name, err)
+		return
+	}
+	if successful, failed, err := buildapi.BuildHistoryLimits(config); err != nil || (successful < 0 && failed < 0) {
+		return
+	}
+	list, err := bc.BuildLister.ListBuilds(build.Namespace, buildutil.BuildConfigSelector(name))
+	if err != nil {
+		glog.V(2).Infof("Unable to list the builds of build config %s/%s to prune them: %v", build.Namespace, name, err)
+		return
+	}
+	builds := make([]*buildapi.Build, 0, len(list.Items))
+	for i := range list.Items {
+		builds = append(builds, &list.Items[i])
+	}
+	tasker, err := prune.NewHistoryLimitPruneTasker(config, builds, func(build *buildapi.Build) error {
+		glog.V(4).Infof("Deleting build %s/%s, which exceeds the history limits of its build config", build.Namespace, build.Name)
+		if err := bc.BuildDeleter.DeleteBuild(build.Namespace, build.Name); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+	if err := tasker.PruneTask(); err != nil {
+		glog.V(2).Infof("Unable to prune the builds of build config %s/%s: %v", build.Namespace, name, err)
+	}
+}
+
+// tagOutput tags the output image of build into the output tags of the build, if it has any.
+func (bc *BuildPodController) tagOutput(build *buildapi.Build) {
+	tags := buildapi.BuildOutputTags(build)
+	to := build.Spec.Output.To
+	if bc.ImageStreamTagger == nil || len(tags) == 0 || to == nil || to.Kind != "ImageStreamTag" {
+		return
+	}
+	stream, tag, ok := imageapi.SplitImageStreamTag(to.Name)
+	if !ok {
+		tag = imageapi.DefaultImageTag
+	}
+	namespace := to.Namespace
+	if len(namespace) == 0 {
+		namespace = build.Namespace
+	}
+	if err := bc.ImageStreamTagger.TagImage(namespace, stream, tag, tags); err != nil {
+		glog.V(2).Infof("Unable to tag the output of build %s/%s into %v: %v", build.Namespace, build.Name, tags, err)
+	}
+}
+
 // isBuildCancellable checks for build status and returns true if the condition is checked.
 func isBuildCancellable(build *buildapi.Build) bool {
 	return build.Status.Phase == buildapi.BuildPhaseNew || build.Status.Phase == buildapi.BuildPhasePending || build.Status.Phase == buildapi.BuildPhaseRunning
@@ -4,11 +4,15 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	kapi "k8s.io/kubernetes/pkg/api"
 	kerrors "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/client/cache"
 	"k8s.io/kubernetes/pkg/client/record"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/util/sets"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
 	buildclient "github.com/openshift/origin/pkg/build/client"
@@ -22,6 +26,15 @@ func (okc *okBuildUpdater) Update(namespace string, build *buildapi.Build) error
 	return nil
 }
 
+type countingBuildUpdater struct {
+	updates int
+}
+
+func (c *countingBuildUpdater) Update(namespace string, build *buildapi.Build) error {
+	c.updates++
+	return nil
+}
+
 type errBuildUpdater struct{}
 
 func (ec *errBuildUpdater) Update(namespace string, build *buildapi.Build) error {
@@ -417,6 +430,37 @@ func TestHandleBuild(t *testing.T) {
 	}
 }
 
+func TestHandleBuildJenkinsPipeline(t *testing.T) {
+	build := mockBuild(buildapi.BuildPhaseNew, buildapi.BuildOutput{})
+	build.Spec.Strategy = buildapi.BuildStrategy{JenkinsPipelineStrategy: &buildapi.JenkinsPipelineBuildStrategy{}}
+	ctrl := mockBuildController()
+	ctrl.BuildStrategy = &errStrategy{}
+	ctrl.PodManager = &errPodManager{}
+	updater := &countingBuildUpdater{}
+	ctrl.BuildUpdater = updater
+	for i := 0; i < 2; i++ {
+		if err := ctrl.HandleBuild(build); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if build.Status.Phase != buildapi.BuildPhaseNew {
+		t.Errorf("expected the pipeline build to be left to an external controller, got phase %s", build.Status.Phase)
+	}
+	if build.Status.Reason != buildapi.StatusReasonPipelineControllerPending || build.Status.Message != buildapi.PipelineControllerPendingMessage {
+		t.Errorf("unexpected status: %#v", build.Status)
+	}
+	if updater.updates != 1 {
+		t.Errorf("expected the build to be updated once, got %d updates", updater.updates)
+	}
+
+	ctrl.BuildUpdater = &errBuildUpdater{}
+	build = mockBuild(buildapi.BuildPhaseNew, buildapi.BuildOutput{})
+	build.Spec.Strategy = buildapi.BuildStrategy{JenkinsPipelineStrategy: &buildapi.JenkinsPipelineBuildStrategy{}}
+	if err := ctrl.HandleBuild(build); err == nil {
+		t.Errorf("expected an error when the build cannot be updated")
+	}
+}
+
 func TestHandlePod(t *testing.T) {
 	type handlePodTest struct {
 		matchID             bool
@@ -545,6 +589,98 @@ func TestHandlePod(t *testing.T) {
 	}
 }
 
+type fakeImageStreamTagger struct {
+	namespace, stream, fromTag string
+	tags                       []string
+}
+
+func (t *fakeImageStreamTagger) TagImage(namespace, stream, fromTag string, tags []string) error {
+	t.namespace, t.stream, t.fromTag, t.tags = namespace, stream, fromTag, tags
+	return nil
+}
+
+func TestHandlePodOutputTags(t *testing.T) {
+	for _, phase := range []kapi.PodPhase{kapi.PodSucceeded, kapi.PodFailed} {
+		build := mockBuild(buildapi.BuildPhaseRunning, buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}})
+		build.Name = "data-build"
+		build.Annotations = map[string]string{buildapi.BuildOutputTagsAnnotation: "v3,stable"}
+		tagger := &fakeImageStreamTagger{}
+		ctrl := mockBuildPodController(build)
+		ctrl.ImageStreamTagger = tagger
+		exitCode := 0
+		if phase == kapi.PodFailed {
+			exitCode = 1
+		}
+		if err := ctrl.HandlePod(mockPod(phase, exitCode)); err != nil {
+			t.Fatal(err)
+		}
+		if phase == kapi.PodFailed {
+			if tagger.tags != nil {
+				t.Errorf("unexpected tags for a failed build: %v", tagger.tags)
+			}
+			continue
+		}
+		if tagger.namespace != "namespace" || tagger.stream != "app" || tagger.fromTag != "latest" || !reflect.DeepEqual(tagger.tags, []string{"v3", "stable"}) {
+			t.Errorf("unexpected tagging: %#v", tagger)
+		}
+	}
+}
+
+type fakeBuildDeleter struct {
+	deleted []string
+}
+
+func (d *fakeBuildDeleter) DeleteBuild(namespace, name string) error {
+	d.deleted = append(d.deleted, name)
+	return nil
+}
+
+func TestHandlePodPrunesBuildHistory(t *testing.T) {
+	config := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "config", Namespace: "namespace"}}
+	successful, failed := 1, 0
+	buildapi.SetBuildHistoryLimits(config, &successful, &failed)
+	history := func(version string, phase buildapi.BuildPhase, age int) buildapi.Build {
+		build := runPolicyBuild(version, phase)
+		build.CreationTimestamp = unversioned.NewTime(time.Now().Add(-time.Duration(age) * time.Hour))
+		build.Status.Config = &kapi.ObjectReference{Name: "config", Namespace: "namespace"}
+		return build
+	}
+	builds := []buildapi.Build{
+		history("1", buildapi.BuildPhaseComplete, 4),
+		history("2", buildapi.BuildPhaseFailed, 3),
+		history("3", buildapi.BuildPhaseRunning, 1),
+		history("4", buildapi.BuildPhaseComplete, 0),
+	}
+
+	build := builds[3]
+	build.Status.Phase = buildapi.BuildPhaseRunning
+	pod := mockPod(kapi.PodSucceeded, 0)
+	pod.Annotations[buildapi.BuildAnnotation] = build.Name
+	pod.Namespace = build.Namespace
+	deleter := &fakeBuildDeleter{}
+	ctrl := mockBuildPodController(&build)
+	ctrl.BuildConfigGetter = &fakeBuildConfigGetter{config: config}
+	ctrl.BuildLister = &fakeBuildLister{builds: builds}
+	ctrl.BuildDeleter = deleter
+	if err := ctrl.HandlePod(pod); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deleter.deleted, []string{"config-1", "config-2"}) {
+		t.Errorf("unexpected deleted builds: %v", deleter.deleted)
+	}
+
+	// builds of build configs without history limits are kept
+	deleter.deleted = nil
+	build.Status.Phase = buildapi.BuildPhaseRunning
+	ctrl.BuildConfigGetter = &fakeBuildConfigGetter{config: &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "config", Namespace: "namespace"}}}
+	if err := ctrl.HandlePod(pod); err != nil {
+		t.Fatal(err)
+	}
+	if len(deleter.deleted) != 0 {
+		t.Errorf("unexpected deleted builds: %v", deleter.deleted)
+	}
+}
+
 func TestCancelBuild(t *testing.T) {
 	type handleCancelBuildTest struct {
 		inStatus            buildapi.BuildPhase
@@ -936,3 +1072,120 @@ func TestHandleBuildPodDeletionBuildUpdateError(t *testing.T) {
 		t.Error("Expected random error, but got none!")
 	}
 }
+
+type fakeBuildConfigGetter struct {
+	config *buildapi.BuildConfig
+}
+
+func (g *fakeBuildConfigGetter) GetBuildConfig(namespace, name string) (*buildapi.BuildConfig, error) {
+	return g.config, nil
+}
+
+type fakeBuildLister struct {
+	builds []buildapi.Build
+}
+
+func (l *fakeBuildLister) ListBuilds(namespace string, selector labels.Selector) (*buildapi.BuildList, error) {
+	return &buildapi.BuildList{Items: l.builds}, nil
+}
+
+func runPolicyBuild(version string, phase buildapi.BuildPhase) buildapi.Build {
+	build := mockBuild(phase, buildapi.BuildOutput{})
+	build.Name = "config-" + version
+	build.Labels = map[string]string{buildapi.BuildConfigLabel: "config"}
+	build.Annotations = map[string]string{buildapi.BuildNumberAnnotation: version}
+	return *build
+}
+
+// runPolicyStores returns a build config store holding config and a build store holding builds.
+func runPolicyStores(config *buildapi.BuildConfig, builds ...buildapi.Build) (cache.Store, cache.Indexer) {
+	configs := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	configs.Add(config)
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{BuildConfigIndex: BuildConfigIndexFunc})
+	for i := range builds {
+		store.Add(&builds[i])
+	}
+	return configs, store
+}
+
+func TestHandleBuildRunPolicy(t *testing.T) {
+	tests := map[string]struct {
+		policy    buildapi.BuildRunPolicy
+		others    []buildapi.Build
+		phase     buildapi.BuildPhase
+		cancelled bool
+	}{
+		"parallel with a running build": {
+			policy: buildapi.BuildRunPolicyParallel,
+			others: []buildapi.Build{runPolicyBuild("1", buildapi.BuildPhaseRunning)},
+			phase:  buildapi.BuildPhasePending,
+		},
+		"serial with a running build": {
+			policy: buildapi.BuildRunPolicySerial,
+			others: []buildapi.Build{runPolicyBuild("1", buildapi.BuildPhaseRunning)},
+			phase:  buildapi.BuildPhaseNew,
+		},
+		"serial with an earlier new build": {
+			policy: buildapi.BuildRunPolicySerial,
+			others: []buildapi.Build{runPolicyBuild("1", buildapi.BuildPhaseNew)},
+			phase:  buildapi.BuildPhaseNew,
+		},
+		"serial with a later new build": {
+			policy: buildapi.BuildRunPolicySerial,
+			others: []buildapi.Build{runPolicyBuild("1", buildapi.BuildPhaseComplete), runPolicyBuild("3", buildapi.BuildPhaseNew)},
+			phase:  buildapi.BuildPhasePending,
+		},
+		"serial latest only with an earlier new build": {
+			policy: buildapi.BuildRunPolicySerialLatestOnly,
+			others: []buildapi.Build{runPolicyBuild("1", buildapi.BuildPhaseNew)},
+			phase:  buildapi.BuildPhasePending,
+		},
+		"serial latest only with a later new build": {
+			policy:    buildapi.BuildRunPolicySerialLatestOnly,
+			others:    []buildapi.Build{runPolicyBuild("1", buildapi.BuildPhaseRunning), runPolicyBuild("3", buildapi.BuildPhaseNew)},
+			phase:     buildapi.BuildPhaseCancelled,
+			cancelled: true,
+		},
+	}
+	for name, test := range tests {
+		config := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "config", Namespace: "namespace"}}
+		buildapi.SetRunPolicy(config, test.policy)
+		build := runPolicyBuild("2", buildapi.BuildPhaseNew)
+		ctrl := mockBuildController()
+		ctrl.BuildConfigStore, ctrl.BuildStore = runPolicyStores(config, append(test.others, build)...)
+		if err := ctrl.HandleBuild(&build); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if build.Status.Phase != test.phase || build.Status.Cancelled != test.cancelled {
+			t.Errorf("%s: unexpected status: %#v", name, build.Status)
+		}
+	}
+}
+
+func TestHandleBuildQueuesHeldBackBuilds(t *testing.T) {
+	config := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "config", Namespace: "namespace"}}
+	buildapi.SetRunPolicy(config, buildapi.BuildRunPolicySerial)
+	completed := runPolicyBuild("1", buildapi.BuildPhaseComplete)
+	ctrl := mockBuildController()
+	ctrl.BuildConfigStore, ctrl.BuildStore = runPolicyStores(config, completed, runPolicyBuild("2", buildapi.BuildPhaseNew), runPolicyBuild("3", buildapi.BuildPhaseNew))
+	queue := cache.NewFIFO(cache.MetaNamespaceKeyFunc)
+	ctrl.BuildQueue = queue
+
+	if err := ctrl.HandleBuild(&completed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := sets.NewString(queue.ListKeys()...); !keys.Equal(sets.NewString("namespace/config-2", "namespace/config-3")) {
+		t.Errorf("unexpected queued builds: %v", keys.List())
+	}
+
+	buildapi.SetRunPolicy(config, buildapi.BuildRunPolicyParallel)
+	queue = cache.NewFIFO(cache.MetaNamespaceKeyFunc)
+	ctrl.BuildQueue = queue
+	if err := ctrl.HandleBuild(&completed); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := queue.ListKeys(); len(keys) != 0 {
+		t.Errorf("unexpected queued builds: %v", keys)
+	}
+}
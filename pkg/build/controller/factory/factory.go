@@ -72,6 +72,12 @@ func (factory *BuildControllerFactory) Create() controller.RunnableController {
 	queue := cache.NewFIFO(cache.MetaNamespaceKeyFunc)
 	cache.NewReflector(&buildLW{client: factory.OSClient}, &buildapi.Build{}, queue, 2*time.Minute).RunUntil(factory.Stop)
 
+	buildStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{buildcontroller.BuildConfigIndex: buildcontroller.BuildConfigIndexFunc})
+	cache.NewReflector(&buildLW{client: factory.OSClient}, &buildapi.Build{}, buildStore, 2*time.Minute).RunUntil(factory.Stop)
+
+	buildConfigStore := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	cache.NewReflector(&buildConfigLW{client: factory.OSClient}, &buildapi.BuildConfig{}, buildConfigStore, 2*time.Minute).RunUntil(factory.Stop)
+
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartRecordingToSink(factory.KubeClient.Events(""))
 
@@ -85,7 +91,10 @@ func (factory *BuildControllerFactory) Create() controller.RunnableController {
 			SourceBuildStrategy: factory.SourceBuildStrategy,
 			CustomBuildStrategy: factory.CustomBuildStrategy,
 		},
-		Recorder: eventBroadcaster.NewRecorder(kapi.EventSource{Component: "build-controller"}),
+		Recorder:         eventBroadcaster.NewRecorder(kapi.EventSource{Component: "build-controller"}),
+		BuildConfigStore: buildConfigStore,
+		BuildStore:       buildStore,
+		BuildQueue:       queue,
 	}
 
 	return &controller.RetryController{
@@ -189,9 +198,13 @@ func (factory *BuildPodControllerFactory) Create() controller.RunnableController
 
 	client := ControllerClient{factory.KubeClient, factory.OSClient}
 	buildPodController := &buildcontroller.BuildPodController{
-		BuildStore:   factory.buildStore,
-		BuildUpdater: factory.BuildUpdater,
-		PodManager:   client,
+		BuildStore:        factory.buildStore,
+		BuildUpdater:      factory.BuildUpdater,
+		PodManager:        client,
+		ImageStreamTagger: client,
+		BuildConfigGetter: client,
+		BuildLister:       client,
+		BuildDeleter:      client,
 	}
 
 	return &controller.RetryController{
@@ -631,3 +644,45 @@ func (c ControllerClient) GetPod(namespace, name string) (*kapi.Pod, error) {
 func (c ControllerClient) GetImageStream(namespace, name string) (*imageapi.ImageStream, error) {
 	return c.Client.ImageStreams(namespace).Get(name)
 }
+
+// GetBuildConfig retrieves a build config by namespace and name
+func (c ControllerClient) GetBuildConfig(namespace, name string) (*buildapi.BuildConfig, error) {
+	return c.Client.BuildConfigs(namespace).Get(name)
+}
+
+// ListBuilds lists the builds in namespace that match selector
+func (c ControllerClient) ListBuilds(namespace string, selector labels.Selector) (*buildapi.BuildList, error) {
+	return c.Client.Builds(namespace).List(kapi.ListOptions{LabelSelector: selector})
+}
+
+// DeleteBuild deletes a build by namespace and name
+func (c ControllerClient) DeleteBuild(namespace, name string) error {
+	return c.Client.Builds(namespace).Delete(name)
+}
+
+// TagImage tags the image that fromTag of the image stream points to into tags of the same image stream. The
+// other fields of tags that already exist are preserved.
+func (c ControllerClient) TagImage(namespace, stream, fromTag string, tags []string) error {
+	istag, err := c.Client.ImageStreamTags(namespace).Get(stream, fromTag)
+	if err != nil {
+		return err
+	}
+	from := &kapi.ObjectReference{Kind: "ImageStreamImage", Name: fmt.Sprintf("%s@%s", stream, istag.Image.Name)}
+	return kclient.RetryOnConflict(kclient.DefaultRetry, func() error {
+		is, err := c.Client.ImageStreams(namespace).Get(stream)
+		if err != nil {
+			return err
+		}
+		if is.Spec.Tags == nil {
+			is.Spec.Tags = make(map[string]imageapi.TagReference)
+		}
+		for _, tag := range tags {
+			ref := is.Spec.Tags[tag]
+			ref.Name = tag
+			ref.From = from
+			is.Spec.Tags[tag] = ref
+		}
+		_, err = c.Client.ImageStreams(namespace).Update(is)
+		return err
+	})
+}
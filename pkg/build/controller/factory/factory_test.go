@@ -8,10 +8,15 @@ import (
 	"time"
 
 	kapi "k8s.io/kubernetes/pkg/api"
+	kerrors "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	ktc "k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/runtime"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
+	"github.com/openshift/origin/pkg/client/testclient"
 	controller "github.com/openshift/origin/pkg/controller"
+	imageapi "github.com/openshift/origin/pkg/image/api"
 )
 
 type buildUpdater struct {
@@ -125,3 +130,45 @@ func TestControllerRetryFunc(t *testing.T) {
 		}
 	}
 }
+
+func TestControllerClientTagImage(t *testing.T) {
+	client := testclient.NewSimpleFake()
+	client.PrependReactor("get", "imagestreamtags", func(action ktc.Action) (bool, runtime.Object, error) {
+		return true, &imageapi.ImageStreamTag{Image: imageapi.Image{ObjectMeta: kapi.ObjectMeta{Name: "sha256:abc"}}}, nil
+	})
+	client.PrependReactor("get", "imagestreams", func(action ktc.Action) (bool, runtime.Object, error) {
+		return true, &imageapi.ImageStream{
+			ObjectMeta: kapi.ObjectMeta{Name: "app", Namespace: "test"},
+			Spec: imageapi.ImageStreamSpec{Tags: map[string]imageapi.TagReference{
+				"v1": {Name: "v1", Annotations: map[string]string{"description": "first"}, Reference: true},
+			}},
+		}, nil
+	})
+	var updated *imageapi.ImageStream
+	conflicts := 1
+	client.PrependReactor("update", "imagestreams", func(action ktc.Action) (bool, runtime.Object, error) {
+		if conflicts > 0 {
+			conflicts--
+			return true, nil, kerrors.NewConflict(imageapi.Resource("imagestreams"), "app", errors.New("changed"))
+		}
+		updated = action.(ktc.UpdateAction).GetObject().(*imageapi.ImageStream)
+		return true, updated, nil
+	})
+
+	c := ControllerClient{Client: client}
+	if err := c.TagImage("test", "app", "latest", []string{"v1", "v2"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated == nil {
+		t.Fatalf("expected the image stream to be updated after the conflict")
+	}
+	for _, tag := range []string{"v1", "v2"} {
+		ref := updated.Spec.Tags[tag]
+		if ref.Name != tag || ref.From == nil || ref.From.Kind != "ImageStreamImage" || ref.From.Name != "app@sha256:abc" {
+			t.Errorf("%s: unexpected tag: %#v", tag, ref)
+		}
+	}
+	if v1 := updated.Spec.Tags["v1"]; v1.Annotations["description"] != "first" || !v1.Reference {
+		t.Errorf("expected the existing tag to keep its fields: %#v", v1)
+	}
+}
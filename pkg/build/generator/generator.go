@@ -409,6 +409,9 @@ func (g *BuildGenerator) generateBuildFromConfig(ctx kapi.Context, bc *buildapi.
 		build.Annotations = make(map[string]string)
 	}
 	build.Annotations[buildapi.BuildNumberAnnotation] = strconv.Itoa(bc.Status.LastVersion)
+	if tags := buildapi.OutputTagsForBuild(bc, bc.Status.LastVersion); len(tags) > 0 {
+		build.Annotations[buildapi.BuildOutputTagsAnnotation] = strings.Join(tags, ",")
+	}
 	if build.Labels == nil {
 		build.Labels = make(map[string]string)
 	}
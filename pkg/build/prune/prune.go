@@ -49,6 +49,20 @@ func NewPruneTasker(buildConfigs []*buildapi.BuildConfig, builds []*buildapi.Bui
 	}
 }
 
+// NewHistoryLimitPruneTasker returns a PruneTasker that prunes the builds of buildConfig exceeding the history
+// limits set by its annotations. Builds belonging to other build configs are ignored.
+func NewHistoryLimitPruneTasker(buildConfig *buildapi.BuildConfig, builds []*buildapi.Build, handler PruneFunc) (PruneTasker, error) {
+	keepComplete, keepFailed, err := buildapi.BuildHistoryLimits(buildConfig)
+	if err != nil {
+		return nil, err
+	}
+	dataSet := NewDataSet([]*buildapi.BuildConfig{buildConfig}, builds)
+	return &pruneTask{
+		resolver: NewPerBuildConfigResolver(dataSet, keepComplete, keepFailed),
+		handler:  handler,
+	}, nil
+}
+
 // PruneTask will visit each item in the prunable set and invoke the associated handler
 func (t *pruneTask) PruneTask() error {
 	builds, err := t.resolver.Resolve()
@@ -99,3 +99,34 @@ func TestPruneTask(t *testing.T) {
 	}
 
 }
+
+func TestHistoryLimitPruneTask(t *testing.T) {
+	successful, failed := 1, 0
+	buildConfig := mockBuildConfig("a", "build-config")
+	buildapi.SetBuildHistoryLimits(buildConfig, &successful, &failed)
+
+	now := unversioned.Now()
+	builds := []*buildapi.Build{
+		withCreated(withStatus(mockBuild("a", "complete-1", buildConfig), buildapi.BuildPhaseComplete), unversioned.NewTime(now.Add(-3*time.Minute))),
+		withCreated(withStatus(mockBuild("a", "complete-2", buildConfig), buildapi.BuildPhaseComplete), unversioned.NewTime(now.Add(-2*time.Minute))),
+		withCreated(withStatus(mockBuild("a", "complete-3", buildConfig), buildapi.BuildPhaseComplete), now),
+		withCreated(withStatus(mockBuild("a", "failed-1", buildConfig), buildapi.BuildPhaseFailed), now),
+		withCreated(withStatus(mockBuild("a", "running-1", buildConfig), buildapi.BuildPhaseRunning), now),
+		withCreated(withStatus(mockBuild("a", "orphan-1", nil), buildapi.BuildPhaseComplete), now),
+	}
+
+	recorder := &mockPruneRecorder{set: sets.String{}}
+	task, err := NewHistoryLimitPruneTasker(buildConfig, builds, recorder.Handler)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := task.PruneTask(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	recorder.Verify(t, sets.NewString("complete-1", "complete-2", "failed-1"))
+
+	buildConfig.Annotations[buildapi.BuildConfigFailedBuildsHistoryLimitAnnotation] = "-1"
+	if _, err := NewHistoryLimitPruneTasker(buildConfig, builds, recorder.Handler); err == nil {
+		t.Errorf("expected an error for an invalid limit")
+	}
+}
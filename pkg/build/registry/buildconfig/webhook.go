@@ -5,8 +5,11 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/golang/glog"
+
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/errors"
+	kclient "k8s.io/kubernetes/pkg/client/unversioned"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
 	"github.com/openshift/origin/pkg/build/client"
@@ -14,10 +17,13 @@ import (
 	"github.com/openshift/origin/pkg/util/rest"
 )
 
-func NewWebHookREST(registry Registry, instantiator client.BuildConfigInstantiator, plugins map[string]webhook.Plugin) *rest.WebHook {
+// NewWebHookREST returns the webhooks of build configs. The secrets of webhook triggers that reference a Secret are
+// read with secrets.
+func NewWebHookREST(registry Registry, instantiator client.BuildConfigInstantiator, secrets kclient.SecretsNamespacer, plugins map[string]webhook.Plugin) *rest.WebHook {
 	controller := &controller{
 		registry:     registry,
 		instantiator: instantiator,
+		secrets:      secrets,
 		plugins:      plugins,
 	}
 	return rest.NewWebHook(controller, false)
@@ -26,6 +32,7 @@ func NewWebHookREST(registry Registry, instantiator client.BuildConfigInstantiat
 type controller struct {
 	registry     Registry
 	instantiator client.BuildConfigInstantiator
+	secrets      kclient.SecretsNamespacer
 	plugins      map[string]webhook.Plugin
 }
 
@@ -49,6 +56,8 @@ func (c *controller) ServeHTTP(w http.ResponseWriter, req *http.Request, ctx kap
 		return errors.NewUnauthorized(fmt.Sprintf("the webhook %q for %q did not accept your secret", hookType, name))
 	}
 
+	c.resolveSecretReferences(config)
+
 	revision, proceed, err := plugin.Extract(config, secret, "", req)
 	switch err {
 	case webhook.ErrSecretMismatch, webhook.ErrHookNotEnabled:
@@ -71,3 +80,39 @@ func (c *controller) ServeHTTP(w http.ResponseWriter, req *http.Request, ctx kap
 	}
 	return nil
 }
+
+// resolveSecretReferences sets the secret of the webhook triggers of config that reference a Secret to the
+// buildapi.WebHookSecretKey value of the Secret. Triggers whose Secret cannot be read or has no value are removed,
+// so that they accept no request.
+func (c *controller) resolveSecretReferences(config *buildapi.BuildConfig) {
+	triggers := make([]buildapi.BuildTriggerPolicy, 0, len(config.Spec.Triggers))
+	for _, trigger := range config.Spec.Triggers {
+		hook := trigger.GitHubWebHook
+		if trigger.Type == buildapi.GenericWebHookBuildTriggerType {
+			hook = trigger.GenericWebHook
+		}
+		if hook == nil || hook.SecretReference == nil {
+			triggers = append(triggers, trigger)
+			continue
+		}
+		name := hook.SecretReference.Name
+		secret, err := c.secrets.Secrets(config.Namespace).Get(name)
+		if err != nil {
+			glog.V(2).Infof("Unable to read the webhook secret %s/%s of build config %s: %v", config.Namespace, name, config.Name, err)
+			continue
+		}
+		value := secret.Data[buildapi.WebHookSecretKey]
+		if len(value) == 0 {
+			glog.V(2).Infof("The webhook secret %s/%s of build config %s has no %s value", config.Namespace, name, config.Name, buildapi.WebHookSecretKey)
+			continue
+		}
+		resolved := &buildapi.WebHookTrigger{Secret: string(value)}
+		if trigger.Type == buildapi.GenericWebHookBuildTriggerType {
+			trigger.GenericWebHook = resolved
+		} else {
+			trigger.GitHubWebHook = resolved
+		}
+		triggers = append(triggers, trigger)
+	}
+	config.Spec.Triggers = triggers
+}
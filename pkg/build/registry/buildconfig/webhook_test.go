@@ -10,6 +10,7 @@ import (
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/runtime"
 
 	"github.com/openshift/origin/pkg/build/api"
@@ -31,18 +32,19 @@ func (i *buildConfigInstantiator) Instantiate(namespace string, request *api.Bui
 
 type plugin struct {
 	Secret, Path string
+	Config       *api.BuildConfig
 	Err          error
 }
 
 func (p *plugin) Extract(buildCfg *api.BuildConfig, secret, path string, req *http.Request) (*api.SourceRevision, bool, error) {
-	p.Secret, p.Path = secret, path
+	p.Secret, p.Path, p.Config = secret, path, buildCfg
 	return nil, true, p.Err
 }
 
-func newStorage() (*rest.WebHook, *buildConfigInstantiator, *test.BuildConfigRegistry) {
-	mockRegistry := &test.BuildConfigRegistry{}
+func newStorage(secrets ...runtime.Object) (*rest.WebHook, *buildConfigInstantiator, *test.BuildConfigRegistry) {
+	mockRegistry := &test.BuildConfigRegistry{BuildConfig: &api.BuildConfig{}}
 	bci := &buildConfigInstantiator{}
-	hook := NewWebHookREST(mockRegistry, bci, map[string]webhook.Plugin{
+	hook := NewWebHookREST(mockRegistry, bci, testclient.NewSimpleFake(secrets...), map[string]webhook.Plugin{
 		"ok":        &plugin{},
 		"errsecret": &plugin{Err: webhook.ErrSecretMismatch},
 		"errhook":   &plugin{Err: webhook.ErrHookNotEnabled},
@@ -165,3 +167,52 @@ func TestConnectWebHook(t *testing.T) {
 		}
 	}
 }
+
+func TestWebHookSecretReferences(t *testing.T) {
+	secret := &kapi.Secret{
+		ObjectMeta: kapi.ObjectMeta{Name: "webhook", Namespace: "default"},
+		Data:       map[string][]byte{api.WebHookSecretKey: []byte("secret101")},
+	}
+	empty := &kapi.Secret{ObjectMeta: kapi.ObjectMeta{Name: "empty", Namespace: "default"}}
+	config := &api.BuildConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: api.BuildConfigSpec{
+			Triggers: []api.BuildTriggerPolicy{
+				{Type: api.GitHubWebHookBuildTriggerType, GitHubWebHook: &api.WebHookTrigger{SecretReference: &kapi.LocalObjectReference{Name: "webhook"}}},
+				{Type: api.GenericWebHookBuildTriggerType, GenericWebHook: &api.WebHookTrigger{SecretReference: &kapi.LocalObjectReference{Name: "missing"}}},
+				{Type: api.GenericWebHookBuildTriggerType, GenericWebHook: &api.WebHookTrigger{SecretReference: &kapi.LocalObjectReference{Name: "empty"}}},
+				{Type: api.GenericWebHookBuildTriggerType, GenericWebHook: &api.WebHookTrigger{Secret: "inline"}},
+			},
+		},
+	}
+	secrets := &testclient.Fake{}
+	secrets.AddReactor("get", "secrets", func(action testclient.Action) (bool, runtime.Object, error) {
+		for _, secret := range []*kapi.Secret{secret, empty} {
+			if secret.Name == action.(testclient.GetAction).GetName() {
+				return true, secret, nil
+			}
+		}
+		return true, nil, errors.NewNotFound(kapi.Resource("secrets"), action.(testclient.GetAction).GetName())
+	})
+	recorder := &plugin{}
+	hook := NewWebHookREST(&test.BuildConfigRegistry{BuildConfig: config}, &buildConfigInstantiator{}, secrets, map[string]webhook.Plugin{"record": recorder})
+	handler, err := hook.Connect(kapi.NewDefaultContext(), "test", &kapi.PodProxyOptions{Path: "secret101/record"}, &fakeResponder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), &http.Request{})
+
+	if recorder.Config == nil {
+		t.Fatalf("the hook was not invoked")
+	}
+	triggers := recorder.Config.Spec.Triggers
+	if len(triggers) != 2 {
+		t.Fatalf("expected the triggers with unreadable secrets to be removed: %#v", triggers)
+	}
+	if triggers[0].GitHubWebHook.Secret != "secret101" || triggers[0].GitHubWebHook.SecretReference != nil {
+		t.Errorf("unexpected resolved trigger: %#v", triggers[0].GitHubWebHook)
+	}
+	if triggers[1].GenericWebHook.Secret != "inline" {
+		t.Errorf("unexpected inline trigger: %#v", triggers[1].GenericWebHook)
+	}
+}
@@ -15,6 +15,18 @@ import (
 // that is not a webhook type.
 var ErrTriggerIsNotAWebHook = fmt.Errorf("the specified trigger is not a webhook")
 
+// WebHookSecretPlaceholder stands for the secret in the URL of a webhook trigger whose secret is read from a
+// Secret, since clients may not be able to read it.
+const WebHookSecretPlaceholder = "SECRET"
+
+// WebHookURLSecret returns the secret that goes in the URL of a webhook trigger.
+func WebHookURLSecret(hook *buildapi.WebHookTrigger) string {
+	if hook.SecretReference != nil {
+		return WebHookSecretPlaceholder
+	}
+	return hook.Secret
+}
+
 // BuildConfigsNamespacer has methods to work with BuildConfig resources in a namespace
 type BuildConfigsNamespacer interface {
 	BuildConfigs(namespace string) BuildConfigInterface
@@ -73,9 +85,9 @@ func (c *buildConfigs) Get(name string) (result *buildapi.BuildConfig, err error
 func (c *buildConfigs) WebHookURL(name string, trigger *buildapi.BuildTriggerPolicy) (*url.URL, error) {
 	switch {
 	case trigger.GenericWebHook != nil:
-		return c.r.Get().Namespace(c.ns).Resource("buildConfigs").Name(name).SubResource("webhooks").Suffix(trigger.GenericWebHook.Secret, "generic").URL(), nil
+		return c.r.Get().Namespace(c.ns).Resource("buildConfigs").Name(name).SubResource("webhooks").Suffix(WebHookURLSecret(trigger.GenericWebHook), "generic").URL(), nil
 	case trigger.GitHubWebHook != nil:
-		return c.r.Get().Namespace(c.ns).Resource("buildConfigs").Name(name).SubResource("webhooks").Suffix(trigger.GitHubWebHook.Secret, "github").URL(), nil
+		return c.r.Get().Namespace(c.ns).Resource("buildConfigs").Name(name).SubResource("webhooks").Suffix(WebHookURLSecret(trigger.GitHubWebHook), "github").URL(), nil
 	default:
 		return nil, ErrTriggerIsNotAWebHook
 	}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+	kapi "k8s.io/kubernetes/pkg/api"
+	apierrs "k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/fields"
+	"k8s.io/kubernetes/pkg/watch"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// TagImportError is returned when the import of a tag fails.
+type TagImportError struct {
+	Tag     string
+	Reason  string
+	Message string
+}
+
+func (e TagImportError) Error() string {
+	if len(e.Reason) == 0 {
+		return fmt.Sprintf("unable to import tag %q: %s", e.Tag, e.Message)
+	}
+	return fmt.Sprintf("unable to import tag %q: %s: %s", e.Tag, e.Reason, e.Message)
+}
+
+// ImportTag imports the image referenced by from into tag of the image stream name right away, and returns the
+// image stream once its status records the result. Servers that import images directly update the status within
+// the request. On older servers the tag is set on the image stream, creating it if necessary, and the status is
+// watched until the import controller has observed the tag or ctx is done. A failed import returns a
+// TagImportError.
+func ImportTag(ctx context.Context, streams ImageStreamInterface, name, tag string, from kapi.ObjectReference, insecure bool) (*imageapi.ImageStream, error) {
+	isi := &imageapi.ImageStreamImport{
+		ObjectMeta: kapi.ObjectMeta{Name: name},
+		Spec: imageapi.ImageStreamImportSpec{
+			Import: true,
+			Images: []imageapi.ImageImportSpec{{
+				From:         from,
+				To:           &kapi.LocalObjectReference{Name: tag},
+				ImportPolicy: imageapi.TagImportPolicy{Insecure: insecure},
+			}},
+		},
+	}
+	result, err := streams.Import(isi)
+	switch {
+	case err == ErrImageStreamImportUnsupported:
+		return importTagLegacy(ctx, streams, name, tag, from, insecure)
+	case err != nil:
+		return nil, err
+	}
+	if len(result.Status.Images) > 0 {
+		if status := result.Status.Images[0].Status; status.Status != unversioned.StatusSuccess {
+			return nil, TagImportError{Tag: tag, Reason: string(status.Reason), Message: status.Message}
+		}
+	}
+	if result.Status.Import != nil {
+		return result.Status.Import, nil
+	}
+	return streams.Get(name)
+}
+
+// importTagLegacy points tag at from in the spec of the image stream and waits for the import controller.
+func importTagLegacy(ctx context.Context, streams ImageStreamInterface, name, tag string, from kapi.ObjectReference, insecure bool) (*imageapi.ImageStream, error) {
+	stream, err := streams.Get(name)
+	switch {
+	case apierrs.IsNotFound(err):
+		stream = &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: name}}
+	case err != nil:
+		return nil, err
+	}
+	if stream.Spec.Tags == nil {
+		stream.Spec.Tags = make(map[string]imageapi.TagReference)
+	}
+	tagRef := stream.Spec.Tags[tag]
+	tagRef.From = &from
+	// reset the generation so the server records a new one
+	zero := int64(0)
+	tagRef.Generation = &zero
+	stream.Spec.Tags[tag] = tagRef
+	if insecure {
+		if stream.Annotations == nil {
+			stream.Annotations = make(map[string]string)
+		}
+		stream.Annotations[imageapi.InsecureRepositoryAnnotation] = "true"
+	}
+
+	if stream.CreationTimestamp.IsZero() {
+		stream, err = streams.Create(stream)
+	} else {
+		stream, err = streams.Update(stream)
+	}
+	if err != nil {
+		return nil, err
+	}
+	generation := stream.Generation
+	if tagRef, ok := stream.Spec.Tags[tag]; ok && tagRef.Generation != nil {
+		generation = *tagRef.Generation
+	}
+	return WaitForTagImport(ctx, streams, stream, tag, generation)
+}
+
+// WaitForTagImport waits until the import controller has observed generation of tag on the image stream, and
+// returns the image stream. The watch starts from the resource version of stream. A failed import returns a
+// TagImportError, and ctx being done returns its error.
+func WaitForTagImport(ctx context.Context, streams ImageStreamInterface, stream *imageapi.ImageStream, tag string, generation int64) (*imageapi.ImageStream, error) {
+	if done, err := tagImported(stream, tag, generation); done {
+		return stream, err
+	}
+	w, err := streams.Watch(kapi.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", stream.Name), ResourceVersion: stream.ResourceVersion})
+	if err != nil {
+		return nil, err
+	}
+	defer w.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return nil, fmt.Errorf("image stream watch ended prematurely")
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				s, ok := event.Object.(*imageapi.ImageStream)
+				if !ok {
+					continue
+				}
+				if done, err := tagImported(s, tag, generation); done {
+					return s, err
+				}
+			case watch.Deleted:
+				return nil, fmt.Errorf("the image stream was deleted")
+			case watch.Error:
+				return nil, fmt.Errorf("error watching image stream")
+			}
+		}
+	}
+}
+
+// tagImported returns true if the status of stream records the import of generation of tag, and an error if
+// that import failed.
+func tagImported(stream *imageapi.ImageStream, tag string, generation int64) (bool, error) {
+	if imageapi.LatestObservedTagGeneration(stream, tag) < generation {
+		return false, nil
+	}
+	for _, condition := range stream.Status.Tags[tag].Conditions {
+		if condition.Type == imageapi.ImportSuccess && condition.Status == kapi.ConditionFalse && condition.Generation >= generation {
+			return true, TagImportError{Tag: tag, Reason: condition.Reason, Message: condition.Message}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/watch"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// fakeImageStreams records the image stream saved by the legacy import path and serves a fake watch.
+type fakeImageStreams struct {
+	ImageStreamInterface
+
+	importResult *imageapi.ImageStreamImport
+	importErr    error
+	saved        *imageapi.ImageStream
+	watcher      *watch.FakeWatcher
+}
+
+func (c *fakeImageStreams) Import(isi *imageapi.ImageStreamImport) (*imageapi.ImageStreamImport, error) {
+	return c.importResult, c.importErr
+}
+
+func (c *fakeImageStreams) Get(name string) (*imageapi.ImageStream, error) {
+	return nil, errors.NewNotFound(imageapi.Resource("imagestreams"), name)
+}
+
+func (c *fakeImageStreams) Create(stream *imageapi.ImageStream) (*imageapi.ImageStream, error) {
+	copied := *stream
+	copied.ResourceVersion = "1"
+	copied.Generation = 1
+	tagRef := copied.Spec.Tags["latest"]
+	generation := int64(1)
+	tagRef.Generation = &generation
+	copied.Spec.Tags = map[string]imageapi.TagReference{"latest": tagRef}
+	c.saved = &copied
+	return &copied, nil
+}
+
+func (c *fakeImageStreams) Watch(opts kapi.ListOptions) (watch.Interface, error) {
+	return c.watcher, nil
+}
+
+func TestImportTagDirect(t *testing.T) {
+	from := kapi.ObjectReference{Kind: "DockerImage", Name: "mysql:latest"}
+	stream := &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "mysql"}}
+	streams := &fakeImageStreams{importResult: &imageapi.ImageStreamImport{
+		Status: imageapi.ImageStreamImportStatus{
+			Import: stream,
+			Images: []imageapi.ImageImportStatus{{Status: unversioned.Status{Status: unversioned.StatusSuccess}}},
+		},
+	}}
+	if result, err := ImportTag(context.Background(), streams, "mysql", "latest", from, false); err != nil || result != stream {
+		t.Errorf("unexpected result: %#v %v", result, err)
+	}
+
+	streams.importResult.Status.Images[0].Status = unversioned.Status{Status: unversioned.StatusFailure, Reason: unversioned.StatusReasonNotFound, Message: "not found"}
+	if _, err := ImportTag(context.Background(), streams, "mysql", "latest", from, false); err == nil {
+		t.Errorf("expected an error")
+	} else if e, ok := err.(TagImportError); !ok || e.Reason != string(unversioned.StatusReasonNotFound) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestImportTagLegacy(t *testing.T) {
+	tests := map[string]struct {
+		status  imageapi.TagEventList
+		failure bool
+	}{
+		"imported": {
+			status: imageapi.TagEventList{Items: []imageapi.TagEvent{{Image: "sha256:abc", Generation: 1}}},
+		},
+		"failed": {
+			status: imageapi.TagEventList{Conditions: []imageapi.TagEventCondition{
+				{Type: imageapi.ImportSuccess, Status: kapi.ConditionFalse, Reason: "NotFound", Message: "not found", Generation: 1},
+			}},
+			failure: true,
+		},
+	}
+	for name, test := range tests {
+		streams := &fakeImageStreams{importErr: ErrImageStreamImportUnsupported, watcher: watch.NewFake()}
+		go func() {
+			// an update that precedes the import is ignored
+			streams.watcher.Modify(&imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "mysql"}})
+			streams.watcher.Modify(&imageapi.ImageStream{
+				ObjectMeta: kapi.ObjectMeta{Name: "mysql"},
+				Status:     imageapi.ImageStreamStatus{Tags: map[string]imageapi.TagEventList{"latest": test.status}},
+			})
+		}()
+		from := kapi.ObjectReference{Kind: "DockerImage", Name: "mysql:latest"}
+		result, err := ImportTag(context.Background(), streams, "mysql", "latest", from, true)
+		if _, ok := err.(TagImportError); ok != test.failure {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if !test.failure && len(result.Status.Tags["latest"].Items) != 1 {
+			t.Errorf("%s: unexpected stream: %#v", name, result)
+		}
+		if saved := streams.saved; saved == nil || saved.Spec.Tags["latest"].From.Name != "mysql:latest" || saved.Annotations[imageapi.InsecureRepositoryAnnotation] != "true" {
+			t.Errorf("%s: unexpected saved stream: %#v", name, saved)
+		}
+	}
+}
+
+func TestWaitForTagImportCanceled(t *testing.T) {
+	streams := &fakeImageStreams{watcher: watch.NewFake()}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	stream := &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "mysql"}}
+	if _, err := WaitForTagImport(ctx, streams, stream, "latest", 1); err != context.DeadlineExceeded {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -68,9 +68,9 @@ func (c *FakeBuildConfigs) Watch(opts kapi.ListOptions) (watch.Interface, error)
 func (c *FakeBuildConfigs) WebHookURL(name string, trigger *buildapi.BuildTriggerPolicy) (*url.URL, error) {
 	switch {
 	case trigger.GenericWebHook != nil:
-		return url.Parse(fmt.Sprintf("http://localhost/buildConfigHooks/%s/%s/generic", name, trigger.GenericWebHook.Secret))
+		return url.Parse(fmt.Sprintf("http://localhost/buildConfigHooks/%s/%s/generic", name, client.WebHookURLSecret(trigger.GenericWebHook)))
 	case trigger.GitHubWebHook != nil:
-		return url.Parse(fmt.Sprintf("http://localhost/buildConfigHooks/%s/%s/github", name, trigger.GitHubWebHook.Secret))
+		return url.Parse(fmt.Sprintf("http://localhost/buildConfigHooks/%s/%s/github", name, client.WebHookURLSecret(trigger.GitHubWebHook)))
 	default:
 		return nil, client.ErrTriggerIsNotAWebHook
 	}
@@ -28,6 +28,7 @@ import (
 	"k8s.io/kubernetes/pkg/util/wait"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
+	"github.com/openshift/origin/pkg/client"
 	cmdutil "github.com/openshift/origin/pkg/cmd/util"
 	ocmdutil "github.com/openshift/origin/pkg/cmd/util"
 	"github.com/openshift/origin/pkg/cmd/util/clientcmd"
@@ -101,7 +102,16 @@ You can use '%[1]s status' to check the progress.`
   $ %[1]s new-app --search --template=ruby
 
   # Search for "ruby" in stored templates and print the output as an YAML
-  $ %[1]s new-app --search --template=ruby --output=yaml`
+  $ %[1]s new-app --search --template=ruby --output=yaml
+
+  # Show the candidates for "ruby" ranked by score, and why each was or was not selected
+  $ %[1]s new-app --suggest ruby
+
+  # Print the objects, warnings and decisions of an application as a JSON report without creating it
+  $ %[1]s new-app https://github.com/openshift/ruby-hello-world --output=report
+
+  # Create the components of the payments-stack bundle of the catalog in ./catalog.yaml
+  $ %[1]s new-app bundle://my-catalog/payments-stack --catalog=my-catalog=./catalog.yaml`
 
 	newAppNoInput = `You must specify one or more images, image streams, templates, or source code locations to create an application.
 
@@ -145,29 +155,38 @@ func NewCmdNewApplication(fullName string, f *clientcmd.Factory, out io.Writer)
 	cmd.Flags().BoolVar(&config.AsTestDeployment, "as-test", config.AsTestDeployment, "If true create this application as a test deployment, which validates that the deployment succeeds and then scales down.")
 	cmd.Flags().StringSliceVar(&config.SourceRepositories, "code", config.SourceRepositories, "Source code to use to build this application.")
 	cmd.Flags().StringVar(&config.ContextDir, "context-dir", "", "Context directory to be used for the build.")
+	cmd.Flags().StringVar(&config.ComposeFile, "compose-file", "", "Path to a docker-compose file of version 2 or 3 whose services are added to the app.")
 	cmd.Flags().StringSliceVarP(&config.ImageStreams, "image", "", config.ImageStreams, "Name of an image stream to use in the app. (deprecated)")
 	cmd.Flags().StringSliceVarP(&config.ImageStreams, "image-stream", "i", config.ImageStreams, "Name of an image stream to use in the app.")
 	cmd.Flags().StringSliceVar(&config.DockerImages, "docker-image", config.DockerImages, "Name of a Docker image to include in the app.")
 	cmd.Flags().StringSliceVar(&config.Templates, "template", config.Templates, "Name of a stored template to use in the app.")
 	cmd.Flags().StringSliceVarP(&config.TemplateFiles, "file", "f", config.TemplateFiles, "Path to a template file to use for the app.")
+	cmd.Flags().StringSliceVar(&config.Catalogs, "catalog", config.Catalogs, "Name the file of a catalog that bundle:// arguments are resolved in, as <name>=<file>. Catalogs are also read from ~/.kube/catalogs/<name>.yaml.")
 	cmd.Flags().StringSliceVarP(&config.TemplateParameters, "param", "p", config.TemplateParameters, "Specify a list of key value pairs (e.g., -p FOO=BAR,BAR=FOO) to set/override parameter values in the template.")
 	cmd.Flags().StringSliceVar(&config.Groups, "group", config.Groups, "Indicate components that should be grouped together as <comp1>+<comp2>.")
-	cmd.Flags().StringSliceVarP(&config.Environment, "env", "e", config.Environment, "Specify key value pairs of environment variables to set into each container.")
+	cmd.Flags().StringSliceVarP(&config.Environment, "env", "e", config.Environment, "Specify key value pairs of environment variables to set into each container. Values of the form secret:NAME:KEY, config:NAME:KEY or field:PATH are read from a secret, a config map or a field of the pod; escape the prefix with a backslash to set such a value literally.")
 	cmd.Flags().StringVar(&config.Name, "name", "", "Set name to use for generated application artifacts")
-	cmd.Flags().StringVar(&config.Strategy, "strategy", "", "Specify the build strategy to use if you don't want to detect (docker|source).")
+	cmd.Flags().StringVar(&config.Strategy, "strategy", "", "Specify the build strategy to use if you don't want to detect (docker|pipeline|source). The pipeline strategy is never detected and requires an external controller to run the builds.")
 	cmd.Flags().StringP("labels", "l", "", "Label to set in all resources for this application.")
+	cmd.Flags().String("spec", "", "Path to a YAML or JSON app spec file describing the application. Flags and arguments override the spec.")
+	cmd.Flags().StringVar(&config.SourceSecret, "source-secret", "", "Name of an existing secret used to clone private source repositories and set as the source secret of the generated build configs.")
 	cmd.Flags().BoolVar(&config.InsecureRegistry, "insecure-registry", false, "If true, indicates that the referenced Docker images are on insecure registries and should bypass certificate checking")
 	cmd.Flags().BoolVarP(&config.AsList, "list", "L", false, "List all local templates and image streams that can be used to create.")
 	cmd.Flags().BoolVarP(&config.AsSearch, "search", "S", false, "Search all templates, image streams, and Docker images that match the arguments provided.")
+	cmd.Flags().BoolVar(&config.AsSuggest, "suggest", false, "List the candidates of every argument ranked by score, with the reason each was or was not selected.")
 	cmd.Flags().BoolVar(&config.AllowMissingImages, "allow-missing-images", false, "If true, indicates that referenced Docker images that cannot be found locally or in a registry should still be used.")
 	cmd.Flags().BoolVar(&config.AllowSecretUse, "grant-install-rights", false, "If true, a component that requires access to your account may use your token to install software into your project. Only grant images you trust the right to run with your token.")
 	cmd.Flags().BoolVar(&config.SkipGeneration, "no-install", false, "Do not attempt to run images that describe themselves as being installable")
+	cmd.Flags().BoolVar(&config.NoRoute, "no-route", false, "If true, do not generate routes for the services that expose port 80, 8080 or 443.")
 	cmd.Flags().BoolVar(&config.DryRun, "dry-run", false, "If true, do not actually create resources.")
+	cmd.Flags().Bool("resolution-cache", false, "If true, cache the images and image streams that arguments resolve to in "+newcmd.DefaultResolutionCacheDir()+" and reuse them in later invocations by the same user against the same server.")
+	cmd.Flags().Duration("resolution-cache-ttl", newapp.DefaultResolutionCacheTTL, "The time cached resolutions remain valid when --resolution-cache is set.")
+	cmd.Flags().Bool("refresh-resolution-cache", false, "If true, ignore cached resolutions and replace them with fresh ones when --resolution-cache is set.")
 
 	// TODO AddPrinterFlags disabled so that it doesn't conflict with our own "template" flag.
 	// Need a better solution.
 	// kcmdutil.AddPrinterFlags(cmd)
-	cmd.Flags().StringP("output", "o", "", "Output format. One of: json|yaml|template|templatefile.")
+	cmd.Flags().StringP("output", "o", "", "Output format. One of: json|yaml|template|templatefile|report.")
 	cmd.Flags().String("output-version", "", "Output the formatted object with the given version (default api-version).")
 	cmd.Flags().Bool("no-headers", false, "When using the default output, don't print headers.")
 	cmd.Flags().String("output-template", "", "Template string or path to template file to use when -o=template or -o=templatefile.  The template format is golang templates [http://golang.org/pkg/text/template/#pkg-overview]")
@@ -184,7 +203,27 @@ func RunNewApplication(fullName string, f *clientcmd.Factory, out io.Writer, c *
 		return err
 	}
 
+	if path := kcmdutil.GetFlagString(c, "spec"); len(path) > 0 {
+		spec, err := newcmd.ReadAppSpec(path)
+		if err != nil {
+			return err
+		}
+		spec.Apply(config)
+	}
+
+	if config.AsSuggest {
+		recommendations, err := config.RunRecommendations()
+		if err != nil {
+			return handleRunError(c, err, fullName)
+		}
+		printHumanReadableRecommendations(recommendations, out)
+		return nil
+	}
+
 	if config.Querying() {
+		if output == "report" {
+			return kcmdutil.UsageError(c, "--output=report cannot be used with --search or --list")
+		}
 		result, err := config.RunQuery()
 		if err != nil {
 			return handleRunError(c, err, fullName)
@@ -209,12 +248,12 @@ func RunNewApplication(fullName string, f *clientcmd.Factory, out io.Writer, c *
 		return err
 	}
 
+	// the labels requested with --labels were added during generation
 	if len(config.Labels) == 0 && len(result.Name) > 0 {
 		config.Labels = map[string]string{"app": result.Name}
-	}
-
-	if err := setLabels(config.Labels, result); err != nil {
-		return err
+		if err := setLabels(config.Labels, result); err != nil {
+			return err
+		}
 	}
 
 	if err := setAnnotations(map[string]string{newcmd.GeneratedByNamespace: newcmd.GeneratedByNewApp}, result); err != nil {
@@ -225,6 +264,8 @@ func RunNewApplication(fullName string, f *clientcmd.Factory, out io.Writer, c *
 	switch {
 	case shortOutput:
 		indent = ""
+	case output == "report":
+		return newcmd.WriteAppReport(out, result)
 	case len(output) != 0:
 		result.List.Items, err = ocmdutil.ConvertItemsForDisplayFromDefaultCommand(c, result.List.Items)
 		if err != nil {
@@ -263,39 +304,15 @@ func RunNewApplication(fullName string, f *clientcmd.Factory, out io.Writer, c *
 		fmt.Fprintf(out, "--> Success\n")
 	}
 
-	hasMissingRepo := false
 	installing := []*kapi.Pod{}
 	for _, item := range result.List.Items {
-		switch t := item.(type) {
-		case *kapi.Pod:
-			if t.Annotations[newcmd.GeneratedForJob] == "true" {
-				installing = append(installing, t)
-			}
-		case *buildapi.BuildConfig:
-			triggered := false
-			for _, trigger := range t.Spec.Triggers {
-				switch trigger.Type {
-				case buildapi.ImageChangeBuildTriggerType, buildapi.ConfigChangeBuildTriggerType:
-					triggered = true
-					break
-				}
-			}
-			if triggered {
-				fmt.Fprintf(out, "%sBuild scheduled for %q, use 'oc logs' to track its progress.\n", indent, t.Name)
-			} else {
-				fmt.Fprintf(out, "%sBuild config %q does not include any automatic triggers, use 'oc start-build' to start a build.\n", indent, t.Name)
-			}
-		case *imageapi.ImageStream:
-			if len(t.Status.DockerImageRepository) == 0 {
-				if hasMissingRepo {
-					continue
-				}
-				hasMissingRepo = true
-				fmt.Fprintf(out, "%sWARNING: No Docker registry has been configured with the server. Automatic builds and deployments may not function.\n", indent)
-			}
+		if t, ok := item.(*kapi.Pod); ok && t.Annotations[newcmd.GeneratedForJob] == "true" {
+			installing = append(installing, t)
 		}
 	}
 
+	newcmd.DescribeNextSteps(out, result, newcmd.SummaryOptions{Indent: indent, CommandName: fullName})
+
 	if shortOutput {
 		return nil
 	}
@@ -410,11 +427,16 @@ func installationComplete(c kclient.PodInterface, name string, out io.Writer) wa
 func setAppConfigLabels(c *cobra.Command, config *newcmd.AppConfig) error {
 	labelStr := kcmdutil.GetFlagString(c, "labels")
 	if len(labelStr) != 0 {
-		var err error
-		config.Labels, err = ctl.ParseLabels(labelStr)
+		labels, err := ctl.ParseLabels(labelStr)
 		if err != nil {
 			return err
 		}
+		if config.Labels == nil {
+			config.Labels = map[string]string{}
+		}
+		for k, v := range labels {
+			config.Labels[k] = v
+		}
 	}
 	return nil
 }
@@ -444,6 +466,15 @@ func setupAppConfig(f *clientcmd.Factory, out io.Writer, c *cobra.Command, args
 	config.KubeClient = kclient
 	config.SetOpenShiftClient(osclient, namespace)
 
+	if kcmdutil.GetFlagBool(c, "resolution-cache") {
+		cache, err := newResolutionCache(f, osclient, kcmdutil.GetFlagDuration(c, "resolution-cache-ttl"))
+		if err != nil {
+			return err
+		}
+		cache.Bypass = kcmdutil.GetFlagBool(c, "refresh-resolution-cache")
+		config.ResolutionCache = cache
+	}
+
 	// Only output="" should print descriptions of intermediate steps. Everything
 	// else should print only some specific output (json, yaml, go-template, ...)
 	output := kcmdutil.GetFlagString(c, "output")
@@ -470,6 +501,9 @@ func setupAppConfig(f *clientcmd.Factory, out io.Writer, c *cobra.Command, args
 	if config.AllowMissingImages && config.AsSearch {
 		return kcmdutil.UsageError(c, "--allow-missing-images and --search are mutually exclusive.")
 	}
+	if config.AsSuggest && config.Querying() {
+		return kcmdutil.UsageError(c, "--suggest can't be used with --search or --list.")
+	}
 
 	if len(config.SourceImage) != 0 && len(config.SourceImagePath) == 0 {
 		return kcmdutil.UsageError(c, "--source-image-path must be specified when --source-image is specified.")
@@ -480,6 +514,19 @@ func setupAppConfig(f *clientcmd.Factory, out io.Writer, c *cobra.Command, args
 	return nil
 }
 
+// newResolutionCache returns a resolution cache for the current user and server.
+func newResolutionCache(f *clientcmd.Factory, osclient client.Interface, ttl time.Duration) (*newapp.ResolutionCache, error) {
+	cfg, err := f.OpenShiftClientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	user, err := osclient.Users().Get("~")
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine the current user for the resolution cache: %v", err)
+	}
+	return newapp.NewResolutionCache(newcmd.DefaultResolutionCacheDir(), cfg.Host, user.Name, ttl), nil
+}
+
 func setAnnotations(annotations map[string]string, result *newcmd.AppResult) error {
 	for _, object := range result.List.Items {
 		err := util.AddObjectAnnotations(object, annotations)
@@ -552,6 +599,8 @@ func createObjects(f *clientcmd.Factory, after configcmd.AfterFunc, result *newc
 		// Retry is used to support previous versions of the API server that will
 		// consider the presence of an unknown trigger type to be an error.
 		Retry: retryBuildConfig,
+		// image stream tags promoting the output image may target other namespaces
+		PreserveNamespace: true,
 	}
 	if errs := bulk.Create(result.List, result.Namespace); len(errs) != 0 {
 		return cmdutil.ErrExit
@@ -785,6 +834,26 @@ func printHumanReadableQueryResult(r *newcmd.QueryResult, out io.Writer, fullNam
 	return nil
 }
 
+func printHumanReadableRecommendations(recommendations []newapp.ComponentRecommendations, out io.Writer) {
+	for _, r := range recommendations {
+		fmt.Fprintf(out, "Candidates for %q\n", r.Value)
+		fmt.Fprintln(out, "-----")
+		if len(r.Recommendations) == 0 {
+			fmt.Fprintln(out, "No matches found")
+		}
+		for _, recommendation := range r.Recommendations {
+			match := recommendation.Match
+			fmt.Fprintln(out, match.Argument)
+			if len(match.Description) > 0 {
+				fmt.Fprintf(out, "  %v\n", match.Description)
+			}
+			fmt.Fprintf(out, "  Score:  %g\n", match.Score)
+			fmt.Fprintf(out, "  Reason: %s, %s\n", recommendation.Reason, recommendation.Message)
+		}
+		fmt.Fprintln(out)
+	}
+}
+
 type configSecretRetriever struct {
 	config *kclient.Config
 }
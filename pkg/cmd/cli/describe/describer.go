@@ -248,6 +248,8 @@ func describeBuildSpec(p buildapi.BuildSpec, out *tabwriter.Writer) {
 		describeSourceStrategy(p.Strategy.SourceStrategy, out)
 	case p.Strategy.CustomStrategy != nil:
 		describeCustomStrategy(p.Strategy.CustomStrategy, out)
+	case p.Strategy.JenkinsPipelineStrategy != nil:
+		describeJenkinsPipelineStrategy(p.Strategy.JenkinsPipelineStrategy, out)
 	}
 
 	if p.Output.To != nil {
@@ -310,6 +312,18 @@ func describeDockerStrategy(s *buildapi.DockerBuildStrategy, out *tabwriter.Writ
 	}
 }
 
+func describeJenkinsPipelineStrategy(s *buildapi.JenkinsPipelineBuildStrategy, out *tabwriter.Writer) {
+	if len(s.JenkinsfilePath) != 0 {
+		formatString(out, "Jenkinsfile Path", s.JenkinsfilePath)
+	}
+	if len(s.Jenkinsfile) != 0 {
+		fmt.Fprintf(out, "Jenkinsfile:\n")
+		for _, line := range strings.Split(s.Jenkinsfile, "\n") {
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+}
+
 func describeCustomStrategy(s *buildapi.CustomBuildStrategy, out *tabwriter.Writer) {
 	if len(s.From.Name) != 0 {
 		formatString(out, "Image Reference", fmt.Sprintf("%s %s", s.From.Kind, nameAndNamespace(s.From.Namespace, s.From.Name)))
@@ -140,21 +140,24 @@ func formatMeta(out *tabwriter.Writer, m api.ObjectMeta) {
 func webhookURL(c *buildapi.BuildConfig, cli client.BuildConfigsNamespacer) map[string]string {
 	result := map[string]string{}
 	for _, trigger := range c.Spec.Triggers {
-		whTrigger := ""
+		var hook *buildapi.WebHookTrigger
 		switch trigger.Type {
 		case buildapi.GitHubWebHookBuildTriggerType:
-			whTrigger = trigger.GitHubWebHook.Secret
+			hook = trigger.GitHubWebHook
 		case buildapi.GenericWebHookBuildTriggerType:
-			whTrigger = trigger.GenericWebHook.Secret
+			hook = trigger.GenericWebHook
 		}
-		if len(whTrigger) == 0 {
+		if hook == nil || len(client.WebHookURLSecret(hook)) == 0 {
 			continue
 		}
 		out := ""
 		url, err := cli.BuildConfigs(c.Namespace).WebHookURL(c.Name, &trigger)
-		if err != nil {
+		switch {
+		case err != nil:
 			out = fmt.Sprintf("<error: %s>", err.Error())
-		} else {
+		case hook.SecretReference != nil:
+			out = fmt.Sprintf("%s (%s is the %s value of secret %q)", url, client.WebHookSecretPlaceholder, buildapi.WebHookSecretKey, hook.SecretReference.Name)
+		default:
 			out = url.String()
 		}
 		result[string(trigger.Type)] = out
@@ -13,7 +13,7 @@ import (
 var (
 	// availableClientDiagnostics contains the names of client diagnostics that can be executed
 	// during a single run of diagnostics. Add more diagnostics to the list as they are defined.
-	availableClientDiagnostics = sets.NewString(clientdiags.ConfigContextsName, clientdiags.DiagnosticPodName)
+	availableClientDiagnostics = sets.NewString(clientdiags.ConfigContextsName, clientdiags.DiagnosticPodName, clientdiags.ImportRegistriesName)
 )
 
 // buildClientDiagnostics builds client Diagnostic objects based on the rawConfig passed in.
@@ -25,7 +25,7 @@ func (o DiagnosticsOptions) buildClientDiagnostics(rawConfig *clientcmdapi.Confi
 	_, kubeClient, clientErr := o.Factory.Clients()
 	if clientErr != nil {
 		o.Logger.Notice("CED0001", "Could not configure a client, so client diagnostics are limited to testing configuration and connection")
-		available = sets.NewString(clientdiags.ConfigContextsName)
+		available = sets.NewString(clientdiags.ConfigContextsName, clientdiags.ImportRegistriesName)
 	}
 
 	diagnostics := []types.Diagnostic{}
@@ -53,6 +53,8 @@ func (o DiagnosticsOptions) buildClientDiagnostics(rawConfig *clientcmdapi.Confi
 				PreventModification: o.PreventModification,
 				ImageTemplate:       o.ImageTemplate,
 			})
+		case clientdiags.ImportRegistriesName:
+			diagnostics = append(diagnostics, clientdiags.ImportRegistries{Registries: o.ImportRegistries, Insecure: o.ImportInsecure})
 		default:
 			return nil, false, fmt.Errorf("unknown diagnostic: %v", diagnosticName)
 		}
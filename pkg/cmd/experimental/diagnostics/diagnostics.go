@@ -39,6 +39,9 @@ type DiagnosticsOptions struct {
 	ImageTemplate variable.ImageTemplate
 	// When true, prevent diagnostics from changing API state (e.g. creating something)
 	PreventModification bool
+	// registries to check that images can be imported from, and whether they may be insecure
+	ImportRegistries []string
+	ImportInsecure   bool
 	// We need a factory for creating clients. Creating a factory
 	// creates flags as a byproduct, most of which we don't want.
 	// The command creates these and binds only the flags we want.
@@ -125,6 +128,8 @@ func NewCommandDiagnostics(name string, fullName string, out io.Writer) *cobra.C
 	cmd.Flags().StringVar(&o.ImageTemplate.Format, options.FlagImageTemplateName, o.ImageTemplate.Format, "Image template for DiagnosticPod to use in creating a pod")
 	cmd.Flags().BoolVar(&o.ImageTemplate.Latest, options.FlagLatestImageName, false, "When expanding the image template, use latest version, not release version")
 	cmd.Flags().BoolVar(&o.PreventModification, options.FlagPreventModificationName, false, "May be set to prevent diagnostics making any changes via the API")
+	cmd.Flags().StringSliceVar(&o.ImportRegistries, options.FlagImportRegistriesName, o.ImportRegistries, "Registries for ImportRegistries to check that images can be imported from")
+	cmd.Flags().BoolVar(&o.ImportInsecure, options.FlagImportInsecureName, false, "Allow ImportRegistries to connect to registries over HTTP or with untrusted certificates")
 	flagtypes.GLog(cmd.Flags())
 	options.BindLoggerOptionFlags(cmd.Flags(), o.LogOptions, options.RecommendedLoggerOptionFlags())
 
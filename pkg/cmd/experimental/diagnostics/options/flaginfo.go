@@ -53,4 +53,6 @@ const (
 	FlagImageTemplateName       = "images"
 	FlagLatestImageName         = "latest-images"
 	FlagPreventModificationName = "prevent-modification"
+	FlagImportRegistriesName    = "import-registries"
+	FlagImportInsecureName      = "import-insecure"
 )
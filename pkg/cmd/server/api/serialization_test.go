@@ -146,6 +146,15 @@ func fuzzInternalObject(t *testing.T, forVersion unversioned.GroupVersion, item
 			if obj.ScheduledImageImportMinimumIntervalSeconds == 0 {
 				obj.ScheduledImageImportMinimumIntervalSeconds = 15 * 60
 			}
+			if obj.MaxConcurrentImageImportRequests == 0 {
+				obj.MaxConcurrentImageImportRequests = 5
+			}
+			if obj.MaxImageImportRequestsPerRegistryPerMinute == 0 {
+				obj.MaxImageImportRequestsPerRegistryPerMinute = 600
+			}
+			if len(obj.DefaultImageImportPlatform) == 0 {
+				obj.DefaultImageImportPlatform = "linux/amd64"
+			}
 		},
 		func(obj *configapi.DNSConfig, c fuzz.Continue) {
 			c.FuzzNoCustom(obj)
@@ -263,6 +263,39 @@ type ImagePolicyConfig struct {
 	// MaxScheduledImageImportsPerMinute is the maximum number of image streams that will be imported in the background per minute.
 	// The default value is 60. Set to -1 for unlimited.
 	MaxScheduledImageImportsPerMinute int `json:"maxScheduledImageImportsPerMinute"`
+	// MaxConcurrentImageImportRequests is the maximum number of requests a single import makes to registries at the
+	// same time, so that the tags and repositories of an import are retrieved in parallel. The default value is 5.
+	// Set to 1 to import serially.
+	MaxConcurrentImageImportRequests int
+	// MaxImageImportRequestsPerRegistryPerMinute is the maximum number of requests all imports make to a single
+	// registry per minute. The default value is 600. Set to -1 for unlimited.
+	MaxImageImportRequestsPerRegistryPerMinute int
+	// DefaultImageImportPlatform is the platform, as os/architecture[/variant], whose image is imported from manifest
+	// lists and image indexes when an import does not request one. The default value is linux/amd64.
+	DefaultImageImportPlatform string
+	// OrphanedImagePruneIntervalMinutes is the number of minutes between two passes of the controller that deletes
+	// imported images no image stream references any longer. The default value is 0, which disables the controller.
+	OrphanedImagePruneIntervalMinutes int
+	// DefaultImportTransport tunes the connections imports make to registries that are not listed in
+	// ImportTransports.
+	DefaultImportTransport RegistryTransportConfig
+	// ImportTransports tunes the connections imports make to individual registries, keyed by host or host:port.
+	ImportTransports map[string]RegistryTransportConfig
+}
+
+// RegistryTransportConfig tunes the connections made to a Docker registry.
+type RegistryTransportConfig struct {
+	// MinTLSVersion is the minimum TLS version to negotiate: VersionTLS10, VersionTLS11 or VersionTLS12. Empty uses
+	// the Go default.
+	MinTLSVersion string
+	// CipherSuites restricts the cipher suites that may be negotiated, named as the crypto/tls constants. Empty uses
+	// the Go default. The RC4 and 3DES suites are not allowed.
+	CipherSuites []string
+	// DisableHTTP2 prevents HTTP/2 from being negotiated with the registry.
+	DisableHTTP2 bool
+	// AllowHTTP allows plain HTTP to be used when the registry does not answer over HTTPS. It may only be set for
+	// individual registries in ImportTransports.
+	AllowHTTP bool
 }
 
 type ProjectConfig struct {
@@ -125,6 +125,15 @@ func addDefaultingFuncs(scheme *runtime.Scheme) {
 			if obj.ScheduledImageImportMinimumIntervalSeconds == 0 {
 				obj.ScheduledImageImportMinimumIntervalSeconds = 15 * 60
 			}
+			if obj.MaxConcurrentImageImportRequests == 0 {
+				obj.MaxConcurrentImageImportRequests = 5
+			}
+			if obj.MaxImageImportRequestsPerRegistryPerMinute == 0 {
+				obj.MaxImageImportRequestsPerRegistryPerMinute = 600
+			}
+			if len(obj.DefaultImageImportPlatform) == 0 {
+				obj.DefaultImageImportPlatform = "linux/amd64"
+			}
 		},
 		func(obj *DNSConfig) {
 			if len(obj.BindNetwork) == 0 {
@@ -215,6 +215,39 @@ type ImagePolicyConfig struct {
 	// MaxScheduledImageImportsPerMinute is the maximum number of scheduled image streams that will be imported in the
 	// background per minute. The default value is 60. Set to -1 for unlimited.
 	MaxScheduledImageImportsPerMinute int `json:"maxScheduledImageImportsPerMinute"`
+	// MaxConcurrentImageImportRequests is the maximum number of requests a single import makes to registries at the
+	// same time, so that the tags and repositories of an import are retrieved in parallel. The default value is 5.
+	// Set to 1 to import serially.
+	MaxConcurrentImageImportRequests int `json:"maxConcurrentImageImportRequests"`
+	// MaxImageImportRequestsPerRegistryPerMinute is the maximum number of requests all imports make to a single
+	// registry per minute. The default value is 600. Set to -1 for unlimited.
+	MaxImageImportRequestsPerRegistryPerMinute int `json:"maxImageImportRequestsPerRegistryPerMinute"`
+	// DefaultImageImportPlatform is the platform, as os/architecture[/variant], whose image is imported from manifest
+	// lists and image indexes when an import does not request one. The default value is linux/amd64.
+	DefaultImageImportPlatform string `json:"defaultImageImportPlatform"`
+	// OrphanedImagePruneIntervalMinutes is the number of minutes between two passes of the controller that deletes
+	// imported images no image stream references any longer. The default value is 0, which disables the controller.
+	OrphanedImagePruneIntervalMinutes int `json:"orphanedImagePruneIntervalMinutes"`
+	// DefaultImportTransport tunes the connections imports make to registries that are not listed in
+	// importTransports.
+	DefaultImportTransport RegistryTransportConfig `json:"defaultImportTransport"`
+	// ImportTransports tunes the connections imports make to individual registries, keyed by host or host:port.
+	ImportTransports map[string]RegistryTransportConfig `json:"importTransports"`
+}
+
+// RegistryTransportConfig tunes the connections made to a Docker registry.
+type RegistryTransportConfig struct {
+	// MinTLSVersion is the minimum TLS version to negotiate: VersionTLS10, VersionTLS11 or VersionTLS12. Empty uses
+	// the Go default.
+	MinTLSVersion string `json:"minTLSVersion"`
+	// CipherSuites restricts the cipher suites that may be negotiated, named as the crypto/tls constants. Empty uses
+	// the Go default. The RC4 and 3DES suites are not allowed.
+	CipherSuites []string `json:"cipherSuites"`
+	// DisableHTTP2 prevents HTTP/2 from being negotiated with the registry.
+	DisableHTTP2 bool `json:"disableHTTP2"`
+	// AllowHTTP allows plain HTTP to be used when the registry does not answer over HTTPS. It may only be set for
+	// individual registries in importTransports.
+	AllowHTTP bool `json:"allowHTTP"`
 }
 
 type ProjectConfig struct {
@@ -137,9 +137,19 @@ imageConfig:
   format: ""
   latest: false
 imagePolicyConfig:
+  defaultImageImportPlatform: ""
+  defaultImportTransport:
+    allowHTTP: false
+    cipherSuites: null
+    disableHTTP2: false
+    minTLSVersion: ""
   disableScheduledImport: false
+  importTransports: null
+  maxConcurrentImageImportRequests: 0
+  maxImageImportRequestsPerRegistryPerMinute: 0
   maxImagesBulkImportedPerRepository: 0
   maxScheduledImageImportsPerMinute: 0
+  orphanedImagePruneIntervalMinutes: 0
   scheduledImageImportMinimumIntervalSeconds: 0
 kind: MasterConfig
 kubeletClientInfo:
@@ -20,6 +20,8 @@ import (
 
 	"github.com/openshift/origin/pkg/cmd/server/api"
 	"github.com/openshift/origin/pkg/cmd/server/bootstrappolicy"
+	"github.com/openshift/origin/pkg/dockerregistry"
+	imageapi "github.com/openshift/origin/pkg/image/api"
 	"github.com/openshift/origin/pkg/security/mcs"
 	"github.com/openshift/origin/pkg/security/uid"
 	"github.com/openshift/origin/pkg/util/labelselector"
@@ -397,6 +399,40 @@ func ValidateImagePolicyConfig(config api.ImagePolicyConfig, fldPath *field.Path
 	if config.MaxScheduledImageImportsPerMinute == 0 || config.MaxScheduledImageImportsPerMinute < -1 {
 		errs = append(errs, field.Invalid(fldPath.Child("maxScheduledImageImportsPerMinute"), config.MaxScheduledImageImportsPerMinute, "must be a positive integer or -1"))
 	}
+	if config.MaxConcurrentImageImportRequests <= 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("maxConcurrentImageImportRequests"), config.MaxConcurrentImageImportRequests, "must be a positive integer"))
+	}
+	if config.MaxImageImportRequestsPerRegistryPerMinute == 0 || config.MaxImageImportRequestsPerRegistryPerMinute < -1 {
+		errs = append(errs, field.Invalid(fldPath.Child("maxImageImportRequestsPerRegistryPerMinute"), config.MaxImageImportRequestsPerRegistryPerMinute, "must be a positive integer or -1"))
+	}
+	if _, err := imageapi.ParseImagePlatform(config.DefaultImageImportPlatform); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("defaultImageImportPlatform"), config.DefaultImageImportPlatform, err.Error()))
+	}
+	if config.OrphanedImagePruneIntervalMinutes < 0 {
+		errs = append(errs, field.Invalid(fldPath.Child("orphanedImagePruneIntervalMinutes"), config.OrphanedImagePruneIntervalMinutes, "must be a positive integer or 0"))
+	}
+	errs = append(errs, ValidateRegistryTransportConfig(config.DefaultImportTransport, fldPath.Child("defaultImportTransport"))...)
+	if config.DefaultImportTransport.AllowHTTP {
+		errs = append(errs, field.Invalid(fldPath.Child("defaultImportTransport", "allowHTTP"), true, "plain HTTP may only be allowed for individual registries in importTransports"))
+	}
+	for host, transport := range config.ImportTransports {
+		hostPath := fldPath.Child("importTransports").Key(host)
+		if len(host) == 0 || strings.ContainsAny(host, "/ ") {
+			errs = append(errs, field.Invalid(hostPath, host, "must be a registry host or host:port"))
+		}
+		errs = append(errs, ValidateRegistryTransportConfig(transport, hostPath)...)
+	}
+	return errs
+}
+
+func ValidateRegistryTransportConfig(config api.RegistryTransportConfig, fldPath *field.Path) field.ErrorList {
+	errs := field.ErrorList{}
+	if _, err := dockerregistry.ParseTLSVersion(config.MinTLSVersion); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("minTLSVersion"), config.MinTLSVersion, err.Error()))
+	}
+	if _, err := dockerregistry.ParseCipherSuites(config.CipherSuites); err != nil {
+		errs = append(errs, field.Invalid(fldPath.Child("cipherSuites"), config.CipherSuites, err.Error()))
+	}
 	return errs
 }
 
@@ -248,3 +248,24 @@ func TestValidateAdmissionPluginConfig(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateImagePolicyConfigAllowHTTP(t *testing.T) {
+	config := api.ImagePolicyConfig{
+		MaxImagesBulkImportedPerRepository:         5,
+		ScheduledImageImportMinimumIntervalSeconds: 900,
+		MaxScheduledImageImportsPerMinute:          60,
+		MaxConcurrentImageImportRequests:           10,
+		MaxImageImportRequestsPerRegistryPerMinute: 600,
+		DefaultImageImportPlatform:                 "linux/amd64",
+		ImportTransports:                           map[string]api.RegistryTransportConfig{"lab.example.com:5000": {AllowHTTP: true}},
+	}
+	if errs := ValidateImagePolicyConfig(config, nil); len(errs) != 0 {
+		t.Errorf("unexpected errors: %v", errs)
+	}
+
+	config.DefaultImportTransport.AllowHTTP = true
+	expected := field.ErrorList{field.Invalid(field.NewPath("defaultImportTransport", "allowHTTP"), true, "plain HTTP may only be allowed for individual registries in importTransports")}
+	if errs := ValidateImagePolicyConfig(config, nil); !kapi.Semantic.DeepEqual(expected, errs) {
+		t.Errorf("unexpected validation results; diff:\n%v", util.ObjectDiff(expected, errs))
+	}
+}
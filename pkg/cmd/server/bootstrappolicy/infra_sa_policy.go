@@ -101,10 +101,21 @@ func init() {
 					Verbs:     sets.NewString("update"),
 					Resources: sets.NewString("builds"),
 				},
+				// BuildPodController.BuildDeleter (ControllerClient)
+				{
+					Verbs:     sets.NewString("delete"),
+					Resources: sets.NewString("builds"),
+				},
+				// BuildController.BuildConfigStore (buildConfigLW)
+				// BuildPodController.BuildConfigGetter (ControllerClient)
+				{
+					Verbs:     sets.NewString("get", "list", "watch"),
+					Resources: sets.NewString("buildconfigs"),
+				},
 				// Create permission on virtual build type resources allows builds of those types to be updated
 				{
 					Verbs:     sets.NewString("create"),
-					Resources: sets.NewString("builds/docker", "builds/source", "builds/custom"),
+					Resources: sets.NewString("builds/docker", "builds/source", "builds/custom", "builds/jenkinspipeline"),
 				},
 				// BuildController.ImageStreamClient (ControllerClient)
 				{
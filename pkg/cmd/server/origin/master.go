@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/distribution/registry/client/auth"
 	restful "github.com/emicklei/go-restful"
 	"github.com/emicklei/go-restful/swagger"
 	"github.com/golang/glog"
@@ -49,6 +50,7 @@ import (
 	deploylogregistry "github.com/openshift/origin/pkg/deploy/registry/deploylog"
 	deployrollback "github.com/openshift/origin/pkg/deploy/registry/rollback"
 	"github.com/openshift/origin/pkg/dockerregistry"
+	imageapi "github.com/openshift/origin/pkg/image/api"
 	"github.com/openshift/origin/pkg/image/importer"
 	imageimporter "github.com/openshift/origin/pkg/image/importer"
 	"github.com/openshift/origin/pkg/image/registry/image"
@@ -330,6 +332,36 @@ func (c *MasterConfig) InstallProtectedAPI(container *restful.Container) []strin
 	return messages
 }
 
+// registryTransportOptions returns the options of the connections imports make to registries.
+func registryTransportOptions(config configapi.ImagePolicyConfig) (dockerregistry.RegistryTransportOptions, error) {
+	options := dockerregistry.RegistryTransportOptions{Registries: make(map[string]dockerregistry.TransportOptions)}
+	var err error
+	if options.Default, err = transportOptions(config.DefaultImportTransport); err != nil {
+		return options, err
+	}
+	if options.Default.AllowHTTP {
+		return options, fmt.Errorf("plain HTTP may only be allowed for individual registries")
+	}
+	for host, transport := range config.ImportTransports {
+		if options.Registries[host], err = transportOptions(transport); err != nil {
+			return options, fmt.Errorf("registry %s: %v", host, err)
+		}
+	}
+	return options, nil
+}
+
+func transportOptions(config configapi.RegistryTransportConfig) (dockerregistry.TransportOptions, error) {
+	version, err := dockerregistry.ParseTLSVersion(config.MinTLSVersion)
+	if err != nil {
+		return dockerregistry.TransportOptions{}, err
+	}
+	suites, err := dockerregistry.ParseCipherSuites(config.CipherSuites)
+	if err != nil {
+		return dockerregistry.TransportOptions{}, err
+	}
+	return dockerregistry.TransportOptions{MinTLSVersion: version, CipherSuites: suites, DisableHTTP2: config.DisableHTTP2, AllowHTTP: config.AllowHTTP}, nil
+}
+
 func (c *MasterConfig) GetRestStorage() map[string]rest.Storage {
 	defaultRegistry := env("OPENSHIFT_DEFAULT_REGISTRY", "${DOCKER_REGISTRY_SERVICE_HOST}:${DOCKER_REGISTRY_SERVICE_PORT}")
 	svcCache := service.NewServiceResolverCache(c.KubeClient().Services(kapi.NamespaceDefault).Get)
@@ -352,6 +384,14 @@ func (c *MasterConfig) GetRestStorage() map[string]rest.Storage {
 	if err != nil {
 		glog.Fatalf("Unable to configure a default transport for importing: %v", err)
 	}
+	importTransportOptions, err := registryTransportOptions(c.Options.ImagePolicyConfig)
+	if err != nil {
+		glog.Fatalf("Unable to configure the transports for importing: %v", err)
+	}
+	// scheduled imports mostly find unchanged manifests, which are reused when the registry reports them unchanged
+	importTransportOptions.Cache = dockerregistry.NewResponseCache(0)
+	// tuned registries are connected to through the same transports by every import request
+	importTransportOptions.Transports = dockerregistry.NewTransportCache()
 
 	buildStorage, buildDetailsStorage := buildetcd.NewREST(c.EtcdHelper)
 	buildRegistry := buildregistry.NewRegistry(buildStorage)
@@ -405,13 +445,28 @@ func (c *MasterConfig) GetRestStorage() map[string]rest.Storage {
 	imageStreamMappingStorage := imagestreammapping.NewREST(imageRegistry, imageStreamRegistry)
 	imageStreamTagStorage := imagestreamtag.NewREST(imageRegistry, imageStreamRegistry)
 	imageStreamTagRegistry := imagestreamtag.NewRegistry(imageStreamTagStorage)
-	importerFn := func(r importer.RepositoryRetriever) imageimporter.Interface {
-		return imageimporter.NewImageStreamImporter(r, c.Options.ImagePolicyConfig.MaxImagesBulkImportedPerRepository, util.NewTokenBucketRateLimiter(2.0, 3))
-	}
-	importerDockerClientFn := func() dockerregistry.Client {
-		return dockerregistry.NewClient(20*time.Second, false)
+	// the requests to each registry are limited across all imports, while each import retrieves its images with
+	// several concurrent requests at the rate a serial import was allowed per request
+	importWorkers := c.Options.ImagePolicyConfig.MaxConcurrentImageImportRequests
+	registryLimiters := imageimporter.NewRegistryRateLimiters(c.Options.ImagePolicyConfig.MaxImageImportRequestsPerRegistryPerMinute, importWorkers)
+	importPlatform, err := imageapi.ParseImagePlatform(c.Options.ImagePolicyConfig.DefaultImageImportPlatform)
+	if err != nil {
+		glog.Fatalf("Invalid default image import platform: %v", err)
 	}
-	imageStreamImportStorage := imagestreamimport.NewREST(importerFn, imageStreamRegistry, internalImageStreamStorage, imageStorage, c.ImageStreamImportSecretClient(), importTransport, insecureImportTransport, importerDockerClientFn)
+	importerFn := func(r importer.RepositoryRetriever) imageimporter.Interface {
+		limiter := util.NewTokenBucketRateLimiter(2.0*float32(importWorkers), 3*importWorkers)
+		return imageimporter.NewImageStreamImporter(r, c.Options.ImagePolicyConfig.MaxImagesBulkImportedPerRepository, limiter).WithConcurrency(importWorkers, registryLimiters).WithDefaultPlatform(importPlatform)
+	}
+	importerDockerClientFn := func(credentials auth.CredentialStore) dockerregistry.Client {
+		options := importTransportOptions
+		options.Credentials = credentials
+		return dockerregistry.NewClientWithTransportOptions(20*time.Second, false, options)
+	}
+	// mirrored images are pushed to the integrated registry using the privileged loopback token, which is never
+	// sent to any other registry
+	mirrorCredentials := imageimporter.NewRegistryCredentials(defaultRegistryFunc, "system", c.PrivilegedLoopbackClientConfig.BearerToken)
+	importMirrorer := imageimporter.NewImageMirrorer(imageimporter.NewContext(importTransport, insecureImportTransport).WithRegistryTransportOptions(importTransportOptions).WithPushCredentials(mirrorCredentials), false)
+	imageStreamImportStorage := imagestreamimport.NewREST(importerFn, imageStreamRegistry, internalImageStreamStorage, imageStorage, c.ImageStreamImportSecretClient(), c.ImageStreamSecretClient(), importTransport, insecureImportTransport, importTransportOptions, importerDockerClientFn, imagestream.DefaultRegistryFunc(defaultRegistryFunc), importMirrorer)
 	imageStreamImageStorage := imagestreamimage.NewREST(imageRegistry, imageStreamRegistry)
 	imageStreamImageRegistry := imagestreamimage.NewRegistry(imageStreamImageStorage)
 
@@ -458,6 +513,7 @@ func (c *MasterConfig) GetRestStorage() map[string]rest.Storage {
 	buildConfigWebHooks := buildconfigregistry.NewWebHookREST(
 		buildConfigRegistry,
 		buildclient.NewOSClientBuildConfigInstantiatorClient(bcClient),
+		c.PrivilegedLoopbackKubernetesClient,
 		map[string]webhook.Plugin{
 			"generic": generic.New(),
 			"github":  github.New(),
@@ -14,6 +14,7 @@ import (
 	_ "github.com/openshift/origin/pkg/api/install"
 	"github.com/openshift/origin/pkg/api/validation"
 	authorizationapi "github.com/openshift/origin/pkg/authorization/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
 )
 
 // KnownUpdateValidationExceptions is the list of types that are known to not have an update validation function registered
@@ -81,8 +82,11 @@ func TestAllOpenShiftResourceCoverage(t *testing.T) {
 
 // fakeMasterConfig creates a new fake master config with an empty kubelet config and dummy storage.
 func fakeMasterConfig() *MasterConfig {
-	return &MasterConfig{
+	config := &MasterConfig{
 		KubeletClientConfig: &kubeletclient.KubeletClientConfig{},
 		EtcdHelper:          etcdstorage.NewEtcdStorage(nil, nil, ""),
 	}
+	// set by the defaults of the master configuration
+	config.Options.ImagePolicyConfig.DefaultImageImportPlatform = imageapi.DefaultImagePlatform
+	return config
 }
@@ -362,6 +362,25 @@ func (c *MasterConfig) RunImageImportController() {
 	}
 }
 
+// RunOrphanedImageController starts the controller that deletes imported images no image stream references,
+// if it is enabled.
+func (c *MasterConfig) RunOrphanedImageController() {
+	interval := c.Options.ImagePolicyConfig.OrphanedImagePruneIntervalMinutes
+	if interval == 0 {
+		glog.V(2).Infof("Orphaned image pruning is disabled")
+		return
+	}
+	factory := imagecontroller.OrphanedImageControllerFactory{
+		Client:   c.ImageImportControllerClient(),
+		Interval: time.Duration(interval) * time.Minute,
+		// images younger than a pass may belong to an import that has not updated its stream yet
+		KeepYoungerThan: time.Duration(interval) * time.Minute,
+		BatchInterval:   time.Second,
+		RateLimiter:     util.NewTokenBucketRateLimiter(10, 20),
+	}
+	factory.Create().Run()
+}
+
 // RunSecurityAllocationController starts the security allocation controller process.
 func (c *MasterConfig) RunSecurityAllocationController() {
 	alloc := c.Options.ProjectConfig.SecurityAllocator
@@ -583,6 +583,7 @@ func startControllers(oc *origin.MasterConfig, kc *kubernetes.MasterConfig) erro
 	oc.RunDeploymentConfigChangeController()
 	oc.RunDeploymentImageChangeTriggerController()
 	oc.RunImageImportController()
+	oc.RunOrphanedImageController()
 	oc.RunOriginNamespaceController()
 	oc.RunSDNController()
 
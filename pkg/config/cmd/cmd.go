@@ -21,6 +21,9 @@ type Bulk struct {
 	RESTClientFactory func(mapping *meta.RESTMapping) (resource.RESTClient, error)
 	After             AfterFunc
 	Retry             func(info *resource.Info, err error) runtime.Object
+	// PreserveNamespace creates items that set a namespace of their own in that
+	// namespace instead of the namespace passed to Create.
+	PreserveNamespace bool
 }
 
 func NewPrintNameOrErrorAfter(mapper meta.RESTMapper, short bool, operation string, out, errs io.Writer) AfterFunc {
@@ -62,8 +65,9 @@ func encodeAndCreate(info *resource.Info, namespace string, obj runtime.Object)
 }
 
 // Create attempts to create each item generically, gathering all errors in the
-// event a failure occurs. The contents of list will be updated to include the
-// version from the server.
+// event a failure occurs. Items are created in namespace, unless
+// PreserveNamespace is set and they set a namespace of their own. The contents
+// of list will be updated to include the version from the server.
 func (b *Bulk) Create(list *kapi.List, namespace string) []error {
 	resourceMapper := &resource.Mapper{ObjectTyper: b.Typer, RESTMapper: b.Mapper, ClientMapper: resource.ClientMapperFunc(b.RESTClientFactory)}
 	after := b.After
@@ -81,10 +85,14 @@ func (b *Bulk) Create(list *kapi.List, namespace string) []error {
 			}
 			continue
 		}
-		obj, err := encodeAndCreate(info, namespace, item)
+		itemNamespace := namespace
+		if b.PreserveNamespace && len(info.Namespace) > 0 {
+			itemNamespace = info.Namespace
+		}
+		obj, err := encodeAndCreate(info, itemNamespace, item)
 		if err != nil && b.Retry != nil {
 			if obj := b.Retry(info, err); obj != nil {
-				obj, err = encodeAndCreate(info, namespace, obj)
+				obj, err = encodeAndCreate(info, itemNamespace, obj)
 			}
 		}
 		if err != nil {
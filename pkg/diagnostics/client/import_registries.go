@@ -0,0 +1,71 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	kclient "k8s.io/kubernetes/pkg/client/unversioned"
+
+	"github.com/openshift/origin/pkg/diagnostics/types"
+	"github.com/openshift/origin/pkg/image/importer"
+)
+
+const (
+	ImportRegistriesName = "ImportRegistries"
+)
+
+// ImportRegistries is a diagnostic that checks that images can be imported from a set of registries, with the
+// credentials of the local Docker configuration and of the cloud providers of the host.
+type ImportRegistries struct {
+	Registries []string
+	Insecure   bool
+}
+
+// Name is part of the Diagnostic interface and just returns name.
+func (d ImportRegistries) Name() string {
+	return ImportRegistriesName
+}
+
+// Description is part of the Diagnostic interface and provides a user-focused description of what the diagnostic does.
+func (d ImportRegistries) Description() string {
+	return "Check that images can be imported from the requested registries"
+}
+
+// CanRun is part of the Diagnostic interface; it determines if the conditions are right to run this diagnostic.
+func (d ImportRegistries) CanRun() (bool, error) {
+	if len(d.Registries) == 0 {
+		return false, errors.New("no registries were requested to be checked")
+	}
+	return true, nil
+}
+
+// Check is part of the Diagnostic interface; it runs the actual diagnostic logic
+func (d ImportRegistries) Check() types.DiagnosticResult {
+	r := types.NewDiagnosticResult(ImportRegistriesName)
+
+	transport, err := kclient.TransportFor(&kclient.Config{})
+	if err != nil {
+		r.Error("DCli3001", err, fmt.Sprintf("Unable to create a transport to connect to registries: %v", err))
+		return r
+	}
+	insecureTransport, err := kclient.TransportFor(&kclient.Config{Insecure: true})
+	if err != nil {
+		r.Error("DCli3001", err, fmt.Sprintf("Unable to create a transport to connect to registries: %v", err))
+		return r
+	}
+
+	statuses := importer.NewContext(transport, insecureTransport).CheckRegistries(importer.NewLocalCredentials(), d.Registries, d.Insecure)
+	for _, status := range statuses {
+		switch {
+		case !status.Reachable:
+			r.Error("DCli3002", status.Err, fmt.Sprintf("Images cannot be imported from %s, it could not be reached as a v2 registry: %v", status.Registry, status.Err))
+		case !status.Authenticated:
+			r.Error("DCli3003", status.Err, fmt.Sprintf("Images cannot be imported from %s, it refused access to %s: %v", status.Registry, status.Endpoint, status.Err))
+		case len(status.AuthScheme) == 0:
+			r.Info("DCli3004", fmt.Sprintf("Images can be imported from %s, which allows anonymous access", status.Registry))
+		default:
+			r.Info("DCli3005", fmt.Sprintf("Images can be imported from %s with %s authentication", status.Registry, status.AuthScheme))
+		}
+	}
+	return r
+}
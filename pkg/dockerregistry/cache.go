@@ -0,0 +1,251 @@
+package dockerregistry
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultResponseCacheSize is the number of bytes of responses a ResponseCache holds when no size is given.
+	DefaultResponseCacheSize = 32 << 20
+	// maxCachedBodySize bounds the size of the responses that are cached, so that only metadata such as
+	// manifests and tag lists is kept, and never layers.
+	maxCachedBodySize = 1 << 20
+)
+
+// ResponseCache holds the registry responses that carry an ETag or Last-Modified validator, so that they can be
+// requested again conditionally and reused when the registry answers 304 Not Modified. Responses are cached per
+// registry, repository, credentials, URL and Accept header. A ResponseCache is safe for concurrent use and may be
+// shared by several transports.
+type ResponseCache struct {
+	maxBytes int64
+
+	lock    sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type cachedResponse struct {
+	key          string
+	etag         string
+	lastModified string
+	header       http.Header
+	body         []byte
+}
+
+// size returns the approximate number of bytes the entry holds in memory.
+func (r *cachedResponse) size() int64 {
+	size := len(r.key) + len(r.etag) + len(r.lastModified) + len(r.body)
+	for k, values := range r.header {
+		size += len(k)
+		for _, v := range values {
+			size += len(v)
+		}
+	}
+	return int64(size)
+}
+
+// NewResponseCache returns a cache that holds up to maxBytes of responses, evicting the least recently used
+// response first. If maxBytes is not positive, DefaultResponseCacheSize is used.
+func NewResponseCache(maxBytes int64) *ResponseCache {
+	if maxBytes <= 0 {
+		maxBytes = DefaultResponseCacheSize
+	}
+	return &ResponseCache{
+		maxBytes: maxBytes,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// CredentialsFunc returns the username and password requests are made with, or empty strings if they are
+// anonymous.
+type CredentialsFunc func() (username, password string)
+
+// Wrap returns a transport that makes the GET requests for repository on registry through rt conditional on the
+// responses in the cache, and reuses the responses the registry reports unchanged. A response is only reused for
+// the same registry, repository and credentials, so that rotating tokens do not defeat the cache and responses
+// are never shared between credentials. credentials, which may be nil for anonymous requests, is called once
+// when the first request is made. An empty repository shares responses between the repositories of registry,
+// whose names are part of the URLs they are cached by.
+func (c *ResponseCache) Wrap(rt http.RoundTripper, registry, repository string, credentials CredentialsFunc) http.RoundTripper {
+	return &cachingTransport{cache: c, rt: rt, registry: registry, repository: repository, credentials: credentials}
+}
+
+// Size returns the approximate number of bytes of the cached responses.
+func (c *ResponseCache) Size() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.size
+}
+
+// Len returns the number of cached responses.
+func (c *ResponseCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+func (c *ResponseCache) get(key string) (*cachedResponse, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return e.Value.(*cachedResponse), true
+}
+
+func (c *ResponseCache) add(entry *cachedResponse) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[entry.key]; ok {
+		c.removeElement(e)
+	}
+	// a response larger than the whole cache would only evict the others
+	if entry.size() > c.maxBytes {
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	c.size += entry.size()
+	for c.size > c.maxBytes {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+func (c *ResponseCache) remove(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.removeElement(e)
+	}
+}
+
+// removeElement removes an entry of the cache. The lock must be held.
+func (c *ResponseCache) removeElement(e *list.Element) {
+	entry := e.Value.(*cachedResponse)
+	c.lru.Remove(e)
+	delete(c.entries, entry.key)
+	c.size -= entry.size()
+}
+
+type cachingTransport struct {
+	cache       *ResponseCache
+	rt          http.RoundTripper
+	registry    string
+	repository  string
+	credentials CredentialsFunc
+
+	once     sync.Once
+	identity string
+}
+
+// cacheKey identifies the response to req. The credentials are hashed so that a response is only reused for the
+// credentials it was returned to, without keeping them in memory.
+func (t *cachingTransport) cacheKey(req *http.Request) string {
+	t.once.Do(func() {
+		var username, password string
+		if t.credentials != nil {
+			username, password = t.credentials()
+		}
+		credentials := sha256.Sum256([]byte(username + "\x00" + password))
+		t.identity = fmt.Sprintf("%s\x00%s\x00%x", t.registry, t.repository, credentials)
+	})
+	return fmt.Sprintf("%s\x00%s\x00%s", t.identity, req.URL.String(), req.Header.Get("Accept"))
+}
+
+func (t *cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// requests that are already conditional are left to the caller
+	if req.Method != "GET" || len(req.Header.Get("If-None-Match")) > 0 || len(req.Header.Get("If-Modified-Since")) > 0 {
+		return t.rt.RoundTrip(req)
+	}
+	key := t.cacheKey(req)
+	cached, ok := t.cache.get(key)
+	if ok {
+		conditional := new(http.Request)
+		*conditional = *req
+		conditional.Header = make(http.Header, len(req.Header)+1)
+		for k, v := range req.Header {
+			conditional.Header[k] = v
+		}
+		if len(cached.etag) > 0 {
+			conditional.Header.Set("If-None-Match", cached.etag)
+		}
+		if len(cached.lastModified) > 0 {
+			conditional.Header.Set("If-Modified-Since", cached.lastModified)
+		}
+		req = conditional
+	}
+
+	resp, err := t.rt.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ok && resp.StatusCode == http.StatusNotModified:
+		resp.Body.Close()
+		return cached.response(req), nil
+	case resp.StatusCode != http.StatusOK:
+		return resp, nil
+	}
+
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if (len(etag) == 0 && len(lastModified) == 0) || strings.Contains(resp.Header.Get("Cache-Control"), "no-store") || resp.ContentLength > maxCachedBodySize {
+		if ok {
+			t.cache.remove(key)
+		}
+		return resp, nil
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxCachedBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxCachedBodySize {
+		resp.Body = readCloser{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	t.cache.add(&cachedResponse{key: key, etag: etag, lastModified: lastModified, header: copyHeader(resp.Header), body: body})
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// response returns the cached response to req.
+func (r *cachedResponse) response(req *http.Request) *http.Response {
+	header := copyHeader(r.header)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}
+}
+
+// copyHeader returns a copy of header that changes to either of them do not affect.
+func copyHeader(header http.Header) http.Header {
+	copied := make(http.Header, len(header))
+	for k, v := range header {
+		copied[k] = append([]string(nil), v...)
+	}
+	return copied
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
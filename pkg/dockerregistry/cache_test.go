@@ -0,0 +1,158 @@
+package dockerregistry
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResponseCacheRevalidates(t *testing.T) {
+	var requests, notModified int
+	body := `{"name":"foo/bar","tags":["latest"]}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(0)
+	client := &http.Client{Transport: cache.Wrap(http.DefaultTransport, "registry.io", "foo/bar", nil)}
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL + "/v2/foo/bar/tags/list")
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK || string(data) != body {
+			t.Fatalf("%d: unexpected response %d: %s", i, resp.StatusCode, data)
+		}
+		if resp.Header.Get("ETag") != `"v1"` {
+			t.Errorf("%d: unexpected headers: %#v", i, resp.Header)
+		}
+	}
+	if requests != 3 || notModified != 2 {
+		t.Errorf("unexpected requests: %d, not modified: %d", requests, notModified)
+	}
+	if cache.Len() != 1 {
+		t.Errorf("unexpected cache size: %d", cache.Len())
+	}
+}
+
+func TestResponseCacheSkips(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/no-validator":
+		case "/no-store":
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Cache-Control", "no-store")
+		case "/error":
+			w.Header().Set("ETag", `"v1"`)
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(0)
+	client := &http.Client{Transport: cache.Wrap(http.DefaultTransport, "registry.io", "foo/bar", nil)}
+	for _, path := range []string{"/no-validator", "/no-store", "/error"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if cache.Len() != 0 {
+		t.Errorf("unexpected cache size: %d", cache.Len())
+	}
+}
+
+func TestResponseCacheSeparatesCredentials(t *testing.T) {
+	conditional := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.Header.Get("If-None-Match")) > 0 {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(0)
+	get := func(username, token string) {
+		client := &http.Client{Transport: cache.Wrap(http.DefaultTransport, "registry.io", "foo/bar", func() (string, string) {
+			return username, "password"
+		})}
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		req.Header.Set("Authorization", token)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	// a response is reused for the same credentials whatever token they were exchanged for
+	get("a", "Bearer 1")
+	get("a", "Bearer 2")
+	if conditional != 1 {
+		t.Errorf("the response was not reused for the same credentials: %d", conditional)
+	}
+	get("b", "Bearer 1")
+	if conditional != 1 {
+		t.Errorf("a response was reused for other credentials")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("unexpected cache size: %d", cache.Len())
+	}
+}
+
+func TestResponseCacheBoundsSize(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	cache := NewResponseCache(2500)
+	client := &http.Client{Transport: cache.Wrap(http.DefaultTransport, "registry.io", "foo/bar", nil)}
+	for _, path := range []string{"/a", "/b", "/c"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// the least recently used response is evicted to stay under the size of the cache
+	if cache.Len() != 2 || cache.Size() > 2500 {
+		t.Errorf("unexpected cache size: %d responses, %d bytes", cache.Len(), cache.Size())
+	}
+
+	// responses larger than the cache are not kept
+	small := NewResponseCache(500)
+	client = &http.Client{Transport: small.Wrap(http.DefaultTransport, "registry.io", "foo/bar", nil)}
+	resp, err := client.Get(server.URL + "/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(data) != body || small.Len() != 0 || small.Size() != 0 {
+		t.Errorf("unexpected cache size: %d responses, %d bytes", small.Len(), small.Size())
+	}
+}
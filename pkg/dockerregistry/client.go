@@ -1,7 +1,6 @@
 package dockerregistry
 
 import (
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -13,10 +12,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/docker/distribution/registry/client/auth"
 	"github.com/fsouza/go-dockerclient"
 	"github.com/golang/glog"
 	"k8s.io/kubernetes/pkg/client/transport"
-	knet "k8s.io/kubernetes/pkg/util/net"
 
 	imageapi "github.com/openshift/origin/pkg/image/api"
 )
@@ -24,6 +23,9 @@ import (
 type Image struct {
 	docker.Image
 
+	// OS is the operating system the image was built for, or empty if the image does not report one.
+	OS string
+
 	// Does this registry support pull by ID
 	PullByID bool
 }
@@ -34,7 +36,8 @@ type Client interface {
 	Connect(registry string, allowInsecure bool) (Connection, error)
 }
 
-// Connection allows you to retrieve data from a Docker V1/V2 registry.
+// Connection allows you to retrieve data from a Docker V1/V2 registry. The namespace of an image may hold several
+// path segments for registries that serve repositories under a path, such as Artifactory and Nexus.
 type Connection interface {
 	// ImageTags will return a map of the tags for the image by namespace and name.
 	// If namespace is not specified, will default to "library" for Docker hub.
@@ -53,6 +56,7 @@ type client struct {
 	dialTimeout time.Duration
 	connections map[string]*connection
 	allowV2     bool
+	options     RegistryTransportOptions
 }
 
 // NewClient returns a client object which allows public access to
@@ -60,10 +64,17 @@ type client struct {
 // API connections.
 // TODO: accept a docker auth config
 func NewClient(dialTimeout time.Duration, allowV2 bool) Client {
+	return NewClientWithTransportOptions(dialTimeout, allowV2, RegistryTransportOptions{})
+}
+
+// NewClientWithTransportOptions returns a client like NewClient that connects to each registry
+// with the transport options configured for it.
+func NewClientWithTransportOptions(dialTimeout time.Duration, allowV2 bool, options RegistryTransportOptions) Client {
 	return &client{
 		dialTimeout: dialTimeout,
 		connections: make(map[string]*connection),
 		allowV2:     allowV2,
+		options:     options,
 	}
 }
 
@@ -79,7 +90,19 @@ func (c *client) Connect(name string, allowInsecure bool) (Connection, error) {
 	if conn, ok := c.connections[prefix]; ok && conn.allowInsecure == allowInsecure {
 		return conn, nil
 	}
-	conn := newConnection(*target, c.dialTimeout, allowInsecure, c.allowV2)
+	conn := newConnection(*target, c.dialTimeout, allowInsecure, c.allowV2, c.options.For(target.Host))
+	conn.allowHTTP = c.options.AllowsHTTP(target.Host)
+	conn.credentials = c.options.Credentials
+	if c.options.Cache != nil {
+		// the connection serves every repository of the registry, whose names are part of the cached URLs
+		credentials := c.options.Credentials
+		conn.client.Transport = c.options.Cache.Wrap(conn.client.Transport, target.Host, "", func() (string, string) {
+			if credentials == nil {
+				return "", ""
+			}
+			return credentials.Basic(target)
+		})
+	}
 	c.connections[prefix] = conn
 	return conn, nil
 }
@@ -104,11 +127,7 @@ func normalizeRegistryName(name string) (*url.URL, error) {
 	if len(prefix) == 0 {
 		prefix = imageapi.DockerDefaultV1Registry
 	}
-	hadPrefix := false
-	switch {
-	case strings.HasPrefix(prefix, "http://"), strings.HasPrefix(prefix, "https://"):
-		hadPrefix = true
-	default:
+	if !strings.HasPrefix(prefix, "http://") && !strings.HasPrefix(prefix, "https://") {
 		prefix = "https://" + prefix
 	}
 
@@ -117,15 +136,15 @@ func normalizeRegistryName(name string) (*url.URL, error) {
 		return nil, fmt.Errorf("the registry name cannot be made into a valid url: %v", err)
 	}
 
+	// the default port of the scheme is removed, and the host lowercased, so that a registry named with and
+	// without its port shares a single connection
+	target.Host = strings.ToLower(target.Host)
 	if host, port, err := net.SplitHostPort(target.Host); err == nil {
-		host = normalizeDockerHubHost(host, false)
-		if hadPrefix {
-			switch {
-			case port == "443" && target.Scheme == "https":
-				target.Host = host
-			case port == "80" && target.Scheme == "http":
-				target.Host = host
-			}
+		switch {
+		case port == "443" && target.Scheme == "https":
+			target.Host = normalizeDockerHubHost(host, false)
+		case port == "80" && target.Scheme == "http":
+			target.Host = normalizeDockerHubHost(host, false)
 		}
 	} else {
 		target.Host = normalizeDockerHubHost(target.Host, false)
@@ -153,35 +172,21 @@ type connection struct {
 	token  string
 
 	allowInsecure bool
+	// allowHTTP permits falling back to plain HTTP without skipping certificate verification
+	allowHTTP bool
+	// credentials, if set, are sent to the token realm of v2 registries
+	credentials auth.CredentialStore
 }
 
 // newConnection creates a new connection
-func newConnection(url url.URL, dialTimeout time.Duration, allowInsecure, enableV2 bool) *connection {
+func newConnection(url url.URL, dialTimeout time.Duration, allowInsecure, enableV2 bool, options TransportOptions) *connection {
 	var isV2 *bool
 	if !enableV2 {
 		v2 := false
 		isV2 = &v2
 	}
 
-	var rt http.RoundTripper
-	if allowInsecure {
-		rt = knet.SetTransportDefaults(&http.Transport{
-			Dial: (&net.Dialer{
-				Timeout:   dialTimeout,
-				KeepAlive: 30 * time.Second,
-			}).Dial,
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		})
-	} else {
-		rt = knet.SetTransportDefaults(&http.Transport{
-			Dial: (&net.Dialer{
-				Timeout:   dialTimeout,
-				KeepAlive: 30 * time.Second,
-			}).Dial,
-		})
-	}
-
-	rt = transport.DebugWrappers(rt)
+	rt := transport.DebugWrappers(NewTransport(options, dialTimeout, allowInsecure))
 
 	jar, _ := cookiejar.New(nil)
 	client := &http.Client{Jar: jar, Transport: rt}
@@ -297,7 +302,7 @@ func (c *connection) checkV2() (bool, error) {
 	resp, err := c.client.Do(req)
 	if err != nil {
 		// if we tried https and were rejected, try http
-		if c.url.Scheme == "https" && c.allowInsecure {
+		if c.url.Scheme == "https" && (c.allowInsecure || c.allowHTTP) {
 			glog.V(4).Infof("Failed to get https, trying http: %v", err)
 			c.url.Scheme = "http"
 			return c.checkV2()
@@ -341,11 +346,17 @@ func parseAuthChallenge(header string) (string, map[string]string) {
 	return sections[0], keys
 }
 
+// repositoryCredentialStore is implemented by credential stores that may hold different credentials for the
+// repositories of a registry, such as those of registries that serve repositories under a path.
+type repositoryCredentialStore interface {
+	ForRepository(registry *url.URL, repository string) auth.CredentialStore
+}
+
 // authenticateV2 attempts to respond to a given WWW-Authenticate challenge header
-// by asking for a token from the realm. Currently only supports "Bearer" challenges
-// with no credentials provided.
-// TODO: support credentials or replace with the Docker distribution v2 registry client
-func (c *connection) authenticateV2(header string) (string, error) {
+// by asking for a token from the realm. Currently only supports "Bearer" challenges.
+// The credentials of the connection for repository, if any, are sent to the realm with basic auth.
+// TODO: replace with the Docker distribution v2 registry client
+func (c *connection) authenticateV2(header, repository string) (string, error) {
 	mode, keys := parseAuthChallenge(header)
 	if strings.ToLower(mode) != "bearer" {
 		return "", fmt.Errorf("unsupported authentication challenge from registry: %s", header)
@@ -370,6 +381,15 @@ func (c *connection) authenticateV2(header string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("error creating v2 auth request: %v", err)
 	}
+	if credentials := c.credentials; credentials != nil {
+		if store, ok := credentials.(repositoryCredentialStore); ok {
+			registry := c.url
+			credentials = store.ForRepository(&registry, repository)
+		}
+		if username, password := credentials.Basic(realmURL); len(username) > 0 || len(password) > 0 {
+			req.SetBasicAuth(username, password)
+		}
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -415,7 +435,7 @@ func (c *connection) getRepositoryV1(name string) (repository, error) {
 	resp, err := c.client.Do(req)
 	if err != nil {
 		// if we tried https and were rejected, try http
-		if c.url.Scheme == "https" && c.allowInsecure {
+		if c.url.Scheme == "https" && (c.allowInsecure || c.allowHTTP) {
 			glog.V(4).Infof("Failed to get https, trying http: %v", err)
 			c.url.Scheme = "http"
 			return c.getRepositoryV1(name)
@@ -487,7 +507,7 @@ func (repo *v2repository) getTags(c *connection) (map[string]string, error) {
 			// repo
 			return nil, errRepositoryNotFound{repo.name}
 		}
-		token, err := c.authenticateV2(resp.Header.Get("WWW-Authenticate"))
+		token, err := c.authenticateV2(resp.Header.Get("WWW-Authenticate"), repo.name)
 		if err != nil {
 			return nil, fmt.Errorf("error getting image tags for %s: %v", repo.name, err)
 		}
@@ -520,6 +540,9 @@ func (repo *v2repository) getTaggedImage(c *connection, tag, userTag string) (*I
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
+	for _, mediaType := range manifestMediaTypes {
+		req.Header.Add("Accept", mediaType)
+	}
 	if len(repo.token) > 0 {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", repo.token))
 	}
@@ -537,7 +560,7 @@ func (repo *v2repository) getTaggedImage(c *connection, tag, userTag string) (*I
 			// repo
 			return nil, errTagNotFound{len(userTag) == 0, tag, repo.name}
 		}
-		token, err := c.authenticateV2(resp.Header.Get("WWW-Authenticate"))
+		token, err := c.authenticateV2(resp.Header.Get("WWW-Authenticate"), repo.name)
 		if err != nil {
 			return nil, fmt.Errorf("error getting image for %s:%s: %v", repo.name, tag, err)
 		}
@@ -558,13 +581,20 @@ func (repo *v2repository) getTaggedImage(c *connection, tag, userTag string) (*I
 	if err != nil {
 		return nil, fmt.Errorf("can't read image body from %s: %v", req.URL, err)
 	}
-	dockerImage, err := unmarshalV2DockerImage(body)
+	dockerImage, configDigest, err := unmarshalV2DockerImage(body)
+	if err, ok := err.(NotAnImageError); ok {
+		err.Repository, err.Tag = repo.name, tag
+		return nil, err
+	}
 	if err != nil {
 		return nil, err
 	}
-	image := &Image{
-		Image: *dockerImage,
+	if dockerImage == nil {
+		if dockerImage, err = repo.getImageConfig(c, configDigest); err != nil {
+			return nil, err
+		}
 	}
+	image := dockerImage
 	if len(digest) > 0 {
 		image.Image.ID = digest
 		image.PullByID = true
@@ -572,6 +602,33 @@ func (repo *v2repository) getTaggedImage(c *connection, tag, userTag string) (*I
 	return image, nil
 }
 
+// getImageConfig retrieves the configuration blob of a schema2 or OCI image, which holds the entrypoint,
+// command and working directory of the image along with the rest of its metadata.
+func (repo *v2repository) getImageConfig(c *connection, digest string) (*Image, error) {
+	endpoint := repo.endpoint
+	endpoint.Path = path.Join(endpoint.Path, fmt.Sprintf("/v2/%s/blobs/%s", repo.name, digest))
+	req, err := http.NewRequest("GET", endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+	if len(repo.token) > 0 {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", repo.token))
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, convertConnectionError(c.url.String(), fmt.Errorf("error getting the configuration %s of %s: %v", digest, repo.name, err))
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error retrieving the configuration %s of %s: server returned %d", digest, repo.name, resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("can't read image configuration from %s: %v", req.URL, err)
+	}
+	return unmarshalDockerImage(body)
+}
+
 func (repo *v2repository) getImage(c *connection, image, userTag string) (*Image, error) {
 	return repo.getTaggedImage(c, image, userTag)
 }
@@ -683,11 +740,7 @@ func (repo *v1repository) getImage(c *connection, image, userTag string) (*Image
 	if err != nil {
 		return nil, fmt.Errorf("can't read image body from %s: %v", req.URL, err)
 	}
-	dockerImage, err := unmarshalDockerImage(body)
-	if err != nil {
-		return nil, err
-	}
-	return &Image{Image: *dockerImage}, nil
+	return unmarshalDockerImage(body)
 }
 
 // errTagNotFound is an error indicating the requested tag does not exist on the server. May be returned on
@@ -765,34 +818,133 @@ func IsNotFound(err error) bool {
 	return IsRegistryNotFound(err) || IsRepositoryNotFound(err) || IsImageNotFound(err) || IsTagNotFound(err)
 }
 
-func unmarshalDockerImage(body []byte) (*docker.Image, error) {
-	var imagePre012 docker.ImagePre012
+func unmarshalDockerImage(body []byte) (*Image, error) {
+	var imagePre012 struct {
+		docker.ImagePre012
+		OS string `json:"os"`
+	}
 	if err := json.Unmarshal(body, &imagePre012); err != nil {
 		return nil, err
 	}
 
-	return &docker.Image{
-		ID:              imagePre012.ID,
-		Parent:          imagePre012.Parent,
-		Comment:         imagePre012.Comment,
-		Created:         imagePre012.Created,
-		Container:       imagePre012.Container,
-		ContainerConfig: imagePre012.ContainerConfig,
-		DockerVersion:   imagePre012.DockerVersion,
-		Author:          imagePre012.Author,
-		Config:          imagePre012.Config,
-		Architecture:    imagePre012.Architecture,
-		Size:            imagePre012.Size,
+	return &Image{
+		Image: docker.Image{
+			ID:              imagePre012.ID,
+			Parent:          imagePre012.Parent,
+			Comment:         imagePre012.Comment,
+			Created:         imagePre012.Created,
+			Container:       imagePre012.Container,
+			ContainerConfig: imagePre012.ContainerConfig,
+			DockerVersion:   imagePre012.DockerVersion,
+			Author:          imagePre012.Author,
+			Config:          imagePre012.Config,
+			Architecture:    imagePre012.Architecture,
+			Size:            imagePre012.Size,
+		},
+		OS: imagePre012.OS,
 	}, nil
 }
 
-func unmarshalV2DockerImage(body []byte) (*docker.Image, error) {
+// NotAnImageError is returned when a manifest describes an artifact, such as a Helm chart or a signature,
+// rather than an image that can be run.
+type NotAnImageError struct {
+	Repository string
+	Tag        string
+	// Kind is a short description of the artifact.
+	Kind string
+	// MediaType is the media type that identifies the artifact.
+	MediaType string
+}
+
+func (e NotAnImageError) Error() string {
+	return fmt.Sprintf("%s:%s is a %s (%s), not an image", e.Repository, e.Tag, e.Kind, e.MediaType)
+}
+
+// IsNotAnImage returns true if err indicates that a manifest describes an artifact rather than an image.
+func IsNotAnImage(err error) bool {
+	_, ok := err.(NotAnImageError)
+	return ok
+}
+
+// artifactKinds maps the media types of well known artifacts that are not images to a description.
+var artifactKinds = map[string]string{
+	"application/vnd.cncf.helm.config.v1+json":            "Helm chart",
+	"application/vnd.cncf.helm.chart.content.v1.tar+gzip": "Helm chart",
+	"application/vnd.dev.cosign.simplesigning.v1+json":    "signature",
+	"application/vnd.dev.sigstore.bundle.v0.3+json":       "signature",
+	"application/vnd.cncf.notary.signature":               "signature",
+	"application/vnd.in-toto+json":                        "attestation",
+	"application/vnd.oci.empty.v1+json":                   "artifact",
+}
+
+// imageConfigMediaTypes are the media types of the configuration of runnable images.
+var imageConfigMediaTypes = map[string]bool{
+	"": true,
+	"application/vnd.docker.container.image.v1+json": true,
+	"application/vnd.oci.image.config.v1+json":       true,
+}
+
+// artifactManifest holds the fields of schema2 and OCI manifests that identify artifacts.
+type artifactManifest struct {
+	ArtifactType string `json:"artifactType"`
+	Config       struct {
+		MediaType string `json:"mediaType"`
+	} `json:"config"`
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+	} `json:"layers"`
+}
+
+// notAnImage returns a NotAnImageError if the manifest in body describes an artifact rather than an image.
+func notAnImage(body []byte) error {
+	manifest := artifactManifest{}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil
+	}
+	mediaTypes := []string{manifest.ArtifactType, manifest.Config.MediaType}
+	for _, layer := range manifest.Layers {
+		mediaTypes = append(mediaTypes, layer.MediaType)
+	}
+	for _, mediaType := range mediaTypes {
+		if kind, ok := artifactKinds[mediaType]; ok {
+			return NotAnImageError{Kind: kind, MediaType: mediaType}
+		}
+	}
+	if len(manifest.ArtifactType) > 0 {
+		return NotAnImageError{Kind: "artifact", MediaType: manifest.ArtifactType}
+	}
+	if !imageConfigMediaTypes[manifest.Config.MediaType] {
+		return NotAnImageError{Kind: "artifact", MediaType: manifest.Config.MediaType}
+	}
+	return nil
+}
+
+// manifestMediaTypes are the media types of the image manifests that are accepted from v2 registries. Schema1
+// manifests embed the configuration of the image, while the configuration of schema2 and OCI images is
+// retrieved separately.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v1+prettyjws",
+	"application/json",
+}
+
+// unmarshalV2DockerImage returns the image described by the schema1 manifest in body, or the digest of the
+// configuration of the image described by the schema2 or OCI manifest in body.
+func unmarshalV2DockerImage(body []byte) (*Image, string, error) {
+	if err := notAnImage(body); err != nil {
+		return nil, "", err
+	}
 	manifest := imageapi.DockerImageManifest{}
 	if err := json.Unmarshal(body, &manifest); err != nil {
-		return nil, err
+		return nil, "", err
+	}
+	if manifest.SchemaVersion == 2 && len(manifest.Config.Digest) > 0 {
+		return nil, manifest.Config.Digest.String(), nil
 	}
 	if len(manifest.History) == 0 {
-		return nil, fmt.Errorf("image has no v1Compatibility history and cannot be used")
+		return nil, "", fmt.Errorf("image has no v1Compatibility history and cannot be used")
 	}
-	return unmarshalDockerImage([]byte(manifest.History[0].DockerV1Compatibility))
+	image, err := unmarshalDockerImage([]byte(manifest.History[0].DockerV1Compatibility))
+	return image, "", err
 }
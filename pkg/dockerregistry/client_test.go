@@ -6,13 +6,39 @@ import (
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/docker/distribution/registry/client/auth"
 )
 
 // tests of running registries are done in the integration client test
 
+func TestNormalizeRegistryName(t *testing.T) {
+	tests := map[string]string{
+		"":                                "https://index.docker.io",
+		"docker.io":                       "https://index.docker.io",
+		"docker.io:443":                   "https://index.docker.io",
+		"Registry.Example.com:443":        "https://registry.example.com",
+		"registry.example.com:5000":       "https://registry.example.com:5000",
+		"https://registry.example.com":    "https://registry.example.com",
+		"http://registry.example.com:80":  "http://registry.example.com",
+		"http://registry.example.com:443": "http://registry.example.com:443",
+	}
+	for name, expected := range tests {
+		target, err := normalizeRegistryName(name)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if target.String() != expected {
+			t.Errorf("%s: unexpected url: %s", name, target)
+		}
+	}
+}
+
 func TestHTTPFallback(t *testing.T) {
 	called := make(chan struct{}, 2)
 	var uri *url.URL
@@ -39,6 +65,45 @@ func TestHTTPFallback(t *testing.T) {
 	<-called
 }
 
+func TestHTTPFallbackAllowedByHost(t *testing.T) {
+	called := make(chan struct{}, 2)
+	var uri *url.URL
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called <- struct{}{}
+		if strings.HasSuffix(r.URL.Path, "/tags") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Docker-Endpoints", uri.Host)
+		w.WriteHeader(http.StatusOK)
+	}))
+	uri, _ = url.Parse(server.URL)
+	options := RegistryTransportOptions{Registries: map[string]TransportOptions{uri.Host: {AllowHTTP: true}}}
+	conn, err := NewClientWithTransportOptions(10*time.Second, true, options).Connect(uri.Host, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := conn.(*connection); c.allowInsecure || !c.allowHTTP {
+		t.Fatalf("unexpected connection: %#v", c)
+	}
+	v2 := false
+	conn.(*connection).isV2 = &v2
+	if _, err := conn.ImageTags("foo", "bar"); !IsRepositoryNotFound(err) {
+		t.Error(err)
+	}
+	<-called
+	<-called
+
+	conn, err = NewClient(10*time.Second, true).Connect(uri.Host, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.(*connection).isV2 = &v2
+	if _, err := conn.ImageTags("foo", "bar"); err == nil || IsRepositoryNotFound(err) {
+		t.Errorf("expected HTTP to be refused: %v", err)
+	}
+}
+
 func TestV2Check(t *testing.T) {
 	called := make(chan struct{}, 2)
 	var uri *url.URL
@@ -312,3 +377,163 @@ func TestGetTagFallback(t *testing.T) {
 		t.Errorf("expected error")
 	}
 }
+
+func TestGetTaggedImageArtifact(t *testing.T) {
+	manifests := map[string]string{
+		"chart":     `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.cncf.helm.config.v1+json"},"layers":[{"mediaType":"application/vnd.cncf.helm.chart.content.v1.tar+gzip"}]}`,
+		"signature": `{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json"},"layers":[{"mediaType":"application/vnd.dev.cosign.simplesigning.v1+json"}]}`,
+		"unknown":   `{"schemaVersion":2,"artifactType":"application/vnd.example.sbom","config":{"mediaType":"application/vnd.oci.empty.v1+json"}}`,
+		"image":     `{"schemaVersion":1,"history":[{"v1Compatibility":"{\"id\":\"image\"}"}]}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, manifests[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]])
+	}))
+	defer server.Close()
+	uri, _ := url.Parse(server.URL)
+	conn, err := NewClient(10*time.Second, true).Connect(uri.Host, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &v2repository{name: "testrepo", endpoint: *uri}
+
+	expected := map[string]string{"chart": "Helm chart", "signature": "signature", "unknown": "artifact"}
+	for tag, kind := range expected {
+		_, err := repo.getTaggedImage(conn.(*connection), tag, tag)
+		if !IsNotAnImage(err) {
+			t.Errorf("%s: expected a not an image error: %v", tag, err)
+			continue
+		}
+		if e := err.(NotAnImageError); e.Kind != kind || e.Repository != "testrepo" || e.Tag != tag {
+			t.Errorf("%s: unexpected error: %#v", tag, e)
+		}
+	}
+	if img, err := repo.getTaggedImage(conn.(*connection), "image", "image"); err != nil || img.ID != "image" {
+		t.Errorf("unexpected image: %#v %v", img, err)
+	}
+}
+
+func TestGetTaggedImageSchema2(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/testrepo/manifests/latest":
+			if !strings.Contains(strings.Join(r.Header["Accept"], ","), "application/vnd.docker.distribution.manifest.v2+json") {
+				t.Errorf("schema2 manifests were not accepted: %v", r.Header["Accept"])
+			}
+			w.Header().Set("Docker-Content-Digest", "sha256:manifest")
+			fmt.Fprintln(w, `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v2+json","config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"sha256:config"}}`)
+		case "/v2/testrepo/blobs/sha256:config":
+			fmt.Fprintln(w, `{"architecture":"amd64","config":{"Entrypoint":["/usr/bin/run"],"Cmd":["--serve"],"WorkingDir":"/srv","ExposedPorts":{"8080/tcp":{}}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	uri, _ := url.Parse(server.URL)
+	conn, err := NewClient(10*time.Second, true).Connect(uri.Host, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &v2repository{name: "testrepo", endpoint: *uri}
+	img, err := repo.getTaggedImage(conn.(*connection), "latest", "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.ID != "sha256:manifest" || !img.PullByID || img.Config == nil {
+		t.Fatalf("unexpected image: %#v", img)
+	}
+	if c := img.Config; !reflect.DeepEqual(c.Entrypoint, []string{"/usr/bin/run"}) || !reflect.DeepEqual(c.Cmd, []string{"--serve"}) || c.WorkingDir != "/srv" {
+		t.Errorf("unexpected config: %#v", c)
+	}
+
+	repo = &v2repository{name: "missing", endpoint: *uri}
+	if _, err := repo.getTaggedImage(conn.(*connection), "latest", "latest"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
+
+type staticCredentials struct {
+	username, password string
+}
+
+func (c staticCredentials) Basic(*url.URL) (string, string) {
+	return c.username, c.password
+}
+
+func TestAuthenticateV2Credentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if scope := r.URL.Query().Get("scope"); scope != "repository:foo/bar:pull" {
+			t.Errorf("unexpected scope: %s", scope)
+		}
+		fmt.Fprintln(w, `{"token":"private"}`)
+	}))
+	defer server.Close()
+	uri, _ := url.Parse(server.URL)
+	header := fmt.Sprintf(`Bearer realm="%s/token",scope="repository:foo/bar:pull"`, server.URL)
+
+	conn, err := NewClient(10*time.Second, true).Connect(uri.Host, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.(*connection).authenticateV2(header, "foo/bar"); err == nil {
+		t.Fatal("expected an error without credentials")
+	}
+
+	options := RegistryTransportOptions{Credentials: staticCredentials{"user", "secret"}}
+	conn, err = NewClientWithTransportOptions(10*time.Second, true, options).Connect(uri.Host, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := conn.(*connection).authenticateV2(header, "foo/bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "private" {
+		t.Errorf("unexpected token: %s", token)
+	}
+}
+
+// pathCredentials provides credentials only for the repositories under a path of the registry.
+type pathCredentials struct {
+	prefix string
+	repo   string
+}
+
+func (c pathCredentials) Basic(*url.URL) (string, string) {
+	if strings.HasPrefix(c.repo, c.prefix+"/") {
+		return "user", "secret"
+	}
+	return "", ""
+}
+
+func (c pathCredentials) ForRepository(registry *url.URL, repository string) auth.CredentialStore {
+	return pathCredentials{prefix: c.prefix, repo: repository}
+}
+
+func TestAuthenticateV2RepositoryCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, `{"token":"private"}`)
+	}))
+	defer server.Close()
+	uri, _ := url.Parse(server.URL)
+	header := fmt.Sprintf(`Bearer realm="%s/token"`, server.URL)
+
+	options := RegistryTransportOptions{Credentials: pathCredentials{prefix: "docker-virtual/team"}}
+	conn, err := NewClientWithTransportOptions(10*time.Second, true, options).Connect(uri.Host, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token, err := conn.(*connection).authenticateV2(header, "docker-virtual/team/app"); err != nil || token != "private" {
+		t.Errorf("unexpected token %q: %v", token, err)
+	}
+	if _, err := conn.(*connection).authenticateV2(header, "docker-virtual/other/app"); err == nil {
+		t.Errorf("expected an error for a repository outside of the path")
+	}
+}
@@ -0,0 +1,232 @@
+package dockerregistry
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/registry/client/auth"
+	knet "k8s.io/kubernetes/pkg/util/net"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// TransportOptions tunes the connections made to a Docker registry.
+type TransportOptions struct {
+	// MinTLSVersion is the minimum TLS version to negotiate, for example tls.VersionTLS12. Zero uses the Go default.
+	MinTLSVersion uint16
+	// CipherSuites restricts the cipher suites that may be negotiated. Empty uses the Go default.
+	CipherSuites []uint16
+	// DisableHTTP2 prevents HTTP/2 from being negotiated with the registry.
+	DisableHTTP2 bool
+	// AllowHTTP allows plain HTTP to be used when the registry does not answer over HTTPS, without skipping
+	// certificate verification for HTTPS. It is only honored for registries listed by host in
+	// RegistryTransportOptions.Registries.
+	AllowHTTP bool
+}
+
+// RegistryTransportOptions holds TransportOptions for individual registries.
+type RegistryTransportOptions struct {
+	// Default applies to any registry not listed in Registries.
+	Default TransportOptions
+	// Registries is keyed by registry host (host or host:port). Hosts are compared after
+	// imageapi.NormalizeRegistryHost, so host and host:443 are the same registry.
+	Registries map[string]TransportOptions
+	// Cache, if set, is used to request manifests and tag lists again conditionally and reuse them when they
+	// have not changed.
+	Cache *ResponseCache
+	// Transports, if set, holds the transports created for tuned registries so that their connections are
+	// reused by every client created from these options.
+	Transports *TransportCache
+	// Credentials, if set, provides the username and password sent to the token realms of v2 registries, so that
+	// private repositories can be searched with the same credential store the importer uses.
+	Credentials auth.CredentialStore
+}
+
+// For returns the options for the provided registry host.
+func (o RegistryTransportOptions) For(host string) TransportOptions {
+	if options, ok := o.registry(host); ok {
+		return options
+	}
+	return o.Default
+}
+
+// Tuned returns the options for the provided registry host, and true if a transport must be created for them
+// rather than using the default transport of the caller.
+func (o RegistryTransportOptions) Tuned(host string) (TransportOptions, bool) {
+	if options, ok := o.registry(host); ok {
+		return options, true
+	}
+	return o.Default, o.Default.customTLS() || o.Default.DisableHTTP2
+}
+
+// AllowsHTTP returns true if plain HTTP connections have been allowed for the provided registry host. The
+// default options never allow HTTP.
+func (o RegistryTransportOptions) AllowsHTTP(host string) bool {
+	options, ok := o.registry(host)
+	return ok && options.AllowHTTP
+}
+
+// registry returns the options listed for host, if any.
+func (o RegistryTransportOptions) registry(host string) (TransportOptions, bool) {
+	if options, ok := o.Registries[host]; ok {
+		return options, true
+	}
+	host = imageapi.NormalizeRegistryHost(host)
+	for registry, options := range o.Registries {
+		if imageapi.NormalizeRegistryHost(registry) == host {
+			return options, true
+		}
+	}
+	return TransportOptions{}, false
+}
+
+// TLSConfig returns the TLS configuration described by these options. If insecure is true, certificate
+// verification is skipped.
+func (o TransportOptions) TLSConfig(insecure bool) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: insecure,
+		MinVersion:         o.MinTLSVersion,
+		CipherSuites:       o.CipherSuites,
+	}
+}
+
+// customTLS returns true if these options require a TLS configuration other than the default.
+func (o TransportOptions) customTLS() bool {
+	return o.MinTLSVersion != 0 || len(o.CipherSuites) > 0
+}
+
+// Transport returns a transport tuned for the provided registry host and true, or false if the default transport
+// of the caller should be used. The transport is taken from Transports if it is set.
+func (o RegistryTransportOptions) Transport(host string, dialTimeout time.Duration, insecure bool) (*http.Transport, bool) {
+	options, ok := o.Tuned(host)
+	if !ok {
+		return nil, false
+	}
+	if o.Transports == nil {
+		return NewTransport(options, dialTimeout, insecure), true
+	}
+	return o.Transports.get(host, options, dialTimeout, insecure), true
+}
+
+// TransportCache holds the transports created for tuned registries, keyed by host. A TransportCache is safe for
+// concurrent use.
+type TransportCache struct {
+	lock       sync.Mutex
+	transports map[transportCacheKey]*http.Transport
+}
+
+type transportCacheKey struct {
+	host        string
+	dialTimeout time.Duration
+	insecure    bool
+}
+
+// NewTransportCache creates an empty TransportCache.
+func NewTransportCache() *TransportCache {
+	return &TransportCache{transports: make(map[transportCacheKey]*http.Transport)}
+}
+
+// get returns the transport for host, creating it with options if it does not exist yet.
+func (c *TransportCache) get(host string, options TransportOptions, dialTimeout time.Duration, insecure bool) *http.Transport {
+	key := transportCacheKey{host: imageapi.NormalizeRegistryHost(host), dialTimeout: dialTimeout, insecure: insecure}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if t, ok := c.transports[key]; ok {
+		return t
+	}
+	t := NewTransport(options, dialTimeout, insecure)
+	c.transports[key] = t
+	return t
+}
+
+// NewTransport creates a transport for connecting to a registry with the provided options. If insecure is true,
+// certificate verification is skipped.
+func NewTransport(options TransportOptions, dialTimeout time.Duration, insecure bool) *http.Transport {
+	t := knet.SetTransportDefaults(&http.Transport{
+		Dial: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).Dial,
+	})
+	if insecure || options.customTLS() {
+		t.TLSClientConfig = options.TLSConfig(insecure)
+	}
+	if options.DisableHTTP2 {
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	} else {
+		t.ForceAttemptHTTP2 = true
+	}
+	return t
+}
+
+var tlsVersions = map[string]uint16{
+	"VersionTLS10": tls.VersionTLS10,
+	"VersionTLS11": tls.VersionTLS11,
+	"VersionTLS12": tls.VersionTLS12,
+}
+
+var cipherSuites = map[string]uint16{
+	"TLS_RSA_WITH_AES_128_CBC_SHA":            tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_RSA_WITH_AES_256_CBC_SHA":            tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA":    tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA":      tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA,
+	"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256":   tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256": tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384":   tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384": tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+}
+
+// insecureCipherSuites are the RC4 and 3DES cipher suites, which are broken and are never negotiated with a
+// registry.
+var insecureCipherSuites = map[string]bool{
+	"TLS_RSA_WITH_RC4_128_SHA":            true,
+	"TLS_RSA_WITH_3DES_EDE_CBC_SHA":       true,
+	"TLS_ECDHE_ECDSA_WITH_RC4_128_SHA":    true,
+	"TLS_ECDHE_RSA_WITH_RC4_128_SHA":      true,
+	"TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA": true,
+}
+
+// ParseTLSVersion converts a TLS version name (VersionTLS10, VersionTLS11, VersionTLS12) into its value.
+// An empty name returns zero.
+func ParseTLSVersion(name string) (uint16, error) {
+	if len(name) == 0 {
+		return 0, nil
+	}
+	if version, ok := tlsVersions[name]; ok {
+		return version, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version %q", name)
+}
+
+// ParseCipherSuites converts a list of cipher suite names (as named by the crypto/tls constants) into their
+// values. The RC4 and 3DES suites are rejected.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	var suites []uint16
+	var unknown, insecure []string
+	for _, name := range names {
+		if insecureCipherSuites[name] {
+			insecure = append(insecure, name)
+			continue
+		}
+		suite, ok := cipherSuites[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		suites = append(suites, suite)
+	}
+	if len(insecure) > 0 {
+		return nil, fmt.Errorf("insecure cipher suites are not allowed: %s", strings.Join(insecure, ", "))
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown cipher suites: %s", strings.Join(unknown, ", "))
+	}
+	return suites, nil
+}
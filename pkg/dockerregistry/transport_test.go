@@ -0,0 +1,116 @@
+package dockerregistry
+
+import (
+	"crypto/tls"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRegistryTransportOptionsFor(t *testing.T) {
+	options := RegistryTransportOptions{
+		Default:    TransportOptions{MinTLSVersion: tls.VersionTLS10},
+		Registries: map[string]TransportOptions{
+			"hardened.io:5000":    {MinTLSVersion: tls.VersionTLS12},
+			"sni.example.com:443": {MinTLSVersion: tls.VersionTLS12},
+		},
+	}
+	for _, host := range []string{"hardened.io:5000", "sni.example.com", "SNI.example.com:443"} {
+		if v := options.For(host).MinTLSVersion; v != tls.VersionTLS12 {
+			t.Errorf("%s: unexpected version: %d", host, v)
+		}
+	}
+	for _, host := range []string{"docker.io", "hardened.io", "sni.example.com:5000"} {
+		if v := options.For(host).MinTLSVersion; v != tls.VersionTLS10 {
+			t.Errorf("%s: unexpected version: %d", host, v)
+		}
+	}
+}
+
+func TestRegistryTransportOptionsTuned(t *testing.T) {
+	options := RegistryTransportOptions{Registries: map[string]TransportOptions{"hardened.io": {MinTLSVersion: tls.VersionTLS12}}}
+	if _, ok := options.Tuned("docker.io"); ok {
+		t.Errorf("expected the default transport without default options")
+	}
+	if o, ok := options.Tuned("hardened.io:443"); !ok || o.MinTLSVersion != tls.VersionTLS12 {
+		t.Errorf("unexpected options: %#v %t", o, ok)
+	}
+	options.Default = TransportOptions{DisableHTTP2: true}
+	if o, ok := options.Tuned("docker.io"); !ok || !o.DisableHTTP2 {
+		t.Errorf("expected the default options to be used: %#v %t", o, ok)
+	}
+}
+
+func TestRegistryTransportOptionsAllowsHTTP(t *testing.T) {
+	options := RegistryTransportOptions{
+		Default:    TransportOptions{AllowHTTP: true},
+		Registries: map[string]TransportOptions{"plain.io:5000": {AllowHTTP: true}, "secure.io": {}},
+	}
+	if !options.AllowsHTTP("plain.io:5000") {
+		t.Errorf("expected HTTP to be allowed")
+	}
+	for _, host := range []string{"secure.io", "plain.io", "docker.io"} {
+		if options.AllowsHTTP(host) {
+			t.Errorf("%s: expected HTTP to be refused", host)
+		}
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	rt := NewTransport(TransportOptions{}, time.Second, false)
+	if rt.TLSClientConfig != nil || !rt.ForceAttemptHTTP2 {
+		t.Errorf("unexpected default transport: %#v", rt)
+	}
+
+	rt = NewTransport(TransportOptions{MinTLSVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, DisableHTTP2: true}, time.Second, true)
+	if rt.TLSClientConfig == nil || !rt.TLSClientConfig.InsecureSkipVerify || rt.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("unexpected TLS config: %#v", rt.TLSClientConfig)
+	}
+	if rt.TLSNextProto == nil || rt.ForceAttemptHTTP2 {
+		t.Errorf("expected HTTP/2 to be disabled: %#v", rt)
+	}
+}
+
+func TestRegistryTransportOptionsTransport(t *testing.T) {
+	options := RegistryTransportOptions{Registries: map[string]TransportOptions{"hardened.io": {MinTLSVersion: tls.VersionTLS12}}}
+	if _, ok := options.Transport("docker.io", time.Second, false); ok {
+		t.Errorf("expected the default transport without default options")
+	}
+	first, _ := options.Transport("hardened.io", time.Second, false)
+	if second, _ := options.Transport("hardened.io", time.Second, false); first == second {
+		t.Errorf("expected a new transport without a cache")
+	}
+
+	options.Transports = NewTransportCache()
+	first, ok := options.Transport("hardened.io", time.Second, false)
+	if !ok || first.TLSClientConfig == nil || first.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("unexpected transport: %#v", first)
+	}
+	if second, _ := options.Transport("hardened.io:443", time.Second, false); first != second {
+		t.Errorf("expected the transport to be reused")
+	}
+	if insecure, _ := options.Transport("hardened.io", time.Second, true); insecure == first || !insecure.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("expected a separate insecure transport: %#v", insecure)
+	}
+}
+
+func TestParseTLSOptions(t *testing.T) {
+	if v, err := ParseTLSVersion("VersionTLS12"); err != nil || v != tls.VersionTLS12 {
+		t.Errorf("unexpected result: %d %v", v, err)
+	}
+	if _, err := ParseTLSVersion("VersionSSL30"); err == nil {
+		t.Errorf("expected error")
+	}
+	suites, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_AES_128_CBC_SHA"})
+	if err != nil || !reflect.DeepEqual(suites, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_128_CBC_SHA}) {
+		t.Errorf("unexpected result: %v %v", suites, err)
+	}
+	if _, err := ParseCipherSuites([]string{"TLS_FAKE"}); err == nil {
+		t.Errorf("expected error")
+	}
+	for _, name := range []string{"TLS_RSA_WITH_RC4_128_SHA", "TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA"} {
+		if _, err := ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", name}); err == nil {
+			t.Errorf("%s: expected an insecure cipher suite to be rejected", name)
+		}
+	}
+}
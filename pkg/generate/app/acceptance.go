@@ -0,0 +1,146 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+)
+
+// MatchSource is the kind of object a component match refers to.
+type MatchSource string
+
+const (
+	MatchSourceImageStream MatchSource = "imagestream"
+	MatchSourceTemplate    MatchSource = "template"
+	MatchSourceDockerImage MatchSource = "docker"
+)
+
+// DefaultMatchSourceOrder prefers image streams to templates, and templates to Docker images.
+var DefaultMatchSourceOrder = []MatchSource{MatchSourceImageStream, MatchSourceTemplate, MatchSourceDockerImage}
+
+// Source returns the kind of object the match refers to, or an empty string if it is not known.
+func (m *ComponentMatch) Source() MatchSource {
+	switch {
+	case m.Template != nil:
+		return MatchSourceTemplate
+	case m.ImageStream != nil:
+		return MatchSourceImageStream
+	case m.Image != nil:
+		return MatchSourceDockerImage
+	}
+	return ""
+}
+
+// AcceptancePolicy decides when a resolution without a single exact match may accept one of its candidates
+// anyway, so that automation can proceed without choosing between matches interactively. The zero value
+// accepts nothing.
+type AcceptancePolicy struct {
+	// Threshold is the highest score of a candidate that is accepted when no single exact match was found.
+	Threshold float32
+	// Order breaks ties between the best candidates by preferring the sources that come earlier. Candidates
+	// from sources that are not listed are never preferred. If empty, ties are not broken.
+	Order []MatchSource
+}
+
+// IsZero returns true if the policy accepts nothing.
+func (p AcceptancePolicy) IsZero() bool {
+	return p.Threshold == 0 && len(p.Order) == 0
+}
+
+// String returns a stable description of the policy.
+func (p AcceptancePolicy) String() string {
+	order := []string{}
+	for _, source := range p.Order {
+		order = append(order, string(source))
+	}
+	return fmt.Sprintf("%g/%s", p.Threshold, strings.Join(order, ","))
+}
+
+// ParseMatchSourceOrder parses a comma delimited list of match sources.
+func ParseMatchSourceOrder(value string) ([]MatchSource, error) {
+	order := []MatchSource{}
+	for _, s := range strings.Split(value, ",") {
+		source := MatchSource(strings.TrimSpace(s))
+		switch source {
+		case MatchSourceImageStream, MatchSourceTemplate, MatchSourceDockerImage:
+			order = append(order, source)
+		case "":
+		default:
+			return nil, fmt.Errorf("%q is not a match source, must be one of %s, %s or %s", s, MatchSourceImageStream, MatchSourceTemplate, MatchSourceDockerImage)
+		}
+	}
+	return order, nil
+}
+
+// Accept returns the candidate of a partial or multiple match error that the policy accepts, or nil if err is
+// another error or no candidate is acceptable.
+func (p AcceptancePolicy) Accept(err error) *ComponentMatch {
+	var candidates ScoredComponentMatches
+	switch t := err.(type) {
+	case ErrPartialMatch:
+		candidates = ScoredComponentMatches{t.Match}
+	case ErrMultipleMatches:
+		candidates = append(candidates, t.Matches...)
+	default:
+		return nil
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	sort.Sort(candidates)
+	best := candidates[0].Score
+	if best > p.Threshold {
+		return nil
+	}
+	tied := ComponentMatches{}
+	for _, m := range candidates {
+		if m.Score != best {
+			break
+		}
+		tied = append(tied, m)
+	}
+	if len(tied) == 1 {
+		return tied[0]
+	}
+
+	// prefer the single candidate from the earliest source in the order
+	for _, source := range p.Order {
+		var found *ComponentMatch
+		count := 0
+		for _, m := range tied {
+			if m.Source() == source {
+				found = m
+				count++
+			}
+		}
+		switch {
+		case count == 1:
+			return found
+		case count > 1:
+			return nil
+		}
+	}
+	return nil
+}
+
+// AcceptingResolver resolves with Resolver, and when that finds only partial or multiple matches accepts
+// the candidate chosen by Policy.
+type AcceptingResolver struct {
+	Resolver Resolver
+	Policy   AcceptancePolicy
+}
+
+// Resolve resolves value, accepting a candidate according to the policy if no single exact match exists.
+func (r AcceptingResolver) Resolve(value string) (*ComponentMatch, error) {
+	match, err := r.Resolver.Resolve(value)
+	if match != nil || err == nil {
+		return match, err
+	}
+	if accepted := r.Policy.Accept(err); accepted != nil {
+		glog.V(2).Infof("Accepting %s with score %v for %q by policy", accepted.Description, accepted.Score, value)
+		return accepted, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	templateapi "github.com/openshift/origin/pkg/template/api"
+)
+
+func TestAcceptancePolicy(t *testing.T) {
+	stream := &ComponentMatch{Name: "stream", Score: 0.0, ImageStream: &imageapi.ImageStream{}}
+	template := &ComponentMatch{Name: "template", Score: 0.0, Template: &templateapi.Template{}}
+	image := &ComponentMatch{Name: "image", Score: 0.5, Image: &imageapi.DockerImage{}}
+	otherImage := &ComponentMatch{Name: "other", Score: 0.5, Image: &imageapi.DockerImage{}}
+
+	tests := []struct {
+		name   string
+		policy AcceptancePolicy
+		err    error
+		expect *ComponentMatch
+	}{
+		{
+			name: "zero value accepts nothing",
+			err:  ErrPartialMatch{Match: image},
+		},
+		{
+			name:   "partial under threshold",
+			policy: AcceptancePolicy{Threshold: 0.5},
+			err:    ErrPartialMatch{Match: image},
+			expect: image,
+		},
+		{
+			name:   "partial over threshold",
+			policy: AcceptancePolicy{Threshold: 0.25},
+			err:    ErrPartialMatch{Match: image},
+		},
+		{
+			name:   "best of multiple",
+			policy: AcceptancePolicy{Threshold: 1},
+			err:    ErrMultipleMatches{Matches: []*ComponentMatch{image, {Name: "worse", Score: 0.75}}},
+			expect: image,
+		},
+		{
+			name:   "tie without order",
+			policy: AcceptancePolicy{Threshold: 1},
+			err:    ErrMultipleMatches{Matches: []*ComponentMatch{stream, template}},
+		},
+		{
+			name:   "tie broken by default order",
+			policy: AcceptancePolicy{Order: DefaultMatchSourceOrder},
+			err:    ErrMultipleMatches{Matches: []*ComponentMatch{template, stream}},
+			expect: stream,
+		},
+		{
+			name:   "tie broken by custom order",
+			policy: AcceptancePolicy{Order: []MatchSource{MatchSourceTemplate, MatchSourceImageStream}},
+			err:    ErrMultipleMatches{Matches: []*ComponentMatch{stream, template}},
+			expect: template,
+		},
+		{
+			name:   "tie within the preferred source",
+			policy: AcceptancePolicy{Threshold: 1, Order: DefaultMatchSourceOrder},
+			err:    ErrMultipleMatches{Matches: []*ComponentMatch{image, otherImage}},
+		},
+		{
+			name:   "other errors",
+			policy: AcceptancePolicy{Threshold: 1},
+			err:    ErrNoMatch{Value: "test"},
+		},
+	}
+	for _, test := range tests {
+		if match := test.policy.Accept(test.err); match != test.expect {
+			t.Errorf("%s: unexpected match: %#v", test.name, match)
+		}
+	}
+}
+
+func TestAcceptingResolver(t *testing.T) {
+	searcher := metricsTestSearcher{matches: ComponentMatches{
+		{Name: "a", Value: "a", Score: 0.5, Image: &imageapi.DockerImage{}},
+		{Name: "b", Value: "b", Score: 0.5, ImageStream: &imageapi.ImageStream{}},
+	}}
+	resolver := PerfectMatchWeightedResolver{{Searcher: searcher}}
+	if _, err := resolver.Resolve("a"); err == nil {
+		t.Fatalf("expected the resolver to fail without a policy")
+	}
+	match, err := AcceptingResolver{Resolver: resolver, Policy: AcceptancePolicy{Threshold: 0.5, Order: DefaultMatchSourceOrder}}.Resolve("a")
+	if err != nil || match.Name != "b" {
+		t.Errorf("unexpected resolution: %#v %v", match, err)
+	}
+}
+
+func TestParseMatchSourceOrder(t *testing.T) {
+	order, err := ParseMatchSourceOrder("docker, imagestream")
+	if err != nil || !reflect.DeepEqual(order, []MatchSource{MatchSourceDockerImage, MatchSourceImageStream}) {
+		t.Errorf("unexpected order: %v %v", order, err)
+	}
+	if _, err := ParseMatchSourceOrder("imagestream,unknown"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"encoding/json"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+const (
+	// AffinityAnnotation declares the affinity of a pod to the schedulers that support it. Schedulers that
+	// predate pod affinity ignore the annotation.
+	AffinityAnnotation = "scheduler.alpha.kubernetes.io/affinity"
+
+	// hostnameTopologyKey is the node label that identifies a single node.
+	hostnameTopologyKey = "kubernetes.io/hostname"
+	// spreadReplicasWeight is the weight of the preference to spread replicas across nodes.
+	spreadReplicasWeight = 100
+)
+
+// affinity is the serialized form of the pod anti-affinity recorded in AffinityAnnotation.
+type affinity struct {
+	PodAntiAffinity *podAntiAffinity `json:"podAntiAffinity,omitempty"`
+}
+
+type podAntiAffinity struct {
+	Preferred []weightedPodAffinityTerm `json:"preferredDuringSchedulingIgnoredDuringExecution,omitempty"`
+}
+
+type weightedPodAffinityTerm struct {
+	Weight int             `json:"weight"`
+	Term   podAffinityTerm `json:"podAffinityTerm"`
+}
+
+type podAffinityTerm struct {
+	LabelSelector labelSelector `json:"labelSelector"`
+	TopologyKey   string        `json:"topologyKey"`
+}
+
+type labelSelector struct {
+	MatchLabels map[string]string `json:"matchLabels"`
+}
+
+// SpreadReplicas asks the scheduler to prefer placing the replicas of each deployment config in objects with
+// more than one replica on different nodes. Pod templates that already declare an affinity are left alone.
+func SpreadReplicas(objects Objects) error {
+	for _, obj := range objects {
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil || dc.Spec.Replicas < 2 || len(dc.Spec.Selector) == 0 {
+			continue
+		}
+		template := dc.Spec.Template
+		if _, ok := template.Annotations[AffinityAnnotation]; ok {
+			continue
+		}
+		data, err := json.Marshal(affinity{
+			PodAntiAffinity: &podAntiAffinity{
+				Preferred: []weightedPodAffinityTerm{{
+					Weight: spreadReplicasWeight,
+					Term: podAffinityTerm{
+						LabelSelector: labelSelector{MatchLabels: dc.Spec.Selector},
+						TopologyKey:   hostnameTopologyKey,
+					},
+				}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if template.Annotations == nil {
+			template.Annotations = make(map[string]string)
+		}
+		template.Annotations[AffinityAnnotation] = string(data)
+	}
+	return nil
+}
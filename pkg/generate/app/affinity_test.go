@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestSpreadReplicas(t *testing.T) {
+	dc := func(name string, replicas int, annotations map[string]string) *deployapi.DeploymentConfig {
+		return &deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: name},
+			Spec: deployapi.DeploymentConfigSpec{
+				Replicas: replicas,
+				Selector: map[string]string{"deploymentconfig": name},
+				Template: &kapi.PodTemplateSpec{ObjectMeta: kapi.ObjectMeta{Annotations: annotations}},
+			},
+		}
+	}
+	objects := Objects{
+		dc("web", 3, nil),
+		dc("db", 1, nil),
+		dc("custom", 2, map[string]string{AffinityAnnotation: "{}"}),
+	}
+	if err := SpreadReplicas(objects); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"podAntiAffinity":{"preferredDuringSchedulingIgnoredDuringExecution":[{"weight":100,"podAffinityTerm":{"labelSelector":{"matchLabels":{"deploymentconfig":"web"}},"topologyKey":"kubernetes.io/hostname"}}]}}`
+	if value := objects[0].(*deployapi.DeploymentConfig).Spec.Template.Annotations[AffinityAnnotation]; value != expected {
+		t.Errorf("unexpected affinity: %s", value)
+	}
+	if _, ok := objects[1].(*deployapi.DeploymentConfig).Spec.Template.Annotations[AffinityAnnotation]; ok {
+		t.Errorf("a single replica should not be spread")
+	}
+	if value := objects[2].(*deployapi.DeploymentConfig).Spec.Template.Annotations[AffinityAnnotation]; value != "{}" {
+		t.Errorf("an existing affinity should be kept: %s", value)
+	}
+}
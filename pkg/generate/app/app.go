@@ -14,6 +14,7 @@ import (
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/conversion"
 	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/sets"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
 	deployapi "github.com/openshift/origin/pkg/deploy/api"
@@ -103,6 +104,9 @@ type SourceRef struct {
 	SourceImage     *ImageRef
 	ImageSourcePath string
 	ImageDestPath   string
+	// NoSourceImageTrigger omits the image change trigger that rebuilds the build config when the image stream
+	// tag of SourceImage is updated.
+	NoSourceImageTrigger bool
 
 	DockerfileContents string
 
@@ -167,12 +171,16 @@ func (r *SourceRef) BuildSource() (*buildapi.BuildSource, []buildapi.BuildTrigge
 				DestinationDir: r.ImageDestPath,
 			},
 		}
-		triggers = append(triggers, buildapi.BuildTriggerPolicy{
-			Type: buildapi.ImageChangeBuildTriggerType,
-			ImageChange: &buildapi.ImageChangeTrigger{
-				From: &objRef,
-			},
-		})
+		// only image stream tags trigger builds, so source images pulled directly from a registry have no trigger
+		if !r.NoSourceImageTrigger && len(r.SourceImage.BuildTriggers()) > 0 {
+			from := objRef
+			triggers = append(triggers, buildapi.BuildTriggerPolicy{
+				Type: buildapi.ImageChangeBuildTriggerType,
+				ImageChange: &buildapi.ImageChangeTrigger{
+					From: &from,
+				},
+			})
+		}
 		source.Images = []buildapi.ImageSource{imgSrc}
 	}
 	return source, triggers
@@ -181,19 +189,32 @@ func (r *SourceRef) BuildSource() (*buildapi.BuildSource, []buildapi.BuildTrigge
 // BuildStrategyRef is a reference to a build strategy
 type BuildStrategyRef struct {
 	IsDockerBuild bool
-	Base          *ImageRef
+	// IsPipelineBuild is true if the build runs the Jenkinsfile of the source, in which case there is no base
+	// image.
+	IsPipelineBuild bool
+	Base            *ImageRef
+	// DockerfilePath is the path of the Dockerfile relative to the context directory, if it is not at
+	// the root of the context directory.
+	DockerfilePath string
 }
 
 // BuildStrategy builds an OpenShift BuildStrategy from a BuildStrategyRef
 func (s *BuildStrategyRef) BuildStrategy(env Environment) (*buildapi.BuildStrategy, []buildapi.BuildTriggerPolicy) {
+	if s.IsPipelineBuild {
+		return &buildapi.BuildStrategy{
+			JenkinsPipelineStrategy: &buildapi.JenkinsPipelineBuildStrategy{},
+		}, nil
+	}
 	if s.IsDockerBuild {
 		var triggers []buildapi.BuildTriggerPolicy
 		strategy := &buildapi.DockerBuildStrategy{
-			Env: env.List(),
+			Env:            env.List(),
+			DockerfilePath: s.DockerfilePath,
 		}
 		if s.Base != nil {
 			ref := s.Base.ObjectReference()
 			strategy.From = &ref
+			strategy.PullSecret = s.Base.pullSecret()
 			triggers = s.Base.BuildTriggers()
 		}
 		return &buildapi.BuildStrategy{
@@ -203,8 +224,9 @@ func (s *BuildStrategyRef) BuildStrategy(env Environment) (*buildapi.BuildStrate
 
 	return &buildapi.BuildStrategy{
 		SourceStrategy: &buildapi.SourceBuildStrategy{
-			From: s.Base.ObjectReference(),
-			Env:  env.List(),
+			From:       s.Base.ObjectReference(),
+			Env:        env.List(),
+			PullSecret: s.Base.pullSecret(),
 		},
 	}, s.Base.BuildTriggers()
 }
@@ -307,6 +329,7 @@ func (r *DeploymentConfigRef) DeploymentConfig() (*deployapi.DeploymentConfig, e
 	}
 
 	template := kapi.PodSpec{}
+	pullSecrets := sets.NewString()
 	for i := range r.Images {
 		c, containerTriggers, err := r.Images[i].DeployableContainer()
 		if err != nil {
@@ -314,6 +337,12 @@ func (r *DeploymentConfigRef) DeploymentConfig() (*deployapi.DeploymentConfig, e
 		}
 		triggers = append(triggers, containerTriggers...)
 		template.Containers = append(template.Containers, *c)
+		for _, name := range r.Images[i].PullSecrets {
+			if !pullSecrets.Has(name) {
+				pullSecrets.Insert(name)
+				template.ImagePullSecrets = append(template.ImagePullSecrets, kapi.LocalObjectReference{Name: name})
+			}
+		}
 	}
 
 	// Create EmptyDir volumes for all container volume mounts
@@ -332,6 +361,10 @@ func (r *DeploymentConfigRef) DeploymentConfig() (*deployapi.DeploymentConfig, e
 		template.Containers[i].Env = append(template.Containers[i].Env, r.Env.List()...)
 	}
 
+	if len(r.Images) > 0 && CheckDeploymentOS(r) == nil && ImageOS(r.Images[0]) == ImageOSWindows {
+		applyWindowsConstraints(&template)
+	}
+
 	return &deployapi.DeploymentConfig{
 		ObjectMeta: kapi.ObjectMeta{
 			Name: r.Name,
@@ -123,6 +123,39 @@ func TestSourceRefBuildSourceURI(t *testing.T) {
 	}
 }
 
+func TestSourceRefSourceImageTrigger(t *testing.T) {
+	stream := &ImageRef{
+		Reference:     imageapi.DockerImageReference{Name: "artifacts", Tag: "latest"},
+		AsImageStream: true,
+	}
+	docker := &ImageRef{Reference: imageapi.DockerImageReference{Namespace: "openshift", Name: "artifacts"}}
+	tests := []struct {
+		name      string
+		source    SourceRef
+		triggered bool
+	}{
+		{name: "image stream", source: SourceRef{SourceImage: stream}, triggered: true},
+		{name: "disabled", source: SourceRef{SourceImage: stream, NoSourceImageTrigger: true}},
+		{name: "docker image", source: SourceRef{SourceImage: docker}},
+	}
+	for _, test := range tests {
+		source, triggers := test.source.BuildSource()
+		if len(source.Images) != 1 {
+			t.Errorf("%s: unexpected image sources: %#v", test.name, source.Images)
+			continue
+		}
+		triggered := false
+		for _, trigger := range triggers {
+			if trigger.ImageChange != nil && trigger.ImageChange.From != nil && reflect.DeepEqual(*trigger.ImageChange.From, source.Images[0].From) {
+				triggered = true
+			}
+		}
+		if triggered != test.triggered {
+			t.Errorf("%s: unexpected triggers: %#v", test.name, triggers)
+		}
+	}
+}
+
 func TestGenerateSimpleDockerApp(t *testing.T) {
 	// TODO: determine if the repo is secured prior to fetching
 	// TODO: determine whether we want to clone this repo, or use it directly. Using it directly would require setting hooks
@@ -257,3 +290,70 @@ func TestImageStream(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckS2IBuilder(t *testing.T) {
+	image := func(labels map[string]string) *imageapi.DockerImage {
+		return &imageapi.DockerImage{Config: &imageapi.DockerConfig{Labels: labels}}
+	}
+	tests := map[string]struct {
+		image      *imageapi.DockerImage
+		contextDir string
+		labels     []string
+	}{
+		"no labels":               {image: image(nil)},
+		"no config":               {image: &imageapi.DockerImage{}},
+		"default destination":     {image: image(map[string]string{"io.openshift.s2i.destination": "/tmp"}), contextDir: "app"},
+		"custom destination":      {image: image(map[string]string{"io.openshift.s2i.destination": "/opt/app"})},
+		"destination context dir": {image: image(map[string]string{"io.openshift.s2i.destination": "/opt/app"}), contextDir: "app", labels: []string{"io.openshift.s2i.destination"}},
+		"relative destination":    {image: image(map[string]string{"io.openshift.s2i.destination": "opt"}), labels: []string{"io.openshift.s2i.destination"}},
+		"numeric assemble user":   {image: image(map[string]string{"io.openshift.s2i.assemble-user": "1001"})},
+		"root assemble user":      {image: image(map[string]string{"io.openshift.s2i.assemble-user": "root"}), labels: []string{"io.openshift.s2i.assemble-user"}},
+		"uid 0 assemble user":     {image: image(map[string]string{"io.openshift.s2i.assemble-user": "0:0"}), labels: []string{"io.openshift.s2i.assemble-user"}},
+		"named assemble user":     {image: image(map[string]string{"io.openshift.s2i.assemble-user": "default"}), labels: []string{"io.openshift.s2i.assemble-user"}},
+	}
+	for name, test := range tests {
+		labels := []string{}
+		for _, warning := range CheckS2IBuilder("builder", test.image, test.contextDir) {
+			labels = append(labels, warning.Label)
+		}
+		if len(labels) != len(test.labels) || (len(labels) > 0 && !reflect.DeepEqual(labels, test.labels)) {
+			t.Errorf("%s: expected warnings for %v, got %v", name, test.labels, labels)
+		}
+	}
+}
+
+func TestCheckBuilderCompatibility(t *testing.T) {
+	match := func(supports string) *ComponentMatch {
+		return &ComponentMatch{
+			Name: "builder",
+			ImageStream: &imageapi.ImageStream{Spec: imageapi.ImageStreamSpec{Tags: map[string]imageapi.TagReference{
+				"latest": {Annotations: map[string]string{"supports": supports}},
+			}}},
+			ImageTag: "latest",
+		}
+	}
+	info := func(types ...SourceLanguageType) *SourceRepositoryInfo {
+		return &SourceRepositoryInfo{Types: types}
+	}
+	tests := map[string]struct {
+		match        *ComponentMatch
+		info         *SourceRepositoryInfo
+		incompatible bool
+	}{
+		"same language":       {match: match("ruby:2.2,ruby"), info: info(SourceLanguageType{Platform: "ruby", Version: "2.2"})},
+		"unversioned source":  {match: match("ruby:2.2"), info: info(SourceLanguageType{Platform: "ruby"})},
+		"any detected term":   {match: match("nodejs"), info: info(SourceLanguageType{Platform: "ruby"}, SourceLanguageType{Platform: "nodejs"})},
+		"other language":      {match: match("nodejs:4,nodejs"), info: info(SourceLanguageType{Platform: "ruby"}), incompatible: true},
+		"other version":       {match: match("ruby:2.0"), info: info(SourceLanguageType{Platform: "ruby", Version: "2.2"}), incompatible: true},
+		"nothing declared":    {match: match(""), info: info(SourceLanguageType{Platform: "ruby"})},
+		"nothing detected":    {match: match("nodejs"), info: info()},
+		"no source info":      {match: match("nodejs")},
+		"not an image stream": {match: &ComponentMatch{Name: "builder"}, info: info(SourceLanguageType{Platform: "ruby"})},
+	}
+	for name, test := range tests {
+		err := CheckBuilderCompatibility(test.match, test.info)
+		if _, ok := err.(BuilderCompatibilityError); ok != test.incompatible || (err != nil && !ok) {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	kclient "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+const (
+	// GeneratedByUserAnnotation records the user that an object was generated for.
+	GeneratedByUserAnnotation = "openshift.io/generated-by-user"
+	// GeneratedFromAnnotation records the arguments that an object was generated from.
+	GeneratedFromAnnotation = "openshift.io/generated-from"
+	// GenerationEventReason is the reason of the events that record a generation.
+	GenerationEventReason = "Generated"
+)
+
+// GenerationRecord describes who generated an application from which arguments, so that project owners can
+// audit generation done on behalf of users, such as by the web console.
+type GenerationRecord struct {
+	// User is the user the application is generated for.
+	User string
+	// Component is the component that generated the application, such as the web console.
+	Component string
+	// Arguments are the source repositories, images and templates the application is generated from.
+	Arguments []string
+}
+
+// Annotate records the user and the arguments on each object in objects.
+func (r GenerationRecord) Annotate(objects Objects) {
+	for _, obj := range objects {
+		_, meta, err := objectMetaData(obj)
+		if err != nil {
+			continue
+		}
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+		if len(r.User) > 0 {
+			meta.Annotations[GeneratedByUserAnnotation] = r.User
+		}
+		if len(r.Arguments) > 0 {
+			meta.Annotations[GeneratedFromAnnotation] = strings.Join(r.Arguments, " ")
+		}
+	}
+}
+
+// Event returns an event in namespace that describes the generation of objects. The event refers to the first
+// object, since events must refer to an object in their namespace. Nil is returned if objects is empty.
+func (r GenerationRecord) Event(namespace string, objects Objects, now unversioned.Time) *kapi.Event {
+	generated := []string{}
+	var involved *kapi.ObjectReference
+	for _, obj := range objects {
+		gvk, err := kapi.Scheme.ObjectKind(obj)
+		if err != nil {
+			continue
+		}
+		_, meta, err := objectMetaData(obj)
+		if err != nil {
+			continue
+		}
+		generated = append(generated, fmt.Sprintf("%s/%s", gvk.Kind, meta.Name))
+		if involved == nil {
+			involved = &kapi.ObjectReference{Kind: gvk.Kind, APIVersion: gvk.GroupVersion().String(), Namespace: namespace, Name: meta.Name}
+		}
+	}
+	if involved == nil {
+		return nil
+	}
+
+	user := r.User
+	if len(user) == 0 {
+		user = "an unknown user"
+	}
+	message := fmt.Sprintf("Generated %s for %s", strings.Join(generated, ", "), user)
+	if len(r.Arguments) > 0 {
+		message = fmt.Sprintf("%s from %s", message, strings.Join(r.Arguments, " "))
+	}
+	return &kapi.Event{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", involved.Name, now.UnixNano()),
+			Namespace: namespace,
+		},
+		InvolvedObject: *involved,
+		Reason:         GenerationEventReason,
+		Message:        message,
+		Source:         kapi.EventSource{Component: r.Component},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Type:           kapi.EventTypeNormal,
+	}
+}
+
+// RecordGeneration creates the event that describes the generation of objects in namespace.
+func RecordGeneration(client kclient.EventNamespacer, namespace string, record GenerationRecord, objects Objects) error {
+	event := record.Event(namespace, objects, unversioned.Now())
+	if event == nil {
+		return nil
+	}
+	_, err := client.Events(namespace).Create(event)
+	return err
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	ktestclient "k8s.io/kubernetes/pkg/client/unversioned/testclient"
+)
+
+func TestGenerationRecord(t *testing.T) {
+	record := GenerationRecord{User: "alice", Component: "console", Arguments: []string{"ruby~https://github.com/openshift/ruby-ex"}}
+	objects := nameTemplateObjects()
+	record.Annotate(objects)
+	_, meta, _ := objectMetaData(objects[2])
+	if meta.Annotations[GeneratedByUserAnnotation] != "alice" || meta.Annotations[GeneratedFromAnnotation] != "ruby~https://github.com/openshift/ruby-ex" {
+		t.Errorf("unexpected annotations: %v", meta.Annotations)
+	}
+
+	client := &ktestclient.Fake{}
+	if err := RecordGeneration(client, "dev", record, objects); err != nil {
+		t.Fatal(err)
+	}
+	actions := client.Actions()
+	if len(actions) != 1 || !actions[0].Matches("create", "events") {
+		t.Fatalf("unexpected actions: %#v", actions)
+	}
+	event := actions[0].(ktestclient.CreateAction).GetObject().(*kapi.Event)
+	if event.Namespace != "dev" || event.InvolvedObject.Kind != "ImageStream" || event.InvolvedObject.Namespace != "dev" || event.Source.Component != "console" {
+		t.Errorf("unexpected event: %#v", event)
+	}
+	expected := "Generated ImageStream/ruby-ex, BuildConfig/ruby-ex, DeploymentConfig/ruby-ex, Service/ruby-ex for alice from ruby~https://github.com/openshift/ruby-ex"
+	if event.Message != expected {
+		t.Errorf("unexpected message: %s", event.Message)
+	}
+
+	client = &ktestclient.Fake{}
+	if err := RecordGeneration(client, "dev", record, Objects{}); err != nil || len(client.Actions()) != 0 {
+		t.Errorf("unexpected actions %#v and error: %v", client.Actions(), err)
+	}
+}
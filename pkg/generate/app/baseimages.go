@@ -0,0 +1,67 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// BaseImageOriginalAnnotation records the Dockerfile base image a generated build config was written for
+// before it was replaced with an approved mirror.
+const BaseImageOriginalAnnotation = "openshift.io/base-image.original"
+
+// BaseImageMirrors maps the base images of Dockerfiles to the approved images that must be built from
+// instead. A key with a tag or image ID matches only that image, while a key without one matches every
+// tag of the repository. A mirror without a tag or image ID keeps the tag or ID of the original image.
+type BaseImageMirrors map[string]string
+
+// Mirror returns the approved mirror for image, or false if no entry of the mapping matches it. Entries
+// with a tag or image ID take precedence over entries naming the whole repository.
+func (m BaseImageMirrors) Mirror(image string) (string, bool, error) {
+	if len(m) == 0 {
+		return "", false, nil
+	}
+	ref, err := imageapi.ParseDockerImageReference(image)
+	if err != nil {
+		return "", false, err
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var repositoryMatch string
+	for _, k := range keys {
+		from, err := imageapi.ParseDockerImageReference(k)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid base image %q: %v", k, err)
+		}
+		switch {
+		case len(from.Tag) > 0 || len(from.ID) > 0:
+			if from.Equal(ref) {
+				return mirrorOf(ref, m[k])
+			}
+		case len(repositoryMatch) == 0 && from.AsRepository().Equal(ref.AsRepository()):
+			repositoryMatch = k
+		}
+	}
+	if len(repositoryMatch) > 0 {
+		return mirrorOf(ref, m[repositoryMatch])
+	}
+	return "", false, nil
+}
+
+// mirrorOf returns the mirror image for ref, keeping the tag or image ID of ref if the mirror has neither.
+func mirrorOf(ref imageapi.DockerImageReference, mirror string) (string, bool, error) {
+	to, err := imageapi.ParseDockerImageReference(mirror)
+	if err != nil {
+		return "", false, fmt.Errorf("invalid base image mirror %q: %v", mirror, err)
+	}
+	if len(to.Tag) == 0 && len(to.ID) == 0 {
+		to.Tag, to.ID = ref.Tag, ref.ID
+	}
+	return to.Exact(), true, nil
+}
@@ -0,0 +1,41 @@
+package app
+
+import "testing"
+
+func TestBaseImageMirrors(t *testing.T) {
+	mirrors := BaseImageMirrors{
+		"centos":                   "mirror.example.com/base/centos",
+		"centos:6":                 "mirror.example.com/legacy/centos:6.8",
+		"registry.example.com/app": "mirror.example.com/app:stable",
+	}
+	tests := []struct {
+		image  string
+		mirror string
+		ok     bool
+	}{
+		{image: "centos", mirror: "mirror.example.com/base/centos"},
+		{image: "centos:7", mirror: "mirror.example.com/base/centos:7"},
+		{image: "docker.io/library/centos:7", mirror: "mirror.example.com/base/centos:7"},
+		{image: "centos:6", mirror: "mirror.example.com/legacy/centos:6.8"},
+		{image: "registry.example.com/app:1.0", mirror: "mirror.example.com/app:stable"},
+		{image: "fedora:24"},
+		{image: "registry.example.com/centos:7"},
+	}
+	for _, test := range tests {
+		mirror, ok, err := mirrors.Mirror(test.image)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.image, err)
+			continue
+		}
+		if ok != (len(test.mirror) > 0) || mirror != test.mirror {
+			t.Errorf("%s: unexpected mirror %q %t", test.image, mirror, ok)
+		}
+	}
+
+	if _, _, err := (BaseImageMirrors{"centos": "a/b/c/d"}).Mirror("centos"); err == nil {
+		t.Errorf("expected an error for an invalid mirror")
+	}
+	if _, ok, err := (BaseImageMirrors(nil)).Mirror("centos"); ok || err != nil {
+		t.Errorf("unexpected result for an empty mapping: %t %v", ok, err)
+	}
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"k8s.io/kubernetes/pkg/util/errors"
@@ -49,6 +50,84 @@ func IsBuilderStreamTag(stream *imageapi.ImageStream, tag string) bool {
 	return false
 }
 
+const (
+	s2iDestinationLabel  = "io.openshift.s2i.destination"
+	s2iAssembleUserLabel = "io.openshift.s2i.assemble-user"
+
+	// defaultS2IDestination is the directory S2I injects source into when the builder does not declare one.
+	defaultS2IDestination = "/tmp"
+)
+
+// BuilderWarning describes a builder image label that is likely to cause the generated build to fail. A build
+// config cannot override the S2I destination or assemble user of its builder image, so the generated build configs
+// are left unchanged and the problems are only reported.
+type BuilderWarning struct {
+	// Builder is the name of the builder image.
+	Builder string
+	// Label is the label of the builder image that causes the problem.
+	Label string
+	// Message describes the problem.
+	Message string
+}
+
+func (w BuilderWarning) String() string {
+	return fmt.Sprintf("builder %q: %s", w.Builder, w.Message)
+}
+
+// CheckS2IBuilder inspects the S2I labels of the builder image for combinations that are known to make a
+// source build from contextDir fail.
+func CheckS2IBuilder(name string, image *imageapi.DockerImage, contextDir string) []BuilderWarning {
+	if image == nil || image.Config == nil {
+		return nil
+	}
+	var warnings []BuilderWarning
+	labels := image.Config.Labels
+	if destination, ok := labels[s2iDestinationLabel]; ok {
+		switch {
+		case !strings.HasPrefix(destination, "/"):
+			warnings = append(warnings, BuilderWarning{
+				Builder: name,
+				Label:   s2iDestinationLabel,
+				Message: fmt.Sprintf("the source destination %q is not an absolute path and will be rejected by the build", destination),
+			})
+		case len(contextDir) > 0 && strings.TrimRight(destination, "/") != defaultS2IDestination:
+			warnings = append(warnings, BuilderWarning{
+				Builder: name,
+				Label:   s2iDestinationLabel,
+				Message: fmt.Sprintf("only the context directory %q will be injected into %s/src, which the builder's scripts may not expect", contextDir, strings.TrimRight(destination, "/")),
+			})
+		}
+	}
+	if user, ok := labels[s2iAssembleUserLabel]; ok {
+		uid := user
+		if i := strings.Index(user, ":"); i != -1 {
+			uid = user[:i]
+		}
+		if id, err := strconv.Atoi(uid); err != nil {
+			if uid == "root" {
+				warnings = append(warnings, BuilderWarning{
+					Builder: name,
+					Label:   s2iAssembleUserLabel,
+					Message: "the assemble script runs as 'root', which is rejected when builds are restricted to non-root users",
+				})
+			} else {
+				warnings = append(warnings, BuilderWarning{
+					Builder: name,
+					Label:   s2iAssembleUserLabel,
+					Message: fmt.Sprintf("the assemble user %q is not numeric and cannot be verified to be non-root, so builds restricted to non-root users will fail", uid),
+				})
+			}
+		} else if id == 0 {
+			warnings = append(warnings, BuilderWarning{
+				Builder: name,
+				Label:   s2iAssembleUserLabel,
+				Message: "the assemble script runs as UID 0, which is rejected when builds are restricted to non-root users",
+			})
+		}
+	}
+	return warnings
+}
+
 func IsBuilderMatch(match *ComponentMatch) bool {
 	if match.Image != nil && IsBuilderImage(match.Image) {
 		return true
@@ -161,3 +240,55 @@ func GeneratorInputFromMatch(match *ComponentMatch) (GeneratorInput, error) {
 	}
 	return input, errors.NewAggregate(errs)
 }
+
+// BuilderCompatibilityError is returned when a builder declares the languages it supports and none of them
+// matches the languages detected in the source.
+type BuilderCompatibilityError struct {
+	// Builder is the name of the builder match.
+	Builder string
+	// Detected are the terms detected in the source, for instance ruby:2.2.
+	Detected []string
+	// Supports are the terms the builder declares support for.
+	Supports []string
+}
+
+func (e BuilderCompatibilityError) Error() string {
+	return fmt.Sprintf("the builder %q supports %s, but the source was detected as %s", e.Builder, strings.Join(e.Supports, ", "), strings.Join(e.Detected, ", "))
+}
+
+// BuilderSupports returns the terms the builder of match declares support for in the 'supports' annotation
+// of its image stream tag.
+func BuilderSupports(match *ComponentMatch) []string {
+	if match == nil || match.ImageStream == nil {
+		return nil
+	}
+	tag, ok := match.ImageStream.Spec.Tags[match.ImageTag]
+	if !ok {
+		return nil
+	}
+	var supports []string
+	for _, s := range strings.Split(tag.Annotations[supportsAnnotationKey], ",") {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			supports = append(supports, s)
+		}
+	}
+	return supports
+}
+
+// CheckBuilderCompatibility returns a BuilderCompatibilityError if the builder of match declares the terms
+// it supports and none of them matches a term detected in the source, by name and by version if both have
+// one. Builders that declare nothing and sources without detected terms are assumed to be compatible.
+func CheckBuilderCompatibility(match *ComponentMatch, info *SourceRepositoryInfo) error {
+	supports := BuilderSupports(match)
+	if len(supports) == 0 || info == nil || len(info.Types) == 0 {
+		return nil
+	}
+	detected := info.Terms()
+	annotation := strings.Join(supports, ",")
+	for _, term := range detected {
+		if score, ok := matchSupportsAnnotation(term, annotation); ok && score < supportsVersionMismatchScore {
+			return nil
+		}
+	}
+	return BuilderCompatibilityError{Builder: match.Name, Detected: detected, Supports: supports}
+}
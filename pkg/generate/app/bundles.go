@@ -0,0 +1,204 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// BundleScheme prefixes the component terms that reference a bundle of a catalog, in the form
+// bundle://<catalog>/<bundle>.
+const BundleScheme = "bundle://"
+
+// IsBundleReference returns true if s references a bundle of a catalog.
+func IsBundleReference(s string) bool {
+	return strings.HasPrefix(s, BundleScheme)
+}
+
+// ParseBundleReference returns the catalog and the name of the bundle s references.
+func ParseBundleReference(s string) (string, string, error) {
+	if !IsBundleReference(s) {
+		return "", "", fmt.Errorf("%q is not a bundle reference, it must start with %s", s, BundleScheme)
+	}
+	parts := strings.Split(strings.TrimPrefix(s, BundleScheme), "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", fmt.Errorf("the bundle reference %q must be in the form %s<catalog>/<bundle>", s, BundleScheme)
+	}
+	return parts[0], parts[1], nil
+}
+
+// Catalog is a curated set of bundles, read from a YAML or JSON file.
+type Catalog struct {
+	Bundles []Bundle `json:"bundles"`
+}
+
+// Bundle is a named set of components that are generated together, such as the services of a multi-service
+// stack. The images and image streams of its components are pinned to a version, so that every application
+// generated from the bundle uses the same ones.
+type Bundle struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Components  []BundleComponent `json:"components"`
+}
+
+// BundleComponent is a component of a bundle. Exactly one of Image, ImageStream and Template is set.
+type BundleComponent struct {
+	// Image is a Docker image with a tag other than latest or a digest.
+	Image string `json:"image,omitempty"`
+	// ImageStream is an image stream tag other than latest, or an image stream image.
+	ImageStream string `json:"imageStream,omitempty"`
+	// Template is the name of a template.
+	Template string `json:"template,omitempty"`
+	// Source, if set, is a source repository built with the image or image stream.
+	Source string `json:"source,omitempty"`
+}
+
+// Validate returns an error if the component does not set exactly one kind, or if its image or image stream is
+// not pinned to a version.
+func (c BundleComponent) Validate() error {
+	set := []string{}
+	for _, value := range []string{c.Image, c.ImageStream, c.Template} {
+		if len(value) > 0 {
+			set = append(set, value)
+		}
+	}
+	if len(set) != 1 {
+		return fmt.Errorf("a component must set exactly one of image, imageStream and template")
+	}
+	if len(c.Template) > 0 {
+		if len(c.Source) > 0 {
+			return fmt.Errorf("template %q cannot build source", c.Template)
+		}
+		return nil
+	}
+	value := set[0]
+	ref, err := imageapi.ParseDockerImageReference(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a valid image reference: %v", value, err)
+	}
+	if len(ref.ID) == 0 && (len(ref.Tag) == 0 || ref.Tag == imageapi.DefaultImageTag) {
+		return fmt.Errorf("%q must be pinned to a tag other than %s or to a digest", value, imageapi.DefaultImageTag)
+	}
+	return nil
+}
+
+// Term returns the component in the same form as a new-app argument.
+func (c BundleComponent) Term() string {
+	value := c.Image
+	switch {
+	case len(c.ImageStream) > 0:
+		value = c.ImageStream
+	case len(c.Template) > 0:
+		value = c.Template
+	}
+	if len(c.Source) > 0 {
+		return fmt.Sprintf("%s~%s", value, c.Source)
+	}
+	return value
+}
+
+// ReadCatalog reads a catalog from a YAML or JSON file and validates its bundles.
+func ReadCatalog(path string) (*Catalog, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	catalog := &Catalog{}
+	if err := yaml.Unmarshal(data, catalog); err != nil {
+		return nil, fmt.Errorf("the catalog %s is invalid: %v", path, err)
+	}
+	for _, bundle := range catalog.Bundles {
+		if len(bundle.Name) == 0 {
+			return nil, fmt.Errorf("the catalog %s has a bundle without a name", path)
+		}
+		if len(bundle.Components) == 0 {
+			return nil, fmt.Errorf("bundle %q of the catalog %s has no components", bundle.Name, path)
+		}
+		for _, component := range bundle.Components {
+			if err := component.Validate(); err != nil {
+				return nil, fmt.Errorf("bundle %q of the catalog %s: %v", bundle.Name, path, err)
+			}
+		}
+	}
+	return catalog, nil
+}
+
+// BundleSearcher resolves bundle references to the bundles of the catalogs they name.
+type BundleSearcher struct {
+	// Catalogs maps the names of catalogs to the files they are read from.
+	Catalogs map[string]string
+	// Dir, if set, is the directory the catalogs that are not in Catalogs are read from, as <catalog>.yaml or
+	// <catalog>.json.
+	Dir string
+}
+
+// Search returns a match for each term that references a bundle of a catalog. Terms that are not bundle
+// references are ignored.
+func (s BundleSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	matches := ComponentMatches{}
+	var errs []error
+	for _, term := range terms {
+		if !IsBundleReference(term) {
+			continue
+		}
+		name, bundleName, err := ParseBundleReference(term)
+		if err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+			continue
+		}
+		path, ok := s.catalogPath(name)
+		if !ok {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the catalog %q of %s is not known, specify the file it is read from with --catalog=%s=<file>", name, term, name))
+			continue
+		}
+		catalog, err := ReadCatalog(path)
+		if err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+			continue
+		}
+		for i := range catalog.Bundles {
+			bundle := &catalog.Bundles[i]
+			if bundle.Name != bundleName {
+				continue
+			}
+			matches = append(matches, &ComponentMatch{
+				Value:       term,
+				Argument:    term,
+				Name:        fmt.Sprintf("%s/%s", name, bundle.Name),
+				Description: bundle.Description,
+				Bundle:      bundle,
+			})
+			break
+		}
+	}
+	return matches, errs
+}
+
+// Capabilities returns the capabilities of a searcher of local files.
+func (s BundleSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
+
+// catalogPath returns the file the named catalog is read from, and whether it exists.
+func (s BundleSearcher) catalogPath(name string) (string, bool) {
+	if path, ok := s.Catalogs[name]; ok {
+		return path, true
+	}
+	if len(s.Dir) == 0 {
+		return "", false
+	}
+	for _, ext := range []string{".yaml", ".json"} {
+		path := filepath.Join(s.Dir, name+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+const testCatalog = `
+bundles:
+- name: payments-stack
+  description: The payments API and its database
+  components:
+  - image: centos/ruby-22-centos7:2.2
+    source: https://github.com/acme/payments-api
+  - imageStream: openshift/mysql:5.6
+  - template: redis-persistent
+- name: cache
+  components:
+  - image: redis@sha256:958fa10ad4e5a0ec6a5e8b2f4a6c5e8d05e9a8f4d7e4b7f1c3b3c4e8d2b7e3c1
+`
+
+func TestParseBundleReference(t *testing.T) {
+	tests := []struct {
+		value   string
+		catalog string
+		bundle  string
+		err     bool
+	}{
+		{value: "bundle://my-catalog/payments-stack", catalog: "my-catalog", bundle: "payments-stack"},
+		{value: "bundle://my-catalog", err: true},
+		{value: "bundle://my-catalog/a/b", err: true},
+		{value: "bundle:///payments-stack", err: true},
+		{value: "my-catalog/payments-stack", err: true},
+	}
+	for _, test := range tests {
+		catalog, bundle, err := ParseBundleReference(test.value)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error: %v", test.value, err)
+			continue
+		}
+		if catalog != test.catalog || bundle != test.bundle {
+			t.Errorf("%s: unexpected %q %q", test.value, catalog, bundle)
+		}
+	}
+}
+
+func TestBundleComponentValidate(t *testing.T) {
+	tests := []struct {
+		component BundleComponent
+		term      string
+		err       bool
+	}{
+		{component: BundleComponent{Image: "ruby:2.2", Source: "https://github.com/acme/api"}, term: "ruby:2.2~https://github.com/acme/api"},
+		{component: BundleComponent{ImageStream: "openshift/mysql:5.6"}, term: "openshift/mysql:5.6"},
+		{component: BundleComponent{ImageStream: "mysql@sha256:958fa10ad4e5a0ec6a5e8b2f4a6c5e8d05e9a8f4d7e4b7f1c3b3c4e8d2b7e3c1"}, term: "mysql@sha256:958fa10ad4e5a0ec6a5e8b2f4a6c5e8d05e9a8f4d7e4b7f1c3b3c4e8d2b7e3c1"},
+		{component: BundleComponent{Template: "redis-persistent"}, term: "redis-persistent"},
+		{component: BundleComponent{Image: "ruby"}, err: true},
+		{component: BundleComponent{ImageStream: "mysql:latest"}, err: true},
+		{component: BundleComponent{Template: "redis", Source: "https://github.com/acme/api"}, err: true},
+		{component: BundleComponent{Image: "ruby:2.2", Template: "redis"}, err: true},
+		{component: BundleComponent{}, err: true},
+	}
+	for _, test := range tests {
+		err := test.component.Validate()
+		if (err != nil) != test.err {
+			t.Errorf("%#v: unexpected error: %v", test.component, err)
+			continue
+		}
+		if !test.err && test.component.Term() != test.term {
+			t.Errorf("%#v: unexpected term %q", test.component, test.component.Term())
+		}
+	}
+}
+
+func TestBundleSearcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "acme.yaml")
+	if err := ioutil.WriteFile(path, []byte(testCatalog), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(dir, "invalid.yaml")
+	if err := ioutil.WriteFile(invalid, []byte("bundles:\n- name: stack\n  components:\n  - image: ruby\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	searcher := BundleSearcher{Catalogs: map[string]string{"my-catalog": path}, Dir: dir}
+	matches, errs := searcher.Search(true, "bundle://my-catalog/payments-stack", "bundle://acme/cache", "ruby")
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("unexpected matches: %#v", matches)
+	}
+	if m := matches[0]; m.Name != "my-catalog/payments-stack" || m.Bundle == nil || len(m.Bundle.Components) != 3 || m.Score != 0 {
+		t.Errorf("unexpected match: %#v", m)
+	}
+	if m := matches[1]; m.Name != "acme/cache" || m.Bundle == nil || len(m.Bundle.Components) != 1 {
+		t.Errorf("unexpected match: %#v", m)
+	}
+
+	if matches, errs := searcher.Search(true, "bundle://my-catalog/missing"); len(matches) != 0 || len(errs) != 0 {
+		t.Errorf("unexpected result: %#v %v", matches, errs)
+	}
+	for _, term := range []string{"bundle://unknown/stack", "bundle://invalid/stack"} {
+		matches, errs := searcher.Search(true, term)
+		if len(matches) != 0 || len(errs) != 1 || generrors.CodeOf(errs[0]) != generrors.CodeInvalidArgument {
+			t.Errorf("%s: unexpected result: %#v %v", term, matches, errs)
+		}
+	}
+}
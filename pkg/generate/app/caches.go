@@ -0,0 +1,92 @@
+package app
+
+import (
+	"strings"
+	"sync"
+)
+
+// SearchCache holds the results of searches so that generations that share it, such as the applications of a
+// batch, search registries and the server once for each term.
+type SearchCache struct {
+	lock    sync.Mutex
+	results map[string]cachedSearch
+}
+
+type cachedSearch struct {
+	matches ComponentMatches
+	errs    []error
+}
+
+// NewSearchCache creates an empty search cache.
+func NewSearchCache() *SearchCache {
+	return &SearchCache{results: make(map[string]cachedSearch)}
+}
+
+// CachingSearcher returns the results of earlier searches of the wrapped searcher with the same terms from
+// its cache. Scope distinguishes searchers whose results for the same terms differ.
+type CachingSearcher struct {
+	Searcher Searcher
+	Cache    *SearchCache
+	Scope    string
+}
+
+// Search returns a copy of the cached matches for terms, searching with the wrapped searcher if there are none.
+func (s CachingSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	key := strings.Join(append([]string{s.Scope, boolString(precise)}, terms...), "\x00")
+	s.Cache.lock.Lock()
+	cached, ok := s.Cache.results[key]
+	s.Cache.lock.Unlock()
+	if !ok {
+		cached.matches, cached.errs = s.Searcher.Search(precise, terms...)
+		s.Cache.lock.Lock()
+		s.Cache.results[key] = cached
+		s.Cache.lock.Unlock()
+	}
+	// resolution scores and updates the matches it is given
+	matches := make(ComponentMatches, 0, len(cached.matches))
+	for _, match := range cached.matches {
+		copied := *match
+		matches = append(matches, &copied)
+	}
+	return matches, cached.errs
+}
+
+// Capabilities returns the capabilities of the wrapped searcher.
+func (s CachingSearcher) Capabilities() SearcherCapabilities {
+	return s.Searcher.Capabilities()
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// CloneCache holds the local clones of remote source repositories so that generations that share it, such as
+// the applications of a batch, clone each repository once.
+type CloneCache struct {
+	lock sync.Mutex
+	dirs map[string]string
+}
+
+// NewCloneCache creates an empty clone cache.
+func NewCloneCache() *CloneCache {
+	return &CloneCache{dirs: make(map[string]string)}
+}
+
+// Clone returns the directory that the repository identified by location was cloned into, calling clone to
+// clone it if it has not been cloned yet. Failed clones are not cached.
+func (c *CloneCache) Clone(location string, clone func() (string, error)) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if dir, ok := c.dirs[location]; ok {
+		return dir, nil
+	}
+	dir, err := clone()
+	if err != nil {
+		return "", err
+	}
+	c.dirs[location] = dir
+	return dir, nil
+}
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"errors"
+	"testing"
+)
+
+// countingSearcher counts its searches.
+type countingSearcher struct {
+	searches *int
+}
+
+func (s countingSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	*s.searches++
+	return ComponentMatches{{Name: terms[0], Score: 0.5}}, nil
+}
+
+func (s countingSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
+
+func TestCachingSearcher(t *testing.T) {
+	searches := 0
+	searcher := countingSearcher{searches: &searches}
+	cache := NewSearchCache()
+	first := CachingSearcher{Searcher: searcher, Cache: cache, Scope: "docker"}
+	second := CachingSearcher{Searcher: searcher, Cache: cache, Scope: "docker"}
+
+	matches, _ := first.Search(true, "ruby")
+	matches[0].Score = 0
+	matches, _ = second.Search(true, "ruby")
+	if searches != 1 || len(matches) != 1 || matches[0].Score != 0.5 {
+		t.Errorf("unexpected searches %d and matches: %#v", searches, matches)
+	}
+	second.Search(false, "ruby")
+	CachingSearcher{Searcher: searcher, Cache: cache, Scope: "image-stream"}.Search(true, "ruby")
+	if searches != 3 {
+		t.Errorf("unexpected searches: %d", searches)
+	}
+}
+
+func TestCloneCache(t *testing.T) {
+	cache := NewCloneCache()
+	clones := 0
+	clone := func() (string, error) {
+		clones++
+		return "/tmp/clone", nil
+	}
+	for i := 0; i < 2; i++ {
+		if dir, err := cache.Clone("https://github.com/openshift/ruby-ex", clone); err != nil || dir != "/tmp/clone" {
+			t.Errorf("unexpected clone %s: %v", dir, err)
+		}
+	}
+	if clones != 1 {
+		t.Errorf("unexpected clones: %d", clones)
+	}
+	failed := func() (string, error) { return "", errors.New("unreachable") }
+	if _, err := cache.Clone("https://github.com/openshift/nodejs-ex", failed); err == nil {
+		t.Errorf("expected an error")
+	}
+	if _, err := cache.Clone("https://github.com/openshift/nodejs-ex", clone); err != nil || clones != 2 {
+		t.Errorf("a failed clone was cached: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/sets"
+	kuval "k8s.io/kubernetes/pkg/util/validation"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+// StorageClassAnnotation requests a class of storage for a persistent volume claim.
+const StorageClassAnnotation = "volume.alpha.kubernetes.io/storage-class"
+
+// ClusterProfile describes how the objects generated for an application differ on one of the clusters it is
+// deployed to.
+type ClusterProfile struct {
+	// Name identifies the cluster.
+	Name string
+	// RegistryHost, if set, is the registry that Docker image outputs are pushed to on the cluster. Containers
+	// that run the outputs pull them from the same registry.
+	RegistryHost string
+	// RouteDomain, if set, is the domain of the router of the cluster. Routes without a host are given the host
+	// <route>-<namespace>.<domain>.
+	RouteDomain string
+	// StorageClass, if set, is requested by the persistent volume claims.
+	StorageClass string
+}
+
+// ValidateClusterProfiles returns an error if a profile has no name, shares its name with another profile, or
+// has an invalid registry host or route domain.
+func ValidateClusterProfiles(profiles []ClusterProfile) error {
+	names := sets.NewString()
+	for _, profile := range profiles {
+		if len(profile.Name) == 0 {
+			return fmt.Errorf("every cluster profile must have a name")
+		}
+		if names.Has(profile.Name) {
+			return fmt.Errorf("the cluster profile %q is specified more than once", profile.Name)
+		}
+		names.Insert(profile.Name)
+		if len(profile.RegistryHost) > 0 {
+			if ref, err := imageapi.ParseDockerImageReference(profile.RegistryHost + "/namespace/image"); err != nil || ref.Registry != profile.RegistryHost {
+				return fmt.Errorf("the registry host %q of the cluster profile %q must be a host name with an optional port", profile.RegistryHost, profile.Name)
+			}
+		}
+		if len(profile.RouteDomain) > 0 && !kuval.IsDNS1123Subdomain(profile.RouteDomain) {
+			return fmt.Errorf("the route domain %q of the cluster profile %q is not a valid domain", profile.RouteDomain, profile.Name)
+		}
+	}
+	return nil
+}
+
+// ForCluster returns a copy of objects with the differences of profile applied. Namespace is the namespace of
+// the application, which is part of the host of routes.
+func ForCluster(objects Objects, profile ClusterProfile, namespace string) (Objects, error) {
+	result := Objects{}
+	for _, obj := range objects {
+		copied, err := kapi.Scheme.DeepCopy(obj)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, copied.(runtime.Object))
+	}
+
+	images := make(map[string]string)
+	for _, obj := range result {
+		bc, ok := obj.(*buildapi.BuildConfig)
+		if !ok || len(profile.RegistryHost) == 0 {
+			continue
+		}
+		to := bc.Spec.Output.To
+		if to == nil || to.Kind != "DockerImage" {
+			continue
+		}
+		ref, err := imageapi.ParseDockerImageReference(to.Name)
+		if err != nil {
+			return nil, fmt.Errorf("the output of the build config %q is not a valid image: %v", bc.Name, err)
+		}
+		ref.Registry = profile.RegistryHost
+		images[to.Name] = ref.String()
+		to.Name = ref.String()
+	}
+
+	for _, obj := range result {
+		switch t := obj.(type) {
+		case *deployapi.DeploymentConfig:
+			if t.Spec.Template == nil {
+				continue
+			}
+			for i := range t.Spec.Template.Spec.Containers {
+				container := &t.Spec.Template.Spec.Containers[i]
+				if image, ok := images[container.Image]; ok {
+					container.Image = image
+				}
+			}
+		case *routeapi.Route:
+			if len(profile.RouteDomain) == 0 || len(t.Spec.Host) > 0 {
+				continue
+			}
+			host := t.Name
+			if len(namespace) > 0 {
+				host = fmt.Sprintf("%s-%s", host, namespace)
+			}
+			t.Spec.Host = fmt.Sprintf("%s.%s", host, profile.RouteDomain)
+		case *kapi.PersistentVolumeClaim:
+			if len(profile.StorageClass) == 0 {
+				continue
+			}
+			if t.Annotations == nil {
+				t.Annotations = make(map[string]string)
+			}
+			t.Annotations[StorageClassAnnotation] = profile.StorageClass
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+func TestForCluster(t *testing.T) {
+	bc := &buildapi.BuildConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "app"},
+		Spec: buildapi.BuildConfigSpec{
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "DockerImage", Name: "172.30.1.1:5000/dev/app:latest"}},
+			},
+		},
+	}
+	dc := &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "app"},
+		Spec: deployapi.DeploymentConfigSpec{
+			Template: &kapi.PodTemplateSpec{
+				Spec: kapi.PodSpec{Containers: []kapi.Container{
+					{Name: "app", Image: "172.30.1.1:5000/dev/app:latest"},
+					{Name: "proxy", Image: "nginx"},
+				}},
+			},
+		},
+	}
+	route := &routeapi.Route{ObjectMeta: kapi.ObjectMeta{Name: "app"}}
+	hosted := &routeapi.Route{ObjectMeta: kapi.ObjectMeta{Name: "www"}, Spec: routeapi.RouteSpec{Host: "www.example.com"}}
+	claim := &kapi.PersistentVolumeClaim{ObjectMeta: kapi.ObjectMeta{Name: "data"}}
+	objects := Objects{bc, dc, route, hosted, claim}
+
+	profile := ClusterProfile{Name: "east", RegistryHost: "registry.east.example.com", RouteDomain: "apps.east.example.com", StorageClass: "fast"}
+	if err := ValidateClusterProfiles([]ClusterProfile{profile}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ForCluster(objects, profile, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if to := result[0].(*buildapi.BuildConfig).Spec.Output.To; to.Name != "registry.east.example.com/dev/app:latest" {
+		t.Errorf("unexpected output: %#v", to)
+	}
+	containers := result[1].(*deployapi.DeploymentConfig).Spec.Template.Spec.Containers
+	if containers[0].Image != "registry.east.example.com/dev/app:latest" || containers[1].Image != "nginx" {
+		t.Errorf("unexpected containers: %#v", containers)
+	}
+	if host := result[2].(*routeapi.Route).Spec.Host; host != "app-dev.apps.east.example.com" {
+		t.Errorf("unexpected route host: %s", host)
+	}
+	if host := result[3].(*routeapi.Route).Spec.Host; host != "www.example.com" {
+		t.Errorf("unexpected route host: %s", host)
+	}
+	if class := result[4].(*kapi.PersistentVolumeClaim).Annotations[StorageClassAnnotation]; class != "fast" {
+		t.Errorf("unexpected storage class: %s", class)
+	}
+
+	if bc.Spec.Output.To.Name != "172.30.1.1:5000/dev/app:latest" || len(route.Spec.Host) != 0 || claim.Annotations != nil {
+		t.Errorf("the generated objects were modified")
+	}
+}
+
+func TestValidateClusterProfiles(t *testing.T) {
+	for _, profiles := range [][]ClusterProfile{
+		{{}},
+		{{Name: "east"}, {Name: "east"}},
+		{{Name: "east", RegistryHost: "registry/path"}},
+		{{Name: "east", RouteDomain: "Apps_East"}},
+	} {
+		if err := ValidateClusterProfiles(profiles); err == nil {
+			t.Errorf("expected %#v to be invalid", profiles)
+		}
+	}
+}
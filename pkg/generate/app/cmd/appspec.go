@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	"github.com/openshift/origin/pkg/generate/app"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+// AppSpec is a declarative description of the input to new-app, read from a YAML or JSON file so that complex
+// invocations can be versioned and reviewed.
+type AppSpec struct {
+	// Name is used for the generated objects.
+	Name string `json:"name,omitempty"`
+	// Components are images, image streams, templates or template files, in the same form as new-app arguments.
+	Components []string `json:"components,omitempty"`
+	// Repos are source repositories to build.
+	Repos []string `json:"repos,omitempty"`
+	// ContextDir is the context directory of the builds.
+	ContextDir string `json:"contextDir,omitempty"`
+	// Strategy is the build strategy, docker or source.
+	Strategy string `json:"strategy,omitempty"`
+	// Env is set in every generated container.
+	Env map[string]string `json:"env,omitempty"`
+	// Labels are set on every generated object.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Volumes are mounted into every generated container.
+	Volumes []app.Volume `json:"volumes,omitempty"`
+	// Secrets are mounted into every generated container, in the form name:path[:mode].
+	Secrets []string `json:"secrets,omitempty"`
+	// Expose generates routes for the generated services.
+	Expose bool `json:"expose,omitempty"`
+	// NoRoute disables the routes generated for services that expose an HTTP port.
+	NoRoute bool `json:"noRoute,omitempty"`
+	// Resources are the default limits and requests of generated containers.
+	Resources kapi.ResourceRequirements `json:"resources,omitempty"`
+	// Preset is the name of the preset applied to the generated objects.
+	Preset string `json:"preset,omitempty"`
+}
+
+// ReadAppSpec reads an AppSpec from a YAML or JSON file.
+func ReadAppSpec(path string) (*AppSpec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &AppSpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "the app spec %s is invalid: %v", path, err)
+	}
+	return spec, nil
+}
+
+// Apply adds the spec to c. Lists are appended to the values already set on c, and values already set on c take
+// precedence over the values in the spec, so that flags may override a shared spec.
+func (s *AppSpec) Apply(c *AppConfig) {
+	c.Components = append(c.Components, s.Components...)
+	c.SourceRepositories = append(c.SourceRepositories, s.Repos...)
+	c.Volumes = append(c.Volumes, s.Volumes...)
+	c.DeploymentSecrets = append(c.DeploymentSecrets, s.Secrets...)
+	c.Expose = c.Expose || s.Expose
+	c.NoRoute = c.NoRoute || s.NoRoute
+	if len(c.Name) == 0 {
+		c.Name = s.Name
+	}
+	if len(c.ContextDir) == 0 {
+		c.ContextDir = s.ContextDir
+	}
+	if len(c.Strategy) == 0 {
+		c.Strategy = s.Strategy
+	}
+	if len(c.Preset) == 0 {
+		c.Preset = s.Preset
+	}
+	if len(c.Resources.Limits) == 0 {
+		c.Resources.Limits = s.Resources.Limits
+	}
+	if len(c.Resources.Requests) == 0 {
+		c.Resources.Requests = s.Resources.Requests
+	}
+
+	// environment arguments are applied in order, so the spec is prepended to let later arguments win
+	keys := make([]string, 0, len(s.Env))
+	for k := range s.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys)+len(c.Environment))
+	for _, k := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", k, s.Env[k]))
+	}
+	c.Environment = append(env, c.Environment...)
+
+	if len(s.Labels) > 0 && c.Labels == nil {
+		c.Labels = map[string]string{}
+	}
+	for k, v := range s.Labels {
+		if _, ok := c.Labels[k]; !ok {
+			c.Labels[k] = v
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestAppSpec(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appspec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.yaml")
+	data := `
+name: frontend
+components:
+- nodejs
+repos:
+- https://github.com/openshift/nodejs-ex
+env:
+  B: "2"
+  A: "1"
+labels:
+  team: web
+  app: frontend
+volumes:
+- name: cache
+  mountPath: /cache
+- name: data
+  mountPath: /data
+  persistent: true
+  size: 2Gi
+secrets:
+- db-creds:/etc/db
+expose: true
+noRoute: true
+resources:
+  limits:
+    memory: 512Mi
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := ReadAppSpec(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	config := &AppConfig{
+		Name:        "override",
+		Environment: []string{"A=3"},
+		Labels:      map[string]string{"app": "override"},
+	}
+	spec.Apply(config)
+
+	if config.Name != "override" || !config.Expose || !config.NoRoute {
+		t.Errorf("unexpected config: %#v", config)
+	}
+	if !reflect.DeepEqual(config.Components, []string{"nodejs"}) || len(config.SourceRepositories) != 1 {
+		t.Errorf("unexpected components: %v %v", config.Components, config.SourceRepositories)
+	}
+	if !reflect.DeepEqual(config.Environment, []string{"A=1", "B=2", "A=3"}) {
+		t.Errorf("unexpected environment: %v", config.Environment)
+	}
+	if !reflect.DeepEqual(config.Labels, map[string]string{"app": "override", "team": "web"}) {
+		t.Errorf("unexpected labels: %v", config.Labels)
+	}
+	if len(config.Volumes) != 2 || !config.Volumes[1].Persistent || config.Volumes[1].Size != "2Gi" {
+		t.Errorf("unexpected volumes: %#v", config.Volumes)
+	}
+	if !reflect.DeepEqual(config.DeploymentSecrets, []string{"db-creds:/etc/db"}) {
+		t.Errorf("unexpected secrets: %v", config.DeploymentSecrets)
+	}
+	memory := config.Resources.Limits[kapi.ResourceMemory]
+	if memory.String() != "512Mi" {
+		t.Errorf("unexpected limits: %#v", config.Resources)
+	}
+}
+
+func TestReadAppSpecInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "appspec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "app.yaml")
+	if err := ioutil.WriteFile(path, []byte("components: nodejs"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadAppSpec(path); err == nil {
+		t.Errorf("expected an error")
+	}
+}
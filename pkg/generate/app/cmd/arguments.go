@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	cmdutil "github.com/openshift/origin/pkg/cmd/util"
+	"github.com/openshift/origin/pkg/generate/app"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+// ArgumentCategory is the kind of input an argument to new-app is treated as.
+type ArgumentCategory string
+
+const (
+	// ArgumentEnvironment is an environment variable of the form NAME=VALUE.
+	ArgumentEnvironment ArgumentCategory = "env"
+	// ArgumentRepository is a source repository URL or local directory.
+	ArgumentRepository ArgumentCategory = "repo"
+	// ArgumentComponent is an image, image stream, template or template file.
+	ArgumentComponent ArgumentCategory = "image"
+)
+
+// ArgumentCategories are the categories an argument may be forced into by prefixing it with the category and
+// ArgumentCategorySeparator, for instance "repo::./src=old".
+var ArgumentCategories = []ArgumentCategory{ArgumentEnvironment, ArgumentRepository, ArgumentComponent}
+
+// ArgumentCategorySeparator separates a forced category from the argument. A single colon would collide with
+// image references such as "image:latest", but no image, template or environment variable name contains "::".
+const ArgumentCategorySeparator = "::"
+
+// AmbiguousArgument is an argument that could reasonably be treated as more than one category.
+type AmbiguousArgument struct {
+	// Argument is the argument as it was provided.
+	Argument string
+	// Guesses are the categories the argument could belong to, most likely first.
+	Guesses []ArgumentCategory
+	// Reason explains why the argument is ambiguous.
+	Reason string
+}
+
+// ErrAmbiguousArguments is returned when arguments are added strictly and some cannot be classified with
+// certainty.
+type ErrAmbiguousArguments []AmbiguousArgument
+
+func (e ErrAmbiguousArguments) Error() string {
+	messages := []string{}
+	for _, arg := range e {
+		guesses := []string{}
+		for _, guess := range arg.Guesses {
+			guesses = append(guesses, string(guess)+ArgumentCategorySeparator+arg.Argument)
+		}
+		messages = append(messages, fmt.Sprintf("%q %s, use one of %s", arg.Argument, arg.Reason, strings.Join(guesses, ", ")))
+	}
+	return fmt.Sprintf("ambiguous arguments: %s", strings.Join(messages, "; "))
+}
+
+// Code returns the code of the error.
+func (e ErrAmbiguousArguments) Code() generrors.Code {
+	return generrors.CodeAmbiguousArgument
+}
+
+// ClassifyArgument returns every category the argument could belong to, in the order AddArguments tries them.
+// Almost any string is a valid component reference, so an argument is only classified as a component reference
+// if it is a template file or matches no other category.
+func ClassifyArgument(s string) []ArgumentCategory {
+	categories := []ArgumentCategory{}
+	if cmdutil.IsEnvironmentArgument(s) {
+		categories = append(categories, ArgumentEnvironment)
+	}
+	if app.IsPossibleSourceRepository(s) {
+		categories = append(categories, ArgumentRepository)
+	}
+	if app.IsPossibleTemplateFile(s) || (len(categories) == 0 && app.IsComponentReference(s)) {
+		categories = append(categories, ArgumentComponent)
+	}
+	return categories
+}
+
+// forcedArgumentCategory returns the category and the remainder of an argument prefixed with a category.
+func forcedArgumentCategory(s string) (ArgumentCategory, string, bool) {
+	for _, category := range ArgumentCategories {
+		prefix := string(category) + ArgumentCategorySeparator
+		if strings.HasPrefix(s, prefix) {
+			return category, s[len(prefix):], true
+		}
+	}
+	return "", s, false
+}
+
+// AddArgumentsStrict converts command line arguments into the appropriate bucket like AddArguments, except that
+// an argument prefixed with a category and a separator (env::, repo:: or image::) is always placed in that
+// category, and an argument that could belong to more than one category is rejected rather than guessed. It
+// returns the arguments that match no category and an ErrAmbiguousArguments describing the ambiguous ones. No
+// arguments are added if any are ambiguous.
+func (c *AppConfig) AddArgumentsStrict(args []string) ([]string, error) {
+	unknown := []string{}
+	ambiguous := ErrAmbiguousArguments{}
+	type classified struct {
+		category ArgumentCategory
+		value    string
+	}
+	accepted := []classified{}
+	for _, s := range args {
+		if len(s) == 0 {
+			continue
+		}
+		if category, value, ok := forcedArgumentCategory(s); ok {
+			if category == ArgumentEnvironment && !cmdutil.IsEnvironmentArgument(value) {
+				unknown = append(unknown, s)
+				continue
+			}
+			accepted = append(accepted, classified{category, value})
+			continue
+		}
+		categories := ClassifyArgument(s)
+		switch {
+		case len(categories) == 0:
+			unknown = append(unknown, s)
+		case len(categories) > 1:
+			ambiguous = append(ambiguous, AmbiguousArgument{Argument: s, Guesses: categories, Reason: "matches more than one kind of argument"})
+		case categories[0] == ArgumentEnvironment && strings.Contains(strings.SplitN(s, "=", 2)[1], "="):
+			ambiguous = append(ambiguous, AmbiguousArgument{Argument: s, Guesses: categories, Reason: "has an environment value that contains '='"})
+		default:
+			accepted = append(accepted, classified{categories[0], s})
+		}
+	}
+	if len(ambiguous) > 0 {
+		return unknown, ambiguous
+	}
+	for _, arg := range accepted {
+		switch arg.category {
+		case ArgumentEnvironment:
+			c.Environment = append(c.Environment, arg.value)
+		case ArgumentRepository:
+			c.SourceRepositories = append(c.SourceRepositories, arg.value)
+		case ArgumentComponent:
+			c.Components = append(c.Components, arg.value)
+		}
+	}
+	return unknown, nil
+}
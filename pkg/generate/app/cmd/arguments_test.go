@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAddArgumentsStrict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "arguments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.Mkdir(filepath.Join(dir, "src=old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	tests := map[string]struct {
+		args       []string
+		env        []string
+		repos      []string
+		components []string
+		unknown    []string
+		ambiguous  []string
+	}{
+		"unambiguous": {
+			args:       []string{"FOO=bar", "nodejs", dir},
+			env:        []string{"FOO=bar"},
+			repos:      []string{dir},
+			components: []string{"nodejs"},
+		},
+		"directory that looks like env": {
+			args:      []string{"src=old", "nodejs"},
+			ambiguous: []string{"src=old"},
+		},
+		"env value containing =": {
+			args:      []string{"FOO=bar=baz"},
+			ambiguous: []string{"FOO=bar=baz"},
+		},
+		"forced categories": {
+			args:       []string{"repo::src=old", "env::FOO=bar=baz", "image::mysql", "image::image:latest"},
+			env:        []string{"FOO=bar=baz"},
+			repos:      []string{"src=old"},
+			components: []string{"mysql", "image:latest"},
+		},
+		"component named like a category": {
+			args:       []string{"image:latest", "repo"},
+			components: []string{"image:latest", "repo"},
+		},
+		"invalid forced env": {
+			args:    []string{"env::nodejs"},
+			unknown: []string{"env::nodejs"},
+		},
+	}
+	for name, test := range tests {
+		c := &AppConfig{}
+		unknown, err := c.AddArgumentsStrict(test.args)
+		if len(unknown) > 0 || len(test.unknown) > 0 {
+			if !reflect.DeepEqual(unknown, test.unknown) {
+				t.Errorf("%s: unexpected unknown arguments: %v", name, unknown)
+			}
+		}
+		if len(test.ambiguous) > 0 {
+			errs, ok := err.(ErrAmbiguousArguments)
+			if !ok || len(errs) != len(test.ambiguous) || errs[0].Argument != test.ambiguous[0] || len(errs[0].Guesses) == 0 {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+			if len(c.Environment)+len(c.SourceRepositories)+len(c.Components) > 0 {
+				t.Errorf("%s: no arguments should be added: %#v", name, c)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(c.Environment, test.env) || !reflect.DeepEqual(c.SourceRepositories, test.repos) || !reflect.DeepEqual(c.Components, test.components) {
+			t.Errorf("%s: unexpected config: %v %v %v", name, c.Environment, c.SourceRepositories, c.Components)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/openshift/origin/pkg/generate/app"
+)
+
+// BatchResult is the result of generating one of the applications of a batch.
+type BatchResult struct {
+	// Spec is the spec the application was generated from.
+	Spec *AppSpec
+	// Result is the generated application. It is nil if Err is set.
+	Result *AppResult
+	Err    error
+}
+
+// BatchRun generates an application for each of specs, such as the repositories imported by an onboarding
+// tool, and returns their results in the same order. Each spec is applied to a copy of c, which holds the
+// options shared by the applications. The copies share the clients and searchers of c and a search cache and a
+// clone cache, which are created if c has none, so that the images, templates and repositories used by several
+// applications are only looked up once. A clone cache created for the batch holds its clones in a workspace
+// that is closed when the batch completes, unless c has a workspace. A clone cache set on c must come with the
+// workspace that holds its clones, or every application fails with ErrCloneCacheWithoutWorkspace. The failure of
+// an application does not stop the others.
+func (c *AppConfig) BatchRun(specs []*AppSpec) []BatchResult {
+	if c.SearchCache == nil {
+		c.SearchCache = app.NewSearchCache()
+	}
+	clones, workspace := c.CloneCache, c.Workspace
+	if clones == nil {
+		clones = app.NewCloneCache()
+		if workspace == nil {
+			workspace = app.NewWorkspace("", 0)
+			defer workspace.Close()
+		}
+	}
+	results := make([]BatchResult, 0, len(specs))
+	for _, spec := range specs {
+		config := c.batchCopy()
+		config.CloneCache, config.Workspace = clones, workspace
+		spec.Apply(config)
+		result, err := config.Run()
+		results = append(results, BatchResult{Spec: spec, Result: result, Err: err})
+	}
+	return results
+}
+
+// batchCopy returns a copy of c that an AppSpec can be applied to without changing c.
+func (c *AppConfig) batchCopy() *AppConfig {
+	config := *c
+	config.RefBuilder = &app.ReferenceBuilder{}
+	config.Components = append([]string(nil), c.Components...)
+	config.SourceRepositories = append([]string(nil), c.SourceRepositories...)
+	config.Volumes = append([]app.Volume(nil), c.Volumes...)
+	config.DeploymentSecrets = append([]string(nil), c.DeploymentSecrets...)
+	config.Environment = append([]string(nil), c.Environment...)
+	if c.Labels != nil {
+		config.Labels = make(map[string]string, len(c.Labels))
+		for k, v := range c.Labels {
+			config.Labels[k] = v
+		}
+	}
+	return &config
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	"github.com/openshift/origin/pkg/generate/app"
+)
+
+// countingSearcher counts the searches made with the wrapped searcher.
+type countingSearcher struct {
+	app.Searcher
+	count *int
+}
+
+func (s countingSearcher) Search(precise bool, terms ...string) (app.ComponentMatches, []error) {
+	*s.count++
+	return s.Searcher.Search(precise, terms...)
+}
+
+func (s countingSearcher) Capabilities() app.SearcherCapabilities {
+	return app.SearcherCapabilities{}
+}
+
+func TestBatchRun(t *testing.T) {
+	searches := 0
+	config := &AppConfig{
+		ImageStreamSearcher: countingSearcher{Searcher: fakeImageStreamSearcher(), count: &searches},
+		DockerSearcher:      app.DockerClientSearcher{},
+		Typer:               kapi.Scheme,
+		Deploy:              true,
+		Labels:              map[string]string{"team": "web"},
+		Out:                 &bytes.Buffer{},
+		ErrOut:              &bytes.Buffer{},
+	}
+	specs := []*AppSpec{
+		{Name: "frontend", Components: []string{"ruby"}, Labels: map[string]string{"app": "frontend"}},
+		{},
+		{Name: "backend", Components: []string{"ruby"}},
+	}
+	results := config.BatchRun(specs)
+	if len(results) != 3 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	for i, name := range []string{"frontend", "", "backend"} {
+		result := results[i]
+		if result.Spec != specs[i] {
+			t.Errorf("%d: unexpected spec: %#v", i, result.Spec)
+		}
+		if len(name) == 0 {
+			if result.Err != ErrNoInputs {
+				t.Errorf("%d: unexpected error: %v", i, result.Err)
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("%d: unexpected error: %v", i, result.Err)
+			continue
+		}
+		found := false
+		for _, obj := range result.Result.List.Items {
+			if dc, ok := obj.(*deployapi.DeploymentConfig); ok && dc.Name == name {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%d: no deployment config named %s in %#v", i, name, result.Result.List.Items)
+		}
+	}
+	if searches != 1 {
+		t.Errorf("expected the image stream to be searched once, searched %d times", searches)
+	}
+	if len(config.Components) != 0 || len(config.Labels) != 1 || len(config.Name) != 0 {
+		t.Errorf("the shared config was changed: %#v", config)
+	}
+}
+
+func TestBatchRunRequiresWorkspaceForCloneCache(t *testing.T) {
+	config := &AppConfig{
+		ImageStreamSearcher: fakeImageStreamSearcher(),
+		DockerSearcher:      app.DockerClientSearcher{},
+		Typer:               kapi.Scheme,
+		Out:                 &bytes.Buffer{},
+		ErrOut:              &bytes.Buffer{},
+		CloneCache:          app.NewCloneCache(),
+	}
+	results := config.BatchRun([]*AppSpec{{Name: "frontend", Components: []string{"ruby"}}})
+	if len(results) != 1 || results[0].Err != ErrCloneCacheWithoutWorkspace {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+
+	config.Workspace = app.NewWorkspace("", 0)
+	defer config.Workspace.Close()
+	results = config.BatchRun([]*AppSpec{{Name: "frontend", Components: []string{"ruby"}}})
+	if len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+}
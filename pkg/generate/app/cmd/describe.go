@@ -45,6 +45,8 @@ func describeLocatedImage(refInput *app.ComponentInput, baseNamespace string) st
 	switch {
 	case match == nil:
 		return ""
+	case match.ImageStream != nil && len(match.ImageID) > 0:
+		return fmt.Sprintf("Found image %s in image stream %s for %q", imageapi.ShortDockerImageID(&imageapi.DockerImage{ID: match.ImageID}, 7), localOrRemoteName(match.ImageStream.ObjectMeta, baseNamespace), refInput)
 	case match.ImageStream != nil:
 		if image := match.Image; image != nil {
 			shortID := imageapi.ShortDockerImageID(image, 7)
@@ -90,7 +92,7 @@ func inputAnnotations(match *app.ComponentMatch) map[string]string {
 	return base
 }
 
-func describeBuildPipelineWithImage(out io.Writer, ref app.ComponentReference, pipeline *app.Pipeline, baseNamespace string) {
+func describeBuildPipelineWithImage(out io.Writer, ref app.ComponentReference, pipeline *app.Pipeline, baseNamespace string, rootAllowed bool) {
 	refInput := ref.Input()
 	match := refInput.ResolvedMatch
 
@@ -134,14 +136,17 @@ func describeBuildPipelineWithImage(out io.Writer, ref app.ComponentReference, p
 				}
 				matches = append(matches, t.Platform)
 			}
-			if len(matches) > 0 && !pipeline.Build.Strategy.IsDockerBuild {
+			if len(matches) > 0 && !pipeline.Build.Strategy.IsDockerBuild && !pipeline.Build.Strategy.IsPipelineBuild {
 				fmt.Fprintf(out, "    * The source repository appears to match: %s\n", strings.Join(matches, ", "))
 			}
 		}
 		var strategy string
-		if pipeline.Build.Strategy.IsDockerBuild {
+		switch {
+		case pipeline.Build.Strategy.IsPipelineBuild:
+			strategy = "pipeline"
+		case pipeline.Build.Strategy.IsDockerBuild:
 			strategy = "Docker"
-		} else {
+		default:
 			strategy = "source"
 		}
 		var source string
@@ -157,6 +162,11 @@ func describeBuildPipelineWithImage(out io.Writer, ref app.ComponentReference, p
 		}
 
 		fmt.Fprintf(out, "    * A %s build using %s will be created\n", strategy, source)
+		if strategy := pipeline.Build.Strategy; !strategy.IsDockerBuild && strategy.Base != nil {
+			for _, warning := range app.CheckS2IBuilder(strategy.Base.Reference.Name, strategy.Base.Info, pipeline.Build.Source.ContextDir) {
+				fmt.Fprintf(out, "      * WARNING: Builder image %q: %s. The build config cannot override the %s label.\n", warning.Builder, warning.Message, warning.Label)
+			}
+		}
 		if buildOut, err := pipeline.Build.Output.BuildOutput(); err == nil && buildOut != nil && buildOut.To != nil {
 			switch to := buildOut.To; {
 			case to.Kind == "ImageStreamTag":
@@ -212,21 +222,23 @@ func describeBuildPipelineWithImage(out io.Writer, ref app.ComponentReference, p
 				fmt.Fprintf(out, "    * This image declares volumes and will default to use non-persistent, host-local storage.\n")
 				fmt.Fprintf(out, "      You can add persistent volumes later by running 'volume dc/%s --add ...'\n", pipeline.Deployment.Name)
 			}
-			if hasRootUser(match.Image) {
-				fmt.Fprintf(out, "    * WARNING: Image %q runs as the 'root' user which may not be permitted by your cluster administrator\n", pipeline.Image.Reference.Name)
+			if os := app.ImageOS(pipeline.Image); len(os) > 0 && os != app.ImageOSLinux {
+				fmt.Fprintf(out, "    * This image is built for %s and will only be scheduled on %s nodes\n", os, os)
+			}
+			if os, first := app.ImageOS(pipeline.Image), app.ImageOS(pipeline.Deployment.Images[0]); len(os) > 0 && len(first) > 0 && os != first {
+				fmt.Fprintf(out, "    * WARNING: This image is built for %s but deployment config %q also runs images built for %s, which cannot run in the same pod\n", os, pipeline.Deployment.Name, first)
+			}
+			if warning := app.AnalyzeImageUser(pipeline.Image.Reference.Name, match.Image); warning != nil && !rootAllowed {
+				fmt.Fprintf(out, "    * WARNING: Image %q %s. The security context constraints of the project may not permit it to run.\n", warning.Image, warning.Reason)
+				for _, suggestion := range warning.Suggestions {
+					fmt.Fprintf(out, "      * To fix this, %s\n", suggestion)
+				}
 			}
 		}
 	}
 	fmt.Fprintln(out)
 }
 
-func hasRootUser(image *imageapi.DockerImage) bool {
-	if image.Config == nil {
-		return false
-	}
-	return len(image.Config.User) == 0 || image.Config.User == "root" || image.Config.User == "0"
-}
-
 func hasEmptyDir(image *imageapi.DockerImage) bool {
 	if image.Config == nil {
 		return false
@@ -3,27 +3,37 @@ package cmd
 import (
 	"fmt"
 	"io"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
+	"github.com/docker/distribution/reference"
 	"github.com/fsouza/go-dockerclient"
 	"github.com/golang/glog"
 	kapi "k8s.io/kubernetes/pkg/api"
 	kerrors "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/api/unversioned"
 	"k8s.io/kubernetes/pkg/api/validation"
 	kclient "k8s.io/kubernetes/pkg/client/unversioned"
+	kclientcmd "k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
 	"k8s.io/kubernetes/pkg/kubectl/resource"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/validation/field"
 
 	authapi "github.com/openshift/origin/pkg/authorization/api"
 	buildapi "github.com/openshift/origin/pkg/build/api"
+	buildvalidation "github.com/openshift/origin/pkg/build/api/validation"
 	"github.com/openshift/origin/pkg/client"
 	cmdutil "github.com/openshift/origin/pkg/cmd/util"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
 	"github.com/openshift/origin/pkg/dockerregistry"
 	"github.com/openshift/origin/pkg/generate/app"
+	"github.com/openshift/origin/pkg/generate/compose"
 	"github.com/openshift/origin/pkg/generate/dockerfile"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
 	"github.com/openshift/origin/pkg/generate/source"
 	imageapi "github.com/openshift/origin/pkg/image/api"
 	"github.com/openshift/origin/pkg/template"
@@ -39,14 +49,21 @@ const (
 	GeneratedByNewBuild  = "OpenShiftNewBuild"
 )
 
+// validTagName matches the tags that may be set on an image stream.
+var validTagName = regexp.MustCompile("^" + reference.TagRegexp.String() + "$")
+
 // ErrNoDockerfileDetected is the error returned when the requested build strategy is Docker
 // and no Dockerfile is detected in the repository.
-var ErrNoDockerfileDetected = fmt.Errorf("No Dockerfile was found in the repository and the requested build strategy is 'docker'")
+var ErrNoDockerfileDetected error = generrors.New(generrors.CodeNoDockerfile, "No Dockerfile was found in the repository and the requested build strategy is 'docker'")
 
 // AppConfig contains all the necessary configuration for an application
 type AppConfig struct {
 	SourceRepositories []string
 	ContextDir         string
+	// DockerfilePath, if set, is the path of the Dockerfile of the source repositories relative to the context
+	// directory, for repositories that build with the repository root as context and keep the Dockerfile
+	// elsewhere, such as under build/. It implies the docker strategy.
+	DockerfilePath string
 
 	Components    []string
 	ImageStreams  []string
@@ -57,18 +74,33 @@ type AppConfig struct {
 	TemplateParameters []string
 	Groups             []string
 	Environment        []string
-	Labels             map[string]string
+	// Labels and Annotations are added to every generated object. Objects produced by templates are labeled and
+	// annotated according to TemplateLabels.
+	Labels      map[string]string
+	Annotations map[string]string
+	// TemplateLabels controls how Labels and Annotations are applied to the objects of instantiated templates,
+	// and whether the labels templates declare for their objects are kept.
+	TemplateLabels app.TemplateLabelPolicy
 
 	AddEnvironmentToBuild bool
 
 	Dockerfile string
 
-	Name             string
-	To               string
+	Name string
+	To   string
+	// Strategy is the build strategy of the source repositories, docker, pipeline or source. If it is empty
+	// the strategy is detected from the repository: a Dockerfile selects docker. Pipeline builds require an
+	// external controller to run them and are never detected, they must be requested explicitly.
 	Strategy         string
 	InsecureRegistry bool
 	OutputDocker     bool
 	NoOutput         bool
+	// RegistryHost, if set, is the registry that generated Docker image outputs are pushed to. If it is not
+	// set and OutputDocker is, the address of the integrated registry service is used if it can be found.
+	RegistryHost string
+
+	// RegistryTransports tunes the connections made to individual Docker registries while searching.
+	RegistryTransports dockerregistry.RegistryTransportOptions
 
 	ExpectToBuild      bool
 	BinaryBuild        bool
@@ -77,20 +109,160 @@ type AppConfig struct {
 	Deploy           bool
 	AsTestDeployment bool
 
+	// Volumes are mounted into every generated deployment config.
+	Volumes []app.Volume
+	// RunningContainers are containers started outside of deployment configs, for which equivalent deployment
+	// configs are generated.
+	RunningContainers []app.RunningContainer
+	// ComposeFile is the path of a docker-compose file of version 2 or 3, for whose services deployment
+	// configs, persistent volume claims and routes are generated.
+	ComposeFile string
+	// InitContainers are run to completion before the containers of generated deployment configs start.
+	InitContainers []app.InitContainer
+	// SpreadReplicas asks the scheduler to place the replicas of generated deployment configs with more than
+	// one replica on different nodes.
+	SpreadReplicas bool
+	// LinkDatabases configures generated database deployment configs with generated credentials, and the other
+	// generated deployment configs with the environment to connect to them.
+	LinkDatabases bool
+	// Expose generates a route for each generated service.
+	Expose bool
+	// NoRoute disables the routes that are generated for the generated services that expose port 80, 8080 or
+	// 443, so that the application is reachable as soon as it is deployed.
+	NoRoute bool
+	// Resources are set on generated containers that do not specify their own limits and requests.
+	Resources kapi.ResourceRequirements
+	// PodSettings are set on the pods of every generated workload, such as deployment configs and the pods
+	// that run installable images.
+	PodSettings app.PodSettings
+
+	// Platform, if set, is the os/arch the generated application targets. Matches built for other platforms
+	// are ranked below matches for the platform, or ignored if RejectOtherPlatforms is set.
+	Platform             string
+	RejectOtherPlatforms bool
+
+	// Preset is the name of an app.Presets entry whose defaults are applied to the generated objects.
+	Preset string
+	// Environments, if set, generates a variant of the deployed objects for each environment.
+	Environments []app.TargetEnvironment
+	// GenerationRecord, if set, describes who the application is generated for, such as a user of the web
+	// console. The generated objects are annotated with it and, if KubeClient is set, an event describing the
+	// generation is created in the target namespace.
+	GenerationRecord *app.GenerationRecord
+
+	// Patches are applied to the generated objects they identify, for fields that are not otherwise generated.
+	Patches []app.ObjectPatch
+	// ClusterProfiles are the clusters that RunForClusters generates a result for.
+	ClusterProfiles []app.ClusterProfile
+	// SuccessfulBuildsHistoryLimit and FailedBuildsHistoryLimit, if set, limit the number of builds kept for
+	// generated build configs.
+	SuccessfulBuildsHistoryLimit *int
+	FailedBuildsHistoryLimit     *int
+	// TestTag, if set, is the tag of the output image stream that generated builds push to. It may only be
+	// used when nothing is deployed.
+	TestTag string
+	// PostCommitScript, if set, is run in the output image of generated builds before it is pushed.
+	PostCommitScript string
+	// OutputTags are additional tags of the output image stream that the image of each successful build is
+	// tagged into, while the output tag keeps tracking the latest build. "${build-number}" in a tag is replaced
+	// with the number of the build, which gives each build a tag of its own.
+	OutputTags []string
+	// OutputImageLabels are labels that generated builds apply to the images they produce, for instance to
+	// record the source or the team that owns them. They override the labels of the source and the builder.
+	OutputImageLabels map[string]string
+	// PromoteTo are image stream tags of the form [namespace/]stream[:tag] that the output image of the
+	// generated build is tagged into, so that they track its builds. A tag defaults to the output tag, and
+	// image streams are generated in other namespaces as needed.
+	PromoteTo []string
+	// ReleaseTag, if set, is the tag of the output image stream that generated builds push to and that
+	// deployments of the output are triggered from, instead of latest.
+	ReleaseTag string
+	// NoLatestTriggers stops generated deployment configs from being deployed automatically when the latest
+	// tag of an image stream they are triggered by changes.
+	NoLatestTriggers bool
+	// WebhookTriggersOnly generates build configs that are only built when a webhook is received and deployment
+	// configs that are only deployed once a build has completed, so that nothing runs when the objects are
+	// created. It is used when the source repository is not ready to be built yet.
+	WebhookTriggersOnly bool
+	// WebhookTriggerTypes, if set, selects the webhook triggers of generated build configs by type, github or
+	// generic. Both are generated by default.
+	WebhookTriggerTypes []string
+	// WebhookSecretName, if set, is an existing Secret whose buildapi.WebHookSecretKey value validates the
+	// requests of generated webhook triggers, instead of a generated secret stored in the build config.
+	WebhookSecretName string
+	// BuildRunPolicy, if set, determines how the builds of generated build configs are scheduled relative to
+	// each other.
+	BuildRunPolicy buildapi.BuildRunPolicy
+	// NameTemplate, if set, is a Go template that names the image streams, build configs, deployment configs
+	// and services generated for the application. It is executed with an app.NameTemplateInput for each object.
+	NameTemplate string
+	// BaseImageMirrors, if set, replaces the base image of Dockerfiles with an approved mirror of the same
+	// image. The original base image is recorded on the generated build config.
+	BaseImageMirrors app.BaseImageMirrors
+
+	// Debug generates a single replica debug variant of each deployment config whose language supports remote
+	// debugging.
+	Debug bool
+
 	SourceImage     string
 	SourceImagePath string
+	// NoSourceImageTrigger omits the image change trigger on the source image from generated build configs, so
+	// that updates to the source image do not rebuild them.
+	NoSourceImageTrigger bool
 
 	SkipGeneration        bool
 	AllowGenerationErrors bool
+	// AllowIncompatibleBuilders turns the errors for builders that do not support the language detected in
+	// their source into builder warnings.
+	AllowIncompatibleBuilders bool
+	// EOLTable lists the end of life of language runtimes, which are reported in the result when the source
+	// or the builder of a generated build uses them. If nil, app.DefaultEOLTable is used.
+	EOLTable app.EOLTable
+	// DockerfileLintSeverities overrides the severity of the lint rules checked against the Dockerfiles of
+	// generated docker builds. Rules that are not listed use app.DefaultDockerfileLintSeverities, and findings
+	// of rules with the Error severity fail generation.
+	DockerfileLintSeverities app.DockerfileLintSeverities
+	// NonBuilderPolicy determines what happens to images that cannot build source when the only source
+	// repository would otherwise be paired with them. The default is NonBuilderReject.
+	NonBuilderPolicy NonBuilderPolicy
 
 	AllowSecretUse bool
 	SecretAccessor app.SecretAccessor
 
+	// baseImageOriginals maps the mirrors substituted for Dockerfile base images to the original images
+	baseImageOriginals map[string]string
+
 	Secrets []string
+	// DeploymentSecrets are secrets in the form name:path[:mode] that are mounted into every generated
+	// deployment config. The mode is ro, the default, or rw.
+	DeploymentSecrets []string
+	deploymentSecrets []app.DeploymentSecret
+	promotionTargets  []app.PromotionTarget
+	webhookTypes      []buildapi.BuildTriggerType
+	// envSources holds the environment variables given with --env whose values reference a secret, a config map
+	// or a field of the pod. They are set on the generated deployment configs only.
+	envSources map[string]*kapi.EnvVarSource
+
+	// rootAllowed caches whether the security context constraints let generated deployments run as root
+	rootAllowed *bool
+	// SourceSecretsByHost sets the source secret of generated build configs to the secret of the namespace
+	// labeled for the git host of their source, if any.
+	SourceSecretsByHost bool
+	// SourceSecret, if set, is an existing Secret that is set as the source secret of generated build configs
+	// and whose SSH private key or basic auth credentials clone private source repositories during detection.
+	SourceSecret string
+	sourceAuth   *app.SourceAuth
 
 	AsSearch bool
 	AsList   bool
 	DryRun   bool
+	// AsSuggest returns the ranked candidates of every component with the reason each was or was not selected
+	// instead of generating the application.
+	AsSuggest bool
+
+	// PreflightOnly checks the generated objects against the target namespace instead of returning them.
+	// The result holds a PreflightReport and an empty list.
+	PreflightOnly bool
 
 	Out    io.Writer
 	ErrOut io.Writer
@@ -104,15 +276,68 @@ type AppConfig struct {
 	ImageStreamByAnnotationSearcher app.Searcher
 	TemplateSearcher                app.Searcher
 	TemplateFileSearcher            app.Searcher
+	// BundleSearcher resolves the bundle references among the components. Defaults to reading the catalogs
+	// named in Catalogs and in DefaultCatalogDir.
+	BundleSearcher app.Searcher
+	// Catalogs name the files of the catalogs that bundle references are resolved in, in the form name=file.
+	Catalogs []string
+	// DockerTagLister lists the tags of Docker image matches when search results include details. Defaults to
+	// listing the tags in the registry of each image.
+	DockerTagLister app.TagLister
+
+	// MatchAcceptance, if set, accepts the best candidate of components that have no single exact match instead
+	// of failing.
+	MatchAcceptance app.AcceptancePolicy
+	// MatchSuggester, if set, adds suggestions to the errors returned when an argument matches nothing.
+	MatchSuggester *app.MatchSuggester
+	// ResolutionCache, if set, caches the images and image streams that arguments resolve to.
+	ResolutionCache *app.ResolutionCache
+	// SearchCache and CloneCache, if set, share searches and the clones of source repositories with the other
+	// configs that use the same caches.
+	SearchCache *app.SearchCache
+	CloneCache  *app.CloneCache
+	// CloneLimits bound the time and disk space the clone of each remote source repository may take during
+	// detection. The component of a repository that exceeds them fails with a CloneTimedOut or CloneTooLarge
+	// error.
+	CloneLimits app.CloneLimits
+	// Workspace, if set, holds the clones of remote source repositories and the other temporary directories of
+	// generation, and is closed by its owner, such as when the generation is cancelled. It must be set with
+	// CloneCache, whose clones it holds. If neither is set, each run uses a workspace of its own that is closed
+	// when it returns.
+	Workspace *app.Workspace
+
+	// LockfilePath and LockfileMode control whether resolved components are recorded in, or verified
+	// against, a lockfile.
+	LockfilePath string
+	LockfileMode app.LockfileMode
+	lockfile     *app.Lockfile
 
 	Detector app.Detector
 
 	Typer        runtime.ObjectTyper
 	Mapper       meta.RESTMapper
 	ClientMapper resource.ClientMapper
+	// Converter converts the generated objects to OutputVersions. If nil, kapi.Scheme is used.
+	Converter app.ObjectConverter
+
+	// OutputVersions, if set, are the API versions the generated objects must be represented in without losing
+	// fields, so that they can be created on older clusters. The objects in the result are converted to the
+	// first of them.
+	OutputVersions []unversioned.GroupVersion
 
 	OSClient        client.Interface
 	OriginNamespace string
+	// TargetNamespace, if set, is the namespace the application is generated into when it differs from
+	// OriginNamespace, which is still searched for images and templates, such as when an administrator
+	// scaffolds an application on behalf of a team. The generated objects are placed in it, and their references
+	// to image streams that are not generated refer to OriginNamespace.
+	TargetNamespace string
+
+	// Policy is applied to the generated objects. If nil, the policy of the target project is used.
+	Policy *app.GenerationPolicy
+
+	// Metrics, if set, records the searches, source detection and failures of generation.
+	Metrics *app.Metrics
 }
 
 // UsageError is an interface for printing usage errors
@@ -134,8 +359,39 @@ func (e ErrRequiresExplicitAccess) Error() string {
 	return fmt.Sprintf("the component %q is requesting access to run with your security credentials and install components - you must explicitly grant that access to continue", e.Match.String())
 }
 
+// Code returns the code of the error.
+func (e ErrRequiresExplicitAccess) Code() generrors.Code {
+	return generrors.CodeRequiresExplicitAccess
+}
+
 // ErrNoInputs is returned when no inputs are specified
-var ErrNoInputs = fmt.Errorf("no inputs provided")
+var ErrNoInputs error = generrors.New(generrors.CodeNoInputs, "no inputs provided")
+
+// ErrCloneCacheWithoutWorkspace is returned when a clone cache is set without the workspace that holds its clones
+var ErrCloneCacheWithoutWorkspace error = generrors.New(generrors.CodeInvalidArgument, "a workspace must be set to hold the clones of the clone cache")
+
+// NonBuilderPolicy determines how images that cannot build source are handled when source would be paired
+// with them.
+type NonBuilderPolicy string
+
+const (
+	// NonBuilderReject returns an error for each such image.
+	NonBuilderReject NonBuilderPolicy = "Reject"
+	// NonBuilderAsImage includes each such image as it is, without building source with it.
+	NonBuilderAsImage NonBuilderPolicy = "Image"
+)
+
+// NonBuilderWarning describes an image that was not paired with source because it cannot build it.
+type NonBuilderWarning struct {
+	// Image is the component that names the image.
+	Image string
+	// Source is the source repository that was not paired with the image.
+	Source string
+}
+
+func (w NonBuilderWarning) String() string {
+	return fmt.Sprintf("%q is not a builder image, so it is included as an image and is not used to build %s", w.Image, w.Source)
+}
 
 // AppResult contains the results of an application
 type AppResult struct {
@@ -144,8 +400,46 @@ type AppResult struct {
 	Name      string
 	HasSource bool
 	Namespace string
+	// Cluster is the name of the cluster profile the result was generated for, if any.
+	Cluster string
+	// Components are the components the objects were generated from.
+	Components app.ComponentReferences
 
 	GeneratedJobs bool
+
+	// Warnings describes the images that are likely to fail under the restricted security context constraint.
+	Warnings []*app.ImageUserWarning
+	// BuilderWarnings describes builder image labels that are likely to make the generated builds fail. They are
+	// reported only: the generated build configs cannot override these labels.
+	BuilderWarnings []app.BuilderWarning
+	// NonBuilderWarnings describes the images that were not used to build source because they are not
+	// builders.
+	NonBuilderWarnings []NonBuilderWarning
+	// DuplicateInputs describes the components and source repositories that were given more than once and
+	// that are only used once.
+	DuplicateInputs []app.DuplicateInput
+	// EOLWarnings describes the language runtimes used by generated builds that have reached, or are about
+	// to reach, their end of life.
+	EOLWarnings []app.RuntimeEOLWarning
+	// DockerfileLintFindings describes the instructions of the Dockerfiles of generated docker builds that
+	// break a lint rule.
+	DockerfileLintFindings []app.DockerfileLintFinding
+	// ComposeWarnings describes the settings of the services of the docker-compose file that the generated
+	// objects do not reproduce.
+	ComposeWarnings []string
+	// OSWarnings describes deployment configs that mix images built for different operating systems.
+	OSWarnings []app.MixedOSWarning
+	// SourceSecretWarnings describes the build configs whose source secret was chosen among several secrets
+	// labeled for the same git host.
+	SourceSecretWarnings []app.SourceSecretWarning
+	// QuotaWarnings and LimitRangeWarnings describe the quotas and limit ranges of the namespace that are
+	// likely to reject the generated objects.
+	QuotaWarnings      []QuotaViolation
+	LimitRangeWarnings []LimitRangeViolation
+
+	// Preflight is the result of checking the generated objects against the target namespace. It is only
+	// set if PreflightOnly was requested.
+	Preflight *PreflightReport
 }
 
 // QueryResult contains the results of a query (search or list)
@@ -154,6 +448,18 @@ type QueryResult struct {
 	List    *kapi.List
 }
 
+// DefaultResolutionCacheDir returns the directory in the home directory of the current user where resolutions
+// are cached.
+func DefaultResolutionCacheDir() string {
+	return filepath.Join(kclientcmd.HomeDir(), ".kube", "cache", "new-app")
+}
+
+// DefaultCatalogDir returns the directory in the home directory of the current user that catalogs are read from
+// when they are not named with Catalogs.
+func DefaultCatalogDir() string {
+	return filepath.Join(kclientcmd.HomeDir(), ".kube", "catalogs")
+}
+
 // NewAppConfig returns a new AppConfig, but you must set your typer, mapper, and clientMapper after the command has been run
 // and flags have been parsed.
 func NewAppConfig() *AppConfig {
@@ -168,7 +474,16 @@ func NewAppConfig() *AppConfig {
 
 func (c *AppConfig) DockerImageSearcher() app.Searcher {
 	return app.DockerRegistrySearcher{
-		Client:        dockerregistry.NewClient(30*time.Second, true),
+		Client:        dockerregistry.NewClientWithTransportOptions(30*time.Second, true, c.RegistryTransports),
+		AllowInsecure: c.InsecureRegistry,
+		Authenticated: c.RegistryTransports.Credentials != nil,
+	}
+}
+
+// DockerImageTagLister returns a lister for the tags of Docker images in registries.
+func (c *AppConfig) DockerImageTagLister() app.TagLister {
+	return app.RegistryTagLister{
+		Client:        dockerregistry.NewClientWithTransportOptions(30*time.Second, true, c.RegistryTransports),
 		AllowInsecure: c.InsecureRegistry,
 	}
 }
@@ -179,6 +494,123 @@ func (c *AppConfig) ensureDockerSearch() {
 	}
 }
 
+// ensureBundleSearch sets a searcher of the catalogs in Catalogs and in DefaultCatalogDir if no bundle searcher
+// is set.
+func (c *AppConfig) ensureBundleSearch() error {
+	if c.BundleSearcher != nil {
+		return nil
+	}
+	catalogs := make(map[string]string)
+	for _, s := range c.Catalogs {
+		parts := strings.SplitN(s, "=", 2)
+		if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+			return generrors.Newf(generrors.CodeInvalidArgument, "the catalog %q must be in the form name=file", s)
+		}
+		catalogs[parts[0]] = parts[1]
+	}
+	c.BundleSearcher = app.BundleSearcher{Catalogs: catalogs, Dir: DefaultCatalogDir()}
+	return nil
+}
+
+// expandBundles replaces the bundle references among the components with the components of the bundles they
+// resolve to. The images and image streams of the bundles are added as image and image stream components, and
+// their templates as template components, so that they are not matched against other kinds of objects.
+func (c *AppConfig) expandBundles() error {
+	components := []string{}
+	for _, s := range c.Components {
+		if !app.IsBundleReference(s) {
+			components = append(components, s)
+			continue
+		}
+		if err := c.ensureBundleSearch(); err != nil {
+			return err
+		}
+		match, err := app.FirstMatchResolver{Searcher: c.BundleSearcher}.Resolve(s)
+		if err != nil {
+			return err
+		}
+		if match.Bundle == nil {
+			return generrors.Newf(generrors.CodeNoMatch, "%s does not reference a bundle", s)
+		}
+		glog.V(2).Infof("Expanding bundle %s into %d components", match.Name, len(match.Bundle.Components))
+		for _, component := range match.Bundle.Components {
+			switch {
+			case len(component.Image) > 0:
+				c.DockerImages = append(c.DockerImages, component.Term())
+			case len(component.ImageStream) > 0:
+				c.ImageStreams = append(c.ImageStreams, component.Term())
+			default:
+				c.Templates = append(c.Templates, component.Term())
+			}
+		}
+	}
+	c.Components = components
+	return nil
+}
+
+// ensurePlatform restricts the image searchers to the target platform, if one is set.
+func (c *AppConfig) ensurePlatform() error {
+	platform, ok, err := c.platform()
+	if err != nil || !ok {
+		return err
+	}
+	wrap := func(searcher app.Searcher) app.Searcher {
+		if _, ok := searcher.(app.PlatformSearcher); ok || searcher == nil {
+			return searcher
+		}
+		return app.PlatformSearcher{Searcher: searcher, Platform: platform, Reject: c.RejectOtherPlatforms}
+	}
+	c.DockerSearcher = wrap(c.DockerSearcher)
+	c.ImageStreamSearcher = wrap(c.ImageStreamSearcher)
+	c.ImageStreamByAnnotationSearcher = wrap(c.ImageStreamByAnnotationSearcher)
+	return nil
+}
+
+// ensureMetrics records the searches made by the searchers in the metrics, if they are set.
+func (c *AppConfig) ensureMetrics() {
+	if c.Metrics == nil {
+		return
+	}
+	wrap := func(searcher app.Searcher, name string) app.Searcher {
+		if _, ok := searcher.(app.MetricsSearcher); ok || searcher == nil {
+			return searcher
+		}
+		return app.MetricsSearcher{Searcher: searcher, Name: name, Metrics: c.Metrics}
+	}
+	c.DockerSearcher = wrap(c.DockerSearcher, app.SearcherDocker)
+	c.ImageStreamSearcher = wrap(c.ImageStreamSearcher, app.SearcherImageStream)
+	c.ImageStreamByAnnotationSearcher = wrap(c.ImageStreamByAnnotationSearcher, app.SearcherImageStreamAnnotation)
+	c.TemplateSearcher = wrap(c.TemplateSearcher, app.SearcherTemplate)
+	c.TemplateFileSearcher = wrap(c.TemplateFileSearcher, app.SearcherTemplateFile)
+}
+
+// ensureSearchCache shares the searches of the searchers through the search cache, if it is set.
+func (c *AppConfig) ensureSearchCache() {
+	if c.SearchCache == nil {
+		return
+	}
+	wrap := func(searcher app.Searcher, name string) app.Searcher {
+		if _, ok := searcher.(app.CachingSearcher); ok || searcher == nil {
+			return searcher
+		}
+		return app.CachingSearcher{Searcher: searcher, Cache: c.SearchCache, Scope: fmt.Sprintf("%s/%s", name, c.OriginNamespace)}
+	}
+	c.DockerSearcher = wrap(c.DockerSearcher, app.SearcherDocker)
+	c.ImageStreamSearcher = wrap(c.ImageStreamSearcher, app.SearcherImageStream)
+	c.ImageStreamByAnnotationSearcher = wrap(c.ImageStreamByAnnotationSearcher, app.SearcherImageStreamAnnotation)
+	c.TemplateSearcher = wrap(c.TemplateSearcher, app.SearcherTemplate)
+	c.TemplateFileSearcher = wrap(c.TemplateFileSearcher, app.SearcherTemplateFile)
+}
+
+// platform returns the parsed target platform and whether one is set.
+func (c *AppConfig) platform() (app.Platform, bool, error) {
+	if len(c.Platform) == 0 {
+		return app.Platform{}, false, nil
+	}
+	platform, err := app.ParsePlatform(c.Platform)
+	return platform, err == nil, err
+}
+
 // SetDockerClient sets the passed Docker client in the application configuration
 func (c *AppConfig) SetDockerClient(dockerclient *docker.Client) {
 	c.DockerSearcher = app.DockerClientSearcher{
@@ -208,6 +640,7 @@ func (c *AppConfig) SetOpenShiftClient(osclient client.Interface, OriginNamespac
 		TemplateConfigsNamespacer: osclient,
 		Namespaces:                namespaces,
 	}
+	c.MatchSuggester = app.NewMatchSuggester(osclient, osclient, namespaces)
 	c.TemplateFileSearcher = &app.TemplateFileSearcher{
 		Typer:        c.Typer,
 		Mapper:       c.Mapper,
@@ -228,6 +661,8 @@ func (c *AppConfig) AddArguments(args []string) []string {
 		switch {
 		case cmdutil.IsEnvironmentArgument(s):
 			c.Environment = append(c.Environment, s)
+		case app.IsBundleReference(s):
+			c.Components = append(c.Components, s)
 		case app.IsPossibleSourceRepository(s):
 			c.SourceRepositories = append(c.SourceRepositories, s)
 		case app.IsComponentReference(s):
@@ -250,6 +685,11 @@ func (c *AppConfig) individualSourceRepositories() (app.SourceRepositories, erro
 	for _, s := range c.SourceRepositories {
 		if repo, ok := c.RefBuilder.AddSourceRepository(s); ok {
 			repo.SetContextDir(c.ContextDir)
+			repo.SetDockerfilePath(c.DockerfilePath)
+			repo.SetCloneCache(c.CloneCache)
+			repo.SetCloneLimits(c.CloneLimits)
+			repo.SetWorkspace(c.Workspace)
+			repo.SetSourceAuth(c.sourceAuth)
 			if c.Strategy == "docker" {
 				repo.BuildWithDocker()
 			}
@@ -268,7 +708,7 @@ func (c *AppConfig) individualSourceRepositories() (app.SourceRepositories, erro
 // builder.
 func (c *AppConfig) addDockerfile() error {
 	if len(c.Strategy) != 0 && c.Strategy != "docker" {
-		return fmt.Errorf("when directly referencing a Dockerfile, the strategy must must be 'docker'")
+		return generrors.Newf(generrors.CodeInvalidArgument, "when directly referencing a Dockerfile, the strategy must must be 'docker'")
 	}
 	_, repos, errs := c.RefBuilder.Result()
 	if err := errors.NewAggregate(errs); err != nil {
@@ -279,7 +719,7 @@ func (c *AppConfig) addDockerfile() error {
 		// Create a new SourceRepository with the Dockerfile.
 		repo, err := app.NewSourceRepositoryForDockerfile(c.Dockerfile)
 		if err != nil {
-			return fmt.Errorf("provided Dockerfile is not valid: %v", err)
+			return generrors.Wrapf(generrors.CodeInvalidDockerfile, err, "provided Dockerfile is not valid: %v", err)
 		}
 		c.RefBuilder.AddExistingSourceRepository(repo)
 	case 1:
@@ -287,11 +727,11 @@ func (c *AppConfig) addDockerfile() error {
 		// eventually we generate a single BuildConfig with multiple
 		// sources.
 		if err := repos[0].AddDockerfile(c.Dockerfile); err != nil {
-			return fmt.Errorf("provided Dockerfile is not valid: %v", err)
+			return generrors.Wrapf(generrors.CodeInvalidDockerfile, err, "provided Dockerfile is not valid: %v", err)
 		}
 	default:
 		// Invalid.
-		return fmt.Errorf("--dockerfile cannot be used with multiple source repositories")
+		return generrors.Newf(generrors.CodeInvalidArgument, "--dockerfile cannot be used with multiple source repositories")
 	}
 	return nil
 }
@@ -307,7 +747,7 @@ func (c *AppConfig) addReferenceBuilderComponents(b *app.ReferenceBuilder) {
 			if c.AllowMissingImages {
 				resolver = append(resolver, app.WeightedResolver{Searcher: app.MissingImageSearcher{}, Weight: 100.0})
 			}
-			input.Resolver = resolver
+			input.Resolver = c.lockedResolver(c.cachedResolver("docker-image", c.acceptingResolver(resolver)))
 		}
 		return input
 	})
@@ -315,7 +755,7 @@ func (c *AppConfig) addReferenceBuilderComponents(b *app.ReferenceBuilder) {
 		input.Argument = fmt.Sprintf("--image-stream=%q", input.From)
 		input.Searcher = c.ImageStreamSearcher
 		if c.ImageStreamSearcher != nil {
-			input.Resolver = app.FirstMatchResolver{Searcher: c.ImageStreamSearcher}
+			input.Resolver = c.lockedResolver(c.cachedResolver("image-stream", app.FirstMatchResolver{Searcher: c.ImageStreamSearcher}))
 		}
 		return input
 	})
@@ -323,7 +763,7 @@ func (c *AppConfig) addReferenceBuilderComponents(b *app.ReferenceBuilder) {
 		input.Argument = fmt.Sprintf("--template=%q", input.From)
 		input.Searcher = c.TemplateSearcher
 		if c.TemplateSearcher != nil {
-			input.Resolver = app.HighestScoreResolver{Searcher: c.TemplateSearcher}
+			input.Resolver = c.lockedResolver(app.HighestScoreResolver{Searcher: c.TemplateSearcher})
 		}
 		return input
 	})
@@ -356,7 +796,7 @@ func (c *AppConfig) addReferenceBuilderComponents(b *app.ReferenceBuilder) {
 		if c.AllowMissingImages {
 			resolver = append(resolver, app.WeightedResolver{Searcher: app.MissingImageSearcher{}, Weight: 100.0})
 		}
-		input.Resolver = resolver
+		input.Resolver = c.lockedResolver(c.cachedResolver("component", c.acceptingResolver(resolver)))
 		input.Searcher = searcher
 		return input
 	})
@@ -364,22 +804,158 @@ func (c *AppConfig) addReferenceBuilderComponents(b *app.ReferenceBuilder) {
 	_, repos, _ := b.Result()
 	for _, repo := range repos {
 		repo.SetContextDir(c.ContextDir)
+		repo.SetDockerfilePath(c.DockerfilePath)
+		repo.SetCloneCache(c.CloneCache)
+		repo.SetCloneLimits(c.CloneLimits)
+		repo.SetWorkspace(c.Workspace)
+		repo.SetSourceAuth(c.sourceAuth)
 	}
 }
 
 // validate converts all of the arguments on the config into references to objects, or returns an error
 func (c *AppConfig) validate() (app.ComponentReferences, app.SourceRepositories, cmdutil.Environment, cmdutil.Environment, error) {
+	if err := c.expandBundles(); err != nil {
+		return nil, nil, nil, nil, err
+	}
 	b := c.RefBuilder
 	c.addReferenceBuilderComponents(b)
 	b.AddGroups(c.Groups)
 	refs, repos, errs := b.Result()
 
 	if len(c.Strategy) != 0 && len(repos) == 0 {
-		errs = append(errs, fmt.Errorf("when --strategy is specified you must provide at least one source code location"))
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "when --strategy is specified you must provide at least one source code location"))
+	}
+	switch c.Strategy {
+	case "", "docker", "pipeline", "source":
+	default:
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the strategy %q is not valid, it must be docker, pipeline or source", c.Strategy))
+	}
+
+	c.deploymentSecrets = nil
+	for _, spec := range c.DeploymentSecrets {
+		secret, err := app.ParseDeploymentSecret(spec)
+		if err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+			continue
+		}
+		c.deploymentSecrets = append(c.deploymentSecrets, secret)
+	}
+
+	if len(c.RegistryHost) > 0 {
+		if ref, err := imageapi.ParseDockerImageReference(c.RegistryHost + "/namespace/image"); err != nil || ref.Registry != c.RegistryHost {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the registry host %q must be a host name with an optional port", c.RegistryHost))
+		}
+	}
+
+	if (c.SuccessfulBuildsHistoryLimit != nil && *c.SuccessfulBuildsHistoryLimit < 0) || (c.FailedBuildsHistoryLimit != nil && *c.FailedBuildsHistoryLimit < 0) {
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "build history limits must not be negative"))
+	}
+
+	if len(c.TestTag) > 0 {
+		if c.Deploy {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "a test tag may only be used when the application is not deployed"))
+		}
+		if !validTagName.MatchString(c.TestTag) {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the test tag %q is not a valid image stream tag", c.TestTag))
+		}
+	}
+
+	if len(c.DockerfilePath) > 0 {
+		if err := app.ValidateDockerfilePath(c.DockerfilePath); err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+		}
+		if c.Strategy == "source" || c.Strategy == "pipeline" || len(c.Dockerfile) > 0 {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "a Dockerfile path may not be used with the source or pipeline strategy or a Dockerfile"))
+		}
+	}
+
+	if len(c.TargetNamespace) > 0 {
+		if ok, _ := validation.ValidateNamespaceName(c.TargetNamespace, false); !ok {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the target namespace %q is not a valid namespace name", c.TargetNamespace))
+		}
+	}
+
+	if err := c.TemplateLabels.Validate(); err != nil {
+		errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+	}
+
+	if len(c.ReleaseTag) > 0 && !validTagName.MatchString(c.ReleaseTag) {
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the release tag %q is not a valid image stream tag", c.ReleaseTag))
+	}
+
+	for _, tag := range c.OutputTags {
+		if !validTagName.MatchString(strings.Replace(tag, buildapi.BuildNumberParameter, "1", -1)) {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the output tag %q is not a valid image stream tag", tag))
+		}
+	}
+
+	for _, err := range buildvalidation.ValidateImageLabels(app.ImageLabels(c.OutputImageLabels), field.NewPath("outputImageLabels")) {
+		errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+	}
+
+	c.webhookTypes = nil
+	for _, name := range c.WebhookTriggerTypes {
+		t, err := app.ParseWebhookTriggerType(name)
+		if err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+			continue
+		}
+		c.webhookTypes = append(c.webhookTypes, t)
+	}
+	if len(c.WebhookSecretName) > 0 {
+		if ok, msg := validation.ValidateSecretName(c.WebhookSecretName, false); !ok {
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the webhook secret name %q is invalid: %s", c.WebhookSecretName, msg))
+		}
+	}
+
+	c.promotionTargets = nil
+	for _, spec := range c.PromoteTo {
+		target, err := app.ParsePromotionTarget(spec)
+		if err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+			continue
+		}
+		c.promotionTargets = append(c.promotionTargets, target)
+	}
+
+	for _, patch := range c.Patches {
+		if err := patch.Validate(); err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+		}
+	}
+
+	if err := app.ValidateClusterProfiles(c.ClusterProfiles); err != nil {
+		errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+	}
+
+	if err := c.PodSettings.Validate(); err != nil {
+		errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+	}
+
+	if len(c.NameTemplate) > 0 {
+		if _, err := app.ParseNameTemplate(c.NameTemplate); err != nil {
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+		}
+	}
+
+	if err := c.DockerfileLintSeverities.Validate(); err != nil {
+		errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err))
+	}
+
+	switch c.NonBuilderPolicy {
+	case "", NonBuilderReject, NonBuilderAsImage:
+	default:
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the non-builder policy must be one of %s or %s", NonBuilderReject, NonBuilderAsImage))
+	}
+
+	switch c.BuildRunPolicy {
+	case "", buildapi.BuildRunPolicyParallel, buildapi.BuildRunPolicySerial, buildapi.BuildRunPolicySerialLatestOnly:
+	default:
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the build run policy must be one of %s, %s or %s", buildapi.BuildRunPolicyParallel, buildapi.BuildRunPolicySerial, buildapi.BuildRunPolicySerialLatestOnly))
 	}
 
 	if c.BinaryBuild && (len(repos) > 0 || refs.HasSource()) {
-		errs = append(errs, fmt.Errorf("specifying binary builds and source repositories at the same time is not allowed"))
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "specifying binary builds and source repositories at the same time is not allowed"))
 	}
 
 	env, duplicateEnv, envErrs := cmdutil.ParseEnvironmentArguments(c.Environment)
@@ -387,6 +963,20 @@ func (c *AppConfig) validate() (app.ComponentReferences, app.SourceRepositories,
 		glog.V(1).Infof("The environment variable %q was overwritten", s)
 	}
 	errs = append(errs, envErrs...)
+	literals := cmdutil.Environment{}
+	c.envSources = map[string]*kapi.EnvVarSource{}
+	for k, v := range env {
+		source, literal, err := app.ParseEnvVarSource(v)
+		switch {
+		case err != nil:
+			errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "invalid value for the environment variable %q: %v", k, err))
+		case source != nil:
+			c.envSources[k] = source
+		default:
+			literals[k] = literal
+		}
+	}
+	env = literals
 
 	parms, duplicateParms, parmsErrs := cmdutil.ParseEnvironmentArguments(c.TemplateParameters)
 	for _, s := range duplicateParms {
@@ -407,15 +997,41 @@ func (c *AppConfig) componentsForRepos(repositories app.SourceRepositories) (app
 		info := repo.Info()
 		switch {
 		case info == nil:
-			errs = append(errs, fmt.Errorf("source not detected for repository %q", repo))
+			errs = append(errs, generrors.Newf(generrors.CodeCouldNotDetect, "source not detected for repository %q", repo))
 			continue
+		case info.Jenkinsfile && c.Strategy == "pipeline":
+			refs := b.AddComponents([]string{"pipeline"}, func(input *app.ComponentInput) app.ComponentReference {
+				input.Resolver = pipelineResolver{}
+				input.ScratchImage = true
+				input.ExpectToBuild = true
+				input.Use(repo)
+				repo.UsedBy(input)
+				repo.BuildWithPipeline()
+				return input
+			})
+			result = append(result, refs...)
+		case c.Strategy == "pipeline":
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "no %s was found in the repository %q and the requested build strategy is 'pipeline'", app.JenkinsfileName, repo))
 		case info.Dockerfile != nil && (len(c.Strategy) == 0 || c.Strategy == "docker"):
 			node := info.Dockerfile.AST()
 			baseImage := dockerfileutil.LastBaseImage(node)
 			if baseImage == "" {
-				errs = append(errs, fmt.Errorf("the Dockerfile in the repository %q has no FROM instruction", info.Path))
+				errs = append(errs, generrors.Newf(generrors.CodeInvalidDockerfile, "the Dockerfile in the repository %q has no FROM instruction", info.Path))
 				continue
 			}
+			mirror, ok, err := c.BaseImageMirrors.Mirror(baseImage)
+			if err != nil {
+				errs = append(errs, generrors.Wrapf(generrors.CodeInvalidArgument, err, "unable to find a mirror for the base image %q of the repository %q: %v", baseImage, info.Path, err))
+				continue
+			}
+			if ok {
+				glog.V(2).Infof("Using the mirror %q for the base image %q of the repository %q", mirror, baseImage, info.Path)
+				if c.baseImageOriginals == nil {
+					c.baseImageOriginals = make(map[string]string)
+				}
+				c.baseImageOriginals[mirror] = baseImage
+				baseImage = mirror
+			}
 			refs := b.AddComponents([]string{baseImage}, func(input *app.ComponentInput) app.ComponentReference {
 				resolver := app.PerfectMatchWeightedResolver{}
 				if c.ImageStreamSearcher != nil {
@@ -436,7 +1052,7 @@ func (c *AppConfig) componentsForRepos(repositories app.SourceRepositories) (app
 		default:
 			// TODO: Add support for searching for more than one language if len(info.Types) > 1
 			if len(info.Types) == 0 {
-				errs = append(errs, fmt.Errorf("no language was detected for repository at %q; please specify a builder image to use with your repository: [builder-image]~%s", repo, repo))
+				errs = append(errs, generrors.Newf(generrors.CodeNoLanguageDetected, "no language was detected for repository at %q; please specify a builder image to use with your repository: [builder-image]~%s", repo, repo))
 
 				continue
 			}
@@ -452,7 +1068,7 @@ func (c *AppConfig) componentsForRepos(repositories app.SourceRepositories) (app
 				if c.DockerSearcher != nil {
 					resolver = append(resolver, app.WeightedResolver{Searcher: c.DockerSearcher, Weight: 2.0})
 				}
-				input.Resolver = resolver
+				input.Resolver = c.acceptingResolver(resolver)
 				input.ExpectToBuild = true
 				input.Use(repo)
 				repo.UsedBy(input)
@@ -464,6 +1080,21 @@ func (c *AppConfig) componentsForRepos(repositories app.SourceRepositories) (app
 	return result, errors.NewAggregate(errs)
 }
 
+// pipelineResolver resolves the components of pipeline builds, which run the Jenkinsfile of their source
+// repository and have no builder image.
+type pipelineResolver struct{}
+
+// Resolve returns a match for the pipeline that is not an image
+func (pipelineResolver) Resolve(value string) (*app.ComponentMatch, error) {
+	return &app.ComponentMatch{
+		Value:       value,
+		Argument:    "--strategy=pipeline",
+		Name:        value,
+		Description: "Jenkins pipeline",
+		Builder:     true,
+	}, nil
+}
+
 // Resolve the references to ensure they are all valid, and identify any images that don't match user input.
 func Resolve(components app.ComponentReferences) error {
 	errs := []error{}
@@ -493,7 +1124,7 @@ func (c *AppConfig) detectPartialMatches(components app.ComponentReferences) err
 	for _, ref := range components {
 		input := ref.Input()
 		if input.ResolvedMatch.Score != 0.0 {
-			errs = append(errs, fmt.Errorf("component %q had only a partial match of %q - if this is the value you want to use, specify it explicitly", input.From, input.ResolvedMatch.Name))
+			errs = append(errs, generrors.Newf(generrors.CodePartialMatch, "component %q had only a partial match of %q - if this is the value you want to use, specify it explicitly", input.From, input.ResolvedMatch.Name))
 		}
 	}
 	return errors.NewAggregate(errs)
@@ -527,18 +1158,18 @@ func (c *AppConfig) inferBuildTypes(components app.ComponentReferences) (app.Com
 		switch {
 		case input.ExpectToBuild && input.ResolvedMatch.IsTemplate():
 			// TODO: harder - break the template pieces and check if source code can be attached (look for a build config, build image, etc)
-			errs = append(errs, fmt.Errorf("template with source code explicitly attached is not supported - you must either specify the template and source code separately or attach an image to the source code using the '[image]~[code]' form"))
+			errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "template with source code explicitly attached is not supported - you must either specify the template and source code separately or attach an image to the source code using the '[image]~[code]' form"))
 			continue
 		case input.ExpectToBuild && !input.ResolvedMatch.Builder && input.Uses != nil && !input.Uses.IsDockerBuild():
 			if len(c.Strategy) == 0 {
-				errs = append(errs, fmt.Errorf("the resolved match %q for component %q cannot build source code - check whether this is the image you want to use, then use --strategy=source to build using source or --strategy=docker to treat this as a Docker base image and set up a layered Docker build", input.ResolvedMatch.Name, ref))
+				errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "the resolved match %q for component %q cannot build source code - check whether this is the image you want to use, then use --strategy=source to build using source or --strategy=docker to treat this as a Docker base image and set up a layered Docker build", input.ResolvedMatch.Name, ref))
 				continue
 			}
 		}
 	}
 	if len(components) == 0 && c.BinaryBuild {
 		if len(c.Name) == 0 {
-			return nil, fmt.Errorf("you must provide a --name when you don't specify a source repository or base image")
+			return nil, generrors.Newf(generrors.CodeNameRequired, "you must provide a --name when you don't specify a source repository or base image")
 		}
 		ref := &app.ComponentInput{
 			From:          "--binary",
@@ -553,6 +1184,94 @@ func (c *AppConfig) inferBuildTypes(components app.ComponentReferences) (app.Com
 	return components, errors.NewAggregate(errs)
 }
 
+// checkBuilderCompatibility verifies that the builders the user chose for source support the language
+// detected in it. Incompatible builders are returned as warnings if AllowIncompatibleBuilders is set.
+func (c *AppConfig) checkBuilderCompatibility(components app.ComponentReferences) ([]app.BuilderWarning, error) {
+	var warnings []app.BuilderWarning
+	errs := []error{}
+	for _, ref := range components {
+		input := ref.Input()
+		if !input.ExpectToBuild || input.ResolvedMatch == nil || input.Uses == nil || input.Uses.IsDockerBuild() {
+			continue
+		}
+		err := app.CheckBuilderCompatibility(input.ResolvedMatch, input.Uses.Info())
+		if err == nil {
+			continue
+		}
+		if c.AllowIncompatibleBuilders {
+			warnings = append(warnings, app.BuilderWarning{Builder: input.ResolvedMatch.Name, Label: "supports", Message: err.Error()})
+			continue
+		}
+		errs = append(errs, generrors.Wrapf(generrors.CodeIncompatibleBuilder, err, "%v - check whether this is the builder you want to use for %q", err, input.Uses))
+	}
+	return warnings, errors.NewAggregate(errs)
+}
+
+// runtimeEOLWarnings returns a warning for each runtime of the components that build source that has reached,
+// or is about to reach, its end of life according to EOLTable.
+func (c *AppConfig) runtimeEOLWarnings(components app.ComponentReferences) []app.RuntimeEOLWarning {
+	table := c.EOLTable
+	if table == nil {
+		table = app.DefaultEOLTable
+	}
+	var warnings []app.RuntimeEOLWarning
+	now := time.Now()
+	for _, ref := range components {
+		input := ref.Input()
+		if !input.ExpectToBuild || input.Uses == nil || input.Uses.IsDockerBuild() || input.Uses.IsPipelineBuild() {
+			continue
+		}
+		warnings = append(warnings, app.CheckRuntimeEOL(table, now, input.Uses.String(), input.ResolvedMatch, input.Uses.Info())...)
+	}
+	return warnings
+}
+
+// lintDockerfiles checks the Dockerfiles of the repositories that are built with the docker strategy against
+// the lint rules and returns the findings. The findings of rules with the Error severity are returned as an
+// error instead.
+func (c *AppConfig) lintDockerfiles(repositories app.SourceRepositories) ([]app.DockerfileLintFinding, error) {
+	var findings []app.DockerfileLintFinding
+	errs := []error{}
+	for _, repo := range repositories {
+		info := repo.Info()
+		if info == nil || info.Dockerfile == nil || !repo.IsDockerBuild() {
+			continue
+		}
+		for _, finding := range app.LintDockerfile(repo.String(), info.Dockerfile.AST(), c.DockerfileLintSeverities) {
+			if finding.Severity == app.DockerfileLintError {
+				errs = append(errs, generrors.Newf(generrors.CodeInvalidDockerfile, "%s", finding))
+				continue
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings, errors.NewAggregate(errs)
+}
+
+// excludeNonBuilders handles the components that would be paired with the only source repository that has
+// not been used, but are not builders. Unless the NonBuilderPolicy is NonBuilderAsImage, each of them is an
+// error. Otherwise they are no longer expected to build, and a warning is returned for each of them.
+func (c *AppConfig) excludeNonBuilders(components app.ComponentReferences, repositories app.SourceRepositories) ([]NonBuilderWarning, error) {
+	if len(repositories) != 1 || len(c.Strategy) > 0 || repositories[0].IsDockerBuild() {
+		return nil, nil
+	}
+	var warnings []NonBuilderWarning
+	errs := []error{}
+	for _, ref := range components {
+		input := ref.Input()
+		if input.ResolvedMatch == nil || input.ResolvedMatch.Builder || input.ScratchImage {
+			continue
+		}
+		if c.NonBuilderPolicy == NonBuilderAsImage {
+			input.ExpectToBuild = false
+			warnings = append(warnings, NonBuilderWarning{Image: input.From, Source: repositories[0].String()})
+			continue
+		}
+		errs = append(errs, generrors.Newf(generrors.CodeNotABuilder, "%q is not a builder image and cannot build %s - use '%s~%s' with --strategy=docker to build on top of it, or include it as an image without building source with it", input.From, repositories[0], input.Value, repositories[0]))
+	}
+	return warnings, errors.NewAggregate(errs)
+}
+
 // ensureHasSource ensure every builder component has source code associated with it. It takes a list of component references
 // that are builders and have not been associated with source, and a set of source repositories that have not been associated
 // with a builder
@@ -567,9 +1286,9 @@ func (c *AppConfig) ensureHasSource(components app.ComponentReferences, reposito
 				for _, repo := range repositories {
 					suggestions += fmt.Sprintf("%s~%s\n", component, repo)
 				}
-				return fmt.Errorf("there are multiple code locations provided - use one of the following suggestions to declare which code goes with the image:\n%s", suggestions)
+				return generrors.Newf(generrors.CodeSourceRequired, "there are multiple code locations provided - use one of the following suggestions to declare which code goes with the image:\n%s", suggestions)
 			}
-			return fmt.Errorf("the following images require source code: %s\n"+
+			return generrors.Newf(generrors.CodeSourceRequired, "the following images require source code: %s\n"+
 				" and the following repositories are not used: %s\nUse '[image]~[repo]' to declare which code goes with which image", components, repositories)
 		case len(repositories) == 1:
 			glog.V(2).Infof("Using %q as the source for build", repositories[0])
@@ -598,7 +1317,7 @@ func (c *AppConfig) ensureHasSource(components app.ComponentReferences, reposito
 					input.ExpectToBuild = true
 				}
 			case c.ExpectToBuild:
-				return fmt.Errorf("you must specify at least one source repository URL, provide a Dockerfile, or indicate you wish to use binary builds")
+				return generrors.Newf(generrors.CodeSourceRequired, "you must specify at least one source repository URL, provide a Dockerfile, or indicate you wish to use binary builds")
 			default:
 				for _, component := range components {
 					component.Input().ExpectToBuild = false
@@ -613,9 +1332,13 @@ func (c *AppConfig) ensureHasSource(components app.ComponentReferences, reposito
 func (c *AppConfig) DetectSource(repositories []*app.SourceRepository) error {
 	errs := []error{}
 	for _, repo := range repositories {
+		start := time.Now()
 		err := repo.Detect(c.Detector, c.Strategy == "docker")
+		c.Metrics.ObserveDetection(start)
 		if err != nil {
-			if c.Strategy == "docker" && err == app.ErrNoLanguageDetected {
+			if _, ok := err.(*app.NoLanguageDetectedError); ok && c.Strategy == "docker" {
+				errs = append(errs, generrors.Wrapf(generrors.CodeNoDockerfile, err, "No Dockerfile was found at the root of the repository and the requested build strategy is 'docker': %v", err))
+			} else if c.Strategy == "docker" && err == app.ErrNoLanguageDetected {
 				errs = append(errs, ErrNoDockerfileDetected)
 			} else {
 				errs = append(errs, err)
@@ -628,22 +1351,45 @@ func (c *AppConfig) DetectSource(repositories []*app.SourceRepository) error {
 
 func validateEnforcedName(name string) error {
 	if ok, _ := validation.ValidateServiceName(name, false); !ok {
-		return fmt.Errorf("invalid name: %s. Must be an a lower case alphanumeric (a-z, and 0-9) string with a maximum length of 24 characters, where the first character is a letter (a-z), and the '-' character is allowed anywhere except the first or last character.", name)
+		return generrors.Newf(generrors.CodeInvalidArgument, "invalid name: %s. Must be an a lower case alphanumeric (a-z, and 0-9) string with a maximum length of 24 characters, where the first character is a letter (a-z), and the '-' character is allowed anywhere except the first or last character.", name)
 	}
 	return nil
 }
 
 func validateOutputImageReference(ref string) error {
 	if _, err := imageapi.ParseDockerImageReference(ref); err != nil {
-		return fmt.Errorf("invalid output image reference: %s", ref)
+		return generrors.Newf(generrors.CodeInvalidArgument, "invalid output image reference: %s", ref)
 	}
 	return nil
 }
 
+// outputRegistryHost returns the registry that generated Docker image outputs are pushed to, or an empty string
+// if they name no registry.
+func (c *AppConfig) outputRegistryHost() (string, error) {
+	if !c.OutputDocker {
+		return "", nil
+	}
+	if len(c.RegistryHost) > 0 || c.KubeClient == nil {
+		return c.RegistryHost, nil
+	}
+	host, ok, err := app.DiscoverRegistryHost(c.KubeClient)
+	if err != nil {
+		return "", generrors.Wrapf(generrors.CodeOf(err), err, "unable to find the integrated registry: %v", err)
+	}
+	if !ok {
+		glog.V(4).Infof("The integrated registry service was not found, Docker image outputs name no registry")
+	}
+	return host, nil
+}
+
 // buildPipelines converts a set of resolved, valid references into pipelines.
 func (c *AppConfig) buildPipelines(components app.ComponentReferences, environment app.Environment) (app.PipelineGroup, error) {
 	pipelines := app.PipelineGroup{}
-	pipelineBuilder := app.NewPipelineBuilder(c.Name, c.GetBuildEnvironment(environment), c.OutputDocker).To(c.To)
+	pipelineBuilder := app.NewPipelineBuilder(c.Name, c.GetBuildEnvironment(environment), c.OutputDocker).To(c.To).OutputTag(c.ReleaseTag)
+	registryHost, err := c.outputRegistryHost()
+	if err != nil {
+		return nil, err
+	}
 	for _, group := range components.Group() {
 		glog.V(4).Infof("found group: %v", group)
 		common := app.PipelineGroup{}
@@ -658,26 +1404,39 @@ func (c *AppConfig) buildPipelines(components app.ComponentReferences, environme
 			case refInput.ExpectToBuild:
 				glog.V(4).Infof("will add %q secrets into a build for a source build of %q", strings.Join(c.Secrets, ","), refInput.Uses)
 				if err := refInput.Uses.AddBuildSecrets(c.Secrets); err != nil {
-					return nil, fmt.Errorf("unable to add build secrets %q: %v", strings.Join(c.Secrets, ","), err)
+					return nil, generrors.Wrapf(generrors.CodeOf(err), err, "unable to add build secrets %q: %v", strings.Join(c.Secrets, ","), err)
 				}
 				glog.V(4).Infof("will use %q as the base image for a source build of %q", ref, refInput.Uses)
 				if pipeline, err = pipelineBuilder.NewBuildPipeline(from, refInput.ResolvedMatch, refInput.Uses); err != nil {
-					return nil, fmt.Errorf("can't build %q: %v", refInput.Uses, err)
+					return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't build %q: %v", refInput.Uses, err)
 				}
 			default:
 				glog.V(4).Infof("will include %q", ref)
 				if pipeline, err = pipelineBuilder.NewImagePipeline(from, refInput.ResolvedMatch); err != nil {
-					return nil, fmt.Errorf("can't include %q: %v", refInput, err)
+					return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't include %q: %v", refInput, err)
 				}
 			}
+			if len(refInput.PullSecrets) > 0 {
+				if pipeline.InputImage != nil {
+					pipeline.InputImage.PullSecrets = refInput.PullSecrets
+				} else {
+					pipeline.Image.PullSecrets = refInput.PullSecrets
+				}
+			}
+			if c.NoSourceImageTrigger && pipeline.Build != nil && pipeline.Build.Source != nil {
+				pipeline.Build.Source.NoSourceImageTrigger = true
+			}
 			if c.Deploy {
 				if err := pipeline.NeedsDeployment(environment, c.Labels, c.AsTestDeployment); err != nil {
-					return nil, fmt.Errorf("can't set up a deployment for %q: %v", refInput, err)
+					return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't set up a deployment for %q: %v", refInput, err)
 				}
 			}
 			if c.NoOutput {
 				pipeline.Build.Output = nil
 			}
+			if len(c.To) == 0 && len(registryHost) > 0 {
+				app.SetOutputRegistry(app.PipelineGroup{pipeline}, registryHost, c.targetNamespace())
+			}
 			if err := pipeline.Validate(); err != nil {
 				switch err.(type) {
 				case app.CircularOutputReferenceError:
@@ -693,9 +1452,9 @@ func (c *AppConfig) buildPipelines(components app.ComponentReferences, environme
 			}
 			common = append(common, pipeline)
 			if err := common.Reduce(); err != nil {
-				return nil, fmt.Errorf("can't create a pipeline from %s: %v", common, err)
+				return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't create a pipeline from %s: %v", common, err)
 			}
-			describeBuildPipelineWithImage(c.Out, ref, pipeline, c.OriginNamespace)
+			describeBuildPipelineWithImage(c.Out, ref, pipeline, c.targetNamespace(), c.imageRootAllowed())
 		}
 		pipelines = append(pipelines, common...)
 	}
@@ -721,7 +1480,7 @@ func (c *AppConfig) buildTemplates(components app.ComponentReferences, environme
 			}
 		}
 
-		result, err := c.OSClient.TemplateConfigs(c.OriginNamespace).Create(tpl)
+		result, err := c.OSClient.TemplateConfigs(c.OriginNamespace).Create(c.TemplateLabels.PrepareTemplate(tpl))
 		if err != nil {
 			return nil, fmt.Errorf("error processing template %s/%s: %v", c.OriginNamespace, tpl.Name, err)
 		}
@@ -730,6 +1489,9 @@ func (c *AppConfig) buildTemplates(components app.ComponentReferences, environme
 			err = errors.NewAggregate(errs)
 			return nil, fmt.Errorf("error processing template %s/%s: %v", c.OriginNamespace, tpl.Name, errs)
 		}
+		if err := c.TemplateLabels.Apply(result.Objects, c.Labels, c.Annotations); err != nil {
+			return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "can't label the objects of template %s/%s: %v", c.OriginNamespace, tpl.Name, err)
+		}
 		objects = append(objects, result.Objects...)
 
 		describeGeneratedTemplate(c.Out, ref, result, c.OriginNamespace)
@@ -737,6 +1499,20 @@ func (c *AppConfig) buildTemplates(components app.ComponentReferences, environme
 	return objects, nil
 }
 
+// addLabels adds the labels and annotations of the config to objects, which must not have been produced by
+// templates.
+func (c *AppConfig) addLabels(objects []runtime.Object) error {
+	for _, obj := range objects {
+		if err := outil.AddObjectLabels(obj, c.Labels); err != nil {
+			return generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+		}
+		if err := outil.AddObjectAnnotations(obj, c.Annotations); err != nil {
+			return generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+		}
+	}
+	return nil
+}
+
 // fakeSecretAccessor is used during dry runs of installation
 type fakeSecretAccessor struct {
 	token string
@@ -760,20 +1536,20 @@ func (c *AppConfig) installComponents(components app.ComponentReferences, env ap
 	jobs := components.InstallableComponentRefs()
 	switch {
 	case len(jobs) > 1:
-		return nil, "", fmt.Errorf("only one installable component may be provided: %s", jobs.HumanString(", "))
+		return nil, "", generrors.Newf(generrors.CodeInvalidArgument, "only one installable component may be provided: %s", jobs.HumanString(", "))
 	case len(jobs) == 0:
 		return nil, "", nil
 	}
 
 	job := jobs[0]
 	if len(components) > 1 {
-		return nil, "", fmt.Errorf("%q is installable and may not be specified with other components", job.Input().Value)
+		return nil, "", generrors.Newf(generrors.CodeInvalidArgument, "%q is installable and may not be specified with other components", job.Input().Value)
 	}
 	input := job.Input()
 
 	imageRef, err := app.InputImageFromMatch(input.ResolvedMatch)
 	if err != nil {
-		return nil, "", fmt.Errorf("can't include %q: %v", input, err)
+		return nil, "", generrors.Wrapf(generrors.CodeOf(err), err, "can't include %q: %v", input, err)
 	}
 	glog.V(4).Infof("Resolved match for installer %#v", input.ResolvedMatch)
 
@@ -786,7 +1562,7 @@ func (c *AppConfig) installComponents(components app.ComponentReferences, env ap
 		var ok bool
 		name, ok = imageRef.SuggestName()
 		if !ok {
-			return nil, "", fmt.Errorf("can't suggest a valid name, please specify a name with --name")
+			return nil, "", generrors.Newf(generrors.CodeNameRequired, "can't suggest a valid name, please specify a name with --name")
 		}
 	}
 	imageRef.ObjectName = name
@@ -826,6 +1602,9 @@ func (c *AppConfig) installComponents(components app.ComponentReferences, env ap
 	if err != nil {
 		return nil, "", err
 	}
+	if err := app.SetPodSettings(app.Objects{pod}, c.PodSettings); err != nil {
+		return nil, "", generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+	}
 	objects = append(objects, pod)
 	if secret != nil {
 		objects = append(objects, secret)
@@ -844,12 +1623,56 @@ func (c *AppConfig) installComponents(components app.ComponentReferences, env ap
 
 // Run executes the provided config to generate objects.
 func (c *AppConfig) Run() (*AppResult, error) {
-	return c.run(app.Acceptors{app.NewAcceptUnique(c.Typer), app.AcceptNew})
+	result, err := c.run(app.Acceptors{app.AcceptNew})
+	c.Metrics.ObserveFailure(err)
+	return result, err
+}
+
+// RunForClusters executes the provided config once and returns a result for each of ClusterProfiles, with the
+// differences of the cluster applied to a copy of the generated objects.
+func (c *AppConfig) RunForClusters() ([]*AppResult, error) {
+	if len(c.ClusterProfiles) == 0 {
+		return nil, generrors.Newf(generrors.CodeInvalidArgument, "at least one cluster profile is required")
+	}
+	// profiles are applied to the internal objects, which are converted to the output versions afterwards
+	versions := c.OutputVersions
+	c.OutputVersions = nil
+	result, err := c.Run()
+	c.OutputVersions = versions
+	if err != nil {
+		return nil, err
+	}
+	results := []*AppResult{}
+	for _, profile := range c.ClusterProfiles {
+		objects, err := app.ForCluster(result.List.Items, profile, result.Namespace)
+		if err != nil {
+			return nil, generrors.Wrapf(generrors.CodeOf(err), err, "unable to generate the application for the cluster %q: %v", profile.Name, err)
+		}
+		if objects, err = c.convertToOutputVersions(objects); err != nil {
+			return nil, err
+		}
+		clusterResult := *result
+		clusterResult.List = &kapi.List{Items: objects}
+		clusterResult.Cluster = profile.Name
+		results = append(results, &clusterResult)
+	}
+	return results, nil
 }
 
 // RunQuery executes the provided config and returns the result of the resolution.
 func (c *AppConfig) RunQuery() (*QueryResult, error) {
+	result, err := c.runQuery()
+	c.Metrics.ObserveFailure(err)
+	return result, err
+}
+
+func (c *AppConfig) runQuery() (*QueryResult, error) {
 	c.ensureDockerSearch()
+	if err := c.ensurePlatform(); err != nil {
+		return nil, err
+	}
+	c.ensureMetrics()
+	c.ensureSearchCache()
 	repositories, err := c.individualSourceRepositories()
 	if err != nil {
 		return nil, err
@@ -857,10 +1680,10 @@ func (c *AppConfig) RunQuery() (*QueryResult, error) {
 
 	if c.AsList {
 		if c.AsSearch {
-			return nil, fmt.Errorf("--list and --search can't be used together")
+			return nil, generrors.Newf(generrors.CodeInvalidArgument, "--list and --search can't be used together")
 		}
 		if c.HasArguments() {
-			return nil, fmt.Errorf("--list can't be used with arguments")
+			return nil, generrors.Newf(generrors.CodeInvalidArgument, "--list can't be used with arguments")
 		}
 		c.Components = append(c.Components, "*")
 	}
@@ -876,13 +1699,13 @@ func (c *AppConfig) RunQuery() (*QueryResult, error) {
 
 	errs := []error{}
 	if len(repositories) > 0 {
-		errs = append(errs, fmt.Errorf("--search can't be used with source code"))
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "--search can't be used with source code"))
 	}
-	if len(environment) > 0 {
-		errs = append(errs, fmt.Errorf("--search can't be used with --env"))
+	if len(environment) > 0 || len(c.envSources) > 0 {
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "--search can't be used with --env"))
 	}
 	if len(parameters) > 0 {
-		errs = append(errs, fmt.Errorf("--search can't be used with --param"))
+		errs = append(errs, generrors.Newf(generrors.CodeInvalidArgument, "--search can't be used with --param"))
 	}
 	if len(errs) > 0 {
 		return nil, errors.NewAggregate(errs)
@@ -895,29 +1718,64 @@ func (c *AppConfig) RunQuery() (*QueryResult, error) {
 	glog.V(4).Infof("Code %v", repositories)
 	glog.V(4).Infof("Components %v", components)
 
-	matches := app.ComponentMatches{}
-	objects := app.Objects{}
-	for _, ref := range components {
-		for _, match := range ref.Input().SearchMatches {
-			matches = append(matches, match)
-			if match.IsTemplate() {
-				objects = append(objects, match.Template)
-			} else if match.IsImage() {
-				if match.ImageStream != nil {
-					objects = append(objects, match.ImageStream)
-				}
-				if match.Image != nil {
-					objects = append(objects, match.Image)
-				}
-			}
-		}
-	}
+	matches, objects := searchMatches(components)
 	return &QueryResult{
 		Matches: matches,
 		List:    &kapi.List{Items: objects},
 	}, nil
 }
 
+// RunRecommendations resolves the components of the provided config and returns, for each, its candidate matches
+// ranked with the reason each was or was not selected. Components that match nothing have no candidates.
+func (c *AppConfig) RunRecommendations() ([]app.ComponentRecommendations, error) {
+	result, err := c.runRecommendations()
+	c.Metrics.ObserveFailure(err)
+	return result, err
+}
+
+func (c *AppConfig) runRecommendations() ([]app.ComponentRecommendations, error) {
+	c.ensureDockerSearch()
+	if err := c.ensurePlatform(); err != nil {
+		return nil, err
+	}
+	c.ensureMetrics()
+	c.ensureSearchCache()
+	if _, err := c.individualSourceRepositories(); err != nil {
+		return nil, err
+	}
+	components, _, _, _, err := c.validate()
+	if err != nil {
+		return nil, err
+	}
+	if len(components) == 0 {
+		return nil, ErrNoInputs
+	}
+
+	recommendations := []app.ComponentRecommendations{}
+	for _, ref := range components {
+		input := ref.Input()
+		value := input.Value
+		var candidates app.ComponentMatches
+		if input.Searcher != nil {
+			matches, errs := input.Searcher.Search(false, value)
+			if len(matches) == 0 && len(errs) > 0 {
+				return nil, errors.NewAggregate(errs)
+			}
+			candidates = matches
+		}
+		var selected *app.ComponentMatch
+		if input.Resolver != nil {
+			if match, err := input.Resolver.Resolve(value); match != nil {
+				selected = match
+			} else {
+				glog.V(4).Infof("Component %q was not resolved: %v", value, err)
+			}
+		}
+		recommendations = append(recommendations, app.Recommend(value, candidates, selected, input.ExpectToBuild))
+	}
+	return recommendations, nil
+}
+
 func (c *AppConfig) addImageSource(sourceRepos app.SourceRepositories) (app.ComponentReference, app.SourceRepositories, error) {
 	if len(c.SourceImage) == 0 {
 		return nil, sourceRepos, nil
@@ -953,7 +1811,7 @@ func (c *AppConfig) addImageSource(sourceRepos app.SourceRepositories) (app.Comp
 		sourceRepos[0].SetSourceImage(compRef)
 		sourceRepos[0].SetSourceImagePath(sourcePath, destPath)
 	default:
-		return nil, nil, fmt.Errorf("--image-source cannot be used with multiple source repositories")
+		return nil, nil, generrors.Newf(generrors.CodeInvalidArgument, "--image-source cannot be used with multiple source repositories")
 	}
 
 	return compRef, sourceRepos, nil
@@ -962,6 +1820,25 @@ func (c *AppConfig) addImageSource(sourceRepos app.SourceRepositories) (app.Comp
 // run executes the provided config applying provided acceptors.
 func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 	c.ensureDockerSearch()
+	if err := c.ensurePlatform(); err != nil {
+		return nil, err
+	}
+	c.ensureMetrics()
+	c.ensureSearchCache()
+	if c.Workspace == nil && c.CloneCache != nil {
+		return nil, ErrCloneCacheWithoutWorkspace
+	}
+	if c.Workspace == nil {
+		// the clones are not shared with other runs, so they are removed when this one returns
+		c.Workspace = app.NewWorkspace("", 0)
+		defer func() {
+			c.Workspace.Close()
+			c.Workspace = nil
+		}()
+	}
+	if err := c.loadSourceSecret(); err != nil {
+		return nil, err
+	}
 	repositories, err := c.individualSourceRepositories()
 	if err != nil {
 		return nil, err
@@ -970,6 +1847,9 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := c.loadLockfile(); err != nil {
+		return nil, err
+	}
 	components, repositories, environment, parameters, err := c.validate()
 	if err != nil {
 		return nil, err
@@ -985,23 +1865,35 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 		componentsIncludingImageComps = append(components, imageComp)
 	}
 	if err := Resolve(componentsIncludingImageComps); err != nil {
-		return nil, err
+		return nil, c.withSuggestions(err)
 	}
 
 	err = c.detectPartialMatches(componentsIncludingImageComps)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.writeLockfile(components); err != nil {
+		return nil, err
+	}
 
 	components, err = c.inferBuildTypes(components)
 	if err != nil {
 		return nil, err
 	}
 
+	nonBuilderWarnings, err := c.excludeNonBuilders(components.NeedsSource(), repositories.NotUsed())
+	if err != nil {
+		return nil, err
+	}
+
 	// Couple source with resolved builder components if possible
 	if err := c.ensureHasSource(components.NeedsSource(), repositories.NotUsed()); err != nil {
 		return nil, err
 	}
+	compatibilityWarnings, err := c.checkBuilderCompatibility(components)
+	if err != nil {
+		return nil, err
+	}
 
 	// For source repos that are not yet coupled with a component, create components
 	sourceComponents, err := c.componentsForRepos(repositories.NotUsed())
@@ -1014,11 +1906,16 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 		return nil, err
 	}
 	components = append(components, sourceComponents...)
+	eolWarnings := c.runtimeEOLWarnings(components)
+	lintFindings, err := c.lintDockerfiles(repositories)
+	if err != nil {
+		return nil, err
+	}
 
 	glog.V(4).Infof("Code [%v]", repositories)
 	glog.V(4).Infof("Components [%v]", components)
 
-	if len(repositories) == 0 && len(components) == 0 {
+	if len(repositories) == 0 && len(components) == 0 && len(c.RunningContainers) == 0 && len(c.ComposeFile) == 0 {
 		return nil, ErrNoInputs
 	}
 
@@ -1035,10 +1932,10 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 	}
 
 	if len(components.ImageComponentRefs().Group()) > 1 && len(c.Name) > 0 {
-		return nil, fmt.Errorf("only one component or source repository can be used when specifying a name")
+		return nil, generrors.Newf(generrors.CodeInvalidArgument, "only one component or source repository can be used when specifying a name")
 	}
 	if len(components.UseSource()) > 1 && len(c.To) > 0 {
-		return nil, fmt.Errorf("only one component with source can be used when specifying an output image reference")
+		return nil, generrors.Newf(generrors.CodeInvalidArgument, "only one component with source can be used when specifying an output image reference")
 	}
 
 	env := app.Environment(environment)
@@ -1049,10 +1946,17 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 		return nil, err
 	}
 	if len(installables) > 0 {
+		if err := c.addLabels(installables); err != nil {
+			return nil, err
+		}
+		if c.PreflightOnly {
+			return c.preflightResult(installables, name, nil)
+		}
 		return &AppResult{
-			List:      &kapi.List{Items: installables},
-			Name:      name,
-			Namespace: c.OriginNamespace,
+			List:       &kapi.List{Items: installables},
+			Name:       name,
+			Namespace:  c.OriginNamespace,
+			Components: components,
 
 			GeneratedJobs: true,
 		}, nil
@@ -1061,10 +1965,10 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 	pipelines, err := c.buildPipelines(components.ImageComponentRefs(), env)
 	if err != nil {
 		if err == app.ErrNameRequired {
-			return nil, fmt.Errorf("can't suggest a valid name, please specify a name with --name")
+			return nil, generrors.Wrapf(generrors.CodeNameRequired, err, "can't suggest a valid name, please specify a name with --name")
 		}
 		if err, ok := err.(app.CircularOutputReferenceError); ok {
-			return nil, fmt.Errorf("%v, please specify a different output reference with --to", err)
+			return nil, generrors.Wrapf(generrors.CodeCircularReference, err, "%v, please specify a different output reference with --to", err)
 		}
 		return nil, err
 	}
@@ -1074,19 +1978,184 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 	for _, p := range pipelines {
 		accepted, err := p.Objects(accept, acceptors)
 		if err != nil {
-			return nil, fmt.Errorf("can't setup %q: %v", p.From, err)
+			return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't setup %q: %v", p.From, err)
+		}
+		if original, ok := c.baseImageOriginals[p.From]; ok {
+			for _, obj := range accepted {
+				if bc, ok := obj.(*buildapi.BuildConfig); ok {
+					if bc.Annotations == nil {
+						bc.Annotations = make(map[string]string)
+					}
+					bc.Annotations[app.BaseImageOriginalAnnotation] = original
+				}
+			}
 		}
 		objects = append(objects, accepted...)
 	}
+	// components that share an image generate the same image stream
+	if objects, err = app.DedupeObjects(c.Typer, objects); err != nil {
+		return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+	}
+
+	containerObjects, err := app.RunningContainerObjects(c.RunningContainers)
+	if err != nil {
+		return nil, err
+	}
+	objects = append(objects, containerObjects...)
+
+	var composeWarnings []string
+	if len(c.ComposeFile) > 0 {
+		project, err := compose.ReadFile(c.ComposeFile)
+		if err != nil {
+			return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+		}
+		composeObjects, warnings, err := project.Objects()
+		if err != nil {
+			return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "unable to convert the docker-compose file %s: %v", c.ComposeFile, err)
+		}
+		objects = append(objects, composeObjects...)
+		composeWarnings = warnings
+	}
 
 	objects = app.AddServices(objects, false)
+	nameChanges := app.NameChanges{}
+	if len(c.NameTemplate) > 0 {
+		nameTemplate, err := app.ParseNameTemplate(c.NameTemplate)
+		if err != nil {
+			return nil, err
+		}
+		if nameChanges, err = nameTemplate.Apply(objects); err != nil {
+			return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+		}
+	}
+	if objects, err = app.AddVolumes(objects, c.Volumes); err != nil {
+		return nil, err
+	}
+	if err := app.AddDeploymentSecrets(objects, c.deploymentSecrets); err != nil {
+		return nil, err
+	}
+	if err := app.AddInitContainers(objects, c.InitContainers); err != nil {
+		return nil, err
+	}
+	if len(c.SourceSecret) > 0 {
+		app.SetSourceSecret(objects, c.SourceSecret)
+	}
+	var sourceSecretWarnings []app.SourceSecretWarning
+	if c.SourceSecretsByHost && c.KubeClient != nil {
+		finder := app.SourceSecretFinder{Client: c.KubeClient, Namespace: c.targetNamespace()}
+		if sourceSecretWarnings, err = app.AddSourceSecrets(objects, finder); err != nil {
+			return nil, generrors.Wrapf(generrors.CodeOf(err), err, "unable to find source secrets: %v", err)
+		}
+	}
+	if c.LinkDatabases {
+		objects = app.LinkDatabases(objects)
+	}
+	app.SetDefaultResources(objects, c.Resources)
+
+	if len(c.TestTag) > 0 || len(c.PostCommitScript) > 0 {
+		app.SetBuildTestHooks(objects, c.TestTag, buildapi.BuildPostCommitSpec{Script: c.PostCommitScript})
+	}
+	if len(c.OutputTags) > 0 {
+		app.SetBuildOutputTags(objects, c.OutputTags)
+	}
+	if len(c.OutputImageLabels) > 0 {
+		app.SetBuildOutputImageLabels(objects, c.OutputImageLabels)
+	}
+	if len(c.promotionTargets) > 0 {
+		if objects, err = app.PromoteOutput(objects, c.promotionTargets, c.targetNamespace()); err != nil {
+			return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+		}
+	}
+
+	for _, obj := range objects {
+		if bc, ok := obj.(*buildapi.BuildConfig); ok {
+			buildapi.SetBuildHistoryLimits(bc, c.SuccessfulBuildsHistoryLimit, c.FailedBuildsHistoryLimit)
+			if len(c.BuildRunPolicy) > 0 {
+				buildapi.SetRunPolicy(bc, c.BuildRunPolicy)
+			}
+		}
+	}
+	if len(c.envSources) > 0 {
+		app.SetEnvironmentSources(objects, c.envSources)
+	}
 
+	if len(c.Preset) > 0 {
+		preset, err := app.PresetForName(c.Preset)
+		if err != nil {
+			return nil, err
+		}
+		objects = preset.Apply(objects)
+	}
+	if !c.PodSettings.IsEmpty() {
+		if err := app.SetPodSettings(objects, c.PodSettings); err != nil {
+			return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+		}
+	}
+	if c.Expose {
+		objects = app.AddRoutes(objects)
+	}
+	if !c.NoRoute {
+		objects = app.AddHTTPRoutes(objects)
+	}
+
+	if err := c.addLabels(objects); err != nil {
+		return nil, err
+	}
 	templateObjects, err := c.buildTemplates(components.TemplateComponentRefs(), app.Environment(parameters))
 	if err != nil {
 		return nil, err
 	}
 	objects = append(objects, templateObjects...)
 
+	policy, err := c.generationPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Apply(objects); err != nil {
+		return nil, err
+	}
+
+	if c.Debug {
+		if objects, err = addDebugDeploymentConfigs(objects, pipelines, nameChanges); err != nil {
+			return nil, err
+		}
+	}
+
+	if platform, ok, _ := c.platform(); ok {
+		app.RecordPlatform(objects, platform)
+	}
+
+	if len(c.Environments) > 0 {
+		if objects, err = app.ForEnvironments(objects, c.Environments); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.SpreadReplicas {
+		if err := app.SpreadReplicas(objects); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.NoLatestTriggers {
+		app.DisableLatestTriggers(objects)
+	}
+	if c.WebhookTriggersOnly {
+		app.WebhookTriggersOnly(objects)
+	}
+	if len(c.webhookTypes) > 0 || len(c.WebhookSecretName) > 0 {
+		if err := c.checkWebhookSecret(); err != nil {
+			return nil, err
+		}
+		app.SetWebhookTriggers(objects, c.webhookTypes, c.WebhookSecretName)
+	}
+
+	app.SetTargetNamespace(objects, c.TargetNamespace, c.OriginNamespace)
+
+	if objects, err = app.ApplyPatches(objects, c.Patches); err != nil {
+		return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+	}
+
 	name = c.Name
 	if len(name) == 0 {
 		for _, pipeline := range pipelines {
@@ -1096,6 +2165,17 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 			}
 		}
 	}
+	if len(name) == 0 && len(c.RunningContainers) > 0 {
+		name = c.RunningContainers[0].Name
+	}
+	if len(name) == 0 && len(c.ComposeFile) > 0 {
+		for _, obj := range objects {
+			if dc, ok := obj.(*deployapi.DeploymentConfig); ok {
+				name = dc.Name
+				break
+			}
+		}
+	}
 	if len(name) == 0 {
 		for _, obj := range objects {
 			if bc, ok := obj.(*buildapi.BuildConfig); ok {
@@ -1105,14 +2185,313 @@ func (c *AppConfig) run(acceptors app.Acceptors) (*AppResult, error) {
 		}
 	}
 
+	if c.PreflightOnly {
+		return c.preflightResult(objects, name, c.imageUserWarnings(pipelines))
+	}
+
+	quotaWarnings, limitRangeWarnings := c.resourceWarnings(objects)
+
+	if c.GenerationRecord != nil {
+		c.recordGeneration(objects)
+	}
+
+	if objects, err = c.convertToOutputVersions(objects); err != nil {
+		return nil, err
+	}
+
 	return &AppResult{
-		List:      &kapi.List{Items: objects},
-		Name:      name,
-		HasSource: len(repositories) != 0,
-		Namespace: c.OriginNamespace,
+		List:       &kapi.List{Items: objects},
+		Name:       name,
+		HasSource:  len(repositories) != 0,
+		Namespace:  c.targetNamespace(),
+		Components: components,
+		Warnings:   c.imageUserWarnings(pipelines),
+
+		BuilderWarnings:      append(compatibilityWarnings, builderWarnings(pipelines)...),
+		NonBuilderWarnings:   nonBuilderWarnings,
+		DuplicateInputs:      c.RefBuilder.Duplicates(),
+		EOLWarnings:          eolWarnings,
+		OSWarnings:           osWarnings(pipelines),
+		SourceSecretWarnings: sourceSecretWarnings,
+
+		DockerfileLintFindings: lintFindings,
+		ComposeWarnings:        composeWarnings,
+
+		QuotaWarnings:      quotaWarnings,
+		LimitRangeWarnings: limitRangeWarnings,
 	}, nil
 }
 
+// recordGeneration annotates objects with GenerationRecord and records their generation in an event. Failing to
+// create the event does not fail the generation.
+func (c *AppConfig) recordGeneration(objects app.Objects) {
+	record := *c.GenerationRecord
+	if len(record.Arguments) == 0 {
+		for _, args := range [][]string{c.SourceRepositories, c.Components, c.ImageStreams, c.DockerImages, c.Templates, c.TemplateFiles} {
+			record.Arguments = append(record.Arguments, args...)
+		}
+	}
+	record.Annotate(objects)
+	namespace := c.targetNamespace()
+	if c.KubeClient == nil || len(namespace) == 0 {
+		return
+	}
+	if err := app.RecordGeneration(c.KubeClient, namespace, record, objects); err != nil {
+		glog.Warningf("Unable to record the generation of the application in %s: %v", namespace, err)
+	}
+}
+
+// convertToOutputVersions converts objects to OutputVersions, if set.
+func (c *AppConfig) convertToOutputVersions(objects app.Objects) (app.Objects, error) {
+	if len(c.OutputVersions) == 0 {
+		return objects, nil
+	}
+	converter := c.Converter
+	if converter == nil {
+		converter = kapi.Scheme
+	}
+	objects, err := app.ConvertToVersions(objects, c.OutputVersions, converter)
+	if err != nil {
+		return nil, generrors.Wrapf(generrors.CodeInvalidArgument, err, "%v", err)
+	}
+	return objects, nil
+}
+
+// cachedResolver wraps resolver with the resolution cache, if one is set. Kind identifies the type of argument
+// being resolved.
+func (c *AppConfig) cachedResolver(kind string, resolver app.Resolver) app.Resolver {
+	if c.ResolutionCache == nil {
+		return resolver
+	}
+	scope := fmt.Sprintf("%s/%s/%t", kind, c.OriginNamespace, c.AllowMissingImages)
+	if !c.MatchAcceptance.IsZero() {
+		scope = fmt.Sprintf("%s/%s", scope, c.MatchAcceptance)
+	}
+	return c.ResolutionCache.Resolver(scope, resolver)
+}
+
+// acceptingResolver accepts the partial or ambiguous matches of resolver allowed by MatchAcceptance.
+func (c *AppConfig) acceptingResolver(resolver app.Resolver) app.Resolver {
+	if c.MatchAcceptance.IsZero() {
+		return resolver
+	}
+	return app.AcceptingResolver{Resolver: resolver, Policy: c.MatchAcceptance}
+}
+
+// lockedResolver verifies resolutions made by resolver against the lockfile when in strict mode.
+func (c *AppConfig) lockedResolver(resolver app.Resolver) app.Resolver {
+	if c.lockfile == nil {
+		return resolver
+	}
+	return c.lockfile.Resolver(resolver)
+}
+
+// loadLockfile reads the lockfile that resolutions are verified against in strict mode.
+func (c *AppConfig) loadLockfile() error {
+	switch c.LockfileMode {
+	case "", app.LockfileWrite:
+		return nil
+	case app.LockfileStrict:
+		lock, err := app.ReadLockfile(c.LockfilePath)
+		if err != nil {
+			return err
+		}
+		c.lockfile = lock
+		return nil
+	default:
+		return generrors.Newf(generrors.CodeInvalidArgument, "unknown lockfile mode %q", c.LockfileMode)
+	}
+}
+
+// writeLockfile records the resolution of components in the lockfile when in write mode.
+func (c *AppConfig) writeLockfile(components app.ComponentReferences) error {
+	if c.LockfileMode != app.LockfileWrite {
+		return nil
+	}
+	lock := &app.Lockfile{}
+	for _, ref := range components {
+		input := ref.Input()
+		if input.ResolvedMatch == nil || input.Resolver == nil {
+			continue
+		}
+		lock.Add(input.Value, input.ResolvedMatch)
+	}
+	return lock.Write(c.LockfilePath)
+}
+
+// withSuggestions adds did-you-mean suggestions to the no match errors in err if a MatchSuggester is set.
+func (c *AppConfig) withSuggestions(err error) error {
+	if c.MatchSuggester == nil {
+		return err
+	}
+	return app.AddSuggestions(err, c.MatchSuggester.Suggest)
+}
+
+// imageUserWarnings returns the user warnings of the images deployed by pipelines, unless the security context
+// constraints let them run as root.
+func (c *AppConfig) imageUserWarnings(pipelines app.PipelineGroup) []*app.ImageUserWarning {
+	if c.imageRootAllowed() {
+		return nil
+	}
+	var warnings []*app.ImageUserWarning
+	for _, p := range pipelines {
+		if p.Deployment == nil || p.Image == nil {
+			continue
+		}
+		if warning := app.AnalyzeImageUser(p.Image.Reference.Name, p.Image.Info); warning != nil {
+			warnings = append(warnings, warning)
+		}
+	}
+	return warnings
+}
+
+// imageRootAllowed returns true if a security context constraint that applies to the default service account of
+// the target namespace, which generated deployment configs run as, allows containers to run as root. If the
+// constraints cannot be listed, root is assumed not to be allowed.
+func (c *AppConfig) imageRootAllowed() bool {
+	if c.rootAllowed != nil {
+		return *c.rootAllowed
+	}
+	allowed := false
+	if c.KubeClient != nil {
+		sccs, err := c.KubeClient.SecurityContextConstraints().List(kapi.ListOptions{})
+		if err == nil {
+			allowed = app.RootAllowed(sccs.Items, c.targetNamespace(), "default")
+		} else {
+			glog.V(4).Infof("Unable to list the security context constraints: %v", err)
+		}
+	}
+	c.rootAllowed = &allowed
+	return allowed
+}
+
+// addDebugDeploymentConfigs appends a debug variant of the deployment config of each pipeline whose language
+// has a debug profile. nameChanges maps the names of the pipelines to those a name template gave them.
+func addDebugDeploymentConfigs(objects app.Objects, pipelines app.PipelineGroup, nameChanges app.NameChanges) (app.Objects, error) {
+	profiles := map[string]*app.DebugProfile{}
+	for _, p := range pipelines {
+		if p.Deployment == nil {
+			continue
+		}
+		if profile := app.DebugProfileForPipeline(p); profile != nil {
+			profiles[nameChanges.Name("DeploymentConfig", p.Deployment.Name)] = profile
+		}
+	}
+	result := app.Objects{}
+	for _, obj := range objects {
+		result = append(result, obj)
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok {
+			continue
+		}
+		profile, ok := profiles[dc.Name]
+		if !ok {
+			continue
+		}
+		debug, err := app.DebugDeploymentConfig(dc, profile)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, debug)
+	}
+	return result, nil
+}
+
+// builderWarnings returns the warnings for the S2I labels of the builder images used by pipelines.
+func builderWarnings(pipelines app.PipelineGroup) []app.BuilderWarning {
+	var warnings []app.BuilderWarning
+	for _, p := range pipelines {
+		if p.Build == nil || p.Build.Strategy == nil || p.Build.Strategy.IsDockerBuild || p.Build.Strategy.Base == nil {
+			continue
+		}
+		contextDir := ""
+		if p.Build.Source != nil {
+			contextDir = p.Build.Source.ContextDir
+		}
+		base := p.Build.Strategy.Base
+		warnings = append(warnings, app.CheckS2IBuilder(base.Reference.Name, base.Info, contextDir)...)
+	}
+	return warnings
+}
+
+// osWarnings returns a warning for each deployment config in pipelines that mixes operating systems.
+func osWarnings(pipelines app.PipelineGroup) []app.MixedOSWarning {
+	var warnings []app.MixedOSWarning
+	seen := map[*app.DeploymentConfigRef]bool{}
+	for _, p := range pipelines {
+		if p.Deployment == nil || seen[p.Deployment] {
+			continue
+		}
+		seen[p.Deployment] = true
+		if warning := app.CheckDeploymentOS(p.Deployment); warning != nil {
+			warnings = append(warnings, *warning)
+		}
+	}
+	return warnings
+}
+
+// generationPolicy returns the policy to apply to generated objects, loading it from the target project if
+// none was provided.
+func (c *AppConfig) generationPolicy() (*app.GenerationPolicy, error) {
+	namespace := c.targetNamespace()
+	if c.Policy != nil || c.OSClient == nil || len(namespace) == 0 {
+		return c.Policy, nil
+	}
+	return app.GenerationPolicyForProject(c.OSClient.Projects(), namespace)
+}
+
+// checkWebhookSecret verifies that the Secret named by WebhookSecretName exists in the target namespace and has a
+// webhook secret, if it can be read.
+func (c *AppConfig) checkWebhookSecret() error {
+	if len(c.WebhookSecretName) == 0 || c.KubeClient == nil {
+		return nil
+	}
+	secret, err := c.KubeClient.Secrets(c.targetNamespace()).Get(c.WebhookSecretName)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return generrors.Newf(generrors.CodeInvalidArgument, "the webhook secret %q does not exist", c.WebhookSecretName)
+		}
+		return generrors.Wrapf(generrors.CodeOf(err), err, "unable to read the webhook secret %q: %v", c.WebhookSecretName, err)
+	}
+	if len(secret.Data[buildapi.WebHookSecretKey]) == 0 {
+		return generrors.Newf(generrors.CodeInvalidArgument, "the webhook secret %q has no %s value", c.WebhookSecretName, buildapi.WebHookSecretKey)
+	}
+	return nil
+}
+
+// loadSourceSecret validates the name of SourceSecret and, if the Secret can be read, loads the credentials
+// remote source repositories are cloned with during detection.
+func (c *AppConfig) loadSourceSecret() error {
+	if len(c.SourceSecret) == 0 {
+		return nil
+	}
+	if ok, msg := validation.ValidateSecretName(c.SourceSecret, false); !ok {
+		return generrors.Newf(generrors.CodeInvalidArgument, "the source secret name %q is invalid: %s", c.SourceSecret, msg)
+	}
+	if c.KubeClient == nil {
+		return nil
+	}
+	secret, err := c.KubeClient.Secrets(c.targetNamespace()).Get(c.SourceSecret)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return generrors.Newf(generrors.CodeInvalidArgument, "the source secret %q does not exist", c.SourceSecret)
+		}
+		return generrors.Wrapf(generrors.CodeOf(err), err, "unable to read the source secret %q: %v", c.SourceSecret, err)
+	}
+	if c.sourceAuth = app.NewSourceAuth(secret); c.sourceAuth == nil {
+		glog.V(2).Infof("The source secret %q has no SSH private key or basic auth credentials, source repositories are cloned without them", c.SourceSecret)
+	}
+	return nil
+}
+
+// targetNamespace returns the namespace the application is generated into.
+func (c *AppConfig) targetNamespace() string {
+	if len(c.TargetNamespace) > 0 {
+		return c.TargetNamespace
+	}
+	return c.OriginNamespace
+}
+
 func (c *AppConfig) Querying() bool {
 	return c.AsList || c.AsSearch
 }
@@ -18,10 +18,13 @@ import (
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/sets"
 
+	buildapi "github.com/openshift/origin/pkg/build/api"
 	client "github.com/openshift/origin/pkg/client/testclient"
 	"github.com/openshift/origin/pkg/dockerregistry"
 	"github.com/openshift/origin/pkg/generate/app"
+	"github.com/openshift/origin/pkg/generate/app/testsearcher"
 	"github.com/openshift/origin/pkg/generate/dockerfile"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
 	"github.com/openshift/origin/pkg/generate/source"
 	imageapi "github.com/openshift/origin/pkg/image/api"
 	templateapi "github.com/openshift/origin/pkg/template/api"
@@ -128,6 +131,157 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestValidateOutputImageLabels(t *testing.T) {
+	cfg := AppConfig{
+		Components:        []string{"ruby"},
+		OutputImageLabels: map[string]string{"team": "a", "-invalid": "b"},
+		RefBuilder:        &app.ReferenceBuilder{},
+	}
+	_, _, _, _, err := cfg.validate()
+	if err == nil || !strings.Contains(err.Error(), "outputImageLabels[0].name") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidateWebhookTriggers(t *testing.T) {
+	cfg := AppConfig{
+		Components:          []string{"ruby"},
+		WebhookTriggerTypes: []string{"github", "bitbucket"},
+		WebhookSecretName:   "Invalid_Name",
+		RefBuilder:          &app.ReferenceBuilder{},
+	}
+	_, _, _, _, err := cfg.validate()
+	if err == nil || !strings.Contains(err.Error(), `"bitbucket"`) || !strings.Contains(err.Error(), `"Invalid_Name"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckWebhookSecret(t *testing.T) {
+	secret := &kapi.Secret{
+		ObjectMeta: kapi.ObjectMeta{Name: "webhook", Namespace: "test"},
+		Data:       map[string][]byte{buildapi.WebHookSecretKey: []byte("secret")},
+	}
+	empty := &kapi.Secret{ObjectMeta: kapi.ObjectMeta{Name: "empty", Namespace: "test"}}
+	tests := map[string]struct {
+		objects  []runtime.Object
+		expected string
+	}{
+		"webhook": {objects: []runtime.Object{secret}},
+		"empty":   {objects: []runtime.Object{empty}, expected: "has no WebHookSecretKey value"},
+		"missing": {expected: "does not exist"},
+	}
+	for name, test := range tests {
+		cfg := AppConfig{OriginNamespace: "test", WebhookSecretName: name, KubeClient: ktestclient.NewSimpleFake(test.objects...)}
+		err := cfg.checkWebhookSecret()
+		if len(test.expected) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
+
+func TestImageUserWarnings(t *testing.T) {
+	pipelines := app.PipelineGroup{{
+		Deployment: &app.DeploymentConfigRef{Name: "php"},
+		Image: &app.ImageRef{
+			Reference: imageapi.DockerImageReference{Name: "php"},
+			Info:      &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "root"}},
+		},
+	}}
+	anyuid := &kapi.SecurityContextConstraints{
+		ObjectMeta: kapi.ObjectMeta{Name: "anyuid"},
+		RunAsUser:  kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyRunAsAny},
+		Users:      []string{"system:serviceaccount:test:default"},
+	}
+	tests := map[string]struct {
+		objects  []runtime.Object
+		warnings int
+	}{
+		"restricted": {warnings: 1},
+		"anyuid":     {objects: []runtime.Object{anyuid}},
+	}
+	for name, test := range tests {
+		cfg := AppConfig{OriginNamespace: "test", KubeClient: ktestclient.NewSimpleFake(test.objects...)}
+		if warnings := cfg.imageUserWarnings(pipelines); len(warnings) != test.warnings {
+			t.Errorf("%s: unexpected warnings: %v", name, warnings)
+		}
+	}
+}
+
+func TestLoadSourceSecret(t *testing.T) {
+	ssh := &kapi.Secret{
+		ObjectMeta: kapi.ObjectMeta{Name: "ssh", Namespace: "test"},
+		Data:       map[string][]byte{kapi.SSHAuthPrivateKey: []byte("key")},
+	}
+	empty := &kapi.Secret{ObjectMeta: kapi.ObjectMeta{Name: "empty", Namespace: "test"}}
+	tests := map[string]struct {
+		objects  []runtime.Object
+		auth     bool
+		expected string
+	}{
+		"ssh":          {objects: []runtime.Object{ssh}, auth: true},
+		"empty":        {objects: []runtime.Object{empty}},
+		"missing":      {expected: "does not exist"},
+		"Invalid_Name": {expected: "is invalid"},
+	}
+	for name, test := range tests {
+		cfg := AppConfig{OriginNamespace: "test", SourceSecret: name, KubeClient: ktestclient.NewSimpleFake(test.objects...)}
+		err := cfg.loadSourceSecret()
+		if len(test.expected) > 0 {
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("%s: unexpected error: %v", name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+		if (cfg.sourceAuth != nil) != test.auth {
+			t.Errorf("%s: unexpected source auth: %#v", name, cfg.sourceAuth)
+		}
+	}
+}
+
+func TestRunRecommendations(t *testing.T) {
+	tests := map[string]struct {
+		value    string
+		reason   app.RecommendationReason
+		selected bool
+	}{
+		"exact":   {value: "ruby", reason: app.RecommendationSelected, selected: true},
+		"partial": {value: "rub", reason: app.RecommendationPartialName},
+	}
+	for name, test := range tests {
+		cfg := AppConfig{
+			RefBuilder:          &app.ReferenceBuilder{},
+			ImageStreamSearcher: fakeImageStreamSearcher(),
+			TemplateSearcher:    fakeTemplateSearcher(),
+			Components:          []string{test.value},
+		}
+		recommendations, err := cfg.RunRecommendations()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		if len(recommendations) != 1 || len(recommendations[0].Recommendations) != 1 {
+			t.Errorf("%s: unexpected recommendations: %#v", name, recommendations)
+			continue
+		}
+		recommendation := recommendations[0].Recommendations[0]
+		if recommendation.Reason != test.reason || recommendation.Match.ImageStream == nil || recommendation.Match.ImageStream.Name != "ruby" {
+			t.Errorf("%s: unexpected recommendation: %#v", name, recommendation)
+		}
+		if (recommendations[0].Selected() != nil) != test.selected {
+			t.Errorf("%s: unexpected selection: %#v", name, recommendations[0].Selected())
+		}
+	}
+}
+
 func TestBuildTemplates(t *testing.T) {
 	tests := map[string]struct {
 		templateName string
@@ -275,6 +429,9 @@ func TestEnsureHasSource(t *testing.T) {
 			if !strings.Contains(err.Error(), test.expectedErr) {
 				t.Errorf("%s: Invalid error: Expected %s, got %v", test.name, test.expectedErr, err)
 			}
+			if code := generrors.CodeOf(err); code != generrors.CodeSourceRequired {
+				t.Errorf("%s: unexpected error code %q", test.name, code)
+			}
 		} else if len(test.expectedErr) != 0 {
 			t.Errorf("%s: Expected %s error but got none", test.name, test.expectedErr)
 		}
@@ -297,27 +454,6 @@ func mapContains(a, b map[string]string) bool {
 	return true
 }
 
-// ExactMatchDockerSearcher returns a match with the value that was passed in
-// and a march score of 0.0(exact)
-type ExactMatchDockerSearcher struct {
-	Errs []error
-}
-
-// Search always returns a match for every term passed in
-func (r *ExactMatchDockerSearcher) Search(precise bool, terms ...string) (app.ComponentMatches, []error) {
-	matches := app.ComponentMatches{}
-	for _, value := range terms {
-		matches = append(matches, &app.ComponentMatch{
-			Value:       value,
-			Name:        value,
-			Argument:    fmt.Sprintf("--docker-image=%q", value),
-			Description: fmt.Sprintf("Docker image %q", value),
-			Score:       0.0,
-		})
-	}
-	return matches, r.Errs
-}
-
 // PrepareAppConfig sets fields in config appropriate for running tests. It
 // returns two buffers bound to stdout and stderr.
 func PrepareAppConfig(config *AppConfig) (stdout, stderr *bytes.Buffer) {
@@ -391,6 +527,209 @@ func TestBuildPipelinesWithUnresolvedImage(t *testing.T) {
 	}
 }
 
+func TestLintDockerfiles(t *testing.T) {
+	dockerFile, err := app.NewDockerfile("FROM centos\nUSER 1001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dockerRepo, err := app.NewSourceRepository("https://github.com/foo/bar.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dockerRepo.BuildWithDocker()
+	dockerRepo.SetInfo(&app.SourceRepositoryInfo{Dockerfile: dockerFile})
+	sourceRepo, err := app.NewSourceRepository("https://github.com/foo/baz.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceRepo.SetInfo(&app.SourceRepositoryInfo{Dockerfile: dockerFile})
+	repos := app.SourceRepositories{dockerRepo, sourceRepo}
+
+	a := AppConfig{}
+	findings, err := a.lintDockerfiles(repos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Rule != app.LintLatestBaseImage || findings[0].Source != "https://github.com/foo/bar.git" {
+		t.Errorf("unexpected findings: %#v", findings)
+	}
+
+	a.DockerfileLintSeverities = app.DockerfileLintSeverities{app.LintLatestBaseImage: app.DockerfileLintError}
+	if _, err := a.lintDockerfiles(repos); err == nil || !strings.Contains(err.Error(), "the base image centos is not pinned") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildPipelinesJenkinsPipeline(t *testing.T) {
+	sourceRepo, err := app.NewSourceRepository("https://github.com/foo/bar.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dockerFile, err := app.NewDockerfile("FROM centos:7\nUSER 1001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceRepo.SetInfo(&app.SourceRepositoryInfo{Jenkinsfile: true, Dockerfile: dockerFile})
+
+	// the pipeline strategy is not detected from a Jenkinsfile
+	a := AppConfig{Deploy: true, Out: &bytes.Buffer{}}
+	a.RefBuilder = &app.ReferenceBuilder{}
+	if _, err := a.componentsForRepos(app.SourceRepositories{sourceRepo}); err != nil {
+		t.Fatal(err)
+	}
+	if sourceRepo.IsPipelineBuild() || !sourceRepo.IsDockerBuild() {
+		t.Fatalf("expected the repository to be built from its Dockerfile")
+	}
+
+	sourceRepo, err = app.NewSourceRepository("https://github.com/foo/bar.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceRepo.SetInfo(&app.SourceRepositoryInfo{Jenkinsfile: true, Dockerfile: dockerFile})
+	a = AppConfig{Deploy: true, Out: &bytes.Buffer{}, Strategy: "pipeline"}
+	a.RefBuilder = &app.ReferenceBuilder{}
+	refs, err := a.componentsForRepos(app.SourceRepositories{sourceRepo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Resolve(refs); err != nil {
+		t.Fatal(err)
+	}
+	if !sourceRepo.IsPipelineBuild() || sourceRepo.IsDockerBuild() {
+		t.Fatalf("expected the repository to be built with a pipeline")
+	}
+	group, err := a.buildPipelines(refs.ImageComponentRefs(), app.Environment{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(group) != 1 || group[0].Deployment != nil || group[0].Image != nil {
+		t.Fatalf("unexpected pipelines: %#v", group)
+	}
+	objects, err := group[0].Objects(app.NewAcceptFirst(), app.AcceptNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Fatalf("unexpected objects: %#v", objects)
+	}
+	bc, ok := objects[0].(*buildapi.BuildConfig)
+	if !ok || bc.Name != "bar" || bc.Spec.Strategy.JenkinsPipelineStrategy == nil || bc.Spec.Output.To != nil {
+		t.Errorf("unexpected build config: %#v", objects[0])
+	}
+
+	// a repository without a Jenkinsfile cannot be built with the pipeline strategy
+	otherRepo, err := app.NewSourceRepository("https://github.com/foo/baz.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherRepo.SetInfo(&app.SourceRepositoryInfo{Dockerfile: dockerFile})
+	a = AppConfig{Strategy: "pipeline"}
+	a.RefBuilder = &app.ReferenceBuilder{}
+	if _, err := a.componentsForRepos(app.SourceRepositories{otherRepo}); err == nil || !strings.Contains(err.Error(), "no Jenkinsfile was found") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildPipelinesRegistryHost(t *testing.T) {
+	registry := &kapi.Service{
+		ObjectMeta: kapi.ObjectMeta{Name: "docker-registry", Namespace: "default"},
+		Spec: kapi.ServiceSpec{
+			ClusterIP: "172.30.1.1",
+			Ports:     []kapi.ServicePort{{Port: 5000}},
+		},
+	}
+	tests := map[string]struct {
+		config   AppConfig
+		expected string
+	}{
+		"image stream output": {
+			config:   AppConfig{KubeClient: ktestclient.NewSimpleFake(registry)},
+			expected: "",
+		},
+		"discovered": {
+			config:   AppConfig{OutputDocker: true, KubeClient: ktestclient.NewSimpleFake(registry)},
+			expected: "172.30.1.1:5000/test/bar:latest",
+		},
+		"no registry service": {
+			config:   AppConfig{OutputDocker: true, KubeClient: ktestclient.NewSimpleFake()},
+			expected: "bar:latest",
+		},
+		"explicit": {
+			config:   AppConfig{OutputDocker: true, RegistryHost: "registry.example.com", KubeClient: ktestclient.NewSimpleFake(registry)},
+			expected: "registry.example.com/test/bar:latest",
+		},
+		"explicit output": {
+			config:   AppConfig{OutputDocker: true, RegistryHost: "registry.example.com", To: "other/bar:latest"},
+			expected: "other/bar:latest",
+		},
+	}
+	for name, test := range tests {
+		sourceRepo, err := app.NewSourceRepository("https://github.com/foo/bar.git")
+		if err != nil {
+			t.Fatal(err)
+		}
+		sourceRepo.BuildWithDocker()
+		refs := app.ComponentReferences{
+			app.ComponentReference(&app.ComponentInput{
+				Value:         "mysql",
+				Uses:          sourceRepo,
+				ExpectToBuild: true,
+				ResolvedMatch: &app.ComponentMatch{Value: "mysql"},
+			}),
+		}
+		config := test.config
+		config.OriginNamespace = "test"
+		config.Out = &bytes.Buffer{}
+		group, err := config.buildPipelines(refs, app.Environment{})
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		output, err := group[0].Build.Output.BuildOutput()
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(test.expected) == 0 {
+			if output.To.Kind != "ImageStreamTag" {
+				t.Errorf("%s: unexpected output: %#v", name, output.To)
+			}
+			continue
+		}
+		if output.To.Name != test.expected {
+			t.Errorf("%s: expected output %s, got %s", name, test.expected, output.To.Name)
+		}
+	}
+}
+
+func TestCheckBuilderCompatibility(t *testing.T) {
+	sourceRepo, err := app.NewSourceRepository("https://github.com/foo/bar.git")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourceRepo.SetInfo(&app.SourceRepositoryInfo{Types: []app.SourceLanguageType{{Platform: "ruby"}}})
+	stream := builderImageStream()
+	stream.Spec.Tags = map[string]imageapi.TagReference{"latest": {Annotations: map[string]string{"supports": "nodejs"}}}
+	refs := app.ComponentReferences{
+		&app.ComponentInput{
+			Value:         "nodejs",
+			Uses:          sourceRepo,
+			ExpectToBuild: true,
+			ResolvedMatch: &app.ComponentMatch{Name: "nodejs", ImageStream: stream, ImageTag: "latest", Builder: true},
+		},
+	}
+
+	a := AppConfig{}
+	if _, err := a.checkBuilderCompatibility(refs); generrors.CodeOf(err) != generrors.CodeIncompatibleBuilder {
+		t.Errorf("unexpected error: %v", err)
+	}
+	a.AllowIncompatibleBuilders = true
+	warnings, err := a.checkBuilderCompatibility(refs)
+	if err != nil || len(warnings) != 1 || warnings[0].Builder != "nodejs" {
+		t.Errorf("unexpected warnings: %#v %v", warnings, err)
+	}
+}
+
 func builderImageStream() *imageapi.ImageStream {
 	return &imageapi.ImageStream{
 		ObjectMeta: kapi.ObjectMeta{
@@ -503,7 +842,7 @@ func fakeDockerSearcher() app.Searcher {
 			Image:  dockerBuilderImage(),
 		},
 		Insecure:         true,
-		RegistrySearcher: &ExactMatchDockerSearcher{},
+		RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{},
 	}
 }
 
@@ -518,7 +857,7 @@ func fakeSimpleDockerSearcher() app.Searcher {
 				},
 			},
 		},
-		RegistrySearcher: &ExactMatchDockerSearcher{},
+		RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{},
 	}
 }
 
@@ -547,3 +886,90 @@ func MockSourceRepositories(t *testing.T, file string) []*app.SourceRepository {
 	}
 	return b
 }
+
+func TestExcludeNonBuilders(t *testing.T) {
+	repo, err := app.NewSourceRepository("https://github.com/openshift/ruby-hello-world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newComponents := func() app.ComponentReferences {
+		return app.ComponentReferences{
+			&app.ComponentInput{From: "ruby", Value: "ruby", ExpectToBuild: true, ResolvedMatch: &app.ComponentMatch{Builder: true}},
+			&app.ComponentInput{From: "mongodb", Value: "mongodb", ExpectToBuild: true, ResolvedMatch: &app.ComponentMatch{}},
+		}
+	}
+
+	components := newComponents()
+	config := &AppConfig{}
+	if _, err := config.excludeNonBuilders(components, app.SourceRepositories{repo}); generrors.CodeOf(err) != generrors.CodeNotABuilder {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	config.NonBuilderPolicy = NonBuilderAsImage
+	warnings, err := config.excludeNonBuilders(components, app.SourceRepositories{repo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Image != "mongodb" {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	if !components[0].Input().ExpectToBuild || components[1].Input().ExpectToBuild {
+		t.Errorf("only the builder should build source")
+	}
+
+	config = &AppConfig{Strategy: "source"}
+	if warnings, err := config.excludeNonBuilders(newComponents(), app.SourceRepositories{repo}); err != nil || len(warnings) != 0 {
+		t.Errorf("an explicit strategy should build with any image: %v %v", warnings, err)
+	}
+}
+
+func TestExpandBundles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalogs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "catalog.yaml")
+	catalog := `
+bundles:
+- name: payments-stack
+  components:
+  - image: centos/ruby-22-centos7:2.2
+    source: https://github.com/acme/payments-api
+  - imageStream: openshift/mysql:5.6
+  - template: redis-persistent
+`
+	if err := ioutil.WriteFile(path, []byte(catalog), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &AppConfig{}
+	c.Components = []string{"ruby", "bundle://acme/payments-stack"}
+	c.Catalogs = []string{"acme=" + path}
+	if err := c.expandBundles(); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.Components, []string{"ruby"}) {
+		t.Errorf("unexpected components: %v", c.Components)
+	}
+	if !reflect.DeepEqual(c.DockerImages, []string{"centos/ruby-22-centos7:2.2~https://github.com/acme/payments-api"}) {
+		t.Errorf("unexpected images: %v", c.DockerImages)
+	}
+	if !reflect.DeepEqual(c.ImageStreams, []string{"openshift/mysql:5.6"}) {
+		t.Errorf("unexpected image streams: %v", c.ImageStreams)
+	}
+	if !reflect.DeepEqual(c.Templates, []string{"redis-persistent"}) {
+		t.Errorf("unexpected templates: %v", c.Templates)
+	}
+
+	tests := map[string]AppConfig{
+		"malformed catalog": {Components: []string{"bundle://acme/payments-stack"}, Catalogs: []string{path}},
+		"unknown catalog":   {Components: []string{"bundle://acme/payments-stack"}, Catalogs: []string{"other=" + path}},
+		"unknown bundle":    {Components: []string{"bundle://acme/missing"}, Catalogs: []string{"acme=" + path}},
+	}
+	for name, c := range tests {
+		if err := c.expandBundles(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
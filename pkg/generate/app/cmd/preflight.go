@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	kerrors "k8s.io/kubernetes/pkg/api/errors"
+	kresource "k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/openshift/origin/pkg/generate/app"
+)
+
+// PreflightReport describes whether the objects generated for an application could be created in the
+// target namespace. It is built from reads against the cluster only.
+type PreflightReport struct {
+	Namespace string
+	// NamespaceExists is false if the namespace does not exist or is not visible to the user. No other
+	// checks are made in that case.
+	NamespaceExists bool
+	// Collisions lists the generated objects whose names are already in use in the namespace.
+	Collisions []PreflightCollision
+	// SecurityWarnings describes the images that are likely to fail under the restricted security context
+	// constraint.
+	SecurityWarnings []*app.ImageUserWarning
+	// QuotaViolations lists the quota resources the generated objects would exceed.
+	QuotaViolations []QuotaViolation
+	// LimitRangeViolations lists the containers the limit ranges of the namespace would reject.
+	LimitRangeViolations []LimitRangeViolation
+}
+
+// Passed returns true if no check found a problem that would prevent the objects from being created.
+// Security warnings are not considered.
+func (r *PreflightReport) Passed() bool {
+	return r.NamespaceExists && len(r.Collisions) == 0 && len(r.QuotaViolations) == 0 && len(r.LimitRangeViolations) == 0
+}
+
+// PreflightCollision identifies a generated object whose name is already in use.
+type PreflightCollision struct {
+	Kind string
+	Name string
+}
+
+func (c PreflightCollision) String() string {
+	return fmt.Sprintf("%s %q already exists", c.Kind, c.Name)
+}
+
+// preflightResult returns the result of checking objects against the target namespace.
+func (c *AppConfig) preflightResult(objects []runtime.Object, name string, warnings []*app.ImageUserWarning) (*AppResult, error) {
+	report, err := c.preflight(objects, warnings)
+	if err != nil {
+		return nil, err
+	}
+	return &AppResult{
+		List:      &kapi.List{},
+		Name:      name,
+		Namespace: c.targetNamespace(),
+		Preflight: report,
+	}, nil
+}
+
+// preflight checks the generated objects against the target namespace without creating them.
+func (c *AppConfig) preflight(objects []runtime.Object, warnings []*app.ImageUserWarning) (*PreflightReport, error) {
+	if c.OSClient == nil || c.KubeClient == nil {
+		return nil, fmt.Errorf("preflight checks require a connection to the server")
+	}
+	report := &PreflightReport{Namespace: c.targetNamespace()}
+	if _, err := c.OSClient.Projects().Get(c.targetNamespace()); err != nil {
+		if kerrors.IsNotFound(err) || kerrors.IsForbidden(err) {
+			return report, nil
+		}
+		return nil, err
+	}
+	report.NamespaceExists = true
+	report.SecurityWarnings = warnings
+
+	collisions, err := c.preflightCollisions(objects)
+	if err != nil {
+		return nil, err
+	}
+	report.Collisions = collisions
+
+	quotas, limitRanges, err := c.namespaceLimits()
+	if err != nil {
+		return nil, err
+	}
+	report.QuotaViolations = quotaViolations(quotas, requestedResources(objects))
+	report.LimitRangeViolations = limitRangeViolations(limitRanges, objects)
+	return report, nil
+}
+
+// preflightCollisions returns the objects whose names are already in use in the namespace.
+func (c *AppConfig) preflightCollisions(objects []runtime.Object) ([]PreflightCollision, error) {
+	mapper := &kresource.Mapper{ObjectTyper: c.Typer, RESTMapper: c.Mapper, ClientMapper: c.ClientMapper}
+	var collisions []PreflightCollision
+	for _, obj := range objects {
+		info, err := mapper.InfoForObject(obj)
+		if err != nil {
+			return nil, err
+		}
+		_, err = kresource.NewHelper(info.Client, info.Mapping).Get(c.targetNamespace(), info.Name, false)
+		switch {
+		case err == nil:
+			collisions = append(collisions, PreflightCollision{Kind: info.Mapping.GroupVersionKind.Kind, Name: info.Name})
+		case !kerrors.IsNotFound(err):
+			return nil, err
+		}
+	}
+	return collisions, nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	ktestclient "k8s.io/kubernetes/pkg/client/unversioned/testclient"
+
+	"github.com/openshift/origin/pkg/client/testclient"
+)
+
+func TestPreflightMissingNamespace(t *testing.T) {
+	c := &AppConfig{
+		OSClient:        testclient.NewSimpleFake(),
+		KubeClient:      ktestclient.NewSimpleFake(),
+		OriginNamespace: "missing",
+	}
+	report, err := c.preflight(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.NamespaceExists || report.Passed() {
+		t.Errorf("unexpected report: %#v", report)
+	}
+
+	c.OSClient = nil
+	if _, err := c.preflight(nil, nil); err == nil {
+		t.Errorf("expected an error without a client")
+	}
+}
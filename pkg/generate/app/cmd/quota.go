@@ -0,0 +1,253 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/glog"
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// QuotaViolation describes a resource of a quota that the generated objects would exceed.
+type QuotaViolation struct {
+	Quota     string
+	Resource  kapi.ResourceName
+	Requested resource.Quantity
+	Available resource.Quantity
+}
+
+func (v QuotaViolation) String() string {
+	return fmt.Sprintf("quota %q allows %s more %s, but %s are requested", v.Quota, v.Available.String(), v.Resource, v.Requested.String())
+}
+
+// LimitRangeViolation describes a container of a generated object that a limit range would reject.
+type LimitRangeViolation struct {
+	LimitRange string
+	Kind       string
+	Name       string
+	Container  string
+	Resource   kapi.ResourceName
+	Reason     string
+}
+
+func (v LimitRangeViolation) String() string {
+	return fmt.Sprintf("container %q in %s %q: %s %s (limit range %q)", v.Container, v.Kind, v.Name, v.Resource, v.Reason, v.LimitRange)
+}
+
+// namespaceLimits returns the resource quotas and limit ranges of the target namespace.
+func (c *AppConfig) namespaceLimits() ([]kapi.ResourceQuota, []kapi.LimitRange, error) {
+	quotas, err := c.KubeClient.ResourceQuotas(c.targetNamespace()).List(kapi.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	limitRanges, err := c.KubeClient.LimitRanges(c.targetNamespace()).List(kapi.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return quotas.Items, limitRanges.Items, nil
+}
+
+// resourceWarnings returns the quotas and limit ranges of the target namespace that would reject objects.
+// The checks are best effort, and are skipped if the namespace can't be read.
+func (c *AppConfig) resourceWarnings(objects []runtime.Object) ([]QuotaViolation, []LimitRangeViolation) {
+	if c.KubeClient == nil || len(c.targetNamespace()) == 0 {
+		return nil, nil
+	}
+	quotas, limitRanges, err := c.namespaceLimits()
+	if err != nil {
+		glog.V(4).Infof("Unable to check the quotas of %q: %v", c.targetNamespace(), err)
+		return nil, nil
+	}
+	return quotaViolations(quotas, requestedResources(objects)), limitRangeViolations(limitRanges, objects)
+}
+
+// requestedResources returns the quota resources consumed by creating objects. Containers without requests
+// are counted with their limits, which is what the server defaults their requests to.
+func requestedResources(objects []runtime.Object) kapi.ResourceList {
+	requested := kapi.ResourceList{}
+	count := func(name kapi.ResourceName, n int64) {
+		addQuantity(requested, name, *resource.NewQuantity(n, resource.DecimalSI))
+	}
+	pods := func(spec *kapi.PodSpec, replicas int64) {
+		count(kapi.ResourcePods, replicas)
+		for _, container := range spec.Containers {
+			for _, name := range []kapi.ResourceName{kapi.ResourceCPU, kapi.ResourceMemory} {
+				q, ok := container.Resources.Requests[name]
+				if !ok {
+					q, ok = container.Resources.Limits[name]
+				}
+				if !ok {
+					continue
+				}
+				for i := int64(0); i < replicas; i++ {
+					addQuantity(requested, name, q)
+				}
+			}
+		}
+	}
+	for _, obj := range objects {
+		switch t := obj.(type) {
+		case *deployapi.DeploymentConfig:
+			count(kapi.ResourceReplicationControllers, 1)
+			if t.Spec.Template != nil {
+				pods(&t.Spec.Template.Spec, int64(t.Spec.Replicas))
+			}
+		case *kapi.Pod:
+			pods(&t.Spec, 1)
+		case *kapi.Service:
+			count(kapi.ResourceServices, 1)
+		case *kapi.Secret:
+			count(kapi.ResourceSecrets, 1)
+		case *kapi.PersistentVolumeClaim:
+			count(kapi.ResourcePersistentVolumeClaims, 1)
+		}
+	}
+	return requested
+}
+
+// quotaViolations returns the resources of quotas that have less available than requested.
+func quotaViolations(quotas []kapi.ResourceQuota, requested kapi.ResourceList) []QuotaViolation {
+	names := []string{}
+	for name := range requested {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	var violations []QuotaViolation
+	for _, quota := range quotas {
+		hard := quota.Status.Hard
+		if len(hard) == 0 {
+			hard = quota.Spec.Hard
+		}
+		for _, name := range names {
+			limit, ok := hard[kapi.ResourceName(name)]
+			if !ok {
+				continue
+			}
+			available := *limit.Copy()
+			if used, ok := quota.Status.Used[kapi.ResourceName(name)]; ok {
+				available.Sub(used)
+			}
+			want := requested[kapi.ResourceName(name)]
+			if want.Cmp(available) > 0 {
+				violations = append(violations, QuotaViolation{
+					Quota:     quota.Name,
+					Resource:  kapi.ResourceName(name),
+					Requested: want,
+					Available: available,
+				})
+			}
+		}
+	}
+	return violations
+}
+
+// limitRangeViolations returns the containers of objects that the container limits of limitRanges would
+// reject, once the defaults of the limit ranges are applied.
+func limitRangeViolations(limitRanges []kapi.LimitRange, objects []runtime.Object) []LimitRangeViolation {
+	var violations []LimitRangeViolation
+	for _, obj := range objects {
+		var (
+			kind, name string
+			spec       *kapi.PodSpec
+		)
+		switch t := obj.(type) {
+		case *deployapi.DeploymentConfig:
+			if t.Spec.Template == nil {
+				continue
+			}
+			kind, name, spec = "deploymentconfig", t.Name, &t.Spec.Template.Spec
+		case *kapi.Pod:
+			kind, name, spec = "pod", t.Name, &t.Spec
+		default:
+			continue
+		}
+		for _, limitRange := range limitRanges {
+			for _, item := range limitRange.Spec.Limits {
+				if item.Type != kapi.LimitTypeContainer {
+					continue
+				}
+				for _, container := range spec.Containers {
+					for _, v := range containerLimitViolations(item, container.Resources) {
+						v.LimitRange, v.Kind, v.Name, v.Container = limitRange.Name, kind, name, container.Name
+						violations = append(violations, v)
+					}
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// containerLimitViolations checks the resources of a container against a container limit range item.
+func containerLimitViolations(item kapi.LimitRangeItem, resources kapi.ResourceRequirements) []LimitRangeViolation {
+	names := map[string]struct{}{}
+	for _, list := range []kapi.ResourceList{item.Min, item.Max, item.MaxLimitRequestRatio} {
+		for name := range list {
+			names[string(name)] = struct{}{}
+		}
+	}
+	sorted := []string{}
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var violations []LimitRangeViolation
+	for _, s := range sorted {
+		name := kapi.ResourceName(s)
+		limit, hasLimit := resources.Limits[name]
+		if !hasLimit {
+			limit, hasLimit = item.Default[name]
+		}
+		request, hasRequest := resources.Requests[name]
+		if !hasRequest {
+			request, hasRequest = item.DefaultRequest[name]
+		}
+		if !hasRequest && hasLimit {
+			request, hasRequest = limit, true
+		}
+
+		var reason string
+		if min, ok := item.Min[name]; ok {
+			switch {
+			case !hasRequest:
+				reason = fmt.Sprintf("has no request, but a request of at least %s is required", min.String())
+			case request.Cmp(min) < 0:
+				reason = fmt.Sprintf("request %s is below the minimum %s", request.String(), min.String())
+			}
+		}
+		if max, ok := item.Max[name]; ok && len(reason) == 0 {
+			switch {
+			case !hasLimit:
+				reason = fmt.Sprintf("has no limit, but a limit of at most %s is required", max.String())
+			case limit.Cmp(max) > 0:
+				reason = fmt.Sprintf("limit %s exceeds the maximum %s", limit.String(), max.String())
+			}
+		}
+		if ratio, ok := item.MaxLimitRequestRatio[name]; ok && len(reason) == 0 && hasLimit && hasRequest && request.MilliValue() > 0 {
+			if float64(limit.MilliValue())/float64(request.MilliValue()) > float64(ratio.MilliValue())/1000 {
+				reason = fmt.Sprintf("limit %s is more than %s times the request %s", limit.String(), ratio.String(), request.String())
+			}
+		}
+		if len(reason) > 0 {
+			violations = append(violations, LimitRangeViolation{Resource: name, Reason: reason})
+		}
+	}
+	return violations
+}
+
+// addQuantity adds q to the named resource of list.
+func addQuantity(list kapi.ResourceList, name kapi.ResourceName, q resource.Quantity) {
+	sum, ok := list[name]
+	if !ok {
+		list[name] = *q.Copy()
+		return
+	}
+	sum.Add(q)
+	list[name] = sum
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestQuotaViolations(t *testing.T) {
+	objects := []runtime.Object{
+		&deployapi.DeploymentConfig{
+			Spec: deployapi.DeploymentConfigSpec{
+				Replicas: 2,
+				Template: &kapi.PodTemplateSpec{
+					Spec: kapi.PodSpec{
+						Containers: []kapi.Container{
+							{Resources: kapi.ResourceRequirements{Requests: kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("500m")}}},
+							{Resources: kapi.ResourceRequirements{Limits: kapi.ResourceList{kapi.ResourceMemory: resource.MustParse("64Mi")}}},
+						},
+					},
+				},
+			},
+		},
+		&kapi.Service{},
+	}
+	requested := requestedResources(objects)
+	for name, expected := range map[kapi.ResourceName]string{
+		kapi.ResourceCPU:                    "1",
+		kapi.ResourceMemory:                 "128Mi",
+		kapi.ResourcePods:                   "2",
+		kapi.ResourceReplicationControllers: "1",
+		kapi.ResourceServices:               "1",
+	} {
+		q := requested[name]
+		if q.Cmp(resource.MustParse(expected)) != 0 {
+			t.Errorf("%s: expected %s, got %s", name, expected, q.String())
+		}
+	}
+
+	quotas := []kapi.ResourceQuota{
+		{
+			ObjectMeta: kapi.ObjectMeta{Name: "compute"},
+			Status: kapi.ResourceQuotaStatus{
+				Hard: kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("1"), kapi.ResourceMemory: resource.MustParse("1Gi")},
+				Used: kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("200m")},
+			},
+		},
+		{
+			ObjectMeta: kapi.ObjectMeta{Name: "objects"},
+			Spec:       kapi.ResourceQuotaSpec{Hard: kapi.ResourceList{kapi.ResourceServices: resource.MustParse("5")}},
+		},
+	}
+	violations := quotaViolations(quotas, requested)
+	if len(violations) != 1 {
+		t.Fatalf("unexpected violations: %v", violations)
+	}
+	v := violations[0]
+	if v.Quota != "compute" || v.Resource != kapi.ResourceCPU || v.Available.Cmp(resource.MustParse("800m")) != 0 {
+		t.Errorf("unexpected violation: %s", v)
+	}
+}
+
+func TestLimitRangeViolations(t *testing.T) {
+	limitRanges := []kapi.LimitRange{
+		{
+			ObjectMeta: kapi.ObjectMeta{Name: "limits"},
+			Spec: kapi.LimitRangeSpec{
+				Limits: []kapi.LimitRangeItem{
+					{
+						Type:                 kapi.LimitTypeContainer,
+						Min:                  kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("100m")},
+						Max:                  kapi.ResourceList{kapi.ResourceMemory: resource.MustParse("1Gi")},
+						MaxLimitRequestRatio: kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("2")},
+						DefaultRequest:       kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("200m")},
+					},
+					{
+						Type: kapi.LimitTypePod,
+						Max:  kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("1m")},
+					},
+				},
+			},
+		},
+	}
+	container := func(name string, requests, limits kapi.ResourceList) kapi.Container {
+		return kapi.Container{Name: name, Resources: kapi.ResourceRequirements{Requests: requests, Limits: limits}}
+	}
+	objects := []runtime.Object{
+		&deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: "app"},
+			Spec: deployapi.DeploymentConfigSpec{
+				Template: &kapi.PodTemplateSpec{
+					Spec: kapi.PodSpec{
+						Containers: []kapi.Container{
+							container("ok", nil, kapi.ResourceList{kapi.ResourceMemory: resource.MustParse("512Mi")}),
+							container("nolimit", nil, nil),
+							container("small", kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("50m")}, kapi.ResourceList{kapi.ResourceMemory: resource.MustParse("2Gi")}),
+							container("burst", nil, kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("1"), kapi.ResourceMemory: resource.MustParse("1Gi")}),
+						},
+					},
+				},
+			},
+		},
+		&kapi.Service{},
+	}
+	violations := limitRangeViolations(limitRanges, objects)
+	expected := []string{
+		`container "nolimit" in deploymentconfig "app": memory has no limit, but a limit of at most 1Gi is required (limit range "limits")`,
+		`container "small" in deploymentconfig "app": cpu request 50m is below the minimum 100m (limit range "limits")`,
+		`container "small" in deploymentconfig "app": memory limit 2Gi exceeds the maximum 1Gi (limit range "limits")`,
+		`container "burst" in deploymentconfig "app": cpu limit 1 is more than 2 times the request 200m (limit range "limits")`,
+	}
+	if len(violations) != len(expected) {
+		t.Fatalf("unexpected violations: %v", violations)
+	}
+	for i, v := range violations {
+		if v.String() != expected[i] {
+			t.Errorf("%d: expected\n%s\ngot\n%s", i, expected[i], v.String())
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/openshift/origin/pkg/api/latest"
+)
+
+const (
+	// AppReportKind is the kind of a serialized AppReport.
+	AppReportKind = "AppReport"
+	// AppReportAPIVersion is the version of the serialization of AppReport. It only changes when a field is
+	// removed or changes meaning, fields may be added within a version. The JSON schema of each version is in
+	// api/schemas/appreport-<version>.json.
+	AppReportAPIVersion = "v1"
+)
+
+// AppReportWarningType identifies the check that produced a warning of a report.
+type AppReportWarningType string
+
+const (
+	AppReportWarningImageUser          AppReportWarningType = "ImageUser"
+	AppReportWarningBuilder            AppReportWarningType = "Builder"
+	AppReportWarningNonBuilder         AppReportWarningType = "NonBuilder"
+	AppReportWarningDuplicateInput     AppReportWarningType = "DuplicateInput"
+	AppReportWarningRuntimeEOL         AppReportWarningType = "RuntimeEOL"
+	AppReportWarningDockerfileLint     AppReportWarningType = "DockerfileLint"
+	AppReportWarningCompose            AppReportWarningType = "Compose"
+	AppReportWarningMixedOS            AppReportWarningType = "MixedOS"
+	AppReportWarningSourceSecret       AppReportWarningType = "SourceSecret"
+	AppReportWarningQuota              AppReportWarningType = "Quota"
+	AppReportWarningLimitRange         AppReportWarningType = "LimitRange"
+	AppReportWarningNamespaceNotFound  AppReportWarningType = "NamespaceNotFound"
+	AppReportWarningPreflightCollision AppReportWarningType = "Collision"
+)
+
+// AppReport is the machine readable form of an AppResult, for tools that consume the results of a generation
+// without importing its Go types. Its JSON serialization is stable within AppReportAPIVersion.
+type AppReport struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+
+	// Name is the name of the generated application.
+	Name string `json:"name,omitempty"`
+	// Namespace is the namespace the objects are created in.
+	Namespace string `json:"namespace,omitempty"`
+	// Cluster is the name of the cluster profile the objects were generated for, if any.
+	Cluster string `json:"cluster,omitempty"`
+	// HasSource is true if the application builds source.
+	HasSource bool `json:"hasSource"`
+
+	// Components are the arguments the objects were generated from and what they resolved to.
+	Components []AppReportComponent `json:"components"`
+	// Objects are the generated objects.
+	Objects []AppReportObject `json:"objects"`
+	// Warnings are the problems found while generating the objects.
+	Warnings []AppReportWarning `json:"warnings"`
+	// Decisions describe the choices made while generating the objects.
+	Decisions []string `json:"decisions"`
+	// PreflightPassed is set if the objects were checked against the namespace instead of being returned,
+	// and is true if no check found a problem that would prevent them from being created.
+	PreflightPassed *bool `json:"preflightPassed,omitempty"`
+}
+
+// AppReportComponent is an argument of a generation and the match it resolved to.
+type AppReportComponent struct {
+	// Value is the argument as it was given.
+	Value string `json:"value"`
+	// Argument is the flag that selects the kind of the component, if any.
+	Argument string `json:"argument,omitempty"`
+	// Match is the name of the image, image stream or template the component resolved to, and Source the kind
+	// of object it is: imagestream, template or docker.
+	Match  string `json:"match,omitempty"`
+	Source string `json:"source,omitempty"`
+	// Builder is true if the component builds source, and Repository is the source repository it builds.
+	Builder    bool   `json:"builder"`
+	Repository string `json:"repository,omitempty"`
+}
+
+// AppReportObject is a generated object.
+type AppReportObject struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Object is the serialization of the object in the v1 API.
+	Object json.RawMessage `json:"object"`
+}
+
+// AppReportWarning is a problem found while generating the objects of a report.
+type AppReportWarning struct {
+	Type    AppReportWarningType `json:"type"`
+	Message string               `json:"message"`
+}
+
+// NewAppReport returns the report of result.
+func NewAppReport(result *AppResult) (*AppReport, error) {
+	report := &AppReport{
+		Kind:       AppReportKind,
+		APIVersion: AppReportAPIVersion,
+		Name:       result.Name,
+		Namespace:  result.Namespace,
+		Cluster:    result.Cluster,
+		HasSource:  result.HasSource,
+		Components: []AppReportComponent{},
+		Objects:    []AppReportObject{},
+		Warnings:   appReportWarnings(result),
+		Decisions:  describeDecisions(result),
+	}
+
+	for _, ref := range result.Components {
+		input := ref.Input()
+		component := AppReportComponent{
+			Value:    input.Value,
+			Argument: input.Argument,
+			Builder:  input.ExpectToBuild,
+		}
+		if match := input.ResolvedMatch; match != nil {
+			component.Match = match.Name
+			component.Source = string(match.Source())
+		}
+		if input.Uses != nil {
+			component.Repository = input.Uses.String()
+		}
+		report.Components = append(report.Components, component)
+	}
+
+	codec := kapi.Codecs.LegacyCodec(latest.Version)
+	for _, obj := range result.List.Items {
+		kind, err := kapi.Scheme.ObjectKind(obj)
+		if err != nil {
+			return nil, err
+		}
+		meta, err := kapi.ObjectMetaFor(obj)
+		if err != nil {
+			return nil, err
+		}
+		data, err := runtime.Encode(codec, obj)
+		if err != nil {
+			return nil, fmt.Errorf("unable to serialize %s %q: %v", kind.Kind, meta.Name, err)
+		}
+		report.Objects = append(report.Objects, AppReportObject{Kind: kind.Kind, Name: meta.Name, Object: json.RawMessage(data)})
+	}
+
+	if preflight := result.Preflight; preflight != nil {
+		passed := preflight.Passed()
+		report.PreflightPassed = &passed
+	}
+	return report, nil
+}
+
+// appReportWarnings returns the warnings of result, grouped by type.
+func appReportWarnings(result *AppResult) []AppReportWarning {
+	warnings := []AppReportWarning{}
+	add := func(t AppReportWarningType, warning fmt.Stringer) {
+		warnings = append(warnings, AppReportWarning{Type: t, Message: warning.String()})
+	}
+	for _, w := range result.Warnings {
+		add(AppReportWarningImageUser, w)
+	}
+	for _, w := range result.BuilderWarnings {
+		add(AppReportWarningBuilder, w)
+	}
+	for _, w := range result.NonBuilderWarnings {
+		add(AppReportWarningNonBuilder, w)
+	}
+	for _, w := range result.DuplicateInputs {
+		add(AppReportWarningDuplicateInput, w)
+	}
+	for _, w := range result.EOLWarnings {
+		add(AppReportWarningRuntimeEOL, w)
+	}
+	for _, w := range result.DockerfileLintFindings {
+		add(AppReportWarningDockerfileLint, w)
+	}
+	for _, w := range result.ComposeWarnings {
+		warnings = append(warnings, AppReportWarning{Type: AppReportWarningCompose, Message: w})
+	}
+	for _, w := range result.OSWarnings {
+		add(AppReportWarningMixedOS, w)
+	}
+	for _, w := range result.SourceSecretWarnings {
+		add(AppReportWarningSourceSecret, w)
+	}
+	for _, w := range result.QuotaWarnings {
+		add(AppReportWarningQuota, w)
+	}
+	for _, w := range result.LimitRangeWarnings {
+		add(AppReportWarningLimitRange, w)
+	}
+	if preflight := result.Preflight; preflight != nil {
+		if !preflight.NamespaceExists {
+			warnings = append(warnings, AppReportWarning{Type: AppReportWarningNamespaceNotFound, Message: fmt.Sprintf("namespace %q does not exist or is not visible", preflight.Namespace)})
+		}
+		for _, w := range preflight.Collisions {
+			add(AppReportWarningPreflightCollision, w)
+		}
+		for _, w := range preflight.SecurityWarnings {
+			add(AppReportWarningImageUser, w)
+		}
+		for _, w := range preflight.QuotaViolations {
+			add(AppReportWarningQuota, w)
+		}
+		for _, w := range preflight.LimitRangeViolations {
+			add(AppReportWarningLimitRange, w)
+		}
+	}
+	return warnings
+}
+
+// WriteAppReport writes the report of result to out as indented JSON.
+func WriteAppReport(out io.Writer, result *AppResult) error {
+	report, err := NewAppReport(result)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
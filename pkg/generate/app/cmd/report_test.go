@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	"github.com/openshift/origin/pkg/generate/app"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestWriteAppReport(t *testing.T) {
+	result := &AppResult{
+		Name:      "web",
+		Namespace: "test",
+		HasSource: true,
+		Components: app.ComponentReferences{
+			&app.ComponentInput{
+				Value:         "nodejs",
+				ExpectToBuild: true,
+				ResolvedMatch: &app.ComponentMatch{Name: "nodejs", ImageStream: &imageapi.ImageStream{}},
+			},
+		},
+		List: &kapi.List{Items: []runtime.Object{
+			&buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "web"}},
+			&kapi.Service{
+				ObjectMeta: kapi.ObjectMeta{Name: "web"},
+				Spec:       kapi.ServiceSpec{Ports: []kapi.ServicePort{{Port: 8080, Protocol: kapi.ProtocolTCP}}},
+			},
+		}},
+		BuilderWarnings: []app.BuilderWarning{{Builder: "nodejs", Message: "is old"}},
+		ComposeWarnings: []string{"links are ignored"},
+	}
+	out := &bytes.Buffer{}
+	if err := WriteAppReport(out, result); err != nil {
+		t.Fatal(err)
+	}
+
+	report := struct {
+		AppReport
+		Objects []struct {
+			Kind   string                 `json:"kind"`
+			Name   string                 `json:"name"`
+			Object map[string]interface{} `json:"object"`
+		} `json:"objects"`
+	}{}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, out.String())
+	}
+	if report.Kind != AppReportKind || report.APIVersion != AppReportAPIVersion || report.Name != "web" || !report.HasSource {
+		t.Errorf("unexpected report: %s", out.String())
+	}
+	expectedComponents := []AppReportComponent{{Value: "nodejs", Match: "nodejs", Source: "imagestream", Builder: true}}
+	if !reflect.DeepEqual(report.Components, expectedComponents) {
+		t.Errorf("unexpected components: %#v", report.Components)
+	}
+	if len(report.Objects) != 2 {
+		t.Fatalf("unexpected objects: %s", out.String())
+	}
+	for i, kind := range []string{"BuildConfig", "Service"} {
+		obj := report.Objects[i]
+		if obj.Kind != kind || obj.Name != "web" || obj.Object["kind"] != kind || obj.Object["apiVersion"] != "v1" {
+			t.Errorf("unexpected object %d: %#v", i, obj)
+		}
+	}
+	expectedWarnings := []AppReportWarning{
+		{Type: AppReportWarningBuilder, Message: `builder "nodejs": is old`},
+		{Type: AppReportWarningCompose, Message: "links are ignored"},
+	}
+	if !reflect.DeepEqual(report.Warnings, expectedWarnings) {
+		t.Errorf("unexpected warnings: %#v", report.Warnings)
+	}
+	if len(report.Decisions) != 2 || report.PreflightPassed != nil {
+		t.Errorf("unexpected decisions: %v", report.Decisions)
+	}
+}
+
+func TestAppReportPreflight(t *testing.T) {
+	result := &AppResult{
+		List: &kapi.List{},
+		Preflight: &PreflightReport{
+			Namespace:       "test",
+			NamespaceExists: true,
+			Collisions:      []PreflightCollision{{Kind: "Service", Name: "web"}},
+		},
+	}
+	report, err := NewAppReport(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.PreflightPassed == nil || *report.PreflightPassed {
+		t.Errorf("unexpected preflight: %v", report.PreflightPassed)
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Type != AppReportWarningPreflightCollision {
+		t.Errorf("unexpected warnings: %#v", report.Warnings)
+	}
+}
+
+// TestAppReportSchema verifies that the published schema of the report declares exactly the fields of the
+// report types.
+func TestAppReportSchema(t *testing.T) {
+	data, err := ioutil.ReadFile("../../../../api/schemas/appreport-" + AppReportAPIVersion + ".json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	type schema struct {
+		Required    []string                   `json:"required"`
+		Properties  map[string]json.RawMessage `json:"properties"`
+		Definitions map[string]schema          `json:"definitions"`
+	}
+	root := schema{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		schema schema
+		value  interface{}
+	}{
+		"report":    {root, AppReport{}},
+		"component": {root.Definitions["component"], AppReportComponent{}},
+		"object":    {root.Definitions["object"], AppReportObject{}},
+		"warning":   {root.Definitions["warning"], AppReportWarning{}},
+	}
+	for name, test := range tests {
+		fields, required := []string{}, []string{}
+		typ := reflect.TypeOf(test.value)
+		for i := 0; i < typ.NumField(); i++ {
+			tag := strings.Split(typ.Field(i).Tag.Get("json"), ",")
+			fields = append(fields, tag[0])
+			if len(tag) == 1 && typ.Field(i).Type.Kind() != reflect.Ptr {
+				required = append(required, tag[0])
+			}
+		}
+		properties := []string{}
+		for property := range test.schema.Properties {
+			properties = append(properties, property)
+		}
+		schemaRequired := append([]string{}, test.schema.Required...)
+		sort.Strings(fields)
+		sort.Strings(required)
+		sort.Strings(properties)
+		sort.Strings(schemaRequired)
+		if !reflect.DeepEqual(fields, properties) {
+			t.Errorf("%s: the schema declares %v rather than %v", name, properties, fields)
+		}
+		if !reflect.DeepEqual(required, schemaRequired) {
+			t.Errorf("%s: the schema requires %v rather than %v", name, schemaRequired, required)
+		}
+	}
+}
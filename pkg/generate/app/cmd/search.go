@@ -0,0 +1,362 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/generate/app"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+const (
+	// DefaultSearchLimit is the number of results returned by Search when no limit is requested.
+	DefaultSearchLimit = 25
+	// DefaultSearchDetailsTimeout bounds each request made for the details of a search result when no timeout
+	// is requested.
+	DefaultSearchDetailsTimeout = 10 * time.Second
+)
+
+// Annotations that describe image streams, their tags and templates in a catalog.
+const (
+	displayNameAnnotation = "openshift.io/display-name"
+	descriptionAnnotation = "description"
+	iconClassAnnotation   = "iconClass"
+	tagsAnnotation        = "tags"
+	versionAnnotation     = "version"
+)
+
+// SearchResultKind identifies the kind of a search result.
+type SearchResultKind string
+
+const (
+	SearchResultImage       SearchResultKind = "Image"
+	SearchResultImageStream SearchResultKind = "ImageStream"
+	SearchResultTemplate    SearchResultKind = "Template"
+)
+
+// SearchOptions describes a page of search results.
+type SearchOptions struct {
+	// Terms are the values to search for, as accepted by new-app.
+	Terms []string
+	// Offset is the number of results to skip.
+	Offset int
+	// Limit is the maximum number of results to return. Defaults to DefaultSearchLimit.
+	Limit int
+	// IncludeDetails sets the details of the returned results, including the tags of image and image stream
+	// matches.
+	IncludeDetails bool
+	// DetailsTimeout bounds each request made for details, such as listing the tags of a Docker image in its
+	// registry. Defaults to DefaultSearchDetailsTimeout.
+	DetailsTimeout time.Duration
+}
+
+// SearchResult is a single match returned by Search.
+type SearchResult struct {
+	Kind SearchResultKind
+	// Term is the search term this result matched.
+	Term string
+	// Name is the exact name of the match.
+	Name string
+	// Namespace is set for image streams and templates.
+	Namespace string
+	// Argument can be passed to new-app to select this match explicitly.
+	Argument    string
+	Description string
+	// Score is 0.0 for an exact match and increases as the match gets worse.
+	Score float32
+	// Details are only set if they were requested.
+	Details *SearchResultDetails
+	// Source describes the searcher the match was found with, such as whether it searched with credentials.
+	Source app.SearcherCapabilities
+
+	Match *app.ComponentMatch
+}
+
+// SearchResultDetails describe a search result in a catalog.
+type SearchResultDetails struct {
+	DisplayName string
+	Description string
+	IconClass   string
+	// Keywords are the tags annotation of templates and image streams, such as builder or database.
+	Keywords []string
+	// Tags are the tags of image stream and image matches.
+	Tags []SearchResultTag
+	// Error is set if the details could not all be retrieved.
+	Error string
+}
+
+// SearchResultTag is a tag of an image stream or image match.
+type SearchResultTag struct {
+	Name string
+	// DisplayName, Description, Version and Keywords are only set for image stream tags.
+	DisplayName string
+	Description string
+	Version     string
+	Keywords    []string
+}
+
+// SearchResults is a page of search results, ordered from the best to the worst match.
+type SearchResults struct {
+	Items []SearchResult
+	// Offset is the offset of the first item.
+	Offset int
+	// Total is the number of results across all pages.
+	Total int
+	// Warnings describe the searches that may have missed matches, such as searches of registries made without
+	// credentials.
+	Warnings []string
+}
+
+// Search searches the images, image streams and templates available to this config for options.Terms and
+// returns the requested page of results.
+func (c *AppConfig) Search(options SearchOptions) (*SearchResults, error) {
+	if len(options.Terms) == 0 {
+		return nil, ErrNoInputs
+	}
+	if options.Offset < 0 || options.Limit < 0 {
+		return nil, generrors.New(generrors.CodeInvalidArgument, "offset and limit must not be negative")
+	}
+	limit := options.Limit
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+
+	c.ensureDockerSearch()
+	if err := c.ensurePlatform(); err != nil {
+		return nil, err
+	}
+	c.ensureMetrics()
+	c.ensureSearchCache()
+	query := *c
+	query.Components = options.Terms
+	query.ImageStreams, query.DockerImages, query.Templates, query.TemplateFiles = nil, nil, nil, nil
+	query.RefBuilder = &app.ReferenceBuilder{}
+	query.addReferenceBuilderComponents(query.RefBuilder)
+	components, _, errs := query.RefBuilder.Result()
+	if len(errs) > 0 {
+		return nil, errors.NewAggregate(errs)
+	}
+	if err := Search(components); err != nil {
+		return nil, err
+	}
+
+	results := []SearchResult{}
+	for _, ref := range components {
+		input := ref.Input()
+		source := app.SearcherCapabilities{}
+		if input.Searcher != nil {
+			source = input.Searcher.Capabilities()
+		}
+		for _, match := range input.SearchMatches {
+			result := newSearchResult(match)
+			result.Source = source
+			results = append(results, result)
+		}
+	}
+	sort.Stable(searchResultsByScore(results))
+
+	page := &SearchResults{Offset: options.Offset, Total: len(results), Warnings: c.unauthenticatedSearchWarnings()}
+	if options.Offset < len(results) {
+		end := options.Offset + limit
+		if end > len(results) {
+			end = len(results)
+		}
+		page.Items = results[options.Offset:end]
+	}
+	if options.IncludeDetails {
+		c.addSearchDetails(page.Items, options.DetailsTimeout)
+	}
+	return page, nil
+}
+
+// addSearchDetails sets the details of results. The tags of image matches are listed concurrently, and each
+// listing that does not complete within timeout is reported as an error on its result.
+func (c *AppConfig) addSearchDetails(results []SearchResult, timeout time.Duration) {
+	if timeout == 0 {
+		timeout = DefaultSearchDetailsTimeout
+	}
+	lister := c.DockerTagLister
+	if lister == nil {
+		lister = c.DockerImageTagLister()
+	}
+	wg := sync.WaitGroup{}
+	for i := range results {
+		result := &results[i]
+		match := result.Match
+		switch result.Kind {
+		case SearchResultTemplate:
+			result.Details = annotatedDetails(match.Template.Annotations)
+		case SearchResultImageStream:
+			result.Details = annotatedDetails(match.ImageStream.Annotations)
+			result.Details.Tags = imageStreamTags(match.ImageStream)
+		default:
+			result.Details = &SearchResultDetails{Description: result.Description}
+			// the tags of images found by searchers that do not support tags, such as missing images, are not
+			// looked up
+			ref, err := imageapi.ParseDockerImageReference(match.Name)
+			if err != nil || match.LocalOnly || !result.Source.SupportsTags {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tags, err := listTags(lister, ref, timeout)
+				if err != nil {
+					result.Details.Error = err.Error()
+					return
+				}
+				for _, tag := range tags {
+					result.Details.Tags = append(result.Details.Tags, SearchResultTag{Name: tag})
+				}
+			}()
+		}
+	}
+	wg.Wait()
+}
+
+// unauthenticatedSearchWarnings warns about the searchers that search the network without credentials, and so
+// cannot find private images.
+func (c *AppConfig) unauthenticatedSearchWarnings() []string {
+	var warnings []string
+	if c.DockerSearcher != nil {
+		if capabilities := c.DockerSearcher.Capabilities(); capabilities.RequiresNetwork && !capabilities.Authenticated {
+			warnings = append(warnings, "Docker registries were searched without credentials, images in private repositories were not found")
+		}
+	}
+	return warnings
+}
+
+// listTags lists the tags of ref, or returns an error if that takes longer than timeout.
+func listTags(lister app.TagLister, ref imageapi.DockerImageReference, timeout time.Duration) ([]string, error) {
+	type listed struct {
+		tags []string
+		err  error
+	}
+	ch := make(chan listed, 1)
+	go func() {
+		tags, err := lister.ImageTags(ref)
+		ch <- listed{tags, err}
+	}()
+	select {
+	case result := <-ch:
+		return result.tags, result.err
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("timed out listing the tags of %s", ref.Exact())
+	}
+}
+
+// annotatedDetails returns the details recorded in the annotations of a template or image stream.
+func annotatedDetails(annotations map[string]string) *SearchResultDetails {
+	return &SearchResultDetails{
+		DisplayName: annotations[displayNameAnnotation],
+		Description: annotations[descriptionAnnotation],
+		IconClass:   annotations[iconClassAnnotation],
+		Keywords:    keywords(annotations[tagsAnnotation]),
+	}
+}
+
+// imageStreamTags returns the tags of the spec and status of stream, sorted by name.
+func imageStreamTags(stream *imageapi.ImageStream) []SearchResultTag {
+	names := sets.NewString()
+	for name := range stream.Spec.Tags {
+		names.Insert(name)
+	}
+	for name := range stream.Status.Tags {
+		names.Insert(name)
+	}
+	tags := []SearchResultTag{}
+	for _, name := range names.List() {
+		tag := SearchResultTag{Name: name}
+		if ref, ok := stream.Spec.Tags[name]; ok {
+			tag.DisplayName = ref.Annotations[displayNameAnnotation]
+			tag.Description = ref.Annotations[descriptionAnnotation]
+			tag.Version = ref.Annotations[versionAnnotation]
+			tag.Keywords = keywords(ref.Annotations[tagsAnnotation])
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// keywords splits a comma separated tags annotation.
+func keywords(value string) []string {
+	result := []string{}
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); len(s) > 0 {
+			result = append(result, s)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// WhatProvides returns the builder image streams available to this config that support terms, such as ruby or
+// nodejs:6, best matches first.
+func (c *AppConfig) WhatProvides(terms ...string) ([]app.BuilderProvider, error) {
+	if len(terms) == 0 {
+		return nil, ErrNoInputs
+	}
+	if c.ImageStreamByAnnotationSearcher == nil {
+		return nil, fmt.Errorf("image streams cannot be searched without a connection to the server")
+	}
+	return app.WhatProvides(c.ImageStreamByAnnotationSearcher, terms...)
+}
+
+// searchMatches returns the matches found for components and the objects backing them.
+func searchMatches(components app.ComponentReferences) (app.ComponentMatches, app.Objects) {
+	matches := app.ComponentMatches{}
+	objects := app.Objects{}
+	for _, ref := range components {
+		for _, match := range ref.Input().SearchMatches {
+			matches = append(matches, match)
+			if match.IsTemplate() {
+				objects = append(objects, match.Template)
+			} else if match.IsImage() {
+				if match.ImageStream != nil {
+					objects = append(objects, match.ImageStream)
+				}
+				if match.Image != nil {
+					objects = append(objects, match.Image)
+				}
+			}
+		}
+	}
+	return matches, objects
+}
+
+func newSearchResult(match *app.ComponentMatch) SearchResult {
+	result := SearchResult{
+		Term:        match.Value,
+		Name:        match.Name,
+		Argument:    match.Argument,
+		Description: match.Description,
+		Score:       match.Score,
+		Match:       match,
+	}
+	switch {
+	case match.Template != nil:
+		result.Kind = SearchResultTemplate
+		result.Namespace = match.Template.Namespace
+	case match.ImageStream != nil:
+		result.Kind = SearchResultImageStream
+		result.Namespace = match.ImageStream.Namespace
+	default:
+		result.Kind = SearchResultImage
+	}
+	return result
+}
+
+type searchResultsByScore []SearchResult
+
+func (r searchResultsByScore) Len() int           { return len(r) }
+func (r searchResultsByScore) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r searchResultsByScore) Less(i, j int) bool { return r[i].Score < r[j].Score }
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	"github.com/openshift/origin/pkg/generate/app"
+	"github.com/openshift/origin/pkg/generate/app/testsearcher"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestSearchPages(t *testing.T) {
+	config := &AppConfig{
+		DockerSearcher: &testsearcher.ExactMatchDockerSearcher{},
+		RefBuilder:     &app.ReferenceBuilder{},
+	}
+	config.TemplateSearcher = fakeTemplateSearcher()
+
+	results, err := config.Search(SearchOptions{Terms: []string{"ruby", "mysql", "first-stored-template"}, Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results.Total != 4 || len(results.Items) != 2 {
+		t.Fatalf("unexpected results: %#v", results)
+	}
+	if results.Items[0].Kind != SearchResultTemplate || results.Items[0].Score > results.Items[1].Score {
+		t.Errorf("expected the best matches first: %#v", results.Items)
+	}
+
+	results, err = config.Search(SearchOptions{Terms: []string{"first-stored-template"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	kinds := map[SearchResultKind]int{}
+	for _, item := range results.Items {
+		kinds[item.Kind]++
+	}
+	if kinds[SearchResultTemplate] != 1 || kinds[SearchResultImage] != 1 {
+		t.Errorf("unexpected result kinds: %#v", kinds)
+	}
+	if results.Items[0].Namespace != "default" && results.Items[1].Namespace != "default" {
+		t.Errorf("expected the template namespace to be set: %#v", results.Items)
+	}
+
+	results, err = config.Search(SearchOptions{Terms: []string{"ruby"}, Offset: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if results.Total != 1 || len(results.Items) != 0 {
+		t.Errorf("unexpected results past the last page: %#v", results)
+	}
+
+	if _, err := config.Search(SearchOptions{}); err != ErrNoInputs {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWhatProvidesRequiresSearcher(t *testing.T) {
+	config := &AppConfig{}
+	if _, err := config.WhatProvides(); err != ErrNoInputs {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := config.WhatProvides("ruby"); err == nil {
+		t.Errorf("expected an error without an image stream searcher")
+	}
+	config.ImageStreamByAnnotationSearcher = fakeImageStreamSearcher()
+	if _, err := config.WhatProvides("ruby"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+type fakeTagLister struct{}
+
+func (fakeTagLister) ImageTags(ref imageapi.DockerImageReference) ([]string, error) {
+	switch ref.Name {
+	case "slow":
+		time.Sleep(time.Second)
+	case "missing":
+		return nil, fmt.Errorf("not found")
+	}
+	return []string{"2.2", "latest"}, nil
+}
+
+type annotatedImageStreamSearcher struct{}
+
+func (annotatedImageStreamSearcher) Search(precise bool, terms ...string) (app.ComponentMatches, []error) {
+	stream := &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:        "nodejs",
+			Namespace:   "openshift",
+			Annotations: map[string]string{"openshift.io/display-name": "Node.js", "iconClass": "icon-nodejs"},
+		},
+		Spec: imageapi.ImageStreamSpec{
+			Tags: map[string]imageapi.TagReference{
+				"4": {Annotations: map[string]string{"description": "Node.js 4", "tags": "builder, nodejs", "version": "4"}},
+			},
+		},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{"0.10": {}},
+		},
+	}
+	matches := app.ComponentMatches{}
+	for _, term := range terms {
+		if term == "nodejs" {
+			matches = append(matches, &app.ComponentMatch{Value: term, Name: "nodejs", ImageStream: stream, ImageTag: "4"})
+		}
+	}
+	return matches, nil
+}
+
+func (annotatedImageStreamSearcher) Capabilities() app.SearcherCapabilities {
+	return app.SearcherCapabilities{}
+}
+
+func TestSearchDetails(t *testing.T) {
+	config := &AppConfig{
+		DockerSearcher:      &testsearcher.ExactMatchDockerSearcher{},
+		ImageStreamSearcher: annotatedImageStreamSearcher{},
+		DockerTagLister:     fakeTagLister{},
+		RefBuilder:          &app.ReferenceBuilder{},
+	}
+	results, err := config.Search(SearchOptions{Terms: []string{"ruby", "slow", "missing", "nodejs"}, IncludeDetails: true, DetailsTimeout: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	details := map[string]*SearchResultDetails{}
+	for _, item := range results.Items {
+		if item.Details == nil {
+			t.Fatalf("expected details for %s", item.Name)
+		}
+		details[string(item.Kind)+"/"+item.Name] = item.Details
+	}
+	if d := details["Image/ruby"]; !reflect.DeepEqual(d.Tags, []SearchResultTag{{Name: "2.2"}, {Name: "latest"}}) || len(d.Error) > 0 {
+		t.Errorf("unexpected image details: %#v", d)
+	}
+	if d := details["Image/slow"]; len(d.Tags) != 0 || !strings.Contains(d.Error, "timed out") {
+		t.Errorf("expected a timeout: %#v", d)
+	}
+	if d := details["Image/missing"]; d.Error != "not found" {
+		t.Errorf("expected an error: %#v", d)
+	}
+	d := details["ImageStream/nodejs"]
+	if d == nil || d.DisplayName != "Node.js" || d.IconClass != "icon-nodejs" {
+		t.Fatalf("unexpected image stream details: %#v", d)
+	}
+	expected := []SearchResultTag{{Name: "0.10"}, {Name: "4", Description: "Node.js 4", Version: "4", Keywords: []string{"builder", "nodejs"}}}
+	if !reflect.DeepEqual(d.Tags, expected) {
+		t.Errorf("unexpected image stream tags: %#v", d.Tags)
+	}
+}
+
+func TestSearchSources(t *testing.T) {
+	searcher := &testsearcher.Searcher{
+		Matches:  map[string]app.ComponentMatches{"*": {{Name: "ruby", Argument: `--docker-image="ruby"`}}},
+		Declared: app.SearcherCapabilities{SupportsMetadata: true, RequiresNetwork: true},
+	}
+	config := &AppConfig{
+		DockerSearcher:  searcher,
+		DockerTagLister: fakeTagLister{},
+		RefBuilder:      &app.ReferenceBuilder{},
+	}
+	results, err := config.Search(SearchOptions{Terms: []string{"ruby"}, IncludeDetails: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(results.Items) != 1 || results.Items[0].Source != searcher.Declared {
+		t.Fatalf("unexpected results: %#v", results.Items)
+	}
+	if d := results.Items[0].Details; d == nil || len(d.Tags) != 0 || len(d.Error) != 0 {
+		t.Errorf("expected the tags not to be listed: %#v", d)
+	}
+	if len(results.Warnings) != 1 || !strings.Contains(results.Warnings[0], "without credentials") {
+		t.Errorf("unexpected warnings: %v", results.Warnings)
+	}
+
+	searcher.Declared.Authenticated = true
+	if results, err = config.Search(SearchOptions{Terms: []string{"ruby"}}); err != nil || len(results.Warnings) != 0 {
+		t.Errorf("unexpected warnings: %v %v", results.Warnings, err)
+	}
+}
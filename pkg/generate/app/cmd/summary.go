@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	buildutil "github.com/openshift/origin/pkg/build/util"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+// DefaultSummaryIndent is the indent of the lines below each heading of a summary.
+const DefaultSummaryIndent = "    "
+
+// SummaryOptions controls how the summary of an AppResult is rendered.
+type SummaryOptions struct {
+	// Indent prefixes the lines below each heading.
+	Indent string
+	// CommandName is the name of the client command referenced by the suggested next steps, for instance "oc".
+	CommandName string
+	// Labels are the labels set on every generated object.
+	Labels map[string]string
+}
+
+// DescribeAppResult writes a summary of the objects in result, the decisions made while generating them, and
+// the next steps once they have been created.
+func DescribeAppResult(out io.Writer, result *AppResult, options SummaryOptions) {
+	DescribeCreating(out, result, options)
+	if decisions := describeDecisions(result); len(decisions) > 0 {
+		fmt.Fprintf(out, "--> Decisions\n")
+		for _, decision := range decisions {
+			fmt.Fprintf(out, "%s%s\n", options.Indent, decision)
+		}
+	}
+	fmt.Fprintf(out, "--> Next steps\n")
+	DescribeNextSteps(out, result, options)
+	if len(result.List.Items) > 0 && len(options.CommandName) > 0 {
+		fmt.Fprintf(out, "%sRun '%s status' to view your app.\n", options.Indent, options.CommandName)
+	}
+}
+
+// DescribeCreating writes the heading of a summary followed by the kind and name of each object in result.
+func DescribeCreating(out io.Writer, result *AppResult, options SummaryOptions) {
+	if len(options.Labels) > 0 {
+		fmt.Fprintf(out, "--> Creating resources with label %s ...\n", labels.SelectorFromSet(options.Labels).String())
+	} else {
+		fmt.Fprintf(out, "--> Creating resources ...\n")
+	}
+	for _, obj := range result.List.Items {
+		kind, err := kapi.Scheme.ObjectKind(obj)
+		if err != nil {
+			continue
+		}
+		meta, err := kapi.ObjectMetaFor(obj)
+		if err != nil {
+			continue
+		}
+		fmt.Fprintf(out, "%s%s %q\n", options.Indent, strings.ToLower(kind.Kind), meta.Name)
+	}
+}
+
+// DescribeNextSteps writes the steps that follow the creation of the objects in result: the builds that will be
+// scheduled, how to find the webhooks that trigger builds, and the routes the application is exposed on. The
+// suggested commands are only included if CommandName is set.
+func DescribeNextSteps(out io.Writer, result *AppResult, options SummaryOptions) {
+	indent := options.Indent
+	hasMissingRepo := false
+	for _, item := range result.List.Items {
+		switch t := item.(type) {
+		case *buildapi.BuildConfig:
+			triggered, webhooks := false, false
+			for _, trigger := range t.Spec.Triggers {
+				switch trigger.Type {
+				case buildapi.ImageChangeBuildTriggerType, buildapi.ConfigChangeBuildTriggerType:
+					triggered = true
+				case buildapi.GitHubWebHookBuildTriggerType, buildapi.GenericWebHookBuildTriggerType:
+					webhooks = true
+				}
+			}
+			command := options.CommandName
+			switch {
+			case len(command) == 0 && triggered:
+				fmt.Fprintf(out, "%sBuild scheduled for %q.\n", indent, t.Name)
+			case len(command) == 0:
+				fmt.Fprintf(out, "%sBuild config %q does not include any automatic triggers.\n", indent, t.Name)
+			case triggered:
+				fmt.Fprintf(out, "%sBuild scheduled for %q, use '%s logs -f bc/%s' to track its progress.\n", indent, t.Name, command, t.Name)
+			default:
+				fmt.Fprintf(out, "%sBuild config %q does not include any automatic triggers, use '%s start-build %s' to start a build.\n", indent, t.Name, command, t.Name)
+			}
+			// webhook URLs contain their secret, so they are not written out
+			if webhooks && len(command) > 0 {
+				fmt.Fprintf(out, "%sBuilds of %q may be triggered with webhooks, use '%s describe bc/%s' to see their URLs.\n", indent, t.Name, command, t.Name)
+			}
+		case *imageapi.ImageStream:
+			if len(t.Status.DockerImageRepository) == 0 {
+				if hasMissingRepo {
+					continue
+				}
+				hasMissingRepo = true
+				fmt.Fprintf(out, "%sWARNING: No Docker registry has been configured with the server. Automatic builds and deployments may not function.\n", indent)
+			}
+		case *routeapi.Route:
+			if len(t.Spec.Host) > 0 {
+				fmt.Fprintf(out, "%sAccess your application via route %q\n", indent, t.Spec.Host)
+			} else if len(options.CommandName) > 0 {
+				fmt.Fprintf(out, "%sRoute %q will be assigned a hostname, use '%s get route %s' to see it.\n", indent, t.Name, options.CommandName, t.Name)
+			}
+		}
+	}
+}
+
+// describeDecisions returns a sentence for each choice made while generating the build and deployment configs
+// in result.
+func describeDecisions(result *AppResult) []string {
+	decisions := []string{}
+	for _, item := range result.List.Items {
+		switch t := item.(type) {
+		case *buildapi.BuildConfig:
+			strategy := strings.ToLower(buildapi.StrategyType(t.Spec.Strategy))
+			if from := buildutil.GetImageStreamForStrategy(t.Spec.Strategy); from != nil && len(from.Name) > 0 {
+				decisions = append(decisions, fmt.Sprintf("Build config %q uses the %s strategy from %s %q", t.Name, strategy, strings.ToLower(from.Kind), from.Name))
+			} else {
+				decisions = append(decisions, fmt.Sprintf("Build config %q uses the %s strategy", t.Name, strategy))
+			}
+		case *deployapi.DeploymentConfig:
+			replicas := "replicas"
+			if t.Spec.Replicas == 1 {
+				replicas = "replica"
+			}
+			images := []string{}
+			for _, trigger := range t.Spec.Triggers {
+				if params := trigger.ImageChangeParams; params != nil {
+					images = append(images, params.From.Name)
+				}
+			}
+			if len(images) > 0 {
+				decisions = append(decisions, fmt.Sprintf("Deployment config %q runs %d %s of %s and redeploys when the image changes", t.Name, t.Spec.Replicas, replicas, strings.Join(images, ", ")))
+			} else {
+				decisions = append(decisions, fmt.Sprintf("Deployment config %q runs %d %s", t.Name, t.Spec.Replicas, replicas))
+			}
+		case *kapi.Service:
+			ports := []string{}
+			for _, port := range t.Spec.Ports {
+				ports = append(ports, fmt.Sprintf("%d/%s", port.Port, strings.ToLower(string(port.Protocol))))
+			}
+			decisions = append(decisions, fmt.Sprintf("Service %q exposes port(s) %s", t.Name, strings.Join(ports, ", ")))
+		}
+	}
+	return decisions
+}
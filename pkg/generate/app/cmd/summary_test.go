@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+func TestDescribeAppResult(t *testing.T) {
+	result := &AppResult{
+		List: &kapi.List{Items: []runtime.Object{
+			&buildapi.BuildConfig{
+				ObjectMeta: kapi.ObjectMeta{Name: "web"},
+				Spec: buildapi.BuildConfigSpec{
+					Triggers: []buildapi.BuildTriggerPolicy{
+						{Type: buildapi.GitHubWebHookBuildTriggerType, GitHubWebHook: &buildapi.WebHookTrigger{Secret: "secret"}},
+						{Type: buildapi.ConfigChangeBuildTriggerType},
+					},
+					BuildSpec: buildapi.BuildSpec{
+						Strategy: buildapi.BuildStrategy{SourceStrategy: &buildapi.SourceBuildStrategy{
+							From: kapi.ObjectReference{Kind: "ImageStreamTag", Name: "nodejs:4"},
+						}},
+					},
+				},
+			},
+			&deployapi.DeploymentConfig{
+				ObjectMeta: kapi.ObjectMeta{Name: "web"},
+				Spec:       deployapi.DeploymentConfigSpec{Replicas: 1},
+			},
+			&kapi.Service{
+				ObjectMeta: kapi.ObjectMeta{Name: "web"},
+				Spec:       kapi.ServiceSpec{Ports: []kapi.ServicePort{{Port: 8080, Protocol: kapi.ProtocolTCP}}},
+			},
+			&routeapi.Route{ObjectMeta: kapi.ObjectMeta{Name: "web"}, Spec: routeapi.RouteSpec{Host: "web.example.com"}},
+		}},
+	}
+	out := &bytes.Buffer{}
+	DescribeAppResult(out, result, SummaryOptions{
+		Indent:      DefaultSummaryIndent,
+		CommandName: "oc",
+		Labels:      map[string]string{"app": "web"},
+	})
+	expected := `--> Creating resources with label app=web ...
+    buildconfig "web"
+    deploymentconfig "web"
+    service "web"
+    route "web"
+--> Decisions
+    Build config "web" uses the source strategy from imagestreamtag "nodejs:4"
+    Deployment config "web" runs 1 replica
+    Service "web" exposes port(s) 8080/tcp
+--> Next steps
+    Build scheduled for "web", use 'oc logs -f bc/web' to track its progress.
+    Builds of "web" may be triggered with webhooks, use 'oc describe bc/web' to see their URLs.
+    Access your application via route "web.example.com"
+    Run 'oc status' to view your app.
+`
+	if out.String() != expected {
+		t.Errorf("unexpected summary:\n%s", out.String())
+	}
+}
@@ -0,0 +1,79 @@
+package app
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util"
+
+	"github.com/openshift/origin/pkg/api/latest"
+)
+
+// ObjectConverter converts objects between API versions, such as a runtime.Scheme.
+type ObjectConverter interface {
+	runtime.ObjectConvertor
+	runtime.ObjectCopier
+}
+
+// ConvertToVersions returns objects converted to the first of versions, after checking that each object is
+// converted to each of versions and back without losing fields. Objects that a version cannot represent are an
+// error, since a cluster that only serves the version would not create them as generated. Conversion sets
+// defaults, so objects are compared with their defaulted form in latest.Version, which represents every field.
+func ConvertToVersions(objects Objects, versions []unversioned.GroupVersion, converter ObjectConverter) (Objects, error) {
+	if len(versions) == 0 {
+		return objects, nil
+	}
+	converted := Objects{}
+	for _, obj := range objects {
+		_, defaulted, err := roundTrip(obj, latest.Version, converter)
+		if err != nil {
+			return nil, fmt.Errorf("%s cannot be converted: %v", describeObject(obj), err)
+		}
+		for i, version := range versions {
+			out, back, err := roundTrip(defaulted, version, converter)
+			if err != nil {
+				return nil, fmt.Errorf("%s cannot be represented in API version %s: %v", describeObject(obj), version, err)
+			}
+			if !kapi.Semantic.DeepEqual(defaulted, back) {
+				return nil, fmt.Errorf("%s cannot be represented in API version %s, fields are lost in conversion: %s", describeObject(obj), version, util.ObjectDiff(defaulted, back))
+			}
+			if i == 0 {
+				converted = append(converted, out)
+			}
+		}
+	}
+	return converted, nil
+}
+
+// roundTrip converts a copy of obj to version and back, and returns obj in version and back in its internal
+// version.
+func roundTrip(obj runtime.Object, version unversioned.GroupVersion, converter ObjectConverter) (runtime.Object, runtime.Object, error) {
+	in, err := converter.Copy(obj)
+	if err != nil {
+		return nil, nil, err
+	}
+	out, err := converter.ConvertToVersion(in, version.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	versioned, err := converter.Copy(out)
+	if err != nil {
+		return nil, nil, err
+	}
+	internal := unversioned.GroupVersion{Group: version.Group, Version: runtime.APIVersionInternal}
+	back, err := converter.ConvertToVersion(versioned, internal.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, back, nil
+}
+
+// describeObject returns the type and name of obj for use in messages.
+func describeObject(obj runtime.Object) string {
+	if _, meta, err := objectMetaData(obj); err == nil {
+		return fmt.Sprintf("the %T %q", obj, meta.Name)
+	}
+	return fmt.Sprintf("the %T", obj)
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	kapiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	buildv1 "github.com/openshift/origin/pkg/build/api/v1"
+	buildv1beta3 "github.com/openshift/origin/pkg/build/api/v1beta3"
+	routev1 "github.com/openshift/origin/pkg/route/api/v1"
+)
+
+func TestConvertToVersions(t *testing.T) {
+	versions := []unversioned.GroupVersion{{Version: "v1"}, {Version: "v1beta3"}}
+	objects := AddRoutes(nameTemplateObjects())
+	for _, version := range versions {
+		if _, err := ConvertToVersions(objects, []unversioned.GroupVersion{version}, kapi.Scheme); err != nil {
+			t.Errorf("%s: %v", version, err)
+		}
+	}
+
+	converted, err := ConvertToVersions(objects, versions, kapi.Scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(converted) != len(objects) {
+		t.Fatalf("unexpected objects: %#v", converted)
+	}
+	if bc, ok := converted[1].(*buildv1.BuildConfig); !ok || bc.Name != "ruby-ex" || bc.Spec.Output.To.Name != "ruby-ex:latest" {
+		t.Errorf("unexpected build config: %#v", converted[1])
+	}
+	if svc, ok := converted[3].(*kapiv1.Service); !ok || svc.Spec.Selector["deploymentconfig"] != "ruby-ex" {
+		t.Errorf("unexpected service: %#v", converted[3])
+	}
+	if route, ok := converted[4].(*routev1.Route); !ok || route.Spec.To.Name != "ruby-ex" {
+		t.Errorf("unexpected route: %#v", converted[4])
+	}
+
+	if converted, err = ConvertToVersions(objects[1:2], versions[1:], kapi.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := converted[0].(*buildv1beta3.BuildConfig); !ok {
+		t.Errorf("unexpected objects: %#v", converted)
+	}
+	if _, err := ConvertToVersions(objects, []unversioned.GroupVersion{{Version: "v1alpha0"}}, kapi.Scheme); err == nil {
+		t.Errorf("expected an unknown version to be rejected")
+	}
+}
@@ -23,7 +23,13 @@ type ComponentMatch struct {
 	Image       *imageapi.DockerImage
 	ImageStream *imageapi.ImageStream
 	ImageTag    string
-	Template    *templateapi.Template
+	// ImageID is set to the image digest when the match is a single image of the image stream
+	// rather than a tag, or when the match is an image imported from a registry.
+	ImageID  string
+	Template *templateapi.Template
+	// Bundle is set on the matches of bundle references, which are expanded into the components of the bundle
+	// before they are resolved.
+	Bundle *Bundle
 
 	// Input to generators extracted from the source
 	Builder        bool
@@ -2,6 +2,7 @@ package app
 
 import (
 	"fmt"
+	"net/url"
 	"sort"
 	"strings"
 
@@ -162,10 +163,27 @@ type GeneratorJobReference struct {
 // ReferenceBuilder is used for building all the necessary object references
 // for an application
 type ReferenceBuilder struct {
-	refs    ComponentReferences
-	repos   SourceRepositories
-	errs    []error
-	groupID int
+	refs       ComponentReferences
+	repos      SourceRepositories
+	errs       []error
+	duplicates []DuplicateInput
+	groupID    int
+}
+
+// DuplicateInput describes an input that was given more than once and that is only used once.
+type DuplicateInput struct {
+	// Kind is the kind of input, either a component or a source repository.
+	Kind string
+	// Input is the duplicate input, and Existing the input it duplicates.
+	Input    string
+	Existing string
+}
+
+func (d DuplicateInput) String() string {
+	if d.Input == d.Existing {
+		return fmt.Sprintf("the %s %q was given more than once and is only used once", d.Kind, d.Input)
+	}
+	return fmt.Sprintf("the %s %q is the same as %q and is only used once", d.Kind, d.Input, d.Existing)
 }
 
 // AddComponents turns all provided component inputs into component references
@@ -178,10 +196,18 @@ func (r *ReferenceBuilder) AddComponents(inputs []string, fn func(*ComponentInpu
 				r.errs = append(r.errs, err)
 				continue
 			}
+			if existing := findComponentInput(refs, input); existing != nil {
+				if existing.Argument != input.Argument {
+					r.errs = append(r.errs, fmt.Errorf("the component %q is given with conflicting options %q and %q", input.Value, existing.Argument, input.Argument))
+				} else {
+					r.duplicates = append(r.duplicates, DuplicateInput{Kind: "component", Input: input.Argument, Existing: existing.Argument})
+				}
+				continue
+			}
 			input.GroupID = r.groupID
 			ref := fn(input)
 			if len(repo) != 0 {
-				repository, ok := r.AddSourceRepository(repo)
+				repository, ok := r.sourceRepository(repo)
 				if !ok {
 					continue
 				}
@@ -226,22 +252,77 @@ func (r *ReferenceBuilder) AddGroups(inputs []string) {
 	}
 }
 
-// AddSourceRepository resolves the input to an actual source repository
-func (r *ReferenceBuilder) AddSourceRepository(input string) (*SourceRepository, bool) {
-	for _, existing := range r.repos {
-		if input == existing.location {
-			return existing, true
+// findComponentInput returns the input of refs for the same component and source as input, or nil.
+func findComponentInput(refs ComponentReferences, input *ComponentInput) *ComponentInput {
+	_, repo, _, _ := componentWithSource(input.Argument)
+	for _, ref := range refs {
+		existing := ref.Input()
+		if existing.Value != input.Value || existing.ExpectToBuild != input.ExpectToBuild {
+			continue
+		}
+		if _, existingRepo, _, _ := componentWithSource(existing.Argument); existingRepo == repo {
+			return existing
 		}
 	}
+	return nil
+}
+
+// AddSourceRepository resolves the input to an actual source repository. A repository that was already added,
+// possibly spelled differently, is reused and recorded as a duplicate. It is an error to add the same
+// repository with a different ref.
+func (r *ReferenceBuilder) AddSourceRepository(input string) (*SourceRepository, bool) {
+	existing := r.existingSourceRepository(input)
+	source, ok := r.sourceRepository(input)
+	if ok && existing != nil {
+		r.duplicates = append(r.duplicates, DuplicateInput{Kind: "source repository", Input: input, Existing: existing.location})
+	}
+	return source, ok
+}
+
+// sourceRepository returns the source repository of input, adding it if it was not already added.
+func (r *ReferenceBuilder) sourceRepository(input string) (*SourceRepository, bool) {
 	source, err := NewSourceRepository(input)
 	if err != nil {
 		r.errs = append(r.errs, err)
 		return nil, false
 	}
+	if existing := r.existingSourceRepository(input); existing != nil {
+		if existing.url.Fragment != source.url.Fragment {
+			r.errs = append(r.errs, fmt.Errorf("the source repository %q is given with conflicting refs %q and %q", sourceRepositoryKey(source), existing.url.Fragment, source.url.Fragment))
+			return nil, false
+		}
+		return existing, true
+	}
 	r.repos = append(r.repos, source)
 	return source, true
 }
 
+// existingSourceRepository returns the source repository that was added for the same repository as input,
+// regardless of its ref, or nil.
+func (r *ReferenceBuilder) existingSourceRepository(input string) *SourceRepository {
+	source, err := NewSourceRepository(input)
+	if err != nil {
+		return nil
+	}
+	key := sourceRepositoryKey(source)
+	for _, existing := range r.repos {
+		if len(existing.location) > 0 && !existing.ignoreRepository && sourceRepositoryKey(existing) == key {
+			return existing
+		}
+	}
+	return nil
+}
+
+// sourceRepositoryKey identifies the repository of source regardless of its ref, a trailing slash or a .git
+// suffix, and the case of its host.
+func sourceRepositoryKey(source *SourceRepository) string {
+	u := source.url
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), ".git")
+	return u.String()
+}
+
 func (r *ReferenceBuilder) AddExistingSourceRepository(source *SourceRepository) {
 	r.repos = append(r.repos, source)
 }
@@ -251,21 +332,58 @@ func (r *ReferenceBuilder) Result() (ComponentReferences, SourceRepositories, []
 	return r.refs, r.repos, r.errs
 }
 
+// Duplicates returns the inputs that were given more than once and are only used once.
+func (r *ReferenceBuilder) Duplicates() []DuplicateInput {
+	return r.duplicates
+}
+
 // NewComponentInput returns a new ComponentInput by checking for image using [image]~
-// (to indicate builder) or [image]~[code] (builder plus code)
+// (to indicate builder) or [image]~[code] (builder plus code). The image may be followed by
+// options, as in [image]?pullSecret=[name].
 func NewComponentInput(input string) (*ComponentInput, string, error) {
 	component, repo, builder, err := componentWithSource(input)
 	if err != nil {
 		return nil, "", err
 	}
+	component, pullSecrets, err := componentOptions(component)
+	if err != nil {
+		return nil, "", err
+	}
 	return &ComponentInput{
 		From:          input,
 		Argument:      input,
 		Value:         component,
 		ExpectToBuild: builder,
+		PullSecrets:   pullSecrets,
 	}, repo, nil
 }
 
+// componentOptions splits the options following a "?" from component. The only option is pullSecret, which
+// may be repeated.
+func componentOptions(component string) (string, []string, error) {
+	i := strings.Index(component, "?")
+	if i == -1 {
+		return component, nil, nil
+	}
+	options, err := url.ParseQuery(component[i+1:])
+	if err != nil {
+		return "", nil, fmt.Errorf("the options of %q are invalid: %v", component, err)
+	}
+	var pullSecrets []string
+	for key, values := range options {
+		if key != "pullSecret" {
+			return "", nil, fmt.Errorf("the option %q of %q is not supported, only pullSecret may be set", key, component)
+		}
+		for _, name := range values {
+			if len(name) == 0 {
+				return "", nil, fmt.Errorf("the pullSecret option of %q must name a secret", component)
+			}
+			pullSecrets = append(pullSecrets, name)
+		}
+	}
+	return component[:i], pullSecrets, nil
+}
+
 // ComponentInput is the necessary input for creating a component
 type ComponentInput struct {
 	GroupID  int
@@ -275,6 +393,8 @@ type ComponentInput struct {
 
 	ExpectToBuild bool
 	ScratchImage  bool
+	// PullSecrets are the names of the secrets used to pull the image of the component.
+	PullSecrets []string
 
 	Uses          *SourceRepository
 	ResolvedMatch *ComponentMatch
@@ -0,0 +1,37 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReferenceBuilderDuplicates(t *testing.T) {
+	b := &ReferenceBuilder{}
+	newRef := func(input *ComponentInput) ComponentReference { return input }
+	refs := b.AddComponents([]string{"mysql", "ruby~https://github.com/openshift/ruby-ex", "mysql", "ruby~https://github.com/openshift/ruby-ex"}, newRef)
+	if len(refs) != 2 {
+		t.Fatalf("unexpected references: %v", refs)
+	}
+	for _, input := range []string{"https://github.com/openshift/ruby-ex", "https://GitHub.com/openshift/ruby-ex.git/"} {
+		repo, ok := b.AddSourceRepository(input)
+		if !ok || repo != refs[1].Input().Uses {
+			t.Errorf("%s: expected the existing repository to be reused", input)
+		}
+	}
+	duplicates := b.Duplicates()
+	if len(duplicates) != 4 {
+		t.Fatalf("unexpected duplicates: %#v", duplicates)
+	}
+	if s := duplicates[3].String(); s != `the source repository "https://GitHub.com/openshift/ruby-ex.git/" is the same as "https://github.com/openshift/ruby-ex" and is only used once` {
+		t.Errorf("unexpected message: %s", s)
+	}
+
+	if _, ok := b.AddSourceRepository("https://github.com/openshift/ruby-ex#v2"); ok {
+		t.Errorf("expected a conflicting ref to be rejected")
+	}
+	b.AddComponents([]string{"postgresql", "postgresql?pullSecret=registry"}, newRef)
+	_, repos, errs := b.Result()
+	if len(repos) != 1 || len(errs) != 2 || !strings.Contains(errs[0].Error(), "conflicting refs") || !strings.Contains(errs[1].Error(), "conflicting options") {
+		t.Errorf("unexpected repositories %v and errors: %v", repos, errs)
+	}
+}
@@ -24,6 +24,35 @@ type Resolver interface {
 // matches
 type Searcher interface {
 	Search(precise bool, terms ...string) (ComponentMatches, []error)
+	// Capabilities describes the source the searcher searches.
+	Capabilities() SearcherCapabilities
+}
+
+// SearcherCapabilities describes the source of the matches of a searcher, so that generation can adapt to what
+// the matches carry and clients can show where matches come from.
+type SearcherCapabilities struct {
+	// SupportsTags is true if the matches identify a tag of an image whose other tags can be listed.
+	SupportsTags bool
+	// SupportsMetadata is true if the matches carry the metadata of their images, such as their ports and labels.
+	SupportsMetadata bool
+	// RequiresNetwork is true if the searcher contacts the server or a registry.
+	RequiresNetwork bool
+	// Authenticated is true if the searcher sends credentials with every request it makes over the network, so
+	// that private images and templates can be found.
+	Authenticated bool
+}
+
+// Merge returns the capabilities of a searcher that combines searchers with the capabilities c and other. It
+// supports what either supports, and is authenticated if it requires the network and every searcher that does
+// is authenticated.
+func (c SearcherCapabilities) Merge(other SearcherCapabilities) SearcherCapabilities {
+	return SearcherCapabilities{
+		SupportsTags:     c.SupportsTags || other.SupportsTags,
+		SupportsMetadata: c.SupportsMetadata || other.SupportsMetadata,
+		RequiresNetwork:  c.RequiresNetwork || other.RequiresNetwork,
+		Authenticated: (c.RequiresNetwork || other.RequiresNetwork) &&
+			(c.Authenticated || !c.RequiresNetwork) && (other.Authenticated || !other.RequiresNetwork),
+	}
 }
 
 // WeightedResolver is a resolver identified as exact or not, depending on its weight
@@ -75,7 +104,7 @@ func (r PerfectMatchWeightedResolver) Resolve(value string) (*ComponentMatch, er
 
 	switch len(candidates) {
 	case 0:
-		return nil, ErrNoMatch{Value: value, Errs: errs}
+		return nil, noMatch(value, errs)
 	case 1:
 		if candidates[0].Score != 0.0 {
 			return nil, ErrPartialMatch{Value: value, Match: candidates[0], Errs: errs}
@@ -105,7 +134,7 @@ type FirstMatchResolver struct {
 func (r FirstMatchResolver) Resolve(value string) (*ComponentMatch, error) {
 	matches, err := r.Searcher.Search(true, value)
 	if len(matches) == 0 {
-		return nil, ErrNoMatch{Value: value, Errs: err}
+		return nil, noMatch(value, err)
 	}
 	return matches[0], errors.NewAggregate(err)
 }
@@ -122,7 +151,7 @@ type HighestScoreResolver struct {
 func (r HighestScoreResolver) Resolve(value string) (*ComponentMatch, error) {
 	matches, err := r.Searcher.Search(true, value)
 	if len(matches) == 0 {
-		return nil, ErrNoMatch{Value: value, Errs: err}
+		return nil, noMatch(value, err)
 	}
 	sort.Sort(ScoredComponentMatches(matches))
 	return matches[0], errors.NewAggregate(err)
@@ -143,7 +172,7 @@ func (r HighestUniqueScoreResolver) Resolve(value string) (*ComponentMatch, erro
 	sort.Sort(ScoredComponentMatches(matches))
 	switch len(matches) {
 	case 0:
-		return nil, ErrNoMatch{Value: value, Errs: err}
+		return nil, noMatch(value, err)
 	case 1:
 		return matches[0], errors.NewAggregate(err)
 	default:
@@ -181,7 +210,7 @@ func (r UniqueExactOrInexactMatchResolver) Resolve(value string) (*ComponentMatc
 		inexact := matches.Inexact()
 		switch len(inexact) {
 		case 0:
-			return nil, ErrNoMatch{Value: value, Errs: err}
+			return nil, noMatch(value, err)
 		case 1:
 			return inexact[0], errors.NewAggregate(err)
 		default:
@@ -213,6 +242,15 @@ func (s MultiSimpleSearcher) Search(precise bool, terms ...string) (ComponentMat
 	return componentMatches, errs
 }
 
+// Capabilities merges the capabilities of the searchers.
+func (s MultiSimpleSearcher) Capabilities() SearcherCapabilities {
+	capabilities := SearcherCapabilities{}
+	for _, searcher := range s {
+		capabilities = capabilities.Merge(searcher.Capabilities())
+	}
+	return capabilities
+}
+
 // WeightedSearcher is a searcher identified as exact or not, depending on its weight
 type WeightedSearcher struct {
 	Searcher
@@ -241,3 +279,12 @@ func (s MultiWeightedSearcher) Search(precise bool, terms ...string) (ComponentM
 	sort.Sort(ScoredComponentMatches(componentMatches))
 	return componentMatches, errs
 }
+
+// Capabilities merges the capabilities of the searchers.
+func (s MultiWeightedSearcher) Capabilities() SearcherCapabilities {
+	capabilities := SearcherCapabilities{}
+	for _, searcher := range s {
+		capabilities = capabilities.Merge(searcher.Capabilities())
+	}
+	return capabilities
+}
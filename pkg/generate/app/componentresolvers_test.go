@@ -18,6 +18,10 @@ func (m mockSearcher) Search(precise bool, terms ...string) (ComponentMatches, [
 	return results, nil
 }
 
+func (m mockSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
+
 func TestWeightedResolvers(t *testing.T) {
 	resolver1 := WeightedResolver{mockSearcher{2}, 1.0}
 	resolver2 := WeightedResolver{mockSearcher{3}, 1.0}
@@ -35,3 +39,41 @@ func TestWeightedResolvers(t *testing.T) {
 		t.Errorf("expected %v matches, got %v", 5, len(multiError.Matches))
 	}
 }
+
+type artifactSearcher struct{}
+
+func (artifactSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	return nil, []error{fmt.Errorf("unrelated"), ErrNotAnImage{Value: terms[0], Kind: "Helm chart", MediaType: "application/vnd.cncf.helm.config.v1+json"}}
+}
+
+func (artifactSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
+
+func TestResolveArtifact(t *testing.T) {
+	wr := PerfectMatchWeightedResolver{WeightedResolver{artifactSearcher{}, 0.0}}
+	_, err := wr.Resolve("charts/nginx")
+	if e, ok := err.(ErrNotAnImage); !ok || e.Value != "charts/nginx" {
+		t.Errorf("expected a not an image error, got %v", err)
+	}
+}
+
+func TestSearcherCapabilitiesMerge(t *testing.T) {
+	local := SearcherCapabilities{SupportsTags: true, SupportsMetadata: true}
+	registry := SearcherCapabilities{SupportsTags: true, RequiresNetwork: true}
+	server := SearcherCapabilities{RequiresNetwork: true, Authenticated: true}
+
+	if c := local.Merge(SearcherCapabilities{}); c != local {
+		t.Errorf("unexpected capabilities: %#v", c)
+	}
+	if c := local.Merge(server); c != (SearcherCapabilities{SupportsTags: true, SupportsMetadata: true, RequiresNetwork: true, Authenticated: true}) {
+		t.Errorf("unexpected capabilities: %#v", c)
+	}
+	if c := server.Merge(registry); c.Authenticated || !c.RequiresNetwork || !c.SupportsTags {
+		t.Errorf("unexpected capabilities: %#v", c)
+	}
+	searcher := MultiWeightedSearcher{{Searcher: mockSearcher{}}, {Searcher: artifactSearcher{}}}
+	if c := searcher.Capabilities(); c != (SearcherCapabilities{}) {
+		t.Errorf("unexpected capabilities: %#v", c)
+	}
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	docker "github.com/fsouza/go-dockerclient"
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// RunningContainer describes a container that was started outside of a deployment config, from which an
+// equivalent deployment config is generated.
+type RunningContainer struct {
+	// Name is the name of the generated deployment config.
+	Name string
+	// Image is the pull spec of the image the container runs.
+	Image string
+
+	Command    []string
+	Args       []string
+	WorkingDir string
+	Env        []kapi.EnvVar
+	Ports      []kapi.ContainerPort
+	Volumes    []ContainerVolume
+}
+
+// ContainerVolume is a volume mounted into a running container. Persistent volumes are backed by a generated
+// persistent volume claim, volumes with a Source keep it, and all other volumes become empty directories.
+type ContainerVolume struct {
+	Name       string
+	MountPath  string
+	ReadOnly   bool
+	Persistent bool
+	Source     *kapi.VolumeSource
+}
+
+// ReadDockerInspect reads the output of docker inspect for one or more containers.
+func ReadDockerInspect(r io.Reader) ([]RunningContainer, error) {
+	containers := []*docker.Container{}
+	if err := json.NewDecoder(r).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("unable to read the docker inspect output: %v", err)
+	}
+	result := make([]RunningContainer, 0, len(containers))
+	for _, c := range containers {
+		running, err := RunningContainerFromDocker(c)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *running)
+	}
+	return result, nil
+}
+
+// RunningContainerFromDocker describes a container inspected by Docker. Named volumes and host directories are
+// persistent, while the anonymous volumes Docker creates for the volumes of the image are not.
+func RunningContainerFromDocker(c *docker.Container) (*RunningContainer, error) {
+	if c.Config == nil || len(c.Config.Image) == 0 {
+		return nil, fmt.Errorf("the container %q does not record its image", c.ID)
+	}
+	config := c.Config
+	running := &RunningContainer{
+		Name:       containerObjectName(c.Name),
+		Image:      config.Image,
+		Command:    config.Entrypoint,
+		Args:       config.Cmd,
+		WorkingDir: config.WorkingDir,
+	}
+	if len(running.Name) == 0 {
+		ref, err := imageapi.ParseDockerImageReference(config.Image)
+		if err != nil {
+			return nil, err
+		}
+		running.Name = ref.Name
+	}
+	for _, s := range config.Env {
+		parts := strings.SplitN(s, "=", 2)
+		env := kapi.EnvVar{Name: parts[0]}
+		if len(parts) == 2 {
+			env.Value = parts[1]
+		}
+		running.Env = append(running.Env, env)
+	}
+
+	exposed := []string{}
+	for p := range config.ExposedPorts {
+		exposed = append(exposed, string(p))
+	}
+	sort.Strings(exposed)
+	for _, s := range exposed {
+		p := docker.Port(s)
+		port, err := strconv.Atoi(p.Port())
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse port %q: %v", p.Port(), err)
+		}
+		running.Ports = append(running.Ports, kapi.ContainerPort{
+			ContainerPort: port,
+			Protocol:      kapi.Protocol(strings.ToUpper(p.Proto())),
+		})
+	}
+
+	for i, m := range c.Mounts {
+		running.Volumes = append(running.Volumes, ContainerVolume{
+			Name:       fmt.Sprintf("%s-%d", volumeNameInfix, i+1),
+			MountPath:  m.Destination,
+			ReadOnly:   !m.RW,
+			Persistent: !isAnonymousVolume(m),
+		})
+	}
+	return running, nil
+}
+
+// RunningContainerFromPod describes the container name of pod. Claims and secrets mounted into the container
+// are kept, host directories become persistent, and other volumes become empty directories.
+func RunningContainerFromPod(pod *kapi.Pod, name string) (*RunningContainer, error) {
+	var container *kapi.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == name || (len(name) == 0 && len(pod.Spec.Containers) == 1) {
+			container = &pod.Spec.Containers[i]
+			break
+		}
+	}
+	if container == nil {
+		if len(name) == 0 {
+			return nil, fmt.Errorf("the pod %q has more than one container, please specify one", pod.Name)
+		}
+		return nil, fmt.Errorf("the pod %q has no container %q", pod.Name, name)
+	}
+	running := &RunningContainer{
+		Name:       containerObjectName(pod.Name),
+		Image:      container.Image,
+		Command:    container.Command,
+		Args:       container.Args,
+		WorkingDir: container.WorkingDir,
+		Env:        container.Env,
+		Ports:      container.Ports,
+	}
+	if len(pod.Spec.Containers) > 1 {
+		running.Name = containerObjectName(container.Name)
+	}
+	volumes := make(map[string]kapi.VolumeSource)
+	for _, v := range pod.Spec.Volumes {
+		volumes[v.Name] = v.VolumeSource
+	}
+	for _, m := range container.VolumeMounts {
+		volume := ContainerVolume{Name: m.Name, MountPath: m.MountPath, ReadOnly: m.ReadOnly}
+		source := volumes[m.Name]
+		switch {
+		case source.PersistentVolumeClaim != nil, source.Secret != nil:
+			volume.Source = &source
+		case source.HostPath != nil:
+			volume.Persistent = true
+		}
+		running.Volumes = append(running.Volumes, volume)
+	}
+	return running, nil
+}
+
+// DeploymentConfig returns a deployment config that runs the container, along with the persistent volume
+// claims of its persistent volumes.
+func (c *RunningContainer) DeploymentConfig() (*deployapi.DeploymentConfig, []runtime.Object, error) {
+	if len(c.Name) == 0 || len(c.Image) == 0 {
+		return nil, nil, fmt.Errorf("a running container must have a name and an image")
+	}
+	size, err := resource.ParseQuantity(DefaultPresetVolumeSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	selector := map[string]string{"deploymentconfig": c.Name}
+	container := kapi.Container{
+		Name:       c.Name,
+		Image:      c.Image,
+		Command:    c.Command,
+		Args:       c.Args,
+		WorkingDir: c.WorkingDir,
+		Env:        c.Env,
+		Ports:      c.Ports,
+	}
+	template := kapi.PodSpec{}
+	claims := []runtime.Object{}
+	for _, v := range c.Volumes {
+		source := kapi.VolumeSource{EmptyDir: &kapi.EmptyDirVolumeSource{Medium: kapi.StorageMediumDefault}}
+		switch {
+		case v.Source != nil:
+			source = *v.Source
+		case v.Persistent:
+			claim := persistentVolumeClaim(fmt.Sprintf("%s-%s", c.Name, v.Name), nil, *size)
+			claims = append(claims, claim)
+			source = kapi.VolumeSource{
+				PersistentVolumeClaim: &kapi.PersistentVolumeClaimVolumeSource{ClaimName: claim.Name},
+			}
+		}
+		template.Volumes = append(template.Volumes, kapi.Volume{Name: v.Name, VolumeSource: source})
+		container.VolumeMounts = append(container.VolumeMounts, kapi.VolumeMount{Name: v.Name, MountPath: v.MountPath, ReadOnly: v.ReadOnly})
+	}
+	template.Containers = []kapi.Container{container}
+
+	return &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{
+			Name: c.Name,
+		},
+		Spec: deployapi.DeploymentConfigSpec{
+			Replicas: 1,
+			Selector: selector,
+			Template: &kapi.PodTemplateSpec{
+				ObjectMeta: kapi.ObjectMeta{
+					Labels: selector,
+				},
+				Spec: template,
+			},
+			Triggers: []deployapi.DeploymentTriggerPolicy{
+				{Type: deployapi.DeploymentTriggerOnConfigChange},
+			},
+		},
+	}, claims, nil
+}
+
+// RunningContainerObjects returns the deployment configs and persistent volume claims equivalent to containers.
+// Services are added for them like for any other deployment config.
+func RunningContainerObjects(containers []RunningContainer) (Objects, error) {
+	objects := Objects{}
+	for i := range containers {
+		dc, claims, err := containers[i].DeploymentConfig()
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, dc)
+		objects = append(objects, claims...)
+	}
+	return objects, nil
+}
+
+// containerObjectName turns the name of a container into a valid object name.
+func containerObjectName(name string) string {
+	name = strings.ToLower(strings.TrimPrefix(name, "/"))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '-'
+	}, name)
+	return strings.Trim(name, "-")
+}
+
+// isAnonymousVolume returns true if m is a volume Docker created without a name, which it identifies by a
+// random 64 character hex string.
+func isAnonymousVolume(m docker.Mount) bool {
+	if len(m.Name) != 64 {
+		return false
+	}
+	for _, r := range m.Name {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
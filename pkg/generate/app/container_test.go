@@ -0,0 +1,102 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestReadDockerInspect(t *testing.T) {
+	inspect := `[{
+		"Id": "abc",
+		"Name": "/My_DB",
+		"Config": {
+			"Image": "mysql:5.6",
+			"Env": ["MYSQL_USER=user", "EMPTY"],
+			"ExposedPorts": {"3306/tcp": {}}
+		},
+		"Mounts": [
+			{"Name": "data", "Source": "/var/lib/docker/volumes/data/_data", "Destination": "/var/lib/mysql", "Driver": "local", "RW": true},
+			{"Name": "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", "Destination": "/tmp", "Driver": "local", "RW": true},
+			{"Source": "/etc/mysql", "Destination": "/etc/mysql/conf.d", "RW": false}
+		]
+	}]`
+	containers, err := ReadDockerInspect(strings.NewReader(inspect))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("unexpected containers: %#v", containers)
+	}
+	objects, err := RunningContainerObjects(containers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected a deployment config and two claims: %#v", objects)
+	}
+	dc := objects[0].(*deployapi.DeploymentConfig)
+	if dc.Name != "my-db" {
+		t.Errorf("unexpected name: %s", dc.Name)
+	}
+	container := dc.Spec.Template.Spec.Containers[0]
+	if container.Image != "mysql:5.6" || len(container.Env) != 2 || container.Env[0].Value != "user" {
+		t.Errorf("unexpected container: %#v", container)
+	}
+	if len(container.Ports) != 1 || container.Ports[0].ContainerPort != 3306 || container.Ports[0].Protocol != kapi.ProtocolTCP {
+		t.Errorf("unexpected ports: %#v", container.Ports)
+	}
+	volumes := dc.Spec.Template.Spec.Volumes
+	if len(volumes) != 3 || volumes[0].PersistentVolumeClaim == nil || volumes[1].EmptyDir == nil || volumes[2].PersistentVolumeClaim == nil {
+		t.Errorf("unexpected volumes: %#v", volumes)
+	}
+	if claim := objects[1].(*kapi.PersistentVolumeClaim); claim.Name != "my-db-volume-1" {
+		t.Errorf("unexpected claim: %#v", claim)
+	}
+	if !container.VolumeMounts[2].ReadOnly {
+		t.Errorf("expected a read only mount: %#v", container.VolumeMounts[2])
+	}
+}
+
+func TestRunningContainerFromPod(t *testing.T) {
+	pod := &kapi.Pod{
+		ObjectMeta: kapi.ObjectMeta{Name: "web"},
+		Spec: kapi.PodSpec{
+			Containers: []kapi.Container{{
+				Name:  "web",
+				Image: "nginx",
+				VolumeMounts: []kapi.VolumeMount{
+					{Name: "config", MountPath: "/etc/nginx"},
+					{Name: "logs", MountPath: "/var/log/nginx"},
+					{Name: "html", MountPath: "/usr/share/nginx/html"},
+				},
+			}},
+			Volumes: []kapi.Volume{
+				{Name: "config", VolumeSource: kapi.VolumeSource{Secret: &kapi.SecretVolumeSource{SecretName: "config"}}},
+				{Name: "logs", VolumeSource: kapi.VolumeSource{EmptyDir: &kapi.EmptyDirVolumeSource{}}},
+				{Name: "html", VolumeSource: kapi.VolumeSource{HostPath: &kapi.HostPathVolumeSource{Path: "/srv/html"}}},
+			},
+		},
+	}
+	running, err := RunningContainerFromPod(pod, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc, claims, err := running.DeploymentConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	volumes := dc.Spec.Template.Spec.Volumes
+	if volumes[0].Secret == nil || volumes[1].EmptyDir == nil || volumes[2].PersistentVolumeClaim == nil {
+		t.Errorf("unexpected volumes: %#v", volumes)
+	}
+	if len(claims) != 1 || claims[0].(*kapi.PersistentVolumeClaim).Name != "web-html" {
+		t.Errorf("unexpected claims: %#v", claims)
+	}
+	if _, err := RunningContainerFromPod(pod, "missing"); err == nil {
+		t.Errorf("expected an error for a missing container")
+	}
+}
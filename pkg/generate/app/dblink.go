@@ -0,0 +1,178 @@
+package app
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/golang/glog"
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+const (
+	// DatabaseUserKey, DatabasePasswordKey and DatabaseNameKey are the keys of the secrets generated for linked
+	// databases.
+	DatabaseUserKey     = "database-user"
+	DatabasePasswordKey = "database-password"
+	DatabaseNameKey     = "database-name"
+
+	// defaultDatabaseName is the name of the database created in linked databases.
+	defaultDatabaseName = "sampledb"
+)
+
+// DatabaseImage describes the environment a database image is configured with.
+type DatabaseImage struct {
+	// Port is the port the database listens on.
+	Port int
+	// UserEnv, PasswordEnv and DatabaseEnv are the variables that set the credentials and the database to
+	// create.
+	UserEnv     string
+	PasswordEnv string
+	DatabaseEnv string
+	// AdminPasswordEnv, if set, is a variable that must be set to the password of the administrator.
+	AdminPasswordEnv string
+}
+
+// DatabaseImages are the database images that can be linked, by the name of their image repository. Images
+// whose name starts with a key followed by a dash, such as mysql-56-centos7, are also matched.
+var DatabaseImages = map[string]DatabaseImage{
+	"mysql":      {Port: 3306, UserEnv: "MYSQL_USER", PasswordEnv: "MYSQL_PASSWORD", DatabaseEnv: "MYSQL_DATABASE"},
+	"mariadb":    {Port: 3306, UserEnv: "MYSQL_USER", PasswordEnv: "MYSQL_PASSWORD", DatabaseEnv: "MYSQL_DATABASE"},
+	"postgresql": {Port: 5432, UserEnv: "POSTGRESQL_USER", PasswordEnv: "POSTGRESQL_PASSWORD", DatabaseEnv: "POSTGRESQL_DATABASE"},
+	"mongodb":    {Port: 27017, UserEnv: "MONGODB_USER", PasswordEnv: "MONGODB_PASSWORD", DatabaseEnv: "MONGODB_DATABASE", AdminPasswordEnv: "MONGODB_ADMIN_PASSWORD"},
+}
+
+// databaseImageFor returns the database run by image, if any.
+func databaseImageFor(image string) (DatabaseImage, bool) {
+	ref, err := imageapi.ParseDockerImageReference(image)
+	if err != nil {
+		return DatabaseImage{}, false
+	}
+	for name, db := range DatabaseImages {
+		if ref.Name == name || strings.HasPrefix(ref.Name, name+"-") {
+			return db, true
+		}
+	}
+	return DatabaseImage{}, false
+}
+
+var invalidEnvChars = regexp.MustCompile("[^A-Z0-9_]")
+
+// LinkDatabases wires the deployment configs in objects that run an application to the deployment configs that
+// run a database, and returns the objects along with a secret holding the generated credentials of each
+// database. The database container is configured from the secret, and each application container gets the
+// variables <NAME>_HOST and <NAME>_PORT, set to the service of the database, and <NAME>_USER, <NAME>_PASSWORD
+// and <NAME>_DATABASE, referencing the secret, where <NAME> is the upper cased name of the database deployment
+// config. Databases whose credentials are already set are not linked.
+func LinkDatabases(objects Objects) Objects {
+	secrets := []runtime.Object{}
+	apps := []*deployapi.DeploymentConfig{}
+	type link struct {
+		dc      *deployapi.DeploymentConfig
+		db      DatabaseImage
+		service *kapi.Service
+		secret  string
+	}
+	links := []link{}
+	for _, obj := range objects {
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil || len(dc.Spec.Template.Spec.Containers) == 0 {
+			continue
+		}
+		container := &dc.Spec.Template.Spec.Containers[0]
+		db, ok := databaseImageFor(container.Image)
+		if !ok {
+			apps = append(apps, dc)
+			continue
+		}
+		if hasEnv(container.Env, db.UserEnv) || hasEnv(container.Env, db.PasswordEnv) {
+			glog.V(4).Infof("The credentials of the database %q are already set, it is not linked", dc.Name)
+			continue
+		}
+		service := serviceFor(objects, dc)
+		if service == nil {
+			continue
+		}
+		secret := &kapi.Secret{
+			ObjectMeta: kapi.ObjectMeta{Name: dc.Name, Labels: dc.Labels},
+			Data: map[string][]byte{
+				DatabaseUserKey:     []byte("user" + strings.ToLower(generateSecret(4))),
+				DatabasePasswordKey: []byte(generateSecret(16)),
+				DatabaseNameKey:     []byte(defaultDatabaseName),
+			},
+		}
+		secrets = append(secrets, secret)
+		container.Env = append(container.Env,
+			secretEnv(db.UserEnv, secret.Name, DatabaseUserKey),
+			secretEnv(db.PasswordEnv, secret.Name, DatabasePasswordKey),
+			secretEnv(db.DatabaseEnv, secret.Name, DatabaseNameKey),
+		)
+		if len(db.AdminPasswordEnv) > 0 && !hasEnv(container.Env, db.AdminPasswordEnv) {
+			container.Env = append(container.Env, kapi.EnvVar{Name: db.AdminPasswordEnv, Value: generateSecret(16)})
+		}
+		links = append(links, link{dc: dc, db: db, service: service, secret: secret.Name})
+	}
+
+	for _, dc := range apps {
+		for _, l := range links {
+			prefix := invalidEnvChars.ReplaceAllString(strings.ToUpper(l.dc.Name), "_")
+			port := l.db.Port
+			if len(l.service.Spec.Ports) > 0 {
+				port = l.service.Spec.Ports[0].Port
+			}
+			env := []kapi.EnvVar{
+				{Name: prefix + "_HOST", Value: l.service.Name},
+				{Name: prefix + "_PORT", Value: fmt.Sprintf("%d", port)},
+				secretEnv(prefix+"_USER", l.secret, DatabaseUserKey),
+				secretEnv(prefix+"_PASSWORD", l.secret, DatabasePasswordKey),
+				secretEnv(prefix+"_DATABASE", l.secret, DatabaseNameKey),
+			}
+			for i := range dc.Spec.Template.Spec.Containers {
+				container := &dc.Spec.Template.Spec.Containers[i]
+				for _, e := range env {
+					if !hasEnv(container.Env, e.Name) {
+						container.Env = append(container.Env, e)
+					}
+				}
+			}
+		}
+	}
+	return append(objects, secrets...)
+}
+
+// serviceFor returns the service in objects that selects the pods of dc, if any.
+func serviceFor(objects Objects, dc *deployapi.DeploymentConfig) *kapi.Service {
+	for _, obj := range objects {
+		service, ok := obj.(*kapi.Service)
+		if !ok || len(service.Spec.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(service.Spec.Selector).Matches(labels.Set(dc.Spec.Template.Labels)) {
+			return service
+		}
+	}
+	return nil
+}
+
+func secretEnv(name, secret, key string) kapi.EnvVar {
+	return kapi.EnvVar{
+		Name: name,
+		ValueFrom: &kapi.EnvVarSource{
+			SecretKeyRef: &kapi.SecretKeySelector{LocalObjectReference: kapi.LocalObjectReference{Name: secret}, Key: key},
+		},
+	}
+}
+
+func hasEnv(env []kapi.EnvVar, name string) bool {
+	for _, e := range env {
+		if e.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestLinkDatabases(t *testing.T) {
+	dc := func(name, image string, env ...kapi.EnvVar) *deployapi.DeploymentConfig {
+		return &deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: name},
+			Spec: deployapi.DeploymentConfigSpec{
+				Selector: map[string]string{"deploymentconfig": name},
+				Template: &kapi.PodTemplateSpec{
+					ObjectMeta: kapi.ObjectMeta{Labels: map[string]string{"deploymentconfig": name}},
+					Spec: kapi.PodSpec{
+						Containers: []kapi.Container{{
+							Name:  name,
+							Image: image,
+							Env:   env,
+							Ports: []kapi.ContainerPort{{ContainerPort: 8080, Protocol: kapi.ProtocolTCP}},
+						}},
+					},
+				},
+			},
+		}
+	}
+	web := dc("web", "172.30.1.1:5000/test/web:latest")
+	db := dc("my-db", "centos/postgresql-94-centos7")
+	configured := dc("mysql", "mysql:5.6", kapi.EnvVar{Name: "MYSQL_USER", Value: "admin"})
+	objects := AddServices(Objects{web, db, configured}, false)
+
+	objects = LinkDatabases(objects)
+
+	secret, ok := objects[len(objects)-1].(*kapi.Secret)
+	if !ok || secret.Name != "my-db" || len(secret.Data[DatabasePasswordKey]) == 0 {
+		t.Fatalf("expected a secret for the database: %#v", objects[len(objects)-1])
+	}
+	for _, obj := range objects[:len(objects)-1] {
+		if _, ok := obj.(*kapi.Secret); ok {
+			t.Errorf("only one secret should be generated")
+		}
+	}
+
+	dbEnv := db.Spec.Template.Spec.Containers[0].Env
+	if len(dbEnv) != 3 || dbEnv[0].Name != "POSTGRESQL_USER" || dbEnv[0].ValueFrom.SecretKeyRef.Name != "my-db" {
+		t.Errorf("unexpected database environment: %#v", dbEnv)
+	}
+	if len(configured.Spec.Template.Spec.Containers[0].Env) != 1 {
+		t.Errorf("a configured database should not be changed")
+	}
+
+	env := map[string]kapi.EnvVar{}
+	for _, e := range web.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e
+	}
+	if len(env) != 5 || env["MY_DB_HOST"].Value != "my-db" || env["MY_DB_PORT"].Value != "8080" {
+		t.Errorf("unexpected application environment: %#v", env)
+	}
+	if ref := env["MY_DB_PASSWORD"].ValueFrom; ref == nil || ref.SecretKeyRef.Key != DatabasePasswordKey {
+		t.Errorf("unexpected password: %#v", env["MY_DB_PASSWORD"])
+	}
+}
@@ -0,0 +1,158 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// DebugPortAnnotation is set on generated debug deployment configs to the port the debugger listens on.
+const DebugPortAnnotation = "openshift.io/debug-port"
+
+// DebugProfile describes how to enable remote debugging for images of a language.
+type DebugProfile struct {
+	// Language is matched against the tags of the images in a pipeline.
+	Language string
+	// Port is the port the debugger listens on.
+	Port int
+	// Env is set on the containers of the debug deployment.
+	Env Environment
+}
+
+// DebugProfiles are the languages debug deployments can be generated for.
+var DebugProfiles = []DebugProfile{
+	{
+		Language: "java",
+		Port:     5005,
+		Env: Environment{
+			"JAVA_TOOL_OPTIONS": "-agentlib:jdwp=transport=dt_socket,server=y,suspend=n,address=5005",
+		},
+	},
+	{
+		Language: "nodejs",
+		Port:     5858,
+		Env: Environment{
+			"DEV_MODE":   "true",
+			"DEBUG_PORT": "5858",
+		},
+	},
+}
+
+// DebugProfileForPipeline returns the debug profile matching the language of the images used by p. If the
+// language is not known, the profile whose debug port is exposed by the deployed image is returned. Nil is
+// returned if no profile applies.
+func DebugProfileForPipeline(p *Pipeline) *DebugProfile {
+	terms := pipelineLanguageTerms(p)
+	for i := range DebugProfiles {
+		for _, term := range terms {
+			if term == DebugProfiles[i].Language {
+				return &DebugProfiles[i]
+			}
+		}
+	}
+	if p.Image == nil || p.Image.Info == nil || p.Image.Info.Config == nil {
+		return nil
+	}
+	for i := range DebugProfiles {
+		port := strconv.Itoa(DebugProfiles[i].Port)
+		for exposed := range p.Image.Info.Config.ExposedPorts {
+			if exposed == port || exposed == port+"/tcp" {
+				return &DebugProfiles[i]
+			}
+		}
+	}
+	return nil
+}
+
+// pipelineLanguageTerms returns the tags and supported languages of the builder and deployed images of p.
+func pipelineLanguageTerms(p *Pipeline) []string {
+	refs := []*ImageRef{p.Image, p.InputImage}
+	if p.Build != nil && p.Build.Strategy != nil {
+		refs = append(refs, p.Build.Strategy.Base)
+	}
+	var terms []string
+	add := func(value string) {
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if i := strings.Index(s, ":"); i != -1 {
+				s = s[:i]
+			}
+			if len(s) > 0 {
+				terms = append(terms, s)
+			}
+		}
+	}
+	for _, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		if ref.Info != nil && ref.Info.Config != nil {
+			add(ref.Info.Config.Labels["io.openshift.tags"])
+		}
+		if ref.Stream != nil {
+			if tag, ok := ref.Stream.Spec.Tags[ref.Reference.Tag]; ok {
+				add(tag.Annotations["tags"])
+				add(tag.Annotations[supportsAnnotationKey])
+			}
+		}
+	}
+	return terms
+}
+
+// DebugDeploymentConfig returns a copy of dc named <name>-debug that runs a single replica with remote debugging
+// enabled on every container as described by profile. The debug port is exposed on each container but no
+// service is created for it, so the debugger is expected to be reached with port forwarding.
+func DebugDeploymentConfig(dc *deployapi.DeploymentConfig, profile *DebugProfile) (*deployapi.DeploymentConfig, error) {
+	copied, err := kapi.Scheme.DeepCopy(dc)
+	if err != nil {
+		return nil, err
+	}
+	debug := copied.(*deployapi.DeploymentConfig)
+	debug.Name = fmt.Sprintf("%s-debug", dc.Name)
+	debug.Spec.Replicas = 1
+	debug.Spec.Test = false
+	if _, ok := debug.Spec.Selector["deploymentconfig"]; ok {
+		debug.Spec.Selector["deploymentconfig"] = debug.Name
+	}
+	if debug.Spec.Template == nil {
+		return debug, nil
+	}
+	if _, ok := debug.Spec.Template.Labels["deploymentconfig"]; ok {
+		debug.Spec.Template.Labels["deploymentconfig"] = debug.Name
+	}
+	for i := range debug.Spec.Template.Spec.Containers {
+		container := &debug.Spec.Template.Spec.Containers[i]
+		exposed := false
+		for _, port := range container.Ports {
+			if port.ContainerPort == profile.Port {
+				exposed = true
+				break
+			}
+		}
+		if !exposed {
+			container.Ports = append(container.Ports, kapi.ContainerPort{
+				Name:          "debug",
+				ContainerPort: profile.Port,
+				Protocol:      kapi.ProtocolTCP,
+			})
+		}
+		env := Environment{}
+		for _, e := range container.Env {
+			env[e.Name] = e.Value
+		}
+		for _, e := range profile.Env.List() {
+			if _, ok := env[e.Name]; !ok {
+				container.Env = append(container.Env, e)
+			}
+		}
+	}
+	if debug.Annotations == nil {
+		debug.Annotations = map[string]string{}
+	}
+	debug.Annotations[DebugPortAnnotation] = strconv.Itoa(profile.Port)
+	return debug, nil
+}
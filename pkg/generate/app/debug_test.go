@@ -0,0 +1,94 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestDebugProfileForPipeline(t *testing.T) {
+	withLabels := func(labels map[string]string, ports ...string) *ImageRef {
+		exposed := map[string]struct{}{}
+		for _, p := range ports {
+			exposed[p] = struct{}{}
+		}
+		return &ImageRef{Info: &imageapi.DockerImage{Config: &imageapi.DockerConfig{Labels: labels, ExposedPorts: exposed}}}
+	}
+	tests := map[string]struct {
+		pipeline *Pipeline
+		language string
+	}{
+		"java image": {
+			pipeline: &Pipeline{Image: withLabels(map[string]string{"io.openshift.tags": "builder,java,wildfly"})},
+			language: "java",
+		},
+		"nodejs builder": {
+			pipeline: &Pipeline{
+				Image: withLabels(nil),
+				Build: &BuildRef{Strategy: &BuildStrategyRef{Base: withLabels(map[string]string{"io.openshift.tags": "builder,nodejs,nodejs4"})}},
+			},
+			language: "nodejs",
+		},
+		"exposed debug port": {
+			pipeline: &Pipeline{Image: withLabels(nil, "8080/tcp", "5005/tcp")},
+			language: "java",
+		},
+		"unknown": {
+			pipeline: &Pipeline{Image: withLabels(map[string]string{"io.openshift.tags": "python"}, "8080/tcp")},
+		},
+	}
+	for name, test := range tests {
+		profile := DebugProfileForPipeline(test.pipeline)
+		switch {
+		case profile == nil && len(test.language) > 0:
+			t.Errorf("%s: expected a %s profile", name, test.language)
+		case profile != nil && profile.Language != test.language:
+			t.Errorf("%s: unexpected profile %s", name, profile.Language)
+		}
+	}
+}
+
+func TestDebugDeploymentConfig(t *testing.T) {
+	dc := &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "web"},
+		Spec: deployapi.DeploymentConfigSpec{
+			Replicas: 3,
+			Selector: map[string]string{"deploymentconfig": "web"},
+			Template: &kapi.PodTemplateSpec{
+				ObjectMeta: kapi.ObjectMeta{Labels: map[string]string{"deploymentconfig": "web"}},
+				Spec: kapi.PodSpec{Containers: []kapi.Container{{
+					Name:  "web",
+					Ports: []kapi.ContainerPort{{ContainerPort: 8080}},
+					Env:   []kapi.EnvVar{{Name: "DEBUG_PORT", Value: "9229"}},
+				}}},
+			},
+		},
+	}
+	debug, err := DebugDeploymentConfig(dc, &DebugProfiles[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if debug.Name != "web-debug" || debug.Spec.Replicas != 1 || debug.Spec.Selector["deploymentconfig"] != "web-debug" {
+		t.Errorf("unexpected debug deployment config: %#v", debug)
+	}
+	if dc.Spec.Selector["deploymentconfig"] != "web" || dc.Spec.Replicas != 3 {
+		t.Errorf("the original deployment config was modified: %#v", dc)
+	}
+	container := debug.Spec.Template.Spec.Containers[0]
+	if len(container.Ports) != 2 || container.Ports[1].ContainerPort != 5858 {
+		t.Errorf("unexpected ports: %#v", container.Ports)
+	}
+	env := map[string]string{}
+	for _, e := range container.Env {
+		env[e.Name] = e.Value
+	}
+	if env["DEV_MODE"] != "true" || env["DEBUG_PORT"] != "9229" || len(container.Env) != 2 {
+		t.Errorf("unexpected env: %#v", container.Env)
+	}
+	if debug.Annotations[DebugPortAnnotation] != "5858" {
+		t.Errorf("unexpected annotations: %#v", debug.Annotations)
+	}
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// DedupeObjects removes the objects that have the same kind, namespace and name as an earlier object in
+// objects, such as the image streams generated for two components that use the same builder image. The
+// annotations of a removed object are merged into the object that is kept. Objects with the same kind,
+// namespace and name that differ in anything but their annotations, or that set an annotation to different
+// values, cannot both be created and are an error.
+func DedupeObjects(typer runtime.ObjectTyper, objects Objects) (Objects, error) {
+	result := Objects{}
+	kept := make(map[string]runtime.Object)
+	for _, obj := range objects {
+		_, meta, err := objectMetaData(obj)
+		if err != nil {
+			return nil, err
+		}
+		gvk, err := typer.ObjectKind(obj)
+		if err != nil {
+			return nil, err
+		}
+		key := fmt.Sprintf("%s/%s/%s", gvk.Kind, meta.Namespace, meta.Name)
+		existing, ok := kept[key]
+		if !ok {
+			kept[key] = obj
+			result = append(result, obj)
+			continue
+		}
+		if err := mergeDuplicate(existing, obj); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// mergeDuplicate merges the annotations of duplicate into existing if the objects are otherwise identical.
+func mergeDuplicate(existing, duplicate runtime.Object) error {
+	a, err := withoutAnnotations(existing)
+	if err != nil {
+		return err
+	}
+	b, err := withoutAnnotations(duplicate)
+	if err != nil {
+		return err
+	}
+	if !kapi.Semantic.DeepEqual(a, b) {
+		return fmt.Errorf("%s is generated more than once with different definitions: %s", describeObject(existing), util.ObjectDiff(a, b))
+	}
+
+	_, existingMeta, _ := objectMetaData(existing)
+	_, duplicateMeta, _ := objectMetaData(duplicate)
+	for k, v := range duplicateMeta.Annotations {
+		if current, ok := existingMeta.Annotations[k]; ok {
+			if current != v {
+				return fmt.Errorf("%s is generated more than once with different values for the annotation %s: %q and %q", describeObject(existing), k, current, v)
+			}
+			continue
+		}
+		if existingMeta.Annotations == nil {
+			existingMeta.Annotations = make(map[string]string)
+		}
+		existingMeta.Annotations[k] = v
+	}
+	return nil
+}
+
+// withoutAnnotations returns a copy of obj that has no annotations.
+func withoutAnnotations(obj runtime.Object) (runtime.Object, error) {
+	copied, err := kapi.Scheme.DeepCopy(obj)
+	if err != nil {
+		return nil, err
+	}
+	result := copied.(runtime.Object)
+	_, meta, err := objectMetaData(result)
+	if err != nil {
+		return nil, err
+	}
+	meta.Annotations = nil
+	return result, nil
+}
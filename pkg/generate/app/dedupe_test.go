@@ -0,0 +1,42 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestDedupeObjects(t *testing.T) {
+	is := func(name, repository string, annotations map[string]string) *imageapi.ImageStream {
+		obj := &imageapi.ImageStream{}
+		obj.Name = name
+		obj.Annotations = annotations
+		obj.Spec.DockerImageRepository = repository
+		return obj
+	}
+	dc := &deployapi.DeploymentConfig{}
+	dc.Name = "ruby"
+
+	first := is("ruby", "centos/ruby-22-centos7", map[string]string{"a": "1"})
+	objects, err := DedupeObjects(kapi.Scheme, Objects{first, dc, is("ruby", "centos/ruby-22-centos7", map[string]string{"a": "1", "b": "2"}), is("mysql", "", nil)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 || objects[0] != first || objects[1] != dc {
+		t.Fatalf("unexpected objects: %#v", objects)
+	}
+	if len(first.Annotations) != 2 || first.Annotations["b"] != "2" {
+		t.Errorf("unexpected annotations: %v", first.Annotations)
+	}
+
+	if _, err := DedupeObjects(kapi.Scheme, Objects{is("ruby", "centos/ruby-22-centos7", nil), is("ruby", "openshift/ruby", nil)}); err == nil || !strings.Contains(err.Error(), "different definitions") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := DedupeObjects(kapi.Scheme, Objects{is("ruby", "", map[string]string{"a": "1"}), is("ruby", "", map[string]string{"a": "2"})}); err == nil || !strings.Contains(err.Error(), "annotation a") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
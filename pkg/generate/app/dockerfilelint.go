@@ -0,0 +1,181 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/builder/command"
+	"github.com/docker/docker/builder/parser"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// DockerfileLintRule identifies a check made against the Dockerfiles that generated builds use.
+type DockerfileLintRule string
+
+const (
+	// LintUnknownInstruction reports instructions Docker does not know.
+	LintUnknownInstruction DockerfileLintRule = "UnknownInstruction"
+	// LintMissingUser reports images that do not set a user other than root, and so run as root unless the
+	// cluster assigns a user.
+	LintMissingUser DockerfileLintRule = "MissingUser"
+	// LintAptGetWithoutClean reports RUN instructions that install packages with apt-get and leave the package
+	// lists in the image.
+	LintAptGetWithoutClean DockerfileLintRule = "AptGetWithoutClean"
+	// LintLatestBaseImage reports base images that are not pinned to a tag other than latest or to a digest.
+	LintLatestBaseImage DockerfileLintRule = "LatestBaseImage"
+)
+
+// DockerfileLintSeverity determines how the findings of a rule are handled.
+type DockerfileLintSeverity string
+
+const (
+	// DockerfileLintError fails generation.
+	DockerfileLintError DockerfileLintSeverity = "Error"
+	// DockerfileLintWarning reports the finding.
+	DockerfileLintWarning DockerfileLintSeverity = "Warning"
+	// DockerfileLintIgnore does not check the rule.
+	DockerfileLintIgnore DockerfileLintSeverity = "Ignore"
+)
+
+// DockerfileLintSeverities sets the severity of each rule.
+type DockerfileLintSeverities map[DockerfileLintRule]DockerfileLintSeverity
+
+// DefaultDockerfileLintSeverities are the severities of the rules that are not configured.
+var DefaultDockerfileLintSeverities = DockerfileLintSeverities{
+	LintUnknownInstruction: DockerfileLintWarning,
+	LintMissingUser:        DockerfileLintWarning,
+	LintAptGetWithoutClean: DockerfileLintWarning,
+	LintLatestBaseImage:    DockerfileLintWarning,
+}
+
+// Validate returns an error if the severities name an unknown rule or severity.
+func (s DockerfileLintSeverities) Validate() error {
+	for rule, severity := range s {
+		if _, ok := DefaultDockerfileLintSeverities[rule]; !ok {
+			return fmt.Errorf("unknown Dockerfile lint rule %q", rule)
+		}
+		switch severity {
+		case DockerfileLintError, DockerfileLintWarning, DockerfileLintIgnore:
+		default:
+			return fmt.Errorf("unknown severity %q for the Dockerfile lint rule %q, it must be one of %s, %s or %s", severity, rule, DockerfileLintError, DockerfileLintWarning, DockerfileLintIgnore)
+		}
+	}
+	return nil
+}
+
+// severity returns the severity of rule, falling back to DefaultDockerfileLintSeverities.
+func (s DockerfileLintSeverities) severity(rule DockerfileLintRule) DockerfileLintSeverity {
+	if severity, ok := s[rule]; ok {
+		return severity
+	}
+	return DefaultDockerfileLintSeverities[rule]
+}
+
+// DockerfileLintFinding describes an instruction of a Dockerfile that breaks a lint rule.
+type DockerfileLintFinding struct {
+	// Source is the source repository the Dockerfile was found in.
+	Source   string
+	Rule     DockerfileLintRule
+	Severity DockerfileLintSeverity
+	// Instruction is the position of the offending instruction in the Dockerfile, starting at 1, or 0 if the
+	// finding applies to the whole Dockerfile.
+	Instruction int
+	Message     string
+}
+
+func (f DockerfileLintFinding) String() string {
+	if f.Instruction > 0 {
+		return fmt.Sprintf("%s: the Dockerfile of %s, instruction %d: %s", f.Rule, f.Source, f.Instruction, f.Message)
+	}
+	return fmt.Sprintf("%s: the Dockerfile of %s: %s", f.Rule, f.Source, f.Message)
+}
+
+// dockerfileInstructions are the instructions Docker knows, including those the vendored parser predates.
+var dockerfileInstructions = map[string]bool{
+	"arg":         true,
+	"healthcheck": true,
+	"shell":       true,
+	"stopsignal":  true,
+}
+
+func init() {
+	for cmd := range command.Commands {
+		dockerfileInstructions[cmd] = true
+	}
+}
+
+// LintDockerfile checks the instructions of the Dockerfile parsed into node against the rules that are not
+// ignored by severities and returns the findings in the order of the instructions. Source names the Dockerfile in
+// the findings.
+func LintDockerfile(source string, node *parser.Node, severities DockerfileLintSeverities) []DockerfileLintFinding {
+	if node == nil {
+		return nil
+	}
+	var findings []DockerfileLintFinding
+	report := func(rule DockerfileLintRule, instruction int, format string, args ...interface{}) {
+		severity := severities.severity(rule)
+		if severity == DockerfileLintIgnore {
+			return
+		}
+		findings = append(findings, DockerfileLintFinding{
+			Source:      source,
+			Rule:        rule,
+			Severity:    severity,
+			Instruction: instruction,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+
+	user := ""
+	for i, child := range node.Children {
+		position := i + 1
+		switch cmd := strings.ToLower(child.Value); {
+		case !dockerfileInstructions[cmd]:
+			report(LintUnknownInstruction, position, "%s is not a Dockerfile instruction", strings.ToUpper(child.Value))
+		case cmd == command.From:
+			// each stage sets its own user
+			user = ""
+			if child.Next != nil && latestBaseImage(child.Next.Value) {
+				report(LintLatestBaseImage, position, "the base image %s is not pinned to a tag or digest, so builds may use different images", child.Next.Value)
+			}
+		case cmd == command.User:
+			if child.Next != nil {
+				user = child.Next.Value
+			}
+		case cmd == command.Run:
+			if run := nodeArgs(child); strings.Contains(run, "apt-get install") && !strings.Contains(run, "apt-get clean") && !strings.Contains(run, "/var/lib/apt/lists") {
+				report(LintAptGetWithoutClean, position, "packages are installed with apt-get without removing the package lists in the same instruction")
+			}
+		}
+	}
+	switch user {
+	case "":
+		report(LintMissingUser, 0, "no USER is set, so the image runs as root unless the cluster assigns a user")
+	case "root", "0", "root:root", "0:0":
+		report(LintMissingUser, 0, "the image runs as root")
+	}
+	return findings
+}
+
+// latestBaseImage returns true if image names neither a digest nor a tag other than latest. The scratch image
+// and images that cannot be parsed are not reported.
+func latestBaseImage(image string) bool {
+	if image == "scratch" {
+		return false
+	}
+	ref, err := imageapi.ParseDockerImageReference(image)
+	if err != nil {
+		return false
+	}
+	return len(ref.ID) == 0 && (len(ref.Tag) == 0 || ref.Tag == imageapi.DefaultImageTag)
+}
+
+// nodeArgs returns the arguments of an instruction joined by spaces.
+func nodeArgs(node *parser.Node) string {
+	var args []string
+	for n := node.Next; n != nil; n = n.Next {
+		args = append(args, n.Value)
+	}
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLintDockerfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		dockerfile string
+		severities DockerfileLintSeverities
+		expected   []DockerfileLintFinding
+	}{
+		{
+			name:       "clean",
+			dockerfile: "FROM centos:7\nARG VERSION\nRUN apt-get update && apt-get install -y curl && rm -rf /var/lib/apt/lists/*\nUSER 1001\n",
+		},
+		{
+			name:       "all rules",
+			dockerfile: "FROM ubuntu\nFOO bar\nRUN apt-get update && apt-get install -y curl\nUSER 1001\nFROM centos:latest\nUSER root\n",
+			expected: []DockerfileLintFinding{
+				{Source: "repo", Rule: LintLatestBaseImage, Severity: DockerfileLintWarning, Instruction: 1, Message: "the base image ubuntu is not pinned to a tag or digest, so builds may use different images"},
+				{Source: "repo", Rule: LintUnknownInstruction, Severity: DockerfileLintWarning, Instruction: 2, Message: "FOO is not a Dockerfile instruction"},
+				{Source: "repo", Rule: LintAptGetWithoutClean, Severity: DockerfileLintWarning, Instruction: 3, Message: "packages are installed with apt-get without removing the package lists in the same instruction"},
+				{Source: "repo", Rule: LintLatestBaseImage, Severity: DockerfileLintWarning, Instruction: 5, Message: "the base image centos:latest is not pinned to a tag or digest, so builds may use different images"},
+				{Source: "repo", Rule: LintMissingUser, Severity: DockerfileLintWarning, Message: "the image runs as root"},
+			},
+		},
+		{
+			name:       "configured severities",
+			dockerfile: "FROM centos@sha256:0000000000000000000000000000000000000000000000000000000000000000\nFOO bar\n",
+			severities: DockerfileLintSeverities{LintUnknownInstruction: DockerfileLintIgnore, LintMissingUser: DockerfileLintError},
+			expected: []DockerfileLintFinding{
+				{Source: "repo", Rule: LintMissingUser, Severity: DockerfileLintError, Message: "no USER is set, so the image runs as root unless the cluster assigns a user"},
+			},
+		},
+	}
+	for _, test := range tests {
+		dockerfile, err := NewDockerfile(test.dockerfile)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		findings := LintDockerfile("repo", dockerfile.AST(), test.severities)
+		if !reflect.DeepEqual(findings, test.expected) {
+			t.Errorf("%s: unexpected findings:\n%#v", test.name, findings)
+		}
+	}
+}
+
+func TestDockerfileLintSeveritiesValidate(t *testing.T) {
+	if err := (DockerfileLintSeverities{LintMissingUser: DockerfileLintIgnore}).Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (DockerfileLintSeverities{"Unknown": DockerfileLintIgnore}).Validate(); err == nil {
+		t.Errorf("expected an unknown rule to be rejected")
+	}
+	if err := (DockerfileLintSeverities{LintMissingUser: "Fatal"}).Validate(); err == nil {
+		t.Errorf("expected an unknown severity to be rejected")
+	}
+}
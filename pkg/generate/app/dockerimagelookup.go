@@ -137,6 +137,15 @@ func (r DockerClientSearcher) Search(precise bool, terms ...string) (ComponentMa
 	return componentMatches, errs
 }
 
+// Capabilities returns the capabilities of the local Docker daemon, merged with those of the registry searcher.
+func (r DockerClientSearcher) Capabilities() SearcherCapabilities {
+	capabilities := SearcherCapabilities{SupportsTags: true, SupportsMetadata: true}
+	if r.RegistrySearcher != nil {
+		capabilities = capabilities.Merge(r.RegistrySearcher.Capabilities())
+	}
+	return capabilities
+}
+
 // MissingImageSearcher always returns an exact match for the item being searched for.
 // It should be used with very high weight(weak priority) as a result of last resort when the
 // user has indicated they want to allow missing images(not found in the docker registry
@@ -158,6 +167,11 @@ func (r MissingImageSearcher) Search(precise bool, terms ...string) (ComponentMa
 	return componentMatches, nil
 }
 
+// Capabilities returns no capabilities, the matches are made up from the terms.
+func (r MissingImageSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
+
 type ImageImportSearcher struct {
 	Client        client.ImageStreamInterface
 	AllowInsecure bool
@@ -220,6 +234,7 @@ func (s ImageImportSearcher) Search(precise bool, terms ...string) (ComponentMat
 			Score:       0,
 			Image:       &image.Image.DockerImageMetadata,
 			ImageTag:    ref.Tag,
+			ImageID:     image.Image.Name,
 			Insecure:    s.AllowInsecure,
 			Meta:        map[string]string{"registry": ref.Registry, "direct-tag": "1"},
 		}
@@ -229,6 +244,12 @@ func (s ImageImportSearcher) Search(precise bool, terms ...string) (ComponentMat
 	return componentMatches, errs
 }
 
+// Capabilities returns the capabilities of the server, which imports images with the secrets of the namespace.
+// The capabilities of the fallback searcher are not included, it is only used by servers that cannot import.
+func (s ImageImportSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{SupportsTags: true, SupportsMetadata: true, RequiresNetwork: true, Authenticated: true}
+}
+
 // DockerRegistrySearcher searches for images in a given docker registry.
 // Notice that it only matches exact searches - so a search for "rub" will
 // not return images with the name "ruby".
@@ -236,6 +257,13 @@ func (s ImageImportSearcher) Search(precise bool, terms ...string) (ComponentMat
 type DockerRegistrySearcher struct {
 	Client        dockerregistry.Client
 	AllowInsecure bool
+	// Authenticated is set if the client sends credentials to registries.
+	Authenticated bool
+}
+
+// Capabilities returns the capabilities of the registries.
+func (r DockerRegistrySearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{SupportsTags: true, SupportsMetadata: true, RequiresNetwork: true, Authenticated: r.Authenticated}
 }
 
 // Search searches in the Docker registry for images that match terms
@@ -260,6 +288,11 @@ func (r DockerRegistrySearcher) Search(precise bool, terms ...string) (Component
 		}
 
 		image, err := connection.ImageByTag(ref.Namespace, ref.Name, ref.Tag)
+		if err, ok := err.(dockerregistry.NotAnImageError); ok {
+			glog.V(4).Infof("not an image: %v", err)
+			errs = append(errs, ErrNotAnImage{Value: term, Kind: err.Kind, MediaType: err.MediaType})
+			continue
+		}
 		if err != nil {
 			if dockerregistry.IsNotFound(err) {
 				if dockerregistry.IsTagNotFound(err) {
@@ -284,6 +317,7 @@ func (r DockerRegistrySearcher) Search(precise bool, terms ...string) (Component
 			errs = append(errs, err)
 			continue
 		}
+		dockerImage.OS = image.OS
 
 		match := &ComponentMatch{
 			Value:       term,
@@ -352,7 +386,7 @@ func matchTag(image docker.APIImages, value, registry, namespace, name, tag stri
 		match.Score += score
 		_, score = partialScorer(namespace, iRef.Namespace, false, 0.5, 1.0)
 		match.Score += score
-		_, score = partialScorer(registry, iRef.Registry, false, 0.5, 1.0)
+		_, score = partialScorer(imageapi.NormalizeRegistryHost(registry), imageapi.NormalizeRegistryHost(iRef.Registry), false, 0.5, 1.0)
 		match.Score += score
 		_, score = partialScorer(tag, iRef.Tag, false, 0.5, 1.0)
 		match.Score += score
@@ -386,3 +420,8 @@ func (r *PassThroughDockerSearcher) Search(precise bool, terms ...string) (Compo
 	}
 	return matches, nil
 }
+
+// Capabilities returns no capabilities, the matches are made up from the terms.
+func (r *PassThroughDockerSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"testing"
+
+	docker "github.com/fsouza/go-dockerclient"
+
+	"github.com/openshift/origin/pkg/dockerregistry"
+)
+
+type fakeRegistryClient struct {
+	registries []string
+	images     map[string]*dockerregistry.Image
+}
+
+func (c *fakeRegistryClient) Connect(registry string, allowInsecure bool) (dockerregistry.Connection, error) {
+	c.registries = append(c.registries, registry)
+	return c, nil
+}
+
+func (c *fakeRegistryClient) ImageTags(namespace, name string) (map[string]string, error) {
+	return nil, dockerregistry.NewImageNotFoundError(namespace+"/"+name, "", "")
+}
+
+func (c *fakeRegistryClient) ImageByID(namespace, name, id string) (*dockerregistry.Image, error) {
+	return c.ImageByTag(namespace, name, id)
+}
+
+func (c *fakeRegistryClient) ImageByTag(namespace, name, tag string) (*dockerregistry.Image, error) {
+	if image, ok := c.images[namespace+"/"+name+":"+tag]; ok {
+		return image, nil
+	}
+	return nil, dockerregistry.NewImageNotFoundError(namespace+"/"+name, tag, tag)
+}
+
+func TestDockerRegistrySearcherPathScopedRegistry(t *testing.T) {
+	client := &fakeRegistryClient{images: map[string]*dockerregistry.Image{
+		"docker-virtual/team/app:1.0": {Image: docker.Image{ID: "1234567890"}},
+	}}
+	searcher := DockerRegistrySearcher{Client: client}
+	matches, errs := searcher.Search(true, "artifactory.example.com/docker-virtual/team/app:1.0", "artifactory.example.com/docker-virtual/team/other:1.0")
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	if len(matches) != 1 || matches[0].Name != "artifactory.example.com/docker-virtual/team/app:1.0" || matches[0].ImageTag != "1.0" {
+		t.Fatalf("unexpected matches: %#v", matches)
+	}
+	if matches[0].Meta["registry"] != "artifactory.example.com" {
+		t.Errorf("unexpected registry: %v", matches[0].Meta)
+	}
+	if len(client.registries) != 2 || client.registries[0] != "artifactory.example.com" {
+		t.Errorf("unexpected registries: %v", client.registries)
+	}
+}
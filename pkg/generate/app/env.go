@@ -1,14 +1,106 @@
 package app
 
 import (
+	"fmt"
 	"sort"
+	"strings"
 
 	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
 )
 
 // Environment holds environment variables for new-app
 type Environment map[string]string
 
+// The prefixes of the values of environment variables given to new-app that reference a source instead of a
+// literal value. A backslash before a prefix makes the rest of the value a literal.
+const (
+	EnvSecretPrefix    = "secret:"
+	EnvConfigMapPrefix = "config:"
+	EnvFieldPrefix     = "field:"
+)
+
+// ParseEnvVarSource parses the value of an environment variable given to new-app. It returns the source the
+// value references if it is of the form secret:NAME:KEY, config:NAME:KEY or field:PATH, or else the literal
+// value, without the backslash that escapes one of these prefixes.
+func ParseEnvVarSource(value string) (*kapi.EnvVarSource, string, error) {
+	switch {
+	case strings.HasPrefix(value, EnvSecretPrefix):
+		name, key, err := parseEnvKeyReference(strings.TrimPrefix(value, EnvSecretPrefix))
+		if err != nil {
+			return nil, "", fmt.Errorf("secret references must be of the form %sNAME:KEY: %v", EnvSecretPrefix, err)
+		}
+		return &kapi.EnvVarSource{
+			SecretKeyRef: &kapi.SecretKeySelector{LocalObjectReference: kapi.LocalObjectReference{Name: name}, Key: key},
+		}, "", nil
+	case strings.HasPrefix(value, EnvConfigMapPrefix):
+		name, key, err := parseEnvKeyReference(strings.TrimPrefix(value, EnvConfigMapPrefix))
+		if err != nil {
+			return nil, "", fmt.Errorf("config map references must be of the form %sNAME:KEY: %v", EnvConfigMapPrefix, err)
+		}
+		return &kapi.EnvVarSource{
+			ConfigMapKeyRef: &kapi.ConfigMapKeySelector{LocalObjectReference: kapi.LocalObjectReference{Name: name}, Key: key},
+		}, "", nil
+	case strings.HasPrefix(value, EnvFieldPrefix):
+		path := strings.TrimPrefix(value, EnvFieldPrefix)
+		if len(path) == 0 {
+			return nil, "", fmt.Errorf("field references must be of the form %sPATH", EnvFieldPrefix)
+		}
+		return &kapi.EnvVarSource{
+			FieldRef: &kapi.ObjectFieldSelector{APIVersion: "v1", FieldPath: path},
+		}, "", nil
+	}
+	for _, prefix := range []string{EnvSecretPrefix, EnvConfigMapPrefix, EnvFieldPrefix} {
+		if strings.HasPrefix(value, `\`+prefix) {
+			return nil, value[1:], nil
+		}
+	}
+	return nil, value, nil
+}
+
+// parseEnvKeyReference splits a NAME:KEY reference.
+func parseEnvKeyReference(s string) (string, string, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return "", "", fmt.Errorf("%q is not a valid reference", s)
+	}
+	return parts[0], parts[1], nil
+}
+
+// SetEnvironmentSources sets the environment variables in sources, whose values come from a secret, a config
+// map or a field of the pod, on the containers of the deployment configs in objects.
+func SetEnvironmentSources(objects Objects, sources map[string]*kapi.EnvVarSource) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, obj := range objects {
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil {
+			continue
+		}
+		for i := range dc.Spec.Template.Spec.Containers {
+			container := &dc.Spec.Template.Spec.Containers[i]
+			for _, name := range names {
+				container.Env = setEnvVar(container.Env, kapi.EnvVar{Name: name, ValueFrom: sources[name]})
+			}
+		}
+	}
+}
+
+// setEnvVar replaces the variable of env with the name of v, or appends v.
+func setEnvVar(env []kapi.EnvVar, v kapi.EnvVar) []kapi.EnvVar {
+	for i := range env {
+		if env[i].Name == v.Name {
+			env[i] = v
+			return env
+		}
+	}
+	return append(env, v)
+}
+
 // NewEnvironment returns a new set of environment variables based on all
 // the provided environment variables
 func NewEnvironment(envs ...map[string]string) Environment {
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestEnvironmentListLiterals(t *testing.T) {
+	env := Environment{"PASSWORD": "secret:db:password", "PLAIN": "value"}
+	expected := []kapi.EnvVar{
+		{Name: "PASSWORD", Value: "secret:db:password"},
+		{Name: "PLAIN", Value: "value"},
+	}
+	if list := env.List(); !reflect.DeepEqual(list, expected) {
+		t.Errorf("unexpected list: %#v", list)
+	}
+}
+
+func TestParseEnvVarSource(t *testing.T) {
+	tests := []struct {
+		value   string
+		source  *kapi.EnvVarSource
+		literal string
+	}{
+		{value: "secret:db:password", source: &kapi.EnvVarSource{SecretKeyRef: &kapi.SecretKeySelector{LocalObjectReference: kapi.LocalObjectReference{Name: "db"}, Key: "password"}}},
+		{value: "config:settings:log-level", source: &kapi.EnvVarSource{ConfigMapKeyRef: &kapi.ConfigMapKeySelector{LocalObjectReference: kapi.LocalObjectReference{Name: "settings"}, Key: "log-level"}}},
+		{value: "field:metadata.namespace", source: &kapi.EnvVarSource{FieldRef: &kapi.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"}}},
+		{value: "value:with:colons", literal: "value:with:colons"},
+		{value: `\secret:not:a-reference`, literal: "secret:not:a-reference"},
+		{value: `\field:`, literal: "field:"},
+		{value: `\\server\share`, literal: `\\server\share`},
+	}
+	for _, test := range tests {
+		source, literal, err := ParseEnvVarSource(test.value)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.value, err)
+			continue
+		}
+		if !reflect.DeepEqual(source, test.source) || literal != test.literal {
+			t.Errorf("%q: unexpected result: %#v %q", test.value, source, literal)
+		}
+	}
+	for _, value := range []string{"secret:", "secret:name", "secret:name:", "config::key", "config:a:b:c", "field:"} {
+		if _, _, err := ParseEnvVarSource(value); err == nil {
+			t.Errorf("%q: expected an error", value)
+		}
+	}
+}
+
+func TestSetEnvironmentSources(t *testing.T) {
+	dc := &deployapi.DeploymentConfig{Spec: deployapi.DeploymentConfigSpec{Template: &kapi.PodTemplateSpec{Spec: kapi.PodSpec{
+		Containers: []kapi.Container{{Env: []kapi.EnvVar{{Name: "PASSWORD", Value: "changeme"}, {Name: "PLAIN", Value: "value"}}}},
+	}}}}
+	secret := &kapi.EnvVarSource{SecretKeyRef: &kapi.SecretKeySelector{LocalObjectReference: kapi.LocalObjectReference{Name: "db"}, Key: "password"}}
+	field := &kapi.EnvVarSource{FieldRef: &kapi.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"}}
+	SetEnvironmentSources(Objects{dc, &kapi.Service{}}, map[string]*kapi.EnvVarSource{"PASSWORD": secret, "NAMESPACE": field})
+	expected := []kapi.EnvVar{
+		{Name: "PASSWORD", ValueFrom: secret},
+		{Name: "PLAIN", Value: "value"},
+		{Name: "NAMESPACE", ValueFrom: field},
+	}
+	if env := dc.Spec.Template.Spec.Containers[0].Env; !reflect.DeepEqual(env, expected) {
+		t.Errorf("unexpected environment: %#v", env)
+	}
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+// EnvironmentLabel is set on every object generated for a TargetEnvironment.
+const EnvironmentLabel = "environment"
+
+// TargetEnvironment describes one environment (for instance dev, staging or prod) that an application is
+// generated for.
+type TargetEnvironment struct {
+	// Name is appended to the names of the objects generated for this environment.
+	Name string
+	// Replicas overrides the replicas of generated deployment configs if set.
+	Replicas *int
+	// Limits overrides the resource limits of every generated container if set.
+	Limits kapi.ResourceList
+	// Hostname is set on generated routes. If there are several routes, the route name is prepended.
+	Hostname string
+	// ImageTag, if set, is the tag of the generated image streams that deployments in this environment
+	// are triggered from, which allows images to be promoted between environments by tagging.
+	ImageTag string
+}
+
+// ForEnvironments returns a variant of the deployment configs, services, routes and persistent volume claims
+// in objects for each environment, with the environment name appended to their names. Build configs and image
+// streams are shared by all environments and are returned once.
+func ForEnvironments(objects Objects, environments []TargetEnvironment) (Objects, error) {
+	names := sets.NewString()
+	for _, env := range environments {
+		if len(env.Name) == 0 {
+			return nil, fmt.Errorf("every environment must have a name")
+		}
+		if names.Has(env.Name) {
+			return nil, fmt.Errorf("the environment %q is specified more than once", env.Name)
+		}
+		names.Insert(env.Name)
+	}
+
+	shared, variable := Objects{}, Objects{}
+	streams, deployments, services, claims := sets.NewString(), sets.NewString(), sets.NewString(), sets.NewString()
+	routes := 0
+	for _, obj := range objects {
+		switch t := obj.(type) {
+		case *deployapi.DeploymentConfig:
+			deployments.Insert(t.Name)
+			variable = append(variable, obj)
+		case *kapi.Service:
+			services.Insert(t.Name)
+			variable = append(variable, obj)
+		case *routeapi.Route:
+			routes++
+			variable = append(variable, obj)
+		case *kapi.PersistentVolumeClaim:
+			claims.Insert(t.Name)
+			variable = append(variable, obj)
+		case *imageapi.ImageStream:
+			streams.Insert(t.Name)
+			shared = append(shared, obj)
+		default:
+			shared = append(shared, obj)
+		}
+	}
+
+	result := append(Objects{}, shared...)
+	for _, env := range environments {
+		r := environmentRenamer{env: env, streams: streams, deployments: deployments, services: services, claims: claims}
+		for _, obj := range variable {
+			copied, err := kapi.Scheme.DeepCopy(obj)
+			if err != nil {
+				return nil, err
+			}
+			obj := copied.(runtime.Object)
+			r.apply(obj, routes > 1)
+			result = append(result, obj)
+		}
+	}
+	return result, nil
+}
+
+// environmentRenamer rewrites a copy of a generated object for an environment, including its references to the
+// other generated objects.
+type environmentRenamer struct {
+	env         TargetEnvironment
+	streams     sets.String
+	deployments sets.String
+	services    sets.String
+	claims      sets.String
+}
+
+func (r environmentRenamer) name(name string) string {
+	return fmt.Sprintf("%s-%s", name, r.env.Name)
+}
+
+// selector renames the deploymentconfig selector if it refers to a generated deployment config and restricts
+// the selector to the environment.
+func (r environmentRenamer) selector(selector map[string]string) map[string]string {
+	if selector == nil {
+		selector = map[string]string{}
+	}
+	if name, ok := selector["deploymentconfig"]; ok && r.deployments.Has(name) {
+		selector["deploymentconfig"] = r.name(name)
+	}
+	selector[EnvironmentLabel] = r.env.Name
+	return selector
+}
+
+func (r environmentRenamer) meta(meta *kapi.ObjectMeta) {
+	meta.Name = r.name(meta.Name)
+	if meta.Labels == nil {
+		meta.Labels = map[string]string{}
+	}
+	meta.Labels[EnvironmentLabel] = r.env.Name
+}
+
+func (r environmentRenamer) apply(obj runtime.Object, multipleRoutes bool) {
+	switch t := obj.(type) {
+	case *deployapi.DeploymentConfig:
+		r.meta(&t.ObjectMeta)
+		t.Spec.Selector = r.selector(t.Spec.Selector)
+		if r.env.Replicas != nil {
+			t.Spec.Replicas = *r.env.Replicas
+		}
+		for i := range t.Spec.Triggers {
+			params := t.Spec.Triggers[i].ImageChangeParams
+			if params == nil || len(r.env.ImageTag) == 0 {
+				continue
+			}
+			if params.From.Kind != "ImageStreamTag" || len(params.From.Namespace) > 0 {
+				continue
+			}
+			if name, _, ok := imageapi.SplitImageStreamTag(params.From.Name); ok && r.streams.Has(name) {
+				params.From.Name = imageapi.JoinImageStreamTag(name, r.env.ImageTag)
+			}
+		}
+		if t.Spec.Template == nil {
+			return
+		}
+		t.Spec.Template.Labels = r.selector(t.Spec.Template.Labels)
+		spec := &t.Spec.Template.Spec
+		for i := range spec.Volumes {
+			// claims that were not generated are shared by all environments
+			if claim := spec.Volumes[i].PersistentVolumeClaim; claim != nil && r.claims.Has(claim.ClaimName) {
+				claim.ClaimName = r.name(claim.ClaimName)
+			}
+		}
+		if len(r.env.Limits) > 0 {
+			for i := range spec.Containers {
+				limits := kapi.ResourceList{}
+				for k, v := range r.env.Limits {
+					limits[k] = *v.Copy()
+				}
+				spec.Containers[i].Resources.Limits = limits
+			}
+		}
+	case *kapi.Service:
+		r.meta(&t.ObjectMeta)
+		t.Spec.Selector = r.selector(t.Spec.Selector)
+	case *routeapi.Route:
+		name := t.Name
+		r.meta(&t.ObjectMeta)
+		if r.services.Has(t.Spec.To.Name) {
+			t.Spec.To.Name = r.name(t.Spec.To.Name)
+		}
+		if len(r.env.Hostname) > 0 {
+			t.Spec.Host = r.env.Hostname
+			if multipleRoutes {
+				t.Spec.Host = fmt.Sprintf("%s.%s", name, r.env.Hostname)
+			}
+		}
+	case *kapi.PersistentVolumeClaim:
+		r.meta(&t.ObjectMeta)
+	}
+}
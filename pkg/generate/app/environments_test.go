@@ -0,0 +1,126 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+func environmentTestObjects() Objects {
+	selector := map[string]string{"deploymentconfig": "web"}
+	return Objects{
+		&imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "web"}},
+		&deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: "web"},
+			Spec: deployapi.DeploymentConfigSpec{
+				Replicas: 1,
+				Selector: selector,
+				Triggers: []deployapi.DeploymentTriggerPolicy{{
+					Type: deployapi.DeploymentTriggerOnImageChange,
+					ImageChangeParams: &deployapi.DeploymentTriggerImageChangeParams{
+						From: kapi.ObjectReference{Kind: "ImageStreamTag", Name: "web:latest"},
+					},
+				}},
+				Template: &kapi.PodTemplateSpec{
+					ObjectMeta: kapi.ObjectMeta{Labels: map[string]string{"deploymentconfig": "web"}},
+					Spec:       kapi.PodSpec{Containers: []kapi.Container{{Name: "web"}}},
+				},
+			},
+		},
+		&kapi.Service{
+			ObjectMeta: kapi.ObjectMeta{Name: "web"},
+			Spec:       kapi.ServiceSpec{Selector: map[string]string{"deploymentconfig": "web"}},
+		},
+		&routeapi.Route{
+			ObjectMeta: kapi.ObjectMeta{Name: "web"},
+			Spec:       routeapi.RouteSpec{To: kapi.ObjectReference{Name: "web"}},
+		},
+	}
+}
+
+func TestForEnvironments(t *testing.T) {
+	replicas := 3
+	objects, err := ForEnvironments(environmentTestObjects(), []TargetEnvironment{
+		{Name: "dev"},
+		{
+			Name:     "prod",
+			Replicas: &replicas,
+			Limits:   kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("2")},
+			Hostname: "web.example.com",
+			ImageTag: "prod",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objects) != 7 {
+		t.Fatalf("expected one image stream and three objects per environment, got %d", len(objects))
+	}
+
+	dc := objects[4].(*deployapi.DeploymentConfig)
+	if dc.Name != "web-prod" || dc.Spec.Replicas != 3 {
+		t.Errorf("unexpected deployment config: %#v", dc)
+	}
+	if dc.Spec.Selector["deploymentconfig"] != "web-prod" || dc.Spec.Selector[EnvironmentLabel] != "prod" {
+		t.Errorf("unexpected selector: %#v", dc.Spec.Selector)
+	}
+	if from := dc.Spec.Triggers[0].ImageChangeParams.From.Name; from != "web:prod" {
+		t.Errorf("unexpected trigger: %s", from)
+	}
+	if _, ok := dc.Spec.Template.Spec.Containers[0].Resources.Limits[kapi.ResourceCPU]; !ok {
+		t.Errorf("expected the environment limits to be set")
+	}
+	if svc := objects[5].(*kapi.Service); svc.Spec.Selector["deploymentconfig"] != "web-prod" {
+		t.Errorf("unexpected service selector: %#v", svc.Spec.Selector)
+	}
+	route := objects[6].(*routeapi.Route)
+	if route.Spec.To.Name != "web-prod" || route.Spec.Host != "web.example.com" {
+		t.Errorf("unexpected route: %#v", route.Spec)
+	}
+
+	dev := objects[1].(*deployapi.DeploymentConfig)
+	if dev.Name != "web-dev" || dev.Spec.Replicas != 1 || dev.Spec.Triggers[0].ImageChangeParams.From.Name != "web:latest" {
+		t.Errorf("unexpected dev deployment config: %#v", dev)
+	}
+}
+
+func TestForEnvironmentsClaims(t *testing.T) {
+	objects := environmentTestObjects()
+	objects = append(objects, &kapi.PersistentVolumeClaim{ObjectMeta: kapi.ObjectMeta{Name: "web-data"}})
+	spec := &objects[1].(*deployapi.DeploymentConfig).Spec.Template.Spec
+	for _, claim := range []string{"web-data", "existing"} {
+		spec.Volumes = append(spec.Volumes, kapi.Volume{
+			Name:         claim,
+			VolumeSource: kapi.VolumeSource{PersistentVolumeClaim: &kapi.PersistentVolumeClaimVolumeSource{ClaimName: claim}},
+		})
+	}
+
+	objects, err := ForEnvironments(objects, []TargetEnvironment{{Name: "dev"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	volumes := objects[1].(*deployapi.DeploymentConfig).Spec.Template.Spec.Volumes
+	if claim := volumes[0].PersistentVolumeClaim.ClaimName; claim != "web-data-dev" {
+		t.Errorf("expected the generated claim to be renamed: %s", claim)
+	}
+	if claim := volumes[1].PersistentVolumeClaim.ClaimName; claim != "existing" {
+		t.Errorf("expected a claim that was not generated to be kept: %s", claim)
+	}
+	if claim := objects[4].(*kapi.PersistentVolumeClaim); claim.Name != "web-data-dev" {
+		t.Errorf("unexpected claim: %s", claim.Name)
+	}
+}
+
+func TestForEnvironmentsInvalid(t *testing.T) {
+	if _, err := ForEnvironments(environmentTestObjects(), []TargetEnvironment{{Name: "dev"}, {Name: "dev"}}); err == nil {
+		t.Errorf("expected an error for duplicate environments")
+	}
+	if _, err := ForEnvironments(environmentTestObjects(), []TargetEnvironment{{}}); err == nil {
+		t.Errorf("expected an error for an unnamed environment")
+	}
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// EOLWarningPeriod is how long before the end of life of a runtime generation starts warning about it.
+const EOLWarningPeriod = 90 * 24 * time.Hour
+
+// RuntimeEOL records the end of life of a version of a language runtime.
+type RuntimeEOL struct {
+	// Platform is the language, as detected in source, such as nodejs.
+	Platform string `json:"platform"`
+	// Version is the version of the runtime. It matches the versions it is a prefix of, component by
+	// component, so that 0.10 matches 0.10.48 but not 0.1.
+	Version string `json:"version"`
+	// Date is when the runtime reaches its end of life.
+	Date time.Time `json:"date"`
+	// Replacement, if set, is the version users are advised to upgrade to.
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// EOLTable is a table of runtime end of life dates that generation consults for the versions detected in source
+// and declared by builder images.
+type EOLTable []RuntimeEOL
+
+func eolDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// DefaultEOLTable lists the end of life of the runtimes supported by the default builder images.
+var DefaultEOLTable = EOLTable{
+	{Platform: "nodejs", Version: "0.10", Date: eolDate(2016, time.October, 31), Replacement: "4"},
+	{Platform: "nodejs", Version: "0.12", Date: eolDate(2016, time.December, 31), Replacement: "4"},
+	{Platform: "ruby", Version: "2.0", Date: eolDate(2016, time.February, 24), Replacement: "2.3"},
+	{Platform: "ruby", Version: "2.1", Date: eolDate(2017, time.March, 31), Replacement: "2.3"},
+	{Platform: "python", Version: "3.3", Date: eolDate(2017, time.September, 29), Replacement: "3.5"},
+	{Platform: "php", Version: "5.5", Date: eolDate(2016, time.July, 21), Replacement: "5.6"},
+}
+
+// ReadEOLTable reads a JSON list of RuntimeEOL entries from r, for platform teams that maintain their own
+// table.
+func ReadEOLTable(r io.Reader) (EOLTable, error) {
+	table := EOLTable{}
+	if err := json.NewDecoder(r).Decode(&table); err != nil {
+		return nil, fmt.Errorf("unable to read the runtime end of life table: %v", err)
+	}
+	for _, eol := range table {
+		if len(eol.Platform) == 0 || len(eol.Version) == 0 || eol.Date.IsZero() {
+			return nil, fmt.Errorf("every runtime end of life entry must have a platform, a version and a date")
+		}
+	}
+	return table, nil
+}
+
+// Lookup returns the entry of the table that matches version of platform.
+func (t EOLTable) Lookup(platform, version string) (RuntimeEOL, bool) {
+	for _, eol := range t {
+		if eol.Platform == platform && versionHasPrefix(version, eol.Version) {
+			return eol, true
+		}
+	}
+	return RuntimeEOL{}, false
+}
+
+// versionHasPrefix returns true if the dot separated components of prefix are the first components of
+// version.
+func versionHasPrefix(version, prefix string) bool {
+	if len(version) == 0 {
+		return false
+	}
+	v, p := strings.Split(version, "."), strings.Split(prefix, ".")
+	if len(p) > len(v) {
+		return false
+	}
+	for i := range p {
+		if v[i] != p[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RuntimeEOLWarning describes a runtime that an application is generated for that has reached, or is about to
+// reach, its end of life.
+type RuntimeEOLWarning struct {
+	// Source is the source repository or builder image the runtime was found in.
+	Source string
+	// Platform and Version identify the runtime.
+	Platform string
+	Version  string
+	// Date is the end of life of the runtime.
+	Date time.Time
+	// Replacement, if set, is the version users are advised to upgrade to.
+	Replacement string
+	// Expired is true if the end of life is in the past.
+	Expired bool
+}
+
+func (w RuntimeEOLWarning) String() string {
+	message := fmt.Sprintf("%s %s used by %s reaches its end of life on %s", w.Platform, w.Version, w.Source, w.Date.Format("2006-01-02"))
+	if w.Expired {
+		message = fmt.Sprintf("%s %s used by %s reached its end of life on %s", w.Platform, w.Version, w.Source, w.Date.Format("2006-01-02"))
+	}
+	if len(w.Replacement) > 0 {
+		message = fmt.Sprintf("%s, consider upgrading to %s", message, w.Replacement)
+	}
+	return message
+}
+
+// CheckRuntimeEOL returns a warning for each runtime version detected in the source described by info, or
+// declared by the builder of match, that table lists as reaching its end of life before now plus
+// EOLWarningPeriod.
+func CheckRuntimeEOL(table EOLTable, now time.Time, source string, match *ComponentMatch, info *SourceRepositoryInfo) []RuntimeEOLWarning {
+	type runtime struct {
+		source, platform, version string
+	}
+	runtimes := []runtime{}
+	if info != nil {
+		for _, t := range info.Types {
+			runtimes = append(runtimes, runtime{source: source, platform: t.Platform, version: t.Version})
+		}
+	}
+	if match != nil {
+		for _, term := range BuilderSupports(match) {
+			if parts := strings.SplitN(term, ":", 2); len(parts) == 2 {
+				runtimes = append(runtimes, runtime{source: match.Name, platform: parts[0], version: parts[1]})
+			}
+		}
+	}
+
+	var warnings []RuntimeEOLWarning
+	seen := make(map[string]bool)
+	for _, r := range runtimes {
+		eol, ok := table.Lookup(r.platform, r.version)
+		key := r.platform + ":" + r.version
+		if !ok || seen[key] || eol.Date.After(now.Add(EOLWarningPeriod)) {
+			continue
+		}
+		seen[key] = true
+		warnings = append(warnings, RuntimeEOLWarning{
+			Source:      r.source,
+			Platform:    r.platform,
+			Version:     r.version,
+			Date:        eol.Date,
+			Replacement: eol.Replacement,
+			Expired:     !eol.Date.After(now),
+		})
+	}
+	return warnings
+}
@@ -0,0 +1,61 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestCheckRuntimeEOL(t *testing.T) {
+	now := time.Date(2016, time.November, 15, 0, 0, 0, 0, time.UTC)
+	info := &SourceRepositoryInfo{Types: []SourceLanguageType{{Platform: "nodejs", Version: "0.10.48"}, {Platform: "nodejs", Version: "4"}}}
+	match := &ComponentMatch{
+		Name:     "openshift/nodejs:0.12",
+		ImageTag: "0.12",
+		ImageStream: &imageapi.ImageStream{
+			ObjectMeta: kapi.ObjectMeta{Name: "nodejs", Namespace: "openshift"},
+			Spec: imageapi.ImageStreamSpec{Tags: map[string]imageapi.TagReference{
+				"0.12": {Annotations: map[string]string{supportsAnnotationKey: "nodejs:0.12,nodejs"}},
+			}},
+		},
+	}
+	warnings := CheckRuntimeEOL(DefaultEOLTable, now, "https://github.com/openshift/nodejs-ex", match, info)
+	if len(warnings) != 2 {
+		t.Fatalf("unexpected warnings: %#v", warnings)
+	}
+	if w := warnings[0]; w.Version != "0.10.48" || !w.Expired || w.Source != "https://github.com/openshift/nodejs-ex" {
+		t.Errorf("unexpected warning: %#v", w)
+	}
+	if w := warnings[1]; w.Version != "0.12" || w.Expired || w.Source != "openshift/nodejs:0.12" || w.Replacement != "4" {
+		t.Errorf("unexpected warning: %#v", w)
+	}
+	if s := warnings[1].String(); !strings.Contains(s, "reaches its end of life on 2016-12-31, consider upgrading to 4") {
+		t.Errorf("unexpected message: %s", s)
+	}
+
+	if warnings := CheckRuntimeEOL(DefaultEOLTable, now.AddDate(-1, 0, 0), "source", match, info); len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %#v", warnings)
+	}
+}
+
+func TestReadEOLTable(t *testing.T) {
+	table, err := ReadEOLTable(strings.NewReader(`[{"platform":"ruby","version":"2.2","date":"2018-03-31T00:00:00Z"}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := table.Lookup("ruby", "2.2.5"); !ok {
+		t.Errorf("expected ruby 2.2.5 to match")
+	}
+	for _, version := range []string{"2", "2.20", ""} {
+		if _, ok := table.Lookup("ruby", version); ok {
+			t.Errorf("expected ruby %q not to match", version)
+		}
+	}
+	if _, err := ReadEOLTable(strings.NewReader(`[{"platform":"ruby"}]`)); err == nil {
+		t.Errorf("expected an incomplete entry to be rejected")
+	}
+}
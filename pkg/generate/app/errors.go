@@ -6,6 +6,7 @@ import (
 
 	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
 
+	generrors "github.com/openshift/origin/pkg/generate/errors"
 	imageapi "github.com/openshift/origin/pkg/image/api"
 )
 
@@ -15,13 +16,24 @@ type ErrNoMatch struct {
 	Value     string
 	Qualifier string
 	Errs      []error
+	// Suggestions are names close to Value that the user may have meant.
+	Suggestions []string
 }
 
 func (e ErrNoMatch) Error() string {
+	msg := fmt.Sprintf("no match for %q", e.Value)
 	if len(e.Qualifier) != 0 {
-		return fmt.Sprintf("no match for %q: %s", e.Value, e.Qualifier)
+		msg = fmt.Sprintf("%s: %s", msg, e.Qualifier)
 	}
-	return fmt.Sprintf("no match for %q", e.Value)
+	if len(e.Suggestions) != 0 {
+		msg = fmt.Sprintf("%s, did you mean %s?", msg, quotedList(e.Suggestions))
+	}
+	return msg
+}
+
+// Code returns the code of the error.
+func (e ErrNoMatch) Code() generrors.Code {
+	return generrors.CodeNoMatch
 }
 
 // UsageError is the usage error message returned when no match is found.
@@ -41,6 +53,11 @@ func (e ErrPartialMatch) Error() string {
 	return fmt.Sprintf("only a partial match was found for %q: %q", e.Value, e.Match.Name)
 }
 
+// Code returns the code of the error.
+func (e ErrPartialMatch) Code() generrors.Code {
+	return generrors.CodePartialMatch
+}
+
 // UsageError is the usage error message returned when only a partial match is
 // found.
 func (e ErrPartialMatch) Suggestion(commandName string) string {
@@ -67,9 +84,43 @@ func (e ErrMultipleMatches) Error() string {
 	return fmt.Sprintf("multiple images or templates matched %q: %d", e.Value, len(e.Matches))
 }
 
+// Code returns the code of the error.
+func (e ErrMultipleMatches) Code() generrors.Code {
+	return generrors.CodeMultipleMatches
+}
+
+// ErrNotAnImage is the error returned by new-app when a component resolves to a registry artifact, such as a
+// Helm chart or a signature, that cannot be run as a container.
+type ErrNotAnImage struct {
+	Value string
+	// Kind is a short description of the artifact.
+	Kind string
+	// MediaType is the media type that identifies the artifact.
+	MediaType string
+}
+
+func (e ErrNotAnImage) Error() string {
+	return fmt.Sprintf("%q is a %s (%s) and cannot be deployed as an image", e.Value, e.Kind, e.MediaType)
+}
+
+// Code returns the code of the error.
+func (e ErrNotAnImage) Code() generrors.Code {
+	return generrors.CodeNotAnImage
+}
+
+// noMatch returns the first ErrNotAnImage in errs, which explains why value matched nothing, or an ErrNoMatch.
+func noMatch(value string, errs []error) error {
+	for _, err := range errs {
+		if err, ok := err.(ErrNotAnImage); ok {
+			return err
+		}
+	}
+	return ErrNoMatch{Value: value, Errs: errs}
+}
+
 // ErrNameRequired is the error returned by new-app when a name cannot be
 // suggested and the user needs to provide one explicitly.
-var ErrNameRequired = fmt.Errorf("you must specify a name for your app")
+var ErrNameRequired error = generrors.New(generrors.CodeNameRequired, "you must specify a name for your app")
 
 // CircularOutputReferenceError is the error returned by new-app when the input
 // and output image stream tags are identical.
@@ -80,3 +131,8 @@ type CircularOutputReferenceError struct {
 func (e CircularOutputReferenceError) Error() string {
 	return fmt.Sprintf("the input and output image stream tags are identical (%q)", e.Reference.DockerClientDefaults())
 }
+
+// Code returns the code of the error.
+func (e CircularOutputReferenceError) Code() generrors.Code {
+	return generrors.CodeCircularReference
+}
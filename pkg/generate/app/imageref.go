@@ -147,6 +147,8 @@ type ImageRef struct {
 	InternalDefaultTag string
 
 	Env Environment
+	// PullSecrets are the names of the secrets used to pull the image.
+	PullSecrets []string
 
 	// ObjectName overrides the name of the ImageStream produced
 	// but does not affect the DockerImageReference
@@ -162,9 +164,29 @@ func (r *ImageRef) Exists() bool {
 	return r.Stream != nil
 }
 
+// pinned returns true if the ref is a single image of an existing image stream, selected by digest.
+func (r *ImageRef) pinned() bool {
+	return r.Stream != nil && len(r.Reference.ID) > 0
+}
+
+// pullSecret returns a reference to the first pull secret of the ref, which is the only one a build can use to
+// pull it.
+func (r *ImageRef) pullSecret() *kapi.LocalObjectReference {
+	if len(r.PullSecrets) == 0 {
+		return nil
+	}
+	return &kapi.LocalObjectReference{Name: r.PullSecrets[0]}
+}
+
 // ObjectReference returns an object reference to this ref (as it would exist during generation)
 func (r *ImageRef) ObjectReference() kapi.ObjectReference {
 	switch {
+	case r.pinned():
+		return kapi.ObjectReference{
+			Kind:      "ImageStreamImage",
+			Name:      r.Stream.Name + "@" + r.Reference.ID,
+			Namespace: r.Stream.Namespace,
+		}
 	case r.Stream != nil:
 		return kapi.ObjectReference{
 			Kind:      "ImageStreamTag",
@@ -255,9 +277,10 @@ func (r *ImageRef) BuildOutput() (*buildapi.BuildOutput, error) {
 	}, nil
 }
 
-// BuildTriggers sets up build triggers for the base image
+// BuildTriggers sets up build triggers for the base image. Images selected by digest never change, so
+// they have no triggers.
 func (r *ImageRef) BuildTriggers() []buildapi.BuildTriggerPolicy {
-	if r.Stream == nil && !r.AsImageStream {
+	if r.pinned() || (r.Stream == nil && !r.AsImageStream) {
 		return nil
 	}
 	return []buildapi.BuildTriggerPolicy{
@@ -322,7 +345,7 @@ func (r *ImageRef) DeployableContainer() (container *kapi.Container, triggers []
 	if !ok {
 		return nil, nil, fmt.Errorf("unable to suggest a container name for the image %q", r.Reference.String())
 	}
-	if r.AsImageStream {
+	if r.AsImageStream && !r.pinned() {
 		triggers = []deployapi.DeploymentTriggerPolicy{
 			{
 				Type: deployapi.DeploymentTriggerOnImageChange,
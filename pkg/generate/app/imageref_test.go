@@ -116,6 +116,64 @@ func TestSimpleDeploymentConfig(t *testing.T) {
 	}
 }
 
+func TestDeploymentConfigPullSecrets(t *testing.T) {
+	images := []*ImageRef{
+		{Reference: imageapi.DockerImageReference{Name: "public"}, Info: testImageInfo()},
+		{Reference: imageapi.DockerImageReference{Registry: "private.io", Name: "api"}, Info: testImageInfo(), PullSecrets: []string{"private", "mirror"}},
+		{Reference: imageapi.DockerImageReference{Registry: "private.io", Name: "worker"}, Info: testImageInfo(), PullSecrets: []string{"private"}},
+	}
+	deploy := &DeploymentConfigRef{Name: "app", Images: images}
+	config, err := deploy.DeploymentConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []kapi.LocalObjectReference{{Name: "private"}, {Name: "mirror"}}
+	if secrets := config.Spec.Template.Spec.ImagePullSecrets; !reflect.DeepEqual(secrets, expected) {
+		t.Errorf("unexpected pull secrets: %#v", secrets)
+	}
+}
+
+func TestNewComponentInputPullSecrets(t *testing.T) {
+	tests := []struct {
+		input       string
+		value       string
+		repo        string
+		pullSecrets []string
+		err         bool
+	}{
+		{input: "mysql", value: "mysql"},
+		{input: "private.io/app?pullSecret=creds", value: "private.io/app", pullSecrets: []string{"creds"}},
+		{input: "ruby?pullSecret=a&pullSecret=b~https://github.com/openshift/ruby-ex?ref", value: "ruby", repo: "https://github.com/openshift/ruby-ex?ref", pullSecrets: []string{"a", "b"}},
+		{input: "mysql?pullSecret=", err: true},
+		{input: "mysql?secret=creds", err: true},
+	}
+	for _, test := range tests {
+		input, repo, err := NewComponentInput(test.input)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error: %v", test.input, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if input.Value != test.value || repo != test.repo || !reflect.DeepEqual(input.PullSecrets, test.pullSecrets) {
+			t.Errorf("%s: unexpected input %#v and repository %q", test.input, input, repo)
+		}
+	}
+}
+
+func TestBuildStrategyPullSecret(t *testing.T) {
+	base := &ImageRef{Reference: imageapi.DockerImageReference{Registry: "private.io", Name: "builder"}, PullSecrets: []string{"private"}}
+	strategy, _ := (&BuildStrategyRef{Base: base}).BuildStrategy(Environment{})
+	if secret := strategy.SourceStrategy.PullSecret; secret == nil || secret.Name != "private" {
+		t.Errorf("unexpected pull secret: %#v", secret)
+	}
+	strategy, _ = (&BuildStrategyRef{Base: base, IsDockerBuild: true}).BuildStrategy(Environment{})
+	if secret := strategy.DockerStrategy.PullSecret; secret == nil || secret.Name != "private" {
+		t.Errorf("unexpected pull secret: %#v", secret)
+	}
+}
+
 func TestImageRefDeployableContainerPorts(t *testing.T) {
 	tests := []struct {
 		name          string
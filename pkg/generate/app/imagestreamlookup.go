@@ -2,6 +2,8 @@ package app
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/golang/glog"
@@ -75,6 +77,36 @@ func (r ImageStreamSearcher) Search(precise bool, terms ...string) (ComponentMat
 				imageref.Registry = ""
 				matchName := fmt.Sprintf("%s/%s", stream.Namespace, stream.Name)
 
+				// a digest selects a single image of the stream, regardless of the tags that point to it
+				if len(ref.ID) > 0 {
+					imageStreamImage, err := r.ImageStreamImages.ImageStreamImages(namespace).Get(stream.Name, ref.ID)
+					if err != nil {
+						if errors.IsNotFound(err) {
+							glog.V(2).Infof("image %q is not part of image stream %s", ref.ID, matchName)
+							continue
+						}
+						errs = append(errs, err)
+						continue
+					}
+					match := &ComponentMatch{
+						Value:       term,
+						Argument:    fmt.Sprintf("--image-stream=%q", matchName+"@"+ref.ID),
+						Name:        matchName,
+						Description: fmt.Sprintf("Image stream %q (image %q) in project %q", stream.Name, ref.ID, stream.Namespace),
+						Score:       score,
+						ImageStream: stream,
+						Image:       &imageStreamImage.Image.DockerImageMetadata,
+						ImageID:     ref.ID,
+						Meta:        meta,
+					}
+					glog.V(2).Infof("Adding %s as component match for %q with score %v", match.Description, term, score)
+					if score == 0.0 {
+						exact = true
+					}
+					componentMatches = append(componentMatches, match)
+					continue
+				}
+
 				// When an image stream contains a tag that references another local tag, and the user has not
 				// provided a tag themselves (i.e. they asked for mysql and we defaulted to mysql:latest), walk
 				// the chain of references to the end. This ensures that applications can default to using a "stable"
@@ -143,6 +175,12 @@ func (r ImageStreamSearcher) Search(precise bool, terms ...string) (ComponentMat
 	return componentMatches, errs
 }
 
+// Capabilities returns the capabilities of the server, which is asked for the images of the image streams with
+// the credentials of the user.
+func (r ImageStreamSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{SupportsTags: true, SupportsMetadata: true, RequiresNetwork: true, Authenticated: true}
+}
+
 // InputImageFromMatch returns an image reference from a component match.
 // The component match will either be an image stream or an image.
 func InputImageFromMatch(match *ComponentMatch) (*ImageRef, error) {
@@ -154,6 +192,10 @@ func InputImageFromMatch(match *ComponentMatch) (*ImageRef, error) {
 		if err != nil {
 			return nil, err
 		}
+		if len(match.ImageID) > 0 {
+			input.Reference.Tag, input.Reference.ID = "", match.ImageID
+			input.ResolvedReference = nil
+		}
 		if match.Meta["direct-tag"] == "1" {
 			input.TagDirectly = true
 		}
@@ -170,6 +212,10 @@ func InputImageFromMatch(match *ComponentMatch) (*ImageRef, error) {
 			input.TagDirectly = true
 			input.AsResolvedImage = true
 		}
+		// an image selected by digest keeps the tag it was found with in the generated image stream
+		if len(input.Reference.ID) > 0 && len(input.Reference.Tag) == 0 {
+			input.InternalDefaultTag = match.ImageTag
+		}
 		input.AsImageStream = !match.LocalOnly
 		input.Info = match.Image
 		input.Insecure = match.Insecure
@@ -219,26 +265,80 @@ func (r *ImageStreamByAnnotationSearcher) getImageStreams(namespace string) ([]i
 	return imageStreamList.Items, nil
 }
 
-func matchSupportsAnnotation(value, annotation string) (float32, bool) {
-	valueBase := strings.Split(value, ":")[0]
-	parts := strings.Split(annotation, ",")
+// The scores of the matches between a value and a 'supports' annotation entry with the same base name.
+const (
+	supportsExactScore           float32 = 0.0
+	supportsVersionPrefixScore   float32 = 0.25
+	supportsUnversionedScore     float32 = 0.5
+	supportsVersionMismatchScore float32 = 0.75
+)
 
-	// attempt an exact match first
-	for _, p := range parts {
-		if value == p {
-			return 0.0, true
+// matchSupportsAnnotation returns the best score of the entries of a 'supports' annotation for value.
+// Versions are compared segment by segment, so nodejs:4 is closer to nodejs:4.4 than to nodejs:0.10.
+func matchSupportsAnnotation(value, annotation string) (float32, bool) {
+	valueBase, valueVersion := splitSupportsValue(value)
+	best, matched := float32(0), false
+	for _, p := range strings.Split(annotation, ",") {
+		partBase, partVersion := splitSupportsValue(strings.TrimSpace(p))
+		if len(partBase) == 0 || partBase != valueBase {
+			continue
+		}
+		score := scoreSupportsVersion(valueVersion, partVersion)
+		if !matched || score < best {
+			best, matched = score, true
 		}
 	}
+	return best, matched
+}
 
-	// attempt a partial match
-	for _, p := range parts {
-		partBase := strings.Split(p, ":")[0]
-		if valueBase == partBase {
-			return 0.5, true
+// splitSupportsValue splits a value of the form name[:version].
+func splitSupportsValue(value string) (string, string) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// scoreSupportsVersion scores a requested version against a supported version of the same name.
+func scoreSupportsVersion(requested, supported string) float32 {
+	switch {
+	case requested == supported:
+		return supportsExactScore
+	case len(requested) == 0 || len(supported) == 0:
+		return supportsUnversionedScore
+	}
+	a, b := strings.Split(requested, "."), strings.Split(supported, ".")
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if !equalVersionSegment(a[i], b[i]) {
+			return supportsVersionMismatchScore
 		}
 	}
+	// the common segments are equal, so the versions are equivalent if the remaining segments are zero
+	rest := a[n:]
+	if len(b) > n {
+		rest = b[n:]
+	}
+	for _, segment := range rest {
+		if !equalVersionSegment(segment, "0") {
+			return supportsVersionPrefixScore
+		}
+	}
+	return supportsExactScore
+}
 
-	return 0, false
+// equalVersionSegment compares two segments of a version numerically if both are numbers.
+func equalVersionSegment(a, b string) bool {
+	x, errX := strconv.Atoi(a)
+	y, errY := strconv.Atoi(b)
+	if errX == nil && errY == nil {
+		return x == y
+	}
+	return a == b
 }
 
 func (r *ImageStreamByAnnotationSearcher) annotationMatches(stream *imageapi.ImageStream, value string) []*ComponentMatch {
@@ -247,7 +347,14 @@ func (r *ImageStreamByAnnotationSearcher) annotationMatches(stream *imageapi.Ima
 		return nil
 	}
 	matches := []*ComponentMatch{}
-	for tag, tagref := range stream.Spec.Tags {
+	tags := []string{}
+	for tag := range stream.Spec.Tags {
+		tags = append(tags, tag)
+	}
+	// visit the tags in a stable order so that tags with equal scores are returned deterministically
+	sort.Strings(tags)
+	for _, tag := range tags {
+		tagref := stream.Spec.Tags[tag]
 		if tagref.Annotations == nil {
 			continue
 		}
@@ -328,3 +435,9 @@ func (r *ImageStreamByAnnotationSearcher) Search(precise bool, terms ...string)
 	}
 	return matches, errs
 }
+
+// Capabilities returns the capabilities of the server, which is asked for the images of the image streams with
+// the credentials of the user.
+func (r *ImageStreamByAnnotationSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{SupportsTags: true, SupportsMetadata: true, RequiresNetwork: true, Authenticated: true}
+}
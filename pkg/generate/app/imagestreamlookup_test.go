@@ -1,10 +1,12 @@
 package app
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
 	kapi "k8s.io/kubernetes/pkg/api"
+	kapierrors "k8s.io/kubernetes/pkg/api/errors"
 	ktestclient "k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/runtime"
 
@@ -20,7 +22,12 @@ func testImageStreamClient(imageStreams *imageapi.ImageStreamList, images map[st
 		return true, imageStreams, nil
 	})
 	fake.AddReactor("get", "imagestreamimages", func(action ktestclient.Action) (handled bool, ret runtime.Object, err error) {
-		return true, images[action.(ktestclient.GetAction).GetName()], nil
+		name := action.(ktestclient.GetAction).GetName()
+		image, ok := images[name]
+		if !ok {
+			return true, nil, kapierrors.NewNotFound(imageapi.Resource("imagestreamimages"), name)
+		}
+		return true, image, nil
 	})
 
 	return fake
@@ -175,8 +182,36 @@ func TestMatchSupportsAnnotation(t *testing.T) {
 			value:         "ruby:2.0",
 			annotation:    "ruby:1.9,ruby:1.8",
 			expectedMatch: true,
+			expectedScore: 0.75,
+		},
+		{
+			name:          "version prefix",
+			value:         "nodejs:4",
+			annotation:    "nodejs:0.10,nodejs:4.4",
+			expectedMatch: true,
+			expectedScore: 0.25,
+		},
+		{
+			name:          "unversioned entry wins over a different version",
+			value:         "nodejs:4",
+			annotation:    "nodejs:0.10,nodejs",
+			expectedMatch: true,
 			expectedScore: 0.5,
 		},
+		{
+			name:          "equivalent versions",
+			value:         "nodejs:4",
+			annotation:    "nodejs:4.0.0",
+			expectedMatch: true,
+			expectedScore: 0.0,
+		},
+		{
+			name:          "versions are not compared as substrings",
+			value:         "nodejs:0.1",
+			annotation:    "nodejs:0.10",
+			expectedMatch: true,
+			expectedScore: 0.75,
+		},
 		{
 			name:          "partial match (no version specified)",
 			value:         "ruby",
@@ -278,12 +313,53 @@ func TestAnnotationMatches(t *testing.T) {
 	}
 }
 
+func TestAnnotationMatchesVersions(t *testing.T) {
+	stream, images := fakeImageStream("nodejs", map[string]string{
+		"0.10": "nodejs:0.10,nodejs",
+		"4":    "nodejs:4.4",
+		"6":    "nodejs:6",
+	}, "")
+	client := testImageStreamClient(nil, images)
+	searcher := NewImageStreamByAnnotationSearcher(client, client, []string{"default"}).(*ImageStreamByAnnotationSearcher)
+	matches := searcher.annotationMatches(stream, "nodejs:4")
+	tags := []string{}
+	for _, match := range matches {
+		tags = append(tags, fmt.Sprintf("%s=%v", match.ImageTag, match.Score))
+	}
+	if expected := []string{"0.10=0.5", "4=0.25", "6=0.75"}; !reflect.DeepEqual(tags, expected) {
+		t.Errorf("unexpected matches: %v", tags)
+	}
+}
+
 type fakeImageStreamDesc struct {
 	name     string
 	supports map[string]string
 	latest   string
 }
 
+func TestImageStreamSearcherByDigest(t *testing.T) {
+	streams, images := fakeImageStreams(&fakeImageStreamDesc{name: "ruby20", supports: map[string]string{"stable": "ruby"}})
+	id := "sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238"
+	images["ruby20@"+id] = &imageapi.ImageStreamImage{
+		Image: imageapi.Image{DockerImageMetadata: imageapi.DockerImage{ID: id}},
+	}
+	client := testImageStreamClient(streams, images)
+	searcher := ImageStreamSearcher{Client: client, ImageStreamImages: client, Namespaces: []string{"default"}}
+
+	matches, errs := searcher.Search(true, "ruby20@"+id)
+	if len(errs) > 0 || len(matches) != 1 {
+		t.Fatalf("unexpected search results: %#v %v", matches, errs)
+	}
+	match := matches[0]
+	if match.ImageID != id || len(match.ImageTag) != 0 || match.Image == nil || match.Image.ID != id {
+		t.Errorf("unexpected match: %#v", match)
+	}
+
+	if matches, errs := searcher.Search(true, "ruby20@sha256:0000000000000000000000000000000000000000000000000000000000000000"); len(errs) > 0 || len(matches) != 0 {
+		t.Errorf("expected no match for an unknown image: %#v %v", matches, errs)
+	}
+}
+
 func fakeImageStreams(descs ...*fakeImageStreamDesc) (*imageapi.ImageStreamList, map[string]*imageapi.ImageStreamImage) {
 	streams := &imageapi.ImageStreamList{
 		Items: []imageapi.ImageStream{},
@@ -381,6 +457,22 @@ func TestInputImageFromMatch(t *testing.T) {
 			},
 			expectedRef: "test/imagename:v2",
 		},
+		{
+			name: "image stream image",
+			match: &ComponentMatch{
+				ImageStream: &imageapi.ImageStream{
+					ObjectMeta: kapi.ObjectMeta{
+						Name:      "testimage",
+						Namespace: "myns",
+					},
+					Status: imageapi.ImageStreamStatus{
+						DockerImageRepository: "test/imagename",
+					},
+				},
+				ImageID: "sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238",
+			},
+			expectedRef: "test/imagename@sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238",
+		},
 		{
 			name: "docker image",
 			match: &ComponentMatch{
@@ -411,3 +503,30 @@ func TestInputImageFromMatch(t *testing.T) {
 	}
 
 }
+
+func TestImageStreamImageRef(t *testing.T) {
+	ref, err := InputImageFromMatch(&ComponentMatch{
+		ImageStream: &imageapi.ImageStream{
+			ObjectMeta: kapi.ObjectMeta{Name: "testimage", Namespace: "myns"},
+			Status:     imageapi.ImageStreamStatus{DockerImageRepository: "test/imagename"},
+		},
+		ImageID: "sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := kapi.ObjectReference{Kind: "ImageStreamImage", Name: "testimage@sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238", Namespace: "myns"}
+	if from := ref.ObjectReference(); !reflect.DeepEqual(from, expected) {
+		t.Errorf("unexpected reference: %#v", from)
+	}
+	if triggers := ref.BuildTriggers(); len(triggers) != 0 {
+		t.Errorf("unexpected build triggers: %#v", triggers)
+	}
+	container, triggers, err := ref.DeployableContainer()
+	if err != nil || len(triggers) != 0 {
+		t.Fatalf("unexpected deployment triggers: %#v %v", triggers, err)
+	}
+	if container.Image != "test/imagename@sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238" {
+		t.Errorf("unexpected container image: %s", container.Image)
+	}
+}
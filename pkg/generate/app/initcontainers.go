@@ -0,0 +1,104 @@
+package app
+
+import (
+	"encoding/json"
+
+	kapiv1 "k8s.io/kubernetes/pkg/api/v1"
+	kvalidation "k8s.io/kubernetes/pkg/util/validation"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+// InitContainersAnnotation declares the init containers of a pod to the nodes that support them. Nodes that
+// predate init containers ignore the annotation and start the pod without them.
+const InitContainersAnnotation = "pod.alpha.kubernetes.io/init-containers"
+
+// InitContainer describes a container that runs to completion before the containers of a generated deployment
+// config start, such as a schema migration or a wait for a database.
+type InitContainer struct {
+	// Name is the name of the init container.
+	Name string `json:"name"`
+	// Image is the image the init container runs.
+	Image string `json:"image"`
+	// Command, if set, overrides the entrypoint of the image.
+	Command []string `json:"command,omitempty"`
+	// Component, if set, is the name of the only deployment config the init container is added to. Otherwise
+	// it is added to every deployment config.
+	Component string `json:"component,omitempty"`
+}
+
+// AddInitContainers adds the init containers to the pod templates of the deployment configs in objects, in
+// order. The containers are merged into any already declared on a template.
+func AddInitContainers(objects Objects, containers []InitContainer) error {
+	if len(containers) == 0 {
+		return nil
+	}
+	for _, c := range containers {
+		if len(c.Name) == 0 || len(c.Image) == 0 {
+			return generrors.New(generrors.CodeInvalidArgument, "every init container must have a name and an image")
+		}
+		if !kvalidation.IsDNS1123Label(c.Name) {
+			return generrors.Newf(generrors.CodeInvalidArgument, "the init container name %q must be a valid DNS label", c.Name)
+		}
+	}
+
+	found := map[string]bool{}
+	for _, obj := range objects {
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil {
+			continue
+		}
+		template := dc.Spec.Template
+		existing := []kapiv1.Container{}
+		if value, ok := template.Annotations[InitContainersAnnotation]; ok {
+			if err := json.Unmarshal([]byte(value), &existing); err != nil {
+				return generrors.Wrapf(generrors.CodeInvalidArgument, err, "the init containers of deployment config %q are invalid: %v", dc.Name, err)
+			}
+		}
+		names := map[string]bool{}
+		for _, c := range template.Spec.Containers {
+			names[c.Name] = true
+		}
+		for _, c := range existing {
+			names[c.Name] = true
+		}
+
+		added := false
+		for _, c := range containers {
+			if len(c.Component) > 0 && c.Component != dc.Name {
+				continue
+			}
+			found[c.Component] = true
+			if names[c.Name] {
+				return generrors.Newf(generrors.CodeInvalidArgument, "the init container %q has the same name as another container of deployment config %q", c.Name, dc.Name)
+			}
+			names[c.Name] = true
+			existing = append(existing, kapiv1.Container{
+				Name:            c.Name,
+				Image:           c.Image,
+				Command:         c.Command,
+				ImagePullPolicy: kapiv1.PullIfNotPresent,
+			})
+			added = true
+		}
+		if !added {
+			continue
+		}
+		data, err := json.Marshal(existing)
+		if err != nil {
+			return err
+		}
+		if template.Annotations == nil {
+			template.Annotations = make(map[string]string)
+		}
+		template.Annotations[InitContainersAnnotation] = string(data)
+	}
+
+	for _, c := range containers {
+		if len(c.Component) > 0 && !found[c.Component] {
+			return generrors.Newf(generrors.CodeInvalidArgument, "the init container %q is for %q, but no deployment config with that name was generated", c.Name, c.Component)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	kapiv1 "k8s.io/kubernetes/pkg/api/v1"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+func TestAddInitContainers(t *testing.T) {
+	objects := append(presetTestObjects(), &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "web"},
+		Spec: deployapi.DeploymentConfigSpec{
+			Template: &kapi.PodTemplateSpec{
+				ObjectMeta: kapi.ObjectMeta{
+					Annotations: map[string]string{InitContainersAnnotation: `[{"name":"setup","image":"busybox"}]`},
+				},
+				Spec: kapi.PodSpec{Containers: []kapi.Container{{Name: "web"}}},
+			},
+		},
+	})
+	err := AddInitContainers(objects, []InitContainer{
+		{Name: "wait-for-db", Image: "busybox", Command: []string{"sh", "-c", "until nc -z db 5432; do sleep 1; done"}, Component: "web"},
+		{Name: "permissions", Image: "busybox", Command: []string{"chmod", "-R", "g+w", "/data"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, test := range []struct {
+		dc    *deployapi.DeploymentConfig
+		names []string
+	}{
+		{dc: objects[0].(*deployapi.DeploymentConfig), names: []string{"permissions"}},
+		{dc: objects[2].(*deployapi.DeploymentConfig), names: []string{"setup", "wait-for-db", "permissions"}},
+	} {
+		containers := []kapiv1.Container{}
+		if err := json.Unmarshal([]byte(test.dc.Spec.Template.Annotations[InitContainersAnnotation]), &containers); err != nil {
+			t.Fatalf("%s: %v", test.dc.Name, err)
+		}
+		names := []string{}
+		for _, c := range containers {
+			names = append(names, c.Name)
+		}
+		if !reflect.DeepEqual(names, test.names) {
+			t.Errorf("%s: unexpected init containers: %#v", test.dc.Name, containers)
+		}
+	}
+}
+
+func TestAddInitContainersInvalid(t *testing.T) {
+	tests := map[string][]InitContainer{
+		"missing image":     {{Name: "setup"}},
+		"invalid name":      {{Name: "Setup", Image: "busybox"}},
+		"same as container": {{Name: "db", Image: "busybox"}},
+		"unknown component": {{Name: "setup", Image: "busybox", Component: "missing"}},
+	}
+	for name, containers := range tests {
+		if err := AddInitContainers(presetTestObjects(), containers); !generrors.HasCode(err, generrors.CodeInvalidArgument) {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
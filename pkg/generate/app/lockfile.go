@@ -0,0 +1,177 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/docker/distribution/digest"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// LockfileMode controls how a lockfile is used when resolving components.
+type LockfileMode string
+
+const (
+	// LockfileWrite records the resolved components in the lockfile after a successful resolution.
+	LockfileWrite LockfileMode = "write"
+	// LockfileStrict requires every component to resolve to the match recorded in the lockfile, and generates
+	// objects that refer to the recorded images by digest.
+	LockfileStrict LockfileMode = "strict"
+)
+
+// Lockfile records the matches that components resolved to, so that later resolutions can be verified to use
+// the same images and templates.
+type Lockfile struct {
+	Components []LockedComponent `json:"components"`
+}
+
+// LockedComponent is the recorded resolution of a single component.
+type LockedComponent struct {
+	// Value is the component as it was specified.
+	Value string `json:"value"`
+	// Kind is one of Image, ImageStream or Template.
+	Kind string `json:"kind"`
+	// Name is the exact name of the match.
+	Name string `json:"name"`
+	// Tag is the image stream tag that was resolved, if any.
+	Tag string `json:"tag,omitempty"`
+	// Digest identifies the resolved image: the image name for image streams, or the image ID for Docker images.
+	Digest string `json:"digest,omitempty"`
+	// Score is the score of the match.
+	Score float32 `json:"score"`
+}
+
+// ReadLockfile reads the lockfile at path.
+func ReadLockfile(path string) (*Lockfile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	lock := &Lockfile{}
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, fmt.Errorf("the lockfile %s is invalid: %v", path, err)
+	}
+	return lock, nil
+}
+
+// Write writes the lockfile to path.
+func (l *Lockfile) Write(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Add records that value resolved to match, replacing any earlier record for value.
+func (l *Lockfile) Add(value string, match *ComponentMatch) {
+	locked := LockedComponentFor(value, match)
+	for i := range l.Components {
+		if l.Components[i].Value == value {
+			l.Components[i] = locked
+			return
+		}
+	}
+	l.Components = append(l.Components, locked)
+}
+
+// Find returns the record for value, if there is one.
+func (l *Lockfile) Find(value string) (*LockedComponent, bool) {
+	for i := range l.Components {
+		if l.Components[i].Value == value {
+			return &l.Components[i], true
+		}
+	}
+	return nil, false
+}
+
+// LockedComponentFor returns the record of value resolving to match.
+func LockedComponentFor(value string, match *ComponentMatch) LockedComponent {
+	locked := LockedComponent{
+		Value: value,
+		Name:  match.Name,
+		Score: match.Score,
+	}
+	switch {
+	case match.Template != nil:
+		locked.Kind = "Template"
+	case match.ImageStream != nil:
+		locked.Kind = "ImageStream"
+		locked.Tag = match.ImageTag
+		if len(match.ImageID) > 0 {
+			locked.Digest = match.ImageID
+		} else if latest := imageapi.LatestTaggedImage(match.ImageStream, match.ImageTag); latest != nil {
+			locked.Digest = latest.Image
+		}
+	default:
+		locked.Kind = "Image"
+		switch {
+		case len(match.ImageID) > 0:
+			locked.Digest = match.ImageID
+		case match.Image != nil:
+			locked.Digest = match.Image.ID
+		}
+	}
+	return locked
+}
+
+// Resolver returns a resolver that resolves values with r and fails unless the result is the match recorded in
+// the lockfile.
+func (l *Lockfile) Resolver(r Resolver) Resolver {
+	return lockfileResolver{lock: l, resolver: r}
+}
+
+// lockfileResolver verifies resolutions against a lockfile.
+type lockfileResolver struct {
+	lock     *Lockfile
+	resolver Resolver
+}
+
+// Resolve implements Resolver.
+func (r lockfileResolver) Resolve(value string) (*ComponentMatch, error) {
+	locked, ok := r.lock.Find(value)
+	if !ok {
+		return nil, fmt.Errorf("%q is not recorded in the lockfile", value)
+	}
+	match, err := r.resolver.Resolve(value)
+	if err != nil {
+		return nil, err
+	}
+	actual := LockedComponentFor(value, match)
+	switch {
+	case actual.Kind != locked.Kind || actual.Name != locked.Name || actual.Tag != locked.Tag:
+		return nil, fmt.Errorf("%q resolved to %s %q but the lockfile requires %s %q", value, actual.Kind, actual.Name, locked.Kind, locked.Name)
+	case actual.Digest != locked.Digest:
+		return nil, fmt.Errorf("%q resolved to %s but the lockfile requires %s - the image has changed since the lockfile was written", value, actual.Digest, locked.Digest)
+	}
+	return pinnedMatch(match, locked), nil
+}
+
+// pinnedMatch returns a copy of match that selects the locked image by digest instead of by tag, so that the
+// objects generated from it keep using the locked image when the tag moves. Images that can only be identified
+// by the ID of a local image are returned unchanged.
+func pinnedMatch(match *ComponentMatch, locked *LockedComponent) *ComponentMatch {
+	if len(locked.Digest) == 0 || match.Template != nil {
+		return match
+	}
+	pinned := *match
+	switch {
+	case match.ImageStream != nil:
+		pinned.ImageID = locked.Digest
+	case len(match.ImageID) > 0 && !match.LocalOnly:
+		if _, err := digest.ParseDigest(locked.Digest); err != nil {
+			return match
+		}
+		ref, err := imageapi.ParseDockerImageReference(match.Value)
+		if err != nil {
+			return match
+		}
+		ref.Tag, ref.ID = "", locked.Digest
+		pinned.Value = ref.Exact()
+	default:
+		return match
+	}
+	return &pinned
+}
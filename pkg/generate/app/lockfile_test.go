@@ -0,0 +1,115 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+type staticResolver struct {
+	match *ComponentMatch
+}
+
+func (r staticResolver) Resolve(value string) (*ComponentMatch, error) {
+	return r.match, nil
+}
+
+func lockfileTestStream(image string) *imageapi.ImageStream {
+	return &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Name: "ruby", Namespace: "openshift"},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				"latest": {Items: []imageapi.TagEvent{{Image: image}}},
+			},
+		},
+	}
+}
+
+func TestLockfileRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lockfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "new-app.lock")
+
+	lock := &Lockfile{}
+	lock.Add("ruby", &ComponentMatch{Name: "openshift/ruby", ImageStream: lockfileTestStream("sha256:1"), ImageTag: "latest"})
+	lock.Add("mysql", &ComponentMatch{Name: "mysql", Image: &imageapi.DockerImage{ID: "abc"}, Score: 1})
+	if err := lock.Write(path); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	read, err := ReadLockfile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(lock, read) {
+		t.Errorf("unexpected lockfile: %#v", read)
+	}
+	if locked, ok := read.Find("ruby"); !ok || locked.Kind != "ImageStream" || locked.Digest != "sha256:1" {
+		t.Errorf("unexpected entry: %#v", locked)
+	}
+}
+
+func TestLockfileResolver(t *testing.T) {
+	lock := &Lockfile{}
+	lock.Add("ruby", &ComponentMatch{Name: "openshift/ruby", ImageStream: lockfileTestStream("sha256:1"), ImageTag: "latest"})
+
+	same := lock.Resolver(staticResolver{&ComponentMatch{Name: "openshift/ruby", ImageStream: lockfileTestStream("sha256:1"), ImageTag: "latest"}})
+	match, err := same.Resolve("ruby")
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if match == nil || match.ImageID != "sha256:1" {
+		t.Errorf("expected the match to be pinned to the locked image: %#v", match)
+	}
+	if _, err := same.Resolve("perl"); err == nil {
+		t.Errorf("expected an error for a component missing from the lockfile")
+	}
+	changed := lock.Resolver(staticResolver{&ComponentMatch{Name: "openshift/ruby", ImageStream: lockfileTestStream("sha256:2"), ImageTag: "latest"}})
+	if _, err := changed.Resolve("ruby"); err == nil {
+		t.Errorf("expected an error for a changed image")
+	}
+	other := lock.Resolver(staticResolver{&ComponentMatch{Name: "ruby", Image: &imageapi.DockerImage{ID: "sha256:1"}}})
+	if _, err := other.Resolve("ruby"); err == nil {
+		t.Errorf("expected an error for a different kind of match")
+	}
+}
+
+func TestLockfileResolverPinsImages(t *testing.T) {
+	id := "sha256:958fa10ad4e5a0ec6a5e8b2f4a6c5e8d05e9a8f4d7e4b7f1c3b3c4e8d2b7e3c1"
+	imported := &ComponentMatch{Value: "centos/ruby-22-centos7:2.2", Name: "centos/ruby-22-centos7:2.2", Image: &imageapi.DockerImage{ID: "config"}, ImageTag: "2.2", ImageID: id}
+	local := &ComponentMatch{Value: "ruby:2.2", Name: "ruby:2.2", Image: &imageapi.DockerImage{ID: "sha256:2"}, LocalOnly: true}
+	lock := &Lockfile{}
+	lock.Add("ruby", imported)
+	lock.Add("local", local)
+
+	match, err := lock.Resolver(staticResolver{imported}).Resolve("ruby")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if match.Value != "centos/ruby-22-centos7@"+id || imported.Value != "centos/ruby-22-centos7:2.2" {
+		t.Errorf("expected a pinned copy of the match: %#v", match)
+	}
+	ref, err := InputImageFromMatch(match)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ref.Reference.ID != id || ref.InternalTag() != "2.2" {
+		t.Errorf("unexpected image reference: %#v", ref)
+	}
+
+	match, err = lock.Resolver(staticResolver{local}).Resolve("local")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if match != local {
+		t.Errorf("expected local images not to be pinned: %#v", match)
+	}
+}
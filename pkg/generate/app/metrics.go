@@ -0,0 +1,128 @@
+package app
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+// The names of the searchers recorded in the resolution metrics.
+const (
+	SearcherDocker                = "docker"
+	SearcherImageStream           = "imagestream"
+	SearcherImageStreamAnnotation = "imagestream-annotation"
+	SearcherTemplate              = "template"
+	SearcherTemplateFile          = "template-file"
+)
+
+// The results of a search recorded in the resolution metrics.
+const (
+	resolutionMatched = "matched"
+	resolutionNone    = "none"
+	resolutionError   = "error"
+)
+
+// Metrics records the work done by generation when it runs in a long lived process, such as a console
+// backend. A process creates and registers a single Metrics and shares it between its AppConfigs. The
+// methods of a nil *Metrics do nothing.
+type Metrics struct {
+	// Resolutions counts searches by searcher and result.
+	Resolutions *prometheus.CounterVec
+	// DetectionDuration observes the time taken to detect the contents of source repositories.
+	DetectionDuration prometheus.Histogram
+	// Failures counts failed generations by error code.
+	Failures *prometheus.CounterVec
+}
+
+// NewMetrics returns unregistered generation metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		Resolutions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "openshift_generate_resolutions_total",
+				Help: "Counter of searches made while resolving components, broken out by searcher and result",
+			},
+			[]string{"searcher", "result"},
+		),
+		DetectionDuration: prometheus.NewHistogram(
+			prometheus.HistogramOpts{
+				Name: "openshift_generate_detection_duration_seconds",
+				Help: "Time taken to detect the contents of a source repository",
+			},
+		),
+		Failures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "openshift_generate_failures_total",
+				Help: "Counter of failed generations, broken out by error code",
+			},
+			[]string{"code"},
+		),
+	}
+}
+
+// Register registers the metrics with register, which is usually prometheus.Register.
+func (m *Metrics) Register(register func(prometheus.Collector) error) error {
+	for _, c := range []prometheus.Collector{m.Resolutions, m.DetectionDuration, m.Failures} {
+		if err := register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ObserveSearch records the result of a search made by the named searcher.
+func (m *Metrics) ObserveSearch(searcher string, matches ComponentMatches, errs []error) {
+	if m == nil {
+		return
+	}
+	result := resolutionNone
+	switch {
+	case len(errs) > 0:
+		result = resolutionError
+	case len(matches) > 0:
+		result = resolutionMatched
+	}
+	m.Resolutions.WithLabelValues(searcher, result).Inc()
+}
+
+// ObserveDetection records the time taken to detect a source repository that started at start.
+func (m *Metrics) ObserveDetection(start time.Time) {
+	if m == nil {
+		return
+	}
+	m.DetectionDuration.Observe(time.Since(start).Seconds())
+}
+
+// ObserveFailure records the code of err, if err is not nil. Errors without a code are recorded as
+// "Unknown".
+func (m *Metrics) ObserveFailure(err error) {
+	if m == nil || err == nil {
+		return
+	}
+	code := generrors.CodeOf(err)
+	if code == generrors.CodeUnknown {
+		code = "Unknown"
+	}
+	m.Failures.WithLabelValues(string(code)).Inc()
+}
+
+// MetricsSearcher records the searches made by a searcher in Metrics.
+type MetricsSearcher struct {
+	Searcher Searcher
+	Name     string
+	Metrics  *Metrics
+}
+
+// Search searches with the wrapped searcher and records the result.
+func (s MetricsSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	matches, errs := s.Searcher.Search(precise, terms...)
+	s.Metrics.ObserveSearch(s.Name, matches, errs)
+	return matches, errs
+}
+
+// Capabilities returns the capabilities of the wrapped searcher.
+func (s MetricsSearcher) Capabilities() SearcherCapabilities {
+	return s.Searcher.Capabilities()
+}
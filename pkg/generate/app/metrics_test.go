@@ -0,0 +1,83 @@
+package app
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+type metricsTestSearcher struct {
+	matches ComponentMatches
+	errs    []error
+}
+
+func (s metricsTestSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	return s.matches, s.errs
+}
+
+func (s metricsTestSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
+
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	if err := c.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	registered := []prometheus.Collector{}
+	if err := m.Register(func(c prometheus.Collector) error {
+		registered = append(registered, c)
+		return nil
+	}); err != nil || len(registered) != 3 {
+		t.Fatalf("unexpected registration: %v %v", registered, err)
+	}
+
+	MetricsSearcher{Searcher: metricsTestSearcher{matches: ComponentMatches{{Name: "a"}}}, Name: SearcherDocker, Metrics: m}.Search(true, "a")
+	MetricsSearcher{Searcher: metricsTestSearcher{matches: ComponentMatches{{Name: "a"}}}, Name: SearcherDocker, Metrics: m}.Search(true, "a")
+	MetricsSearcher{Searcher: metricsTestSearcher{}, Name: SearcherTemplate, Metrics: m}.Search(true, "b")
+	MetricsSearcher{Searcher: metricsTestSearcher{errs: []error{fmt.Errorf("down")}}, Name: SearcherImageStream, Metrics: m}.Search(true, "c")
+	for _, test := range []struct {
+		searcher, result string
+		value            float64
+	}{
+		{SearcherDocker, resolutionMatched, 2},
+		{SearcherTemplate, resolutionNone, 1},
+		{SearcherImageStream, resolutionError, 1},
+		{SearcherImageStream, resolutionMatched, 0},
+	} {
+		if v := counterValue(t, m.Resolutions.WithLabelValues(test.searcher, test.result)); v != test.value {
+			t.Errorf("%s/%s: expected %v, got %v", test.searcher, test.result, test.value, v)
+		}
+	}
+
+	m.ObserveFailure(nil)
+	m.ObserveFailure(ErrNameRequired)
+	m.ObserveFailure(fmt.Errorf("plain"))
+	if v := counterValue(t, m.Failures.WithLabelValues(string(generrors.CodeNameRequired))); v != 1 {
+		t.Errorf("unexpected name required failures: %v", v)
+	}
+	if v := counterValue(t, m.Failures.WithLabelValues("Unknown")); v != 1 {
+		t.Errorf("unexpected unknown failures: %v", v)
+	}
+
+	m.ObserveDetection(time.Now())
+	h := &dto.Metric{}
+	if err := m.DetectionDuration.Write(h); err != nil || h.GetHistogram().GetSampleCount() != 1 {
+		t.Errorf("unexpected detection duration: %v %v", h, err)
+	}
+
+	var nilMetrics *Metrics
+	nilMetrics.ObserveSearch(SearcherDocker, nil, nil)
+	nilMetrics.ObserveDetection(time.Now())
+	nilMetrics.ObserveFailure(ErrNameRequired)
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	buildutil "github.com/openshift/origin/pkg/build/util"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// SetTargetNamespace places the objects that do not set a namespace in target, for applications that are
+// generated on behalf of another namespace than origin, the namespace that images and templates were found in.
+// The image inputs of builds, the image change triggers of deployments and the tags of image streams that refer
+// to image streams in the current namespace that are not generated are made to refer to those image streams in
+// origin. Nothing is changed if target is empty or origin.
+func SetTargetNamespace(objects Objects, target, origin string) {
+	if len(target) == 0 || target == origin {
+		return
+	}
+
+	generated := make(map[string]bool)
+	for _, obj := range objects {
+		if is, ok := obj.(*imageapi.ImageStream); ok && (len(is.Namespace) == 0 || is.Namespace == target) {
+			generated[is.Name] = true
+		}
+	}
+	qualify := func(ref *kapi.ObjectReference) {
+		if name, ok := localImageStream(ref); ok && !generated[name] && len(origin) > 0 {
+			ref.Namespace = origin
+		}
+	}
+
+	for _, obj := range objects {
+		_, meta, err := objectMetaData(obj)
+		if err != nil {
+			continue
+		}
+		if len(meta.Namespace) == 0 {
+			meta.Namespace = target
+		}
+		switch t := obj.(type) {
+		case *buildapi.BuildConfig:
+			qualify(buildutil.GetImageStreamForStrategy(t.Spec.Strategy))
+			for i := range t.Spec.Source.Images {
+				qualify(&t.Spec.Source.Images[i].From)
+			}
+			for _, trigger := range t.Spec.Triggers {
+				if trigger.ImageChange != nil {
+					qualify(trigger.ImageChange.From)
+				}
+			}
+		case *deployapi.DeploymentConfig:
+			for _, trigger := range t.Spec.Triggers {
+				if trigger.ImageChangeParams != nil {
+					qualify(&trigger.ImageChangeParams.From)
+				}
+			}
+		case *imageapi.ImageStream:
+			if t.Namespace != target {
+				continue
+			}
+			for _, tag := range t.Spec.Tags {
+				qualify(tag.From)
+			}
+		}
+	}
+}
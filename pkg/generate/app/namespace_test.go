@@ -0,0 +1,46 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestSetTargetNamespace(t *testing.T) {
+	objects := nameTemplateObjects()
+	bc := objects[1].(*buildapi.BuildConfig)
+	bc.Spec.Source.Images = []buildapi.ImageSource{{From: kapi.ObjectReference{Kind: "ImageStreamTag", Name: "assets:latest"}}}
+	objects = append(objects, &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "ruby-ex", Namespace: "stage"}})
+
+	SetTargetNamespace(objects, "team-a", "admin")
+	for _, obj := range objects[:4] {
+		if _, meta, _ := objectMetaData(obj); meta.Namespace != "team-a" {
+			t.Errorf("unexpected namespace of %s: %s", meta.Name, meta.Namespace)
+		}
+	}
+	if ns := objects[4].(*imageapi.ImageStream).Namespace; ns != "stage" {
+		t.Errorf("the namespace of an image stream in another namespace was changed to %s", ns)
+	}
+	if from := bc.Spec.Strategy.SourceStrategy.From; from.Namespace != "openshift" {
+		t.Errorf("unexpected strategy input: %#v", from)
+	}
+	if from := bc.Spec.Source.Images[0].From; from.Namespace != "admin" {
+		t.Errorf("unexpected image source: %#v", from)
+	}
+	if to := bc.Spec.Output.To; to.Namespace != "" {
+		t.Errorf("unexpected output: %#v", to)
+	}
+	if from := objects[2].(*deployapi.DeploymentConfig).Spec.Triggers[0].ImageChangeParams.From; from.Namespace != "" {
+		t.Errorf("the trigger of a generated image stream was changed: %#v", from)
+	}
+
+	objects = nameTemplateObjects()
+	SetTargetNamespace(objects, "admin", "admin")
+	if _, meta, _ := objectMetaData(objects[0]); meta.Namespace != "" {
+		t.Errorf("unexpected namespace: %s", meta.Namespace)
+	}
+}
@@ -0,0 +1,244 @@
+package app
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	kuval "k8s.io/kubernetes/pkg/util/validation"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	buildutil "github.com/openshift/origin/pkg/build/util"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// NameTemplateInput holds the variables a NameTemplate is executed with for each generated object.
+type NameTemplateInput struct {
+	// Name is the name the object was generated with.
+	Name string
+	// Kind is the kind of the object, such as BuildConfig or Service.
+	Kind string
+	// Repository is the source repository built by the component of the object, if any.
+	Repository string
+	// Strategy is the build strategy of the component of the object, if it is built: Source, Docker or
+	// Custom.
+	Strategy string
+}
+
+// NameTemplate renames the image streams, build configs, deployment configs and services generated for an
+// application, so that they follow a naming convention. Routes keep the name of their service.
+type NameTemplate struct {
+	template *template.Template
+}
+
+// ParseNameTemplate parses text as a Go template that is executed with a NameTemplateInput and returns the new
+// name of the object.
+func ParseNameTemplate(text string) (*NameTemplate, error) {
+	t, err := template.New("name").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("the name template is invalid: %v", err)
+	}
+	return &NameTemplate{template: t}, nil
+}
+
+// NameChanges records the objects renamed by a NameTemplate, by kind and original name.
+type NameChanges map[string]map[string]string
+
+// Name returns the name the object of kind named name was given, which is name if it was not renamed.
+func (c NameChanges) Name(kind, name string) string {
+	if newName, ok := c[kind][name]; ok {
+		return newName
+	}
+	return name
+}
+
+func (c NameChanges) add(kind, oldName, newName string) {
+	if c[kind] == nil {
+		c[kind] = make(map[string]string)
+	}
+	c[kind][oldName] = newName
+}
+
+// Name executes the template for input, returning an error if the result is not a valid object name.
+func (t *NameTemplate) Name(input NameTemplateInput) (string, error) {
+	out := &bytes.Buffer{}
+	if err := t.template.Execute(out, input); err != nil {
+		return "", fmt.Errorf("unable to name the %s %q: %v", input.Kind, input.Name, err)
+	}
+	name := strings.TrimSpace(out.String())
+	if !kuval.IsDNS1123Label(name) {
+		return "", fmt.Errorf("the name template named the %s %q %q, which is not a valid name: it must be at most %d lowercase alphanumeric characters or '-'", input.Kind, input.Name, name, kuval.DNS1123LabelMaxLength)
+	}
+	return name, nil
+}
+
+// Apply renames the image streams, build configs, deployment configs and services in objects, and updates the
+// references between them. The repository and strategy of an object are those of the build config with the
+// same name, or of the build config that outputs to the image stream the object refers to.
+func (t *NameTemplate) Apply(objects Objects) (NameChanges, error) {
+	builds := make(map[string]*buildapi.BuildConfig)
+	for _, obj := range objects {
+		bc, ok := obj.(*buildapi.BuildConfig)
+		if !ok {
+			continue
+		}
+		builds[bc.Name] = bc
+		if stream, ok := localImageStream(bc.Spec.Output.To); ok {
+			builds[stream] = bc
+		}
+	}
+	inputFor := func(kind, name string, related ...string) NameTemplateInput {
+		input := NameTemplateInput{Name: name, Kind: kind}
+		for _, n := range append([]string{name}, related...) {
+			if bc, ok := builds[n]; ok {
+				if bc.Spec.Source.Git != nil {
+					input.Repository = bc.Spec.Source.Git.URI
+				}
+				input.Strategy = buildapi.StrategyType(bc.Spec.Strategy)
+				break
+			}
+		}
+		return input
+	}
+
+	changes := NameChanges{}
+	for _, obj := range objects {
+		var meta *kapi.ObjectMeta
+		var input NameTemplateInput
+		switch o := obj.(type) {
+		case *imageapi.ImageStream:
+			meta, input = &o.ObjectMeta, inputFor("ImageStream", o.Name)
+		case *buildapi.BuildConfig:
+			meta, input = &o.ObjectMeta, inputFor("BuildConfig", o.Name)
+		case *deployapi.DeploymentConfig:
+			meta, input = &o.ObjectMeta, inputFor("DeploymentConfig", o.Name, deploymentStreams(o)...)
+		case *kapi.Service:
+			name := o.Name
+			if len(name) == 0 {
+				name = o.GenerateName
+			}
+			meta, input = &o.ObjectMeta, inputFor("Service", name, o.Spec.Selector["deploymentconfig"])
+		default:
+			continue
+		}
+		name, err := t.Name(input)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := changes[input.Kind][input.Name]; exists {
+			return nil, fmt.Errorf("more than one %s is named %q", input.Kind, input.Name)
+		}
+		for _, other := range changes[input.Kind] {
+			if other == name {
+				return nil, fmt.Errorf("the name template gives more than one %s the name %q", input.Kind, name)
+			}
+		}
+		changes.add(input.Kind, input.Name, name)
+		meta.Name, meta.GenerateName = name, ""
+	}
+
+	for _, obj := range objects {
+		switch t := obj.(type) {
+		case *buildapi.BuildConfig:
+			renameStreamReference(t.Spec.Output.To, changes)
+			renameStreamReference(buildutil.GetImageStreamForStrategy(t.Spec.Strategy), changes)
+			for _, trigger := range t.Spec.Triggers {
+				if trigger.ImageChange != nil {
+					renameStreamReference(trigger.ImageChange.From, changes)
+				}
+			}
+		case *deployapi.DeploymentConfig:
+			renameDeploymentConfigReferences(t, changes)
+		case *kapi.Service:
+			t.Spec.Selector = renameSelector(t.Spec.Selector, changes)
+		}
+	}
+	return changes, nil
+}
+
+// localImageStream returns the name of the image stream in the current namespace that ref refers to.
+func localImageStream(ref *kapi.ObjectReference) (string, bool) {
+	if ref == nil || len(ref.Namespace) > 0 {
+		return "", false
+	}
+	switch ref.Kind {
+	case "ImageStreamTag":
+		name, _, _ := imageapi.SplitImageStreamTag(ref.Name)
+		return name, true
+	case "ImageStreamImage":
+		return strings.SplitN(ref.Name, "@", 2)[0], true
+	case "ImageStream":
+		return ref.Name, true
+	}
+	return "", false
+}
+
+// renameStreamReference points ref at the new name of the image stream in the current namespace it refers to.
+func renameStreamReference(ref *kapi.ObjectReference, changes NameChanges) {
+	name, ok := localImageStream(ref)
+	if !ok {
+		return
+	}
+	newName, ok := changes["ImageStream"][name]
+	if !ok {
+		return
+	}
+	ref.Name = newName + strings.TrimPrefix(ref.Name, name)
+}
+
+// deploymentStreams returns the image streams in the current namespace that trigger dc.
+func deploymentStreams(dc *deployapi.DeploymentConfig) []string {
+	streams := []string{}
+	for _, trigger := range dc.Spec.Triggers {
+		if trigger.ImageChangeParams == nil {
+			continue
+		}
+		if name, ok := localImageStream(&trigger.ImageChangeParams.From); ok {
+			streams = append(streams, name)
+		}
+	}
+	return streams
+}
+
+// renameDeploymentConfigReferences updates the image streams that trigger dc, the containers that run their
+// images, and the selector of dc.
+func renameDeploymentConfigReferences(dc *deployapi.DeploymentConfig, changes NameChanges) {
+	for _, trigger := range dc.Spec.Triggers {
+		params := trigger.ImageChangeParams
+		if params == nil {
+			continue
+		}
+		oldName := params.From.Name
+		renameStreamReference(&params.From, changes)
+		if oldName == params.From.Name || dc.Spec.Template == nil {
+			continue
+		}
+		for i := range dc.Spec.Template.Spec.Containers {
+			if container := &dc.Spec.Template.Spec.Containers[i]; container.Image == oldName {
+				container.Image = params.From.Name
+			}
+		}
+	}
+	dc.Spec.Selector = renameSelector(dc.Spec.Selector, changes)
+	if dc.Spec.Template != nil {
+		dc.Spec.Template.Labels = renameSelector(dc.Spec.Template.Labels, changes)
+	}
+}
+
+// renameSelector returns a copy of selector with the deploymentconfig label, which selects the pods of a
+// deployment config by name, updated. Generated objects share selectors, so they are never changed in place.
+func renameSelector(selector map[string]string, changes NameChanges) map[string]string {
+	name, ok := selector["deploymentconfig"]
+	if !ok {
+		return selector
+	}
+	renamed := make(map[string]string, len(selector))
+	for k, v := range selector {
+		renamed[k] = v
+	}
+	renamed["deploymentconfig"] = changes.Name("DeploymentConfig", name)
+	return renamed
+}
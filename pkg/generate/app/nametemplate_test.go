@@ -0,0 +1,121 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func nameTemplateObjects() Objects {
+	selector := map[string]string{"deploymentconfig": "ruby-ex"}
+	return Objects{
+		&imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "ruby-ex"}},
+		&buildapi.BuildConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: "ruby-ex"},
+			Spec: buildapi.BuildConfigSpec{
+				BuildSpec: buildapi.BuildSpec{
+					Source: buildapi.BuildSource{Git: &buildapi.GitBuildSource{URI: "https://github.com/openshift/ruby-ex"}},
+					Strategy: buildapi.BuildStrategy{SourceStrategy: &buildapi.SourceBuildStrategy{
+						From: kapi.ObjectReference{Kind: "ImageStreamTag", Namespace: "openshift", Name: "ruby:latest"},
+					}},
+					Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "ruby-ex:latest"}},
+				},
+			},
+		},
+		&deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: "ruby-ex"},
+			Spec: deployapi.DeploymentConfigSpec{
+				Selector: selector,
+				Template: &kapi.PodTemplateSpec{
+					ObjectMeta: kapi.ObjectMeta{Labels: selector},
+					Spec:       kapi.PodSpec{Containers: []kapi.Container{{Name: "ruby-ex", Image: "ruby-ex:latest"}}},
+				},
+				Triggers: []deployapi.DeploymentTriggerPolicy{{
+					Type: deployapi.DeploymentTriggerOnImageChange,
+					ImageChangeParams: &deployapi.DeploymentTriggerImageChangeParams{
+						From: kapi.ObjectReference{Kind: "ImageStreamTag", Name: "ruby-ex:latest"},
+					},
+				}},
+			},
+		},
+		&kapi.Service{
+			ObjectMeta: kapi.ObjectMeta{Name: "ruby-ex"},
+			Spec:       kapi.ServiceSpec{Selector: selector},
+		},
+	}
+}
+
+func TestNameTemplateApply(t *testing.T) {
+	tmpl, err := ParseNameTemplate(`team-{{.Name}}{{if eq .Kind "Service"}}-svc{{end}}-{{.Strategy}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := nameTemplateObjects()
+	if _, err := tmpl.Apply(objects); err == nil || !strings.Contains(err.Error(), "not a valid name") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tmpl, err = ParseNameTemplate(`team-{{.Name}}{{if eq .Kind "Service"}}-svc{{end}}{{if .Repository}}-dev{{end}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects = nameTemplateObjects()
+	changes, err := tmpl.Apply(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := changes.Name("DeploymentConfig", "ruby-ex"); name != "team-ruby-ex-dev" {
+		t.Errorf("unexpected name change: %s", name)
+	}
+	if name := changes.Name("DeploymentConfig", "other"); name != "other" {
+		t.Errorf("unexpected name change: %s", name)
+	}
+
+	if is := objects[0].(*imageapi.ImageStream); is.Name != "team-ruby-ex-dev" {
+		t.Errorf("unexpected image stream name: %s", is.Name)
+	}
+	bc := objects[1].(*buildapi.BuildConfig)
+	if bc.Name != "team-ruby-ex-dev" || bc.Spec.Output.To.Name != "team-ruby-ex-dev:latest" || bc.Spec.Strategy.SourceStrategy.From.Name != "ruby:latest" {
+		t.Errorf("unexpected build config: %#v", bc)
+	}
+	dc := objects[2].(*deployapi.DeploymentConfig)
+	if dc.Name != "team-ruby-ex-dev" || dc.Spec.Triggers[0].ImageChangeParams.From.Name != "team-ruby-ex-dev:latest" {
+		t.Errorf("unexpected deployment config: %#v", dc)
+	}
+	if dc.Spec.Template.Spec.Containers[0].Image != "team-ruby-ex-dev:latest" {
+		t.Errorf("unexpected container image: %s", dc.Spec.Template.Spec.Containers[0].Image)
+	}
+	if dc.Spec.Selector["deploymentconfig"] != "team-ruby-ex-dev" || dc.Spec.Template.Labels["deploymentconfig"] != "team-ruby-ex-dev" {
+		t.Errorf("unexpected selector: %v", dc.Spec.Selector)
+	}
+	svc := objects[3].(*kapi.Service)
+	if svc.Name != "team-ruby-ex-svc-dev" || svc.Spec.Selector["deploymentconfig"] != "team-ruby-ex-dev" {
+		t.Errorf("unexpected service: %#v", svc)
+	}
+}
+
+func TestNameTemplateConflicts(t *testing.T) {
+	tmpl, err := ParseNameTemplate(`app`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := Objects{
+		&imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "ruby"}},
+		&imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "mysql"}},
+	}
+	if _, err := tmpl.Apply(objects); err == nil || !strings.Contains(err.Error(), "more than one ImageStream") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ParseNameTemplate(`{{.Name`); err == nil {
+		t.Errorf("expected an invalid template to be rejected")
+	}
+	tmpl, _ = ParseNameTemplate(`{{.Team}}-{{.Name}}`)
+	if _, err := tmpl.Apply(objects); err == nil {
+		t.Errorf("expected an unknown variable to be rejected")
+	}
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/evanphx/json-patch"
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/strategicpatch"
+
+	"github.com/openshift/origin/pkg/api/latest"
+)
+
+// ObjectPatch is a patch that is applied to a generated object, for fields that are not otherwise generated.
+// Patches are written against the latest.Version representation of the object.
+type ObjectPatch struct {
+	// Kind and Name identify the patched object, such as DeploymentConfig and ruby-ex.
+	Kind string
+	Name string
+	// Type is kapi.JSONPatchType, kapi.MergePatchType or kapi.StrategicMergePatchType. Defaults to
+	// kapi.StrategicMergePatchType.
+	Type kapi.PatchType
+	// Patch is the JSON document of the patch.
+	Patch []byte
+}
+
+// String returns the kind and name of the patched object.
+func (p ObjectPatch) String() string {
+	return fmt.Sprintf("%s/%s", p.Kind, p.Name)
+}
+
+// Validate returns an error if the patch does not identify an object or is not a patch of its type.
+func (p ObjectPatch) Validate() error {
+	if len(p.Kind) == 0 || len(p.Name) == 0 {
+		return fmt.Errorf("every patch must have a kind and a name")
+	}
+	switch p.Type {
+	case kapi.JSONPatchType:
+		if _, err := jsonpatch.DecodePatch(p.Patch); err != nil {
+			return fmt.Errorf("the patch of %s is not a valid JSON patch: %v", p, err)
+		}
+	case "", kapi.MergePatchType, kapi.StrategicMergePatchType:
+		patch := map[string]interface{}{}
+		if err := json.Unmarshal(p.Patch, &patch); err != nil {
+			return fmt.Errorf("the patch of %s must be a JSON object: %v", p, err)
+		}
+	default:
+		return fmt.Errorf("the patch of %s has the unknown type %q", p, p.Type)
+	}
+	return nil
+}
+
+// ApplyPatches applies patches to the objects in objects they identify, in order, and returns the patched
+// objects. An error is returned if a patch identifies no object, does not apply, or changes the kind or name of
+// the object.
+func ApplyPatches(objects Objects, patches []ObjectPatch) (Objects, error) {
+	if len(patches) == 0 {
+		return objects, nil
+	}
+	codec := kapi.Codecs.LegacyCodec(latest.Version)
+	result := append(Objects{}, objects...)
+	for _, patch := range patches {
+		if err := patch.Validate(); err != nil {
+			return nil, err
+		}
+		found := false
+		for i, obj := range result {
+			gvk, err := kapi.Scheme.ObjectKind(obj)
+			if err != nil {
+				return nil, err
+			}
+			_, meta, err := objectMetaData(obj)
+			if err != nil || gvk.Kind != patch.Kind || meta.Name != patch.Name {
+				continue
+			}
+			found = true
+			patched, err := applyPatch(obj, patch, codec)
+			if err != nil {
+				return nil, fmt.Errorf("unable to apply the patch of %s: %v", patch, err)
+			}
+			result[i] = patched
+		}
+		if !found {
+			return nil, fmt.Errorf("the patch of %s does not match a generated object", patch)
+		}
+	}
+	return result, nil
+}
+
+func applyPatch(obj runtime.Object, patch ObjectPatch, codec runtime.Codec) (runtime.Object, error) {
+	data, err := runtime.Encode(codec, obj)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = patchData(obj, data, patch); err != nil {
+		return nil, err
+	}
+	patched, err := runtime.Decode(codec, data)
+	if err != nil {
+		return nil, err
+	}
+	_, meta, err := objectMetaData(patched)
+	if err != nil {
+		return nil, err
+	}
+	if gvk, err := kapi.Scheme.ObjectKind(patched); err != nil || gvk.Kind != patch.Kind || meta.Name != patch.Name {
+		return nil, fmt.Errorf("a patch may not change the kind or name of an object")
+	}
+	return patched, nil
+}
+
+// patchData applies patch to data, the latest.Version representation of obj.
+func patchData(obj runtime.Object, data []byte, patch ObjectPatch) ([]byte, error) {
+	switch patch.Type {
+	case kapi.JSONPatchType:
+		ops, err := jsonpatch.DecodePatch(patch.Patch)
+		if err != nil {
+			return nil, err
+		}
+		return ops.Apply(data)
+	case kapi.MergePatchType:
+		return jsonpatch.MergePatch(data, patch.Patch)
+	default:
+		gvk, err := kapi.Scheme.ObjectKind(obj)
+		if err != nil {
+			return nil, err
+		}
+		versioned, err := kapi.Scheme.New(latest.Version.WithKind(gvk.Kind))
+		if err != nil {
+			return nil, err
+		}
+		return strategicpatch.StrategicMergePatch(data, patch.Patch, versioned)
+	}
+}
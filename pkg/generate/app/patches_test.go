@@ -0,0 +1,67 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestApplyPatches(t *testing.T) {
+	objects := nameTemplateObjects()
+	patches := []ObjectPatch{
+		{
+			Kind:  "DeploymentConfig",
+			Name:  "ruby-ex",
+			Patch: []byte(`{"spec":{"template":{"spec":{"containers":[{"name":"ruby-ex","workingDir":"/srv"}]}}}}`),
+		},
+		{
+			Kind:  "DeploymentConfig",
+			Name:  "ruby-ex",
+			Type:  kapi.JSONPatchType,
+			Patch: []byte(`[{"op":"replace","path":"/spec/replicas","value":3}]`),
+		},
+		{
+			Kind:  "Service",
+			Name:  "ruby-ex",
+			Type:  kapi.MergePatchType,
+			Patch: []byte(`{"metadata":{"annotations":{"team":"web"}}}`),
+		},
+	}
+	patched, err := ApplyPatches(objects, patches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dc := patched[2].(*deployapi.DeploymentConfig)
+	if dc.Spec.Replicas != 3 {
+		t.Errorf("unexpected replicas: %d", dc.Spec.Replicas)
+	}
+	if c := dc.Spec.Template.Spec.Containers; len(c) != 1 || c[0].WorkingDir != "/srv" || c[0].Image != "ruby-ex:latest" {
+		t.Errorf("unexpected containers: %#v", c)
+	}
+	if svc := patched[3].(*kapi.Service); svc.Annotations["team"] != "web" || svc.Spec.Selector["deploymentconfig"] != "ruby-ex" {
+		t.Errorf("unexpected service: %#v", svc)
+	}
+	if objects[2].(*deployapi.DeploymentConfig).Spec.Replicas == 3 {
+		t.Errorf("the generated objects were modified")
+	}
+
+	tests := map[string]ObjectPatch{
+		"does not match": {Kind: "Route", Name: "ruby-ex", Patch: []byte(`{}`)},
+		"may not change": {Kind: "Service", Name: "ruby-ex", Patch: []byte(`{"metadata":{"name":"other"}}`)},
+		"unknown type":   {Kind: "Service", Name: "ruby-ex", Type: "text/plain", Patch: []byte(`{}`)},
+		"unable to apply": {
+			Kind:  "Service",
+			Name:  "ruby-ex",
+			Type:  kapi.JSONPatchType,
+			Patch: []byte(`[{"op":"test","path":"/metadata/name","value":"other"}]`),
+		},
+	}
+	for expected, patch := range tests {
+		if _, err := ApplyPatches(nameTemplateObjects(), []ObjectPatch{patch}); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: unexpected error: %v", expected, err)
+		}
+	}
+}
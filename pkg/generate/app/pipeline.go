@@ -15,6 +15,7 @@ import (
 
 	build "github.com/openshift/origin/pkg/build/api"
 	deploy "github.com/openshift/origin/pkg/deploy/api"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
 	image "github.com/openshift/origin/pkg/image/api"
 	route "github.com/openshift/origin/pkg/route/api"
 	"github.com/openshift/origin/pkg/util/docker/dockerfile"
@@ -23,6 +24,7 @@ import (
 // A PipelineBuilder creates Pipeline instances.
 type PipelineBuilder interface {
 	To(string) PipelineBuilder
+	OutputTag(string) PipelineBuilder
 
 	NewBuildPipeline(string, *ComponentMatch, *SourceRepository) (*Pipeline, error)
 	NewImagePipeline(string, *ComponentMatch) (*Pipeline, error)
@@ -47,6 +49,7 @@ type pipelineBuilder struct {
 	environment   Environment
 	outputDocker  bool
 	to            string
+	outputTag     string
 }
 
 func (pb *pipelineBuilder) To(name string) PipelineBuilder {
@@ -54,14 +57,21 @@ func (pb *pipelineBuilder) To(name string) PipelineBuilder {
 	return pb
 }
 
+// OutputTag sets the tag that builds push to when their output names no tag, instead of image.DefaultImageTag.
+func (pb *pipelineBuilder) OutputTag(tag string) PipelineBuilder {
+	pb.outputTag = tag
+	return pb
+}
+
 // NewBuildPipeline creates a new pipeline with components that are expected to
 // be built.
 func (pb *pipelineBuilder) NewBuildPipeline(from string, resolvedMatch *ComponentMatch, sourceRepository *SourceRepository) (*Pipeline, error) {
 	var input *ImageRef
-	if resolvedMatch != nil {
+	// pipeline builds run the Jenkinsfile of the source and have no base image
+	if resolvedMatch != nil && !sourceRepository.IsPipelineBuild() {
 		inputImage, err := InputImageFromMatch(resolvedMatch)
 		if err != nil {
-			return nil, fmt.Errorf("can't build %q: %v", from, err)
+			return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't build %q: %v", from, err)
 		}
 		input = inputImage
 		if !input.AsImageStream {
@@ -72,7 +82,7 @@ func (pb *pipelineBuilder) NewBuildPipeline(from string, resolvedMatch *Componen
 
 	strategy, source, err := StrategyAndSourceForRepository(sourceRepository, input)
 	if err != nil {
-		return nil, fmt.Errorf("can't build %q: %v", from, err)
+		return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't build %q: %v", from, err)
 	}
 
 	var name string
@@ -85,6 +95,9 @@ func (pb *pipelineBuilder) NewBuildPipeline(from string, resolvedMatch *Componen
 		if err != nil {
 			return nil, err
 		}
+		if len(outputImageRef.Tag) == 0 && len(outputImageRef.ID) == 0 {
+			outputImageRef.Tag = pb.outputTag
+		}
 		output.Reference = outputImageRef
 		name, err = pb.nameGenerator.Generate(NameSuggestions{source, output, input})
 		if err != nil {
@@ -95,13 +108,22 @@ func (pb *pipelineBuilder) NewBuildPipeline(from string, resolvedMatch *Componen
 		if err != nil {
 			return nil, err
 		}
+		tag := pb.outputTag
+		if len(tag) == 0 {
+			tag = image.DefaultImageTag
+		}
 		output.Reference = image.DockerImageReference{
 			Name: name,
-			Tag:  image.DefaultImageTag,
+			Tag:  tag,
 		}
 	}
 	source.Name = name
 
+	// pipeline builds do not produce an image
+	if sourceRepository.IsPipelineBuild() {
+		output = nil
+	}
+
 	// Append any exposed ports from Dockerfile to input image
 	if sourceRepository.IsDockerBuild() && sourceRepository.Info() != nil {
 		node := sourceRepository.Info().Dockerfile.AST()
@@ -147,7 +169,7 @@ func (pb *pipelineBuilder) NewBuildPipeline(from string, resolvedMatch *Componen
 func (pb *pipelineBuilder) NewImagePipeline(from string, resolvedMatch *ComponentMatch) (*Pipeline, error) {
 	input, err := InputImageFromMatch(resolvedMatch)
 	if err != nil {
-		return nil, fmt.Errorf("can't include %q: %v", from, err)
+		return nil, generrors.Wrapf(generrors.CodeOf(err), err, "can't include %q: %v", from, err)
 	}
 	if !input.AsImageStream {
 		msg := "Could not find an image stream match for %q. Make sure that a Docker image with that tag is available on the node for the deployment to succeed."
@@ -184,6 +206,10 @@ func (p *Pipeline) NeedsDeployment(env Environment, labels map[string]string, as
 	if p.Deployment != nil {
 		return nil
 	}
+	// a build that produces no image, such as a pipeline build, has nothing to deploy
+	if p.Image == nil {
+		return nil
+	}
 	p.Deployment = &DeploymentConfigRef{
 		Name: p.Name,
 		Images: []*ImageRef{
@@ -402,6 +428,262 @@ func AddRoutes(objects Objects) Objects {
 	return append(objects, routes...)
 }
 
+// httpRoutePorts are the service ports that are assumed to serve HTTP, and whether they serve it over TLS.
+var httpRoutePorts = map[int]bool{80: false, 8080: false, 443: true}
+
+// AddHTTPRoutes adds a route for each service in objects that exposes an HTTP port and that no route in objects
+// already points to. The route targets the first HTTP port of the service, and passes TLS through to the service
+// if the port is 443.
+func AddHTTPRoutes(objects Objects) Objects {
+	routed := map[string]bool{}
+	for _, o := range objects {
+		if r, ok := o.(*route.Route); ok {
+			routed[r.Spec.To.Name] = true
+		}
+	}
+	routes := []runtime.Object{}
+	for _, o := range objects {
+		svc, ok := o.(*kapi.Service)
+		if !ok || routed[svc.Name] {
+			continue
+		}
+		for _, port := range svc.Spec.Ports {
+			tls, ok := httpRoutePorts[port.Port]
+			if !ok || strings.EqualFold(string(port.Protocol), string(kapi.ProtocolUDP)) {
+				continue
+			}
+			r := &route.Route{
+				ObjectMeta: kapi.ObjectMeta{
+					Name:   svc.Name,
+					Labels: svc.Labels,
+				},
+				Spec: route.RouteSpec{
+					To:   kapi.ObjectReference{Kind: "Service", Name: svc.Name},
+					Port: &route.RoutePort{TargetPort: intstr.FromString(port.Name)},
+				},
+			}
+			if tls {
+				r.Spec.TLS = &route.TLSConfig{Termination: route.TLSTerminationPassthrough}
+			}
+			routes = append(routes, r)
+			routed[svc.Name] = true
+			break
+		}
+	}
+	return append(objects, routes...)
+}
+
+// SetDefaultResources sets the limits and requests in resources on the containers of the deployment configs in
+// objects that do not already specify them.
+func SetDefaultResources(objects Objects, resources kapi.ResourceRequirements) {
+	for _, o := range objects {
+		dc, ok := o.(*deploy.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil {
+			continue
+		}
+		for i := range dc.Spec.Template.Spec.Containers {
+			container := &dc.Spec.Template.Spec.Containers[i]
+			if len(container.Resources.Limits) == 0 && len(resources.Limits) > 0 {
+				container.Resources.Limits = kapi.ResourceList{}
+				for k, v := range resources.Limits {
+					container.Resources.Limits[k] = *v.Copy()
+				}
+			}
+			if len(container.Resources.Requests) == 0 && len(resources.Requests) > 0 {
+				container.Resources.Requests = kapi.ResourceList{}
+				for k, v := range resources.Requests {
+					container.Resources.Requests[k] = *v.Copy()
+				}
+			}
+		}
+	}
+}
+
+// SetBuildTestHooks sets hook as the post commit hook of the build configs in objects and, if tag is set, pushes
+// their output to tag of the output image stream instead, so that builds produce a tested image that is kept
+// apart from the images that are deployed.
+func SetBuildTestHooks(objects Objects, tag string, hook build.BuildPostCommitSpec) {
+	for _, o := range objects {
+		bc, ok := o.(*build.BuildConfig)
+		if !ok {
+			continue
+		}
+		bc.Spec.PostCommit = hook
+		to := bc.Spec.Output.To
+		if len(tag) == 0 || to == nil || to.Kind != "ImageStreamTag" {
+			continue
+		}
+		if name, _, ok := image.SplitImageStreamTag(to.Name); ok {
+			to.Name = image.JoinImageStreamTag(name, tag)
+		}
+	}
+}
+
+// SetBuildOutputTags sets tags as the output tags of the build configs in objects that push to an image stream,
+// so that each successful build is also tagged into them.
+func SetBuildOutputTags(objects Objects, tags []string) {
+	for _, o := range objects {
+		bc, ok := o.(*build.BuildConfig)
+		if !ok || bc.Spec.Output.To == nil || bc.Spec.Output.To.Kind != "ImageStreamTag" {
+			continue
+		}
+		build.SetOutputTags(bc, tags)
+	}
+}
+
+// ImageLabels returns labels as the output image labels of a build, ordered by name.
+func ImageLabels(labels map[string]string) []build.ImageLabel {
+	if len(labels) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	imageLabels := make([]build.ImageLabel, 0, len(names))
+	for _, name := range names {
+		imageLabels = append(imageLabels, build.ImageLabel{Name: name, Value: labels[name]})
+	}
+	return imageLabels
+}
+
+// SetBuildOutputImageLabels adds labels to the output image labels of the build configs in objects, replacing the
+// labels with the same name.
+func SetBuildOutputImageLabels(objects Objects, labels map[string]string) {
+	for _, o := range objects {
+		bc, ok := o.(*build.BuildConfig)
+		if !ok {
+			continue
+		}
+		output := &bc.Spec.Output
+		existing := output.ImageLabels[:0]
+		for _, label := range output.ImageLabels {
+			if _, ok := labels[label.Name]; !ok {
+				existing = append(existing, label)
+			}
+		}
+		output.ImageLabels = append(existing, ImageLabels(labels)...)
+	}
+}
+
+// DisableLatestTriggers stops the deployment configs in objects from being deployed automatically when the
+// image stream tags named image.DefaultImageTag that they are triggered by change, so that new images must be
+// deployed explicitly.
+func DisableLatestTriggers(objects Objects) {
+	for _, o := range objects {
+		dc, ok := o.(*deploy.DeploymentConfig)
+		if !ok {
+			continue
+		}
+		for _, t := range dc.Spec.Triggers {
+			params := t.ImageChangeParams
+			if params == nil || params.From.Kind != "ImageStreamTag" {
+				continue
+			}
+			if _, tag, _ := image.SplitImageStreamTag(params.From.Name); tag == image.DefaultImageTag {
+				params.Automatic = false
+			}
+		}
+	}
+}
+
+// ParseWebhookTriggerType returns the type of webhook trigger named by name, github or generic.
+func ParseWebhookTriggerType(name string) (build.BuildTriggerType, error) {
+	switch strings.ToLower(name) {
+	case "github":
+		return build.GitHubWebHookBuildTriggerType, nil
+	case "generic":
+		return build.GenericWebHookBuildTriggerType, nil
+	}
+	return "", fmt.Errorf("the webhook trigger type %q must be github or generic", name)
+}
+
+// SetWebhookTriggers keeps only the webhook triggers of the given types on the build configs in objects, or all
+// of them if types is empty. If secretName is set, the remaining webhook triggers validate requests with the
+// build.WebHookSecretKey value of that Secret instead of a secret stored in the build config.
+func SetWebhookTriggers(objects Objects, types []build.BuildTriggerType, secretName string) {
+	for _, o := range objects {
+		bc, ok := o.(*build.BuildConfig)
+		if !ok {
+			continue
+		}
+		triggers := []build.BuildTriggerPolicy{}
+		for _, t := range bc.Spec.Triggers {
+			var hook *build.WebHookTrigger
+			switch t.Type {
+			case build.GitHubWebHookBuildTriggerType:
+				hook = t.GitHubWebHook
+			case build.GenericWebHookBuildTriggerType:
+				hook = t.GenericWebHook
+			default:
+				triggers = append(triggers, t)
+				continue
+			}
+			if len(types) > 0 && !hasTriggerType(types, t.Type) {
+				continue
+			}
+			if hook != nil && len(secretName) > 0 {
+				hook.Secret = ""
+				hook.SecretReference = &kapi.LocalObjectReference{Name: secretName}
+			}
+			triggers = append(triggers, t)
+		}
+		bc.Spec.Triggers = triggers
+	}
+}
+
+func hasTriggerType(types []build.BuildTriggerType, t build.BuildTriggerType) bool {
+	for _, existing := range types {
+		if existing == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookTriggersOnly keeps only the webhook triggers of the build configs in objects, so that no build is
+// started until a webhook is received, and removes the config change triggers of the deployment configs. The
+// image change triggers of deployment configs on images that are not built by a build config in objects are
+// made manual, so that nothing is deployed before the first build.
+func WebhookTriggersOnly(objects Objects) {
+	built := make(map[string]bool)
+	for _, o := range objects {
+		bc, ok := o.(*build.BuildConfig)
+		if !ok {
+			continue
+		}
+		if to := bc.Spec.Output.To; to != nil && to.Kind == "ImageStreamTag" {
+			built[to.Name] = true
+		}
+		triggers := []build.BuildTriggerPolicy{}
+		for _, t := range bc.Spec.Triggers {
+			switch t.Type {
+			case build.GitHubWebHookBuildTriggerType, build.GenericWebHookBuildTriggerType:
+				triggers = append(triggers, t)
+			}
+		}
+		bc.Spec.Triggers = triggers
+	}
+	for _, o := range objects {
+		dc, ok := o.(*deploy.DeploymentConfig)
+		if !ok {
+			continue
+		}
+		triggers := []deploy.DeploymentTriggerPolicy{}
+		for _, t := range dc.Spec.Triggers {
+			if t.Type == deploy.DeploymentTriggerOnConfigChange {
+				continue
+			}
+			if params := t.ImageChangeParams; params != nil && (params.From.Kind != "ImageStreamTag" || !built[params.From.Name]) {
+				params.Automatic = false
+			}
+			triggers = append(triggers, t)
+		}
+		dc.Spec.Triggers = triggers
+	}
+}
+
 type acceptNew struct{}
 
 // AcceptNew only accepts runtime.Objects with an empty resource version.
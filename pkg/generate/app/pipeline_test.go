@@ -9,8 +9,10 @@ import (
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/intstr"
 
+	buildapi "github.com/openshift/origin/pkg/build/api"
 	deployapi "github.com/openshift/origin/pkg/deploy/api"
 	imageapi "github.com/openshift/origin/pkg/image/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
 )
 
 type portDesc struct {
@@ -231,3 +233,269 @@ func TestAddServices(t *testing.T) {
 		}
 	}
 }
+
+func TestAddHTTPRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    Objects
+		expected map[string]string
+		tls      map[string]bool
+	}{
+		{
+			name: "http ports",
+			input: Objects{
+				fakeDeploymentConfig("web", containerDesc{"test", []portDesc{{25, "tcp"}, {8080, "tcp"}}}),
+				fakeDeploymentConfig("plain", containerDesc{"test", []portDesc{{80, "tcp"}}}),
+				fakeDeploymentConfig("secure", containerDesc{"test", []portDesc{{443, "tcp"}}}),
+			},
+			expected: map[string]string{"web": "8080-tcp", "plain": "80-tcp", "secure": "443-tcp"},
+			tls:      map[string]bool{"secure": true},
+		},
+		{
+			name: "other ports",
+			input: Objects{
+				fakeDeploymentConfig("db", containerDesc{"test", []portDesc{{5432, "tcp"}}}),
+				fakeDeploymentConfig("dns", containerDesc{"test", []portDesc{{80, "udp"}}}),
+			},
+			expected: map[string]string{},
+		},
+		{
+			name: "already routed",
+			input: Objects{
+				fakeDeploymentConfig("web", containerDesc{"test", []portDesc{{8080, "tcp"}}}),
+				&routeapi.Route{ObjectMeta: kapi.ObjectMeta{Name: "custom"}, Spec: routeapi.RouteSpec{To: kapi.ObjectReference{Name: "web"}}},
+			},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, test := range tests {
+		output := AddHTTPRoutes(AddServices(test.input, false))
+		routes := map[string]string{}
+		for _, obj := range output {
+			route, ok := obj.(*routeapi.Route)
+			if !ok || route.Name == "custom" {
+				continue
+			}
+			if route.Spec.To.Kind != "Service" || route.Spec.To.Name != route.Name || route.Spec.Port == nil {
+				t.Errorf("%s: unexpected route: %#v", test.name, route)
+				continue
+			}
+			routes[route.Name] = route.Spec.Port.TargetPort.StrVal
+			if tls := route.Spec.TLS != nil && route.Spec.TLS.Termination == routeapi.TLSTerminationPassthrough; tls != test.tls[route.Name] {
+				t.Errorf("%s: unexpected TLS on route %s: %#v", test.name, route.Name, route.Spec.TLS)
+			}
+		}
+		if !reflect.DeepEqual(routes, test.expected) {
+			t.Errorf("%s: unexpected routes: %v", test.name, routes)
+		}
+	}
+}
+
+func TestSetBuildTestHooks(t *testing.T) {
+	bc := &buildapi.BuildConfig{
+		Spec: buildapi.BuildConfigSpec{
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+		},
+	}
+	docker := &buildapi.BuildConfig{
+		Spec: buildapi.BuildConfigSpec{
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "DockerImage", Name: "registry/app:latest"}},
+			},
+		},
+	}
+	SetBuildTestHooks(Objects{bc, docker}, "test", buildapi.BuildPostCommitSpec{Script: "make test"})
+	if bc.Spec.Output.To.Name != "app:test" || bc.Spec.PostCommit.Script != "make test" {
+		t.Errorf("unexpected build config: %#v", bc.Spec)
+	}
+	if docker.Spec.Output.To.Name != "registry/app:latest" || docker.Spec.PostCommit.Script != "make test" {
+		t.Errorf("unexpected build config: %#v", docker.Spec)
+	}
+}
+
+func TestSetBuildOutputTags(t *testing.T) {
+	bc := &buildapi.BuildConfig{
+		Spec: buildapi.BuildConfigSpec{
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+		},
+	}
+	docker := &buildapi.BuildConfig{
+		Spec: buildapi.BuildConfigSpec{
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "DockerImage", Name: "registry/app:latest"}},
+			},
+		},
+	}
+	SetBuildOutputTags(Objects{bc, docker}, []string{"v${build-number}", "stable"})
+	if tags := buildapi.OutputTagsForBuild(bc, 3); !reflect.DeepEqual(tags, []string{"v3", "stable"}) {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	if bc.Spec.Output.To.Name != "app:latest" {
+		t.Errorf("unexpected output: %#v", bc.Spec.Output.To)
+	}
+	if _, ok := docker.Annotations[buildapi.BuildConfigOutputTagsAnnotation]; ok {
+		t.Errorf("unexpected annotations: %v", docker.Annotations)
+	}
+}
+
+func TestSetBuildOutputImageLabels(t *testing.T) {
+	bc := &buildapi.BuildConfig{
+		Spec: buildapi.BuildConfigSpec{
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{
+					ImageLabels: []buildapi.ImageLabel{{Name: "team", Value: "old"}, {Name: "tier", Value: "web"}},
+				},
+			},
+		},
+	}
+	SetBuildOutputImageLabels(Objects{bc, &kapi.Service{}}, map[string]string{"team": "a", "git-ref": "master"})
+	expected := []buildapi.ImageLabel{{Name: "tier", Value: "web"}, {Name: "git-ref", Value: "master"}, {Name: "team", Value: "a"}}
+	if !reflect.DeepEqual(bc.Spec.Output.ImageLabels, expected) {
+		t.Errorf("unexpected labels: %#v", bc.Spec.Output.ImageLabels)
+	}
+}
+
+func TestPipelineBuilderOutputTag(t *testing.T) {
+	repo, err := NewSourceRepository("https://github.com/openshift/ruby-ex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := &ComponentMatch{
+		Value:       "ruby",
+		Builder:     true,
+		ImageStream: &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "ruby", Namespace: "openshift"}},
+		ImageTag:    "2.2",
+	}
+	pipeline, err := NewPipelineBuilder("", nil, false).OutputTag("release").NewBuildPipeline("ruby", match, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pipeline.NeedsDeployment(nil, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	output, err := pipeline.Build.Output.BuildOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output.To.Name != "ruby-ex:release" {
+		t.Errorf("unexpected output: %#v", output.To)
+	}
+	_, triggers, err := pipeline.Image.DeployableContainer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggers) != 1 || triggers[0].ImageChangeParams.From.Name != "ruby-ex:release" {
+		t.Errorf("unexpected triggers: %#v", triggers)
+	}
+
+	pipeline, err = NewPipelineBuilder("", nil, false).To("app:v1").OutputTag("release").NewBuildPipeline("ruby", match, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pipeline.Image.Reference.Tag != "v1" {
+		t.Errorf("unexpected output reference: %#v", pipeline.Image.Reference)
+	}
+}
+
+func TestDisableLatestTriggers(t *testing.T) {
+	trigger := func(from string) deployapi.DeploymentTriggerPolicy {
+		return deployapi.DeploymentTriggerPolicy{
+			Type: deployapi.DeploymentTriggerOnImageChange,
+			ImageChangeParams: &deployapi.DeploymentTriggerImageChangeParams{
+				Automatic: true,
+				From:      kapi.ObjectReference{Kind: "ImageStreamTag", Name: from},
+			},
+		}
+	}
+	dc := &deployapi.DeploymentConfig{
+		Spec: deployapi.DeploymentConfigSpec{
+			Triggers: []deployapi.DeploymentTriggerPolicy{
+				{Type: deployapi.DeploymentTriggerOnConfigChange},
+				trigger("web:latest"),
+				trigger("mysql:5.6"),
+			},
+		},
+	}
+	DisableLatestTriggers(Objects{dc})
+	if dc.Spec.Triggers[1].ImageChangeParams.Automatic || !dc.Spec.Triggers[2].ImageChangeParams.Automatic {
+		t.Errorf("unexpected triggers: %#v", dc.Spec.Triggers)
+	}
+}
+
+func TestSetWebhookTriggers(t *testing.T) {
+	bc := &buildapi.BuildConfig{
+		Spec: buildapi.BuildConfigSpec{
+			Triggers: []buildapi.BuildTriggerPolicy{
+				{Type: buildapi.GitHubWebHookBuildTriggerType, GitHubWebHook: &buildapi.WebHookTrigger{Secret: "a"}},
+				{Type: buildapi.GenericWebHookBuildTriggerType, GenericWebHook: &buildapi.WebHookTrigger{Secret: "b"}},
+				{Type: buildapi.ConfigChangeBuildTriggerType},
+			},
+		},
+	}
+	types := []buildapi.BuildTriggerType{}
+	for _, name := range []string{"GitHub"} {
+		trigger, err := ParseWebhookTriggerType(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, trigger)
+	}
+	if _, err := ParseWebhookTriggerType("bitbucket"); err == nil {
+		t.Errorf("expected an unknown webhook type to be rejected")
+	}
+	SetWebhookTriggers(Objects{bc}, types, "webhook")
+	triggers := bc.Spec.Triggers
+	if len(triggers) != 2 || triggers[0].Type != buildapi.GitHubWebHookBuildTriggerType || triggers[1].Type != buildapi.ConfigChangeBuildTriggerType {
+		t.Fatalf("unexpected build triggers: %#v", triggers)
+	}
+	hook := triggers[0].GitHubWebHook
+	if len(hook.Secret) != 0 || hook.SecretReference == nil || hook.SecretReference.Name != "webhook" {
+		t.Errorf("unexpected webhook: %#v", hook)
+	}
+}
+
+func TestWebhookTriggersOnly(t *testing.T) {
+	imageTrigger := func(from string) deployapi.DeploymentTriggerPolicy {
+		return deployapi.DeploymentTriggerPolicy{
+			Type: deployapi.DeploymentTriggerOnImageChange,
+			ImageChangeParams: &deployapi.DeploymentTriggerImageChangeParams{
+				Automatic: true,
+				From:      kapi.ObjectReference{Kind: "ImageStreamTag", Name: from},
+			},
+		}
+	}
+	bc := &buildapi.BuildConfig{
+		Spec: buildapi.BuildConfigSpec{
+			Triggers: []buildapi.BuildTriggerPolicy{
+				{Type: buildapi.GitHubWebHookBuildTriggerType, GitHubWebHook: &buildapi.WebHookTrigger{Secret: "a"}},
+				{Type: buildapi.GenericWebHookBuildTriggerType, GenericWebHook: &buildapi.WebHookTrigger{Secret: "b"}},
+				{Type: buildapi.ConfigChangeBuildTriggerType},
+				{Type: buildapi.ImageChangeBuildTriggerType, ImageChange: &buildapi.ImageChangeTrigger{}},
+			},
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "web:latest"}},
+			},
+		},
+	}
+	dc := &deployapi.DeploymentConfig{
+		Spec: deployapi.DeploymentConfigSpec{
+			Triggers: []deployapi.DeploymentTriggerPolicy{
+				{Type: deployapi.DeploymentTriggerOnConfigChange},
+				imageTrigger("web:latest"),
+				imageTrigger("mysql:5.6"),
+			},
+		},
+	}
+	WebhookTriggersOnly(Objects{dc, bc})
+	if len(bc.Spec.Triggers) != 2 || bc.Spec.Triggers[0].Type != buildapi.GitHubWebHookBuildTriggerType || bc.Spec.Triggers[1].Type != buildapi.GenericWebHookBuildTriggerType {
+		t.Errorf("unexpected build triggers: %#v", bc.Spec.Triggers)
+	}
+	if len(dc.Spec.Triggers) != 2 || !dc.Spec.Triggers[0].ImageChangeParams.Automatic || dc.Spec.Triggers[1].ImageChangeParams.Automatic {
+		t.Errorf("unexpected deployment triggers: %#v", dc.Spec.Triggers)
+	}
+}
@@ -0,0 +1,160 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/golang/glog"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+const (
+	// PlatformAnnotation is set by generation on image streams that import a whole repository to the platform
+	// their images are imported for. Image stream authors may also set it on a stream or a tag to a comma
+	// delimited list of the platforms it is available for, which takes precedence over the metadata of the
+	// image the tag points to.
+	PlatformAnnotation = imageapi.ImagePlatformAnnotation
+
+	// PlatformMismatchPenalty is added to the score of matches built for a platform other than the target.
+	PlatformMismatchPenalty = 10.0
+)
+
+// architectureAliases maps the architecture names reported by some tools to the names used by Docker.
+var architectureAliases = map[string]string{
+	"x86_64":  "amd64",
+	"aarch64": "arm64",
+	"armhf":   "arm",
+}
+
+// Platform identifies the operating system and architecture an image is built for.
+type Platform struct {
+	OS           string
+	Architecture string
+}
+
+// ParsePlatform parses a platform of the form os/arch, for instance linux/arm64.
+func ParsePlatform(s string) (Platform, error) {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return Platform{}, fmt.Errorf("the platform %q must be of the form os/arch, for instance linux/amd64", s)
+	}
+	return Platform{OS: strings.ToLower(parts[0]), Architecture: normalizeArchitecture(parts[1])}, nil
+}
+
+func (p Platform) String() string {
+	return fmt.Sprintf("%s/%s", p.OS, p.Architecture)
+}
+
+// Matches returns whether the image is built for the platform, and whether the image reports enough metadata
+// to tell. Images that do not report both an operating system and an architecture are unknown.
+func (p Platform) Matches(image *imageapi.DockerImage) (matches, known bool) {
+	if image == nil || len(image.OS) == 0 || len(image.Architecture) == 0 {
+		return false, false
+	}
+	return strings.ToLower(image.OS) == p.OS && normalizeArchitecture(image.Architecture) == p.Architecture, true
+}
+
+// MatchesDeclared returns whether the comma delimited list of platforms in value includes the platform, and
+// whether value declares any platform. Entries that are not of the form os/arch are ignored.
+func (p Platform) MatchesDeclared(value string) (matches, known bool) {
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if len(s) == 0 {
+			continue
+		}
+		declared, err := ParsePlatform(s)
+		if err != nil {
+			glog.V(4).Infof("Ignoring declared platform: %v", err)
+			continue
+		}
+		known = true
+		if declared == p {
+			return true, true
+		}
+	}
+	return false, known
+}
+
+// MatchesComponent returns whether the match is built for the platform, and whether that can be told. The
+// platforms declared on the image stream tag of the match, or else on its image stream, take precedence over
+// the metadata of its image.
+func (p Platform) MatchesComponent(match *ComponentMatch) (matches, known bool) {
+	if stream := match.ImageStream; stream != nil {
+		if tag, ok := stream.Spec.Tags[match.ImageTag]; ok && len(match.ImageTag) > 0 {
+			if matches, known := p.MatchesDeclared(tag.Annotations[PlatformAnnotation]); known {
+				return matches, true
+			}
+			if matches, known := p.MatchesDeclared(tag.ImportPolicy.Platform); known {
+				return matches, true
+			}
+		}
+		if matches, known := p.MatchesDeclared(stream.Annotations[PlatformAnnotation]); known {
+			return matches, true
+		}
+	}
+	return p.Matches(match.Image)
+}
+
+func normalizeArchitecture(arch string) string {
+	arch = strings.ToLower(arch)
+	if alias, ok := architectureAliases[arch]; ok {
+		return alias
+	}
+	return arch
+}
+
+// PlatformSearcher wraps a searcher and down-ranks the matches built for a platform other than Platform, or
+// drops them if Reject is set. Matches without platform metadata or declared platforms are returned unchanged.
+type PlatformSearcher struct {
+	Searcher Searcher
+	Platform Platform
+	Reject   bool
+}
+
+// Search searches with the wrapped searcher and applies the platform to the matches.
+func (s PlatformSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	matches, errs := s.Searcher.Search(precise, terms...)
+	result := ComponentMatches{}
+	for _, match := range matches {
+		if ok, known := s.Platform.MatchesComponent(match); known && !ok {
+			if s.Reject {
+				glog.V(4).Infof("Ignoring %s, which is not built for %s", match.Name, s.Platform)
+				continue
+			}
+			match.Score += PlatformMismatchPenalty
+		}
+		result = append(result, match)
+	}
+	return result, errs
+}
+
+// Capabilities returns the capabilities of the wrapped searcher.
+func (s PlatformSearcher) Capabilities() SearcherCapabilities {
+	return s.Searcher.Capabilities()
+}
+
+// RecordPlatform sets the platform the images of the image streams in objects were selected for, so that they
+// are imported for that platform. Tags name it in their import policy, and streams that import a whole
+// repository in their PlatformAnnotation.
+func RecordPlatform(objects Objects, platform Platform) {
+	for _, obj := range objects {
+		stream, ok := obj.(*imageapi.ImageStream)
+		if !ok {
+			continue
+		}
+		if len(stream.Spec.DockerImageRepository) > 0 {
+			if stream.Annotations == nil {
+				stream.Annotations = map[string]string{}
+			}
+			stream.Annotations[PlatformAnnotation] = platform.String()
+		}
+		for name, tag := range stream.Spec.Tags {
+			if tag.From == nil || tag.From.Kind != "DockerImage" {
+				continue
+			}
+			tag.ImportPolicy.Platform = platform.String()
+			stream.Spec.Tags[name] = tag
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+type platformTestSearcher ComponentMatches
+
+func (s platformTestSearcher) Search(precise bool, terms ...string) (ComponentMatches, []error) {
+	matches := ComponentMatches{}
+	for _, m := range s {
+		copied := *m
+		matches = append(matches, &copied)
+	}
+	return matches, nil
+}
+
+func (s platformTestSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
+
+func TestParsePlatform(t *testing.T) {
+	platform, err := ParsePlatform("Linux/aarch64")
+	if err != nil || platform.String() != "linux/arm64" {
+		t.Errorf("unexpected platform: %v %v", platform, err)
+	}
+	for _, s := range []string{"", "arm64", "linux/", "linux/arm64/v8"} {
+		if _, err := ParsePlatform(s); err == nil {
+			t.Errorf("%q: expected an error", s)
+		}
+	}
+}
+
+func TestPlatformSearcher(t *testing.T) {
+	searcher := platformTestSearcher{
+		{Name: "amd64", Image: &imageapi.DockerImage{Architecture: "amd64", OS: "linux"}},
+		{Name: "arm64", Image: &imageapi.DockerImage{Architecture: "arm64", OS: "linux"}},
+		{Name: "no-os", Image: &imageapi.DockerImage{Architecture: "amd64"}},
+		{Name: "unknown"},
+	}
+	platform := Platform{OS: "linux", Architecture: "arm64"}
+
+	matches, _ := PlatformSearcher{Searcher: searcher, Platform: platform}.Search(true, "test")
+	scores := map[string]float32{}
+	for _, m := range matches {
+		scores[m.Name] = m.Score
+	}
+	if len(matches) != 4 || scores["amd64"] != PlatformMismatchPenalty || scores["arm64"] != 0 || scores["no-os"] != 0 || scores["unknown"] != 0 {
+		t.Errorf("unexpected matches: %#v", scores)
+	}
+
+	matches, _ = PlatformSearcher{Searcher: searcher, Platform: platform, Reject: true}.Search(true, "test")
+	if len(matches) != 3 || matches[0].Name != "arm64" || matches[1].Name != "no-os" || matches[2].Name != "unknown" {
+		t.Errorf("unexpected matches: %#v", matches)
+	}
+}
+
+func TestPlatformSearcherDeclared(t *testing.T) {
+	stream := &imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Annotations: map[string]string{PlatformAnnotation: "linux/amd64"}},
+		Spec: imageapi.ImageStreamSpec{Tags: map[string]imageapi.TagReference{
+			"multi":   {Annotations: map[string]string{PlatformAnnotation: "linux/amd64, linux/aarch64"}},
+			"amd64":   {ImportPolicy: imageapi.TagImportPolicy{Platform: "linux/amd64"}},
+			"invalid": {Annotations: map[string]string{PlatformAnnotation: "arm64"}},
+			"stream":  {},
+		}},
+	}
+	searcher := platformTestSearcher{
+		// the declared platforms take precedence over the image metadata
+		{Name: "multi", ImageStream: stream, ImageTag: "multi", Image: &imageapi.DockerImage{Architecture: "amd64"}},
+		{Name: "amd64", ImageStream: stream, ImageTag: "amd64"},
+		{Name: "invalid", ImageStream: &imageapi.ImageStream{Spec: stream.Spec}, ImageTag: "invalid", Image: &imageapi.DockerImage{Architecture: "arm64"}},
+		{Name: "stream", ImageStream: stream, ImageTag: "stream"},
+	}
+
+	matches, _ := PlatformSearcher{Searcher: searcher, Platform: Platform{OS: "linux", Architecture: "arm64"}, Reject: true}.Search(true, "test")
+	names := []string{}
+	for _, m := range matches {
+		names = append(names, m.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"multi", "invalid"}) {
+		t.Errorf("unexpected matches: %v", names)
+	}
+}
+
+func TestRecordPlatform(t *testing.T) {
+	tagged := &imageapi.ImageStream{
+		Spec: imageapi.ImageStreamSpec{Tags: map[string]imageapi.TagReference{
+			"latest": {From: &kapi.ObjectReference{Kind: "DockerImage", Name: "mysql"}},
+			"local":  {From: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "mysql:latest"}},
+		}},
+	}
+	repository := &imageapi.ImageStream{Spec: imageapi.ImageStreamSpec{DockerImageRepository: "mysql"}}
+	RecordPlatform(Objects{tagged, repository}, Platform{OS: "linux", Architecture: "arm64"})
+	if tagged.Spec.Tags["latest"].ImportPolicy.Platform != "linux/arm64" {
+		t.Errorf("unexpected tag: %#v", tagged.Spec.Tags["latest"])
+	}
+	if len(tagged.Spec.Tags["local"].ImportPolicy.Platform) > 0 {
+		t.Errorf("unexpected annotation on tag: %#v", tagged.Spec.Tags["local"])
+	}
+	if repository.Annotations[PlatformAnnotation] != "linux/arm64" {
+		t.Errorf("unexpected annotations: %#v", repository.Annotations)
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// PodSettings are pod level settings that are applied to the pods of every generated workload.
+type PodSettings struct {
+	// TerminationGracePeriodSeconds, if set, is how long pods are given to shut down before they are killed.
+	TerminationGracePeriodSeconds *int64
+	// RestartPolicy, if set, is the restart policy of the pods. Deployment configs only support
+	// kapi.RestartPolicyAlways.
+	RestartPolicy kapi.RestartPolicy
+	// DNSPolicy, if set, is the DNS policy of the pods.
+	DNSPolicy kapi.DNSPolicy
+}
+
+// IsEmpty returns true if no setting is set.
+func (s PodSettings) IsEmpty() bool {
+	return s.TerminationGracePeriodSeconds == nil && len(s.RestartPolicy) == 0 && len(s.DNSPolicy) == 0
+}
+
+// Validate returns an error if a setting has an invalid value.
+func (s PodSettings) Validate() error {
+	if s.TerminationGracePeriodSeconds != nil && *s.TerminationGracePeriodSeconds < 0 {
+		return fmt.Errorf("the termination grace period must not be negative")
+	}
+	switch s.RestartPolicy {
+	case "", kapi.RestartPolicyAlways, kapi.RestartPolicyOnFailure, kapi.RestartPolicyNever:
+	default:
+		return fmt.Errorf("the restart policy must be one of %s, %s or %s", kapi.RestartPolicyAlways, kapi.RestartPolicyOnFailure, kapi.RestartPolicyNever)
+	}
+	switch s.DNSPolicy {
+	case "", kapi.DNSClusterFirst, kapi.DNSDefault:
+	default:
+		return fmt.Errorf("the DNS policy must be one of %s or %s", kapi.DNSClusterFirst, kapi.DNSDefault)
+	}
+	return nil
+}
+
+// SetPodSettings applies settings to the pod templates of the deployment configs and to the pods in objects. An
+// error is returned if the restart policy is not supported by a deployment config in objects.
+func SetPodSettings(objects Objects, settings PodSettings) error {
+	for _, obj := range objects {
+		switch t := obj.(type) {
+		case *deployapi.DeploymentConfig:
+			if t.Spec.Template == nil {
+				continue
+			}
+			if len(settings.RestartPolicy) > 0 && settings.RestartPolicy != kapi.RestartPolicyAlways {
+				return fmt.Errorf("the deployment config %q cannot use the restart policy %s, only %s is supported", t.Name, settings.RestartPolicy, kapi.RestartPolicyAlways)
+			}
+			settings.apply(&t.Spec.Template.Spec)
+		case *kapi.Pod:
+			settings.apply(&t.Spec)
+		}
+	}
+	return nil
+}
+
+func (s PodSettings) apply(spec *kapi.PodSpec) {
+	if s.TerminationGracePeriodSeconds != nil {
+		period := *s.TerminationGracePeriodSeconds
+		spec.TerminationGracePeriodSeconds = &period
+	}
+	if len(s.RestartPolicy) > 0 {
+		spec.RestartPolicy = s.RestartPolicy
+	}
+	if len(s.DNSPolicy) > 0 {
+		spec.DNSPolicy = s.DNSPolicy
+	}
+}
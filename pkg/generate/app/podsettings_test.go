@@ -0,0 +1,54 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestSetPodSettings(t *testing.T) {
+	period := int64(120)
+	settings := PodSettings{TerminationGracePeriodSeconds: &period, DNSPolicy: kapi.DNSDefault}
+	if err := settings.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	dc := &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "app"},
+		Spec:       deployapi.DeploymentConfigSpec{Template: &kapi.PodTemplateSpec{}},
+	}
+	pod := &kapi.Pod{Spec: kapi.PodSpec{RestartPolicy: kapi.RestartPolicyNever}}
+	if err := SetPodSettings(Objects{dc, pod, &kapi.Service{}}, settings); err != nil {
+		t.Fatal(err)
+	}
+	for _, spec := range []kapi.PodSpec{dc.Spec.Template.Spec, pod.Spec} {
+		if spec.TerminationGracePeriodSeconds == nil || *spec.TerminationGracePeriodSeconds != 120 || spec.DNSPolicy != kapi.DNSDefault {
+			t.Errorf("unexpected pod spec: %#v", spec)
+		}
+	}
+	if pod.Spec.RestartPolicy != kapi.RestartPolicyNever {
+		t.Errorf("unexpected restart policy: %s", pod.Spec.RestartPolicy)
+	}
+
+	settings = PodSettings{RestartPolicy: kapi.RestartPolicyOnFailure}
+	if err := SetPodSettings(Objects{pod}, settings); err != nil || pod.Spec.RestartPolicy != kapi.RestartPolicyOnFailure {
+		t.Errorf("unexpected restart policy %s and error: %v", pod.Spec.RestartPolicy, err)
+	}
+	if err := SetPodSettings(Objects{dc}, settings); err == nil {
+		t.Errorf("expected the restart policy to be rejected for a deployment config")
+	}
+}
+
+func TestPodSettingsValidate(t *testing.T) {
+	negative := int64(-1)
+	for _, settings := range []PodSettings{
+		{TerminationGracePeriodSeconds: &negative},
+		{RestartPolicy: "Sometimes"},
+		{DNSPolicy: "None"},
+	} {
+		if err := settings.Validate(); err == nil {
+			t.Errorf("expected %#v to be invalid", settings)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	kerrors "k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	buildutil "github.com/openshift/origin/pkg/build/util"
+	"github.com/openshift/origin/pkg/client"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+	"github.com/openshift/origin/pkg/util"
+)
+
+// GenerationPolicyAnnotation may be set on a project by an administrator to a JSON serialized
+// GenerationPolicy that is applied to every application generated into that project.
+const GenerationPolicyAnnotation = "openshift.io/generation-policy"
+
+// GenerationPolicy shapes the objects generated for an application.
+type GenerationPolicy struct {
+	// Labels are added to every generated object that does not already set them.
+	Labels map[string]string `json:"labels,omitempty"`
+	// DefaultLimits are set on every generated container that does not specify limits.
+	DefaultLimits kapi.ResourceList `json:"defaultLimits,omitempty"`
+	// RequireLimits rejects generated containers that have no limits after DefaultLimits are applied.
+	RequireLimits bool `json:"requireLimits,omitempty"`
+	// BannedRegistries are registry hosts that generated objects may not reference images from.
+	BannedRegistries []string `json:"bannedRegistries,omitempty"`
+	// DefaultRouteTermination is the TLS termination set on generated routes that do not define TLS.
+	DefaultRouteTermination routeapi.TLSTerminationType `json:"defaultRouteTermination,omitempty"`
+}
+
+// GenerationPolicyForProject loads the generation policy from the annotations of the named project.
+// A nil policy is returned if the project has no policy or cannot be read by the caller.
+func GenerationPolicyForProject(projects client.ProjectInterface, name string) (*GenerationPolicy, error) {
+	project, err := projects.Get(name)
+	if err != nil {
+		if kerrors.IsNotFound(err) || kerrors.IsForbidden(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	value, ok := project.Annotations[GenerationPolicyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	policy := &GenerationPolicy{}
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, fmt.Errorf("the generation policy of project %q is invalid: %v", name, err)
+	}
+	return policy, nil
+}
+
+// Apply applies the policy to the provided objects, returning an error if an object violates the policy.
+func (p *GenerationPolicy) Apply(objects Objects) error {
+	if p == nil {
+		return nil
+	}
+	banned := sets.NewString(p.BannedRegistries...)
+	for _, obj := range objects {
+		if err := p.applyLabels(obj); err != nil {
+			return err
+		}
+		for _, ref := range imageReferences(obj) {
+			if banned.Has(ref.Registry) {
+				return fmt.Errorf("the image %q uses the registry %q which is not allowed in this project", ref.Exact(), ref.Registry)
+			}
+		}
+		switch t := obj.(type) {
+		case *deployapi.DeploymentConfig:
+			if t.Spec.Template != nil {
+				if err := p.applyLimits(t.Name, &t.Spec.Template.Spec); err != nil {
+					return err
+				}
+			}
+		case *kapi.Pod:
+			if err := p.applyLimits(t.Name, &t.Spec); err != nil {
+				return err
+			}
+		case *routeapi.Route:
+			if t.Spec.TLS == nil && len(p.DefaultRouteTermination) > 0 {
+				t.Spec.TLS = &routeapi.TLSConfig{Termination: p.DefaultRouteTermination}
+			}
+		}
+	}
+	return nil
+}
+
+// applyLabels adds the policy labels that are not already set on obj.
+func (p *GenerationPolicy) applyLabels(obj runtime.Object) error {
+	if len(p.Labels) == 0 {
+		return nil
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil
+	}
+	existing := accessor.GetLabels()
+	missing := map[string]string{}
+	for k, v := range p.Labels {
+		if _, ok := existing[k]; !ok {
+			missing[k] = v
+		}
+	}
+	return util.AddObjectLabels(obj, missing)
+}
+
+// applyLimits sets the default limits on containers without limits and enforces required limits.
+func (p *GenerationPolicy) applyLimits(name string, spec *kapi.PodSpec) error {
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if len(container.Resources.Limits) == 0 && len(p.DefaultLimits) > 0 {
+			container.Resources.Limits = kapi.ResourceList{}
+			for k, v := range p.DefaultLimits {
+				container.Resources.Limits[k] = *v.Copy()
+			}
+		}
+		if p.RequireLimits && len(container.Resources.Limits) == 0 {
+			return fmt.Errorf("container %q in %q must specify resource limits in this project", container.Name, name)
+		}
+	}
+	return nil
+}
+
+// imageReferences returns the Docker image references used by a generated object.
+func imageReferences(obj runtime.Object) []imageapi.DockerImageReference {
+	var specs []string
+	switch t := obj.(type) {
+	case *deployapi.DeploymentConfig:
+		if t.Spec.Template != nil {
+			for _, c := range t.Spec.Template.Spec.Containers {
+				specs = append(specs, c.Image)
+			}
+		}
+	case *kapi.Pod:
+		for _, c := range t.Spec.Containers {
+			specs = append(specs, c.Image)
+		}
+	case *imageapi.ImageStream:
+		specs = append(specs, t.Spec.DockerImageRepository)
+		for _, tag := range t.Spec.Tags {
+			if tag.From != nil && tag.From.Kind == "DockerImage" {
+				specs = append(specs, tag.From.Name)
+			}
+		}
+	case *buildapi.BuildConfig:
+		if from := buildutil.GetImageStreamForStrategy(t.Spec.Strategy); from != nil && from.Kind == "DockerImage" {
+			specs = append(specs, from.Name)
+		}
+	}
+	var refs []imageapi.DockerImageReference
+	for _, spec := range specs {
+		if len(spec) == 0 {
+			continue
+		}
+		ref, err := imageapi.ParseDockerImageReference(spec)
+		if err != nil {
+			continue
+		}
+		refs = append(refs, ref.DockerClientDefaults())
+	}
+	return refs
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+func policyTestObjects(image string, limits kapi.ResourceList) Objects {
+	return Objects{
+		&deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: "app", Labels: map[string]string{"team": "existing"}},
+			Spec: deployapi.DeploymentConfigSpec{
+				Template: &kapi.PodTemplateSpec{
+					Spec: kapi.PodSpec{
+						Containers: []kapi.Container{{Name: "app", Image: image, Resources: kapi.ResourceRequirements{Limits: limits}}},
+					},
+				},
+			},
+		},
+		&routeapi.Route{ObjectMeta: kapi.ObjectMeta{Name: "app"}},
+	}
+}
+
+func TestGenerationPolicyApply(t *testing.T) {
+	memory := resource.MustParse("512Mi")
+	policy := &GenerationPolicy{
+		Labels:                  map[string]string{"team": "policy", "cost-center": "42"},
+		DefaultLimits:           kapi.ResourceList{kapi.ResourceMemory: memory},
+		DefaultRouteTermination: routeapi.TLSTerminationEdge,
+	}
+	objects := policyTestObjects("mysql", nil)
+	if err := policy.Apply(objects); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dc := objects[0].(*deployapi.DeploymentConfig)
+	if dc.Labels["team"] != "existing" || dc.Labels["cost-center"] != "42" {
+		t.Errorf("unexpected labels: %#v", dc.Labels)
+	}
+	limit := dc.Spec.Template.Spec.Containers[0].Resources.Limits[kapi.ResourceMemory]
+	if limit.Cmp(memory) != 0 {
+		t.Errorf("unexpected memory limit: %s", limit.String())
+	}
+	route := objects[1].(*routeapi.Route)
+	if route.Spec.TLS == nil || route.Spec.TLS.Termination != routeapi.TLSTerminationEdge {
+		t.Errorf("unexpected route TLS: %#v", route.Spec.TLS)
+	}
+	if route.Labels["team"] != "policy" {
+		t.Errorf("unexpected route labels: %#v", route.Labels)
+	}
+}
+
+func TestGenerationPolicyViolations(t *testing.T) {
+	tests := map[string]struct {
+		policy  *GenerationPolicy
+		objects Objects
+		err     bool
+	}{
+		"nil policy": {
+			objects: policyTestObjects("docker.io/library/mysql", nil),
+		},
+		"banned registry": {
+			policy:  &GenerationPolicy{BannedRegistries: []string{"docker.io"}},
+			objects: policyTestObjects("mysql", nil),
+			err:     true,
+		},
+		"allowed registry": {
+			policy:  &GenerationPolicy{BannedRegistries: []string{"docker.io"}},
+			objects: policyTestObjects("registry.example.com/mysql", nil),
+		},
+		"missing limits": {
+			policy:  &GenerationPolicy{RequireLimits: true},
+			objects: policyTestObjects("mysql", nil),
+			err:     true,
+		},
+		"explicit limits": {
+			policy:  &GenerationPolicy{RequireLimits: true},
+			objects: policyTestObjects("mysql", kapi.ResourceList{kapi.ResourceCPU: resource.MustParse("1")}),
+		},
+	}
+	for name, test := range tests {
+		err := test.policy.Apply(test.objects)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// VolumeMode describes how the volumes declared by a generated container are backed.
+type VolumeMode string
+
+const (
+	// VolumeModeEmptyDir backs volumes with an empty directory that lives as long as the pod.
+	VolumeModeEmptyDir VolumeMode = "EmptyDir"
+	// VolumeModePersistent backs volumes with a generated persistent volume claim.
+	VolumeModePersistent VolumeMode = "Persistent"
+)
+
+// DefaultPresetVolumeSize is the size requested by the persistent volume claims generated by a preset.
+const DefaultPresetVolumeSize = "1Gi"
+
+// Preset bundles the defaults for a common shape of application.
+type Preset struct {
+	// Name identifies the preset.
+	Name string
+	// Replicas is the number of replicas set on generated deployment configs.
+	Replicas int
+	// Probes adds TCP readiness and liveness probes on the first port of each container that exposes one.
+	Probes bool
+	// Service determines whether services are kept for generated deployment configs.
+	Service bool
+	// ServiceType is set on the services that are kept. Empty leaves the default type.
+	ServiceType kapi.ServiceType
+	// VolumeMode describes how container volumes are backed.
+	VolumeMode VolumeMode
+	// Strategy is the deployment strategy set on generated deployment configs. Empty leaves the default.
+	Strategy deployapi.DeploymentStrategyType
+}
+
+// Presets are the named presets that may be selected when generating an application.
+var Presets = map[string]Preset{
+	"web-service": {
+		Name:        "web-service",
+		Replicas:    2,
+		Probes:      true,
+		Service:     true,
+		ServiceType: kapi.ServiceTypeClusterIP,
+		VolumeMode:  VolumeModeEmptyDir,
+		Strategy:    deployapi.DeploymentStrategyTypeRolling,
+	},
+	"worker": {
+		Name:       "worker",
+		Replicas:   1,
+		VolumeMode: VolumeModeEmptyDir,
+		Strategy:   deployapi.DeploymentStrategyTypeRolling,
+	},
+	// cronjob deployments are created scaled down so that an external scheduler may scale them up when due
+	"cronjob": {
+		Name:       "cronjob",
+		Replicas:   0,
+		VolumeMode: VolumeModeEmptyDir,
+		Strategy:   deployapi.DeploymentStrategyTypeRecreate,
+	},
+	"database": {
+		Name:        "database",
+		Replicas:    1,
+		Probes:      true,
+		Service:     true,
+		ServiceType: kapi.ServiceTypeClusterIP,
+		VolumeMode:  VolumeModePersistent,
+		Strategy:    deployapi.DeploymentStrategyTypeRecreate,
+	},
+}
+
+// PresetNames returns the sorted names of the available presets.
+func PresetNames() []string {
+	names := make([]string, 0, len(Presets))
+	for name := range Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PresetForName returns the named preset or an error if no such preset exists.
+func PresetForName(name string) (*Preset, error) {
+	preset, ok := Presets[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q, must be one of: %s", name, strings.Join(PresetNames(), ", "))
+	}
+	return &preset, nil
+}
+
+// Apply sets the defaults of the preset on the provided objects and returns the resulting objects, which may
+// include additional persistent volume claims and omit services the preset does not expose.
+func (p *Preset) Apply(objects Objects) Objects {
+	if p == nil {
+		return objects
+	}
+	result := Objects{}
+	for _, obj := range objects {
+		switch t := obj.(type) {
+		case *deployapi.DeploymentConfig:
+			result = append(result, t)
+			result = append(result, p.applyDeploymentConfig(t)...)
+		case *kapi.Service:
+			if !p.Service {
+				continue
+			}
+			if len(p.ServiceType) > 0 {
+				t.Spec.Type = p.ServiceType
+			}
+			result = append(result, t)
+		default:
+			result = append(result, obj)
+		}
+	}
+	return result
+}
+
+// applyDeploymentConfig applies the preset to dc and returns any persistent volume claims it requires.
+func (p *Preset) applyDeploymentConfig(dc *deployapi.DeploymentConfig) []runtime.Object {
+	dc.Spec.Replicas = p.Replicas
+	if len(p.Strategy) > 0 {
+		dc.Spec.Strategy.Type = p.Strategy
+	}
+	if dc.Spec.Template == nil {
+		return nil
+	}
+	spec := &dc.Spec.Template.Spec
+	if p.Probes {
+		for i := range spec.Containers {
+			addTCPProbes(&spec.Containers[i])
+		}
+	}
+	if p.VolumeMode != VolumeModePersistent {
+		return nil
+	}
+	claims := []runtime.Object{}
+	for i := range spec.Volumes {
+		volume := &spec.Volumes[i]
+		if volume.EmptyDir == nil {
+			continue
+		}
+		claim := persistentVolumeClaim(fmt.Sprintf("%s-%s", dc.Name, volume.Name), dc.Labels, resource.MustParse(DefaultPresetVolumeSize))
+		volume.VolumeSource = kapi.VolumeSource{
+			PersistentVolumeClaim: &kapi.PersistentVolumeClaimVolumeSource{ClaimName: claim.Name},
+		}
+		claims = append(claims, claim)
+	}
+	return claims
+}
+
+// addTCPProbes adds readiness and liveness probes against the first port of container, unless the container
+// has no ports or already defines a probe.
+func addTCPProbes(container *kapi.Container) {
+	if len(container.Ports) == 0 {
+		return
+	}
+	action := kapi.Handler{
+		TCPSocket: &kapi.TCPSocketAction{Port: intstr.FromInt(container.Ports[0].ContainerPort)},
+	}
+	if container.ReadinessProbe == nil {
+		container.ReadinessProbe = &kapi.Probe{Handler: action, InitialDelaySeconds: 5, TimeoutSeconds: 1}
+	}
+	if container.LivenessProbe == nil {
+		container.LivenessProbe = &kapi.Probe{Handler: action, InitialDelaySeconds: 30, TimeoutSeconds: 1}
+	}
+}
+
+// persistentVolumeClaim returns a claim of size for a single writer.
+func persistentVolumeClaim(name string, labels map[string]string, size resource.Quantity) *kapi.PersistentVolumeClaim {
+	return &kapi.PersistentVolumeClaim{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+		Spec: kapi.PersistentVolumeClaimSpec{
+			AccessModes: []kapi.PersistentVolumeAccessMode{kapi.ReadWriteOnce},
+			Resources: kapi.ResourceRequirements{
+				Requests: kapi.ResourceList{
+					kapi.ResourceStorage: size,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func presetTestObjects() Objects {
+	return Objects{
+		&deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{Name: "db"},
+			Spec: deployapi.DeploymentConfigSpec{
+				Replicas: 1,
+				Template: &kapi.PodTemplateSpec{
+					Spec: kapi.PodSpec{
+						Containers: []kapi.Container{{
+							Name:         "db",
+							Ports:        []kapi.ContainerPort{{ContainerPort: 5432}},
+							VolumeMounts: []kapi.VolumeMount{{Name: "data", MountPath: "/var/lib/data"}},
+						}},
+						Volumes: []kapi.Volume{{
+							Name:         "data",
+							VolumeSource: kapi.VolumeSource{EmptyDir: &kapi.EmptyDirVolumeSource{}},
+						}},
+					},
+				},
+			},
+		},
+		&kapi.Service{ObjectMeta: kapi.ObjectMeta{Name: "db"}},
+	}
+}
+
+func TestPresetDatabase(t *testing.T) {
+	preset, err := PresetForName("database")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	objects := preset.Apply(presetTestObjects())
+	if len(objects) != 3 {
+		t.Fatalf("expected a deployment config, claim and service, got %#v", objects)
+	}
+	dc := objects[0].(*deployapi.DeploymentConfig)
+	if dc.Spec.Strategy.Type != deployapi.DeploymentStrategyTypeRecreate {
+		t.Errorf("unexpected strategy: %s", dc.Spec.Strategy.Type)
+	}
+	container := dc.Spec.Template.Spec.Containers[0]
+	if container.ReadinessProbe == nil || container.LivenessProbe == nil {
+		t.Errorf("expected probes: %#v", container)
+	}
+	claim, ok := objects[1].(*kapi.PersistentVolumeClaim)
+	if !ok || claim.Name != "db-data" {
+		t.Fatalf("unexpected claim: %#v", objects[1])
+	}
+	volume := dc.Spec.Template.Spec.Volumes[0]
+	if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != "db-data" {
+		t.Errorf("unexpected volume: %#v", volume)
+	}
+	if svc := objects[2].(*kapi.Service); svc.Spec.Type != kapi.ServiceTypeClusterIP {
+		t.Errorf("unexpected service type: %s", svc.Spec.Type)
+	}
+}
+
+func TestPresetWorker(t *testing.T) {
+	preset, err := PresetForName("worker")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	objects := preset.Apply(presetTestObjects())
+	if len(objects) != 1 {
+		t.Fatalf("expected only the deployment config, got %#v", objects)
+	}
+	dc := objects[0].(*deployapi.DeploymentConfig)
+	if dc.Spec.Template.Spec.Containers[0].ReadinessProbe != nil {
+		t.Errorf("unexpected probe")
+	}
+	if dc.Spec.Template.Spec.Volumes[0].EmptyDir == nil {
+		t.Errorf("unexpected volume: %#v", dc.Spec.Template.Spec.Volumes[0])
+	}
+}
+
+func TestPresetForNameUnknown(t *testing.T) {
+	if _, err := PresetForName("unknown"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,114 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	kuval "k8s.io/kubernetes/pkg/util/validation"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// PromotionTarget is an image stream tag that the output image of a build is tagged into when it is generated,
+// such as the image stream of a staging project.
+type PromotionTarget struct {
+	// Namespace is the namespace of the image stream. If empty, it is the namespace of the application.
+	Namespace string
+	// Name is the name of the image stream.
+	Name string
+	// Tag is the tag of the image stream. If empty, it is the output tag of the build.
+	Tag string
+}
+
+// ParsePromotionTarget parses a promotion target of the form [namespace/]stream[:tag].
+func ParsePromotionTarget(spec string) (PromotionTarget, error) {
+	target := PromotionTarget{}
+	name := spec
+	if parts := strings.SplitN(spec, "/", 2); len(parts) == 2 {
+		target.Namespace, name = parts[0], parts[1]
+		if !kuval.IsDNS1123Label(target.Namespace) {
+			return target, fmt.Errorf("the promotion target %q must have a valid namespace", spec)
+		}
+	}
+	target.Name = name
+	if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+		target.Name, target.Tag = parts[0], parts[1]
+		if len(target.Tag) == 0 {
+			return target, fmt.Errorf("the promotion target %q must not have an empty tag", spec)
+		}
+	}
+	if !kuval.IsDNS1123Subdomain(target.Name) || strings.ContainsAny(target.Tag, "/:@") {
+		return target, fmt.Errorf("the promotion target %q must be of the form [namespace/]stream[:tag]", spec)
+	}
+	return target, nil
+}
+
+// PromoteOutput tags the output image of the build config in objects into each of the targets, so that the
+// image streams of the targets track the builds of the application. Targets in namespace, the namespace of the
+// application, are added to the image stream in objects with the same name if there is one. An image stream is
+// generated for each other target, in the namespace of the target. An error is returned unless exactly one build
+// config in objects pushes to an image stream.
+func PromoteOutput(objects Objects, targets []PromotionTarget, namespace string) (Objects, error) {
+	var output *kapi.ObjectReference
+	for _, obj := range objects {
+		bc, ok := obj.(*buildapi.BuildConfig)
+		if !ok {
+			continue
+		}
+		if to := bc.Spec.Output.To; to != nil && to.Kind == "ImageStreamTag" && len(to.Namespace) == 0 {
+			if output != nil {
+				return nil, fmt.Errorf("the output image can only be promoted when a single build is generated")
+			}
+			output = to
+		}
+	}
+	if output == nil {
+		return nil, fmt.Errorf("the output image can only be promoted when a build that pushes to an image stream is generated")
+	}
+	outputName, outputTag, _ := imageapi.SplitImageStreamTag(output.Name)
+
+	streams := make(map[string]*imageapi.ImageStream)
+	for _, obj := range objects {
+		if is, ok := obj.(*imageapi.ImageStream); ok && len(is.Namespace) == 0 {
+			streams[is.Name] = is
+		}
+	}
+	for _, target := range targets {
+		from := &kapi.ObjectReference{Kind: "ImageStreamTag", Name: output.Name}
+		key := target.Name
+		if len(target.Namespace) > 0 && target.Namespace != namespace {
+			if len(namespace) == 0 {
+				return nil, fmt.Errorf("the output image can only be promoted into the namespace %q when the namespace of the application is known", target.Namespace)
+			}
+			from.Namespace = namespace
+			key = target.Namespace + "/" + target.Name
+		}
+		tag := target.Tag
+		if len(tag) == 0 {
+			tag = outputTag
+		}
+		if len(from.Namespace) == 0 && target.Name == outputName && tag == outputTag {
+			return nil, fmt.Errorf("the output image cannot be promoted into its own tag %q", output.Name)
+		}
+
+		is, ok := streams[key]
+		if !ok {
+			is = &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: target.Name}}
+			if len(from.Namespace) > 0 {
+				is.Namespace = target.Namespace
+			}
+			streams[key] = is
+			objects = append(objects, is)
+		}
+		if is.Spec.Tags == nil {
+			is.Spec.Tags = make(map[string]imageapi.TagReference)
+		}
+		if existing, ok := is.Spec.Tags[tag]; ok && existing.From != nil && *existing.From != *from {
+			return nil, fmt.Errorf("the image stream tag %s already refers to another image", imageapi.JoinImageStreamTag(key, tag))
+		}
+		is.Spec.Tags[tag] = imageapi.TagReference{Name: tag, From: from}
+	}
+	return objects, nil
+}
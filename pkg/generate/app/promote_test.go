@@ -0,0 +1,79 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestParsePromotionTarget(t *testing.T) {
+	tests := map[string]PromotionTarget{
+		"app":             {Name: "app"},
+		"app:stable":      {Name: "app", Tag: "stable"},
+		"stage/app":       {Namespace: "stage", Name: "app"},
+		"stage/app:ready": {Namespace: "stage", Name: "app", Tag: "ready"},
+	}
+	for spec, expected := range tests {
+		target, err := ParsePromotionTarget(spec)
+		if err != nil || target != expected {
+			t.Errorf("%s: unexpected target %#v and error: %v", spec, target, err)
+		}
+	}
+	for _, spec := range []string{"", "app:", "Stage/app", "stage/app/other", "stage/app:v1:v2"} {
+		if _, err := ParsePromotionTarget(spec); err == nil {
+			t.Errorf("%s: expected an error", spec)
+		}
+	}
+}
+
+func TestPromoteOutput(t *testing.T) {
+	stream := &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: "app"}}
+	bc := &buildapi.BuildConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "app"},
+		Spec: buildapi.BuildConfigSpec{
+			BuildSpec: buildapi.BuildSpec{
+				Output: buildapi.BuildOutput{To: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}},
+			},
+		},
+	}
+	targets := []PromotionTarget{
+		{Name: "app", Tag: "dev"},
+		{Namespace: "dev", Name: "release"},
+		{Namespace: "stage", Name: "app", Tag: "candidate"},
+	}
+	objects, err := PromoteOutput(Objects{stream, bc}, targets, "dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 4 {
+		t.Fatalf("unexpected objects: %#v", objects)
+	}
+	if ref := stream.Spec.Tags["dev"].From; ref == nil || *ref != (kapi.ObjectReference{Kind: "ImageStreamTag", Name: "app:latest"}) {
+		t.Errorf("unexpected tags: %#v", stream.Spec.Tags)
+	}
+	release := objects[2].(*imageapi.ImageStream)
+	if release.Name != "release" || len(release.Namespace) != 0 || release.Spec.Tags["latest"].From.Name != "app:latest" {
+		t.Errorf("unexpected image stream: %#v", release)
+	}
+	stage := objects[3].(*imageapi.ImageStream)
+	if ref := stage.Spec.Tags["candidate"].From; stage.Namespace != "stage" || ref == nil || *ref != (kapi.ObjectReference{Kind: "ImageStreamTag", Namespace: "dev", Name: "app:latest"}) {
+		t.Errorf("unexpected image stream: %#v", stage)
+	}
+
+	if _, err := PromoteOutput(Objects{stream, bc}, []PromotionTarget{{Name: "app"}}, "dev"); err == nil || !strings.Contains(err.Error(), "its own tag") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := PromoteOutput(Objects{bc}, []PromotionTarget{{Namespace: "stage", Name: "app"}}, ""); err == nil {
+		t.Errorf("expected an error without the namespace of the application")
+	}
+	if _, err := PromoteOutput(Objects{stream, bc, bc}, targets, "dev"); err == nil || !strings.Contains(err.Error(), "single build") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := PromoteOutput(Objects{stream}, targets, "dev"); err == nil {
+		t.Errorf("expected an error without a build")
+	}
+}
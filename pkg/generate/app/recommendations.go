@@ -0,0 +1,158 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// RecommendationReason explains why a candidate match of a component was or was not selected.
+type RecommendationReason string
+
+const (
+	// RecommendationSelected is the candidate the component resolved to.
+	RecommendationSelected RecommendationReason = "Selected"
+	// RecommendationNotBuilder is an image that cannot build the source of a component that builds.
+	RecommendationNotBuilder RecommendationReason = "NotBuilder"
+	// RecommendationWrongTag is an image whose tag is not the requested one, or an image stream with no image
+	// for the requested tag.
+	RecommendationWrongTag RecommendationReason = "WrongTag"
+	// RecommendationPartialName is a candidate whose name only partially matches the requested name.
+	RecommendationPartialName RecommendationReason = "PartialName"
+	// RecommendationAmbiguous is a candidate that matches as well as another one, so that neither is selected.
+	RecommendationAmbiguous RecommendationReason = "Ambiguous"
+	// RecommendationLowerScore is a candidate that scored worse than the best candidate.
+	RecommendationLowerScore RecommendationReason = "LowerScore"
+)
+
+// Recommendation is a candidate match of a component, with the reason it was or was not selected.
+type Recommendation struct {
+	Match  *ComponentMatch
+	Reason RecommendationReason
+	// Message describes the reason for the candidate.
+	Message string
+}
+
+// Selected returns true if the component resolved to the candidate.
+func (r Recommendation) Selected() bool {
+	return r.Reason == RecommendationSelected
+}
+
+// ComponentRecommendations are the candidate matches of the value of a component, ranked with the selected
+// candidate first and the others by score.
+type ComponentRecommendations struct {
+	Value           string
+	Recommendations []Recommendation
+}
+
+// Selected returns the candidate the component resolved to, or nil if it resolved to none.
+func (r ComponentRecommendations) Selected() *ComponentMatch {
+	for _, recommendation := range r.Recommendations {
+		if recommendation.Selected() {
+			return recommendation.Match
+		}
+	}
+	return nil
+}
+
+// Recommend ranks the candidates matched for value and explains why each was or was not selected. selected is
+// the match the value resolved to, if any, and builder is true if the component builds source, so that it
+// requires a builder image.
+func Recommend(value string, candidates ComponentMatches, selected *ComponentMatch, builder bool) ComponentRecommendations {
+	name, tag := value, ""
+	if ref, err := imageapi.ParseDockerImageReference(value); err == nil && len(ref.Name) > 0 {
+		name, tag = ref.Name, ref.Tag
+	}
+
+	var best *float32
+	for _, m := range candidates {
+		if best == nil || m.Score < *best {
+			score := m.Score
+			best = &score
+		}
+	}
+
+	recommendations := []Recommendation{}
+	found := false
+	for _, m := range candidates {
+		recommendation := Recommendation{Match: m}
+		switch {
+		case selected != nil && !found && sameMatch(m, selected):
+			found = true
+			recommendation.Reason = RecommendationSelected
+			recommendation.Message = fmt.Sprintf("%s was selected", m.Name)
+		case builder && m.IsImage() && !IsBuilderMatch(m):
+			recommendation.Reason = RecommendationNotBuilder
+			recommendation.Message = fmt.Sprintf("%s is not a builder image and cannot build source", m.Name)
+		case m.ImageStream != nil && m.Image == nil && len(m.ImageID) == 0:
+			recommendation.Reason = RecommendationWrongTag
+			recommendation.Message = fmt.Sprintf("image stream %s has no image for tag %q", m.Name, m.ImageTag)
+		case len(tag) > 0 && m.IsImage() && len(candidateTag(m)) > 0 && candidateTag(m) != tag:
+			recommendation.Reason = RecommendationWrongTag
+			recommendation.Message = fmt.Sprintf("%s has tag %q rather than %q", m.Name, candidateTag(m), tag)
+		case !strings.EqualFold(candidateName(m), name):
+			recommendation.Reason = RecommendationPartialName
+			recommendation.Message = fmt.Sprintf("%q only partially matches %q", candidateName(m), name)
+		case selected == nil && best != nil && m.Score == *best:
+			recommendation.Reason = RecommendationAmbiguous
+			recommendation.Message = fmt.Sprintf("%s matches as well as another candidate, specify it explicitly with %s", m.Name, m.Argument)
+		default:
+			recommendation.Reason = RecommendationLowerScore
+			recommendation.Message = fmt.Sprintf("%s scored %g, worse than the best candidate", m.Name, m.Score)
+		}
+		recommendations = append(recommendations, recommendation)
+	}
+	if selected != nil && !found {
+		recommendations = append(recommendations, Recommendation{
+			Match:   selected,
+			Reason:  RecommendationSelected,
+			Message: fmt.Sprintf("%s was selected", selected.Name),
+		})
+	}
+	sort.Stable(recommendationsByRank(recommendations))
+	return ComponentRecommendations{Value: value, Recommendations: recommendations}
+}
+
+// sameMatch returns true if a and b refer to the same object.
+func sameMatch(a, b *ComponentMatch) bool {
+	return a.Name == b.Name && a.Source() == b.Source() && a.ImageTag == b.ImageTag && a.ImageID == b.ImageID
+}
+
+// candidateName returns the name of the object of m, without its namespace, registry or tag.
+func candidateName(m *ComponentMatch) string {
+	switch {
+	case m.Template != nil:
+		return m.Template.Name
+	case m.ImageStream != nil:
+		return m.ImageStream.Name
+	}
+	if ref, err := imageapi.ParseDockerImageReference(m.Name); err == nil && len(ref.Name) > 0 {
+		return ref.Name
+	}
+	return m.Name
+}
+
+// candidateTag returns the tag of the image of m, if known.
+func candidateTag(m *ComponentMatch) string {
+	if len(m.ImageTag) > 0 {
+		return m.ImageTag
+	}
+	if ref, err := imageapi.ParseDockerImageReference(m.Name); err == nil {
+		return ref.Tag
+	}
+	return ""
+}
+
+// recommendationsByRank orders the selected candidate first and the others by score.
+type recommendationsByRank []Recommendation
+
+func (r recommendationsByRank) Len() int      { return len(r) }
+func (r recommendationsByRank) Swap(i, j int) { r[i], r[j] = r[j], r[i] }
+func (r recommendationsByRank) Less(i, j int) bool {
+	if r[i].Selected() != r[j].Selected() {
+		return r[i].Selected()
+	}
+	return r[i].Match.Score < r[j].Match.Score
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	templateapi "github.com/openshift/origin/pkg/template/api"
+)
+
+func TestRecommend(t *testing.T) {
+	builder := &imageapi.DockerImage{Config: &imageapi.DockerConfig{Env: []string{"STI_SCRIPTS_URL=http://repo/git/ruby"}}}
+	runtime := &imageapi.DockerImage{Config: &imageapi.DockerConfig{}}
+	stream := func(name string) *imageapi.ImageStream {
+		return &imageapi.ImageStream{ObjectMeta: kapi.ObjectMeta{Name: name, Namespace: "openshift"}}
+	}
+	ruby := &ComponentMatch{Name: "openshift/ruby", ImageStream: stream("ruby"), ImageTag: "latest", Image: builder}
+	rubyRuntime := &ComponentMatch{Name: "openshift/ruby-runtime", ImageStream: stream("ruby-runtime"), ImageTag: "latest", Image: runtime, Score: 0.1}
+	rubyEx := &ComponentMatch{Name: "openshift/ruby-ex", ImageStream: stream("ruby-ex"), ImageTag: "latest", Image: builder, Score: 0.1}
+	untagged := &ComponentMatch{Name: "openshift/ruby", ImageStream: stream("ruby"), ImageTag: "2.3", Score: 0.5}
+	dockerRuby := &ComponentMatch{Name: "library/ruby:2.2", Image: builder, ImageTag: "2.2", Score: 1}
+	template := &ComponentMatch{Name: "ruby", Template: &templateapi.Template{ObjectMeta: kapi.ObjectMeta{Name: "ruby"}}}
+
+	tests := map[string]struct {
+		value      string
+		candidates ComponentMatches
+		selected   *ComponentMatch
+		builder    bool
+		expected   []RecommendationReason
+		first      *ComponentMatch
+	}{
+		"selected first and ranked": {
+			value:      "ruby",
+			candidates: ComponentMatches{rubyEx, ruby},
+			selected:   ruby,
+			expected:   []RecommendationReason{RecommendationSelected, RecommendationPartialName},
+			first:      ruby,
+		},
+		"not a builder": {
+			value:      "ruby",
+			candidates: ComponentMatches{ruby, rubyRuntime},
+			selected:   ruby,
+			builder:    true,
+			expected:   []RecommendationReason{RecommendationSelected, RecommendationNotBuilder},
+		},
+		"wrong tag": {
+			value:      "ruby:2.3",
+			candidates: ComponentMatches{untagged, dockerRuby},
+			expected:   []RecommendationReason{RecommendationWrongTag, RecommendationWrongTag},
+			first:      untagged,
+		},
+		"ambiguous": {
+			value:      "ruby",
+			candidates: ComponentMatches{ruby, template, rubyEx},
+			expected:   []RecommendationReason{RecommendationAmbiguous, RecommendationAmbiguous, RecommendationPartialName},
+		},
+		"lower score": {
+			value:      "ruby",
+			candidates: ComponentMatches{ruby, &ComponentMatch{Name: "ruby", Template: template.Template, Score: 0.2}},
+			expected:   []RecommendationReason{RecommendationAmbiguous, RecommendationLowerScore},
+		},
+		"selected without candidates": {
+			value:    "ruby",
+			selected: ruby,
+			expected: []RecommendationReason{RecommendationSelected},
+			first:    ruby,
+		},
+		"no candidates": {
+			value: "ruby",
+		},
+	}
+	for name, test := range tests {
+		r := Recommend(test.value, test.candidates, test.selected, test.builder)
+		if r.Value != test.value {
+			t.Errorf("%s: unexpected value %q", name, r.Value)
+		}
+		reasons := []RecommendationReason{}
+		for _, recommendation := range r.Recommendations {
+			reasons = append(reasons, recommendation.Reason)
+			if len(recommendation.Message) == 0 {
+				t.Errorf("%s: no message for %s", name, recommendation.Match.Name)
+			}
+		}
+		if len(reasons) != len(test.expected) {
+			t.Errorf("%s: unexpected reasons %v", name, reasons)
+			continue
+		}
+		for i := range reasons {
+			if reasons[i] != test.expected[i] {
+				t.Errorf("%s: unexpected reasons %v", name, reasons)
+				break
+			}
+		}
+		if test.first != nil && r.Recommendations[0].Match != test.first {
+			t.Errorf("%s: unexpected first candidate %#v", name, r.Recommendations[0].Match)
+		}
+		if r.Selected() != test.selected {
+			t.Errorf("%s: unexpected selection %#v", name, r.Selected())
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package app
+
+import (
+	"fmt"
+	"net"
+
+	kapierrors "k8s.io/kubernetes/pkg/api/errors"
+	kclient "k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+const (
+	// RegistryServiceNamespace and RegistryServiceName identify the service of the integrated registry.
+	RegistryServiceNamespace = "default"
+	RegistryServiceName      = "docker-registry"
+)
+
+// DiscoverRegistryHost returns the address of the integrated registry service, or false if the service does
+// not exist or has no cluster IP.
+func DiscoverRegistryHost(client kclient.ServicesNamespacer) (string, bool, error) {
+	service, err := client.Services(RegistryServiceNamespace).Get(RegistryServiceName)
+	if kapierrors.IsNotFound(err) || kapierrors.IsForbidden(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if net.ParseIP(service.Spec.ClusterIP) == nil || len(service.Spec.Ports) == 0 {
+		return "", false, nil
+	}
+	return net.JoinHostPort(service.Spec.ClusterIP, fmt.Sprintf("%d", service.Spec.Ports[0].Port)), true, nil
+}
+
+// SetOutputRegistry pushes the Docker image outputs of pipelines that name no registry to the namespace of the
+// registry at host. Outputs to image streams are left alone.
+func SetOutputRegistry(pipelines PipelineGroup, host, namespace string) {
+	for _, p := range pipelines {
+		if p.Build == nil || p.Build.Output == nil {
+			continue
+		}
+		output := p.Build.Output
+		if output.AsImageStream || len(output.Reference.Registry) > 0 {
+			continue
+		}
+		output.Reference.Registry = host
+		if len(output.Reference.Namespace) == 0 {
+			output.Reference.Namespace = namespace
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// DefaultResolutionCacheTTL is the time a cached resolution remains valid when no TTL is specified.
+const DefaultResolutionCacheTTL = 15 * time.Minute
+
+// ResolutionCache stores resolved image and image stream matches on disk so that repeated invocations can
+// skip searching registries and the server. Template matches are never cached.
+type ResolutionCache struct {
+	// Dir is the directory entries are stored in. It should be private to the current user.
+	Dir string
+	// TTL is the time an entry remains valid. Defaults to DefaultResolutionCacheTTL.
+	TTL time.Duration
+	// Bypass ignores existing entries. Successful resolutions still replace the cached entries.
+	Bypass bool
+	// Server and User identify the server resolutions are made against and the user making them. Entries are
+	// only shared by invocations with the same server and user.
+	Server string
+	User   string
+
+	now func() time.Time
+}
+
+// NewResolutionCache creates a cache stored in dir for the resolutions user makes against server, whose entries
+// expire after ttl.
+func NewResolutionCache(dir, server, user string, ttl time.Duration) *ResolutionCache {
+	return &ResolutionCache{Dir: dir, Server: server, User: user, TTL: ttl, now: time.Now}
+}
+
+// cachedMatch is the serialized form of a ComponentMatch.
+type cachedMatch struct {
+	Resolved    time.Time             `json:"resolved"`
+	Value       string                `json:"value"`
+	Argument    string                `json:"argument"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Score       float32               `json:"score"`
+	Insecure    bool                  `json:"insecure,omitempty"`
+	LocalOnly   bool                  `json:"localOnly,omitempty"`
+	Image       *imageapi.DockerImage `json:"image,omitempty"`
+	ImageStream *imageapi.ImageStream `json:"imageStream,omitempty"`
+	ImageTag    string                `json:"imageTag,omitempty"`
+	ImageID     string                `json:"imageID,omitempty"`
+	Meta        map[string]string     `json:"meta,omitempty"`
+}
+
+// Resolver returns a resolver that consults the cache before resolving value with r. Scope distinguishes
+// resolvers whose results for the same value differ, for instance because they search different namespaces.
+func (c *ResolutionCache) Resolver(scope string, r Resolver) Resolver {
+	return cachingResolver{cache: c, scope: scope, resolver: r}
+}
+
+// Get returns the cached match for value in scope, if one exists and has not expired.
+func (c *ResolutionCache) Get(scope, value string) (*ComponentMatch, bool) {
+	if c.Bypass {
+		return nil, false
+	}
+	data, err := ioutil.ReadFile(c.path(scope, value))
+	if err != nil {
+		return nil, false
+	}
+	cached := &cachedMatch{}
+	if err := json.Unmarshal(data, cached); err != nil {
+		glog.V(4).Infof("Ignoring invalid resolution cache entry for %q: %v", value, err)
+		return nil, false
+	}
+	if c.clock().Sub(cached.Resolved) > c.ttl() {
+		return nil, false
+	}
+	return &ComponentMatch{
+		Value:       cached.Value,
+		Argument:    cached.Argument,
+		Name:        cached.Name,
+		Description: cached.Description,
+		Score:       cached.Score,
+		Insecure:    cached.Insecure,
+		LocalOnly:   cached.LocalOnly,
+		Image:       cached.Image,
+		ImageStream: cached.ImageStream,
+		ImageTag:    cached.ImageTag,
+		ImageID:     cached.ImageID,
+		Meta:        cached.Meta,
+	}, true
+}
+
+// Put stores match as the resolution of value in scope. Template matches are ignored.
+func (c *ResolutionCache) Put(scope, value string, match *ComponentMatch) error {
+	if match == nil || match.Template != nil {
+		return nil
+	}
+	data, err := json.Marshal(&cachedMatch{
+		Resolved:    c.clock(),
+		Value:       match.Value,
+		Argument:    match.Argument,
+		Name:        match.Name,
+		Description: match.Description,
+		Score:       match.Score,
+		Insecure:    match.Insecure,
+		LocalOnly:   match.LocalOnly,
+		Image:       match.Image,
+		ImageStream: match.ImageStream,
+		ImageTag:    match.ImageTag,
+		ImageID:     match.ImageID,
+		Meta:        match.Meta,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(c.Dir, "entry")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(scope, value))
+}
+
+// Purge removes every entry from the cache.
+func (c *ResolutionCache) Purge() error {
+	return os.RemoveAll(c.Dir)
+}
+
+func (c *ResolutionCache) path(scope, value string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{c.Server, c.User, scope, value}, "\n")))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *ResolutionCache) ttl() time.Duration {
+	if c.TTL == 0 {
+		return DefaultResolutionCacheTTL
+	}
+	return c.TTL
+}
+
+func (c *ResolutionCache) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
+// cachingResolver resolves values from a ResolutionCache before falling back to another resolver.
+type cachingResolver struct {
+	cache    *ResolutionCache
+	scope    string
+	resolver Resolver
+}
+
+// Resolve implements Resolver.
+func (r cachingResolver) Resolve(value string) (*ComponentMatch, error) {
+	if match, ok := r.cache.Get(r.scope, value); ok {
+		glog.V(4).Infof("Resolved %q from the resolution cache", value)
+		return match, nil
+	}
+	match, err := r.resolver.Resolve(value)
+	if err != nil {
+		return match, err
+	}
+	if err := r.cache.Put(r.scope, value, match); err != nil {
+		glog.V(4).Infof("Unable to cache the resolution of %q: %v", value, err)
+	}
+	return match, nil
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	templateapi "github.com/openshift/origin/pkg/template/api"
+)
+
+type countingResolver struct {
+	match *ComponentMatch
+	calls int
+}
+
+func (r *countingResolver) Resolve(value string) (*ComponentMatch, error) {
+	r.calls++
+	return r.match, nil
+}
+
+func TestResolutionCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resolution-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	cache := NewResolutionCache(dir, "https://server:8443", "alice", time.Minute)
+	cache.now = func() time.Time { return now }
+
+	resolver := &countingResolver{match: &ComponentMatch{
+		Value: "ruby",
+		Name:  "ruby",
+		Image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "1001"}},
+	}}
+	cached := cache.Resolver("component/test", resolver)
+	for i := 0; i < 2; i++ {
+		match, err := cached.Resolve("ruby")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if match.Name != "ruby" || match.Image == nil || match.Image.Config.User != "1001" {
+			t.Errorf("unexpected match: %#v", match)
+		}
+	}
+	if resolver.calls != 1 {
+		t.Errorf("expected the second resolution to be cached, resolved %d times", resolver.calls)
+	}
+	if _, ok := cache.Get("component/other", "ruby"); ok {
+		t.Errorf("expected entries to be scoped")
+	}
+	for _, other := range []*ResolutionCache{
+		NewResolutionCache(dir, "https://server:8443", "bob", time.Minute),
+		NewResolutionCache(dir, "https://other:8443", "alice", time.Minute),
+	} {
+		if _, ok := other.Get("component/test", "ruby"); ok {
+			t.Errorf("expected entries not to be shared with %s on %s", other.User, other.Server)
+		}
+	}
+
+	cache.Bypass = true
+	if _, err := cached.Resolve("ruby"); err != nil || resolver.calls != 2 {
+		t.Errorf("expected the cache to be bypassed: %v %d", err, resolver.calls)
+	}
+	cache.Bypass = false
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get("component/test", "ruby"); ok {
+		t.Errorf("expected the entry to expire")
+	}
+
+	if err := cache.Put("component/test", "template", &ComponentMatch{Template: &templateapi.Template{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := cache.Get("component/test", "template"); ok {
+		t.Errorf("expected templates not to be cached")
+	}
+
+	if err := cache.Purge(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("expected the cache directory to be removed: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/validation"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// DeploymentSecret is a secret that is mounted into every container of the generated deployment configs.
+type DeploymentSecret struct {
+	// Name is the name of the secret.
+	Name string `json:"name"`
+	// MountPath is where the secret is mounted in each container.
+	MountPath string `json:"mountPath"`
+	// ReadWrite mounts the secret writable. Secrets are mounted read only by default.
+	ReadWrite bool `json:"readWrite,omitempty"`
+}
+
+// ParseDeploymentSecret parses a secret in the form name:path[:mode], where mode is ro (the default) or rw.
+func ParseDeploymentSecret(spec string) (DeploymentSecret, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || len(parts) > 3 || len(parts[0]) == 0 || len(parts[1]) == 0 {
+		return DeploymentSecret{}, fmt.Errorf("the secret %q must be in the form name:path[:mode]", spec)
+	}
+	secret := DeploymentSecret{Name: parts[0], MountPath: parts[1]}
+	if ok, _ := validation.ValidateSecretName(secret.Name, false); !ok {
+		return DeploymentSecret{}, fmt.Errorf("%q is not a valid secret name", secret.Name)
+	}
+	if len(parts) == 3 {
+		switch parts[2] {
+		case "ro":
+		case "rw":
+			secret.ReadWrite = true
+		default:
+			return DeploymentSecret{}, fmt.Errorf("the mode of the secret %q must be ro or rw", spec)
+		}
+	}
+	return secret, nil
+}
+
+// AddDeploymentSecrets mounts secrets as volumes into the containers of the deployment configs in objects.
+func AddDeploymentSecrets(objects Objects, secrets []DeploymentSecret) error {
+	names := sets.NewString()
+	for _, s := range secrets {
+		if names.Has(s.Name) {
+			return fmt.Errorf("the secret %q is specified more than once", s.Name)
+		}
+		names.Insert(s.Name)
+	}
+
+	for _, obj := range objects {
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil {
+			continue
+		}
+		spec := &dc.Spec.Template.Spec
+		for _, s := range secrets {
+			name := secretVolumeName(spec, s.Name)
+			spec.Volumes = append(spec.Volumes, kapi.Volume{
+				Name:         name,
+				VolumeSource: kapi.VolumeSource{Secret: &kapi.SecretVolumeSource{SecretName: s.Name}},
+			})
+			for i := range spec.Containers {
+				container := &spec.Containers[i]
+				container.VolumeMounts = append(container.VolumeMounts, kapi.VolumeMount{Name: name, MountPath: s.MountPath, ReadOnly: !s.ReadWrite})
+			}
+		}
+	}
+	return nil
+}
+
+// secretVolumeName returns a name for the volume of secret that no other volume of spec uses. Secret names may
+// contain dots, which volume names may not.
+func secretVolumeName(spec *kapi.PodSpec, secret string) string {
+	used := sets.NewString()
+	for _, v := range spec.Volumes {
+		used.Insert(v.Name)
+	}
+	base := strings.Replace(secret, ".", "-", -1) + "-secret"
+	name := base
+	for i := 2; used.Has(name); i++ {
+		name = fmt.Sprintf("%s-%d", base, i)
+	}
+	return name
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestParseDeploymentSecret(t *testing.T) {
+	tests := map[string]struct {
+		expected DeploymentSecret
+		invalid  bool
+	}{
+		"db-creds:/etc/db":       {expected: DeploymentSecret{Name: "db-creds", MountPath: "/etc/db"}},
+		"db-creds:/etc/db:ro":    {expected: DeploymentSecret{Name: "db-creds", MountPath: "/etc/db"}},
+		"db-creds:/etc/db:rw":    {expected: DeploymentSecret{Name: "db-creds", MountPath: "/etc/db", ReadWrite: true}},
+		"db-creds":               {invalid: true},
+		"db-creds:/etc/db:0400":  {invalid: true},
+		"DB_CREDS:/etc/db":       {invalid: true},
+		":/etc/db":               {invalid: true},
+		"db-creds:/etc/db:rw:ro": {invalid: true},
+	}
+	for spec, test := range tests {
+		secret, err := ParseDeploymentSecret(spec)
+		if (err != nil) != test.invalid {
+			t.Errorf("%s: unexpected error: %v", spec, err)
+			continue
+		}
+		if secret != test.expected {
+			t.Errorf("%s: unexpected secret: %#v", spec, secret)
+		}
+	}
+}
+
+func TestAddDeploymentSecrets(t *testing.T) {
+	dc := &deployapi.DeploymentConfig{
+		Spec: deployapi.DeploymentConfigSpec{
+			Template: &kapi.PodTemplateSpec{
+				Spec: kapi.PodSpec{
+					Volumes:    []kapi.Volume{{Name: "tls-example-com-secret"}},
+					Containers: []kapi.Container{{Name: "web"}, {Name: "proxy"}},
+				},
+			},
+		},
+	}
+	secrets := []DeploymentSecret{
+		{Name: "tls.example.com", MountPath: "/etc/tls"},
+		{Name: "cache", MountPath: "/var/cache/app", ReadWrite: true},
+	}
+	if err := AddDeploymentSecrets(Objects{dc}, secrets); err != nil {
+		t.Fatal(err)
+	}
+	volumes := dc.Spec.Template.Spec.Volumes
+	if len(volumes) != 3 || volumes[1].Name != "tls-example-com-secret-2" || volumes[1].Secret.SecretName != "tls.example.com" || volumes[2].Name != "cache-secret" {
+		t.Errorf("unexpected volumes: %#v", volumes)
+	}
+	for _, c := range dc.Spec.Template.Spec.Containers {
+		if len(c.VolumeMounts) != 2 || !c.VolumeMounts[0].ReadOnly || c.VolumeMounts[1].ReadOnly || c.VolumeMounts[0].Name != "tls-example-com-secret-2" {
+			t.Errorf("unexpected mounts: %#v", c.VolumeMounts)
+		}
+	}
+	if err := AddDeploymentSecrets(Objects{dc}, []DeploymentSecret{secrets[1], secrets[1]}); err == nil {
+		t.Errorf("expected an error for a repeated secret")
+	}
+}
@@ -4,13 +4,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/url"
+	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/docker/docker/builder/parser"
 
 	buildapi "github.com/openshift/origin/pkg/build/api"
 	"github.com/openshift/origin/pkg/generate/dockerfile"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
 	"github.com/openshift/origin/pkg/generate/git"
 	"github.com/openshift/origin/pkg/generate/source"
 	s2iapi "github.com/openshift/source-to-image/pkg/api"
@@ -30,14 +34,14 @@ func NewDockerfileFromFile(path string) (Dockerfile, error) {
 		return nil, err
 	}
 	if len(data) == 0 {
-		return nil, fmt.Errorf("Dockerfile %q is empty", path)
+		return nil, generrors.Newf(generrors.CodeInvalidDockerfile, "Dockerfile %q is empty", path)
 	}
 	return NewDockerfile(string(data))
 }
 
 func NewDockerfile(contents string) (Dockerfile, error) {
 	if len(contents) == 0 {
-		return nil, fmt.Errorf("Dockerfile is empty")
+		return nil, generrors.New(generrors.CodeInvalidDockerfile, "Dockerfile is empty")
 	}
 	node, err := parser.Parse(strings.NewReader(contents))
 	if err != nil {
@@ -88,16 +92,22 @@ type SourceRepository struct {
 	localDir        string
 	remoteURL       *url.URL
 	contextDir      string
+	dockerfilePath  string
+	clones          *CloneCache
+	cloneLimits     CloneLimits
+	auth            *SourceAuth
+	workspace       *Workspace
 	secrets         []buildapi.SecretBuildSource
 	info            *SourceRepositoryInfo
 	sourceImage     ComponentReference
 	sourceImageFrom string
 	sourceImageTo   string
 
-	usedBy           []ComponentReference
-	buildWithDocker  bool
-	ignoreRepository bool
-	binary           bool
+	usedBy            []ComponentReference
+	buildWithDocker   bool
+	buildWithPipeline bool
+	ignoreRepository  bool
+	binary            bool
 
 	forceAddDockerfile bool
 }
@@ -170,6 +180,16 @@ func (r *SourceRepository) IsDockerBuild() bool {
 	return r.buildWithDocker
 }
 
+// BuildWithPipeline specifies that the source repository is built by running its Jenkinsfile
+func (r *SourceRepository) BuildWithPipeline() {
+	r.buildWithPipeline = true
+}
+
+// IsPipelineBuild checks if the source repository is built by running its Jenkinsfile
+func (r *SourceRepository) IsPipelineBuild() bool {
+	return r.buildWithPipeline
+}
+
 func (r *SourceRepository) String() string {
 	return r.location
 }
@@ -184,10 +204,41 @@ func (r *SourceRepository) Detect(d Detector, dockerStrategy bool) error {
 	if err != nil {
 		return err
 	}
+	if len(r.contextDir) > 0 {
+		if fi, err := os.Stat(path); err != nil || !fi.IsDir() {
+			return fmt.Errorf("the context directory %q does not exist in the repository %s", r.contextDir, r)
+		}
+	}
 	r.info, err = d.Detect(path, dockerStrategy)
+	if len(r.dockerfilePath) > 0 && (err == nil || err == ErrNoLanguageDetected) {
+		return r.detectDockerfilePath(path)
+	}
+	if err == ErrNoLanguageDetected && len(r.contextDir) == 0 {
+		if dirs := SuggestContextDirs(d, git.NewRepository(), path, dockerStrategy); len(dirs) > 0 {
+			return &NoLanguageDetectedError{Repository: r.String(), ContextDirs: dirs}
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// detectDockerfilePath reads the Dockerfile at the dockerfile path of the repository, relative to the context
+// directory at path, in place of any Dockerfile at the root of the context directory.
+func (r *SourceRepository) detectDockerfilePath(path string) error {
+	file := filepath.Join(path, r.dockerfilePath)
+	if fi, err := os.Stat(file); err != nil || fi.IsDir() {
+		return generrors.Newf(generrors.CodeNoDockerfile, "the Dockerfile %q does not exist in the context directory of the repository %s", r.dockerfilePath, r)
+	}
+	dockerfile, err := NewDockerfileFromFile(file)
 	if err != nil {
 		return err
 	}
+	if r.info == nil {
+		r.info = &SourceRepositoryInfo{Path: path}
+	}
+	r.info.Dockerfile = dockerfile
 	return nil
 }
 
@@ -210,25 +261,94 @@ func (r *SourceRepository) LocalPath() (string, error) {
 	case r.url.Scheme == "file":
 		r.localDir = filepath.Join(r.url.Path, r.contextDir)
 	default:
-		gitRepo := git.NewRepository()
+		var dir string
 		var err error
-		if r.localDir, err = ioutil.TempDir("", "gen"); err != nil {
+		if r.clones != nil {
+			dir, err = r.clones.Clone(r.url.String(), r.clone)
+		} else {
+			dir, err = r.clone()
+		}
+		if err != nil {
 			return "", err
 		}
-		localURL := r.url
-		ref := localURL.Fragment
-		localURL.Fragment = ""
-		if err = gitRepo.Clone(r.localDir, localURL.String()); err != nil {
-			return "", fmt.Errorf("cannot clone repository %s: %v", localURL.String(), err)
+		r.localDir = filepath.Join(dir, r.contextDir)
+	}
+	return r.localDir, nil
+}
+
+// clone clones the remote source repository into a directory of its workspace, within the clone limits of the
+// repository and the space left in the workspace, and with its credentials, if any. The directory is removed if
+// the clone or the checkout of its ref fails.
+func (r *SourceRepository) clone() (string, error) {
+	gitRepo := git.NewRepository()
+	if r.auth != nil {
+		env, cleanup, err := r.auth.cloneEnv(r.workspace)
+		if err != nil {
+			return "", err
 		}
-		if len(ref) > 0 {
-			if err = gitRepo.Checkout(r.localDir, ref); err != nil {
-				return "", fmt.Errorf("cannot checkout ref %s of repository %s: %v", ref, localURL.String(), err)
-			}
+		defer cleanup()
+		gitRepo = git.NewRepositoryWithEnv(env)
+	}
+	dir, err := r.workspace.TempDir("gen")
+	if err != nil {
+		return "", err
+	}
+	localURL := r.url
+	ref := localURL.Fragment
+	localURL.Fragment = ""
+	// the clone may not take more space than the workspace has left
+	maxSize := r.cloneLimits.MaxSize
+	if remaining := r.workspace.Remaining(); remaining > 0 && (maxSize == 0 || remaining < maxSize) {
+		maxSize = remaining
+	}
+	opts := git.CloneOptions{Recursive: true, Timeout: r.cloneLimits.Timeout, MaxSize: maxSize}
+	if err = gitRepo.CloneWithOptions(dir, localURL.String(), opts); err != nil {
+		r.workspace.Remove(dir)
+		switch err {
+		case git.ErrCloneTimedOut:
+			return "", generrors.Wrapf(generrors.CodeCloneTimedOut, err, "cannot clone repository %s: the clone did not complete within %s", localURL.String(), r.cloneLimits.Timeout)
+		case git.ErrCloneTooLarge:
+			return "", generrors.Wrapf(generrors.CodeCloneTooLarge, err, "cannot clone repository %s: the repository is larger than %d bytes", localURL.String(), maxSize)
 		}
-		r.localDir = filepath.Join(r.localDir, r.contextDir)
+		return "", fmt.Errorf("cannot clone repository %s: %v", localURL.String(), err)
 	}
-	return r.localDir, nil
+	if len(ref) > 0 {
+		if err = gitRepo.Checkout(dir, ref); err != nil {
+			r.workspace.Remove(dir)
+			return "", fmt.Errorf("cannot checkout ref %s of repository %s: %v", ref, localURL.String(), err)
+		}
+	}
+	return dir, nil
+}
+
+// SetCloneCache sets the cache that the source repository is cloned through, so that it is cloned once for all
+// the generations that share the cache.
+func (r *SourceRepository) SetCloneCache(clones *CloneCache) {
+	r.clones = clones
+}
+
+// CloneLimits bound the clone of a remote source repository during detection, so that a single repository
+// cannot take unbounded time or disk space. Zero values do not limit the clone.
+type CloneLimits struct {
+	// Timeout is the longest the clone of a repository may take.
+	Timeout time.Duration
+	// MaxSize is the largest number of bytes the clone of a repository may hold on disk.
+	MaxSize int64
+}
+
+// SetCloneLimits sets the limits the source repository is cloned within, if it is remote.
+func (r *SourceRepository) SetCloneLimits(limits CloneLimits) {
+	r.cloneLimits = limits
+}
+
+// SetWorkspace sets the workspace the source repository is cloned into, if it is remote.
+func (r *SourceRepository) SetWorkspace(workspace *Workspace) {
+	r.workspace = workspace
+}
+
+// SetSourceAuth sets the credentials the source repository is cloned with, if it is remote.
+func (r *SourceRepository) SetSourceAuth(auth *SourceAuth) {
+	r.auth = auth
 }
 
 // RemoteURL returns the remote URL of the source repository
@@ -266,6 +386,29 @@ func (r *SourceRepository) ContextDir() string {
 	return r.contextDir
 }
 
+// ValidateDockerfilePath checks that path is a relative path to a file in the context directory.
+func ValidateDockerfilePath(path string) error {
+	clean := filepath.Clean(path)
+	switch {
+	case filepath.IsAbs(path):
+		return fmt.Errorf("the Dockerfile path %q must be relative to the context directory", path)
+	case clean == "." || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)):
+		return fmt.Errorf("the Dockerfile path %q must be a file in the context directory", path)
+	}
+	return nil
+}
+
+// SetDockerfilePath sets the path of the Dockerfile to build, relative to the context directory, for
+// repositories whose Dockerfile is not at the root of the build context.
+func (r *SourceRepository) SetDockerfilePath(path string) {
+	r.dockerfilePath = path
+}
+
+// DockerfilePath returns the path of the Dockerfile relative to the context directory
+func (r *SourceRepository) DockerfilePath() string {
+	return r.dockerfilePath
+}
+
 // Secrets returns the secrets
 func (r *SourceRepository) Secrets() []buildapi.SecretBuildSource {
 	return r.secrets
@@ -360,6 +503,8 @@ type SourceRepositoryInfo struct {
 	Path       string
 	Types      []SourceLanguageType
 	Dockerfile Dockerfile
+	// Jenkinsfile is true if a Jenkinsfile was found at the root of the repository.
+	Jenkinsfile bool
 }
 
 // Terms returns which languages the source repository was
@@ -400,9 +545,12 @@ type SourceRepositoryEnumerator struct {
 	Tester    dockerfile.Tester
 }
 
+// JenkinsfileName is the name of the file that defines the Jenkins pipeline of a repository.
+const JenkinsfileName = "Jenkinsfile"
+
 // ErrNoLanguageDetected is the error returned when no language can be detected by all
 // source code detectors.
-var ErrNoLanguageDetected = fmt.Errorf("No language matched the source repository")
+var ErrNoLanguageDetected error = generrors.New(generrors.CodeNoLanguageDetected, "No language matched the source repository")
 
 // Detect extracts source code information about the provided source repository
 func (e SourceRepositoryEnumerator) Detect(dir string, dockerStrategy bool) (*SourceRepositoryInfo, error) {
@@ -429,21 +577,107 @@ func (e SourceRepositoryEnumerator) Detect(dir string, dockerStrategy bool) (*So
 		}
 		info.Dockerfile = dockerfile
 	}
+	if fi, err := os.Stat(filepath.Join(dir, JenkinsfileName)); err == nil && !fi.IsDir() {
+		info.Jenkinsfile = true
+	}
 
-	if info.Dockerfile == nil && len(info.Types) == 0 {
+	if info.Dockerfile == nil && len(info.Types) == 0 && !info.Jenkinsfile {
 		return nil, ErrNoLanguageDetected
 	}
 	return info, nil
 }
 
+// MaxContextDirCandidates bounds the number of first level directories of a repository that source is detected
+// in when none is detected at its root.
+const MaxContextDirCandidates = 20
+
+// NoLanguageDetectedError is returned when no source is detected at the root of a repository, but is detected
+// in some of its first level directories, which may be used as its context directory instead.
+type NoLanguageDetectedError struct {
+	Repository string
+	// ContextDirs are the directories source was detected in, most recently changed first.
+	ContextDirs []string
+}
+
+func (e *NoLanguageDetectedError) Error() string {
+	return fmt.Sprintf("no source was detected at the root of the repository %q, but it was detected in %s; please specify one of them as the context directory", e.Repository, strings.Join(e.ContextDirs, ", "))
+}
+
+// Code returns generrors.CodeNoLanguageDetected.
+func (e *NoLanguageDetectedError) Code() generrors.Code {
+	return generrors.CodeNoLanguageDetected
+}
+
+// Cause returns ErrNoLanguageDetected.
+func (e *NoLanguageDetectedError) Cause() error {
+	return ErrNoLanguageDetected
+}
+
+// CommitHistory reports when the paths of a repository last changed.
+type CommitHistory interface {
+	LastCommitTime(dir, path string) (time.Time, error)
+}
+
+// SuggestContextDirs runs d in the first level directories of dir, up to MaxContextDirCandidates of them, and
+// returns those source is detected in. The directories most recently changed in history come first, so that
+// the active applications of a repository holding several are suggested before abandoned ones. Hidden
+// directories are skipped.
+func SuggestContextDirs(d Detector, history CommitHistory, dir string, dockerStrategy bool) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	candidates := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if len(candidates) == MaxContextDirCandidates {
+			break
+		}
+		candidates = append(candidates, entry.Name())
+	}
+
+	changed := make(map[string]time.Time)
+	dirs := []string{}
+	for _, name := range candidates {
+		if _, err := d.Detect(filepath.Join(dir, name), dockerStrategy); err != nil {
+			continue
+		}
+		if history != nil {
+			// the history is only used for ordering, so directories without one are kept
+			changed[name], _ = history.LastCommitTime(dir, name)
+		}
+		dirs = append(dirs, name)
+	}
+	sort.Stable(dirsByChange{dirs: dirs, changed: changed})
+	return dirs
+}
+
+// dirsByChange sorts directories by the time they last changed, most recent first.
+type dirsByChange struct {
+	dirs    []string
+	changed map[string]time.Time
+}
+
+func (d dirsByChange) Len() int      { return len(d.dirs) }
+func (d dirsByChange) Swap(i, j int) { d.dirs[i], d.dirs[j] = d.dirs[j], d.dirs[i] }
+func (d dirsByChange) Less(i, j int) bool {
+	return d.changed[d.dirs[i]].After(d.changed[d.dirs[j]])
+}
+
 // StrategyAndSourceForRepository returns the build strategy and source code reference
 // of the provided source repository
 // TODO: user should be able to choose whether to download a remote source ref for
 // more info
 func StrategyAndSourceForRepository(repo *SourceRepository, image *ImageRef) (*BuildStrategyRef, *SourceRef, error) {
 	strategy := &BuildStrategyRef{
-		Base:          image,
-		IsDockerBuild: repo.IsDockerBuild(),
+		Base:            image,
+		IsDockerBuild:   repo.IsDockerBuild(),
+		IsPipelineBuild: repo.IsPipelineBuild(),
+	}
+	if strategy.IsDockerBuild {
+		strategy.DockerfilePath = repo.dockerfilePath
 	}
 	source := &SourceRef{
 		Binary:  repo.binary,
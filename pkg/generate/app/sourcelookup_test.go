@@ -1,6 +1,18 @@
 package app
 
-import "testing"
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/openshift/origin/pkg/generate/dockerfile"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
 
 func TestAddBuildSecrets(t *testing.T) {
 	type result struct{ name, dest string }
@@ -56,3 +68,197 @@ func TestAddBuildSecrets(t *testing.T) {
 		}
 	}
 }
+
+// fileDetector detects source in directories that hold an app.js file.
+type fileDetector struct{}
+
+func (fileDetector) Detect(dir string, dockerStrategy bool) (*SourceRepositoryInfo, error) {
+	if _, err := os.Stat(filepath.Join(dir, "app.js")); err != nil {
+		return nil, ErrNoLanguageDetected
+	}
+	return &SourceRepositoryInfo{Path: dir, Types: []SourceLanguageType{{Platform: "nodejs"}}}, nil
+}
+
+type fakeHistory map[string]time.Time
+
+func (h fakeHistory) LastCommitTime(dir, path string) (time.Time, error) {
+	return h[path], nil
+}
+
+func TestSuggestContextDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contextdirs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, path := range []string{"api/app.js", "web/app.js", ".hidden/app.js", "docs/README.md", "app/nested/app.js"} {
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, path), []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if dirs := SuggestContextDirs(fileDetector{}, nil, dir, false); !reflect.DeepEqual(dirs, []string{"api", "web"}) {
+		t.Errorf("unexpected context dirs: %v", dirs)
+	}
+	history := fakeHistory{"api": time.Unix(100, 0), "web": time.Unix(200, 0)}
+	if dirs := SuggestContextDirs(fileDetector{}, history, dir, false); !reflect.DeepEqual(dirs, []string{"web", "api"}) {
+		t.Errorf("unexpected context dirs: %v", dirs)
+	}
+
+	repo, err := NewSourceRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = repo.Detect(fileDetector{}, false)
+	suggestion, ok := err.(*NoLanguageDetectedError)
+	if !ok || len(suggestion.ContextDirs) != 2 || generrors.CodeOf(err) != generrors.CodeNoLanguageDetected {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	repo, err = NewSourceRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.SetContextDir("docs")
+	if err := repo.Detect(fileDetector{}, false); err != ErrNoLanguageDetected {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCloneLimits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clonelimits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "data"), make([]byte, 10000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init"}, {"add", "data"}, {"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "data"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+
+	// a path URL is cloned like a remote repository
+	repo := &SourceRepository{location: dir, url: url.URL{Path: dir}}
+	repo.SetCloneLimits(CloneLimits{MaxSize: 1000, Timeout: time.Minute})
+	if _, err := repo.LocalPath(); generrors.CodeOf(err) != generrors.CodeCloneTooLarge {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	repo = &SourceRepository{location: dir, url: url.URL{Path: dir}}
+	repo.SetCloneLimits(CloneLimits{MaxSize: 1000000, Timeout: time.Minute})
+	path, err := repo.LocalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+	if _, err := os.Stat(filepath.Join(path, "data")); err != nil {
+		t.Errorf("unexpected clone: %v", err)
+	}
+}
+
+func TestDetectDockerfilePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dockerfilepath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "build", "Dockerfile"), []byte("FROM centos:7\nCOPY . /src\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewSourceRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.SetDockerfilePath("build/Dockerfile")
+	if err := repo.Detect(fileDetector{}, true); err != nil {
+		t.Fatal(err)
+	}
+	if repo.Info().Dockerfile == nil || repo.Info().Dockerfile.Contents() != "FROM centos:7\nCOPY . /src\n" {
+		t.Fatalf("unexpected info: %#v", repo.Info())
+	}
+	repo.BuildWithDocker()
+	repo.remoteURL = &url.URL{Scheme: "https", Host: "github.com", Path: "/openshift/ruby-hello-world"}
+	strategy, _, err := StrategyAndSourceForRepository(repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, _ := strategy.BuildStrategy(Environment{})
+	if built.DockerStrategy == nil || built.DockerStrategy.DockerfilePath != "build/Dockerfile" {
+		t.Errorf("unexpected strategy: %#v", built)
+	}
+
+	repo, err = NewSourceRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.SetDockerfilePath("Dockerfile")
+	if err := repo.Detect(fileDetector{}, true); generrors.CodeOf(err) != generrors.CodeNoDockerfile {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	repo, err = NewSourceRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.SetContextDir("missing")
+	if err := repo.Detect(fileDetector{}, true); err == nil {
+		t.Errorf("expected a missing context directory to be rejected")
+	}
+
+	for _, path := range []string{"/build/Dockerfile", "../Dockerfile", "build/../..", "."} {
+		if err := ValidateDockerfilePath(path); err == nil {
+			t.Errorf("expected %q to be rejected", path)
+		}
+	}
+	if err := ValidateDockerfilePath("build/Dockerfile"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestDetectJenkinsfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "jenkinsfile")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	detector := SourceRepositoryEnumerator{Tester: dockerfile.NewTester()}
+	if _, err := detector.Detect(dir, false); err != ErrNoLanguageDetected {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, JenkinsfileName), []byte("node {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := detector.Detect(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Jenkinsfile || info.Dockerfile != nil || len(info.Types) != 0 {
+		t.Fatalf("unexpected info: %#v", info)
+	}
+
+	repo, err := NewSourceRepository(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.BuildWithPipeline()
+	repo.remoteURL = &url.URL{Scheme: "https", Host: "github.com", Path: "/openshift/ruby-hello-world"}
+	strategy, _, err := StrategyAndSourceForRepository(repo, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	built, triggers := strategy.BuildStrategy(Environment{})
+	if built.JenkinsPipelineStrategy == nil || built.DockerStrategy != nil || built.SourceStrategy != nil || len(triggers) != 0 {
+		t.Errorf("unexpected strategy: %#v", built)
+	}
+}
@@ -0,0 +1,190 @@
+package app
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang/glog"
+	kapi "k8s.io/kubernetes/pkg/api"
+	kclient "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/labels"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	"github.com/openshift/origin/pkg/generate/git"
+)
+
+// SourceSecretHostLabel is set on secrets to the git host, for instance github.com, whose repositories
+// they grant access to.
+const SourceSecretHostLabel = "openshift.io/source-secret.host"
+
+const (
+	// sourceSecretToken is the key of a source secret that holds a token used as the basic auth password.
+	sourceSecretToken = "token"
+	// sourceSecretDefaultUsername is the basic auth username of source secrets that only hold a password or
+	// token, as used by the builder.
+	sourceSecretDefaultUsername = "builder"
+)
+
+// SourceSecretFinder finds the secrets of a namespace that are labeled for a git host.
+type SourceSecretFinder struct {
+	Client    kclient.SecretsNamespacer
+	Namespace string
+}
+
+// SecretsForHost returns the names of the secrets labeled for host, sorted by name.
+func (f SourceSecretFinder) SecretsForHost(host string) ([]string, error) {
+	selector := labels.SelectorFromSet(labels.Set{SourceSecretHostLabel: strings.ToLower(host)})
+	secrets, err := f.Client.Secrets(f.Namespace).List(kapi.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, secret := range secrets.Items {
+		names = append(names, secret.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SourceSecretWarning describes a build config whose source secret was chosen among several candidates.
+type SourceSecretWarning struct {
+	// BuildConfig is the name of the build config.
+	BuildConfig string
+	// Host is the git host of the build config source.
+	Host string
+	// Selected is the secret that was chosen.
+	Selected string
+	// Candidates are all the secrets labeled for the host.
+	Candidates []string
+}
+
+func (w SourceSecretWarning) String() string {
+	return fmt.Sprintf("build config %q: %d secrets are labeled for %s (%s), using %q", w.BuildConfig, len(w.Candidates), w.Host, strings.Join(w.Candidates, ", "), w.Selected)
+}
+
+// AddSourceSecrets sets the source secret of the build configs in objects that clone a git repository and
+// have none to the secret labeled for the host of the repository. If several secrets are labeled for a host
+// the first by name is used and a warning is returned.
+func AddSourceSecrets(objects Objects, finder SourceSecretFinder) ([]SourceSecretWarning, error) {
+	var warnings []SourceSecretWarning
+	found := map[string][]string{}
+	for _, obj := range objects {
+		bc, ok := obj.(*buildapi.BuildConfig)
+		if !ok || bc.Spec.Source.Git == nil || bc.Spec.Source.SourceSecret != nil {
+			continue
+		}
+		uri, err := git.ParseRepository(bc.Spec.Source.Git.URI)
+		if err != nil || len(uri.Host) == 0 {
+			continue
+		}
+		host := uri.Host
+		if i := strings.LastIndex(host, "@"); i != -1 {
+			host = host[i+1:]
+		}
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		candidates, ok := found[host]
+		if !ok {
+			if candidates, err = finder.SecretsForHost(host); err != nil {
+				return nil, err
+			}
+			found[host] = candidates
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		glog.V(4).Infof("Using secret %q as the source secret of build config %q", candidates[0], bc.Name)
+		bc.Spec.Source.SourceSecret = &kapi.LocalObjectReference{Name: candidates[0]}
+		if len(candidates) > 1 {
+			warnings = append(warnings, SourceSecretWarning{BuildConfig: bc.Name, Host: host, Selected: candidates[0], Candidates: candidates})
+		}
+	}
+	return warnings, nil
+}
+
+// SetSourceSecret sets the source secret of the build configs in objects that clone a git repository and have
+// none to the secret name.
+func SetSourceSecret(objects Objects, name string) {
+	for _, obj := range objects {
+		bc, ok := obj.(*buildapi.BuildConfig)
+		if !ok || bc.Spec.Source.Git == nil || bc.Spec.Source.SourceSecret != nil {
+			continue
+		}
+		bc.Spec.Source.SourceSecret = &kapi.LocalObjectReference{Name: name}
+	}
+}
+
+// SourceAuth holds the credentials of a source secret that remote source repositories are cloned with during
+// detection.
+type SourceAuth struct {
+	// SSHPrivateKey is the key repositories are cloned with over SSH.
+	SSHPrivateKey []byte
+	// Username and Password are the credentials repositories are cloned with over HTTP.
+	Username string
+	Password string
+}
+
+// NewSourceAuth returns the credentials of secret, read from the keys the builder reads source secrets
+// from, or nil if it holds neither an SSH private key nor a password or token.
+func NewSourceAuth(secret *kapi.Secret) *SourceAuth {
+	auth := &SourceAuth{
+		SSHPrivateKey: secret.Data[kapi.SSHAuthPrivateKey],
+		Username:      string(secret.Data[kapi.BasicAuthUsernameKey]),
+		Password:      string(secret.Data[kapi.BasicAuthPasswordKey]),
+	}
+	if len(auth.Password) == 0 {
+		auth.Password = string(secret.Data[sourceSecretToken])
+	}
+	if len(auth.Password) == 0 {
+		auth.Username = ""
+	} else if len(auth.Username) == 0 {
+		auth.Username = sourceSecretDefaultUsername
+	}
+	if len(auth.SSHPrivateKey) == 0 && len(auth.Password) == 0 {
+		return nil
+	}
+	return auth
+}
+
+// cloneEnv returns the environment that git authenticates with the credentials in, and a function that removes
+// the files written for it in workspace. Git never prompts for credentials in the environment, so that a clone
+// the credentials do not grant fails instead of waiting for input.
+func (a *SourceAuth) cloneEnv(workspace *Workspace) ([]string, func(), error) {
+	dir, err := workspace.TempDir("gen-auth")
+	if err != nil {
+		return nil, nil, err
+	}
+	cleanup := func() { workspace.Remove(dir) }
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if len(a.SSHPrivateKey) > 0 {
+		key := filepath.Join(dir, kapi.SSHAuthPrivateKey)
+		if err := ioutil.WriteFile(key, a.SSHPrivateKey, 0600); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		script := filepath.Join(dir, "ssh")
+		contents := fmt.Sprintf("#!/bin/sh\nexec ssh -i '%s' -o IdentitiesOnly=yes -o StrictHostKeyChecking=no -o BatchMode=yes \"$@\"\n", key)
+		if err := ioutil.WriteFile(script, []byte(contents), 0700); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		env = append(env, "GIT_SSH="+script)
+	}
+	if len(a.Password) > 0 {
+		// the credentials are passed to the script in the environment so that they are not written to disk
+		script := filepath.Join(dir, "askpass")
+		contents := "#!/bin/sh\ncase \"$1\" in\nUsername*) echo \"$SOURCE_AUTH_USERNAME\" ;;\n*) echo \"$SOURCE_AUTH_PASSWORD\" ;;\nesac\n"
+		if err := ioutil.WriteFile(script, []byte(contents), 0700); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+		env = append(env, "GIT_ASKPASS="+script, "SOURCE_AUTH_USERNAME="+a.Username, "SOURCE_AUTH_PASSWORD="+a.Password)
+	}
+	return env, cleanup, nil
+}
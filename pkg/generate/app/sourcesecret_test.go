@@ -0,0 +1,183 @@
+package app
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	ktestclient "k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/labels"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+)
+
+func fakeSourceSecretClient(secrets ...kapi.Secret) *ktestclient.Fake {
+	client := &ktestclient.Fake{}
+	client.AddReactor("list", "secrets", func(action ktestclient.Action) (bool, runtime.Object, error) {
+		selector := action.(ktestclient.ListAction).GetListRestrictions().Labels
+		list := &kapi.SecretList{}
+		for _, secret := range secrets {
+			if selector.Matches(labels.Set(secret.Labels)) {
+				list.Items = append(list.Items, secret)
+			}
+		}
+		return true, list, nil
+	})
+	return client
+}
+
+func TestAddSourceSecrets(t *testing.T) {
+	secret := func(name, host string) kapi.Secret {
+		return kapi.Secret{ObjectMeta: kapi.ObjectMeta{Name: name, Labels: map[string]string{SourceSecretHostLabel: host}}}
+	}
+	client := fakeSourceSecretClient(
+		secret("github-b", "github.com"),
+		secret("github-a", "github.com"),
+		secret("internal", "git.example.com"),
+	)
+	bc := func(name, uri string, sourceSecret *kapi.LocalObjectReference) *buildapi.BuildConfig {
+		config := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: name}}
+		config.Spec.Source.Git = &buildapi.GitBuildSource{URI: uri}
+		config.Spec.Source.SourceSecret = sourceSecret
+		return config
+	}
+	objects := Objects{
+		bc("ruby", "https://github.com/openshift/ruby-hello-world.git", nil),
+		bc("internal", "ssh://git@git.example.com/team/app.git", nil),
+		bc("port", "https://git.example.com:8443/team/app.git", nil),
+		bc("unlabeled", "https://gitlab.com/team/app.git", nil),
+		bc("explicit", "https://github.com/openshift/nodejs-ex.git", &kapi.LocalObjectReference{Name: "mine"}),
+	}
+
+	warnings, err := AddSourceSecrets(objects, SourceSecretFinder{Client: client, Namespace: "test"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"ruby": "github-a", "internal": "internal", "port": "internal", "unlabeled": "", "explicit": "mine"}
+	for _, obj := range objects {
+		config := obj.(*buildapi.BuildConfig)
+		name := ""
+		if config.Spec.Source.SourceSecret != nil {
+			name = config.Spec.Source.SourceSecret.Name
+		}
+		if name != expected[config.Name] {
+			t.Errorf("%s: unexpected source secret %q", config.Name, name)
+		}
+	}
+	expectedWarnings := []SourceSecretWarning{{BuildConfig: "ruby", Host: "github.com", Selected: "github-a", Candidates: []string{"github-a", "github-b"}}}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("unexpected warnings: %#v", warnings)
+	}
+	// each host is only looked up once
+	if actions := client.Actions(); len(actions) != 3 {
+		t.Errorf("unexpected actions: %#v", actions)
+	}
+}
+
+func TestSetSourceSecret(t *testing.T) {
+	git := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "git"}}
+	git.Spec.Source.Git = &buildapi.GitBuildSource{URI: "git@github.com:openshift/private.git"}
+	explicit := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "explicit"}}
+	explicit.Spec.Source.Git = &buildapi.GitBuildSource{URI: "https://github.com/openshift/ruby-hello-world.git"}
+	explicit.Spec.Source.SourceSecret = &kapi.LocalObjectReference{Name: "mine"}
+	binary := &buildapi.BuildConfig{ObjectMeta: kapi.ObjectMeta{Name: "binary"}}
+	binary.Spec.Source.Binary = &buildapi.BinaryBuildSource{}
+
+	SetSourceSecret(Objects{git, explicit, binary}, "private")
+	if git.Spec.Source.SourceSecret == nil || git.Spec.Source.SourceSecret.Name != "private" {
+		t.Errorf("unexpected source secret: %#v", git.Spec.Source.SourceSecret)
+	}
+	if explicit.Spec.Source.SourceSecret.Name != "mine" {
+		t.Errorf("unexpected source secret: %#v", explicit.Spec.Source.SourceSecret)
+	}
+	if binary.Spec.Source.SourceSecret != nil {
+		t.Errorf("unexpected source secret: %#v", binary.Spec.Source.SourceSecret)
+	}
+}
+
+func TestNewSourceAuth(t *testing.T) {
+	tests := map[string]struct {
+		data     map[string][]byte
+		expected *SourceAuth
+	}{
+		"ssh": {
+			data:     map[string][]byte{"ssh-privatekey": []byte("key")},
+			expected: &SourceAuth{SSHPrivateKey: []byte("key")},
+		},
+		"basic auth": {
+			data:     map[string][]byte{"username": []byte("user"), "password": []byte("pass")},
+			expected: &SourceAuth{Username: "user", Password: "pass"},
+		},
+		"token": {
+			data:     map[string][]byte{"token": []byte("token")},
+			expected: &SourceAuth{Username: "builder", Password: "token"},
+		},
+		"username only": {
+			data: map[string][]byte{"username": []byte("user")},
+		},
+		"ca cert only": {
+			data: map[string][]byte{"ca.crt": []byte("cert")},
+		},
+	}
+	for name, test := range tests {
+		auth := NewSourceAuth(&kapi.Secret{Data: test.data})
+		if !reflect.DeepEqual(auth, test.expected) {
+			t.Errorf("%s: unexpected auth: %#v", name, auth)
+		}
+	}
+}
+
+func TestSourceAuthCloneEnv(t *testing.T) {
+	auth := &SourceAuth{SSHPrivateKey: []byte("key"), Username: "user", Password: "pass"}
+	env, cleanup, err := auth.cloneEnv(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vars := map[string]string{}
+	for _, v := range env {
+		if parts := strings.SplitN(v, "=", 2); len(parts) == 2 {
+			vars[parts[0]] = parts[1]
+		}
+	}
+	if vars["GIT_TERMINAL_PROMPT"] != "0" {
+		t.Errorf("unexpected environment: %v", env)
+	}
+
+	ssh, err := ioutil.ReadFile(vars["GIT_SSH"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := filepath.Join(filepath.Dir(vars["GIT_SSH"]), "ssh-privatekey")
+	if !strings.Contains(string(ssh), "-i '"+key+"'") {
+		t.Errorf("unexpected ssh script: %s", ssh)
+	}
+	if info, err := os.Stat(key); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("unexpected key file: %v %v", info, err)
+	}
+
+	for prompt, expected := range map[string]string{
+		"Username for 'https://github.com': ":      "user",
+		"Password for 'https://user@github.com': ": "pass",
+	} {
+		cmd := exec.Command(vars["GIT_ASKPASS"], prompt)
+		cmd.Env = env
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.TrimSpace(string(out)) != expected {
+			t.Errorf("%q: unexpected answer %q", prompt, out)
+		}
+	}
+
+	cleanup()
+	if _, err := os.Stat(filepath.Dir(vars["GIT_SSH"])); !os.IsNotExist(err) {
+		t.Errorf("unexpected files left after cleanup: %v", err)
+	}
+}
@@ -2,7 +2,6 @@ package app
 
 import (
 	"fmt"
-	"io/ioutil"
 	"path/filepath"
 
 	"github.com/openshift/origin/pkg/generate/dockerfile"
@@ -23,6 +22,7 @@ type BuildStrategyRefGenerator struct {
 	dockerfileFinder  dockerfile.Finder
 	sourceDetectors   source.Detectors
 	imageRefGenerator ImageRefGenerator
+	workspace         *Workspace
 }
 
 // NewBuildStrategyRefGenerator creates a BuildStrategyRefGenerator
@@ -35,6 +35,11 @@ func NewBuildStrategyRefGenerator(sourceDetectors source.Detectors) *BuildStrate
 	}
 }
 
+// SetWorkspace sets the workspace the sources are cloned into.
+func (g *BuildStrategyRefGenerator) SetWorkspace(workspace *Workspace) {
+	g.workspace = workspace
+}
+
 // FromDockerContextAndParent generates a build strategy ref from a context path and parent image name
 func (g *BuildStrategyRefGenerator) FromDockerContextAndParent(parentRef *ImageRef) (*BuildStrategyRef, error) {
 	return &BuildStrategyRef{
@@ -67,11 +72,12 @@ func (g *BuildStrategyRefGenerator) detectDockerFile(dir string) (contextDir str
 
 func (g *BuildStrategyRefGenerator) getSource(srcRef *SourceRef) error {
 	var err error
-	// Clone git repository into a local directory
-	if srcRef.Dir, err = ioutil.TempDir("", "gen"); err != nil {
+	// Clone git repository into a directory of the workspace
+	if srcRef.Dir, err = g.workspace.TempDir("gen"); err != nil {
 		return err
 	}
 	if err = g.gitRepository.Clone(srcRef.Dir, srcRef.URL.String()); err != nil {
+		g.workspace.Remove(srcRef.Dir)
 		return fmt.Errorf("unable to clone repository at %s", srcRef.URL.String())
 	}
 	if len(srcRef.Ref) != 0 {
@@ -1,6 +1,10 @@
 package app
 
 import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/openshift/origin/pkg/generate/app/test"
@@ -57,6 +61,35 @@ func TestFromDockerContextAndParent(t *testing.T) {
 	}
 }
 
+func TestGetSourceInWorkspace(t *testing.T) {
+	base, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+	workspace := NewWorkspace(base, 0)
+	g := &BuildStrategyRefGenerator{
+		gitRepository:     &test.FakeGit{},
+		dockerfileFinder:  &fakeFinder{},
+		sourceDetectors:   sourceDetectors,
+		imageRefGenerator: NewImageRefGenerator(),
+	}
+	g.SetWorkspace(workspace)
+	srcRef := &SourceRef{URL: &url.URL{Scheme: "https", Host: "github.com", Path: "/foo/bar.git"}}
+	if err := g.getSource(srcRef); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filepath.Dir(srcRef.Dir) != base {
+		t.Errorf("expected the source to be cloned into the workspace, got %s", srcRef.Dir)
+	}
+	if err := workspace.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(srcRef.Dir); !os.IsNotExist(err) {
+		t.Errorf("expected the clone to be removed with the workspace: %v", err)
+	}
+}
+
 type fakeFinder struct {
 	result []string
 }
@@ -0,0 +1,186 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/golang/glog"
+
+	"github.com/openshift/origin/pkg/client"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// DefaultMaxSuggestions is the number of suggestions added to an ErrNoMatch by default.
+const DefaultMaxSuggestions = 3
+
+// MatchSuggester suggests the names of image streams and templates that are close to a value that did not match
+// anything. The names are loaded once and cached for the lifetime of the suggester.
+type MatchSuggester struct {
+	ImageStreams client.ImageStreamsNamespacer
+	Templates    client.TemplatesNamespacer
+	Namespaces   []string
+	// Max is the maximum number of suggestions returned. Defaults to DefaultMaxSuggestions.
+	Max int
+
+	names []string
+}
+
+// NewMatchSuggester creates a suggester for the image streams and templates in namespaces.
+func NewMatchSuggester(imageStreams client.ImageStreamsNamespacer, templates client.TemplatesNamespacer, namespaces []string) *MatchSuggester {
+	return &MatchSuggester{
+		ImageStreams: imageStreams,
+		Templates:    templates,
+		Namespaces:   namespaces,
+	}
+}
+
+// Suggest returns up to Max names close to value, closest first.
+func (s *MatchSuggester) Suggest(value string) []string {
+	if s.names == nil {
+		s.names = s.load()
+	}
+	max := s.Max
+	if max == 0 {
+		max = DefaultMaxSuggestions
+	}
+	return SuggestNames(value, s.names, max)
+}
+
+// load lists the names of the image streams and templates, ignoring namespaces that cannot be read.
+func (s *MatchSuggester) load() []string {
+	names := sets.NewString()
+	for _, namespace := range s.Namespaces {
+		if s.ImageStreams != nil {
+			streams, err := s.ImageStreams.ImageStreams(namespace).List(kapi.ListOptions{})
+			if err != nil {
+				glog.V(4).Infof("Unable to list image streams in %s for suggestions: %v", namespace, err)
+			} else {
+				for _, stream := range streams.Items {
+					names.Insert(stream.Name)
+				}
+			}
+		}
+		if s.Templates != nil {
+			templates, err := s.Templates.Templates(namespace).List(kapi.ListOptions{})
+			if err != nil {
+				glog.V(4).Infof("Unable to list templates in %s for suggestions: %v", namespace, err)
+			} else {
+				for _, template := range templates.Items {
+					names.Insert(template.Name)
+				}
+			}
+		}
+	}
+	return names.List()
+}
+
+// AddSuggestions returns err with suggestions from suggest added to every ErrNoMatch it contains. Aggregate
+// errors are searched.
+func AddSuggestions(err error, suggest func(string) []string) error {
+	switch t := err.(type) {
+	case ErrNoMatch:
+		if len(t.Suggestions) == 0 {
+			t.Suggestions = suggest(t.Value)
+		}
+		return t
+	case errors.Aggregate:
+		errs := []error{}
+		for _, err := range t.Errors() {
+			errs = append(errs, AddSuggestions(err, suggest))
+		}
+		return errors.NewAggregate(errs)
+	default:
+		return err
+	}
+}
+
+// SuggestNames returns up to max candidates whose edit distance from value is small relative to the length of
+// value, closest first. Image references are compared by name, ignoring the registry, namespace and tag.
+func SuggestNames(value string, candidates []string, max int) []string {
+	name := value
+	if ref, err := imageapi.ParseDockerImageReference(value); err == nil && len(ref.Name) > 0 {
+		name = ref.Name
+	}
+	name = strings.ToLower(name)
+	threshold := len(name) / 3
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	scored := []scoredName{}
+	for _, candidate := range candidates {
+		if candidate == name {
+			continue
+		}
+		if d := editDistance(name, strings.ToLower(candidate)); d <= threshold {
+			scored = append(scored, scoredName{name: candidate, distance: d})
+		}
+	}
+	sort.Sort(scoredNames(scored))
+	suggestions := []string{}
+	for i := 0; i < len(scored) && i < max; i++ {
+		suggestions = append(suggestions, scored[i].name)
+	}
+	return suggestions
+}
+
+type scoredName struct {
+	name     string
+	distance int
+}
+
+type scoredNames []scoredName
+
+func (n scoredNames) Len() int      { return len(n) }
+func (n scoredNames) Swap(i, j int) { n[i], n[j] = n[j], n[i] }
+func (n scoredNames) Less(i, j int) bool {
+	if n[i].distance == n[j].distance {
+		return n[i].name < n[j].name
+	}
+	return n[i].distance < n[j].distance
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+	return min
+}
+
+// quotedList formats names as a comma separated list of quoted strings.
+func quotedList(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("%q", name))
+	}
+	return strings.Join(quoted, " or ")
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/util/errors"
+)
+
+func TestSuggestNames(t *testing.T) {
+	candidates := []string{"ruby", "nodejs", "mysql", "mongodb", "python", "rubygems"}
+	tests := map[string][]string{
+		"rubby":                      {"ruby"},
+		"mysq":                       {"mysql"},
+		"docker.io/library/pyhton:3": {"python"},
+		"ruby":                       {},
+		"postgresql":                 {},
+	}
+	for value, expected := range tests {
+		if actual := SuggestNames(value, candidates, DefaultMaxSuggestions); !reflect.DeepEqual(expected, actual) {
+			t.Errorf("%s: expected %v, got %v", value, expected, actual)
+		}
+	}
+	if actual := SuggestNames("mongo", []string{"mongob", "mongoa", "mango", "mongodb"}, 2); !reflect.DeepEqual([]string{"mango", "mongoa"}, actual) {
+		t.Errorf("unexpected ordering or limit: %v", actual)
+	}
+}
+
+func TestAddSuggestions(t *testing.T) {
+	suggest := func(value string) []string { return []string{value + "1"} }
+	other := fmt.Errorf("other")
+	err := AddSuggestions(errors.NewAggregate([]error{ErrNoMatch{Value: "ruby"}, other}), suggest)
+	agg, ok := err.(errors.Aggregate)
+	if !ok || len(agg.Errors()) != 2 {
+		t.Fatalf("unexpected error: %#v", err)
+	}
+	noMatch, ok := agg.Errors()[0].(ErrNoMatch)
+	if !ok || !reflect.DeepEqual(noMatch.Suggestions, []string{"ruby1"}) {
+		t.Errorf("unexpected error: %#v", agg.Errors()[0])
+	}
+	if noMatch.Error() != `no match for "ruby", did you mean "ruby1"?` {
+		t.Errorf("unexpected message: %s", noMatch.Error())
+	}
+	if agg.Errors()[1] != other {
+		t.Errorf("unexpected error: %v", agg.Errors()[1])
+	}
+}
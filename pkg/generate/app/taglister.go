@@ -0,0 +1,37 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/openshift/origin/pkg/dockerregistry"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// TagLister lists the tags of a Docker image repository.
+type TagLister interface {
+	ImageTags(ref imageapi.DockerImageReference) ([]string, error)
+}
+
+// RegistryTagLister lists the tags of repositories in Docker registries.
+type RegistryTagLister struct {
+	Client        dockerregistry.Client
+	AllowInsecure bool
+}
+
+// ImageTags returns the sorted tags of the repository of ref.
+func (l RegistryTagLister) ImageTags(ref imageapi.DockerImageReference) ([]string, error) {
+	connection, err := l.Client.Connect(ref.Registry, l.AllowInsecure)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := connection.ImageTags(ref.Namespace, ref.Name)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(tags))
+	for tag := range tags {
+		names = append(names, tag)
+	}
+	sort.Strings(names)
+	return names, nil
+}
@@ -0,0 +1,135 @@
+package app
+
+import (
+	"fmt"
+
+	kmeta "k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	templateapi "github.com/openshift/origin/pkg/template/api"
+	"github.com/openshift/origin/pkg/util"
+)
+
+// MetadataConflictPolicy decides the value of a label or annotation that an object produced by a template already
+// sets to a different value than the generation requests.
+type MetadataConflictPolicy string
+
+const (
+	// MetadataConflictFail fails the generation. It is the default.
+	MetadataConflictFail MetadataConflictPolicy = "Fail"
+	// MetadataConflictKeepTemplate keeps the value set by the template.
+	MetadataConflictKeepTemplate MetadataConflictPolicy = "KeepTemplate"
+	// MetadataConflictOverride replaces the value set by the template.
+	MetadataConflictOverride MetadataConflictPolicy = "Override"
+)
+
+// TemplateLabelPolicy controls how the labels and annotations requested for a generation are applied to the objects
+// produced by instantiating a template. The zero value adds them to every object and fails on conflicts, like for
+// the other generated objects.
+type TemplateLabelPolicy struct {
+	// SkipLabels and SkipAnnotations leave the labels and annotations of the objects as the template sets them.
+	SkipLabels      bool
+	SkipAnnotations bool
+	// DropTemplateLabels instantiates the template without the labels it declares for all of its objects, which
+	// are usually set from its parameters, so that the objects are only identified by the requested labels.
+	DropTemplateLabels bool
+	// Conflicts decides the value of a label or annotation an object already sets to a different value. The
+	// labels of the pod template of a deployment config that its selector matches keep the value of the template
+	// unless Conflicts is MetadataConflictFail, since changing them would stop it from selecting its pods.
+	Conflicts MetadataConflictPolicy
+}
+
+// Validate returns an error if the conflict policy is not known.
+func (p TemplateLabelPolicy) Validate() error {
+	switch p.Conflicts {
+	case "", MetadataConflictFail, MetadataConflictKeepTemplate, MetadataConflictOverride:
+		return nil
+	}
+	return fmt.Errorf("unknown conflict policy %q, must be one of %s, %s or %s", p.Conflicts, MetadataConflictFail, MetadataConflictKeepTemplate, MetadataConflictOverride)
+}
+
+// PrepareTemplate returns the template to instantiate, without the labels it declares for its objects if the
+// policy drops them. The template itself is not modified.
+func (p TemplateLabelPolicy) PrepareTemplate(template *templateapi.Template) *templateapi.Template {
+	if !p.DropTemplateLabels || len(template.ObjectLabels) == 0 {
+		return template
+	}
+	copied := *template
+	copied.ObjectLabels = nil
+	return &copied
+}
+
+// Apply adds labels and annotations to objects produced by a template according to the policy.
+func (p TemplateLabelPolicy) Apply(objects []runtime.Object, labels, annotations map[string]string) error {
+	if p.SkipLabels {
+		labels = nil
+	}
+	if p.SkipAnnotations {
+		annotations = nil
+	}
+	if len(labels) == 0 && len(annotations) == 0 {
+		return nil
+	}
+	for _, obj := range objects {
+		accessor, err := kmeta.Accessor(obj)
+		if err != nil {
+			// objects of unknown kinds are decoded as unstructured and labeled like any other generated object
+			if err := util.AddObjectLabels(obj, labels); err != nil {
+				return err
+			}
+			if err := util.AddObjectAnnotations(obj, annotations); err != nil {
+				return err
+			}
+			continue
+		}
+		name := fmt.Sprintf("%s %q", obj.GetObjectKind().GroupVersionKind().Kind, accessor.GetName())
+
+		merged, err := p.merge(accessor.GetLabels(), labels, nil, "label", name)
+		if err != nil {
+			return err
+		}
+		accessor.SetLabels(merged)
+		if merged, err = p.merge(accessor.GetAnnotations(), annotations, nil, "annotation", name); err != nil {
+			return err
+		}
+		accessor.SetAnnotations(merged)
+
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil {
+			continue
+		}
+		if dc.Spec.Template.Labels, err = p.merge(dc.Spec.Template.Labels, labels, dc.Spec.Selector, "pod template label", name); err != nil {
+			return err
+		}
+		if dc.Spec.Template.Annotations, err = p.merge(dc.Spec.Template.Annotations, annotations, nil, "pod template annotation", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge adds values to existing, resolving conflicts with the policy. The keys of selector keep their value unless
+// conflicts fail.
+func (p TemplateLabelPolicy) merge(existing, values, selector map[string]string, kind, name string) (map[string]string, error) {
+	if len(values) == 0 {
+		return existing, nil
+	}
+	if existing == nil {
+		existing = make(map[string]string)
+	}
+	for k, v := range values {
+		current, ok := existing[k]
+		switch {
+		case !ok || current == v:
+			existing[k] = v
+		case p.Conflicts == "" || p.Conflicts == MetadataConflictFail:
+			return nil, fmt.Errorf("the %s %s of the template object %s is %q, not %q", kind, k, name, current, v)
+		case p.Conflicts == MetadataConflictOverride:
+			if _, selected := selector[k]; !selected {
+				existing[k] = v
+			}
+		}
+	}
+	return existing, nil
+}
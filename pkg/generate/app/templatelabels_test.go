@@ -0,0 +1,81 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	templateapi "github.com/openshift/origin/pkg/template/api"
+)
+
+func templateObjects() (*kapi.Service, *deployapi.DeploymentConfig) {
+	svc := &kapi.Service{ObjectMeta: kapi.ObjectMeta{Name: "frontend", Labels: map[string]string{"app": "template", "template": "ruby"}}}
+	dc := &deployapi.DeploymentConfig{
+		ObjectMeta: kapi.ObjectMeta{Name: "frontend", Annotations: map[string]string{"owner": "template"}},
+		Spec: deployapi.DeploymentConfigSpec{
+			Selector: map[string]string{"app": "template"},
+			Template: &kapi.PodTemplateSpec{ObjectMeta: kapi.ObjectMeta{Labels: map[string]string{"app": "template"}}},
+		},
+	}
+	return svc, dc
+}
+
+func TestTemplateLabelPolicyApply(t *testing.T) {
+	labels := map[string]string{"app": "user", "team": "a"}
+	annotations := map[string]string{"owner": "user"}
+
+	svc, dc := templateObjects()
+	err := TemplateLabelPolicy{}.Apply([]runtime.Object{svc, dc}, labels, annotations)
+	if err == nil || !strings.Contains(err.Error(), `label app of the template object`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	svc, dc = templateObjects()
+	if err := (TemplateLabelPolicy{Conflicts: MetadataConflictKeepTemplate}).Apply([]runtime.Object{svc, dc}, labels, annotations); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(svc.Labels, map[string]string{"app": "template", "template": "ruby", "team": "a"}) {
+		t.Errorf("unexpected labels: %v", svc.Labels)
+	}
+	if dc.Annotations["owner"] != "template" || dc.Spec.Template.Annotations["owner"] != "user" {
+		t.Errorf("unexpected annotations: %v %v", dc.Annotations, dc.Spec.Template.Annotations)
+	}
+
+	svc, dc = templateObjects()
+	if err := (TemplateLabelPolicy{Conflicts: MetadataConflictOverride}).Apply([]runtime.Object{svc, dc}, labels, annotations); err != nil {
+		t.Fatal(err)
+	}
+	if svc.Labels["app"] != "user" || dc.Labels["app"] != "user" || dc.Annotations["owner"] != "user" {
+		t.Errorf("unexpected metadata: %v %v %v", svc.Labels, dc.Labels, dc.Annotations)
+	}
+	// the deployment config must still select its pods
+	if !reflect.DeepEqual(dc.Spec.Template.Labels, map[string]string{"app": "template", "team": "a"}) {
+		t.Errorf("unexpected pod template labels: %v", dc.Spec.Template.Labels)
+	}
+
+	svc, dc = templateObjects()
+	if err := (TemplateLabelPolicy{SkipLabels: true}).Apply([]runtime.Object{svc, dc}, labels, map[string]string{"note": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(svc.Labels) != 2 || len(dc.Labels) != 0 || dc.Annotations["note"] != "x" || svc.Annotations["note"] != "x" {
+		t.Errorf("unexpected metadata: %v %v %v", svc.Labels, dc.Labels, dc.Annotations)
+	}
+}
+
+func TestTemplateLabelPolicyPrepareTemplate(t *testing.T) {
+	template := &templateapi.Template{ObjectLabels: map[string]string{"template": "${NAME}"}}
+	if prepared := (TemplateLabelPolicy{}).PrepareTemplate(template); prepared != template {
+		t.Errorf("expected the template to be kept")
+	}
+	prepared := TemplateLabelPolicy{DropTemplateLabels: true}.PrepareTemplate(template)
+	if prepared == template || len(prepared.ObjectLabels) != 0 || len(template.ObjectLabels) != 1 {
+		t.Errorf("unexpected template: %#v", prepared)
+	}
+	if err := (TemplateLabelPolicy{Conflicts: "Merge"}).Validate(); err == nil {
+		t.Errorf("expected an error")
+	}
+}
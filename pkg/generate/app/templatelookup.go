@@ -82,6 +82,12 @@ func (r TemplateSearcher) Search(precise bool, terms ...string) (ComponentMatche
 	return matches, errs
 }
 
+// Capabilities returns the capabilities of the server, which is asked for templates with the credentials of the
+// user.
+func (r TemplateSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{RequiresNetwork: true, Authenticated: true}
+}
+
 // IsPossibleTemplateFile returns true if the argument can be a template file
 func IsPossibleTemplateFile(value string) bool {
 	return isFile(value)
@@ -149,3 +155,8 @@ func (r *TemplateFileSearcher) Search(precise bool, terms ...string) (ComponentM
 
 	return matches, errs
 }
+
+// Capabilities returns no capabilities, templates are read from files.
+func (r *TemplateFileSearcher) Capabilities() SearcherCapabilities {
+	return SearcherCapabilities{}
+}
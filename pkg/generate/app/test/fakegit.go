@@ -2,6 +2,7 @@ package test
 
 import (
 	"io"
+	"time"
 
 	"github.com/openshift/origin/pkg/generate/git"
 )
@@ -87,3 +88,7 @@ func (f *FakeGit) ListRemote(url string, args ...string) (string, string, error)
 func (f *FakeGit) GetInfo(location string) (*git.SourceInfo, []error) {
 	return nil, nil
 }
+
+func (f *FakeGit) LastCommitTime(dir, path string) (time.Time, error) {
+	return time.Time{}, nil
+}
@@ -0,0 +1,171 @@
+// Package testsearcher provides in memory searchers for hermetic tests of code that generates applications.
+package testsearcher
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/errors"
+	ktestclient "k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"github.com/openshift/origin/pkg/client/testclient"
+	"github.com/openshift/origin/pkg/generate/app"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	templateapi "github.com/openshift/origin/pkg/template/api"
+)
+
+// ExactMatchDockerSearcher returns a match with the value that was passed in
+// and a match score of 0.0 (exact)
+type ExactMatchDockerSearcher struct {
+	Errs []error
+}
+
+// Search always returns a match for every term passed in
+func (r *ExactMatchDockerSearcher) Search(precise bool, terms ...string) (app.ComponentMatches, []error) {
+	matches := app.ComponentMatches{}
+	for _, value := range terms {
+		matches = append(matches, &app.ComponentMatch{
+			Value:       value,
+			Name:        value,
+			Argument:    fmt.Sprintf("--docker-image=%q", value),
+			Description: fmt.Sprintf("Docker image %q", value),
+			Score:       0.0,
+		})
+	}
+	return matches, r.Errs
+}
+
+// Capabilities returns the capabilities of a registry searched with credentials, except that the matches carry
+// no metadata.
+func (r *ExactMatchDockerSearcher) Capabilities() app.SearcherCapabilities {
+	return app.SearcherCapabilities{SupportsTags: true, RequiresNetwork: true, Authenticated: true}
+}
+
+// Search records a single call to a Searcher.
+type Search struct {
+	Precise bool
+	Terms   []string
+}
+
+// Searcher is a scriptable app.Searcher. It returns copies of the matches listed for each term, so the same
+// matches may be returned by several searches. It is safe for concurrent use.
+type Searcher struct {
+	// Matches are the matches returned for each term. Matches listed under "*" are returned for every term.
+	Matches map[string]app.ComponentMatches
+	// Errors are the errors returned for each term.
+	Errors map[string][]error
+	// Score, if set, sets the score of each match returned for term.
+	Score func(term string, match *app.ComponentMatch) float32
+	// Delay is waited before each search returns, to simulate a slow backend.
+	Delay time.Duration
+	// Declared are the capabilities returned by Capabilities.
+	Declared app.SearcherCapabilities
+
+	lock     sync.Mutex
+	searches []Search
+}
+
+// Search returns the matches and errors scripted for terms.
+func (s *Searcher) Search(precise bool, terms ...string) (app.ComponentMatches, []error) {
+	s.lock.Lock()
+	s.searches = append(s.searches, Search{Precise: precise, Terms: terms})
+	s.lock.Unlock()
+
+	if s.Delay > 0 {
+		time.Sleep(s.Delay)
+	}
+	matches := app.ComponentMatches{}
+	var errs []error
+	for _, term := range terms {
+		for _, key := range []string{term, "*"} {
+			for _, m := range s.Matches[key] {
+				match := *m
+				match.Value = term
+				if s.Score != nil {
+					match.Score = s.Score(term, &match)
+				}
+				matches = append(matches, &match)
+			}
+		}
+		errs = append(errs, s.Errors[term]...)
+	}
+	return matches, errs
+}
+
+// Capabilities returns the declared capabilities.
+func (s *Searcher) Capabilities() app.SearcherCapabilities {
+	return s.Declared
+}
+
+// Searches returns the searches made so far.
+func (s *Searcher) Searches() []Search {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]Search(nil), s.searches...)
+}
+
+// ImageStreamSearcher returns a searcher for streams in namespaces. images are the images the tags of streams
+// point to, and are looked up by name.
+func ImageStreamSearcher(namespaces []string, streams []imageapi.ImageStream, images ...imageapi.Image) app.ImageStreamSearcher {
+	client := &testclient.Fake{}
+	client.AddReactor("list", "imagestreams", func(action ktestclient.Action) (bool, runtime.Object, error) {
+		list := &imageapi.ImageStreamList{}
+		for _, stream := range streams {
+			if stream.Namespace == action.GetNamespace() {
+				list.Items = append(list.Items, stream)
+			}
+		}
+		return true, list, nil
+	})
+	client.AddReactor("get", "imagestreams", func(action ktestclient.Action) (bool, runtime.Object, error) {
+		name := action.(ktestclient.GetAction).GetName()
+		for i := range streams {
+			if streams[i].Namespace == action.GetNamespace() && streams[i].Name == name {
+				stream := streams[i]
+				return true, &stream, nil
+			}
+		}
+		return true, nil, errors.NewNotFound(imageapi.Resource("imagestreams"), name)
+	})
+	client.AddReactor("get", "imagestreamimages", func(action ktestclient.Action) (bool, runtime.Object, error) {
+		name := action.(ktestclient.GetAction).GetName()
+		if i := strings.Index(name, "@"); i != -1 {
+			for _, image := range images {
+				if image.Name == name[i+1:] {
+					return true, &imageapi.ImageStreamImage{
+						ObjectMeta: kapi.ObjectMeta{Namespace: action.GetNamespace(), Name: name},
+						Image:      image,
+					}, nil
+				}
+			}
+		}
+		return true, nil, errors.NewNotFound(imageapi.Resource("imagestreamimages"), name)
+	})
+	return app.ImageStreamSearcher{
+		Client:            client,
+		ImageStreamImages: client,
+		Namespaces:        namespaces,
+	}
+}
+
+// TemplateSearcher returns a searcher for templates in namespaces.
+func TemplateSearcher(namespaces []string, templates ...templateapi.Template) app.TemplateSearcher {
+	client := &testclient.Fake{}
+	client.AddReactor("list", "templates", func(action ktestclient.Action) (bool, runtime.Object, error) {
+		list := &templateapi.TemplateList{}
+		for _, template := range templates {
+			if template.Namespace == action.GetNamespace() {
+				list.Items = append(list.Items, template)
+			}
+		}
+		return true, list, nil
+	})
+	return app.TemplateSearcher{
+		Client:     client,
+		Namespaces: namespaces,
+	}
+}
@@ -0,0 +1,79 @@
+package testsearcher
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	"github.com/openshift/origin/pkg/generate/app"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	templateapi "github.com/openshift/origin/pkg/template/api"
+)
+
+func TestSearcher(t *testing.T) {
+	searcher := &Searcher{
+		Matches: map[string]app.ComponentMatches{
+			"ruby": {{Name: "ruby", Score: 0.5}},
+			"*":    {{Name: "any", Score: 0.5}},
+		},
+		Errors: map[string][]error{"broken": {fmt.Errorf("unavailable")}},
+		Score: func(term string, match *app.ComponentMatch) float32 {
+			if match.Name == term {
+				return 0
+			}
+			return match.Score
+		},
+	}
+	matches, errs := searcher.Search(true, "ruby", "broken")
+	if len(errs) != 1 || len(matches) != 3 {
+		t.Fatalf("unexpected matches %v and errors %v", matches, errs)
+	}
+	if matches[0].Value != "ruby" || matches[0].Score != 0 || matches[1].Score != 0.5 || matches[2].Value != "broken" {
+		t.Errorf("unexpected matches: %#v", matches)
+	}
+	if searcher.Matches["ruby"][0].Value != "" {
+		t.Errorf("the scripted matches were modified")
+	}
+	if searches := searcher.Searches(); !reflect.DeepEqual(searches, []Search{{Precise: true, Terms: []string{"ruby", "broken"}}}) {
+		t.Errorf("unexpected searches: %#v", searches)
+	}
+}
+
+func TestImageStreamSearcher(t *testing.T) {
+	stream := imageapi.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{Name: "ruby", Namespace: "openshift"},
+		Status: imageapi.ImageStreamStatus{
+			Tags: map[string]imageapi.TagEventList{
+				"latest": {Items: []imageapi.TagEvent{{Image: "sha256:ruby"}}},
+			},
+		},
+	}
+	image := imageapi.Image{
+		ObjectMeta:           kapi.ObjectMeta{Name: "sha256:ruby"},
+		DockerImageReference: "openshift/ruby:latest",
+	}
+	searcher := ImageStreamSearcher([]string{"openshift"}, []imageapi.ImageStream{stream}, image)
+	matches, errs := searcher.Search(true, "ruby")
+	if len(errs) != 0 || len(matches) != 1 {
+		t.Fatalf("unexpected matches %v and errors %v", matches, errs)
+	}
+	if matches[0].ImageStream == nil || matches[0].ImageStream.Name != "ruby" || matches[0].Score != 0 {
+		t.Errorf("unexpected match: %#v", matches[0])
+	}
+	if matches, _ := searcher.Search(true, "perl"); len(matches) != 0 {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+}
+
+func TestTemplateSearcher(t *testing.T) {
+	searcher := TemplateSearcher([]string{"openshift"},
+		templateapi.Template{ObjectMeta: kapi.ObjectMeta{Name: "rails", Namespace: "openshift"}},
+		templateapi.Template{ObjectMeta: kapi.ObjectMeta{Name: "rails", Namespace: "other"}},
+	)
+	matches, errs := searcher.Search(true, "rails")
+	if len(errs) != 0 || len(matches) != 1 || matches[0].Template == nil || matches[0].Template.Namespace != "openshift" {
+		t.Errorf("unexpected matches %v and errors %v", matches, errs)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/serviceaccount"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	"github.com/openshift/origin/pkg/security/admission"
+)
+
+// ImageUserWarning describes why the user an image runs as is likely to prevent it from running under the
+// restricted security context constraint, which assigns a random non-root UID to every pod.
+type ImageUserWarning struct {
+	// Image is the name of the image that was analyzed.
+	Image string
+	// User is the user set in the image config, or empty if none is set (in which case the image runs as root).
+	User string
+	// Reason describes the problem.
+	Reason string
+	// Suggestions lists the ways the problem can be resolved.
+	Suggestions []string
+}
+
+// String returns a description of the warning.
+func (w *ImageUserWarning) String() string {
+	return fmt.Sprintf("image %q %s", w.Image, w.Reason)
+}
+
+// AnalyzeImageUser returns a warning if the user of image (which may be given as user, uid, user:group or
+// uid:gid) is root or cannot be verified to be non-root, or nil if the image is expected to run unmodified.
+func AnalyzeImageUser(name string, image *imageapi.DockerImage) *ImageUserWarning {
+	if image == nil || image.Config == nil {
+		return nil
+	}
+	user := image.Config.User
+	uid := user
+	if i := strings.Index(user, ":"); i != -1 {
+		uid = user[:i]
+	}
+	warning := &ImageUserWarning{Image: name, User: user}
+	switch {
+	case len(uid) == 0, uid == "root":
+		warning.Reason = "runs as the 'root' user"
+	default:
+		id, err := strconv.Atoi(uid)
+		if err != nil {
+			warning.Reason = fmt.Sprintf("runs as the non-numeric user %q, which cannot be verified to be non-root", uid)
+			warning.Suggestions = append(warning.Suggestions, fmt.Sprintf("change the image to set USER to the numeric UID of %q", uid))
+			break
+		}
+		if id != 0 {
+			return nil
+		}
+		warning.Reason = "runs as the 'root' user (UID 0)"
+	}
+	warning.Suggestions = append(warning.Suggestions,
+		"change the image to run as a non-root user and to not depend on a specific UID",
+		"ask your administrator to grant the 'anyuid' security context constraint to the service account of this application",
+	)
+	return warning
+}
+
+// RootAllowed returns true if one of sccs applies to the service account serviceAccount in namespace and lets
+// containers run as root, in which case the warnings of AnalyzeImageUser do not apply to the pods of that
+// service account.
+func RootAllowed(sccs []kapi.SecurityContextConstraints, namespace, serviceAccount string) bool {
+	userInfo := serviceaccount.UserInfo(namespace, serviceAccount, "")
+	for i := range sccs {
+		scc := &sccs[i]
+		if !admission.ConstraintAppliesTo(scc, userInfo) {
+			continue
+		}
+		switch opts := scc.RunAsUser; opts.Type {
+		case kapi.RunAsUserStrategyRunAsAny:
+			return true
+		case kapi.RunAsUserStrategyMustRunAs:
+			if opts.UID != nil && *opts.UID == 0 {
+				return true
+			}
+		case kapi.RunAsUserStrategyMustRunAsRange:
+			if opts.UIDRangeMin != nil && *opts.UIDRangeMin == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func TestAnalyzeImageUser(t *testing.T) {
+	tests := map[string]struct {
+		image   *imageapi.DockerImage
+		warning bool
+	}{
+		"no config":     {image: &imageapi.DockerImage{}},
+		"no user":       {image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{}}, warning: true},
+		"root":          {image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "root"}}, warning: true},
+		"root group":    {image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "root:wheel"}}, warning: true},
+		"uid 0":         {image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "0:0"}}, warning: true},
+		"named user":    {image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "postgres"}}, warning: true},
+		"numeric user":  {image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "1001"}}},
+		"numeric group": {image: &imageapi.DockerImage{Config: &imageapi.DockerConfig{User: "1001:0"}}},
+	}
+	for name, test := range tests {
+		warning := AnalyzeImageUser("test", test.image)
+		if (warning != nil) != test.warning {
+			t.Errorf("%s: unexpected warning: %#v", name, warning)
+			continue
+		}
+		if warning != nil && len(warning.Suggestions) == 0 {
+			t.Errorf("%s: expected suggestions", name)
+		}
+	}
+}
+
+func TestRootAllowed(t *testing.T) {
+	zero, uid := int64(0), int64(1000)
+	restricted := kapi.SecurityContextConstraints{
+		ObjectMeta: kapi.ObjectMeta{Name: "restricted"},
+		RunAsUser:  kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyMustRunAsRange},
+		Groups:     []string{"system:authenticated"},
+	}
+	anyuid := kapi.SecurityContextConstraints{
+		ObjectMeta: kapi.ObjectMeta{Name: "anyuid"},
+		RunAsUser:  kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyRunAsAny},
+	}
+	tests := map[string]struct {
+		sccs    []kapi.SecurityContextConstraints
+		allowed bool
+	}{
+		"none":       {},
+		"restricted": {sccs: []kapi.SecurityContextConstraints{restricted}},
+		"anyuid not granted": {
+			sccs: []kapi.SecurityContextConstraints{restricted, anyuid},
+		},
+		"anyuid granted to the service account": {
+			sccs:    []kapi.SecurityContextConstraints{restricted, withSubjects(anyuid, []string{"system:serviceaccount:test:default"}, nil)},
+			allowed: true,
+		},
+		"anyuid granted to another service account": {
+			sccs: []kapi.SecurityContextConstraints{withSubjects(anyuid, []string{"system:serviceaccount:test:builder"}, nil)},
+		},
+		"anyuid granted to the service accounts of the namespace": {
+			sccs:    []kapi.SecurityContextConstraints{withSubjects(anyuid, nil, []string{"system:serviceaccounts:test"})},
+			allowed: true,
+		},
+		"uid 0": {
+			sccs: []kapi.SecurityContextConstraints{{
+				RunAsUser: kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyMustRunAs, UID: &zero},
+				Groups:    []string{"system:serviceaccounts"},
+			}},
+			allowed: true,
+		},
+		"non-root uid": {
+			sccs: []kapi.SecurityContextConstraints{{
+				RunAsUser: kapi.RunAsUserStrategyOptions{Type: kapi.RunAsUserStrategyMustRunAs, UID: &uid},
+				Groups:    []string{"system:serviceaccounts"},
+			}},
+		},
+	}
+	for name, test := range tests {
+		if allowed := RootAllowed(test.sccs, "test", "default"); allowed != test.allowed {
+			t.Errorf("%s: expected %t, got %t", name, test.allowed, allowed)
+		}
+	}
+}
+
+func withSubjects(scc kapi.SecurityContextConstraints, users, groups []string) kapi.SecurityContextConstraints {
+	scc.Users, scc.Groups = users, groups
+	return scc
+}
@@ -0,0 +1,77 @@
+package app
+
+import (
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// Volume describes a volume that is mounted into every container of the generated deployment configs.
+type Volume struct {
+	// Name is the name of the volume.
+	Name string `json:"name"`
+	// MountPath is where the volume is mounted in each container.
+	MountPath string `json:"mountPath"`
+	// Persistent backs the volume with a generated persistent volume claim instead of an empty directory.
+	Persistent bool `json:"persistent,omitempty"`
+	// Size is the size requested by a persistent volume claim. Defaults to DefaultPresetVolumeSize.
+	Size string `json:"size,omitempty"`
+}
+
+// AddVolumes mounts volumes into the containers of the deployment configs in objects and returns the objects
+// along with any persistent volume claims the volumes require.
+func AddVolumes(objects Objects, volumes []Volume) (Objects, error) {
+	names := sets.NewString()
+	sizes := make([]resource.Quantity, len(volumes))
+	for i, v := range volumes {
+		if len(v.Name) == 0 || len(v.MountPath) == 0 {
+			return nil, fmt.Errorf("every volume must have a name and a mount path")
+		}
+		if names.Has(v.Name) {
+			return nil, fmt.Errorf("the volume %q is specified more than once", v.Name)
+		}
+		names.Insert(v.Name)
+		size := v.Size
+		if len(size) == 0 {
+			size = DefaultPresetVolumeSize
+		}
+		q, err := resource.ParseQuantity(size)
+		if err != nil {
+			return nil, fmt.Errorf("the volume %q has an invalid size: %v", v.Name, err)
+		}
+		sizes[i] = *q
+	}
+	if len(volumes) == 0 {
+		return objects, nil
+	}
+
+	claims := []runtime.Object{}
+	for _, obj := range objects {
+		dc, ok := obj.(*deployapi.DeploymentConfig)
+		if !ok || dc.Spec.Template == nil {
+			continue
+		}
+		spec := &dc.Spec.Template.Spec
+		for i, v := range volumes {
+			source := kapi.VolumeSource{EmptyDir: &kapi.EmptyDirVolumeSource{}}
+			if v.Persistent {
+				claim := persistentVolumeClaim(fmt.Sprintf("%s-%s", dc.Name, v.Name), dc.Labels, sizes[i])
+				source = kapi.VolumeSource{
+					PersistentVolumeClaim: &kapi.PersistentVolumeClaimVolumeSource{ClaimName: claim.Name},
+				}
+				claims = append(claims, claim)
+			}
+			spec.Volumes = append(spec.Volumes, kapi.Volume{Name: v.Name, VolumeSource: source})
+			for j := range spec.Containers {
+				container := &spec.Containers[j]
+				container.VolumeMounts = append(container.VolumeMounts, kapi.VolumeMount{Name: v.Name, MountPath: v.MountPath})
+			}
+		}
+	}
+	return append(objects, claims...), nil
+}
@@ -0,0 +1,48 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func TestAddVolumes(t *testing.T) {
+	objects, err := AddVolumes(presetTestObjects(), []Volume{
+		{Name: "cache", MountPath: "/cache"},
+		{Name: "logs", MountPath: "/logs", Persistent: true, Size: "5Gi"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected a claim to be added, got %#v", objects)
+	}
+	dc := objects[0].(*deployapi.DeploymentConfig)
+	spec := dc.Spec.Template.Spec
+	if len(spec.Volumes) != 3 || spec.Volumes[1].EmptyDir == nil || spec.Volumes[2].PersistentVolumeClaim == nil {
+		t.Errorf("unexpected volumes: %#v", spec.Volumes)
+	}
+	if mounts := spec.Containers[0].VolumeMounts; len(mounts) != 3 || mounts[2].MountPath != "/logs" {
+		t.Errorf("unexpected mounts: %#v", mounts)
+	}
+	claim := objects[2].(*kapi.PersistentVolumeClaim)
+	size := claim.Spec.Resources.Requests[kapi.ResourceStorage]
+	if claim.Name != "db-logs" || size.String() != "5Gi" {
+		t.Errorf("unexpected claim: %#v", claim)
+	}
+}
+
+func TestAddVolumesInvalid(t *testing.T) {
+	tests := map[string][]Volume{
+		"missing mount path": {{Name: "cache"}},
+		"duplicate":          {{Name: "cache", MountPath: "/a"}, {Name: "cache", MountPath: "/b"}},
+		"invalid size":       {{Name: "cache", MountPath: "/a", Persistent: true, Size: "lots"}},
+	}
+	for name, volumes := range tests {
+		if _, err := AddVolumes(presetTestObjects(), volumes); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
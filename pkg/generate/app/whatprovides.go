@@ -0,0 +1,74 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/util/errors"
+)
+
+// BuilderProvider is an image stream tag that declares support for a language or framework term through
+// its 'supports' annotation.
+type BuilderProvider struct {
+	// Term is the term that was searched for, for instance ruby or nodejs:6.
+	Term      string
+	Namespace string
+	Name      string
+	Tag       string
+	// Supports lists the terms declared by the 'supports' annotation of the tag.
+	Supports []string
+	// Score is 0.0 when the term and its version match exactly and 0.5 when only the term matches.
+	Score float32
+	// Builder is true if the image or tag is marked as a builder.
+	Builder bool
+
+	Match *ComponentMatch
+}
+
+// WhatProvides returns the image stream tags found by searcher (usually an ImageStreamByAnnotationSearcher)
+// that support terms, best matches first.
+func WhatProvides(searcher Searcher, terms ...string) ([]BuilderProvider, error) {
+	matches, errs := searcher.Search(false, terms...)
+	providers := []BuilderProvider{}
+	for _, match := range matches {
+		if match.ImageStream == nil {
+			continue
+		}
+		provider := BuilderProvider{
+			Term:      match.Value,
+			Namespace: match.ImageStream.Namespace,
+			Name:      match.ImageStream.Name,
+			Tag:       match.ImageTag,
+			Score:     match.Score,
+			Builder:   IsBuilderMatch(match),
+			Match:     match,
+		}
+		if tag, ok := match.ImageStream.Spec.Tags[match.ImageTag]; ok {
+			for _, s := range strings.Split(tag.Annotations[supportsAnnotationKey], ",") {
+				if s = strings.TrimSpace(s); len(s) > 0 {
+					provider.Supports = append(provider.Supports, s)
+				}
+			}
+		}
+		providers = append(providers, provider)
+	}
+	sort.Sort(buildersByScore(providers))
+	return providers, errors.NewAggregate(errs)
+}
+
+type buildersByScore []BuilderProvider
+
+func (b buildersByScore) Len() int      { return len(b) }
+func (b buildersByScore) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b buildersByScore) Less(i, j int) bool {
+	if b[i].Score != b[j].Score {
+		return b[i].Score < b[j].Score
+	}
+	if b[i].Namespace != b[j].Namespace {
+		return b[i].Namespace < b[j].Namespace
+	}
+	if b[i].Name != b[j].Name {
+		return b[i].Name < b[j].Name
+	}
+	return b[i].Tag < b[j].Tag
+}
@@ -0,0 +1,45 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhatProvides(t *testing.T) {
+	streams, images := fakeImageStreams(
+		&fakeImageStreamDesc{
+			name: "ruby",
+			supports: map[string]string{
+				"ruby20": "ruby:2.0,ruby:2.1,ruby",
+				"ruby19": "ruby:1.9, ruby",
+			},
+		},
+		&fakeImageStreamDesc{
+			name: "wildfly",
+			supports: map[string]string{
+				"v8": "wildfly:8.0,java,jee",
+			},
+		},
+	)
+	client := testImageStreamClient(streams, images)
+	searcher := NewImageStreamByAnnotationSearcher(client, client, []string{"default"})
+
+	providers, err := WhatProvides(searcher, "ruby:2.1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(providers) != 2 {
+		t.Fatalf("unexpected providers: %#v", providers)
+	}
+	if providers[0].Tag != "ruby20" || providers[0].Score != 0.0 {
+		t.Errorf("expected the exact match first: %#v", providers[0])
+	}
+	if providers[1].Tag != "ruby19" || !reflect.DeepEqual(providers[1].Supports, []string{"ruby:1.9", "ruby"}) {
+		t.Errorf("unexpected partial match: %#v", providers[1])
+	}
+
+	providers, err = WhatProvides(searcher, "python")
+	if err != nil || len(providers) != 0 {
+		t.Errorf("unexpected result: %#v %v", providers, err)
+	}
+}
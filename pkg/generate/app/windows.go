@@ -0,0 +1,111 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+const (
+	// ImageOSWindows is the operating system reported by images built for Windows.
+	ImageOSWindows = "windows"
+	// ImageOSLinux is the operating system reported by images built for linux.
+	ImageOSLinux = "linux"
+
+	// NodeOSLabel is the node label that identifies the operating system of a node.
+	NodeOSLabel = "beta.kubernetes.io/os"
+)
+
+// ImageOS returns the operating system the image was built for, or an empty string if the image does not
+// report one.
+func ImageOS(image *ImageRef) string {
+	if image == nil || image.Info == nil {
+		return ""
+	}
+	return strings.ToLower(image.Info.OS)
+}
+
+// MixedOSWarning describes a deployment config whose containers run images built for different operating
+// systems, which cannot be scheduled together in a single pod.
+type MixedOSWarning struct {
+	// Deployment is the name of the deployment config.
+	Deployment string
+	// Images maps the name of each image to its operating system.
+	Images map[string]string
+}
+
+func (w MixedOSWarning) String() string {
+	names := make([]string, 0, len(w.Images))
+	for name := range w.Images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	images := []string{}
+	for _, name := range names {
+		images = append(images, fmt.Sprintf("%s (%s)", name, w.Images[name]))
+	}
+	return fmt.Sprintf("deployment config %q mixes images built for different operating systems: %s", w.Deployment, strings.Join(images, ", "))
+}
+
+// CheckDeploymentOS returns a warning if the images of the deployment config are built for different operating
+// systems, or nil. Images that do not report an operating system are ignored.
+func CheckDeploymentOS(r *DeploymentConfigRef) *MixedOSWarning {
+	if r == nil {
+		return nil
+	}
+	images := map[string]string{}
+	oses := map[string]struct{}{}
+	for _, image := range r.Images {
+		os := ImageOS(image)
+		if len(os) == 0 {
+			continue
+		}
+		images[image.Reference.Name] = os
+		oses[os] = struct{}{}
+	}
+	if len(oses) < 2 {
+		return nil
+	}
+	return &MixedOSWarning{Deployment: r.Name, Images: images}
+}
+
+// applyWindowsConstraints restricts a pod running only Windows images to Windows nodes, removes probes that rely
+// on /bin/sh, and converts volume mount paths to Windows conventions.
+func applyWindowsConstraints(spec *kapi.PodSpec) {
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = map[string]string{}
+	}
+	spec.NodeSelector[NodeOSLabel] = ImageOSWindows
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		if usesShell(container.ReadinessProbe) {
+			container.ReadinessProbe = nil
+		}
+		if usesShell(container.LivenessProbe) {
+			container.LivenessProbe = nil
+		}
+		for j := range container.VolumeMounts {
+			container.VolumeMounts[j].MountPath = windowsPath(container.VolumeMounts[j].MountPath)
+		}
+	}
+}
+
+// usesShell returns true if the probe executes a command under /bin, such as /bin/sh.
+func usesShell(probe *kapi.Probe) bool {
+	if probe == nil || probe.Exec == nil || len(probe.Exec.Command) == 0 {
+		return false
+	}
+	return strings.HasPrefix(probe.Exec.Command[0], "/bin/")
+}
+
+// windowsPath converts a path to use backslashes and a drive letter, which Windows containers require for
+// volume mounts.
+func windowsPath(path string) string {
+	path = strings.Replace(path, "/", `\`, -1)
+	if strings.HasPrefix(path, `\`) {
+		path = "C:" + path
+	}
+	return path
+}
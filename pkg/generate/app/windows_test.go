@@ -0,0 +1,70 @@
+package app
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+func osImageRef(name, os string, volumes ...string) *ImageRef {
+	config := &imageapi.DockerConfig{Volumes: map[string]struct{}{}}
+	for _, v := range volumes {
+		config.Volumes[v] = struct{}{}
+	}
+	return &ImageRef{
+		Reference: imageapi.DockerImageReference{Name: name},
+		Info:      &imageapi.DockerImage{OS: os, Config: config},
+	}
+}
+
+func TestDeploymentConfigWindows(t *testing.T) {
+	ref := &DeploymentConfigRef{Name: "iis", Images: []*ImageRef{osImageRef("iis", "windows", "/inetpub/logs")}}
+	dc, err := ref.DeploymentConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec := dc.Spec.Template.Spec
+	if spec.NodeSelector[NodeOSLabel] != ImageOSWindows {
+		t.Errorf("unexpected node selector: %#v", spec.NodeSelector)
+	}
+	if path := spec.Containers[0].VolumeMounts[0].MountPath; path != `C:\inetpub\logs` {
+		t.Errorf("unexpected mount path: %s", path)
+	}
+	if CheckDeploymentOS(ref) != nil {
+		t.Errorf("unexpected warning")
+	}
+	ref.Images = append(ref.Images, osImageRef("sidecar", ""))
+	if CheckDeploymentOS(ref) != nil {
+		t.Errorf("unexpected warning for an image of unknown OS")
+	}
+}
+
+func TestDeploymentConfigMixedOS(t *testing.T) {
+	ref := &DeploymentConfigRef{Name: "app", Images: []*ImageRef{osImageRef("iis", "windows"), osImageRef("nginx", "linux"), osImageRef("busybox", "")}}
+	dc, err := ref.DeploymentConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dc.Spec.Template.Spec.NodeSelector) != 0 {
+		t.Errorf("unexpected node selector: %#v", dc.Spec.Template.Spec.NodeSelector)
+	}
+	warning := CheckDeploymentOS(ref)
+	if warning == nil || len(warning.Images) != 2 || warning.Images["iis"] != ImageOSWindows || warning.Images["nginx"] != ImageOSLinux {
+		t.Fatalf("unexpected warning: %#v", warning)
+	}
+	if s := warning.String(); s != `deployment config "app" mixes images built for different operating systems: iis (windows), nginx (linux)` {
+		t.Errorf("unexpected message: %s", s)
+	}
+}
+
+func TestApplyWindowsConstraintsProbes(t *testing.T) {
+	shell := &kapi.Probe{Handler: kapi.Handler{Exec: &kapi.ExecAction{Command: []string{"/bin/sh", "-c", "true"}}}}
+	exec := &kapi.Probe{Handler: kapi.Handler{Exec: &kapi.ExecAction{Command: []string{"powershell", "exit 0"}}}}
+	spec := &kapi.PodSpec{Containers: []kapi.Container{{ReadinessProbe: shell, LivenessProbe: exec}}}
+	applyWindowsConstraints(spec)
+	if spec.Containers[0].ReadinessProbe != nil || spec.Containers[0].LivenessProbe != exec {
+		t.Errorf("unexpected probes: %#v", spec.Containers[0])
+	}
+}
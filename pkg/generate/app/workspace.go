@@ -0,0 +1,151 @@
+package app
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/glog"
+)
+
+var (
+	// ErrWorkspaceClosed is returned when a directory is requested from a workspace that was closed.
+	ErrWorkspaceClosed = errors.New("the generation workspace was closed")
+	// ErrWorkspaceQuotaExceeded is returned when a directory is requested from a workspace whose directories
+	// hold as many bytes as its quota allows.
+	ErrWorkspaceQuotaExceeded = errors.New("the generation workspace is out of space")
+)
+
+// Workspace holds the temporary directories that generation creates, such as the clones of remote source
+// repositories, under a base directory and within a quota, and removes them when it is closed. Processes that
+// embed the generator close the workspace when they are done with a generation or when it is cancelled, so that
+// the directories are not left behind. A nil workspace creates untracked directories in the default directory
+// for temporary files.
+type Workspace struct {
+	// Base is the directory the directories are created in. Defaults to the directory for temporary files.
+	Base string
+	// Quota is the largest number of bytes the directories may hold together. Zero does not limit them.
+	Quota int64
+
+	lock   sync.Mutex
+	dirs   map[string]struct{}
+	closed bool
+}
+
+// NewWorkspace creates a workspace that creates directories in base within quota.
+func NewWorkspace(base string, quota int64) *Workspace {
+	return &Workspace{Base: base, Quota: quota, dirs: make(map[string]struct{})}
+}
+
+// TempDir creates a new directory whose name starts with prefix and tracks it until it is removed or the
+// workspace is closed.
+func (w *Workspace) TempDir(prefix string) (string, error) {
+	if w == nil {
+		return ioutil.TempDir("", prefix)
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.closed {
+		return "", ErrWorkspaceClosed
+	}
+	if w.Quota > 0 && w.usage() >= w.Quota {
+		return "", ErrWorkspaceQuotaExceeded
+	}
+	if len(w.Base) > 0 {
+		if err := os.MkdirAll(w.Base, 0700); err != nil {
+			return "", err
+		}
+	}
+	dir, err := ioutil.TempDir(w.Base, prefix)
+	if err != nil {
+		return "", err
+	}
+	if w.dirs == nil {
+		w.dirs = make(map[string]struct{})
+	}
+	w.dirs[dir] = struct{}{}
+	return dir, nil
+}
+
+// Remove removes dir, a directory of the workspace, and stops tracking it.
+func (w *Workspace) Remove(dir string) error {
+	if w != nil {
+		w.lock.Lock()
+		delete(w.dirs, dir)
+		w.lock.Unlock()
+	}
+	return os.RemoveAll(dir)
+}
+
+// Remaining returns the number of bytes the directories of the workspace may still hold, or zero if the
+// workspace has no quota.
+func (w *Workspace) Remaining() int64 {
+	if w == nil || w.Quota <= 0 {
+		return 0
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	remaining := w.Quota - w.usage()
+	if remaining < 1 {
+		// zero means unlimited to the callers, the next directory is refused instead
+		return 1
+	}
+	return remaining
+}
+
+// Usage returns the number of bytes the directories of the workspace hold.
+func (w *Workspace) Usage() int64 {
+	if w == nil {
+		return 0
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.usage()
+}
+
+func (w *Workspace) usage() int64 {
+	var size int64
+	for dir := range w.dirs {
+		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && info.Mode().IsRegular() {
+				size += info.Size()
+			}
+			return nil
+		})
+	}
+	return size
+}
+
+// Close removes the directories of the workspace. Directories can no longer be created once it is closed.
+func (w *Workspace) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.closed = true
+	var firstErr error
+	for dir := range w.dirs {
+		if err := os.RemoveAll(dir); err != nil {
+			glog.V(4).Infof("Unable to remove the workspace directory %s: %v", dir, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		delete(w.dirs, dir)
+	}
+	return firstErr
+}
+
+// CloseWhen closes the workspace when done is closed, such as when the generation using it is cancelled.
+func (w *Workspace) CloseWhen(done <-chan struct{}) {
+	if w == nil {
+		return
+	}
+	go func() {
+		<-done
+		w.Close()
+	}()
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	generrors "github.com/openshift/origin/pkg/generate/errors"
+)
+
+func TestWorkspace(t *testing.T) {
+	base, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+
+	w := NewWorkspace(filepath.Join(base, "gen"), 0)
+	a, err := w.TempDir("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := w.TempDir("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(a) != w.Base || !strings.HasPrefix(filepath.Base(a), "a") {
+		t.Errorf("unexpected directory: %s", a)
+	}
+	if err := ioutil.WriteFile(filepath.Join(b, "data"), make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if usage := w.Usage(); usage != 100 {
+		t.Errorf("unexpected usage: %d", usage)
+	}
+	if remaining := w.Remaining(); remaining != 0 {
+		t.Errorf("unexpected remaining space without a quota: %d", remaining)
+	}
+
+	if err := w.Remove(b); err != nil {
+		t.Fatal(err)
+	}
+	if usage := w.Usage(); usage != 0 {
+		t.Errorf("unexpected usage: %d", usage)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(a); !os.IsNotExist(err) {
+		t.Errorf("directory was not removed: %v", err)
+	}
+	if _, err := w.TempDir("c"); err != ErrWorkspaceClosed {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWorkspaceQuota(t *testing.T) {
+	base, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+
+	w := NewWorkspace(base, 1000)
+	dir, err := w.TempDir("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "data"), make([]byte, 400), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := w.Remaining(); remaining != 600 {
+		t.Errorf("unexpected remaining space: %d", remaining)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "more"), make([]byte, 600), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if remaining := w.Remaining(); remaining != 1 {
+		t.Errorf("unexpected remaining space: %d", remaining)
+	}
+	if _, err := w.TempDir("b"); err != ErrWorkspaceQuotaExceeded {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestWorkspaceCloseWhen(t *testing.T) {
+	w := NewWorkspace("", 0)
+	dir, err := w.TempDir("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	done := make(chan struct{})
+	w.CloseWhen(done)
+	close(done)
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("directory was not removed when the workspace was cancelled")
+}
+
+func TestNilWorkspace(t *testing.T) {
+	var w *Workspace
+	dir, err := w.TempDir("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if w.Remaining() != 0 || w.Usage() != 0 || w.Close() != nil {
+		t.Errorf("unexpected nil workspace behavior")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("directory of a nil workspace was removed: %v", err)
+	}
+}
+
+func TestWorkspaceClone(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspaceclone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "data"), make([]byte, 10000), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"init"}, {"add", "data"}, {"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-m", "data"}} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	base, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(base)
+
+	// the quota of the workspace limits the clone when it is lower than the clone limits
+	w := NewWorkspace(base, 1000)
+	repo := &SourceRepository{location: dir, url: url.URL{Path: dir}}
+	repo.SetCloneLimits(CloneLimits{MaxSize: 1000000, Timeout: time.Minute})
+	repo.SetWorkspace(w)
+	if _, err := repo.LocalPath(); generrors.CodeOf(err) != generrors.CodeCloneTooLarge {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if entries, _ := ioutil.ReadDir(base); len(entries) != 0 {
+		t.Errorf("failed clone was not removed: %v", entries)
+	}
+
+	w = NewWorkspace(base, 0)
+	repo = &SourceRepository{location: dir, url: url.URL{Path: dir}}
+	repo.SetWorkspace(w)
+	path, err := repo.LocalPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(path) != base || w.Usage() == 0 {
+		t.Errorf("unexpected clone %s with usage %d", path, w.Usage())
+	}
+	w.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("clone was not removed: %v", err)
+	}
+}
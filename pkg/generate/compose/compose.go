@@ -0,0 +1,344 @@
+package compose
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Project is a docker-compose file of version 2 or 3.
+type Project struct {
+	Version  string              `json:"version"`
+	Services map[string]*Service `json:"services"`
+	// Volumes are the named volumes services may mount.
+	Volumes map[string]*Volume `json:"volumes"`
+}
+
+// Service is a service of a docker-compose file. Fields that have no equivalent in a pod are not read.
+type Service struct {
+	Image string `json:"image"`
+	// Build is set if the image of the service is built from a local directory.
+	Build       *Build      `json:"build"`
+	Entrypoint  Command     `json:"entrypoint"`
+	Command     Command     `json:"command"`
+	WorkingDir  string      `json:"working_dir"`
+	Environment Environment `json:"environment"`
+	Ports       []Port      `json:"ports"`
+	Expose      []Port      `json:"expose"`
+	Volumes     []Mount     `json:"volumes"`
+	// VolumesFrom are the services whose volumes are mounted into the service, with an optional :ro or :rw
+	// suffix.
+	VolumesFrom []string `json:"volumes_from"`
+	// NetworkMode may share the network of another service as service:<name>.
+	NetworkMode string `json:"network_mode"`
+}
+
+// Volume is a named volume of a docker-compose file.
+type Volume struct {
+	External interface{} `json:"external"`
+}
+
+// Build is the build section of a service, which is either a context directory or an object.
+type Build struct {
+	Context    string `json:"context"`
+	Dockerfile string `json:"dockerfile"`
+}
+
+// UnmarshalJSON reads a context directory or a build object.
+func (b *Build) UnmarshalJSON(data []byte) error {
+	var context string
+	if err := json.Unmarshal(data, &context); err == nil {
+		b.Context = context
+		return nil
+	}
+	type build Build
+	return json.Unmarshal(data, (*build)(b))
+}
+
+// Command is a command or entrypoint, which is either a list of arguments or a string that is split like a shell
+// would.
+type Command []string
+
+// UnmarshalJSON reads a list of arguments or a command line.
+func (c *Command) UnmarshalJSON(data []byte) error {
+	var line string
+	if err := json.Unmarshal(data, &line); err == nil {
+		args, err := splitCommand(line)
+		if err != nil {
+			return err
+		}
+		*c = args
+		return nil
+	}
+	var args []string
+	if err := json.Unmarshal(data, &args); err != nil {
+		return fmt.Errorf("a command must be a string or a list of strings: %v", err)
+	}
+	*c = args
+	return nil
+}
+
+// EnvVar is an environment variable of a service. Variables without a value are taken from the environment of
+// docker-compose, which generated objects cannot do.
+type EnvVar struct {
+	Name     string
+	Value    string
+	HasValue bool
+}
+
+// Environment are the environment variables of a service, which are either a list of NAME=value or a map. The
+// variables of a map are sorted by name.
+type Environment []EnvVar
+
+// UnmarshalJSON reads a list or a map of environment variables.
+func (e *Environment) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		for _, s := range list {
+			parts := strings.SplitN(s, "=", 2)
+			env := EnvVar{Name: parts[0]}
+			if len(parts) == 2 {
+				env.Value, env.HasValue = parts[1], true
+			}
+			*e = append(*e, env)
+		}
+		return nil
+	}
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("the environment must be a list or a map: %v", err)
+	}
+	names := []string{}
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		env := EnvVar{Name: name}
+		if v := values[name]; v != nil {
+			env.Value, env.HasValue = scalarString(v), true
+		}
+		*e = append(*e, env)
+	}
+	return nil
+}
+
+// Port is a port of a service. Published is the port of the host that the port is published on, or 0 if it is
+// only exposed to other services.
+type Port struct {
+	Target    int
+	Published int
+	Protocol  string
+}
+
+// UnmarshalJSON reads the short syntax [[ip:]published:]target[/protocol] or the long syntax of version 3.2.
+func (p *Port) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if long, ok := value.(map[string]interface{}); ok {
+		target, err := portNumber(scalarString(long["target"]))
+		if err != nil {
+			return err
+		}
+		p.Target = target
+		if published, ok := long["published"]; ok {
+			if p.Published, err = portNumber(scalarString(published)); err != nil {
+				return err
+			}
+		}
+		if protocol, ok := long["protocol"]; ok {
+			p.Protocol = strings.ToUpper(scalarString(protocol))
+		}
+		return nil
+	}
+
+	spec := scalarString(value)
+	if i := strings.Index(spec, "/"); i != -1 {
+		p.Protocol = strings.ToUpper(spec[i+1:])
+		spec = spec[:i]
+	}
+	parts := strings.Split(spec, ":")
+	target, err := portNumber(parts[len(parts)-1])
+	if err != nil {
+		return err
+	}
+	p.Target = target
+	if len(parts) > 1 && len(parts[len(parts)-2]) > 0 {
+		if p.Published, err = portNumber(parts[len(parts)-2]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MountType is the kind of a volume mounted into a service.
+type MountType string
+
+const (
+	// MountVolume mounts a named volume, or an anonymous volume if it has no source.
+	MountVolume MountType = "volume"
+	// MountBind mounts a directory of the host.
+	MountBind MountType = "bind"
+	// MountTmpfs mounts a temporary file system.
+	MountTmpfs MountType = "tmpfs"
+)
+
+// Mount is a volume mounted into a service.
+type Mount struct {
+	Type     MountType
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// UnmarshalJSON reads the short syntax [source:]target[:mode] or the long syntax of version 3.2.
+func (m *Mount) UnmarshalJSON(data []byte) error {
+	var spec string
+	if err := json.Unmarshal(data, &spec); err != nil {
+		long := struct {
+			Type     MountType `json:"type"`
+			Source   string    `json:"source"`
+			Target   string    `json:"target"`
+			ReadOnly bool      `json:"read_only"`
+		}{}
+		if err := json.Unmarshal(data, &long); err != nil {
+			return fmt.Errorf("a volume must be a string or an object: %v", err)
+		}
+		*m = Mount{Type: long.Type, Source: long.Source, Target: long.Target, ReadOnly: long.ReadOnly}
+		if len(m.Type) == 0 {
+			m.Type = MountVolume
+		}
+		return nil
+	}
+
+	parts := strings.Split(spec, ":")
+	switch len(parts) {
+	case 1:
+		m.Target = parts[0]
+	case 2, 3:
+		m.Source, m.Target = parts[0], parts[1]
+		if len(parts) == 3 {
+			for _, mode := range strings.Split(parts[2], ",") {
+				m.ReadOnly = m.ReadOnly || mode == "ro"
+			}
+		}
+	default:
+		return fmt.Errorf("the volume %q is not valid", spec)
+	}
+	m.Type = MountVolume
+	if strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~") {
+		m.Type = MountBind
+	}
+	return nil
+}
+
+// Load reads a docker-compose file of version 2 or 3 from data, which may be YAML or JSON.
+func Load(data []byte) (*Project, error) {
+	// the fields of services take several forms, so the file is converted without looking at the types it is
+	// read into
+	data, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the docker-compose file: %v", err)
+	}
+	project := &Project{}
+	if err := json.Unmarshal(data, project); err != nil {
+		return nil, fmt.Errorf("unable to read the docker-compose file: %v", err)
+	}
+	switch {
+	case len(project.Version) == 0:
+		return nil, fmt.Errorf("the docker-compose file has no version, only versions 2 and 3 are supported")
+	case !strings.HasPrefix(project.Version, "2") && !strings.HasPrefix(project.Version, "3"):
+		return nil, fmt.Errorf("the docker-compose file version %q is not supported, only versions 2 and 3 are", project.Version)
+	}
+	if len(project.Services) == 0 {
+		return nil, fmt.Errorf("the docker-compose file has no services")
+	}
+	for name, service := range project.Services {
+		if service == nil {
+			return nil, fmt.Errorf("the service %q is empty", name)
+		}
+	}
+	return project, nil
+}
+
+// ReadFile reads the docker-compose file at path.
+func ReadFile(path string) (*Project, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Load(data)
+}
+
+// scalarString formats a YAML scalar, which JSON decodes numbers of as floats.
+func scalarString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+func portNumber(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("%q is not a valid port, port ranges are not supported", s)
+	}
+	return port, nil
+}
+
+// splitCommand splits a command line into arguments at unquoted white space, removing single and double quotes
+// and backslash escapes.
+func splitCommand(line string) ([]string, error) {
+	args := []string{}
+	var current []rune
+	inArg := false
+	var quote rune
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped:
+			current = append(current, r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current = append(current, r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, string(current))
+				current, inArg = nil, false
+			}
+		default:
+			current = append(current, r)
+			inArg = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("the command %q has an unterminated quote or escape", line)
+	}
+	if inArg {
+		args = append(args, string(current))
+	}
+	return args, nil
+}
@@ -0,0 +1,117 @@
+package compose
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	project, err := Load([]byte(`
+version: "3.2"
+services:
+  web:
+    image: nginx:1.11
+    command: nginx -g 'daemon off;'
+    environment:
+      B: 2
+      A: "1"
+      C:
+    ports:
+    - "8080:80"
+    - "127.0.0.1:8443:443/tcp"
+    - target: 9000
+      published: 9000
+      protocol: udp
+    expose:
+    - 9090
+    volumes:
+    - data:/var/lib/data
+    - ./html:/usr/share/nginx/html:ro,z
+    - /tmp/cache
+    - type: tmpfs
+      target: /run
+  worker:
+    build: ./worker
+    entrypoint: ["/bin/worker"]
+    environment:
+    - MODE=batch
+    - TOKEN
+volumes:
+  data: {}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := project.Services["web"]
+	if !reflect.DeepEqual(web.Command, Command{"nginx", "-g", "daemon off;"}) {
+		t.Errorf("unexpected command: %#v", web.Command)
+	}
+	if !reflect.DeepEqual(web.Environment, Environment{{Name: "A", Value: "1", HasValue: true}, {Name: "B", Value: "2", HasValue: true}, {Name: "C"}}) {
+		t.Errorf("unexpected environment: %#v", web.Environment)
+	}
+	if !reflect.DeepEqual(web.Ports, []Port{{Target: 80, Published: 8080}, {Target: 443, Published: 8443, Protocol: "TCP"}, {Target: 9000, Published: 9000, Protocol: "UDP"}}) {
+		t.Errorf("unexpected ports: %#v", web.Ports)
+	}
+	if !reflect.DeepEqual(web.Expose, []Port{{Target: 9090}}) {
+		t.Errorf("unexpected exposed ports: %#v", web.Expose)
+	}
+	expected := []Mount{
+		{Type: MountVolume, Source: "data", Target: "/var/lib/data"},
+		{Type: MountBind, Source: "./html", Target: "/usr/share/nginx/html", ReadOnly: true},
+		{Type: MountVolume, Target: "/tmp/cache"},
+		{Type: MountTmpfs, Target: "/run"},
+	}
+	if !reflect.DeepEqual(web.Volumes, expected) {
+		t.Errorf("unexpected volumes: %#v", web.Volumes)
+	}
+	worker := project.Services["worker"]
+	if worker.Build == nil || worker.Build.Context != "./worker" || !reflect.DeepEqual(worker.Entrypoint, Command{"/bin/worker"}) {
+		t.Errorf("unexpected service: %#v", worker)
+	}
+	if !reflect.DeepEqual(worker.Environment, Environment{{Name: "MODE", Value: "batch", HasValue: true}, {Name: "TOKEN"}}) {
+		t.Errorf("unexpected environment: %#v", worker.Environment)
+	}
+	if _, ok := project.Volumes["data"]; !ok {
+		t.Errorf("unexpected volumes: %#v", project.Volumes)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"no version":   "services:\n  web:\n    image: nginx\n",
+		"version 1":    "version: \"1\"\nservices:\n  web:\n    image: nginx\n",
+		"no services":  "version: \"2\"\n",
+		"port range":   "version: \"2\"\nservices:\n  web:\n    image: nginx\n    ports:\n    - \"3000-3005\"\n",
+		"open quote":   "version: \"2\"\nservices:\n  web:\n    image: nginx\n    command: echo 'hello\n",
+		"empty server": "version: \"2\"\nservices:\n  web:\n",
+	}
+	for name, data := range tests {
+		if _, err := Load([]byte(data)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := map[string][]string{
+		"":                           {},
+		"echo":                       {"echo"},
+		`sh -c "echo \"a b\""`:       {"sh", "-c", `echo "a b"`},
+		`echo 'a\b'  c\ d ""`:        {"echo", `a\b`, "c d", ""},
+		"  python   manage.py  run ": {"python", "manage.py", "run"},
+	}
+	for line, expected := range tests {
+		args, err := splitCommand(line)
+		if err != nil {
+			t.Errorf("%q: %v", line, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, expected) {
+			t.Errorf("%q: unexpected arguments %#v", line, args)
+		}
+	}
+	if _, err := splitCommand(`echo "a`); err == nil || !strings.Contains(err.Error(), "unterminated") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,3 @@
+// Package compose converts the services of docker-compose files into deployment configs, persistent volume
+// claims and routes
+package compose
@@ -0,0 +1,290 @@
+package compose
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/resource"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	"github.com/openshift/origin/pkg/generate/app"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+// Objects returns the deployment configs, persistent volume claims and routes that run the services of the
+// project, along with warnings for the settings of the services that the objects cannot reproduce.
+//
+// Each service runs in a container of a deployment config named after it, except that services that mount the
+// volumes of another service with volumes_from, or share its network with network_mode, run in the pod of that
+// service. Named volumes become persistent volume claims, unless they are external, in which case the claim is
+// expected to exist. Anonymous volumes, host directories and tmpfs mounts become empty directories. A route is
+// generated for the first published port of each service. Services are expected to be added for the deployment
+// configs like for any other deployment config.
+func (p *Project) Objects() (app.Objects, []string, error) {
+	names := []string{}
+	for name := range p.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	warnings := []string{}
+	for _, name := range names {
+		service := p.Services[name]
+		switch {
+		case service.Build != nil && len(service.Image) == 0:
+			return nil, nil, fmt.Errorf("the service %q is built from %q, which is not supported - build its image and set it as the image of the service", name, service.Build.Context)
+		case service.Build != nil:
+			warnings = append(warnings, fmt.Sprintf("the service %q is not built, the image %s is used", name, service.Image))
+		case len(service.Image) == 0:
+			return nil, nil, fmt.Errorf("the service %q has no image", name)
+		}
+	}
+
+	pods, err := p.pods(names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	size, err := resource.ParseQuantity(app.DefaultPresetVolumeSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	objects := app.Objects{}
+	claims := make(map[string]bool)
+	routes := app.Objects{}
+	mounts := make(map[string][]kapi.VolumeMount)
+	for _, root := range sortedKeys(pods) {
+		dcName := objectName(root)
+		selector := map[string]string{"deploymentconfig": dcName}
+		template := kapi.PodSpec{}
+		volumes := make(map[string]bool)
+		for _, name := range pods[root] {
+			service := p.Services[name]
+			container := kapi.Container{
+				Name:       objectName(name),
+				Image:      service.Image,
+				Command:    service.Entrypoint,
+				Args:       service.Command,
+				WorkingDir: service.WorkingDir,
+			}
+			for _, env := range service.Environment {
+				if !env.HasValue {
+					warnings = append(warnings, fmt.Sprintf("the environment variable %s of the service %q has no value and is not set", env.Name, name))
+					continue
+				}
+				container.Env = append(container.Env, kapi.EnvVar{Name: env.Name, Value: env.Value})
+			}
+
+			ports := make(map[string]bool)
+			for _, port := range append(append([]Port{}, service.Ports...), service.Expose...) {
+				protocol := kapi.ProtocolTCP
+				if len(port.Protocol) > 0 {
+					protocol = kapi.Protocol(port.Protocol)
+				}
+				key := fmt.Sprintf("%d/%s", port.Target, protocol)
+				if ports[key] {
+					continue
+				}
+				ports[key] = true
+				container.Ports = append(container.Ports, kapi.ContainerPort{ContainerPort: port.Target, Protocol: protocol})
+			}
+			for _, port := range service.Ports {
+				if port.Published == 0 {
+					continue
+				}
+				routes = append(routes, &routeapi.Route{
+					ObjectMeta: kapi.ObjectMeta{Name: container.Name},
+					Spec: routeapi.RouteSpec{
+						To:   kapi.ObjectReference{Kind: "Service", Name: dcName},
+						Port: &routeapi.RoutePort{TargetPort: intstr.FromInt(port.Target)},
+					},
+				})
+				break
+			}
+
+			for i, m := range service.Volumes {
+				volume := kapi.Volume{Name: fmt.Sprintf("%s-volume-%d", container.Name, i+1)}
+				switch {
+				case m.Type == MountVolume && len(m.Source) > 0:
+					volume.Name = objectName(m.Source)
+					volume.PersistentVolumeClaim = &kapi.PersistentVolumeClaimVolumeSource{ClaimName: volume.Name}
+					if v := p.Volumes[m.Source]; (v == nil || v.External == nil) && !claims[volume.Name] {
+						claims[volume.Name] = true
+						objects = append(objects, persistentVolumeClaim(volume.Name, *size))
+					}
+				case m.Type == MountTmpfs:
+					volume.EmptyDir = &kapi.EmptyDirVolumeSource{Medium: kapi.StorageMediumMemory}
+				case m.Type == MountBind:
+					warnings = append(warnings, fmt.Sprintf("the host directory %s mounted by the service %q at %s is replaced by an empty directory", m.Source, name, m.Target))
+					fallthrough
+				default:
+					volume.EmptyDir = &kapi.EmptyDirVolumeSource{Medium: kapi.StorageMediumDefault}
+				}
+				if !volumes[volume.Name] {
+					volumes[volume.Name] = true
+					template.Volumes = append(template.Volumes, volume)
+				}
+				container.VolumeMounts = append(container.VolumeMounts, kapi.VolumeMount{Name: volume.Name, MountPath: m.Target, ReadOnly: m.ReadOnly})
+			}
+			mounts[name] = container.VolumeMounts
+			template.Containers = append(template.Containers, container)
+		}
+
+		// services are ordered after the services whose volumes they mount
+		for i, name := range pods[root] {
+			for _, from := range p.Services[name].VolumesFrom {
+				source, readOnly := volumesFromService(from)
+				for _, m := range mounts[source] {
+					m.ReadOnly = m.ReadOnly || readOnly
+					template.Containers[i].VolumeMounts = append(template.Containers[i].VolumeMounts, m)
+				}
+			}
+			mounts[name] = template.Containers[i].VolumeMounts
+		}
+
+		objects = append(objects, &deployapi.DeploymentConfig{
+			ObjectMeta: kapi.ObjectMeta{
+				Name: dcName,
+			},
+			Spec: deployapi.DeploymentConfigSpec{
+				Replicas: 1,
+				Selector: selector,
+				Template: &kapi.PodTemplateSpec{
+					ObjectMeta: kapi.ObjectMeta{
+						Labels: selector,
+					},
+					Spec: template,
+				},
+				Triggers: []deployapi.DeploymentTriggerPolicy{
+					{Type: deployapi.DeploymentTriggerOnConfigChange},
+				},
+			},
+		})
+	}
+	return append(objects, routes...), warnings, nil
+}
+
+// pods groups the services that share the volumes or the network of another service with that service. It returns
+// the services of each pod keyed by the service the others depend on, ordered so that every service comes after
+// the services it depends on.
+func (p *Project) pods(names []string) (map[string][]string, error) {
+	dependencies := make(map[string][]string)
+	for _, name := range names {
+		service := p.Services[name]
+		for _, from := range service.VolumesFrom {
+			source, _ := volumesFromService(from)
+			if strings.HasPrefix(from, "container:") {
+				return nil, fmt.Errorf("the service %q mounts the volumes of the container %q, only the volumes of services can be mounted", name, source)
+			}
+			if _, ok := p.Services[source]; !ok {
+				return nil, fmt.Errorf("the service %q mounts the volumes of the unknown service %q", name, source)
+			}
+			dependencies[name] = append(dependencies[name], source)
+		}
+		switch mode := service.NetworkMode; {
+		case strings.HasPrefix(mode, "service:"):
+			source := strings.TrimPrefix(mode, "service:")
+			if _, ok := p.Services[source]; !ok {
+				return nil, fmt.Errorf("the service %q shares the network of the unknown service %q", name, source)
+			}
+			dependencies[name] = append(dependencies[name], source)
+		case strings.HasPrefix(mode, "container:"):
+			return nil, fmt.Errorf("the service %q shares the network of a container, only the network of services can be shared", name)
+		}
+	}
+
+	// services that depend on each other share a pod, named after the first service without dependencies
+	pod := make(map[string]string)
+	var assign func(name, root string)
+	assign = func(name, root string) {
+		if _, ok := pod[name]; ok {
+			return
+		}
+		pod[name] = root
+		for _, dependency := range dependencies[name] {
+			assign(dependency, root)
+		}
+		for _, other := range names {
+			for _, dependency := range dependencies[other] {
+				if dependency == name {
+					assign(other, root)
+				}
+			}
+		}
+	}
+	for _, name := range names {
+		if len(dependencies[name]) == 0 {
+			assign(name, name)
+		}
+	}
+	for _, name := range names {
+		if _, ok := pod[name]; !ok {
+			return nil, fmt.Errorf("the service %q depends on itself through volumes_from or network_mode", name)
+		}
+	}
+
+	pods := make(map[string][]string)
+	added := make(map[string]bool)
+	var add func(name string)
+	add = func(name string) {
+		if added[name] {
+			return
+		}
+		added[name] = true
+		for _, dependency := range dependencies[name] {
+			add(dependency)
+		}
+		pods[pod[name]] = append(pods[pod[name]], name)
+	}
+	for _, name := range names {
+		add(name)
+	}
+	return pods, nil
+}
+
+// volumesFromService returns the service of an entry of volumes_from and whether its volumes are mounted read only.
+func volumesFromService(from string) (string, bool) {
+	from = strings.TrimPrefix(from, "container:")
+	parts := strings.SplitN(from, ":", 2)
+	return parts[0], len(parts) == 2 && parts[1] == "ro"
+}
+
+func persistentVolumeClaim(name string, size resource.Quantity) *kapi.PersistentVolumeClaim {
+	return &kapi.PersistentVolumeClaim{
+		ObjectMeta: kapi.ObjectMeta{
+			Name: name,
+		},
+		Spec: kapi.PersistentVolumeClaimSpec{
+			AccessModes: []kapi.PersistentVolumeAccessMode{kapi.ReadWriteOnce},
+			Resources: kapi.ResourceRequirements{
+				Requests: kapi.ResourceList{
+					kapi.ResourceStorage: size,
+				},
+			},
+		},
+	}
+}
+
+// objectName turns the name of a service or a volume into a valid object name.
+func objectName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '-'
+	}, strings.ToLower(name))
+	return strings.Trim(name, "-")
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,124 @@
+package compose
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/intstr"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	routeapi "github.com/openshift/origin/pkg/route/api"
+)
+
+func TestObjects(t *testing.T) {
+	project, err := Load([]byte(`
+version: "2"
+services:
+  db:
+    image: mysql:5.6
+    environment:
+      MYSQL_ROOT_PASSWORD: secret
+      MYSQL_USER:
+    expose:
+    - 3306
+    volumes:
+    - db_data:/var/lib/mysql
+    - /etc/mysql/conf.d
+  web:
+    image: nginx:1.11
+    build: ./web
+    ports:
+    - "8080:80"
+    - "8443:443"
+    volumes:
+    - ./html:/usr/share/nginx/html
+    - shared:/var/cache
+  log_shipper:
+    image: fluentd
+    volumes_from:
+    - web:ro
+    network_mode: service:web
+volumes:
+  db_data: {}
+  shared:
+    external: true
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, warnings, err := project.Objects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 3 || !strings.Contains(warnings[0], `"web" is not built`) || !strings.Contains(warnings[1], "MYSQL_USER") || !strings.Contains(warnings[2], "./html") {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+	if len(objects) != 4 {
+		t.Fatalf("unexpected objects: %#v", objects)
+	}
+
+	claim, ok := objects[0].(*kapi.PersistentVolumeClaim)
+	if !ok || claim.Name != "db-data" {
+		t.Errorf("unexpected claim: %#v", objects[0])
+	}
+
+	db, ok := objects[1].(*deployapi.DeploymentConfig)
+	if !ok || db.Name != "db" || len(db.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("unexpected deployment config: %#v", objects[1])
+	}
+	container := db.Spec.Template.Spec.Containers[0]
+	if !reflect.DeepEqual(container.Env, []kapi.EnvVar{{Name: "MYSQL_ROOT_PASSWORD", Value: "secret"}}) {
+		t.Errorf("unexpected environment: %#v", container.Env)
+	}
+	if !reflect.DeepEqual(container.Ports, []kapi.ContainerPort{{ContainerPort: 3306, Protocol: kapi.ProtocolTCP}}) {
+		t.Errorf("unexpected ports: %#v", container.Ports)
+	}
+	volumes := db.Spec.Template.Spec.Volumes
+	if len(volumes) != 2 || volumes[0].Name != "db-data" || volumes[0].PersistentVolumeClaim == nil || volumes[0].PersistentVolumeClaim.ClaimName != "db-data" || volumes[1].Name != "db-volume-2" || volumes[1].EmptyDir == nil {
+		t.Errorf("unexpected volumes: %#v", volumes)
+	}
+
+	// the log shipper shares the pod of the web service and mounts its volumes read only
+	web, ok := objects[2].(*deployapi.DeploymentConfig)
+	if !ok || web.Name != "web" || len(web.Spec.Template.Spec.Containers) != 2 {
+		t.Fatalf("unexpected deployment config: %#v", objects[2])
+	}
+	if volumes := web.Spec.Template.Spec.Volumes; len(volumes) != 2 || volumes[0].EmptyDir == nil || volumes[1].PersistentVolumeClaim == nil || volumes[1].PersistentVolumeClaim.ClaimName != "shared" {
+		t.Errorf("unexpected volumes: %#v", volumes)
+	}
+	shipper := web.Spec.Template.Spec.Containers[1]
+	expected := []kapi.VolumeMount{
+		{Name: "web-volume-1", MountPath: "/usr/share/nginx/html", ReadOnly: true},
+		{Name: "shared", MountPath: "/var/cache", ReadOnly: true},
+	}
+	if shipper.Name != "log-shipper" || !reflect.DeepEqual(shipper.VolumeMounts, expected) {
+		t.Errorf("unexpected container: %#v", shipper)
+	}
+
+	route, ok := objects[3].(*routeapi.Route)
+	if !ok || route.Name != "web" || route.Spec.To.Name != "web" || route.Spec.Port == nil || route.Spec.Port.TargetPort != intstr.FromInt(80) {
+		t.Errorf("unexpected route: %#v", objects[3])
+	}
+}
+
+func TestObjectsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"build without image":  "version: \"2\"\nservices:\n  web:\n    build: .\n",
+		"unknown volumes from": "version: \"2\"\nservices:\n  web:\n    image: nginx\n    volumes_from:\n    - data\n",
+		"container volumes":    "version: \"2\"\nservices:\n  web:\n    image: nginx\n    volumes_from:\n    - container:data\n",
+		"container network":    "version: \"2\"\nservices:\n  web:\n    image: nginx\n    network_mode: container:proxy\n",
+		"cycle":                "version: \"2\"\nservices:\n  a:\n    image: nginx\n    volumes_from:\n    - b\n  b:\n    image: nginx\n    volumes_from:\n    - a\n",
+	}
+	for name, data := range tests {
+		project, err := Load([]byte(data))
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if _, _, err := project.Objects(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package errors
+
+import "fmt"
+
+// Code is a machine readable identifier for the category of an error returned during generation. Codes
+// are stable, while the messages of errors may change.
+type Code string
+
+const (
+	// CodeUnknown is returned by CodeOf for errors that carry no code.
+	CodeUnknown Code = ""
+
+	// Resolution errors
+	CodeNoMatch           Code = "NoMatch"
+	CodePartialMatch      Code = "PartialMatch"
+	CodeMultipleMatches   Code = "MultipleMatches"
+	CodeAmbiguousArgument Code = "AmbiguousArgument"
+	CodeNotAnImage        Code = "NotAnImage"
+
+	// Detection errors
+	CodeNoGit               Code = "NoGit"
+	CodeSourceDirAndURL     Code = "SourceDirAndURL"
+	CodeInvalidSourceDir    Code = "InvalidSourceDir"
+	CodeCouldNotDetect      Code = "CouldNotDetect"
+	CodeNoBuilderFound      Code = "NoBuilderFound"
+	CodeInvalidDockerfile   Code = "InvalidDockerfile"
+	CodeImageNotFound       Code = "ImageNotFound"
+	CodeMultipleDockerfiles Code = "MultipleDockerfiles"
+	CodeNoDockerfile        Code = "NoDockerfile"
+	CodeNoLanguageDetected  Code = "NoLanguageDetected"
+	CodeSourceRequired      Code = "SourceRequired"
+	CodeIncompatibleBuilder Code = "IncompatibleBuilder"
+	CodeNotABuilder         Code = "NotABuilder"
+	CodeCloneTimedOut       Code = "CloneTimedOut"
+	CodeCloneTooLarge       Code = "CloneTooLarge"
+
+	// Generation errors
+	CodeNoInputs               Code = "NoInputs"
+	CodeInvalidArgument        Code = "InvalidArgument"
+	CodeNameRequired           Code = "NameRequired"
+	CodeCircularReference      Code = "CircularOutputReference"
+	CodeRequiresExplicitAccess Code = "RequiresExplicitAccess"
+)
+
+// Coded is implemented by errors that carry a code.
+type Coded interface {
+	error
+	Code() Code
+}
+
+// Error is an error with a code and an optional cause. The message is returned as is, so callers that wrap
+// a cause include its text themselves where it should be shown.
+type Error struct {
+	code    Code
+	message string
+	cause   error
+}
+
+// New returns an error with the given code and message.
+func New(code Code, message string) *Error {
+	return &Error{code: code, message: message}
+}
+
+// Newf returns an error with the given code and a formatted message.
+func Newf(code Code, format string, args ...interface{}) *Error {
+	return &Error{code: code, message: fmt.Sprintf(format, args...)}
+}
+
+// Wrapf returns an error with the given code and a formatted message, caused by cause.
+func Wrapf(code Code, cause error, format string, args ...interface{}) *Error {
+	return &Error{code: code, message: fmt.Sprintf(format, args...), cause: cause}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// Code returns the code of the error.
+func (e *Error) Code() Code {
+	return e.code
+}
+
+// Cause returns the error that caused this one, or nil.
+func (e *Error) Cause() error {
+	return e.cause
+}
+
+// CodeOf returns the code of err. If err carries no code, the code of its cause is returned, and for
+// aggregate errors the first code of the aggregated errors.
+func CodeOf(err error) Code {
+	if err == nil {
+		return CodeUnknown
+	}
+	if coded, ok := err.(Coded); ok && coded.Code() != CodeUnknown {
+		return coded.Code()
+	}
+	if causer, ok := err.(interface {
+		Cause() error
+	}); ok {
+		if code := CodeOf(causer.Cause()); code != CodeUnknown {
+			return code
+		}
+	}
+	if aggregate, ok := err.(interface {
+		Errors() []error
+	}); ok {
+		for _, err := range aggregate.Errors() {
+			if code := CodeOf(err); code != CodeUnknown {
+				return code
+			}
+		}
+	}
+	return CodeUnknown
+}
+
+// HasCode returns true if err or any error it wraps or aggregates has the given code.
+func HasCode(err error, code Code) bool {
+	if err == nil {
+		return false
+	}
+	if coded, ok := err.(Coded); ok && coded.Code() == code {
+		return true
+	}
+	if causer, ok := err.(interface {
+		Cause() error
+	}); ok && HasCode(causer.Cause(), code) {
+		return true
+	}
+	if aggregate, ok := err.(interface {
+		Errors() []error
+	}); ok {
+		for _, err := range aggregate.Errors() {
+			if HasCode(err, code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Code returns the code of a GenerationError.
+func (e GenerationError) Code() Code {
+	switch e {
+	case NoGit:
+		return CodeNoGit
+	case SourceDirAndURL:
+		return CodeSourceDirAndURL
+	case InvalidSourceDir:
+		return CodeInvalidSourceDir
+	case CouldNotDetect:
+		return CodeCouldNotDetect
+	case NoBuilderFound:
+		return CodeNoBuilderFound
+	case InvalidDockerfile:
+		return CodeInvalidDockerfile
+	case ImageNotFound:
+		return CodeImageNotFound
+	}
+	return CodeUnknown
+}
+
+func (e multipleDockerFilesError) Code() Code {
+	return CodeMultipleDockerfiles
+}
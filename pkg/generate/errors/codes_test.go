@@ -0,0 +1,44 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	kutilerrors "k8s.io/kubernetes/pkg/util/errors"
+)
+
+func TestCodeOf(t *testing.T) {
+	noGit := New(CodeNoGit, "git is missing")
+	wrapped := Wrapf(CodeUnknown, noGit, "can't build: %v", noGit)
+	tests := []struct {
+		err  error
+		code Code
+	}{
+		{err: nil, code: CodeUnknown},
+		{err: fmt.Errorf("plain"), code: CodeUnknown},
+		{err: noGit, code: CodeNoGit},
+		{err: wrapped, code: CodeNoGit},
+		{err: Wrapf(CodeNameRequired, noGit, "no name"), code: CodeNameRequired},
+		{err: InvalidDockerfile, code: CodeInvalidDockerfile},
+		{err: NewMultipleDockerfilesErr([]string{"a/Dockerfile"}), code: CodeMultipleDockerfiles},
+		{err: kutilerrors.NewAggregate([]error{fmt.Errorf("plain"), wrapped}), code: CodeNoGit},
+	}
+	for i, test := range tests {
+		if code := CodeOf(test.err); code != test.code {
+			t.Errorf("%d: expected %q, got %q", i, test.code, code)
+		}
+	}
+
+	if wrapped.Error() != "can't build: git is missing" || wrapped.Cause() != noGit {
+		t.Errorf("unexpected wrapped error: %v", wrapped)
+	}
+	aggregate := kutilerrors.NewAggregate([]error{New(CodeNoInputs, "none"), Wrapf(CodeInvalidArgument, noGit, "invalid")})
+	for _, code := range []Code{CodeNoInputs, CodeInvalidArgument, CodeNoGit} {
+		if !HasCode(aggregate, code) {
+			t.Errorf("expected %q to be found", code)
+		}
+	}
+	if HasCode(aggregate, CodeNoMatch) || HasCode(nil, CodeUnknown) {
+		t.Errorf("unexpected code found")
+	}
+}
@@ -0,0 +1,20 @@
+// +build !windows
+
+package git
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// startProcessGroup makes cmd the leader of a new process group, so that the processes it forks can be killed
+// with it.
+func startProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup kills the started cmd and every process of its process group, which would otherwise keep its
+// output pipes open and block waiting for it.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,13 @@
+// +build windows
+
+package git
+
+import "os/exec"
+
+// startProcessGroup is a no-op on Windows.
+func startProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills the started cmd. The processes it created are not killed on Windows.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
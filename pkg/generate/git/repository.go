@@ -9,7 +9,10 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
 
 	"github.com/golang/glog"
@@ -36,6 +39,7 @@ type Repository interface {
 	ShowFormat(dir, commit, format string) (string, error)
 	ListRemote(url string, args ...string) (string, string, error)
 	GetInfo(location string) (*SourceInfo, []error)
+	LastCommitTime(dir, path string) (time.Time, error)
 }
 
 // SourceInfo stores information about the source code
@@ -47,13 +51,33 @@ type SourceInfo struct {
 type CloneOptions struct {
 	Recursive bool
 	Quiet     bool
+	// Timeout, if set, stops the clone with ErrCloneTimedOut once it has run for that long.
+	Timeout time.Duration
+	// MaxSize, if set, stops the clone with ErrCloneTooLarge once the directory it clones into holds more than
+	// that many bytes.
+	MaxSize int64
 }
 
+var (
+	// ErrCloneTimedOut is returned when a clone is stopped because it ran longer than its timeout.
+	ErrCloneTimedOut = fmt.Errorf("the clone did not complete in time")
+	// ErrCloneTooLarge is returned when a clone is stopped because it grew larger than its maximum size.
+	ErrCloneTooLarge = fmt.Errorf("the repository is larger than the maximum size")
+)
+
+// cloneSizeInterval is how often the size of a clone with a maximum size is checked.
+var cloneSizeInterval = 250 * time.Millisecond
+
 // execGitFunc is a function that executes a Git command
 type execGitFunc func(w io.Writer, dir string, args ...string) (string, string, error)
 
+// execGitUntilFunc is a function that executes a Git command and kills it when stop is closed
+type execGitUntilFunc func(stop <-chan struct{}, dir string, args ...string) (string, string, error)
+
 type repository struct {
 	git execGitFunc
+	// gitUntil, if set, is used for commands that may be stopped before they complete
+	gitUntil execGitUntilFunc
 }
 
 // NewRepository creates a new Repository
@@ -67,6 +91,9 @@ func NewRepositoryWithEnv(env []string) Repository {
 		git: func(w io.Writer, dir string, args ...string) (string, string, error) {
 			return command(w, "git", dir, env, args...)
 		},
+		gitUntil: func(stop <-chan struct{}, dir string, args ...string) (string, string, error) {
+			return commandUntil(stop, nil, "git", dir, env, args...)
+		},
 	}
 }
 
@@ -83,6 +110,9 @@ func NewRepositoryForBinaryWithEnvironment(gitBinaryPath string, env []string) R
 		git: func(w io.Writer, dir string, args ...string) (string, string, error) {
 			return command(w, gitBinaryPath, dir, env, args...)
 		},
+		gitUntil: func(stop <-chan struct{}, dir string, args ...string) (string, string, error) {
+			return commandUntil(stop, nil, gitBinaryPath, dir, env, args...)
+		},
 	}
 }
 
@@ -182,10 +212,70 @@ func (r *repository) CloneWithOptions(location string, url string, opts CloneOpt
 	}
 	args = append(args, url)
 	args = append(args, location)
-	_, _, err := r.git(nil, "", args...)
+	if (opts.Timeout <= 0 && opts.MaxSize <= 0) || r.gitUntil == nil {
+		_, _, err := r.git(nil, "", args...)
+		return err
+	}
+
+	// the first limit that is reached stops the clone and is reported instead of the error of the killed command
+	stop := make(chan struct{})
+	var once sync.Once
+	var reason error
+	halt := func(err error) {
+		once.Do(func() {
+			reason = err
+			close(stop)
+		})
+	}
+	if opts.Timeout > 0 {
+		timer := time.AfterFunc(opts.Timeout, func() { halt(ErrCloneTimedOut) })
+		defer timer.Stop()
+	}
+	if opts.MaxSize > 0 {
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			ticker := time.NewTicker(cloneSizeInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-stop:
+					return
+				case <-ticker.C:
+					if dirSize(location) > opts.MaxSize {
+						halt(ErrCloneTooLarge)
+						return
+					}
+				}
+			}
+		}()
+	}
+	_, _, err := r.gitUntil(stop, "", args...)
+	select {
+	case <-stop:
+		return reason
+	default:
+	}
+	if err == nil && opts.MaxSize > 0 && dirSize(location) > opts.MaxSize {
+		return ErrCloneTooLarge
+	}
 	return err
 }
 
+// dirSize returns the number of bytes held by the files under dir, ignoring files that cannot be read.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
 // Clone clones a remote git repository to a local directory
 func (r *repository) Clone(location string, url string) error {
 	return r.CloneWithOptions(location, url, CloneOptions{Recursive: true})
@@ -255,6 +345,23 @@ func (r *repository) Init(location string, bare bool) error {
 	return err
 }
 
+// LastCommitTime returns the time of the last commit that changed path, relative to the repository at
+// location.
+func (r *repository) LastCommitTime(location, path string) (time.Time, error) {
+	out, _, err := r.git(nil, location, "log", "-1", "--format=%ct", "--", path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(out) == 0 {
+		return time.Time{}, fmt.Errorf("no commit changed %s", path)
+	}
+	seconds, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse the commit time %q: %v", out, err)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
 // GetInfo retrieves the informations about the source code and commit
 func (r *repository) GetInfo(location string) (*SourceInfo, []error) {
 	errors := []error{}
@@ -284,6 +391,11 @@ func (r *repository) GetInfo(location string) (*SourceInfo, []error) {
 // The command's standard out and error are trimmed and returned as strings
 // It may return the type *GitError if the command itself fails.
 func command(w io.Writer, name, dir string, env []string, args ...string) (stdout, stderr string, err error) {
+	return commandUntil(nil, w, name, dir, env, args...)
+}
+
+// commandUntil executes an external command like command, killing it if stop is closed before it completes.
+func commandUntil(stop <-chan struct{}, w io.Writer, name, dir string, env []string, args ...string) (stdout, stderr string, err error) {
 	cmdOut := &bytes.Buffer{}
 	cmdErr := &bytes.Buffer{}
 
@@ -305,7 +417,24 @@ func command(w io.Writer, name, dir string, env []string, args ...string) (stdou
 		}
 	}
 
-	err = cmd.Run()
+	if stop == nil {
+		err = cmd.Run()
+	} else {
+		// git runs helpers such as git-remote-https in child processes, which are killed with it
+		startProcessGroup(cmd)
+		if err = cmd.Start(); err == nil {
+			exited := make(chan struct{})
+			go func() {
+				select {
+				case <-stop:
+					killProcessGroup(cmd)
+				case <-exited:
+				}
+			}()
+			err = cmd.Wait()
+			close(exited)
+		}
+	}
 	if err != nil {
 		glog.V(4).Infof("Exec error: %v", err)
 	}
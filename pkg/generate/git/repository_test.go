@@ -1,8 +1,13 @@
 package git
 
 import (
+	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestGetRootDir(t *testing.T) {
@@ -93,6 +98,65 @@ func TestClone(t *testing.T) {
 	}
 }
 
+func TestCloneLimits(t *testing.T) {
+	defer func(interval time.Duration) { cloneSizeInterval = interval }(cloneSizeInterval)
+	cloneSizeInterval = 5 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "clone-limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the fake clone writes its content and then waits to be stopped
+	clone := func(content []byte) execGitUntilFunc {
+		return func(stop <-chan struct{}, _ string, args ...string) (string, string, error) {
+			if err := ioutil.WriteFile(filepath.Join(args[len(args)-1], "file"), content, 0600); err != nil {
+				return "", "", err
+			}
+			select {
+			case <-stop:
+				return "", "", fmt.Errorf("killed")
+			case <-time.After(5 * time.Second):
+				return "", "", nil
+			}
+		}
+	}
+	r := &repository{git: makeExecFunc("", nil), gitUntil: clone(make([]byte, 100))}
+	if err := r.CloneWithOptions(dir, "https://test/url/to/repository", CloneOptions{MaxSize: 50, Timeout: 5 * time.Second}); err != ErrCloneTooLarge {
+		t.Errorf("unexpected error: %v", err)
+	}
+	r = &repository{git: makeExecFunc("", nil), gitUntil: clone(nil)}
+	if err := r.CloneWithOptions(dir, "https://test/url/to/repository", CloneOptions{MaxSize: 1000, Timeout: 20 * time.Millisecond}); err != ErrCloneTimedOut {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCommandUntil(t *testing.T) {
+	stop := make(chan struct{})
+	time.AfterFunc(10*time.Millisecond, func() { close(stop) })
+	start := time.Now()
+	if _, _, err := commandUntil(stop, nil, "sleep", "", nil, "5"); err == nil {
+		t.Errorf("expected the stopped command to fail")
+	}
+	if time.Now().Sub(start) > 4*time.Second {
+		t.Errorf("the command was not killed")
+	}
+}
+
+func TestCommandUntilKillsChildren(t *testing.T) {
+	stop := make(chan struct{})
+	time.AfterFunc(10*time.Millisecond, func() { close(stop) })
+	start := time.Now()
+	// the background sleep inherits the output pipes of the shell and keeps them open until it is killed
+	if _, _, err := commandUntil(stop, nil, "sh", "", nil, "-c", "sleep 5 & sleep 5"); err == nil {
+		t.Errorf("expected the stopped command to fail")
+	}
+	if time.Now().Sub(start) > 4*time.Second {
+		t.Errorf("the children of the command were not killed")
+	}
+}
+
 func TestCheckout(t *testing.T) {
 	r := &repository{git: makeExecFunc("", nil)}
 	err := r.Checkout("/test/dir", "branch2")
@@ -101,6 +165,21 @@ func TestCheckout(t *testing.T) {
 	}
 }
 
+func TestLastCommitTime(t *testing.T) {
+	r := &repository{git: makeExecFunc("1476600000\n", nil)}
+	result, err := r.LastCommitTime("/test/dir", "app")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Unix() != 1476600000 {
+		t.Errorf("Unexpected result: %v", result)
+	}
+	r = &repository{git: makeExecFunc("", nil)}
+	if _, err := r.LastCommitTime("/test/dir", "app"); err == nil {
+		t.Errorf("Expected an error for a path without commits")
+	}
+}
+
 func makeExecFunc(output string, err error) execGitFunc {
 	return func(w io.Writer, dir string, args ...string) (out string, errout string, resultErr error) {
 		out = output
@@ -1,8 +1,10 @@
 package source
 
 import (
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Info is detected platform information from a source directory
@@ -28,6 +30,8 @@ var DefaultDetectors = Detectors{
 	DetectPython,
 	DetectPerl,
 	DetectScala,
+	DetectDotNet,
+	DetectRust,
 }
 
 type sourceDetector struct {
@@ -80,7 +84,18 @@ func DetectScala(dir string) (*Info, bool) {
 	return detect("scala", dir, "build.sbt")
 }
 
-// detect returns an Info object with the given platform if the source at dir contains any of the argument files
+// DetectDotNet detects .NET Core source
+func DetectDotNet(dir string) (*Info, bool) {
+	return detect("dotnet", dir, "project.json", "*.csproj")
+}
+
+// DetectRust detects Rust source
+func DetectRust(dir string) (*Info, bool) {
+	return detect("rust", dir, "Cargo.toml")
+}
+
+// detect returns an Info object with the given platform if the source at dir contains any of the argument files.
+// Files may be patterns, such as *.csproj, that match the names of the files in dir.
 func detect(platform string, dir string, files ...string) (*Info, bool) {
 	if filesPresent(dir, files) {
 		return &Info{
@@ -92,6 +107,12 @@ func detect(platform string, dir string, files ...string) (*Info, bool) {
 
 func filesPresent(dir string, files []string) bool {
 	for _, f := range files {
+		if strings.ContainsAny(f, "*?[") {
+			if matchPresent(dir, f) {
+				return true
+			}
+			continue
+		}
 		_, err := os.Stat(filepath.Join(dir, f))
 		if err == nil {
 			return true
@@ -99,3 +120,17 @@ func filesPresent(dir string, files []string) bool {
 	}
 	return false
 }
+
+// matchPresent returns true if dir contains a file whose name matches pattern.
+func matchPresent(dir string, pattern string) bool {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if ok, _ := filepath.Match(pattern, entry.Name()); ok && !entry.IsDir() {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,9 @@
 package source
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -26,6 +29,41 @@ func TestDetectSource(t *testing.T) {
 	}
 }
 
+func TestDefaultDetectors(t *testing.T) {
+	tests := []struct {
+		files    []string
+		platform string
+	}{
+		{files: []string{"project.json", "Program.cs"}, platform: "dotnet"},
+		{files: []string{"web.csproj", "Program.cs"}, platform: "dotnet"},
+		{files: []string{"Cargo.toml", "Cargo.lock"}, platform: "rust"},
+		{files: []string{"Gemfile"}, platform: "ruby"},
+		{files: []string{"web.csproj.bak", "README.md"}},
+	}
+	for _, test := range tests {
+		dir, err := ioutil.TempDir("", "detector")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range test.files {
+			if err := ioutil.WriteFile(filepath.Join(dir, file), []byte{}, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		info, ok := DefaultDetectors.DetectSource(dir)
+		os.RemoveAll(dir)
+		if len(test.platform) == 0 {
+			if ok {
+				t.Errorf("%v: unexpected platform: %#v", test.files, info)
+			}
+			continue
+		}
+		if !ok || info.Platform != test.platform {
+			t.Errorf("%v: unexpected platform: %#v", test.files, info)
+		}
+	}
+}
+
 func fake1(dir string) (*Info, bool) {
 	if strings.Contains(dir, "fake1") {
 		return &Info{
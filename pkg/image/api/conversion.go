@@ -10,7 +10,8 @@ import (
 
 func init() {
 	err := kapi.Scheme.AddConversionFuncs(
-		// Convert docker client object to internal object
+		// Convert docker client object to internal object. The docker client image does not report the OS, so
+		// callers that know it must set out.OS themselves - an empty OS means the OS is unknown.
 		func(in *docker.Image, out *DockerImage, s conversion.Scope) error {
 			if err := s.Convert(&in.Config, &out.Config, conversion.AllowDifferentFieldTypeNames); err != nil {
 				return err
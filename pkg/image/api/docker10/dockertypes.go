@@ -19,6 +19,7 @@ type DockerImage struct {
 	Author          string           `json:"Author,omitempty"`
 	Config          *DockerConfig    `json:"Config,omitempty"`
 	Architecture    string           `json:"Architecture,omitempty"`
+	OS              string           `json:"Os,omitempty"`
 	Size            int64            `json:"Size,omitempty"`
 }
 
@@ -19,6 +19,7 @@ type DockerImage struct {
 	Author          string           `json:"author,omitempty"`
 	Config          *DockerConfig    `json:"config,omitempty"`
 	Architecture    string           `json:"architecture,omitempty"`
+	OS              string           `json:"os,omitempty"`
 	Size            int64            `json:"size,omitempty"`
 }
 
@@ -21,6 +21,7 @@ type DockerImage struct {
 	Author          string           `json:"Author,omitempty"`
 	Config          *DockerConfig    `json:"Config,omitempty"`
 	Architecture    string           `json:"Architecture,omitempty"`
+	OS              string           `json:"Os,omitempty"`
 	Size            int64            `json:"Size,omitempty"`
 }
 
@@ -98,6 +99,7 @@ type DockerV1CompatibilityImage struct {
 	Author          string           `json:"author,omitempty"`
 	Config          *DockerConfig    `json:"config,omitempty"`
 	Architecture    string           `json:"architecture,omitempty"`
+	OS              string           `json:"os,omitempty"`
 	Size            int64            `json:"size,omitempty"`
 }
 
@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
 	"regexp"
 	"sort"
@@ -59,7 +60,7 @@ func isRegistryName(str string) bool {
 // IsRegistryDockerHub returns true if the given registry name belongs to
 // Docker hub.
 func IsRegistryDockerHub(registry string) bool {
-	switch registry {
+	switch NormalizeRegistryHost(registry) {
 	case DockerDefaultRegistry, DockerDefaultV1Registry, DockerDefaultV2Registry:
 		return true
 	default:
@@ -67,6 +68,25 @@ func IsRegistryDockerHub(registry string) bool {
 	}
 }
 
+// NormalizeRegistryHost returns the canonical form of a registry host, so that the same registry is
+// identified by a single host when images, credentials and transports are matched. The host name is
+// lowercased and the default HTTPS port is removed, while any other port is kept: registry.example.com:443
+// and registry.example.com are the same registry, but registry.example.com:5000 is not.
+func NormalizeRegistryHost(registry string) string {
+	host, port, err := net.SplitHostPort(registry)
+	if err != nil {
+		return strings.ToLower(registry)
+	}
+	if port == "443" {
+		if strings.Contains(host, ":") {
+			// IPv6 addresses keep their brackets
+			return "[" + strings.ToLower(host) + "]"
+		}
+		return strings.ToLower(host)
+	}
+	return net.JoinHostPort(strings.ToLower(host), port)
+}
+
 // ParseDockerImageReference parses a Docker pull spec string into a
 // DockerImageReference.
 func ParseDockerImageReference(spec string) (DockerImageReference, error) {
@@ -121,7 +141,21 @@ func ParseDockerImageReference(spec string) (DockerImageReference, error) {
 		ref.ID = id
 		break
 	default:
-		return ref, fmt.Errorf("the docker pull spec %q must be two or three segments separated by slashes", spec)
+		// registry/path/namespace/name, used by registries that serve many repositories under a path such as
+		// Artifactory and Nexus, which keeps all of the segments between the registry and the name as the namespace
+		if !isRegistryName(repoParts[0]) {
+			return ref, fmt.Errorf("the docker pull spec %q must be two or three segments separated by slashes", spec)
+		}
+		for _, part := range repoParts[1:] {
+			if len(part) == 0 {
+				return ref, fmt.Errorf("the docker pull spec %q must be two or three segments separated by slashes", spec)
+			}
+		}
+		ref.Registry = repoParts[0]
+		ref.Namespace = strings.Join(repoParts[1:len(repoParts)-1], "/")
+		ref.Name = repoParts[len(repoParts)-1]
+		ref.Tag = tag
+		ref.ID = id
 	}
 
 	return ref, nil
@@ -173,8 +207,10 @@ func (r DockerImageReference) RepositoryName() string {
 	return r.Exact()
 }
 
-// RepositoryName returns the registry relative name
+// RegistryURL returns the URL of the registry of the reference, with the host normalized by
+// NormalizeRegistryHost.
 func (r DockerImageReference) RegistryURL() *url.URL {
+	r.Registry = NormalizeRegistryHost(r.Registry)
 	return &url.URL{
 		Scheme: "https",
 		Host:   r.AsV2().Registry,
@@ -267,6 +303,44 @@ func (r DockerImageReference) String() string {
 	return r.Exact()
 }
 
+// ImagePlatform identifies one of the images of a manifest list or an image index by the operating system,
+// architecture and optional variant it runs on.
+type ImagePlatform struct {
+	OS           string
+	Architecture string
+	Variant      string
+}
+
+// ParseImagePlatform parses a platform of the form os/architecture[/variant], such as linux/arm64/v8.
+func ParseImagePlatform(platform string) (ImagePlatform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 {
+		return ImagePlatform{}, fmt.Errorf("the platform %q must be of the form os/architecture[/variant]", platform)
+	}
+	for _, part := range parts {
+		if len(part) == 0 {
+			return ImagePlatform{}, fmt.Errorf("the platform %q must be of the form os/architecture[/variant]", platform)
+		}
+	}
+	p := ImagePlatform{OS: parts[0], Architecture: parts[1]}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// Matches returns true if other runs on the platform. A platform without a variant matches every variant.
+func (p ImagePlatform) Matches(other ImagePlatform) bool {
+	return p.OS == other.OS && p.Architecture == other.Architecture && (len(p.Variant) == 0 || p.Variant == other.Variant)
+}
+
+func (p ImagePlatform) String() string {
+	if len(p.Variant) > 0 {
+		return p.OS + "/" + p.Architecture + "/" + p.Variant
+	}
+	return p.OS + "/" + p.Architecture
+}
+
 // SplitImageStreamTag turns the name of an ImageStreamTag into Name and Tag.
 // It returns false if the tag was not properly specified in the name.
 func SplitImageStreamTag(nameAndTag string) (name string, tag string, ok bool) {
@@ -380,6 +454,7 @@ func ImageWithMetadata(image *Image) error {
 		image.DockerImageMetadata.Author = v1Metadata.Author
 		image.DockerImageMetadata.Config = v1Metadata.Config
 		image.DockerImageMetadata.Architecture = v1Metadata.Architecture
+		image.DockerImageMetadata.OS = v1Metadata.OS
 		if len(image.DockerImageLayers) > 0 {
 			size := int64(0)
 			for _, layer := range image.DockerImageLayers {
@@ -390,8 +465,16 @@ func ImageWithMetadata(image *Image) error {
 			image.DockerImageMetadata.Size = v1Metadata.Size
 		}
 	case 2:
-		// TODO: need to prepare for this
-		return fmt.Errorf("unrecognized Docker image manifest schema %d for %q (%s)", manifest.SchemaVersion, image.Name, image.DockerImageReference)
+		// the metadata of the image is in its configuration, which the importer reads when it retrieves the
+		// manifest, so only the layers are taken from the manifest
+		image.DockerImageLayers = make([]ImageLayer, len(manifest.Layers))
+		size := int64(0)
+		for i, layer := range manifest.Layers {
+			image.DockerImageLayers[i].Name = layer.Digest.String()
+			image.DockerImageLayers[i].Size = layer.Size
+			size += layer.Size
+		}
+		image.DockerImageMetadata.Size = size
 	default:
 		return fmt.Errorf("unrecognized Docker image manifest schema %d for %q (%s)", manifest.SchemaVersion, image.Name, image.DockerImageReference)
 	}
@@ -473,6 +556,47 @@ func DifferentTagEvent(stream *ImageStream, tag string, next TagEvent) bool {
 	return !(sameRef && sameImage)
 }
 
+// ImageStreamTagChanges lists the tags that differ between two versions of an image stream.
+type ImageStreamTagChanges struct {
+	// Added are tags that only exist in the updated stream.
+	Added []string `json:"added,omitempty"`
+	// Updated are tags whose latest image differs between the two streams.
+	Updated []string `json:"updated,omitempty"`
+	// Removed are tags that only exist in the original stream.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Empty returns true if no tags were changed.
+func (c ImageStreamTagChanges) Empty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+// CompareImageStreamTags returns the tags whose latest tag event was added, updated or removed between
+// original and updated. Tags are returned in sorted order.
+func CompareImageStreamTags(original, updated *ImageStream) ImageStreamTagChanges {
+	changes := ImageStreamTagChanges{}
+	for tag := range updated.Status.Tags {
+		next := LatestTaggedImage(updated, tag)
+		previous := LatestTaggedImage(original, tag)
+		switch {
+		case next == nil:
+		case previous == nil:
+			changes.Added = append(changes.Added, tag)
+		case previous.Image != next.Image || previous.DockerImageReference != next.DockerImageReference:
+			changes.Updated = append(changes.Updated, tag)
+		}
+	}
+	for tag := range original.Status.Tags {
+		if LatestTaggedImage(original, tag) != nil && LatestTaggedImage(updated, tag) == nil {
+			changes.Removed = append(changes.Removed, tag)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Updated)
+	sort.Strings(changes.Removed)
+	return changes
+}
+
 // AddTagEventToImageStream attempts to update the given image stream with a tag event. It will
 // collapse duplicate entries - returning true if a change was made or false if no change
 // occurred. Any successful tag resets the status field.
@@ -184,6 +184,24 @@ func TestParseDockerImageReference(t *testing.T) {
 			From: "bar/foo/baz/biz",
 			Err:  true,
 		},
+		{
+			From:      "artifactory.example.com/docker-virtual/team/app:1.0",
+			Registry:  "artifactory.example.com",
+			Namespace: "docker-virtual/team",
+			Name:      "app",
+			Tag:       "1.0",
+		},
+		{
+			From:      "nexus:8443/repository/docker-hosted/team/app@sha256:3c87c572822935df60f0f5d3665bd376841a7fcfeb806b5f212de6a00e9a7b25",
+			Registry:  "nexus:8443",
+			Namespace: "repository/docker-hosted/team",
+			Name:      "app",
+			ID:        "sha256:3c87c572822935df60f0f5d3665bd376841a7fcfeb806b5f212de6a00e9a7b25",
+		},
+		{
+			From: "artifactory.example.com/docker-virtual//app",
+			Err:  true,
+		},
 		{
 			From: "ftp://baz/baz/biz",
 			Err:  true,
@@ -554,6 +572,17 @@ func TestImageWithMetadata(t *testing.T) {
 			},
 			expectError: true,
 		},
+		"schema2 layers": {
+			image: Image{
+				DockerImageManifest: `{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json", "config": {"digest": "sha256:c0"}, "layers": [{"digest": "sha256:a1", "size": 10}, {"digest": "sha256:b2", "size": 5}]}`,
+				DockerImageMetadata: DockerImage{ID: "sha256:d3", Architecture: "arm64"},
+			},
+			expectedImage: Image{
+				DockerImageManifest: `{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json", "config": {"digest": "sha256:c0"}, "layers": [{"digest": "sha256:a1", "size": 10}, {"digest": "sha256:b2", "size": 5}]}`,
+				DockerImageLayers:   []ImageLayer{{Name: "sha256:a1", Size: 10}, {Name: "sha256:b2", Size: 5}},
+				DockerImageMetadata: DockerImage{ID: "sha256:d3", Architecture: "arm64", Size: 15},
+			},
+		},
 		"happy path": {
 			image: validImageWithManifestData(),
 			expectedImage: Image{
@@ -628,6 +657,7 @@ func TestImageWithMetadata(t *testing.T) {
 						OnBuild:         []string{},
 					},
 					Architecture: "amd64",
+					OS:           "linux",
 					Size:         188294133,
 				},
 			},
@@ -1046,6 +1076,68 @@ func TestJoinImageStreamTag(t *testing.T) {
 	}
 }
 
+func TestNormalizeRegistryHost(t *testing.T) {
+	tests := map[string]string{
+		"":                          "",
+		"registry.example.com":      "registry.example.com",
+		"Registry.Example.com:443":  "registry.example.com",
+		"registry.example.com:5000": "registry.example.com:5000",
+		"[::1]:443":                 "[::1]",
+		"[::1]:5000":                "[::1]:5000",
+	}
+	for host, expected := range tests {
+		if actual := NormalizeRegistryHost(host); actual != expected {
+			t.Errorf("%s: unexpected host: %s", host, actual)
+		}
+	}
+	ref, err := ParseDockerImageReference("registry.example.com:443/foo/bar:latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := ref.RegistryURL(); u.Host != "registry.example.com" {
+		t.Errorf("unexpected registry url: %s", u)
+	}
+	if !IsRegistryDockerHub("docker.io:443") || IsRegistryDockerHub("docker.io:5000") {
+		t.Errorf("unexpected Docker Hub registries")
+	}
+}
+
+func TestParseImagePlatform(t *testing.T) {
+	tests := map[string]struct {
+		platform ImagePlatform
+		err      bool
+	}{
+		"linux/amd64":    {platform: ImagePlatform{OS: "linux", Architecture: "amd64"}},
+		"linux/arm64/v8": {platform: ImagePlatform{OS: "linux", Architecture: "arm64", Variant: "v8"}},
+		"":               {err: true},
+		"amd64":          {err: true},
+		"linux//v8":      {err: true},
+		"linux/arm/v7/1": {err: true},
+	}
+	for s, test := range tests {
+		platform, err := ParseImagePlatform(s)
+		if (err != nil) != test.err {
+			t.Errorf("%s: unexpected error: %v", s, err)
+			continue
+		}
+		if platform != test.platform {
+			t.Errorf("%s: unexpected platform: %#v", s, platform)
+		}
+		if err == nil && platform.String() != s {
+			t.Errorf("%s: unexpected string: %s", s, platform)
+		}
+	}
+
+	arm := ImagePlatform{OS: "linux", Architecture: "arm"}
+	if !arm.Matches(ImagePlatform{OS: "linux", Architecture: "arm", Variant: "v7"}) {
+		t.Errorf("expected a platform without a variant to match every variant")
+	}
+	v6 := ImagePlatform{OS: "linux", Architecture: "arm", Variant: "v6"}
+	if v6.Matches(ImagePlatform{OS: "linux", Architecture: "arm", Variant: "v7"}) || v6.Matches(arm) {
+		t.Errorf("expected a platform with a variant to match only that variant")
+	}
+}
+
 func TestResolveImageID(t *testing.T) {
 	tests := map[string]struct {
 		tags     map[string]TagEventList
@@ -1302,3 +1394,33 @@ func TestPrioritizeTags(t *testing.T) {
 		t.Errorf("unexpected order: %v", tags)
 	}
 }
+
+func TestCompareImageStreamTags(t *testing.T) {
+	original := &ImageStream{
+		Status: ImageStreamStatus{
+			Tags: map[string]TagEventList{
+				"same":    {Items: []TagEvent{{Image: "a", DockerImageReference: "r/a"}}},
+				"changed": {Items: []TagEvent{{Image: "a", DockerImageReference: "r/a"}}},
+				"removed": {Items: []TagEvent{{Image: "a", DockerImageReference: "r/a"}}},
+			},
+		},
+	}
+	updated := &ImageStream{
+		Status: ImageStreamStatus{
+			Tags: map[string]TagEventList{
+				"same":    {Items: []TagEvent{{Image: "a", DockerImageReference: "r/a"}}},
+				"changed": {Items: []TagEvent{{Image: "b", DockerImageReference: "r/b"}, {Image: "a", DockerImageReference: "r/a"}}},
+				"added":   {Items: []TagEvent{{Image: "c", DockerImageReference: "r/c"}}},
+				"empty":   {},
+			},
+		},
+	}
+	changes := CompareImageStreamTags(original, updated)
+	expected := ImageStreamTagChanges{Added: []string{"added"}, Updated: []string{"changed"}, Removed: []string{"removed"}}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes: %#v", changes)
+	}
+	if !CompareImageStreamTags(original, original).Empty() {
+		t.Errorf("expected no changes")
+	}
+}
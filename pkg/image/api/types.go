@@ -28,6 +28,41 @@ const (
 	// ExcludeImageSecretAnnotation indicates that a secret should not be returned by imagestream/secrets.
 	ExcludeImageSecretAnnotation = "openshift.io/image.excludeSecret"
 
+	// MirrorImportAnnotation may be set true on an image stream or an image stream import to copy imported
+	// images into the integrated registry and point the imported tags at the mirrored copy.
+	MirrorImportAnnotation = "openshift.io/image.mirror"
+
+	// ImportDryRunAnnotation may be set true on an image stream import to calculate the changes the import would
+	// make to the image stream without persisting them. The resulting stream is returned in status.import.
+	ImportDryRunAnnotation = "openshift.io/image.dryRun"
+
+	// ImportDryRunChangesAnnotation is set by the server on the result of a dry run import and contains a JSON
+	// serialized ImageStreamTagChanges describing the tags that would be added, updated, or removed.
+	ImportDryRunChangesAnnotation = "openshift.io/image.dryRunChanges"
+
+	// ImageLegacyDigestsAnnotation is set on images imported from schema1 manifests to the comma separated
+	// digests, other than the name of the image, that the manifest is known by. The name of such an image is the
+	// digest of the manifest without its signatures, while registries may identify it by the digest of the signed
+	// manifest.
+	ImageLegacyDigestsAnnotation = "openshift.io/image.legacyDigests"
+
+	// ImageManifestListAnnotation is set on images imported from a manifest list or an image index to the digest
+	// of the list, which is what the imported tag pointed to.
+	ImageManifestListAnnotation = "openshift.io/image.manifestList"
+
+	// ImagePlatformsAnnotation is set on images imported from a manifest list or an image index to the comma
+	// separated platforms, in the os/architecture[/variant] form, that the list has images for.
+	ImagePlatformsAnnotation = "openshift.io/image.platforms"
+
+	// ImagePlatformAnnotation is set on an image stream to the platform, in the os/architecture[/variant] form, of
+	// the images imported from manifest lists and image indexes for its DockerImageRepository. Tags name their
+	// platform in their import policy instead.
+	ImagePlatformAnnotation = "openshift.io/image.platform"
+
+	// DefaultImagePlatform is the platform of the images imported from manifest lists and image indexes when the
+	// import policy and the server configuration do not name one.
+	DefaultImagePlatform = "linux/amd64"
+
 	// DefaultImageTag is used when an image tag is needed and the configuration does not specify a tag to use.
 	DefaultImageTag = "latest"
 )
@@ -116,6 +151,9 @@ type TagImportPolicy struct {
 	Insecure bool
 	// Scheduled indicates to the server that this tag should be periodically checked to ensure it is up to date, and imported
 	Scheduled bool
+	// Platform is the os/architecture[/variant] of the image imported when the tag points to a manifest list or
+	// an image index, such as linux/arm64/v8. The server default is used when it is empty.
+	Platform string
 }
 
 // ImageStreamStatus contains information about the state of this image stream.
@@ -145,6 +183,18 @@ type TagEvent struct {
 	Image string
 	// Generation is the spec tag generation that resulted in this tag being updated
 	Generation int64
+	// ImportSource describes where and how the image was imported, if it was imported from a registry
+	ImportSource *TagImportSource
+}
+
+// TagImportSource records the provenance of an image imported into an image stream tag.
+type TagImportSource struct {
+	// Registry is the registry endpoint the image was retrieved from
+	Registry string
+	// Secret is the name of the secret whose credentials the registry accepted, if credentials were needed
+	Secret string
+	// Retrieved is when the image was retrieved from the registry
+	Retrieved unversioned.Time
 }
 
 type TagEventConditionType string
@@ -230,7 +280,9 @@ type ImageStreamImage struct {
 
 // DockerImageReference points to a Docker image.
 type DockerImageReference struct {
-	Registry  string
+	Registry string
+	// Namespace holds every path segment between the registry and the name, which is more than one segment for
+	// registries that serve repositories under a path.
 	Namespace string
 	Name      string
 	Tag       string
@@ -291,6 +343,9 @@ type RepositoryImportStatus struct {
 	// AdditionalTags are tags that exist in the repository but were not imported because
 	// a maximum limit of automatic imports was applied.
 	AdditionalTags []string
+	// TagsTruncated is true if the repository has more tags than the importer lists, so that the tags
+	// beyond the limit are neither imported nor named in AdditionalTags.
+	TagsTruncated bool
 }
 
 // ImageImportSpec defines how an image is imported.
@@ -307,4 +362,6 @@ type ImageImportStatus struct {
 	Tag    string
 	Status unversioned.Status
 	Image  *Image
+	// ImportSource describes where and how the image was retrieved, if it was
+	ImportSource *TagImportSource
 }
@@ -92,6 +92,9 @@ type TagImportPolicy struct {
 	Insecure bool `json:"insecure,omitempty" description:"if true, the server may bypass certificate verification or connect directly over HTTP during image import"`
 	// Scheduled indicates to the server that this tag should be periodically checked to ensure it is up to date, and imported
 	Scheduled bool `json:"scheduled,omitempty" description:"if true, the server will periodically check to ensure this tag is up to date"`
+	// Platform is the os/architecture[/variant] of the image imported when the tag points to a manifest list or
+	// an image index, such as linux/arm64/v8. The server default is used when it is empty.
+	Platform string `json:"platform,omitempty" description:"os/architecture[/variant] of the image imported from a manifest list or image index; defaults to the platform configured for the server"`
 }
 
 // ImageStreamStatus contains information about the state of this image stream.
@@ -122,6 +125,18 @@ type TagEvent struct {
 	Image string `json:"image" description:"the image"`
 	// Generation is the spec tag generation that resulted in this tag being updated
 	Generation int64 `json:"generation" description:"the generation of the image stream spec tag this tag event represents"`
+	// ImportSource describes where and how the image was imported, if it was imported from a registry
+	ImportSource *TagImportSource `json:"importSource,omitempty" description:"where and how the image was imported, if it was imported from a registry"`
+}
+
+// TagImportSource records the provenance of an image imported into an image stream tag.
+type TagImportSource struct {
+	// Registry is the registry endpoint the image was retrieved from
+	Registry string `json:"registry" description:"the registry endpoint the image was retrieved from"`
+	// Secret is the name of the secret whose credentials the registry accepted, if credentials were needed
+	Secret string `json:"secret,omitempty" description:"the name of the secret whose credentials the registry accepted, if credentials were needed"`
+	// Retrieved is when the image was retrieved from the registry
+	Retrieved unversioned.Time `json:"retrieved" description:"when the image was retrieved from the registry"`
 }
 
 type TagEventConditionType string
@@ -256,6 +271,9 @@ type RepositoryImportStatus struct {
 	// AdditionalTags are tags that exist in the repository but were not imported because
 	// a maximum limit of automatic imports was applied.
 	AdditionalTags []string `json:"additionalTags,omitempty" description:"a list of additional tags on the repository that were not retrieved"`
+	// TagsTruncated is true if the repository has more tags than the importer lists, so that the tags
+	// beyond the limit are neither imported nor named in AdditionalTags.
+	TagsTruncated bool `json:"tagsTruncated,omitempty" description:"true if the repository has more tags than were listed, the tags beyond the listing limit are not reported"`
 }
 
 // ImageImportSpec describes a request to import a specific image.
@@ -272,4 +290,6 @@ type ImageImportStatus struct {
 	Status unversioned.Status `json:"status" description:"the status of the image import, including errors encountered while retrieving the image"`
 	Image  *Image             `json:"image,omitempty" description:"if the image was located, the metadata of that image"`
 	Tag    string             `json:"tag,omitempty" description:"the tag this image was located under, if any"`
+	// ImportSource describes where and how the image was retrieved, if it was
+	ImportSource *TagImportSource `json:"importSource,omitempty" description:"where and how the image was retrieved, if it was"`
 }
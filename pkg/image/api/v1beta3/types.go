@@ -87,6 +87,9 @@ type TagImportPolicy struct {
 	Insecure bool `json:"insecure,omitempty" description:"if true, the server may bypass certificate verification or connect directly over HTTP during image import"`
 	// Scheduled indicates to the server that this tag should be periodically checked to ensure it is up to date, and imported
 	Scheduled bool `json:"scheduled,omitempty" description:"if true, the server will periodically check to ensure this tag is up to date"`
+	// Platform is the os/architecture[/variant] of the image imported when the tag points to a manifest list or
+	// an image index, such as linux/arm64/v8. The server default is used when it is empty.
+	Platform string `json:"platform,omitempty" description:"os/architecture[/variant] of the image imported from a manifest list or image index; defaults to the platform configured for the server"`
 }
 
 // ImageStreamStatus contains information about the state of this image stream.
@@ -117,6 +120,18 @@ type TagEvent struct {
 	Image string `json:"image" description:"the image"`
 	// Generation is the spec tag generation that resulted in this tag being updated
 	Generation int64 `json:"generation" description:"the generation of the image stream spec tag this tag event represents"`
+	// ImportSource describes where and how the image was imported, if it was imported from a registry
+	ImportSource *TagImportSource `json:"importSource,omitempty" description:"where and how the image was imported, if it was imported from a registry"`
+}
+
+// TagImportSource records the provenance of an image imported into an image stream tag.
+type TagImportSource struct {
+	// Registry is the registry endpoint the image was retrieved from
+	Registry string `json:"registry" description:"the registry endpoint the image was retrieved from"`
+	// Secret is the name of the secret whose credentials the registry accepted, if credentials were needed
+	Secret string `json:"secret,omitempty" description:"the name of the secret whose credentials the registry accepted, if credentials were needed"`
+	// Retrieved is when the image was retrieved from the registry
+	Retrieved unversioned.Time `json:"retrieved" description:"when the image was retrieved from the registry"`
 }
 
 type TagEventConditionType string
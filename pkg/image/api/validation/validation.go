@@ -109,6 +109,7 @@ func ValidateImageStream(stream *api.ImageStream) field.ErrorList {
 // ValidateImageStreamTagReference ensures that a given tag reference is valid.
 func ValidateImageStreamTagReference(tagRef api.TagReference, fldPath *field.Path) field.ErrorList {
 	var errs field.ErrorList
+	errs = append(errs, validateImportPolicy(tagRef.ImportPolicy, fldPath.Child("importPolicy"))...)
 	if tagRef.From != nil {
 		if len(tagRef.From.Name) == 0 {
 			errs = append(errs, field.Required(fldPath.Child("from", "name"), "name is required"))
@@ -129,6 +130,16 @@ func ValidateImageStreamTagReference(tagRef api.TagReference, fldPath *field.Pat
 	return errs
 }
 
+func validateImportPolicy(policy api.TagImportPolicy, fldPath *field.Path) field.ErrorList {
+	if len(policy.Platform) == 0 {
+		return nil
+	}
+	if _, err := api.ParseImagePlatform(policy.Platform); err != nil {
+		return field.ErrorList{field.Invalid(fldPath.Child("platform"), policy.Platform, err.Error())}
+	}
+	return nil
+}
+
 func ValidateImageStreamUpdate(newStream, oldStream *api.ImageStream) field.ErrorList {
 	result := validation.ValidateObjectMetaUpdate(&newStream.ObjectMeta, &oldStream.ObjectMeta, field.NewPath("metadata"))
 	result = append(result, ValidateImageStream(newStream)...)
@@ -216,6 +227,7 @@ func ValidateImageStreamImport(isi *api.ImageStreamImport) field.ErrorList {
 
 	errs := field.ErrorList{}
 	for i, spec := range isi.Spec.Images {
+		errs = append(errs, validateImportPolicy(spec.ImportPolicy, imagesPath.Index(i).Child("importPolicy"))...)
 		from := spec.From
 		switch from.Kind {
 		case "DockerImage":
@@ -239,6 +251,7 @@ func ValidateImageStreamImport(isi *api.ImageStreamImport) field.ErrorList {
 	}
 
 	if spec := isi.Spec.Repository; spec != nil {
+		errs = append(errs, validateImportPolicy(spec.ImportPolicy, repoPath.Child("importPolicy"))...)
 		from := spec.From
 		switch from.Kind {
 		case "DockerImage":
@@ -599,6 +599,30 @@ func TestValidateImageStreamImport(t *testing.T) {
 				field.Invalid(field.NewPath("spec", "images").Index(3).Child("from", "kind"), "ImageStreamImage", "only DockerImage is supported"),
 			},
 		},
+		"invalid platform": {
+			isi: &api.ImageStreamImport{
+				ObjectMeta: validMeta, Spec: api.ImageStreamImportSpec{
+					Images: []api.ImageImportSpec{
+						{
+							From:         kapi.ObjectReference{Kind: "DockerImage", Name: "abc"},
+							ImportPolicy: api.TagImportPolicy{Platform: "linux/arm64/v8"},
+						},
+						{
+							From:         kapi.ObjectReference{Kind: "DockerImage", Name: "abc"},
+							ImportPolicy: api.TagImportPolicy{Platform: "amd64"},
+						},
+					},
+					Repository: &api.RepositoryImportSpec{
+						From:         kapi.ObjectReference{Kind: "DockerImage", Name: "abc"},
+						ImportPolicy: api.TagImportPolicy{Platform: "linux/"},
+					},
+				},
+			},
+			expected: field.ErrorList{
+				field.Invalid(field.NewPath("spec", "images").Index(1).Child("importPolicy", "platform"), "amd64", `the platform "amd64" must be of the form os/architecture[/variant]`),
+				field.Invalid(field.NewPath("spec", "repository", "importPolicy", "platform"), "linux/", `the platform "linux/" must be of the form os/architecture[/variant]`),
+			},
+		},
 		"valid": {
 			namespace: "namespace",
 			name:      "foo",
@@ -156,9 +156,10 @@ func (c *ImportController) Next(stream *api.ImageStream, notifier Notifier) erro
 	}
 	if repo := stream.Spec.DockerImageRepository; !partial && len(repo) > 0 {
 		insecure := stream.Annotations[api.InsecureRepositoryAnnotation] == "true"
+		platform := stream.Annotations[api.ImagePlatformAnnotation]
 		isi.Spec.Repository = &api.RepositoryImportSpec{
 			From:         kapi.ObjectReference{Kind: "DockerImage", Name: repo},
-			ImportPolicy: api.TagImportPolicy{Insecure: insecure},
+			ImportPolicy: api.TagImportPolicy{Insecure: insecure, Platform: platform},
 		}
 	}
 	result, err := c.streams.ImageStreams(stream.Namespace).Import(isi)
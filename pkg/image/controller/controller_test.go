@@ -8,6 +8,7 @@ import (
 	kapi "k8s.io/kubernetes/pkg/api"
 	apierrs "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	ktestclient "k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/util"
 
 	client "github.com/openshift/origin/pkg/client/testclient"
@@ -328,6 +329,33 @@ func TestControllerExternalRepo(t *testing.T) {
 	}
 }
 
+func TestControllerRepositoryPlatform(t *testing.T) {
+	fake := &client.Fake{}
+	c := ImportController{streams: fake}
+
+	stream := api.ImageStream{
+		ObjectMeta: kapi.ObjectMeta{
+			Name:        "test",
+			Namespace:   "other",
+			Annotations: map[string]string{api.ImagePlatformAnnotation: "linux/arm64"},
+		},
+		Spec: api.ImageStreamSpec{
+			DockerImageRepository: "some/repo",
+		},
+	}
+	if err := c.Next(&stream, nil); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	actions := fake.Actions()
+	if len(actions) != 1 || !actions[0].Matches("create", "imagestreamimports") {
+		t.Fatalf("expected a create action: %#v", actions)
+	}
+	isi := actions[0].(ktestclient.CreateAction).GetObject().(*api.ImageStreamImport)
+	if isi.Spec.Repository == nil || isi.Spec.Repository.ImportPolicy.Platform != "linux/arm64" {
+		t.Errorf("unexpected repository import: %#v", isi.Spec.Repository)
+	}
+}
+
 func TestScheduledImport(t *testing.T) {
 	fake := &client.Fake{}
 	b := newScheduled(true, fake, 1, nil, nil)
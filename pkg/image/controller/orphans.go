@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/golang/glog"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util"
+
+	"github.com/openshift/origin/pkg/client"
+	"github.com/openshift/origin/pkg/controller"
+	"github.com/openshift/origin/pkg/image/prune"
+)
+
+// OrphanedImageControllerFactory can create a controller that periodically deletes imported images that are
+// no longer referenced by any image stream.
+type OrphanedImageControllerFactory struct {
+	Client client.Interface
+	// Interval is the time between two passes of the controller.
+	Interval time.Duration
+	// KeepYoungerThan, BatchSize, BatchInterval and RateLimiter are passed to the pruner on each pass.
+	KeepYoungerThan time.Duration
+	BatchSize       int
+	BatchInterval   time.Duration
+	RateLimiter     util.RateLimiter
+}
+
+// Create creates a controller that prunes orphaned images every Interval.
+func (f *OrphanedImageControllerFactory) Create() controller.RunnableController {
+	return &orphanedImageController{
+		client:  f.Client,
+		pruner:  prune.NewDeletingImagePruner(f.Client.Images()),
+		factory: *f,
+	}
+}
+
+type orphanedImageController struct {
+	client  client.Interface
+	pruner  prune.ImagePruner
+	factory OrphanedImageControllerFactory
+}
+
+// Run starts the controller loop in the background.
+func (c *orphanedImageController) Run() {
+	go util.Until(func() {
+		if err := c.prune(); err != nil {
+			util.HandleError(err)
+		}
+	}, c.factory.Interval, util.NeverStop)
+}
+
+// prune performs a single pass over all images and image streams.
+func (c *orphanedImageController) prune() error {
+	images, err := c.client.Images().List(kapi.ListOptions{})
+	if err != nil {
+		return err
+	}
+	streams, err := c.client.ImageStreams(kapi.NamespaceAll).List(kapi.ListOptions{})
+	if err != nil {
+		return err
+	}
+	glog.V(4).Infof("Checking %d images for references from %d image streams", len(images.Items), len(streams.Items))
+	return prune.PruneOrphanedImages(prune.OrphanedImagePrunerOptions{
+		KeepYoungerThan: c.factory.KeepYoungerThan,
+		BatchSize:       c.factory.BatchSize,
+		BatchInterval:   c.factory.BatchInterval,
+		RateLimiter:     c.factory.RateLimiter,
+		Images:          images,
+		Streams:         streams,
+	}, c.pruner)
+}
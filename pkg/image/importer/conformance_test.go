@@ -0,0 +1,231 @@
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution/registry/client/auth"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+
+	"github.com/openshift/origin/pkg/image/api"
+	registrytest "github.com/openshift/origin/pkg/image/importer/test"
+)
+
+// importFrom imports the named images from registry with credentials, returning the status of each.
+func importFrom(t *testing.T, registry *registrytest.Registry, credentials auth.CredentialStore, names ...string) []api.ImageImportStatus {
+	retriever := NewContext(registry.Transport(), registry.Transport()).WithCredentials(credentials)
+	isi := &api.ImageStreamImport{}
+	for _, name := range names {
+		isi.Spec.Images = append(isi.Spec.Images, api.ImageImportSpec{
+			From: kapi.ObjectReference{Kind: "DockerImage", Name: registry.Host() + "/" + name},
+		})
+	}
+	if err := NewImageStreamImporter(retriever, 5, nil).Import(gocontext.Background(), isi); err != nil {
+		t.Fatal(err)
+	}
+	return isi.Status.Images
+}
+
+func addSchema1(t *testing.T, registry *registrytest.Registry, name, tag string) registrytest.Manifest {
+	m, err := registrytest.Schema1Manifest(name, tag)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.AddManifest(name, tag, m)
+	return m
+}
+
+func dockercfgSecret(name, host, username, password string) kapi.Secret {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	return kapi.Secret{
+		ObjectMeta: kapi.ObjectMeta{Name: name},
+		Type:       kapi.SecretTypeDockercfg,
+		Data: map[string][]byte{
+			kapi.DockerConfigKey: []byte(fmt.Sprintf(`{%q:{"auth":%q,"email":"test@example.com"}}`, host, auth)),
+		},
+	}
+}
+
+func TestConformanceManifestFormats(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.Start()
+	defer registry.Close()
+
+	schema1 := addSchema1(t, registry, "test/schema1", "latest")
+	schema2 := registrytest.Schema2Manifest("test/schema2", "latest")
+	converted := addSchema1(t, registry, "test/schema2", "")
+	schema2.Schema1 = &converted
+	registry.AddManifest("test/schema2", "latest", schema2)
+	oci := registrytest.OCIManifest("test/oci", "latest")
+	registry.AddManifest("test/oci", "latest", oci)
+
+	statuses := importFrom(t, registry, NoCredentials,
+		"test/schema1:latest",
+		"test/schema1@"+schema1.Digest.String(),
+		"test/schema2:latest",
+		"test/oci:latest",
+		"test/schema1:missing",
+	)
+	for i := 0; i < 2; i++ {
+		status := statuses[i]
+		if status.Status.Status != unversioned.StatusSuccess || status.Image == nil {
+			t.Fatalf("%d: unexpected status: %#v", i, status.Status)
+		}
+		if status.Image.Name != schema1.Digest.String() || len(status.Image.DockerImageLayers) != 1 || status.Image.DockerImageMetadata.Size != 1024 {
+			t.Errorf("%d: unexpected image: %#v", i, status.Image)
+		}
+	}
+	// schema2 and OCI manifests are preferred to the schema1 manifests registries convert them to
+	for i, m := range map[int]registrytest.Manifest{2: schema2, 3: oci} {
+		status := statuses[i]
+		if status.Status.Status != unversioned.StatusSuccess || status.Image == nil {
+			t.Fatalf("%d: unexpected status: %#v", i, status.Status)
+		}
+		if status.Image.Name != m.Digest.String() || status.Image.DockerImageManifest != string(m.Content) {
+			t.Errorf("%d: unexpected image: %#v", i, status.Image)
+		}
+	}
+	if status := statuses[4]; status.Status.Reason != unversioned.StatusReasonNotFound {
+		t.Errorf("unexpected status: %#v", status.Status)
+	}
+}
+
+func TestConformanceManifestList(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.Start()
+	defer registry.Close()
+
+	image := registrytest.Schema2Manifest("test/list", "amd64")
+	registry.AddManifest("test/list", "", image)
+	list := registrytest.ManifestList(image)
+	registry.AddManifest("test/list", "latest", list)
+	// a single platform image is imported as pushed rather than converted to schema1
+	single := registrytest.Schema2Manifest("test/single", "latest")
+	converted := addSchema1(t, registry, "test/single", "")
+	single.Schema1 = &converted
+	registry.AddManifest("test/single", "latest", single)
+
+	statuses := importFrom(t, registry, NoCredentials, "test/list:latest", "test/single:latest")
+	status := statuses[0]
+	if status.Status.Status != unversioned.StatusSuccess || status.Image == nil {
+		t.Fatalf("unexpected status: %#v", status.Status)
+	}
+	if status.Image.Name != image.Digest.String() || status.Image.DockerImageManifest != string(image.Content) {
+		t.Errorf("expected the image of the platform to be imported: %#v", status.Image)
+	}
+	if status.Image.Annotations[api.ImageManifestListAnnotation] != list.Digest.String() || status.Image.Annotations[api.ImagePlatformsAnnotation] != "linux/amd64" {
+		t.Errorf("unexpected annotations: %v", status.Image.Annotations)
+	}
+	status = statuses[1]
+	if status.Status.Status != unversioned.StatusSuccess || status.Image == nil {
+		t.Fatalf("unexpected status: %#v", status.Status)
+	}
+	if status.Image.Name != single.Digest.String() || len(status.Image.Annotations[api.ImageManifestListAnnotation]) > 0 {
+		t.Errorf("unexpected image: %#v", status.Image)
+	}
+}
+
+func TestConformanceRepository(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.Start()
+	defer registry.Close()
+	for _, tag := range []string{"v1", "v2", "latest"} {
+		addSchema1(t, registry, "test/repo", tag)
+	}
+
+	retriever := NewContext(registry.Transport(), registry.Transport()).WithCredentials(NoCredentials)
+	isi := &api.ImageStreamImport{
+		Spec: api.ImageStreamImportSpec{
+			Repository: &api.RepositoryImportSpec{
+				From: kapi.ObjectReference{Kind: "DockerImage", Name: registry.Host() + "/test/repo"},
+			},
+		},
+	}
+	if err := NewImageStreamImporter(retriever, 2, nil).Import(gocontext.Background(), isi); err != nil {
+		t.Fatal(err)
+	}
+	status := isi.Status.Repository
+	if status.Status.Status != unversioned.StatusSuccess || len(status.Images) != 2 || len(status.AdditionalTags) != 1 {
+		t.Errorf("unexpected repository status: %#v", status)
+	}
+}
+
+func TestConformanceAuth(t *testing.T) {
+	for _, mode := range []registrytest.Auth{registrytest.AuthBasic, registrytest.AuthToken} {
+		registry := registrytest.NewRegistry()
+		registry.Auth, registry.Username, registry.Password = mode, "user", "pass"
+		registry.Start()
+		addSchema1(t, registry, "test/private", "latest")
+
+		if status := importFrom(t, registry, NoCredentials, "test/private:latest")[0]; status.Status.Reason != unversioned.StatusReasonUnauthorized {
+			t.Errorf("%d: expected an unauthorized error without credentials: %#v", mode, status.Status)
+		}
+
+		basic := NewBasicCredentials()
+		basic.Add(&url.URL{Host: registry.Host()}, "user", "pass")
+		if status := importFrom(t, registry, basic, "test/private:latest")[0]; status.Status.Status != unversioned.StatusSuccess {
+			t.Errorf("%d: unexpected status with basic credentials: %#v", mode, status.Status)
+		}
+
+		secrets := NewCredentialsForSecrets([]kapi.Secret{
+			dockercfgSecret("other", "other.io", "user", "pass"),
+			dockercfgSecret("pull", "https://"+registry.Host(), "user", "pass"),
+		})
+		status := importFrom(t, registry, secrets, "test/private:latest")[0]
+		if status.Status.Status != unversioned.StatusSuccess {
+			t.Fatalf("%d: unexpected status with secrets: %#v", mode, status.Status)
+		}
+		if source := status.ImportSource; source == nil || source.Secret != "pull" {
+			t.Errorf("%d: unexpected import source: %#v", mode, source)
+		}
+		registry.Close()
+	}
+}
+
+func TestConformanceAnonymousSecret(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.Start()
+	defer registry.Close()
+	addSchema1(t, registry, "test/public", "latest")
+
+	// a secret for the registry is not recorded when the registry never asked for credentials
+	secrets := NewCredentialsForSecrets([]kapi.Secret{dockercfgSecret("pull", "https://"+registry.Host(), "user", "pass")})
+	status := importFrom(t, registry, secrets, "test/public:latest")[0]
+	if status.Status.Status != unversioned.StatusSuccess {
+		t.Fatalf("unexpected status: %#v", status.Status)
+	}
+	if source := status.ImportSource; source == nil || len(source.Secret) > 0 || source.Registry != "https://"+registry.Host() {
+		t.Errorf("unexpected import source: %#v", source)
+	}
+}
+
+func TestConformanceRateLimit(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.RateLimit = 1
+	registry.Start()
+	defer registry.Close()
+	addSchema1(t, registry, "test/limited", "latest")
+
+	if status := importFrom(t, registry, NoCredentials, "test/limited:latest")[0]; status.Status.Status != unversioned.StatusFailure {
+		t.Errorf("expected the rate limited import to fail: %#v", status.Status)
+	}
+	if status := importFrom(t, registry, NoCredentials, "test/limited:latest")[0]; status.Status.Status != unversioned.StatusSuccess {
+		t.Errorf("unexpected status after the rate limit: %#v", status.Status)
+	}
+	limited := 0
+	for _, request := range registry.Requests() {
+		if strings.HasPrefix(request, "GET /v2/test/limited/manifests/") {
+			limited++
+		}
+	}
+	if limited != 2 {
+		t.Errorf("unexpected requests: %v", registry.Requests())
+	}
+}
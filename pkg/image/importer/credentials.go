@@ -1,23 +1,50 @@
 package importer
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
 	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/golang/glog"
 
 	"github.com/docker/distribution/registry/client/auth"
+	docker "github.com/fsouza/go-dockerclient"
 
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/credentialprovider"
+
+	"github.com/openshift/origin/pkg/image/api"
 )
 
 var (
 	NoCredentials auth.CredentialStore = &noopCredentialStore{}
 
-	emptyKeyring = &credentialprovider.BasicDockerKeyring{}
+	emptyKeyring = &pathKeyring{}
+
+	// TokenUsernames are the usernames registries require alongside a token, keyed by the domain of the
+	// registry. They are used for credentials that carry a token as their password and no username.
+	TokenUsernames = map[string]string{
+		"gcr.io":     "oauth2accesstoken",
+		"pkg.dev":    "oauth2accesstoken",
+		"azurecr.io": "00000000-0000-0000-0000-000000000000",
+	}
 )
 
+// DefaultTokenUsername is sent with a token to registries not listed in TokenUsernames. Registries that
+// authenticate with tokens, such as GitLab with deploy and access tokens, accept any username that is not empty.
+const DefaultTokenUsername = "token"
+
 type noopCredentialStore struct{}
 
 func (s *noopCredentialStore) Basic(url *url.URL) (string, string) {
@@ -44,7 +71,7 @@ func (c *BasicCredentials) Add(url *url.URL, username, password string) {
 
 func (c *BasicCredentials) Basic(url *url.URL) (string, string) {
 	for _, cred := range c.creds {
-		if len(cred.url.Host) != 0 && cred.url.Host != url.Host {
+		if len(cred.url.Host) != 0 && api.NormalizeRegistryHost(cred.url.Host) != api.NormalizeRegistryHost(url.Host) {
 			continue
 		}
 		if len(cred.url.Path) != 0 && cred.url.Path != url.Path {
@@ -55,8 +82,214 @@ func (c *BasicCredentials) Basic(url *url.URL) (string, string) {
 	return "", ""
 }
 
+// NewRegistryCredentials returns a store that provides username and password only to the registry whose host
+// registry currently returns. The host is looked up on every request, so that it can follow a registry whose
+// address is resolved from a service.
+func NewRegistryCredentials(registry func() (string, bool), username, password string) auth.CredentialStore {
+	return &registryCredentialStore{registry: registry, username: username, password: password}
+}
+
+type registryCredentialStore struct {
+	registry           func() (string, bool)
+	username, password string
+}
+
+func (s *registryCredentialStore) Basic(url *url.URL) (string, string) {
+	host, ok := s.registry()
+	if !ok || len(host) == 0 || api.NormalizeRegistryHost(host) != api.NormalizeRegistryHost(url.Host) {
+		return "", ""
+	}
+	return s.username, s.password
+}
+
+// NewCompositeCredentials returns a credential store that consults stores in the order they are provided.
+func NewCompositeCredentials(stores ...auth.CredentialStore) CompositeCredentialStore {
+	return CompositeCredentialStore(stores)
+}
+
+// CompositeCredentialStore is a list of credential stores in order of precedence, such as explicit
+// BasicCredentials, the secrets of a namespace, the local keyring and the cloud providers it consults. It
+// returns the first credentials any of them provides, so that the importer and the searchers can share one
+// chain of stores.
+type CompositeCredentialStore []auth.CredentialStore
+
+func (s CompositeCredentialStore) Basic(url *url.URL) (string, string) {
+	for _, store := range s {
+		if store == nil {
+			continue
+		}
+		if username, password := store.Basic(url); len(username) > 0 || len(password) > 0 {
+			return username, password
+		}
+	}
+	return "", ""
+}
+
+// Err returns the first error reported by a store of the list that failed to load its credentials, such as a
+// SecretCredentialStore.
+func (s CompositeCredentialStore) Err() error {
+	for _, store := range s {
+		if errStore, ok := store.(interface {
+			Err() error
+		}); ok {
+			if err := errStore.Err(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ForRepository returns the list of stores with the stores that provide credentials by repository scoped to
+// repository on registry.
+func (s CompositeCredentialStore) ForRepository(registry *url.URL, repository string) auth.CredentialStore {
+	return s.ForRepositoryRealms(registry, repository, nil)
+}
+
+// ForRepositoryRealms returns the list of stores with the stores that provide credentials by repository scoped to
+// repository on registry, and to the token servers at realms if they restrict their credentials to them.
+func (s CompositeCredentialStore) ForRepositoryRealms(registry *url.URL, repository string, realms []*url.URL) auth.CredentialStore {
+	scoped := make(CompositeCredentialStore, 0, len(s))
+	for _, store := range s {
+		switch t := store.(type) {
+		case RealmCredentialStore:
+			store = t.ForRepositoryRealms(registry, repository, realms)
+		case RepositoryCredentialStore:
+			store = t.ForRepository(registry, repository)
+		}
+		scoped = append(scoped, store)
+	}
+	return scoped
+}
+
+// CredentialResolver returns the credentials that imports requested by namespace use for the registry or
+// token realm at url, for instance by reading the secrets of the namespace. An empty username and password
+// mean there are none.
+type CredentialResolver func(namespace string, url *url.URL) (username, password string, err error)
+
+// ResolvedCredentialsCacheDuration is how long the credentials ResolvedCredentials resolves are reused, so that
+// rotated secrets are picked up.
+var ResolvedCredentialsCacheDuration = 5 * time.Minute
+
+// NewResolvedCredentials returns a store for importers that serve many namespaces and choose the credentials
+// of each request with resolve instead of from a single list of secrets.
+func NewResolvedCredentials(resolve CredentialResolver) *ResolvedCredentials {
+	return &ResolvedCredentials{
+		resolve: resolve,
+		now:     time.Now,
+		cache:   make(map[string]*resolvedCredential),
+	}
+}
+
+// ResolvedCredentials caches the credentials resolved for each namespace and registry for
+// ResolvedCredentialsCacheDuration, so that resolve is called once for each of them however many images are
+// imported. Concurrent requests for the same namespace and registry wait for a single resolution, while
+// resolutions of different keys run in parallel. Failed resolutions are not cached.
+type ResolvedCredentials struct {
+	resolve CredentialResolver
+	now     func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]*resolvedCredential
+}
+
+// resolvedCredential is the result of a resolution, which may still be in progress until done is closed.
+type resolvedCredential struct {
+	done               chan struct{}
+	username, password string
+	err                error
+	expires            time.Time
+}
+
+// valid returns true if the resolution finished without an error before now and has not expired.
+func (c *resolvedCredential) valid(now time.Time) bool {
+	select {
+	case <-c.done:
+		return c.err == nil && now.Before(c.expires)
+	default:
+		return true
+	}
+}
+
+// ForNamespace returns the credential store for the imports requested by namespace.
+func (c *ResolvedCredentials) ForNamespace(namespace string) *NamespaceCredentialStore {
+	return &NamespaceCredentialStore{credentials: c, namespace: namespace}
+}
+
+func (c *ResolvedCredentials) basic(namespace string, target *url.URL) (string, string, error) {
+	key := namespace + "/" + api.NormalizeRegistryHost(target.Host) + target.Path
+	c.lock.Lock()
+	now := c.now()
+	cred, ok := c.cache[key]
+	if !ok || !cred.valid(now) {
+		// drop the expired credentials, so that the cache does not grow with every namespace ever served
+		for k, v := range c.cache {
+			if !v.valid(now) {
+				delete(c.cache, k)
+			}
+		}
+		cred = &resolvedCredential{done: make(chan struct{})}
+		c.cache[key] = cred
+		c.lock.Unlock()
+
+		// the lock is not held while resolving, which may take as long as reading the secrets of the namespace
+		cred.username, cred.password, cred.err = c.resolve(namespace, target)
+		cred.expires = c.now().Add(ResolvedCredentialsCacheDuration)
+		close(cred.done)
+		return cred.username, cred.password, cred.err
+	}
+	c.lock.Unlock()
+
+	<-cred.done
+	return cred.username, cred.password, cred.err
+}
+
+// NamespaceCredentialStore provides the credentials that ResolvedCredentials resolves for a namespace.
+type NamespaceCredentialStore struct {
+	credentials *ResolvedCredentials
+	namespace   string
+
+	lock sync.Mutex
+	err  error
+}
+
+func (s *NamespaceCredentialStore) Basic(url *url.URL) (string, string) {
+	username, password, err := s.credentials.basic(s.namespace, url)
+	if err != nil {
+		glog.V(5).Infof("Unable to resolve credentials of namespace %s for %s: %v", s.namespace, url, err)
+		s.lock.Lock()
+		s.err = err
+		s.lock.Unlock()
+	}
+	return username, password
+}
+
+// Err returns the last error resolving credentials for the namespace.
+func (s *NamespaceCredentialStore) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// NewLocalCredentials returns the credentials of the local Docker client: those its credential helpers provide
+// and those of its configuration file.
 func NewLocalCredentials() auth.CredentialStore {
-	return &keyringCredentialStore{credentialprovider.NewDockerKeyring()}
+	var configs []credentialprovider.DockerConfig
+	if config, err := credentialprovider.ReadDockerConfigFile(); err == nil {
+		configs = append(configs, normalizeDockerConfig(config))
+	} else if !os.IsNotExist(err) {
+		glog.V(2).Infof("Unable to read the credentials of the Docker client: %v", err)
+	}
+	keyring := &keyringCredentialStore{keyringForConfigs(configs)}
+	helpers, err := NewCredentialHelpersFromConfig(DockerConfigPath())
+	if err != nil {
+		glog.V(2).Infof("Unable to read the credential helpers of the Docker client: %v", err)
+		return keyring
+	}
+	if !helpers.Configured() {
+		return keyring
+	}
+	return NewCompositeCredentials(helpers, keyring)
 }
 
 type keyringCredentialStore struct {
@@ -67,6 +300,266 @@ func (s *keyringCredentialStore) Basic(url *url.URL) (string, string) {
 	return basicCredentialsFromKeyring(s.DockerKeyring, url)
 }
 
+// ForRepository returns a store that prefers the credentials the keyring holds for the path of repository on
+// registry, like the Docker client does for registries that serve repositories under a path, such as Artifactory
+// and Nexus.
+func (s *keyringCredentialStore) ForRepository(registry *url.URL, repository string) auth.CredentialStore {
+	return s.ForRepositoryRealms(registry, repository, nil)
+}
+
+// ForRepositoryRealms returns a store like ForRepository that also provides the credentials of the path of
+// repository to the token servers at realms.
+func (s *keyringCredentialStore) ForRepositoryRealms(registry *url.URL, repository string, realms []*url.URL) auth.CredentialStore {
+	return &keyringRepositoryStore{keyring: s.DockerKeyring, target: &url.URL{Host: registry.Host, Path: "/" + repository}, realms: realms}
+}
+
+// keyringRepositoryStore provides the credentials of a keyring for a single repository. The credentials of its
+// path are only provided to the registry and to the token servers it announced, since the token server of a
+// registry may be on another host, and other URLs get the credentials the keyring holds for them.
+type keyringRepositoryStore struct {
+	keyring credentialprovider.DockerKeyring
+	target  *url.URL
+	realms  []*url.URL
+}
+
+func (s *keyringRepositoryStore) Basic(url *url.URL) (string, string) {
+	if s.scoped(url) {
+		if username, password := basicCredentialsFromKeyring(s.keyring, s.target); len(username) > 0 || len(password) > 0 {
+			return username, password
+		}
+	}
+	return basicCredentialsFromKeyring(s.keyring, url)
+}
+
+// scoped returns true if url is on the registry of the repository or is one of the token realms of the registry.
+func (s *keyringRepositoryStore) scoped(url *url.URL) bool {
+	if api.NormalizeRegistryHost(url.Host) == api.NormalizeRegistryHost(s.target.Host) {
+		return true
+	}
+	for _, realm := range s.realms {
+		if realm.Host == url.Host && realm.Path == url.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// CredentialHelperCacheDuration is how long the credentials a credential helper returned are reused. Helpers such
+// as ecr-login and gcr return tokens that expire, so they are asked again once it has passed.
+var CredentialHelperCacheDuration = 5 * time.Minute
+
+// CredentialHelperTimeout is how long a credential helper may run before it is killed, so that a helper that hangs,
+// for instance waiting for a network that is down, does not hold up imports.
+var CredentialHelperTimeout = 10 * time.Second
+
+// credentialsNotFound is the output of a credential helper that has no credentials for a server.
+const credentialsNotFound = "credentials not found in native keychain"
+
+// DockerConfigPath returns the path of the configuration of the Docker client, config.json in the directory set
+// by DOCKER_CONFIG or in ~/.docker.
+func DockerConfigPath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); len(dir) > 0 {
+		return filepath.Join(dir, "config.json")
+	}
+	return filepath.Join(os.Getenv("HOME"), ".docker", "config.json")
+}
+
+// NewCredentialHelpersFromConfig returns a store for the credential helpers declared by the credHelpers and
+// credsStore of the Docker client configuration at path. A missing file declares no helpers.
+func NewCredentialHelpersFromConfig(path string) (*CredentialHelperStore, error) {
+	config := struct {
+		CredHelpers map[string]string `json:"credHelpers"`
+		CredsStore  string            `json:"credsStore"`
+	}{}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewCredentialHelpers(nil, ""), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", path, err)
+	}
+	return NewCredentialHelpers(config.CredHelpers, config.CredsStore), nil
+}
+
+// NewCredentialHelpers returns a store that asks the credential helper helpers name for the host of a registry,
+// such as ecr-login for docker-credential-ecr-login, and defaultHelper for the registries helpers does not list,
+// if it is set.
+func NewCredentialHelpers(helpers map[string]string, defaultHelper string) *CredentialHelperStore {
+	s := &CredentialHelperStore{
+		helpers:       make(map[string]credentialHelper),
+		defaultHelper: defaultHelper,
+		run:           runCredentialHelper,
+		now:           time.Now,
+		cache:         make(map[string]*helperCredential),
+	}
+	for server, helper := range helpers {
+		if len(helper) == 0 {
+			continue
+		}
+		s.helpers[credentialHelperHost(server)] = credentialHelper{name: helper, server: server}
+	}
+	return s
+}
+
+// CredentialHelperStore provides the credentials of the Docker credential helpers, the docker-credential-<name>
+// programs that return credentials for a server on demand, so that registries such as ECR and GCR can be used
+// without long lived passwords. The credentials of each registry are cached for CredentialHelperCacheDuration,
+// failures are not cached. Helpers run without the lock of the store held, and concurrent requests for the same
+// registry wait for a single run.
+type CredentialHelperStore struct {
+	helpers       map[string]credentialHelper
+	defaultHelper string
+	// run runs the credential helper name with input on its standard input and returns its output.
+	run func(name string, input []byte) ([]byte, error)
+	now func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]*helperCredential
+	err   error
+}
+
+type credentialHelper struct {
+	name   string
+	server string
+}
+
+// helperCredential is the result of running a credential helper, which may still be running until done is closed.
+type helperCredential struct {
+	done               chan struct{}
+	username, password string
+	err                error
+	expires            time.Time
+}
+
+// valid returns true if the helper is still running, or finished without an error and its credentials have not
+// expired at now.
+func (c *helperCredential) valid(now time.Time) bool {
+	select {
+	case <-c.done:
+		return c.err == nil && now.Before(c.expires)
+	default:
+		return true
+	}
+}
+
+// Configured returns true if the store has any credential helper to ask.
+func (s *CredentialHelperStore) Configured() bool {
+	return len(s.helpers) > 0 || len(s.defaultHelper) > 0
+}
+
+func (s *CredentialHelperStore) Basic(url *url.URL) (string, string) {
+	host := credentialHelperHost(url.Host)
+	helper, ok := s.helpers[host]
+	if !ok {
+		if len(s.defaultHelper) == 0 {
+			return "", ""
+		}
+		helper = credentialHelper{name: s.defaultHelper, server: host}
+		if host == "index.docker.io" {
+			helper.server = "https://index.docker.io/v1/"
+		}
+	}
+
+	s.lock.Lock()
+	if cred, ok := s.cache[host]; ok && cred.valid(s.now()) {
+		s.lock.Unlock()
+		<-cred.done
+		return cred.username, cred.password
+	}
+	cred := &helperCredential{done: make(chan struct{})}
+	s.cache[host] = cred
+	s.lock.Unlock()
+
+	// the lock is not held while the helper runs, which may take until CredentialHelperTimeout
+	username, password, err := s.get(helper)
+	if err == nil && len(username) == 0 && len(password) > 0 {
+		username = tokenUsername(host)
+	}
+	s.lock.Lock()
+	if err != nil {
+		glog.V(5).Infof("Unable to get the credentials of %s from the credential helper %s: %v", url, helper.name, err)
+		s.err = err
+		if s.cache[host] == cred {
+			delete(s.cache, host)
+		}
+	}
+	cred.username, cred.password, cred.err = username, password, err
+	cred.expires = s.now().Add(CredentialHelperCacheDuration)
+	close(cred.done)
+	s.lock.Unlock()
+	return username, password
+}
+
+// Err returns the last error running a credential helper.
+func (s *CredentialHelperStore) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// get asks helper for the credentials of its server. A helper that has none returns no credentials and no error.
+func (s *CredentialHelperStore) get(helper credentialHelper) (string, string, error) {
+	out, err := s.run(helper.name, []byte(helper.server))
+	if err != nil {
+		if strings.TrimSpace(string(out)) == credentialsNotFound {
+			return "", "", nil
+		}
+		return "", "", fmt.Errorf("docker-credential-%s failed: %v: %s", helper.name, err, strings.TrimSpace(string(out)))
+	}
+	cred := struct {
+		Username string
+		Secret   string
+	}{}
+	if err := json.Unmarshal(out, &cred); err != nil {
+		return "", "", fmt.Errorf("unable to read the output of docker-credential-%s: %v", helper.name, err)
+	}
+	// an identity token can only be exchanged for a token by the registry's OAuth flow, which is not supported
+	if cred.Username == "<token>" {
+		glog.V(5).Infof("The credential helper %s returned an identity token for %s, which cannot be used", helper.name, helper.server)
+		return "", "", nil
+	}
+	return cred.Username, cred.Secret, nil
+}
+
+// runCredentialHelper runs docker-credential-<name> get, which reads a server from its standard input and writes
+// its credentials, or an error message, to its standard output. The helper is killed after CredentialHelperTimeout.
+func runCredentialHelper(name string, input []byte) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), CredentialHelperTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "docker-credential-"+name, "get")
+	cmd.Stdin = bytes.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", CredentialHelperTimeout)
+	}
+	return out.Bytes(), err
+}
+
+// credentialHelperHost returns the normalized host that the servers of the credHelpers of a Docker configuration
+// and the registries asked for credentials are matched on. The hosts of Docker Hub and its token server all match
+// index.docker.io, which Docker stores the credentials of Docker Hub under.
+func credentialHelperHost(server string) string {
+	host := server
+	if i := strings.Index(host, "://"); i != -1 {
+		host = host[i+3:]
+	}
+	if i := strings.Index(host, "/"); i != -1 {
+		host = host[:i]
+	}
+	host = api.NormalizeRegistryHost(host)
+	switch host {
+	case "docker.io", "registry-1.docker.io", "auth.docker.io":
+		return "index.docker.io"
+	}
+	return host
+}
+
 func NewCredentialsForSecrets(secrets []kapi.Secret) *SecretCredentialStore {
 	return &SecretCredentialStore{secrets: secrets}
 }
@@ -75,16 +568,186 @@ func NewLazyCredentialsForSecrets(secretsFn func() ([]kapi.Secret, error)) *Secr
 	return &SecretCredentialStore{secretsFn: secretsFn}
 }
 
+// AuthFailureFunc is invoked when a registry rejects the credentials of secret for repository, so that failing
+// secrets can be diagnosed.
+type AuthFailureFunc func(secret string, registry *url.URL, repository string, err error)
+
 type SecretCredentialStore struct {
 	lock      sync.Mutex
 	secrets   []kapi.Secret
 	secretsFn func() ([]kapi.Secret, error)
 	err       error
 	keyring   credentialprovider.DockerKeyring
+	named     []namedKeyring
+	onFailure AuthFailureFunc
+	// presented holds the secret whose credentials were last presented for each registry host and repository
+	presented map[string]string
 }
 
+// namedKeyring is the keyring loaded from a single secret, along with the credentials it holds.
+type namedKeyring struct {
+	name        string
+	keyring     credentialprovider.DockerKeyring
+	credentials []SecretCredential
+}
+
+// SecretCredential is a credential a secret holds for a registry or for some of its repositories.
+type SecretCredential struct {
+	// Secret is the name of the secret holding the credential.
+	Secret string
+	// Location is the registry host the credential is stored for, followed by the repository path prefix it is
+	// limited to, if any, such as quay.io or quay.io/myorg. The API version of Docker configuration keys like
+	// https://index.docker.io/v1/ is not part of the location.
+	Location string
+	Username string
+	Password string
+}
+
+// Specificity returns the number of repository path segments the location of the credential is limited to, or
+// zero if the credential is stored for the whole registry. More specific credentials are preferred.
+func (c SecretCredential) Specificity() int {
+	return strings.Count(c.Location, "/")
+}
+
+// matches returns true if the credential applies to repository on the registry at host.
+func (c SecretCredential) matches(host, repository string) bool {
+	parts := strings.SplitN(c.Location, "/", 2)
+	if credentialHelperHost(parts[0]) != credentialHelperHost(api.NormalizeRegistryHost(host)) {
+		return false
+	}
+	if len(parts) == 1 {
+		return true
+	}
+	return repository == parts[1] || strings.HasPrefix(repository, parts[1]+"/")
+}
+
+// bySpecificity orders credentials from the most to the least specific.
+type bySpecificity []SecretCredential
+
+func (c bySpecificity) Len() int           { return len(c) }
+func (c bySpecificity) Less(i, j int) bool { return c[i].Specificity() > c[j].Specificity() }
+func (c bySpecificity) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// OnAuthFailure sets the function invoked when a registry rejects the credentials of one of the secrets.
+func (s *SecretCredentialStore) OnAuthFailure(fn AuthFailureFunc) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.onFailure = fn
+}
+
+// Basic returns the most specific credential for the host and path of url, falling back to the credentials
+// of the keyring merged from all secrets.
 func (s *SecretCredentialStore) Basic(url *url.URL) (string, string) {
-	return basicCredentialsFromKeyring(s.init(), url)
+	keyring := s.init()
+	if credentials := s.CredentialsFor(url); len(credentials) > 0 {
+		return credentials[0].basic(url.Host)
+	}
+	return basicCredentialsFromKeyring(keyring, url)
+}
+
+// SecretFor returns the name of the secret that provides the most specific credential for target, or an empty
+// string if no secret does. When several secrets provide equally specific credentials, the first one wins.
+func (s *SecretCredentialStore) SecretFor(target *url.URL) string {
+	if credentials := s.CredentialsFor(target); len(credentials) > 0 {
+		return credentials[0].Secret
+	}
+	s.lock.Lock()
+	named := s.named
+	s.lock.Unlock()
+	for _, secret := range named {
+		if username, password := basicCredentialsFromKeyring(secret.keyring, target); len(username) > 0 || len(password) > 0 {
+			return secret.name
+		}
+	}
+	return ""
+}
+
+// CredentialsFor returns the credentials of the secrets that apply to target, whose path is the repository
+// credentials are needed for, ranked from the most to the least specific location. Equally specific
+// credentials keep the order of their secrets.
+func (s *SecretCredentialStore) CredentialsFor(target *url.URL) []SecretCredential {
+	s.init()
+	s.lock.Lock()
+	named := s.named
+	s.lock.Unlock()
+
+	repository := strings.Trim(target.Path, "/")
+	var matched []SecretCredential
+	for _, secret := range named {
+		for _, credential := range secret.credentials {
+			if credential.matches(target.Host, repository) {
+				matched = append(matched, credential)
+			}
+		}
+	}
+	sort.Stable(bySpecificity(matched))
+	if len(matched) > 1 && matched[0].Specificity() == matched[1].Specificity() && matched[0].Secret != matched[1].Secret {
+		glog.V(4).Infof("Secrets %s and %s both provide credentials for %s, using %s", matched[0].Secret, matched[1].Secret, matched[0].Location, matched[0].Secret)
+	}
+	return matched
+}
+
+// PresentedSecret returns the name of the secret whose credentials were last presented to registry, or to its
+// token server, for repository, or an empty string if the registry did not ask for credentials or none of the
+// secrets provided them.
+func (s *SecretCredentialStore) PresentedSecret(registry *url.URL, repository string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.presented[presentedKey(registry.Host, repository)]
+}
+
+// recordPresented records that the credentials of secret were presented for repository on the registry at host.
+func (s *SecretCredentialStore) recordPresented(host, repository, secret string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.presented == nil {
+		s.presented = make(map[string]string)
+	}
+	s.presented[presentedKey(host, repository)] = secret
+}
+
+// presentedKey returns the key the secret presented for repository on the registry at host is recorded under.
+func presentedKey(host, repository string) string {
+	return credentialHelperHost(host) + "/" + strings.Trim(repository, "/")
+}
+
+// secretProviding returns the name of the secret that provides username and password for target, or an empty
+// string if none does.
+func (s *SecretCredentialStore) secretProviding(target *url.URL, username, password string) string {
+	for _, credential := range s.CredentialsFor(target) {
+		if u, p := credential.basic(target.Host); u == username && p == password {
+			return credential.Secret
+		}
+	}
+	s.lock.Lock()
+	named := s.named
+	s.lock.Unlock()
+	for _, secret := range named {
+		if u, p := basicCredentialsFromKeyring(secret.keyring, target); u == username && p == password {
+			return secret.name
+		}
+	}
+	return ""
+}
+
+// ForRepository returns a store that provides the most specific credentials for repository on registry,
+// including to the token server of the registry.
+func (s *SecretCredentialStore) ForRepository(registry *url.URL, repository string) auth.CredentialStore {
+	return &repositoryCredentialStore{store: s, target: &url.URL{Host: registry.Host, Path: "/" + repository}}
+}
+
+// RecordAuthFailure reports that registry rejected the credentials presented for repository to the function set
+// with OnAuthFailure.
+func (s *SecretCredentialStore) RecordAuthFailure(registry *url.URL, repository string, err error) {
+	s.lock.Lock()
+	fn := s.onFailure
+	s.lock.Unlock()
+	if fn == nil {
+		return
+	}
+	if secret := s.PresentedSecret(registry, repository); len(secret) > 0 {
+		fn(secret, registry, repository, err)
+	}
 }
 
 func (s *SecretCredentialStore) Err() error {
@@ -108,20 +771,288 @@ func (s *SecretCredentialStore) init() credentialprovider.DockerKeyring {
 	}
 
 	// TODO: need a version of this that is best effort secret - otherwise one error blocks all secrets
-	keyring, err := credentialprovider.MakeDockerKeyring(s.secrets, emptyKeyring)
+	keyring, err := keyringForSecrets(s.secrets)
 	if err != nil {
 		glog.V(5).Infof("Loading keyring failed for credential store: %v", err)
 		s.err = err
 		keyring = emptyKeyring
 	}
 	s.keyring = keyring
+
+	// load each secret on its own so that the secret providing a credential can be identified
+	for _, secret := range s.secrets {
+		configs, err := dockerConfigsForSecrets([]kapi.Secret{secret})
+		if err != nil || len(configs) == 0 {
+			continue
+		}
+		named := namedKeyring{name: secret.Name, keyring: keyringForConfigs(configs)}
+		for _, config := range configs {
+			for registry, entry := range config {
+				location, ok := credentialLocation(registry)
+				if !ok {
+					continue
+				}
+				named.credentials = append(named.credentials, SecretCredential{
+					Secret:   secret.Name,
+					Location: location,
+					Username: entry.Username,
+					Password: entry.Password,
+				})
+			}
+		}
+		// the keys of a configuration are unordered, sort them so that ties are resolved the same way every time
+		sort.Sort(byLocation(named.credentials))
+		s.named = append(s.named, named)
+	}
+	return keyring
+}
+
+// byLocation orders credentials by location.
+type byLocation []SecretCredential
+
+func (c byLocation) Len() int           { return len(c) }
+func (c byLocation) Less(i, j int) bool { return c[i].Location < c[j].Location }
+func (c byLocation) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+// basic returns the username and password of the credential, with the username registries at host expect
+// alongside a token if the credential has none.
+func (c SecretCredential) basic(host string) (string, string) {
+	if len(c.Username) == 0 && len(c.Password) > 0 {
+		return tokenUsername(host), c.Password
+	}
+	return c.Username, c.Password
+}
+
+// credentialLocation returns the registry host and repository path prefix a Docker configuration key stores
+// credentials for. Keys matching hosts with wildcards are left to the keyring.
+func credentialLocation(registry string) (string, bool) {
+	u, err := url.Parse(normalizeCredentialKey(registry))
+	if err != nil || len(u.Host) == 0 || strings.Contains(u.Host, "*") {
+		return "", false
+	}
+	path := strings.Trim(u.Path, "/")
+	if path == "v1" || path == "v2" {
+		path = ""
+	}
+	if len(path) == 0 {
+		return credentialHelperHost(u.Host), true
+	}
+	return credentialHelperHost(u.Host) + "/" + path, true
+}
+
+// repositoryCredentialStore provides the credentials of a secret store for a single repository, whatever URL
+// they are requested for, since the token server of a registry may be on another host.
+type repositoryCredentialStore struct {
+	store  *SecretCredentialStore
+	target *url.URL
+}
+
+// Basic returns the credentials for the repository of the store and records the secret they came from as the
+// one presented for the repository.
+func (s *repositoryCredentialStore) Basic(url *url.URL) (string, string) {
+	repository := strings.Trim(s.target.Path, "/")
+	if credentials := s.store.CredentialsFor(s.target); len(credentials) > 0 {
+		s.store.recordPresented(s.target.Host, repository, credentials[0].Secret)
+		return credentials[0].basic(s.target.Host)
+	}
+	username, password := s.store.Basic(url)
+	if len(username) > 0 || len(password) > 0 {
+		s.store.recordPresented(s.target.Host, repository, s.store.secretProviding(url, username, password))
+	}
+	return username, password
+}
+
+func (s *repositoryCredentialStore) Err() error {
+	return s.store.Err()
+}
+
+// keyringForSecrets returns a keyring holding the Docker credentials of secrets, or emptyKeyring if they hold
+// none. Unlike credentialprovider.MakeDockerKeyring, registries named host:port without a scheme are kept
+// instead of failing to parse as URLs, and each registry host is normalized with api.NormalizeRegistryHost.
+func keyringForSecrets(secrets []kapi.Secret) (credentialprovider.DockerKeyring, error) {
+	configs, err := dockerConfigsForSecrets(secrets)
+	if err != nil {
+		return nil, err
+	}
+	return keyringForConfigs(configs), nil
+}
+
+// dockerConfigsForSecrets returns the Docker configurations held by secrets, with the registry keys normalized
+// by normalizeCredentialKey.
+func dockerConfigsForSecrets(secrets []kapi.Secret) ([]credentialprovider.DockerConfig, error) {
+	configs := []credentialprovider.DockerConfig{}
+	for _, secret := range secrets {
+		config := credentialprovider.DockerConfig{}
+		switch {
+		case secret.Type == kapi.SecretTypeDockerConfigJson && len(secret.Data[kapi.DockerConfigJsonKey]) > 0:
+			configJson := credentialprovider.DockerConfigJson{}
+			if err := json.Unmarshal(secret.Data[kapi.DockerConfigJsonKey], &configJson); err != nil {
+				return nil, err
+			}
+			config = configJson.Auths
+		case secret.Type == kapi.SecretTypeDockercfg && len(secret.Data[kapi.DockerConfigKey]) > 0:
+			if err := json.Unmarshal(secret.Data[kapi.DockerConfigKey], &config); err != nil {
+				return nil, err
+			}
+		default:
+			continue
+		}
+		configs = append(configs, normalizeDockerConfig(config))
+	}
+	return configs, nil
+}
+
+// normalizeDockerConfig returns config with the registries its credentials are stored for normalized.
+func normalizeDockerConfig(config credentialprovider.DockerConfig) credentialprovider.DockerConfig {
+	normalized := credentialprovider.DockerConfig{}
+	for registry, entry := range config {
+		normalized[normalizeCredentialKey(registry)] = entry
+	}
+	return normalized
+}
+
+// keyringForConfigs returns a keyring holding the credentials of configs, or emptyKeyring if there are none.
+func keyringForConfigs(configs []credentialprovider.DockerConfig) credentialprovider.DockerKeyring {
+	if len(configs) == 0 {
+		return emptyKeyring
+	}
+	keyring := &pathKeyring{}
+	for i, config := range configs {
+		for location, entry := range config {
+			u, err := url.Parse(location)
+			if err != nil || len(u.Host) == 0 {
+				glog.V(5).Infof("Ignoring the invalid credential location %q", location)
+				continue
+			}
+			path := strings.TrimSuffix(u.Path, "/")
+			// the API versions Docker stores the credentials of some registries under are not repository paths
+			if path == "/v1" || path == "/v2" {
+				path = ""
+			}
+			keyring.entries = append(keyring.entries, pathKeyringEntry{
+				config: i,
+				host:   u.Host,
+				path:   path,
+				auth:   docker.AuthConfiguration{Username: entry.Username, Password: entry.Password, Email: entry.Email},
+			})
+		}
+	}
+	sort.Stable(byPathLength(keyring.entries))
 	return keyring
 }
 
+// pathKeyring is a keyring that returns the credentials stored for the longest path that prefixes the requested
+// repository, and only the credentials stored for a host when no repository is requested. The keyring of the
+// Kubernetes credential provider also files the credentials of a path under its host, in no particular order,
+// which makes the credentials of a registry that serves repositories under paths unpredictable.
+type pathKeyring struct {
+	entries []pathKeyringEntry
+}
+
+// pathKeyringEntry holds the credentials stored for a host, which may have wildcards, and a path, which is empty
+// for the host alone. config is the index of the configuration the entry was read from.
+type pathKeyringEntry struct {
+	config int
+	host   string
+	path   string
+	auth   docker.AuthConfiguration
+}
+
+// byPathLength orders entries with the longest path first, then by configuration and location, so that lookups
+// are deterministic.
+type byPathLength []pathKeyringEntry
+
+func (e byPathLength) Len() int      { return len(e) }
+func (e byPathLength) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+func (e byPathLength) Less(i, j int) bool {
+	if len(e[i].path) != len(e[j].path) {
+		return len(e[i].path) > len(e[j].path)
+	}
+	if e[i].config != e[j].config {
+		return e[i].config < e[j].config
+	}
+	return e[i].host+e[i].path < e[j].host+e[j].path
+}
+
+// Lookup returns the credentials of the entries with the longest path matching image, a host optionally followed
+// by a path.
+func (k *pathKeyring) Lookup(image string) ([]docker.AuthConfiguration, bool) {
+	target, err := url.Parse("https://" + image)
+	if err != nil {
+		return nil, false
+	}
+	path := strings.TrimSuffix(target.Path, "/")
+	var configs []docker.AuthConfiguration
+	matched := -1
+	for _, entry := range k.entries {
+		if matched != -1 && len(entry.path) != matched {
+			break
+		}
+		if !hostMatches(entry.host, target.Host) {
+			continue
+		}
+		if len(entry.path) > 0 && path != entry.path && !strings.HasPrefix(path, entry.path+"/") {
+			continue
+		}
+		matched = len(entry.path)
+		configs = append(configs, entry.auth)
+	}
+	return configs, len(configs) > 0
+}
+
+// hostMatches returns true if host matches pattern, whose labels may be wildcards such as *.example.com. The
+// ports must be the same.
+func hostMatches(pattern, host string) bool {
+	patternName, patternPort := splitHostPort(pattern)
+	name, port := splitHostPort(host)
+	if patternPort != port {
+		return false
+	}
+	patternLabels, labels := strings.Split(patternName, "."), strings.Split(name, ".")
+	if len(patternLabels) != len(labels) {
+		return false
+	}
+	for i := range labels {
+		if ok, err := filepath.Match(patternLabels[i], labels[i]); err != nil || !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func splitHostPort(host string) (string, string) {
+	name, port, err := net.SplitHostPort(host)
+	if err != nil {
+		return host, ""
+	}
+	return name, port
+}
+
+// normalizeCredentialKey turns the registry a Docker credential is stored for into a URL with a normalized
+// host.
+func normalizeCredentialKey(registry string) string {
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+	u, err := url.Parse(registry)
+	if err != nil {
+		return registry
+	}
+	u.Host = api.NormalizeRegistryHost(u.Host)
+	return u.String()
+}
+
 func basicCredentialsFromKeyring(keyring credentialprovider.DockerKeyring, target *url.URL) (string, string) {
 	// TODO: compare this logic to Docker authConfig in v2 configuration
 	value := target.Host + target.Path
-	configs, found := keyring.Lookup(value)
+	var configs []docker.AuthConfiguration
+	found := false
+	for _, host := range credentialHosts(target.Host) {
+		if configs, found = keyring.Lookup(host + target.Path); found && len(configs) > 0 {
+			value = host + target.Path
+			break
+		}
+	}
 	if !found || len(configs) == 0 {
 		// do a special case check for docker.io to match historical lookups when we respond to a challenge
 		if value == "auth.docker.io/token" {
@@ -132,5 +1063,37 @@ func basicCredentialsFromKeyring(keyring credentialprovider.DockerKeyring, targe
 		return "", ""
 	}
 	glog.V(5).Infof("Found secret to match %s (%s): %s", target, value, configs[0].ServerAddress)
-	return configs[0].Username, configs[0].Password
+	username, password := configs[0].Username, configs[0].Password
+	if len(username) == 0 && len(password) > 0 {
+		username = tokenUsername(target.Host)
+		glog.V(5).Infof("Secret for %s has a token and no username, using %q", target, username)
+	}
+	return username, password
+}
+
+// credentialHosts returns the forms of host that credentials for it may be stored under. Credentials are
+// matched on host and port, and the keyrings built from secrets hold normalized hosts, so a registry on the
+// default HTTPS port is also looked up without the port, while a registry on any other port only matches
+// credentials for that port.
+func credentialHosts(host string) []string {
+	hosts := []string{host}
+	normalized := api.NormalizeRegistryHost(host)
+	if normalized != host {
+		hosts = append(hosts, normalized)
+	}
+	return hosts
+}
+
+// tokenUsername returns the username to send with a token to host.
+func tokenUsername(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	for domain, username := range TokenUsernames {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return username
+		}
+	}
+	return DefaultTokenUsername
 }
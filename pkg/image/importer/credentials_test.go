@@ -1,14 +1,20 @@
 package importer
 
 import (
+	"fmt"
 	"io/ioutil"
 	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	docker "github.com/fsouza/go-dockerclient"
 
 	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/credentialprovider"
 	"k8s.io/kubernetes/pkg/runtime"
 
 	_ "github.com/openshift/origin/pkg/api/install"
@@ -28,6 +34,12 @@ func TestCredentialsForSecrets(t *testing.T) {
 	if user != "serviceaccount" || len(pass) == 0 {
 		t.Errorf("unexpected username and password: %s %s", user, pass)
 	}
+	if name := store.SecretFor(&url.URL{Scheme: "https", Host: "172.30.213.112:5000"}); name != "builder-dockercfg-hnq87" {
+		t.Errorf("unexpected secret: %s", name)
+	}
+	if name := store.SecretFor(&url.URL{Scheme: "https", Host: "other.io"}); len(name) != 0 {
+		t.Errorf("unexpected secret: %s", name)
+	}
 }
 
 type mockKeyring struct {
@@ -47,6 +59,241 @@ func TestHubFallback(t *testing.T) {
 	}
 }
 
+type staticKeyring struct {
+	config docker.AuthConfiguration
+}
+
+func (k *staticKeyring) Lookup(image string) ([]docker.AuthConfiguration, bool) {
+	return []docker.AuthConfiguration{k.config}, true
+}
+
+func TestTokenCredentials(t *testing.T) {
+	tests := []struct {
+		host     string
+		config   docker.AuthConfiguration
+		username string
+	}{
+		{host: "registry.gitlab.com", config: docker.AuthConfiguration{Password: "token"}, username: DefaultTokenUsername},
+		{host: "gcr.io", config: docker.AuthConfiguration{Password: "token"}, username: "oauth2accesstoken"},
+		{host: "us.gcr.io:443", config: docker.AuthConfiguration{Password: "token"}, username: "oauth2accesstoken"},
+		{host: "europe-docker.pkg.dev", config: docker.AuthConfiguration{Password: "token"}, username: "oauth2accesstoken"},
+		{host: "myregistry.azurecr.io", config: docker.AuthConfiguration{Password: "token"}, username: "00000000-0000-0000-0000-000000000000"},
+		{host: "notgcr.io", config: docker.AuthConfiguration{Password: "token"}, username: DefaultTokenUsername},
+		{host: "gcr.io", config: docker.AuthConfiguration{Username: "_json_key", Password: "token"}, username: "_json_key"},
+		{host: "gcr.io", config: docker.AuthConfiguration{}, username: ""},
+	}
+	for i, test := range tests {
+		username, password := basicCredentialsFromKeyring(&staticKeyring{test.config}, &url.URL{Host: test.host})
+		if username != test.username || password != test.config.Password {
+			t.Errorf("%d: unexpected username and password: %q %q", i, username, password)
+		}
+	}
+}
+
+func TestCredentialsForPorts(t *testing.T) {
+	store := NewCredentialsForSecrets([]kapi.Secret{{
+		Type: kapi.SecretTypeDockercfg,
+		Data: map[string][]byte{kapi.DockerConfigKey: []byte(`{
+			"registry.example.com": {"username": "default", "password": "a"},
+			"other.example.com:443": {"username": "explicit", "password": "b"},
+			"ports.example.com:5000": {"username": "custom", "password": "c"},
+			"https://sni.example.com": {"username": "sni", "password": "d"}
+		}`)},
+	}})
+	tests := []struct {
+		host     string
+		username string
+	}{
+		{host: "registry.example.com", username: "default"},
+		{host: "registry.example.com:443", username: "default"},
+		{host: "Registry.Example.com", username: "default"},
+		{host: "registry.example.com:5000"},
+		{host: "other.example.com", username: "explicit"},
+		{host: "other.example.com:443", username: "explicit"},
+		{host: "ports.example.com"},
+		{host: "ports.example.com:5000", username: "custom"},
+		{host: "sni.example.com:443", username: "sni"},
+		{host: "example.com"},
+	}
+	for _, test := range tests {
+		if username, _ := store.Basic(&url.URL{Host: test.host, Path: "/v2/"}); username != test.username {
+			t.Errorf("%s: unexpected username: %q", test.host, username)
+		}
+	}
+
+	creds := NewBasicCredentials()
+	creds.Add(&url.URL{Host: "registry.example.com:443"}, "test", "other")
+	if u, _ := creds.Basic(&url.URL{Host: "registry.example.com"}); u != "test" {
+		t.Errorf("unexpected response: %s", u)
+	}
+	if u, _ := creds.Basic(&url.URL{Host: "registry.example.com:5000"}); u != "" {
+		t.Errorf("unexpected response: %s", u)
+	}
+}
+
+func TestCredentialsForRepositories(t *testing.T) {
+	secret := func(name, config string) kapi.Secret {
+		return kapi.Secret{
+			ObjectMeta: kapi.ObjectMeta{Name: name},
+			Type:       kapi.SecretTypeDockercfg,
+			Data:       map[string][]byte{kapi.DockerConfigKey: []byte(config)},
+		}
+	}
+	store := NewCredentialsForSecrets([]kapi.Secret{
+		secret("registry", `{"quay.io": {"username": "registry", "password": "a"}}`),
+		secret("org", `{"quay.io/org": {"username": "org", "password": "b"}, "https://index.docker.io/v1/": {"username": "hub", "password": "c"}}`),
+		secret("repo", `{"https://quay.io/org/repo": {"username": "repo", "password": "d"}}`),
+		secret("other", `{"quay.io/org": {"username": "other", "password": "e"}}`),
+	})
+	tests := []struct {
+		target   url.URL
+		secrets  []string
+		username string
+	}{
+		{target: url.URL{Host: "quay.io", Path: "/org/repo"}, secrets: []string{"repo", "org", "other", "registry"}, username: "repo"},
+		{target: url.URL{Host: "quay.io:443", Path: "/org/repo/nested"}, secrets: []string{"repo", "org", "other", "registry"}, username: "repo"},
+		{target: url.URL{Host: "quay.io", Path: "/org/other"}, secrets: []string{"org", "other", "registry"}, username: "org"},
+		{target: url.URL{Host: "quay.io", Path: "/organization/repo"}, secrets: []string{"registry"}, username: "registry"},
+		{target: url.URL{Host: "quay.io", Path: "/v2/"}, secrets: []string{"registry"}, username: "registry"},
+		{target: url.URL{Host: "registry-1.docker.io", Path: "/library/busybox"}, secrets: []string{"org"}, username: "hub"},
+		{target: url.URL{Host: "auth.docker.io", Path: "/token"}, secrets: []string{"org"}, username: "hub"},
+		{target: url.URL{Host: "quay.io:5000", Path: "/org/repo"}},
+	}
+	for _, test := range tests {
+		secrets := []string{}
+		for _, credential := range store.CredentialsFor(&test.target) {
+			secrets = append(secrets, credential.Secret)
+		}
+		if len(test.secrets) == 0 {
+			test.secrets = []string{}
+		}
+		if !reflect.DeepEqual(secrets, test.secrets) {
+			t.Errorf("%s: unexpected secrets: %v", test.target.String(), secrets)
+		}
+		if username, _ := store.Basic(&test.target); username != test.username {
+			t.Errorf("%s: unexpected username: %q", test.target.String(), username)
+		}
+		if name := store.SecretFor(&test.target); len(test.secrets) > 0 && name != test.secrets[0] {
+			t.Errorf("%s: unexpected secret: %s", test.target.String(), name)
+		}
+	}
+
+	// the credentials for a repository are also sent to the token server of its registry
+	scoped := store.ForRepository(&url.URL{Host: "quay.io"}, "org/repo")
+	if username, _ := scoped.Basic(&url.URL{Host: "quay.io", Path: "/v2/auth"}); username != "repo" {
+		t.Errorf("unexpected username: %q", username)
+	}
+	if name := store.PresentedSecret(&url.URL{Host: "quay.io:443"}, "org/repo"); name != "repo" {
+		t.Errorf("unexpected presented secret: %s", name)
+	}
+	if name := store.PresentedSecret(&url.URL{Host: "quay.io"}, "org/other"); len(name) > 0 {
+		t.Errorf("unexpected presented secret: %s", name)
+	}
+	if username, _ := store.ForRepository(&url.URL{Host: "example.com"}, "org/repo").Basic(&url.URL{Host: "quay.io"}); username != "registry" {
+		t.Errorf("unexpected username: %q", username)
+	}
+
+	failures := []string{}
+	store.OnAuthFailure(func(secret string, registry *url.URL, repository string, err error) {
+		failures = append(failures, fmt.Sprintf("%s %s/%s: %v", secret, registry.Host, repository, err))
+	})
+	// only the secrets whose credentials were presented are reported
+	store.RecordAuthFailure(&url.URL{Host: "quay.io"}, "org/other", fmt.Errorf("denied"))
+	store.ForRepository(&url.URL{Host: "quay.io"}, "org/other").Basic(&url.URL{Host: "quay.io", Path: "/v2/auth"})
+	store.RecordAuthFailure(&url.URL{Host: "quay.io"}, "org/other", fmt.Errorf("denied"))
+	store.RecordAuthFailure(&url.URL{Host: "example.com"}, "org/other", fmt.Errorf("denied"))
+	if !reflect.DeepEqual(failures, []string{"org quay.io/org/other: denied"}) {
+		t.Errorf("unexpected failures: %v", failures)
+	}
+}
+
+func TestKeyringCredentialsForRepositories(t *testing.T) {
+	keyring := keyringForConfigs([]credentialprovider.DockerConfig{normalizeDockerConfig(credentialprovider.DockerConfig{
+		"https://artifactory.example.com":                     {Username: "registry", Password: "a"},
+		"https://artifactory.example.com/docker-virtual":      {Username: "virtual", Password: "b"},
+		"https://artifactory.example.com/docker-virtual/team": {Username: "team", Password: "c"},
+		"nexus.example.com/docker-hosted":                     {Username: "hosted", Password: "d"},
+	})})
+	store := NewCompositeCredentials(NewBasicCredentials(), &keyringCredentialStore{keyring})
+	tests := []struct {
+		registry   string
+		repository string
+		username   string
+	}{
+		{registry: "artifactory.example.com", repository: "docker-virtual/app", username: "virtual"},
+		{registry: "artifactory.example.com", repository: "docker-virtual/team/app", username: "team"},
+		{registry: "artifactory.example.com", repository: "docker-virtual/teams/app", username: "virtual"},
+		{registry: "artifactory.example.com", repository: "docker-local/team/app", username: "registry"},
+		{registry: "nexus.example.com", repository: "docker-hosted/team/app", username: "hosted"},
+		{registry: "nexus.example.com", repository: "docker-other/team/app", username: ""},
+	}
+	// the keyring of the credential provider files the credentials of paths under their host in no particular
+	// order, so every lookup is repeated to check it is deterministic
+	for i := 0; i < 20; i++ {
+		for _, test := range tests {
+			scoped := store.ForRepository(&url.URL{Host: test.registry}, test.repository)
+			if username, _ := scoped.Basic(&url.URL{Host: test.registry, Path: "/v2/token"}); username != test.username {
+				t.Fatalf("%s: unexpected username: %q", test.repository, username)
+			}
+		}
+		// requests for a host alone ignore the credentials of its paths
+		if username, _ := store.Basic(&url.URL{Host: "artifactory.example.com", Path: "/v2/token"}); username != "registry" {
+			t.Fatalf("unexpected username: %q", username)
+		}
+		if username, _ := store.Basic(&url.URL{Host: "nexus.example.com"}); username != "" {
+			t.Fatalf("unexpected username: %q", username)
+		}
+	}
+
+	// the credentials of a repository are only provided to its registry and to the token servers it announced
+	realm := &url.URL{Scheme: "https", Host: "auth.example.com", Path: "/token"}
+	scoped := store.ForRepository(&url.URL{Host: "nexus.example.com"}, "docker-hosted/app")
+	if username, _ := scoped.Basic(&url.URL{Host: "auth.example.com", Path: "/token"}); username != "" {
+		t.Errorf("unexpected username for another host: %q", username)
+	}
+	scoped = store.ForRepositoryRealms(&url.URL{Host: "nexus.example.com"}, "docker-hosted/app", []*url.URL{realm})
+	if username, _ := scoped.Basic(&url.URL{Host: "auth.example.com", Path: "/token"}); username != "hosted" {
+		t.Errorf("unexpected username for the realm: %q", username)
+	}
+	if username, _ := scoped.Basic(&url.URL{Host: "auth.example.com", Path: "/other"}); username != "" {
+		t.Errorf("unexpected username for another path of the realm host: %q", username)
+	}
+}
+
+func TestPathKeyring(t *testing.T) {
+	keyring := keyringForConfigs([]credentialprovider.DockerConfig{
+		normalizeDockerConfig(credentialprovider.DockerConfig{
+			"https://index.docker.io/v1/": {Username: "hub", Password: "a"},
+			"*.example.com":               {Username: "wildcard", Password: "b"},
+			"registry.example.com:5000":   {Username: "port", Password: "c"},
+		}),
+		normalizeDockerConfig(credentialprovider.DockerConfig{
+			"registry.example.com:5000": {Username: "other", Password: "d"},
+		}),
+	})
+	tests := []struct {
+		image     string
+		usernames []string
+	}{
+		{image: "index.docker.io", usernames: []string{"hub"}},
+		{image: "index.docker.io/v1", usernames: []string{"hub"}},
+		{image: "registry.example.com/app", usernames: []string{"wildcard"}},
+		{image: "registry.example.com:5000/app", usernames: []string{"port", "other"}},
+		{image: "example.com/app"},
+		{image: "quay.io/app"},
+	}
+	for _, test := range tests {
+		configs, found := keyring.Lookup(test.image)
+		usernames := []string{}
+		for _, config := range configs {
+			usernames = append(usernames, config.Username)
+		}
+		if found != (len(test.usernames) > 0) || (found && !reflect.DeepEqual(usernames, test.usernames)) {
+			t.Errorf("%s: unexpected credentials: %v %t", test.image, usernames, found)
+		}
+	}
+}
+
 func TestBasicCredentials(t *testing.T) {
 	creds := NewBasicCredentials()
 	creds.Add(&url.URL{Host: "localhost"}, "test", "other")
@@ -60,3 +307,311 @@ func TestBasicCredentials(t *testing.T) {
 		t.Fatalf("unexpected response: %s %s", u, p)
 	}
 }
+
+func TestRegistryCredentials(t *testing.T) {
+	registry, ok := "", false
+	creds := NewRegistryCredentials(func() (string, bool) { return registry, ok }, "system", "token")
+	if u, p := creds.Basic(&url.URL{}); u != "" || p != "" {
+		t.Fatalf("unexpected response without a registry: %s %s", u, p)
+	}
+	registry, ok = "172.30.1.1:5000", true
+	for _, host := range []string{"", "docker.io", "172.30.1.1", "evil.com:5000"} {
+		if u, p := creds.Basic(&url.URL{Host: host}); u != "" || p != "" {
+			t.Errorf("%s: unexpected response: %s %s", host, u, p)
+		}
+	}
+	if u, p := creds.Basic(&url.URL{Host: "172.30.1.1:5000", Path: "/openshift/token"}); u != "system" || p != "token" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+}
+
+func TestCompositeCredentials(t *testing.T) {
+	explicit := NewBasicCredentials()
+	explicit.Add(&url.URL{Host: "registry.example.com"}, "explicit", "one")
+	fallback := NewBasicCredentials()
+	fallback.Add(&url.URL{Host: "registry.example.com"}, "fallback", "two")
+	fallback.Add(&url.URL{Host: "other.example.com"}, "fallback", "three")
+	secrets := NewLazyCredentialsForSecrets(func() ([]kapi.Secret, error) {
+		return nil, fmt.Errorf("unable to list secrets")
+	})
+
+	creds := NewCompositeCredentials(nil, secrets, explicit, fallback)
+	if u, p := creds.Basic(&url.URL{Host: "registry.example.com"}); u != "explicit" || p != "one" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if u, p := creds.Basic(&url.URL{Host: "other.example.com"}); u != "fallback" || p != "three" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if u, p := creds.Basic(&url.URL{Host: "unknown.example.com"}); u != "" || p != "" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if err := creds.Err(); err == nil || err.Error() != "unable to list secrets" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	creds = NewCompositeCredentials(fallback, explicit)
+	if u, _ := creds.Basic(&url.URL{Host: "registry.example.com"}); u != "fallback" {
+		t.Errorf("unexpected response: %s", u)
+	}
+	if err := creds.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestResolvedCredentials(t *testing.T) {
+	calls := 0
+	fail := true
+	creds := NewResolvedCredentials(func(namespace string, url *url.URL) (string, string, error) {
+		calls++
+		if namespace == "broken" && fail {
+			return "", "", fmt.Errorf("unable to read secrets")
+		}
+		if namespace == "other" {
+			return "", "", nil
+		}
+		return namespace, url.Host, nil
+	})
+
+	first := creds.ForNamespace("first")
+	if u, p := first.Basic(&url.URL{Host: "registry.example.com:443"}); u != "first" || p != "registry.example.com:443" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if u, _ := creds.ForNamespace("first").Basic(&url.URL{Host: "registry.example.com"}); u != "first" {
+		t.Errorf("unexpected response: %s", u)
+	}
+	if u, p := creds.ForNamespace("other").Basic(&url.URL{Host: "registry.example.com"}); u != "" || p != "" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	creds.ForNamespace("other").Basic(&url.URL{Host: "registry.example.com"})
+	if calls != 2 {
+		t.Errorf("expected resolutions to be cached, got %d calls", calls)
+	}
+
+	broken := creds.ForNamespace("broken")
+	if u, p := broken.Basic(&url.URL{Host: "registry.example.com"}); u != "" || p != "" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if err := broken.Err(); err == nil {
+		t.Errorf("expected an error")
+	}
+	fail = false
+	if u, _ := broken.Basic(&url.URL{Host: "registry.example.com"}); u != "broken" {
+		t.Errorf("expected failures not to be cached, got %s", u)
+	}
+	if first.Err() != nil {
+		t.Errorf("unexpected error: %v", first.Err())
+	}
+
+	// resolutions expire
+	now := time.Now()
+	creds.now = func() time.Time { return now }
+	calls = 0
+	creds.ForNamespace("first").Basic(&url.URL{Host: "registry.example.com"})
+	now = now.Add(ResolvedCredentialsCacheDuration)
+	creds.ForNamespace("first").Basic(&url.URL{Host: "registry.example.com"})
+	if calls != 1 {
+		t.Errorf("expected the resolution to expire, got %d calls", calls)
+	}
+	if len(creds.cache) != 1 {
+		t.Errorf("expected the expired resolutions to be dropped: %d", len(creds.cache))
+	}
+}
+
+func TestResolvedCredentialsConcurrent(t *testing.T) {
+	var lock sync.Mutex
+	calls := map[string]int{}
+	release := make(chan struct{})
+	creds := NewResolvedCredentials(func(namespace string, url *url.URL) (string, string, error) {
+		lock.Lock()
+		calls[namespace]++
+		lock.Unlock()
+		// a slow resolution does not block the resolutions of other namespaces
+		if namespace == "slow" {
+			<-release
+		}
+		return namespace, "", nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if u, _ := creds.ForNamespace("slow").Basic(&url.URL{Host: "registry.example.com"}); u != "slow" {
+				t.Errorf("unexpected username: %s", u)
+			}
+		}()
+	}
+	if u, _ := creds.ForNamespace("fast").Basic(&url.URL{Host: "registry.example.com"}); u != "fast" {
+		t.Errorf("unexpected username: %s", u)
+	}
+	close(release)
+	wg.Wait()
+	if calls["slow"] != 1 || calls["fast"] != 1 {
+		t.Errorf("expected a single resolution per namespace: %v", calls)
+	}
+}
+
+func TestCredentialHelpers(t *testing.T) {
+	calls := []string{}
+	fail := false
+	store := NewCredentialHelpers(map[string]string{
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com": "ecr-login",
+		"https://gcr.io": "gcr",
+	}, "")
+	store.run = func(name string, input []byte) ([]byte, error) {
+		calls = append(calls, name+" "+string(input))
+		switch {
+		case fail:
+			return []byte("unable to reach the metadata server"), fmt.Errorf("exit status 1")
+		case name == "gcr":
+			return []byte(`{"ServerURL":"https://gcr.io","Username":"","Secret":"access-token"}`), nil
+		default:
+			return []byte(`{"ServerURL":"123456789012.dkr.ecr.us-east-1.amazonaws.com","Username":"AWS","Secret":"password"}`), nil
+		}
+	}
+	now := time.Unix(0, 0)
+	store.now = func() time.Time { return now }
+
+	if u, p := store.Basic(&url.URL{Host: "123456789012.dkr.ecr.us-east-1.amazonaws.com:443"}); u != "AWS" || p != "password" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if u, p := store.Basic(&url.URL{Host: "gcr.io", Path: "/v2/token"}); u != "oauth2accesstoken" || p != "access-token" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if u, p := store.Basic(&url.URL{Host: "quay.io"}); u != "" || p != "" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	store.Basic(&url.URL{Host: "gcr.io"})
+	expected := []string{"ecr-login 123456789012.dkr.ecr.us-east-1.amazonaws.com", "gcr https://gcr.io"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected credentials to be cached, got calls %v", calls)
+	}
+
+	now = now.Add(CredentialHelperCacheDuration)
+	fail = true
+	if u, p := store.Basic(&url.URL{Host: "gcr.io"}); u != "" || p != "" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if err := store.Err(); err == nil || err.Error() != "docker-credential-gcr failed: exit status 1: unable to reach the metadata server" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCredentialHelpersConcurrent(t *testing.T) {
+	store := NewCredentialHelpers(map[string]string{"gcr.io": "gcr", "quay.io": "quay"}, "")
+	var lock sync.Mutex
+	calls := map[string]int{}
+	release := make(chan struct{})
+	store.run = func(name string, input []byte) ([]byte, error) {
+		lock.Lock()
+		calls[name]++
+		lock.Unlock()
+		// a slow helper does not block the helpers of other registries
+		if name == "gcr" {
+			<-release
+		}
+		return []byte(`{"Username":"` + name + `","Secret":"password"}`), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if u, _ := store.Basic(&url.URL{Host: "gcr.io"}); u != "gcr" {
+				t.Errorf("unexpected username: %s", u)
+			}
+		}()
+	}
+	if u, _ := store.Basic(&url.URL{Host: "quay.io"}); u != "quay" {
+		t.Errorf("unexpected username: %s", u)
+	}
+	close(release)
+	wg.Wait()
+	if calls["gcr"] != 1 || calls["quay"] != 1 {
+		t.Errorf("expected a single run per registry: %v", calls)
+	}
+}
+
+func TestRunCredentialHelperTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-helpers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "docker-credential-hang"), []byte("#!/bin/sh\nexec sleep 30\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	defer func(timeout time.Duration) { CredentialHelperTimeout = timeout }(CredentialHelperTimeout)
+	CredentialHelperTimeout = 100 * time.Millisecond
+
+	start := time.Now()
+	if _, err := runCredentialHelper("hang", []byte("gcr.io")); err == nil || err.Error() != "timed out after 100ms" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("the credential helper was not killed after %s", d)
+	}
+}
+
+func TestCredentialHelpersDefault(t *testing.T) {
+	store := NewCredentialHelpers(nil, "osxkeychain")
+	servers := []string{}
+	store.run = func(name string, input []byte) ([]byte, error) {
+		servers = append(servers, string(input))
+		if string(input) == "quay.io" {
+			return []byte(`{"Username":"<token>","Secret":"identity-token"}`), nil
+		}
+		return []byte(credentialsNotFound + "\n"), fmt.Errorf("exit status 1")
+	}
+	if !store.Configured() {
+		t.Errorf("expected the store to be configured")
+	}
+	if u, p := store.Basic(&url.URL{Host: "auth.docker.io", Path: "/token"}); u != "" || p != "" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if u, p := store.Basic(&url.URL{Host: "quay.io"}); u != "" || p != "" {
+		t.Errorf("unexpected response: %s %s", u, p)
+	}
+	if err := store.Err(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(servers, []string{"https://index.docker.io/v1/", "quay.io"}) {
+		t.Errorf("unexpected servers: %v", servers)
+	}
+}
+
+func TestCredentialHelpersFromConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credential-helpers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewCredentialHelpersFromConfig(filepath.Join(dir, "config.json"))
+	if err != nil || store.Configured() {
+		t.Fatalf("unexpected store for a missing configuration: %#v %v", store, err)
+	}
+
+	path := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"auths":{"quay.io":{}},"credHelpers":{"gcr.io":"gcr","eu.gcr.io":""}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewCredentialHelpersFromConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(store.helpers, map[string]credentialHelper{"gcr.io": {name: "gcr", server: "gcr.io"}}) || len(store.defaultHelper) != 0 {
+		t.Errorf("unexpected helpers: %#v", store)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"credHelpers":[]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCredentialHelpersFromConfig(path); err == nil {
+		t.Errorf("expected an error")
+	}
+}
@@ -0,0 +1,186 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/registry/client/auth"
+
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+// RegistryStatus reports whether images can be imported from a registry.
+type RegistryStatus struct {
+	// Registry is the registry that was checked, as it was provided.
+	Registry string
+	// Endpoint is the URL of the v2 API of the registry, if it was reached.
+	Endpoint string
+	// Reachable is true if the registry answered as a v2 Docker registry.
+	Reachable bool
+	// AuthScheme is the scheme the registry challenged with, such as basic or bearer, or empty if it allows
+	// anonymous access.
+	AuthScheme string
+	// Authenticated is true if the registry allows anonymous access or accepted the credentials it was sent.
+	Authenticated bool
+	// Err is the reason the registry could not be reached or refused access.
+	Err error
+}
+
+// CheckRegistries verifies that each of registries, a host, a host:port or a URL, can be reached with the
+// transports of the context and accepts the credentials the store provides for it, by requesting the v2 API
+// root the way an import does. Insecure allows plain HTTP connections and registries with untrusted
+// certificates. The statuses are returned in the order of registries.
+func (c Context) CheckRegistries(credentials auth.CredentialStore, registries []string, insecure bool) []RegistryStatus {
+	if credentials == nil {
+		credentials = NoCredentials
+	}
+	statuses := make([]RegistryStatus, 0, len(registries))
+	for _, registry := range registries {
+		statuses = append(statuses, c.checkRegistry(credentials, registry, insecure))
+	}
+	return statuses
+}
+
+func (c Context) checkRegistry(credentials auth.CredentialStore, registry string, insecure bool) RegistryStatus {
+	status := RegistryStatus{Registry: registry}
+	target, err := registryURL(registry)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+
+	r := c.withActions(credentials, "pull").(*repositoryRetriever)
+	t := r.transportFor(target.Host, insecure)
+	redirect, err := r.ping(*target, insecure, t)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	if redirect != nil {
+		target = redirect
+	}
+	endpoint := *target
+	endpoint.Path = path.Join(endpoint.Path, "v2") + "/"
+	status.Endpoint = endpoint.String()
+	status.Reachable = true
+
+	challenges, _ := c.Challenges.GetChallenges(endpoint.String())
+	if len(challenges) == 0 {
+		status.Authenticated = true
+		return status
+	}
+	challenge := challenges[0]
+	status.AuthScheme = strings.ToLower(challenge.Scheme)
+
+	client := &http.Client{Transport: t, Timeout: 15 * time.Second}
+	req, err := http.NewRequest("HEAD", endpoint.String(), nil)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	switch status.AuthScheme {
+	case "basic":
+		username, password := credentials.Basic(&endpoint)
+		if len(username) == 0 && len(password) == 0 {
+			status.Err = fmt.Errorf("no credentials were found for %s", registry)
+			return status
+		}
+		req.SetBasicAuth(username, password)
+	case "bearer":
+		token, err := registryToken(client, credentials, challenge.Parameters)
+		if err != nil {
+			status.Err = err
+			return status
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	default:
+		status.Err = fmt.Errorf("the registry %s requested the unsupported authentication scheme %q", registry, challenge.Scheme)
+		return status
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		status.Err = err
+		return status
+	}
+	resp.Body.Close()
+	switch code := resp.StatusCode; {
+	case code == http.StatusUnauthorized, code == http.StatusForbidden:
+		status.Err = fmt.Errorf("the registry %s refused the credentials: %s", registry, resp.Status)
+	case code >= 300 || code < 200:
+		status.Err = fmt.Errorf("the registry %s returned an unexpected response: %s", registry, resp.Status)
+	default:
+		status.Authenticated = true
+	}
+	return status
+}
+
+// registryURL returns the URL of registry, which defaults to the HTTPS scheme. An empty registry is the Docker Hub.
+func registryURL(registry string) (*url.URL, error) {
+	if len(registry) == 0 || registry == api.DockerDefaultRegistry {
+		registry = api.DockerDefaultV2Registry
+	}
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+	target, err := url.Parse(registry)
+	if err != nil {
+		return nil, fmt.Errorf("%q is not a valid registry: %v", registry, err)
+	}
+	if len(target.Host) == 0 {
+		return nil, fmt.Errorf("%q is not a valid registry: no host was provided", registry)
+	}
+	return target, nil
+}
+
+// registryToken requests a token without a repository scope from the realm of a bearer challenge, sending the
+// credentials the store provides for the realm.
+func registryToken(client *http.Client, credentials auth.CredentialStore, params map[string]string) (string, error) {
+	realm, ok := params["realm"]
+	if !ok {
+		return "", fmt.Errorf("no realm was specified for the token challenge")
+	}
+	realmURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("the realm %q of the token challenge is not a valid URL: %v", realm, err)
+	}
+	if service := params["service"]; len(service) > 0 {
+		query := realmURL.Query()
+		query.Set("service", service)
+		realmURL.RawQuery = query.Encode()
+	}
+	req, err := http.NewRequest("GET", realmURL.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if username, password := credentials.Basic(realmURL); len(username) > 0 || len(password) > 0 {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the realm %s refused to issue a token: %s", realm, resp.Status)
+	}
+	token := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("unable to read the token issued by %s: %v", realm, err)
+	}
+	if len(token.Token) == 0 {
+		token.Token = token.AccessToken
+	}
+	if len(token.Token) == 0 {
+		return "", fmt.Errorf("the realm %s did not issue a token", realm)
+	}
+	return token.Token, nil
+}
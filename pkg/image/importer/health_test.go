@@ -0,0 +1,82 @@
+package importer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCheckRegistries(t *testing.T) {
+	var private *httptest.Server
+	private = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if service := r.URL.Query().Get("service"); service != "test" {
+				t.Errorf("unexpected service: %s", service)
+			}
+			fmt.Fprintln(w, `{"token":"valid"}`)
+		case "/v2/":
+			w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+			if r.Header.Get("Authorization") != "Bearer valid" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, private.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer private.Close()
+	basic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "other" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer basic.Close()
+	public := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	}))
+	defer public.Close()
+	notV2 := httptest.NewServer(http.NotFoundHandler())
+	defer notV2.Close()
+
+	privateURL, _ := url.Parse(private.URL)
+	basicURL, _ := url.Parse(basic.URL)
+	creds := NewBasicCredentials()
+	creds.Add(&url.URL{Host: privateURL.Host}, "user", "secret")
+	creds.Add(&url.URL{Host: basicURL.Host}, "user", "secret")
+
+	registries := []string{private.URL, basic.URL, public.URL, notV2.URL, "http://"}
+	statuses := NewContext(http.DefaultTransport, nil).CheckRegistries(creds, registries, true)
+	if len(statuses) != len(registries) {
+		t.Fatalf("unexpected statuses: %#v", statuses)
+	}
+	if s := statuses[0]; !s.Reachable || !s.Authenticated || s.AuthScheme != "bearer" || s.Err != nil || s.Endpoint != private.URL+"/v2/" {
+		t.Errorf("unexpected status: %#v", s)
+	}
+	if s := statuses[1]; !s.Reachable || s.Authenticated || s.AuthScheme != "basic" || s.Err == nil {
+		t.Errorf("unexpected status: %#v", s)
+	}
+	if s := statuses[2]; !s.Reachable || !s.Authenticated || s.AuthScheme != "" || s.Err != nil {
+		t.Errorf("unexpected status: %#v", s)
+	}
+	if s := statuses[3]; s.Reachable || s.Authenticated || s.Err == nil {
+		t.Errorf("unexpected status: %#v", s)
+	}
+	if s := statuses[4]; s.Reachable || s.Err == nil {
+		t.Errorf("unexpected status: %#v", s)
+	}
+
+	// without credentials the realm refuses to issue a token
+	statuses = NewContext(http.DefaultTransport, nil).CheckRegistries(nil, []string{private.URL}, true)
+	if s := statuses[0]; !s.Reachable || s.Authenticated || s.Err == nil {
+		t.Errorf("unexpected status: %#v", s)
+	}
+}
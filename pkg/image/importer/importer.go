@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -43,11 +44,60 @@ type Interface interface {
 	Import(ctx gocontext.Context, isi *api.ImageStreamImport) error
 }
 
-// RepositoryRetriever fetches a Docker distribution.Repository.
+// RepositoryRetriever fetches a Docker distribution.Repository and offers convenience accessors for the
+// manifests, tags, and blobs within it. Implementations are responsible for authenticating to the remote
+// registry with the credentials they were created with.
 type RepositoryRetriever interface {
 	// Repository returns a properly authenticated distribution.Repository for the given registry, repository
 	// name, and insecure toleration behavior.
 	Repository(ctx gocontext.Context, registry *url.URL, repoName string, insecure bool) (distribution.Repository, error)
+	// GetManifest returns the manifest identified by reference, which may be either a tag or a digest.
+	GetManifest(ctx gocontext.Context, registry *url.URL, repoName, reference string, insecure bool) (*schema1.SignedManifest, error)
+	// ListTags returns all of the tags in the named repository.
+	ListTags(ctx gocontext.Context, registry *url.URL, repoName string, insecure bool) ([]string, error)
+	// GetBlobMeta returns the descriptor of the blob identified by dgst in the named repository.
+	GetBlobMeta(ctx gocontext.Context, registry *url.URL, repoName string, dgst digest.Digest, insecure bool) (distribution.Descriptor, error)
+}
+
+// ImportSource is optionally implemented by a RepositoryRetriever to describe how it reached a registry, so that
+// the provenance of imported images can be recorded.
+type ImportSource interface {
+	// Endpoint returns the URL content is retrieved from for registry, which differs from registry when the
+	// retriever fell back to HTTP.
+	Endpoint(registry *url.URL) *url.URL
+	// SecretFor returns the name of the secret whose credentials were presented to registry for repository, or
+	// an empty string if the registry did not ask for credentials or they did not come from a secret.
+	SecretFor(registry *url.URL, repository string) string
+}
+
+// RepositoryCredentialStore is implemented by credential stores that may hold different credentials for the
+// repositories of a registry.
+type RepositoryCredentialStore interface {
+	// ForRepository returns the store providing the credentials for repository on registry.
+	ForRepository(registry *url.URL, repository string) auth.CredentialStore
+}
+
+// RealmCredentialStore is implemented by repository credential stores that only provide the credentials of a
+// repository to its registry and to the token servers the registry announced.
+type RealmCredentialStore interface {
+	// ForRepositoryRealms returns the store providing the credentials for repository on registry, which are
+	// also provided to the token servers at realms.
+	ForRepositoryRealms(registry *url.URL, repository string, realms []*url.URL) auth.CredentialStore
+}
+
+// PresentedSecretSource is implemented by credential stores that load their credentials from secrets and keep
+// track of the secret whose credentials were presented to a registry.
+type PresentedSecretSource interface {
+	// PresentedSecret returns the name of the secret whose credentials were presented to registry for
+	// repository, or an empty string if there is none.
+	PresentedSecret(registry *url.URL, repository string) string
+}
+
+// AuthFailureRecorder is implemented by RepositoryRetrievers and credential stores that keep track of the
+// credentials registries reject.
+type AuthFailureRecorder interface {
+	// RecordAuthFailure reports that registry rejected the credentials used for repository with err.
+	RecordAuthFailure(registry *url.URL, repository string, err error)
 }
 
 // ErrNotV2Registry is returned when the server does not report itself as a V2 Docker registry
@@ -67,6 +117,11 @@ type ImageStreamImporter struct {
 	retriever RepositoryRetriever
 	limiter   util.RateLimiter
 
+	workers          int
+	registryLimiters *RegistryRateLimiters
+	defaultPlatform  api.ImagePlatform
+	tagListLimits    TagListLimits
+
 	digestToRepositoryCache map[gocontext.Context]map[manifestKey]*api.Image
 }
 
@@ -79,13 +134,41 @@ func NewImageStreamImporter(retriever RepositoryRetriever, maximumTagsPerRepo in
 	return &ImageStreamImporter{
 		maximumTagsPerRepo: maximumTagsPerRepo,
 
-		retriever: retriever,
-		limiter:   limiter,
+		retriever:       retriever,
+		limiter:         limiter,
+		defaultPlatform: api.ImagePlatform{OS: "linux", Architecture: "amd64"},
+		tagListLimits:   DefaultTagListLimits,
 
 		digestToRepositoryCache: make(map[gocontext.Context]map[manifestKey]*api.Image),
 	}
 }
 
+// WithConcurrency returns a copy of the importer that imports the repositories and images of an import with
+// up to workers requests at the same time, and that limits the requests to each registry with registries,
+// which may be shared with other importers. A workers value of one or less imports serially.
+func (i *ImageStreamImporter) WithConcurrency(workers int, registries *RegistryRateLimiters) *ImageStreamImporter {
+	copied := *i
+	copied.workers = workers
+	copied.registryLimiters = registries
+	return &copied
+}
+
+// WithDefaultPlatform returns a copy of the importer that imports the image of platform from the manifest lists
+// and image indexes that tags point to, unless the import policy of the tag names another platform.
+func (i *ImageStreamImporter) WithDefaultPlatform(platform api.ImagePlatform) *ImageStreamImporter {
+	copied := *i
+	copied.defaultPlatform = platform
+	return &copied
+}
+
+// WithTagListLimits returns a copy of the importer that lists the tags of the repositories it imports within
+// limits.
+func (i *ImageStreamImporter) WithTagListLimits(limits TagListLimits) *ImageStreamImporter {
+	copied := *i
+	copied.tagListLimits = limits
+	return &copied
+}
+
 // contextImageCache returns the image cache entry for a context.
 func (i *ImageStreamImporter) contextImageCache(ctx gocontext.Context) map[manifestKey]*api.Image {
 	cache := i.digestToRepositoryCache[ctx]
@@ -99,17 +182,20 @@ func (i *ImageStreamImporter) contextImageCache(ctx gocontext.Context) map[manif
 // Import tries to complete the provided isi object with images loaded from remote registries.
 func (i *ImageStreamImporter) Import(ctx gocontext.Context, isi *api.ImageStreamImport) error {
 	cache := i.contextImageCache(ctx)
-	importImages(ctx, i.retriever, isi, cache, i.limiter)
-	importFromRepository(ctx, i.retriever, isi, i.maximumTagsPerRepo, cache, i.limiter)
+	limits := newImportLimits(i.limiter, i.registryLimiters, i.workers)
+	importImages(ctx, i.retriever, isi, cache, limits, i.defaultPlatform)
+	importFromRepository(ctx, i.retriever, isi, i.maximumTagsPerRepo, i.tagListLimits, cache, limits, i.defaultPlatform)
 	return nil
 }
 
 // importImages updates the passed ImageStreamImport object and sets Status for each image based on whether the import
-// succeeded or failed. Cache is updated with any loaded images. Limiter is optional and controls how fast images are updated.
-func importImages(ctx gocontext.Context, retriever RepositoryRetriever, isi *api.ImageStreamImport, cache map[manifestKey]*api.Image, limiter util.RateLimiter) {
+// succeeded or failed. Cache is updated with any loaded images. Limits control how fast and how many images are retrieved
+// at the same time. The image of defaultPlatform is imported from manifest lists unless the import policy names another.
+func importImages(ctx gocontext.Context, retriever RepositoryRetriever, isi *api.ImageStreamImport, cache map[manifestKey]*api.Image, limits *importLimits, defaultPlatform api.ImagePlatform) {
 	tags := make(map[manifestKey][]int)
 	ids := make(map[manifestKey][]int)
 	repositories := make(map[repositoryKey]*importRepository)
+	var keys []repositoryKey
 
 	isi.Status.Images = make([]api.ImageImportStatus, len(isi.Spec.Images))
 	for i := range isi.Spec.Images {
@@ -126,8 +212,9 @@ func importImages(ctx gocontext.Context, retriever RepositoryRetriever, isi *api
 		defaultRef := ref.DockerClientDefaults()
 		repoName := defaultRef.RepositoryName()
 		registryURL := defaultRef.RegistryURL()
+		platform := importPlatform(spec.ImportPolicy, defaultPlatform)
 
-		key := repositoryKey{url: *registryURL, name: repoName}
+		key := repositoryKey{url: *registryURL, name: repoName, platform: platform}
 		repo, ok := repositories[key]
 		if !ok {
 			repo = &importRepository{
@@ -135,8 +222,10 @@ func importImages(ctx gocontext.Context, retriever RepositoryRetriever, isi *api
 				Registry: &key.url,
 				Name:     key.name,
 				Insecure: spec.ImportPolicy.Insecure,
+				Platform: platform,
 			}
 			repositories[key] = repo
+			keys = append(keys, key)
 		}
 
 		if len(defaultRef.ID) > 0 {
@@ -163,8 +252,12 @@ func importImages(ctx gocontext.Context, retriever RepositoryRetriever, isi *api
 	}
 
 	// for each repository we found, import all tags and digests
-	for key, repo := range repositories {
-		importRepositoryFromDocker(ctx, retriever, repo, limiter)
+	limits.each(len(keys), func(i int) {
+		importRepositoryFromDocker(ctx, retriever, repositories[keys[i]], limits)
+	})
+	for _, key := range keys {
+		repo := repositories[key]
+		source := importSource(retriever, repo)
 		for _, tag := range repo.Tags {
 			j := manifestKey{repositoryKey: key}
 			j.value = tag.Name
@@ -183,6 +276,7 @@ func importImages(ctx gocontext.Context, retriever RepositoryRetriever, isi *api
 				copied.DockerImageReference = ref.MostSpecific().Exact()
 				image.Tag = tag.Name
 				image.Image = &copied
+				image.ImportSource = source
 				image.Status.Status = unversioned.StatusSuccess
 			}
 		}
@@ -199,20 +293,26 @@ func importImages(ctx gocontext.Context, retriever RepositoryRetriever, isi *api
 				}
 				image := &isi.Status.Images[index]
 				copied := *digest.Image
+				// the image is pulled by the digest the registry served it by, which may be a legacy digest, unless
+				// it was selected from a manifest list
 				ref := repo.Ref
-				ref.Tag, ref.ID = "", copied.Name
+				ref.Tag, ref.ID = "", digest.Name
+				if _, ok := copied.Annotations[api.ImageManifestListAnnotation]; ok {
+					ref.ID = copied.Name
+				}
 				copied.DockerImageReference = ref.MostSpecific().Exact()
 				image.Image = &copied
+				image.ImportSource = source
 				image.Status.Status = unversioned.StatusSuccess
 			}
 		}
 	}
 }
 
-// importFromRepository imports the repository named on the ImageStreamImport, if any, importing up to maximumTags, and reporting
-// status on each image that is attempted to be imported. If the repository cannot be found or tags cannot be retrieved, the repository
-// status field is set.
-func importFromRepository(ctx gocontext.Context, retriever RepositoryRetriever, isi *api.ImageStreamImport, maximumTags int, cache map[manifestKey]*api.Image, limiter util.RateLimiter) {
+// importFromRepository imports the repository named on the ImageStreamImport, if any, importing up to maximumTags of the tags
+// listed within tagListLimits, and reporting status on each image that is attempted to be imported. If the repository cannot be
+// found or tags cannot be retrieved, the repository status field is set.
+func importFromRepository(ctx gocontext.Context, retriever RepositoryRetriever, isi *api.ImageStreamImport, maximumTags int, tagListLimits TagListLimits, cache map[manifestKey]*api.Image, limits *importLimits, defaultPlatform api.ImagePlatform) {
 	if isi.Spec.Repository == nil {
 		return
 	}
@@ -233,15 +333,19 @@ func importFromRepository(ctx gocontext.Context, retriever RepositoryRetriever,
 	repoName := defaultRef.RepositoryName()
 	registryURL := defaultRef.RegistryURL()
 
-	key := repositoryKey{url: *registryURL, name: repoName}
+	platform := importPlatform(spec.ImportPolicy, defaultPlatform)
+	key := repositoryKey{url: *registryURL, name: repoName, platform: platform}
 	repo := &importRepository{
-		Ref:         ref,
-		Registry:    &key.url,
-		Name:        key.name,
-		Insecure:    spec.ImportPolicy.Insecure,
-		MaximumTags: maximumTags,
+		Ref:           ref,
+		Registry:      &key.url,
+		Name:          key.name,
+		Insecure:      spec.ImportPolicy.Insecure,
+		MaximumTags:   maximumTags,
+		TagListLimits: tagListLimits,
+		Platform:      platform,
 	}
-	importRepositoryFromDocker(ctx, retriever, repo, limiter)
+	importRepositoryFromDocker(ctx, retriever, repo, limits)
+	source := importSource(retriever, repo)
 
 	if repo.Err != nil {
 		status.Status = imageImportStatus(repo.Err, "", "repository")
@@ -262,6 +366,7 @@ func importFromRepository(ctx gocontext.Context, retriever RepositoryRetriever,
 		}
 	}
 	status.AdditionalTags = additional
+	status.TagsTruncated = repo.TagsTruncated
 
 	failures := 0
 	status.Status.Status = unversioned.StatusSuccess
@@ -279,6 +384,7 @@ func importFromRepository(ctx gocontext.Context, retriever RepositoryRetriever,
 		copied.DockerImageReference = ref.MostSpecific().Exact()
 		status.Images[i].Tag = tag.Name
 		status.Images[i].Image = &copied
+		status.Images[i].ImportSource = source
 	}
 	if failures > 0 {
 		status.Status.Status = unversioned.StatusFailure
@@ -292,6 +398,20 @@ func importFromRepository(ctx gocontext.Context, retriever RepositoryRetriever,
 	}
 }
 
+// importPlatform returns the platform named by policy, or defaultPlatform.
+func importPlatform(policy api.TagImportPolicy, defaultPlatform api.ImagePlatform) api.ImagePlatform {
+	if len(policy.Platform) == 0 {
+		return defaultPlatform
+	}
+	platform, err := api.ParseImagePlatform(policy.Platform)
+	if err != nil {
+		// the policy is validated, this is only reached by callers that skip validation
+		glog.V(4).Infof("Ignoring the invalid platform of an import policy: %v", err)
+		return defaultPlatform
+	}
+	return platform
+}
+
 func applyErrorToRepository(repository *importRepository, err error) {
 	repository.Err = err
 	for i := range repository.Tags {
@@ -303,12 +423,31 @@ func applyErrorToRepository(repository *importRepository, err error) {
 }
 
 // importRepositoryFromDocker loads the tags and images requested in the passed importRepository, obeying the
-// optional rate limiter.  Errors are set onto the individual tags and digest objects.
-func importRepositoryFromDocker(ctx gocontext.Context, retriever RepositoryRetriever, repository *importRepository, limiter util.RateLimiter) {
+// limits.  Errors are set onto the individual tags and digest objects.
+func importRepositoryFromDocker(ctx gocontext.Context, retriever RepositoryRetriever, repository *importRepository, limits *importLimits) {
 	glog.V(5).Infof("importing remote Docker repository registry=%s repository=%s insecure=%t", repository.Registry, repository.Name, repository.Insecure)
-	// retrieve the repository
-	repo, err := retriever.Repository(ctx, repository.Registry, repository.Name, repository.Insecure)
-	if err != nil {
+	repository.Retrieved = unversioned.Now()
+	var repo distribution.Repository
+	var s distribution.ManifestService
+	var tags []string
+	var repoErr, manifestsErr, tagsErr error
+	limits.do(func() {
+		// retrieve the repository
+		if repo, repoErr = retriever.Repository(ctx, repository.Registry, repository.Name, repository.Insecure); repoErr != nil {
+			return
+		}
+		// get a manifest context
+		if s, manifestsErr = repo.Manifests(ctx); manifestsErr != nil {
+			return
+		}
+		if count := repository.MaximumTags; count > 0 || count == -1 {
+			tags, repository.TagsTruncated, tagsErr = listTags(ctx, repo, s, repository.TagListLimits, func() {
+				limits.accept(repository.Registry)
+			})
+		}
+	})
+
+	if err := repoErr; err != nil {
 		glog.V(5).Infof("unable to access repository %#v: %#v", repository, err)
 		switch {
 		case err == reference.ErrReferenceInvalidFormat:
@@ -316,28 +455,26 @@ func importRepositoryFromDocker(ctx gocontext.Context, retriever RepositoryRetri
 		case isDockerError(err, v2.ErrorCodeNameUnknown):
 			err = kapierrors.NewNotFound(api.Resource("dockerimage"), repository.Ref.Exact())
 		case isDockerError(err, errcode.ErrorCodeUnauthorized):
-			err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
+			err = unauthorizedError(retriever, repository, err)
 		case strings.Contains(err.Error(), "tls: oversized record received with length") && !repository.Insecure:
-			err = kapierrors.NewBadRequest("this repository is HTTP only and requires the insecure flag to import")
+			err = kapierrors.NewBadRequest("this repository is HTTP only and requires the insecure flag, or plain HTTP to be allowed for the registry, to import")
 		case strings.HasSuffix(err.Error(), "no basic auth credentials"):
 			err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q and did not have credentials to the repository", repository.Ref.Exact()))
 		case strings.HasSuffix(err.Error(), "does not support v2 API"):
-			importRepositoryFromDockerV1(ctx, repository, limiter)
+			importRepositoryFromDockerV1(ctx, repository, limits)
 			return
 		}
 		applyErrorToRepository(repository, err)
 		return
 	}
 
-	// get a manifest context
-	s, err := repo.Manifests(ctx)
-	if err != nil {
+	if err := manifestsErr; err != nil {
 		glog.V(5).Infof("unable to access manifests for repository %#v: %#v", repository, err)
 		switch {
 		case isDockerError(err, v2.ErrorCodeNameUnknown):
 			err = kapierrors.NewNotFound(api.Resource("dockerimage"), repository.Ref.Exact())
 		case isDockerError(err, errcode.ErrorCodeUnauthorized):
-			err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
+			err = unauthorizedError(retriever, repository, err)
 		case strings.HasSuffix(err.Error(), "no basic auth credentials"):
 			err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q and did not have credentials to the repository", repository.Ref.Exact()))
 		}
@@ -347,14 +484,13 @@ func importRepositoryFromDocker(ctx gocontext.Context, retriever RepositoryRetri
 
 	// if repository import is requested (MaximumTags), attempt to load the tags, sort them, and request the first N
 	if count := repository.MaximumTags; count > 0 || count == -1 {
-		tags, err := s.Tags()
-		if err != nil {
+		if err := tagsErr; err != nil {
 			glog.V(5).Infof("unable to access tags for repository %#v: %#v", repository, err)
 			switch {
 			case isDockerError(err, v2.ErrorCodeNameUnknown):
 				err = kapierrors.NewNotFound(api.Resource("dockerimage"), repository.Ref.Exact())
 			case isDockerError(err, errcode.ErrorCodeUnauthorized):
-				err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
+				err = unauthorizedError(retriever, repository, err)
 			}
 			repository.Err = err
 			return
@@ -381,18 +517,25 @@ func importRepositoryFromDocker(ctx gocontext.Context, retriever RepositoryRetri
 	}
 
 	// load digests
-	for i := range repository.Digests {
+	limits.each(len(repository.Digests), func(i int) {
 		importDigest := &repository.Digests[i]
 		if importDigest.Err != nil || importDigest.Image != nil {
-			continue
+			return
 		}
 		d, err := digest.ParseDigest(importDigest.Name)
 		if err != nil {
 			importDigest.Err = err
-			continue
+			return
 		}
-		limiter.Accept()
-		m, err := s.Get(d)
+		var m *schema1.SignedManifest
+		limits.do(func() {
+			limits.accept(repository.Registry)
+			if manifests, ok := repo.(manifestRepository); ok {
+				importDigest.Image, err = importManifest(ctx, manifests, repository, importDigest.Name, d, repository.Platform)
+				return
+			}
+			m, err = s.Get(d)
+		})
 		if err != nil {
 			glog.V(5).Infof("unable to access digest %q for repository %#v: %#v", d, repository, err)
 			switch {
@@ -401,31 +544,40 @@ func importRepositoryFromDocker(ctx gocontext.Context, retriever RepositoryRetri
 				ref.Tag, ref.ID = "", importDigest.Name
 				err = kapierrors.NewNotFound(api.Resource("dockerimage"), ref.Exact())
 			case isDockerError(err, errcode.ErrorCodeUnauthorized):
-				err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
+				err = unauthorizedError(retriever, repository, err)
 			case strings.HasSuffix(err.Error(), "no basic auth credentials"):
 				err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
 			}
 			importDigest.Err = err
-			continue
+			return
 		}
-		importDigest.Image, err = schema1ToImage(m, d)
-		if err != nil {
-			importDigest.Err = err
-			continue
+		if m != nil {
+			if importDigest.Image, err = schema1ToImage(m, d); err != nil {
+				importDigest.Err = err
+				return
+			}
 		}
 		if err := api.ImageWithMetadata(importDigest.Image); err != nil {
 			importDigest.Err = err
-			continue
+			return
 		}
-	}
+	})
 
-	for i := range repository.Tags {
+	limits.each(len(repository.Tags), func(i int) {
 		importTag := &repository.Tags[i]
 		if importTag.Err != nil || importTag.Image != nil {
-			continue
+			return
 		}
-		limiter.Accept()
-		m, err := s.GetByTag(importTag.Name)
+		var m *schema1.SignedManifest
+		var err error
+		limits.do(func() {
+			limits.accept(repository.Registry)
+			if manifests, ok := repo.(manifestRepository); ok {
+				importTag.Image, err = importManifest(ctx, manifests, repository, importTag.Name, "", repository.Platform)
+				return
+			}
+			m, err = s.GetByTag(importTag.Name)
+		})
 		if err != nil {
 			glog.V(5).Infof("unable to access tag %q for repository %#v: %#v", importTag.Name, repository, err)
 			switch {
@@ -434,26 +586,41 @@ func importRepositoryFromDocker(ctx gocontext.Context, retriever RepositoryRetri
 				ref.Tag = importTag.Name
 				err = kapierrors.NewNotFound(api.Resource("dockerimage"), ref.Exact())
 			case isDockerError(err, errcode.ErrorCodeUnauthorized):
-				err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
+				err = unauthorizedError(retriever, repository, err)
 			case strings.HasSuffix(err.Error(), "no basic auth credentials"):
 				err = kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
 			}
 			importTag.Err = err
-			continue
+			return
 		}
-		importTag.Image, err = schema1ToImage(m, "")
-		if err != nil {
-			importTag.Err = err
-			continue
+		if m != nil {
+			if importTag.Image, err = schema1ToImage(m, ""); err != nil {
+				importTag.Err = err
+				return
+			}
 		}
 		if err := api.ImageWithMetadata(importTag.Image); err != nil {
 			importTag.Err = err
-			continue
+			return
+		}
+	})
+}
+
+// unauthorizedError returns the error reported when the registry rejects the credentials used for repository,
+// naming the secret they came from, and records the failure.
+func unauthorizedError(retriever RepositoryRetriever, repository *importRepository, err error) error {
+	if recorder, ok := retriever.(AuthFailureRecorder); ok {
+		recorder.RecordAuthFailure(repository.Registry, repository.Name, err)
+	}
+	if source, ok := retriever.(ImportSource); ok {
+		if secret := source.SecretFor(repository.Registry, repository.Name); len(secret) > 0 {
+			return kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q with the credentials of secret %q", repository.Ref.Exact(), secret))
 		}
 	}
+	return kapierrors.NewUnauthorized(fmt.Sprintf("you may not have access to the Docker image %q", repository.Ref.Exact()))
 }
 
-func importRepositoryFromDockerV1(ctx gocontext.Context, repository *importRepository, limiter util.RateLimiter) {
+func importRepositoryFromDockerV1(ctx gocontext.Context, repository *importRepository, limits *importLimits) {
 	value := ctx.Value(ContextKeyV1RegistryClient)
 	if value == nil {
 		err := kapierrors.NewForbidden(api.Resource(""), "", fmt.Errorf("registry %q does not support the v2 Registry API", repository.Registry.Host)).(*kapierrors.StatusError)
@@ -511,7 +678,7 @@ func importRepositoryFromDockerV1(ctx gocontext.Context, repository *importRepos
 		if importDigest.Err != nil || importDigest.Image != nil {
 			continue
 		}
-		limiter.Accept()
+		limits.accept(repository.Registry)
 		image, err := conn.ImageByID(repository.Ref.Namespace, repository.Ref.Name, importDigest.Name)
 		if err != nil {
 			importDigest.Err = err
@@ -530,7 +697,7 @@ func importRepositoryFromDockerV1(ctx gocontext.Context, repository *importRepos
 		if importTag.Err != nil || importTag.Image != nil {
 			continue
 		}
-		limiter.Accept()
+		limits.accept(repository.Registry)
 		image, err := conn.ImageByTag(repository.Ref.Namespace, repository.Ref.Name, importTag.Name)
 		if err != nil {
 			importTag.Err = err
@@ -562,6 +729,8 @@ type importRepository struct {
 	Registry *url.URL
 	Name     string
 	Insecure bool
+	// Platform selects the image imported from manifest lists and image indexes.
+	Platform api.ImagePlatform
 
 	Tags    []importTag
 	Digests []importDigest
@@ -569,6 +738,27 @@ type importRepository struct {
 	MaximumTags    int
 	AdditionalTags []string
 	Err            error
+	// TagListLimits bound the listing of the tags of the repository, and TagsTruncated is set if the listing
+	// stopped at the limit before the last tag.
+	TagListLimits TagListLimits
+	TagsTruncated bool
+
+	// Retrieved is the time the repository was contacted.
+	Retrieved unversioned.Time
+}
+
+// importSource returns where, when, and with the credentials of which secret the images of repository were
+// retrieved.
+func importSource(retriever RepositoryRetriever, repository *importRepository) *api.TagImportSource {
+	endpoint, secret := repository.Registry, ""
+	if source, ok := retriever.(ImportSource); ok {
+		endpoint, secret = source.Endpoint(repository.Registry), source.SecretFor(repository.Registry, repository.Name)
+	}
+	return &api.TagImportSource{
+		Registry:  endpoint.String(),
+		Secret:    secret,
+		Retrieved: repository.Retrieved,
+	}
 }
 
 // repositoryKey is the key used to cache information loaded from a remote Docker repository.
@@ -577,6 +767,8 @@ type repositoryKey struct {
 	url url.URL
 	// The name of the image repository (contains both namespace and path)
 	name string
+	// The platform of the images imported from manifest lists
+	platform api.ImagePlatform
 }
 
 // manifestKey is a key for a map between a Docker image tag or image ID and a retrieved api.Image, used
@@ -611,7 +803,7 @@ func NewContext(transport, insecureTransport http.RoundTripper) Context {
 	return Context{
 		Transport:         transport,
 		InsecureTransport: insecureTransport,
-		Challenges:        auth.NewSimpleChallengeManager(),
+		Challenges:        &lockedChallengeManager{manager: auth.NewSimpleChallengeManager()},
 	}
 }
 
@@ -619,63 +811,211 @@ type Context struct {
 	Transport         http.RoundTripper
 	InsecureTransport http.RoundTripper
 	Challenges        auth.ChallengeManager
+	// RegistryTransports overrides Transport and InsecureTransport for the registries it lists.
+	RegistryTransports dockerregistry.RegistryTransportOptions
+}
+
+// WithRegistryTransportOptions returns a copy of the context that connects to the registries listed in
+// options with a transport tuned by their options, and to other registries with a transport tuned by the default
+// options of options if they differ from the transports of the context.
+func (c Context) WithRegistryTransportOptions(options dockerregistry.RegistryTransportOptions) Context {
+	c.RegistryTransports = options
+	return c
 }
 
 func (c Context) WithCredentials(credentials auth.CredentialStore) RepositoryRetriever {
+	return c.withActions(credentials, "pull")
+}
+
+// WithPushCredentials returns a RepositoryRetriever that requests both pull and push access to the
+// repositories it returns, which allows content to be written to the remote registry.
+func (c Context) WithPushCredentials(credentials auth.CredentialStore) RepositoryRetriever {
+	return c.withActions(credentials, "pull", "push")
+}
+
+func (c Context) withActions(credentials auth.CredentialStore, actions ...string) RepositoryRetriever {
 	return &repositoryRetriever{
 		context:     c,
 		credentials: credentials,
+		actions:     actions,
 
-		pings:    make(map[url.URL]error),
-		redirect: make(map[url.URL]*url.URL),
+		pings:      make(map[url.URL]error),
+		redirect:   make(map[url.URL]*url.URL),
+		transports: make(map[transportKey]http.RoundTripper),
 	}
 }
 
 type repositoryRetriever struct {
 	context     Context
 	credentials auth.CredentialStore
+	actions     []string
 
-	pings    map[url.URL]error
-	redirect map[url.URL]*url.URL
+	// lock guards the state below, since repositories may be retrieved concurrently.
+	lock       sync.Mutex
+	pings      map[url.URL]error
+	redirect   map[url.URL]*url.URL
+	transports map[transportKey]http.RoundTripper
 }
 
-func (r *repositoryRetriever) Repository(ctx gocontext.Context, registry *url.URL, repoName string, insecure bool) (distribution.Repository, error) {
-	t := r.context.Transport
-	if insecure && r.context.InsecureTransport != nil {
-		t = r.context.InsecureTransport
+// transportKey identifies a transport created for a single registry.
+type transportKey struct {
+	host     string
+	insecure bool
+}
+
+// transportFor returns the transport to use when connecting to host.
+func (r *repositoryRetriever) transportFor(host string, insecure bool) http.RoundTripper {
+	key := transportKey{host: host, insecure: insecure}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if t, ok := r.transports[key]; ok {
+		return t
 	}
-	src := *registry
-	// ping the registry to get challenge headers
-	if err, ok := r.pings[src]; ok {
-		if err != nil {
-			return nil, err
-		}
-		if redirect, ok := r.redirect[src]; ok {
-			src = *redirect
-		}
+	var t http.RoundTripper
+	if tuned, ok := r.context.RegistryTransports.Transport(host, 30*time.Second, insecure); ok {
+		t = tuned
 	} else {
-		redirect, err := r.ping(src, insecure, t)
-		r.pings[src] = err
-		if err != nil {
-			return nil, err
-		}
-		if redirect != nil {
-			r.redirect[src] = redirect
-			src = *redirect
+		t = r.context.Transport
+		if insecure && r.context.InsecureTransport != nil {
+			t = r.context.InsecureTransport
 		}
 	}
+	r.transports[key] = t
+	return t
+}
 
+func (r *repositoryRetriever) Repository(ctx gocontext.Context, registry *url.URL, repoName string, insecure bool) (distribution.Repository, error) {
+	t := r.transportFor(registry.Host, insecure)
+	src, err := r.pingOnce(*registry, insecure, t)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := r.credentialsFor(registry, repoName, r.tokenRealms(src))
+	tokenHandler := auth.NewTokenHandler(t, credentials, repoName, r.actions...)
+	if store, ok := credentials.(RefreshTokenStore); ok {
+		tokenHandler = newRefreshTokenHandler(t, store, repoName, r.actions...)
+	}
 	rt := transport.NewTransport(
 		t,
 		// TODO: slightly smarter authorizer that retries unauthenticated requests
 		// TODO: make multiple attempts if the first credential fails
 		auth.NewAuthorizer(
 			r.context.Challenges,
-			auth.NewTokenHandler(t, r.credentials, repoName, "pull"),
-			auth.NewBasicHandler(r.credentials),
+			tokenHandler,
+			auth.NewBasicHandler(credentials),
 		),
 	)
-	return registryclient.NewRepository(context.Context(ctx), repoName, src.String(), rt)
+	if cache := r.context.RegistryTransports.Cache; cache != nil {
+		rt = cache.Wrap(rt, src.Host, repoName, r.cacheCredentials(src, repoName))
+	}
+	repo, err := registryclient.NewRepository(context.Context(ctx), repoName, src.String(), rt)
+	if err != nil {
+		return nil, err
+	}
+	urls, err := v2.NewURLBuilderFromString(src.String())
+	if err != nil {
+		return nil, err
+	}
+	// manifest lists and image indexes are retrieved with the same transport as the rest of the repository
+	return &rawManifestRepository{Repository: repo, client: &http.Client{Transport: rt}, urls: urls, name: repoName}, nil
+}
+
+// cacheCredentials returns the credentials the responses for repository on registry are cached for. They are
+// only loaded if the registry asked for credentials when it was pinged, so that the secrets of anonymous imports
+// are not listed, and they are read from the unscoped credentials so that they are not recorded as presented.
+func (r *repositoryRetriever) cacheCredentials(registry url.URL, repository string) dockerregistry.CredentialsFunc {
+	return func() (string, string) {
+		endpoint := url.URL{Scheme: registry.Scheme, Host: registry.Host, Path: "/v2/"}
+		if challenges, err := r.context.Challenges.GetChallenges(endpoint.String()); err != nil || len(challenges) == 0 || r.credentials == nil {
+			return "", ""
+		}
+		return r.credentials.Basic(&url.URL{Scheme: registry.Scheme, Host: registry.Host, Path: "/" + repository})
+	}
+}
+
+// pingOnce pings registry the first time it is retrieved from, to get its challenge headers, and returns the
+// URL to retrieve content from.
+func (r *repositoryRetriever) pingOnce(registry url.URL, insecure bool, t http.RoundTripper) (url.URL, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if err, ok := r.pings[registry]; ok {
+		if err != nil {
+			return registry, err
+		}
+		if redirect, ok := r.redirect[registry]; ok {
+			return *redirect, nil
+		}
+		return registry, nil
+	}
+	redirect, err := r.ping(registry, insecure, t)
+	r.pings[registry] = err
+	if err != nil {
+		return registry, err
+	}
+	if redirect != nil {
+		r.redirect[registry] = redirect
+		return *redirect, nil
+	}
+	return registry, nil
+}
+
+// Endpoint returns the URL content is retrieved from for registry.
+func (r *repositoryRetriever) Endpoint(registry *url.URL) *url.URL {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if redirect, ok := r.redirect[*registry]; ok {
+		return redirect
+	}
+	return registry
+}
+
+// credentialsFor returns the credentials of the retriever for repository on registry, whose token servers are at
+// realms.
+func (r *repositoryRetriever) credentialsFor(registry *url.URL, repository string, realms []*url.URL) auth.CredentialStore {
+	switch store := r.credentials.(type) {
+	case RealmCredentialStore:
+		return store.ForRepositoryRealms(registry, repository, realms)
+	case RepositoryCredentialStore:
+		return store.ForRepository(registry, repository)
+	}
+	return r.credentials
+}
+
+// tokenRealms returns the token servers registry announced in its challenges when it was pinged.
+func (r *repositoryRetriever) tokenRealms(registry url.URL) []*url.URL {
+	endpoint := url.URL{Scheme: registry.Scheme, Host: registry.Host, Path: "/v2/"}
+	challenges, err := r.context.Challenges.GetChallenges(endpoint.String())
+	if err != nil {
+		return nil
+	}
+	var realms []*url.URL
+	for _, challenge := range challenges {
+		if !strings.EqualFold(challenge.Scheme, "bearer") {
+			continue
+		}
+		if realm, err := url.Parse(challenge.Parameters["realm"]); err == nil && len(realm.Host) > 0 {
+			realms = append(realms, realm)
+		}
+	}
+	return realms
+}
+
+// SecretFor returns the name of the secret whose credentials were presented to registry for repository, if the
+// credentials of the retriever come from secrets.
+func (r *repositoryRetriever) SecretFor(registry *url.URL, repository string) string {
+	if source, ok := r.credentials.(PresentedSecretSource); ok {
+		return source.PresentedSecret(registry, repository)
+	}
+	return ""
+}
+
+// RecordAuthFailure reports the rejected credentials to the credentials of the retriever, if they keep track of
+// them.
+func (r *repositoryRetriever) RecordAuthFailure(registry *url.URL, repository string, err error) {
+	if recorder, ok := r.credentials.(AuthFailureRecorder); ok {
+		recorder.RecordAuthFailure(registry, repository, err)
+	}
 }
 
 func (r *repositoryRetriever) ping(registry url.URL, insecure bool, transport http.RoundTripper) (*url.URL, error) {
@@ -691,7 +1031,7 @@ func (r *repositoryRetriever) ping(registry url.URL, insecure bool, transport ht
 	}
 	resp, err := pingClient.Do(req)
 	if err != nil {
-		if insecure && registry.Scheme == "https" {
+		if (insecure || r.context.RegistryTransports.AllowsHTTP(registry.Host)) && registry.Scheme == "https" {
 			glog.V(5).Infof("Falling back to an HTTP check for an insecure registry %s: %v", registry, err)
 			registry.Scheme = "http"
 			_, nErr := r.ping(registry, true, transport)
@@ -723,23 +1063,11 @@ func schema1ToImage(manifest *schema1.SignedManifest, d digest.Digest) (*api.Ima
 	if err != nil {
 		return nil, err
 	}
-	if len(d) > 0 {
-		dockerImage.ID = d.String()
-	} else {
-		if p, err := manifest.Payload(); err == nil {
-			d, err := digest.FromBytes(p)
-			if err != nil {
-				return nil, fmt.Errorf("unable to create digest from image payload: %v", err)
-			}
-			dockerImage.ID = d.String()
-		} else {
-			d, err := digest.FromBytes(manifest.Raw)
-			if err != nil {
-				return nil, fmt.Errorf("unable to create digest from image bytes: %v", err)
-			}
-			dockerImage.ID = d.String()
-		}
+	canonical, legacy, err := schema1Digests(manifest)
+	if err != nil {
+		return nil, err
 	}
+	dockerImage.ID = canonical.String()
 	image := &api.Image{
 		ObjectMeta: kapi.ObjectMeta{
 			Name: dockerImage.ID,
@@ -749,9 +1077,44 @@ func schema1ToImage(manifest *schema1.SignedManifest, d digest.Digest) (*api.Ima
 		DockerImageMetadataVersion: "1.0",
 	}
 
+	// the digest the image was requested by is the one the registry knows it by, even if it was computed over
+	// the signed manifest
+	legacyDigests := sets.NewString()
+	for _, other := range []digest.Digest{d, legacy} {
+		if len(other) > 0 && other != canonical {
+			legacyDigests.Insert(other.String())
+		}
+	}
+	if legacyDigests.Len() > 0 {
+		image.Annotations = map[string]string{api.ImageLegacyDigestsAnnotation: strings.Join(legacyDigests.List(), ",")}
+	}
 	return image, nil
 }
 
+// schema1Digests returns the canonical digest of a schema1 manifest, computed over its payload with the
+// signatures stripped so that it is the same however and by whomever the manifest was signed, and the legacy
+// digest computed over the manifest as it was served, signatures included. The legacy digest is empty if the
+// manifest is not signed, since an unsigned manifest is its own payload.
+func schema1Digests(manifest *schema1.SignedManifest) (canonical, legacy digest.Digest, err error) {
+	payload, err := manifest.Payload()
+	if err != nil {
+		if canonical, err = digest.FromBytes(manifest.Raw); err != nil {
+			return "", "", fmt.Errorf("unable to create digest from image bytes: %v", err)
+		}
+		return canonical, "", nil
+	}
+	if canonical, err = digest.FromBytes(payload); err != nil {
+		return "", "", fmt.Errorf("unable to create digest from image payload: %v", err)
+	}
+	if legacy, err = digest.FromBytes(manifest.Raw); err != nil {
+		return "", "", fmt.Errorf("unable to create digest from image bytes: %v", err)
+	}
+	if legacy == canonical {
+		legacy = ""
+	}
+	return canonical, legacy, nil
+}
+
 func schema0ToImage(dockerImage *dockerregistry.Image, id string) (*api.Image, error) {
 	var baseImage api.DockerImage
 	if err := kapi.Scheme.Convert(&dockerImage.Image, &baseImage); err != nil {
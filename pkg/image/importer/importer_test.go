@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -17,6 +18,7 @@ import (
 	"github.com/docker/distribution/context"
 	"github.com/docker/distribution/digest"
 	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/registry/api/errcode"
 
 	kapi "k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/unversioned"
@@ -36,6 +38,30 @@ func (r *mockRetriever) Repository(ctx gocontext.Context, registry *url.URL, rep
 	return r.repo, r.err
 }
 
+func (r *mockRetriever) GetManifest(ctx gocontext.Context, registry *url.URL, repoName, reference string, insecure bool) (*schema1.SignedManifest, error) {
+	repo, err := r.Repository(ctx, registry, repoName, insecure)
+	if err != nil {
+		return nil, err
+	}
+	return manifestFromRepository(ctx, repo, reference)
+}
+
+func (r *mockRetriever) ListTags(ctx gocontext.Context, registry *url.URL, repoName string, insecure bool) ([]string, error) {
+	repo, err := r.Repository(ctx, registry, repoName, insecure)
+	if err != nil {
+		return nil, err
+	}
+	return tagsFromRepository(ctx, repo)
+}
+
+func (r *mockRetriever) GetBlobMeta(ctx gocontext.Context, registry *url.URL, repoName string, dgst digest.Digest, insecure bool) (distribution.Descriptor, error) {
+	repo, err := r.Repository(ctx, registry, repoName, insecure)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	return blobMetaFromRepository(ctx, repo, dgst)
+}
+
 type mockRepository struct {
 	repoErr, getErr, getByTagErr, tagsErr, err error
 
@@ -198,6 +224,10 @@ func TestImport(t *testing.T) {
 					if image.Image.DockerImageReference != "test@sha256:958608f8ecc1dc62c93b6c610f3a834dae4220c9642e6e8b4e0f2b3ad7cbd238" {
 						t.Errorf("unexpected ref %d: %#v", i, image.Image.DockerImageReference)
 					}
+					// the source of the import is recorded
+					if source := image.ImportSource; source == nil || source.Registry != "https://registry-1.docker.io" || len(source.Secret) > 0 || source.Retrieved.IsZero() {
+						t.Errorf("unexpected import source %d: %#v", i, image.ImportSource)
+					}
 				}
 			},
 		},
@@ -300,8 +330,25 @@ func TestSchema1ToImage(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if image.DockerImageMetadata.ID != "sha256:test" {
-		t.Errorf("unexpected image: %#v", image.DockerImageMetadata.ID)
+	payload, err := m.Payload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	canonical, _ := digest.FromBytes(payload)
+	legacy, _ := digest.FromBytes(m.Raw)
+	if image.Name != canonical.String() || image.DockerImageMetadata.ID != canonical.String() {
+		t.Errorf("unexpected image: %s %#v", image.Name, image.DockerImageMetadata.ID)
+	}
+	if digests := image.Annotations[api.ImageLegacyDigestsAnnotation]; digests != legacy.String()+",sha256:test" {
+		t.Errorf("unexpected legacy digests: %s", digests)
+	}
+
+	image, err = schema1ToImage(m, canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if image.Name != canonical.String() || image.Annotations[api.ImageLegacyDigestsAnnotation] != legacy.String() {
+		t.Errorf("unexpected image: %s %v", image.Name, image.Annotations)
 	}
 }
 
@@ -352,3 +399,145 @@ func TestDockerV1Fallback(t *testing.T) {
 		t.Errorf("unexpected images: %#v", images)
 	}
 }
+
+func TestPingAllowsHTTPByHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	uri, _ := url.Parse(server.URL)
+	registry := url.URL{Scheme: "https", Host: uri.Host}
+
+	r := &repositoryRetriever{context: NewContext(http.DefaultTransport, http.DefaultTransport)}
+	if _, err := r.ping(registry, false, http.DefaultTransport); err == nil {
+		t.Fatalf("expected HTTP to be refused")
+	}
+
+	r.context = r.context.WithRegistryTransportOptions(dockerregistry.RegistryTransportOptions{
+		Registries: map[string]dockerregistry.TransportOptions{uri.Host: {AllowHTTP: true}},
+	})
+	redirect, err := r.ping(registry, false, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redirect == nil || redirect.Scheme != "http" {
+		t.Errorf("unexpected redirect: %#v", redirect)
+	}
+}
+
+func TestCacheCredentialsOnlyWhenChallenged(t *testing.T) {
+	basic := NewBasicCredentials()
+	basic.Add(&url.URL{Host: "registry.io"}, "user", "pass")
+	r := NewContext(http.DefaultTransport, http.DefaultTransport).WithCredentials(basic).(*repositoryRetriever)
+	registry := url.URL{Scheme: "https", Host: "registry.io"}
+	if username, _ := r.cacheCredentials(registry, "org/repo")(); len(username) > 0 {
+		t.Errorf("credentials were used for a registry that did not ask for them: %s", username)
+	}
+
+	r.context.Challenges.AddResponse(&http.Response{
+		StatusCode: http.StatusUnauthorized,
+		Request:    &http.Request{URL: &url.URL{Scheme: "https", Host: "registry.io", Path: "/v2/"}},
+		Header:     http.Header{"Www-Authenticate": []string{`Basic realm="registry"`}},
+	})
+	if username, _ := r.cacheCredentials(registry, "org/repo")(); username != "user" {
+		t.Errorf("unexpected credentials: %s", username)
+	}
+}
+
+func TestTransportForDefaultOptions(t *testing.T) {
+	r := NewContext(http.DefaultTransport, http.DefaultTransport).WithCredentials(nil).(*repositoryRetriever)
+	if tr := r.transportFor("docker.io", false); tr != http.DefaultTransport {
+		t.Errorf("expected the transport of the context: %#v", tr)
+	}
+
+	r = NewContext(http.DefaultTransport, http.DefaultTransport).WithRegistryTransportOptions(dockerregistry.RegistryTransportOptions{
+		Default: dockerregistry.TransportOptions{MinTLSVersion: tls.VersionTLS12},
+	}).WithCredentials(nil).(*repositoryRetriever)
+	tr, ok := r.transportFor("docker.io", false).(*http.Transport)
+	if !ok || tr == http.DefaultTransport || tr.TLSClientConfig == nil || tr.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("expected a transport tuned by the default options: %#v", tr)
+	}
+}
+
+func TestTransportForSharedAcrossRetrievers(t *testing.T) {
+	options := dockerregistry.RegistryTransportOptions{
+		Registries: map[string]dockerregistry.TransportOptions{"hardened.io": {MinTLSVersion: tls.VersionTLS12}},
+		Transports: dockerregistry.NewTransportCache(),
+	}
+	first := NewContext(http.DefaultTransport, http.DefaultTransport).WithRegistryTransportOptions(options).WithCredentials(nil).(*repositoryRetriever)
+	second := NewContext(http.DefaultTransport, http.DefaultTransport).WithRegistryTransportOptions(options).WithCredentials(nil).(*repositoryRetriever)
+	if first.transportFor("hardened.io", false) != second.transportFor("hardened.io", false) {
+		t.Errorf("expected the tuned transport to be shared by the retrievers")
+	}
+}
+
+type mockImportSource struct {
+	*mockRetriever
+}
+
+func (s mockImportSource) Endpoint(registry *url.URL) *url.URL {
+	return &url.URL{Scheme: "http", Host: registry.Host}
+}
+
+func (s mockImportSource) SecretFor(registry *url.URL, repository string) string {
+	return "pull-secret"
+}
+
+func TestImportProvenance(t *testing.T) {
+	m := &schema1.SignedManifest{Raw: []byte(etcdManifest)}
+	if err := json.Unmarshal([]byte(etcdManifest), m); err != nil {
+		t.Fatal(err)
+	}
+	retriever := mockImportSource{&mockRetriever{repo: &mockRepository{manifest: m, tags: []string{"v1"}}}}
+	isi := &api.ImageStreamImport{
+		Spec: api.ImageStreamImportSpec{
+			Repository: &api.RepositoryImportSpec{
+				From: kapi.ObjectReference{Kind: "DockerImage", Name: "registry.io:5000/test"},
+			},
+		},
+	}
+	im := NewImageStreamImporter(retriever, 5, nil)
+	if err := im.Import(nil, isi); err != nil {
+		t.Fatal(err)
+	}
+	images := isi.Status.Repository.Images
+	if len(images) != 1 || images[0].Image == nil {
+		t.Fatalf("unexpected images: %#v", images)
+	}
+	source := images[0].ImportSource
+	if source == nil || source.Registry != "http://registry.io:5000" || source.Secret != "pull-secret" || source.Retrieved.IsZero() {
+		t.Errorf("unexpected import source: %#v", source)
+	}
+}
+
+type mockFailureRecorder struct {
+	mockImportSource
+	failures []string
+}
+
+func (r *mockFailureRecorder) RecordAuthFailure(registry *url.URL, repository string, err error) {
+	r.failures = append(r.failures, registry.Host+"/"+repository)
+}
+
+func TestImportUnauthorizedNamesSecret(t *testing.T) {
+	retriever := &mockFailureRecorder{mockImportSource: mockImportSource{&mockRetriever{repo: &mockRepository{repoErr: errcode.ErrorCodeUnauthorized}}}}
+	isi := &api.ImageStreamImport{
+		Spec: api.ImageStreamImportSpec{
+			Images: []api.ImageImportSpec{
+				{From: kapi.ObjectReference{Kind: "DockerImage", Name: "registry.io/org/test:v1"}},
+			},
+		},
+	}
+	im := NewImageStreamImporter(retriever, 5, nil)
+	if err := im.Import(nil, isi); err != nil {
+		t.Fatal(err)
+	}
+	status := isi.Status.Images[0].Status
+	if status.Reason != unversioned.StatusReasonUnauthorized || !strings.Contains(status.Message, `with the credentials of secret "pull-secret"`) {
+		t.Errorf("unexpected status: %#v", status)
+	}
+	if !reflect.DeepEqual(retriever.failures, []string{"registry.io/org/test"}) {
+		t.Errorf("unexpected failures: %v", retriever.failures)
+	}
+}
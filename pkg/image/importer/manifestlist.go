@@ -0,0 +1,246 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/registry/api/errcode"
+	"github.com/docker/distribution/registry/api/v2"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	kapierrors "k8s.io/kubernetes/pkg/api/errors"
+
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+// The media types of the manifests the importer understands. The distribution client only decodes schema1
+// manifests, the others are retrieved by the importer itself.
+const (
+	mediaTypeManifestList  = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeImageIndex    = "application/vnd.oci.image.index.v1+json"
+	mediaTypeSchema2       = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCIManifest   = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeSchema1       = "application/vnd.docker.distribution.manifest.v1+json"
+	mediaTypeSignedSchema1 = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+)
+
+// tagMediaTypes are accepted for the manifests of tags. Schema2 and OCI manifests are preferred to schema1, which
+// registries convert newer manifests to for clients that do not accept them, because the converted manifest is
+// signed again on every request and is named by a different digest than the image that was pushed.
+var tagMediaTypes = []string{mediaTypeImageIndex, mediaTypeManifestList, mediaTypeOCIManifest, mediaTypeSchema2, mediaTypeSignedSchema1, mediaTypeSchema1}
+
+// digestMediaTypes are accepted for manifests retrieved by digest, which registries cannot convert.
+var digestMediaTypes = []string{mediaTypeImageIndex, mediaTypeManifestList, mediaTypeOCIManifest, mediaTypeSchema2, mediaTypeSignedSchema1, mediaTypeSchema1}
+
+// manifestRepository is a repository that can retrieve manifests of any media type, including the manifest lists
+// and image indexes of images built for several platforms.
+type manifestRepository interface {
+	distribution.Repository
+	// RawManifest returns the manifest of reference, a tag or a digest, in one of mediaTypes, and the media type
+	// the registry served it as.
+	RawManifest(ctx gocontext.Context, reference string, mediaTypes ...string) (string, []byte, error)
+}
+
+// rawManifestRepository retrieves manifests with the authorized transport of a repository.
+type rawManifestRepository struct {
+	distribution.Repository
+	client *http.Client
+	urls   *v2.URLBuilder
+	name   string
+}
+
+func (r *rawManifestRepository) RawManifest(ctx gocontext.Context, reference string, mediaTypes ...string) (string, []byte, error) {
+	u, err := r.urls.BuildManifestURL(r.name, reference)
+	if err != nil {
+		return "", nil, err
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	req.Header.Set("Accept", strings.Join(mediaTypes, ", "))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", nil, registryResponseError(resp, body, "the manifest")
+	}
+	mediaType := resp.Header.Get("Content-Type")
+	if i := strings.Index(mediaType, ";"); i != -1 {
+		mediaType = mediaType[:i]
+	}
+	return strings.TrimSpace(mediaType), body, nil
+}
+
+// registryResponseError returns the error the registry reported while retrieving what, decoded like the
+// distribution client does so that it is handled the same way.
+func registryResponseError(resp *http.Response, body []byte, what string) error {
+	var errs errcode.Errors
+	if err := json.Unmarshal(body, &errs); err != nil || len(errs) == 0 {
+		if resp.StatusCode == http.StatusUnauthorized {
+			return errcode.ErrorCodeUnauthorized.WithDetail(string(body))
+		}
+		return fmt.Errorf("unexpected status retrieving %s: %s", what, resp.Status)
+	}
+	return errs
+}
+
+// manifestList is a Docker manifest list or an OCI image index.
+type manifestList struct {
+	SchemaVersion int                 `json:"schemaVersion"`
+	MediaType     string              `json:"mediaType,omitempty"`
+	Manifests     []manifestListEntry `json:"manifests"`
+}
+
+type manifestListEntry struct {
+	distribution.Descriptor
+	Platform struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+		Variant      string `json:"variant,omitempty"`
+	} `json:"platform"`
+}
+
+func (e manifestListEntry) platform() api.ImagePlatform {
+	return api.ImagePlatform{OS: e.Platform.OS, Architecture: e.Platform.Architecture, Variant: e.Platform.Variant}
+}
+
+// schema2Manifest is a Docker schema2 or an OCI image manifest.
+type schema2Manifest struct {
+	SchemaVersion int                       `json:"schemaVersion"`
+	MediaType     string                    `json:"mediaType,omitempty"`
+	Config        distribution.Descriptor   `json:"config"`
+	Layers        []distribution.Descriptor `json:"layers"`
+}
+
+// isManifestList returns true if mediaType is the type of a manifest list or an image index.
+func isManifestList(mediaType string) bool {
+	return mediaType == mediaTypeManifestList || mediaType == mediaTypeImageIndex
+}
+
+// importManifest retrieves the image that reference, a tag or a digest of repository, points to. If it points to a
+// manifest list or an image index, the image of platform is imported and annotated with the platforms of the list.
+func importManifest(ctx gocontext.Context, repo manifestRepository, repository *importRepository, reference string, d digest.Digest, platform api.ImagePlatform) (*api.Image, error) {
+	mediaTypes := tagMediaTypes
+	if len(d) > 0 {
+		mediaTypes = digestMediaTypes
+	}
+	mediaType, body, err := repo.RawManifest(ctx, reference, mediaTypes...)
+	if err != nil {
+		return nil, err
+	}
+	if !isManifestList(mediaType) {
+		return manifestToImage(ctx, repo, mediaType, body, d)
+	}
+
+	list := &manifestList{}
+	if err := json.Unmarshal(body, list); err != nil {
+		return nil, fmt.Errorf("unable to read the manifest list: %v", err)
+	}
+	listDigest := d
+	if len(listDigest) == 0 {
+		if listDigest, err = digest.FromBytes(body); err != nil {
+			return nil, err
+		}
+	}
+	platforms := make([]string, 0, len(list.Manifests))
+	var selected *manifestListEntry
+	for i := range list.Manifests {
+		entry := &list.Manifests[i]
+		platforms = append(platforms, entry.platform().String())
+		if selected == nil && platform.Matches(entry.platform()) && !isManifestList(entry.MediaType) {
+			selected = entry
+		}
+	}
+	if selected == nil {
+		ref := repository.Ref
+		ref.Tag, ref.ID = "", ""
+		if len(d) > 0 {
+			ref.ID = reference
+		} else {
+			ref.Tag = reference
+		}
+		err := kapierrors.NewNotFound(api.Resource("dockerimage"), ref.Exact()).(*kapierrors.StatusError)
+		err.ErrStatus.Message = fmt.Sprintf("the image %q has no image for the platform %s, only for %s", ref.Exact(), platform, strings.Join(platforms, ", "))
+		return nil, err
+	}
+
+	mediaType, body, err = repo.RawManifest(ctx, selected.Digest.String(), digestMediaTypes...)
+	if err != nil {
+		return nil, err
+	}
+	image, err := manifestToImage(ctx, repo, mediaType, body, selected.Digest)
+	if err != nil {
+		return nil, err
+	}
+	if image.Annotations == nil {
+		image.Annotations = make(map[string]string)
+	}
+	image.Annotations[api.ImageManifestListAnnotation] = listDigest.String()
+	image.Annotations[api.ImagePlatformsAnnotation] = strings.Join(platforms, ",")
+	return image, nil
+}
+
+// manifestToImage converts a single image manifest of mediaType to an image. d is the digest it was requested by,
+// if any.
+func manifestToImage(ctx gocontext.Context, repo distribution.Repository, mediaType string, body []byte, d digest.Digest) (*api.Image, error) {
+	switch mediaType {
+	case mediaTypeSchema2, mediaTypeOCIManifest:
+		manifest := &schema2Manifest{}
+		if err := json.Unmarshal(body, manifest); err != nil {
+			return nil, fmt.Errorf("unable to read the image manifest: %v", err)
+		}
+		config, err := repo.Blobs(context.Context(ctx)).Get(context.Context(ctx), manifest.Config.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the image configuration %s: %v", manifest.Config.Digest, err)
+		}
+		if len(d) == 0 {
+			if d, err = digest.FromBytes(body); err != nil {
+				return nil, err
+			}
+		}
+		return schema2ToImage(manifest, body, config, d)
+	default:
+		// registries serving schema1 manifests may not set the media type
+		m := &schema1.SignedManifest{}
+		if err := json.Unmarshal(body, m); err != nil {
+			return nil, fmt.Errorf("unable to read the image manifest of type %q: %v", mediaType, err)
+		}
+		m.Raw = body
+		return schema1ToImage(m, d)
+	}
+}
+
+// schema2ToImage converts a schema2 or OCI image manifest and the configuration it references to an image named
+// by the digest of the manifest.
+func schema2ToImage(manifest *schema2Manifest, body, config []byte, d digest.Digest) (*api.Image, error) {
+	dockerImage, err := unmarshalDockerImage(config)
+	if err != nil {
+		return nil, err
+	}
+	dockerImage.ID = d.String()
+	image := &api.Image{
+		ObjectMeta: kapi.ObjectMeta{
+			Name: d.String(),
+		},
+		DockerImageMetadata:        *dockerImage,
+		DockerImageManifest:        string(body),
+		DockerImageMetadataVersion: "1.0",
+	}
+	return image, nil
+}
@@ -0,0 +1,192 @@
+package importer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution/digest"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+// manifestListRegistry serves a manifest list for the latest tag of the test repository, with an image for
+// linux/amd64 and one for linux/arm64/v8.
+type manifestListRegistry struct {
+	manifests map[string]string
+	types     map[string]string
+	blobs     map[string]string
+	// images are the digests of the manifests of each platform.
+	images map[string]digest.Digest
+	list   digest.Digest
+}
+
+func newManifestListRegistry(t *testing.T) *manifestListRegistry {
+	r := &manifestListRegistry{
+		manifests: make(map[string]string),
+		types:     make(map[string]string),
+		blobs:     make(map[string]string),
+		images:    make(map[string]digest.Digest),
+	}
+	entries := []string{}
+	for _, platform := range []struct{ arch, variant string }{{"amd64", ""}, {"arm64", "v8"}} {
+		config := fmt.Sprintf(`{"architecture":%q,"os":"linux","created":"2016-06-01T00:00:00Z","config":{"Env":["ARCH=%s"]}}`, platform.arch, platform.arch)
+		configDigest := mustDigest(t, config)
+		r.blobs[configDigest.String()] = config
+		manifest := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"config":{"mediaType":"application/vnd.docker.container.image.v1+json","size":%d,"digest":%q},"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","size":100,"digest":"sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"}]}`, mediaTypeSchema2, len(config), configDigest)
+		manifestDigest := mustDigest(t, manifest)
+		r.manifests[manifestDigest.String()] = manifest
+		r.types[manifestDigest.String()] = mediaTypeSchema2
+		r.images[platform.arch] = manifestDigest
+		variant := ""
+		if len(platform.variant) > 0 {
+			variant = fmt.Sprintf(`,"variant":%q`, platform.variant)
+		}
+		entries = append(entries, fmt.Sprintf(`{"mediaType":%q,"size":%d,"digest":%q,"platform":{"architecture":%q,"os":"linux"%s}}`, mediaTypeSchema2, len(manifest), manifestDigest, platform.arch, variant))
+	}
+	list := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"manifests":[%s]}`, mediaTypeManifestList, strings.Join(entries, ","))
+	r.list = mustDigest(t, list)
+	r.manifests["latest"] = list
+	r.types["latest"] = mediaTypeManifestList
+	r.manifests[r.list.String()] = list
+	r.types[r.list.String()] = mediaTypeManifestList
+	return r
+}
+
+func mustDigest(t *testing.T, content string) digest.Digest {
+	d, err := digest.FromBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func (r *manifestListRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	switch {
+	case req.URL.Path == "/v2/":
+		w.WriteHeader(http.StatusOK)
+	case strings.HasPrefix(req.URL.Path, "/v2/test/manifests/"):
+		reference := strings.TrimPrefix(req.URL.Path, "/v2/test/manifests/")
+		manifest, ok := r.manifests[reference]
+		if !ok || !strings.Contains(req.Header.Get("Accept"), r.types[reference]) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", r.types[reference])
+		fmt.Fprint(w, manifest)
+	case strings.HasPrefix(req.URL.Path, "/v2/test/blobs/"):
+		blob, ok := r.blobs[strings.TrimPrefix(req.URL.Path, "/v2/test/blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(blob)))
+		if req.Method != "HEAD" {
+			fmt.Fprint(w, blob)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestImportManifestList(t *testing.T) {
+	registry := newManifestListRegistry(t)
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	uri, _ := url.Parse(server.URL)
+
+	testCases := []struct {
+		name            string
+		reference       string
+		platform        string
+		defaultPlatform api.ImagePlatform
+		expect          digest.Digest
+		expectErr       string
+	}{
+		{
+			name:      "default platform",
+			reference: ":latest",
+			expect:    registry.images["amd64"],
+		},
+		{
+			name:      "requested platform",
+			reference: ":latest",
+			platform:  "linux/arm64",
+			expect:    registry.images["arm64"],
+		},
+		{
+			name:            "configured default platform",
+			reference:       ":latest",
+			defaultPlatform: api.ImagePlatform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+			expect:          registry.images["arm64"],
+		},
+		{
+			name:      "list by digest",
+			reference: "@" + registry.list.String(),
+			platform:  "linux/arm64/v8",
+			expect:    registry.images["arm64"],
+		},
+		{
+			name:      "missing platform",
+			reference: ":latest",
+			platform:  "windows/amd64",
+			expectErr: "has no image for the platform windows/amd64, only for linux/amd64, linux/arm64/v8",
+		},
+	}
+	for _, test := range testCases {
+		isi := &api.ImageStreamImport{
+			Spec: api.ImageStreamImportSpec{
+				Images: []api.ImageImportSpec{
+					{
+						From:         kapi.ObjectReference{Kind: "DockerImage", Name: uri.Host + "/test" + test.reference},
+						ImportPolicy: api.TagImportPolicy{Insecure: true, Platform: test.platform},
+					},
+				},
+			},
+		}
+		im := NewImageStreamImporter(NewContext(http.DefaultTransport, http.DefaultTransport).WithCredentials(NoCredentials), 5, nil)
+		if len(test.defaultPlatform.OS) > 0 {
+			im = im.WithDefaultPlatform(test.defaultPlatform)
+		}
+		if err := im.Import(gocontext.Background(), isi); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		status := isi.Status.Images[0]
+		if len(test.expectErr) > 0 {
+			if status.Status.Reason != unversioned.StatusReasonNotFound || !strings.Contains(status.Status.Message, test.expectErr) {
+				t.Errorf("%s: unexpected status: %#v", test.name, status.Status)
+			}
+			continue
+		}
+		if status.Status.Status != unversioned.StatusSuccess || status.Image == nil {
+			t.Errorf("%s: unexpected status: %#v", test.name, status.Status)
+			continue
+		}
+		image := status.Image
+		if image.Name != test.expect.String() {
+			t.Errorf("%s: unexpected image %s, expected %s", test.name, image.Name, test.expect)
+		}
+		if image.Annotations[api.ImageManifestListAnnotation] != registry.list.String() {
+			t.Errorf("%s: unexpected manifest list annotation: %#v", test.name, image.Annotations)
+		}
+		if image.Annotations[api.ImagePlatformsAnnotation] != "linux/amd64,linux/arm64/v8" {
+			t.Errorf("%s: unexpected platforms annotation: %#v", test.name, image.Annotations)
+		}
+		if !strings.HasSuffix(image.DockerImageReference, "@"+test.expect.String()) {
+			t.Errorf("%s: unexpected pull spec: %s", test.name, image.DockerImageReference)
+		}
+		if len(image.DockerImageLayers) != 1 || image.DockerImageMetadata.Size != 100 {
+			t.Errorf("%s: unexpected layers: %#v", test.name, image.DockerImageLayers)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/golang/glog"
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/libtrust"
+
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+// Mirrorer copies the content of an imported image into another repository.
+type Mirrorer interface {
+	// Mirror copies the layers and manifest of image, which must have been loaded with its manifest, from the
+	// repository named by its DockerImageReference into the repository named by to. The source repository is
+	// accessed with source. The returned image is a copy of the input whose DockerImageReference points to the
+	// mirrored copy. If the manifest had to be re-signed for the destination, the name of the returned image is
+	// the new digest.
+	Mirror(ctx gocontext.Context, source RepositoryRetriever, image *api.Image, insecure bool, to api.DockerImageReference) (*api.Image, error)
+}
+
+// ImageMirrorer mirrors images into repositories accessed through a RepositoryRetriever. The destination
+// retriever must be able to push to the target registry.
+type ImageMirrorer struct {
+	destination RepositoryRetriever
+	insecure    bool
+
+	keyOnce sync.Once
+	key     libtrust.PrivateKey
+	keyErr  error
+}
+
+var _ Mirrorer = &ImageMirrorer{}
+
+// NewImageMirrorer creates a mirrorer that writes content through destination. If insecure is true the
+// destination registry may be contacted without verifying certificates or over HTTP.
+func NewImageMirrorer(destination RepositoryRetriever, insecure bool) *ImageMirrorer {
+	return &ImageMirrorer{
+		destination: destination,
+		insecure:    insecure,
+	}
+}
+
+// Mirror implements the Mirrorer interface.
+func (m *ImageMirrorer) Mirror(ctx gocontext.Context, source RepositoryRetriever, image *api.Image, insecure bool, to api.DockerImageReference) (*api.Image, error) {
+	if len(image.DockerImageManifest) == 0 {
+		return nil, fmt.Errorf("image %s has no manifest and cannot be mirrored", image.Name)
+	}
+	from, err := api.ParseDockerImageReference(image.DockerImageReference)
+	if err != nil {
+		return nil, fmt.Errorf("image %s has an invalid pull spec: %v", image.Name, err)
+	}
+	from = from.DockerClientDefaults()
+	to = to.DockerClientDefaults()
+
+	manifest := &schema1.SignedManifest{}
+	if err := json.Unmarshal([]byte(image.DockerImageManifest), manifest); err != nil {
+		return nil, fmt.Errorf("unable to read the manifest of image %s: %v", image.Name, err)
+	}
+	manifest.Raw = []byte(image.DockerImageManifest)
+	if manifest.SchemaVersion != 1 {
+		return nil, fmt.Errorf("image %s has a schema %d manifest, only schema1 manifests can be mirrored", image.Name, manifest.SchemaVersion)
+	}
+
+	src, err := source.Repository(ctx, from.RegistryURL(), from.RepositoryName(), insecure)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := m.destination.Repository(ctx, to.RegistryURL(), to.RepositoryName(), m.insecure)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := copyBlobs(ctx, src, dst, manifest); err != nil {
+		return nil, err
+	}
+
+	// a schema1 manifest is signed for the repository it was pushed to, so it must be re-signed when the
+	// destination repository differs
+	name := image.Name
+	if manifest.Name != to.RepositoryName() {
+		if manifest, err = m.resign(manifest, to.RepositoryName()); err != nil {
+			return nil, err
+		}
+		d, _, err := schema1Digests(manifest)
+		if err != nil {
+			return nil, err
+		}
+		name = d.String()
+	}
+
+	services, err := dst.Manifests(context.Context(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if err := services.Put(manifest); err != nil {
+		return nil, err
+	}
+
+	copied := *image
+	copied.Name = name
+	copied.DockerImageManifest = string(manifest.Raw)
+	ref := to
+	ref.Tag, ref.ID = "", name
+	copied.DockerImageReference = ref.Exact()
+	glog.V(4).Infof("Mirrored image %s to %s", image.DockerImageReference, copied.DockerImageReference)
+	return &copied, nil
+}
+
+// resign signs manifest for the named repository with a key generated for this mirrorer.
+func (m *ImageMirrorer) resign(manifest *schema1.SignedManifest, name string) (*schema1.SignedManifest, error) {
+	m.keyOnce.Do(func() {
+		m.key, m.keyErr = libtrust.GenerateECP256PrivateKey()
+	})
+	if m.keyErr != nil {
+		return nil, fmt.Errorf("unable to generate a key to sign mirrored manifests: %v", m.keyErr)
+	}
+	unsigned := manifest.Manifest
+	unsigned.Name = name
+	return schema1.Sign(&unsigned, m.key)
+}
+
+// copyBlobs streams every layer referenced by manifest that does not already exist in dst from src.
+func copyBlobs(ctx gocontext.Context, src, dst distribution.Repository, manifest *schema1.SignedManifest) error {
+	dctx := context.Context(ctx)
+	from, to := src.Blobs(dctx), dst.Blobs(dctx)
+
+	copied := make(map[digest.Digest]struct{})
+	for _, layer := range manifest.FSLayers {
+		d := layer.BlobSum
+		if _, ok := copied[d]; ok {
+			continue
+		}
+		copied[d] = struct{}{}
+
+		if _, err := to.Stat(dctx, d); err == nil {
+			glog.V(5).Infof("Layer %s already exists in %s", d, dst.Name())
+			continue
+		}
+		desc, err := from.Stat(dctx, d)
+		if err != nil {
+			return fmt.Errorf("unable to find layer %s in %s: %v", d, src.Name(), err)
+		}
+		if err := copyBlob(dctx, from, to, desc); err != nil {
+			return fmt.Errorf("unable to copy layer %s to %s: %v", d, dst.Name(), err)
+		}
+	}
+	return nil
+}
+
+func copyBlob(ctx context.Context, from, to distribution.BlobService, desc distribution.Descriptor) error {
+	r, err := from.Open(ctx, desc.Digest)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	w, err := to.Create(ctx)
+	if err != nil {
+		return err
+	}
+	defer w.Cancel(ctx)
+
+	if _, err := w.ReadFrom(r); err != nil {
+		return err
+	}
+	_, err = w.Commit(ctx, desc)
+	return err
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/distribution/manifest/schema1"
+
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+func TestMirrorRequiresManifest(t *testing.T) {
+	m := NewImageMirrorer(&mockRetriever{}, false)
+	image := &api.Image{DockerImageReference: "test"}
+	image.Name = "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	_, err := m.Mirror(nil, &mockRetriever{}, image, false, api.DockerImageReference{Registry: "localhost:5000", Namespace: "ns", Name: "test"})
+	if err == nil || !strings.Contains(err.Error(), "has no manifest") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMirrorResign(t *testing.T) {
+	manifest := &schema1.SignedManifest{Raw: []byte(etcdManifest)}
+	if err := json.Unmarshal([]byte(etcdManifest), manifest); err != nil {
+		t.Fatal(err)
+	}
+	m := NewImageMirrorer(&mockRetriever{}, false)
+	signed, err := m.resign(manifest, "ns/etcd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.Name != "ns/etcd" {
+		t.Errorf("unexpected name: %s", signed.Name)
+	}
+	if len(signed.FSLayers) != len(manifest.FSLayers) {
+		t.Errorf("layers were not preserved: %#v", signed.FSLayers)
+	}
+	if _, err := signed.Payload(); err != nil {
+		t.Errorf("unexpected payload error: %v", err)
+	}
+}
+
+func TestMirrorResignConcurrently(t *testing.T) {
+	manifest := &schema1.SignedManifest{Raw: []byte(etcdManifest)}
+	if err := json.Unmarshal([]byte(etcdManifest), manifest); err != nil {
+		t.Fatal(err)
+	}
+	m := NewImageMirrorer(&mockRetriever{}, false)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.resign(manifest, "ns/etcd"); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	key := m.key
+	if _, err := m.resign(manifest, "ns/etcd"); err != nil {
+		t.Fatal(err)
+	}
+	if m.key != key {
+		t.Errorf("expected the signing key to be reused")
+	}
+}
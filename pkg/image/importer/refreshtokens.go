@@ -0,0 +1,366 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/docker/distribution/registry/client/auth"
+)
+
+// RefreshTokenClientID identifies the importer to token servers that issue refresh tokens.
+const RefreshTokenClientID = "openshift"
+
+// minimumTokenLifetime is the lifetime of the tokens whose response does not set a longer one.
+const minimumTokenLifetime = 60 * time.Second
+
+// RefreshTokenDuration is how long an unused refresh token is kept. Token servers expire refresh tokens that are
+// not used for a while, and keeping them longer would only hold on to the tokens of credentials that are gone.
+var RefreshTokenDuration = 24 * time.Hour
+
+// MaxRefreshTokens is the number of refresh tokens RefreshTokens keeps. When it is reached, the token that was
+// used least recently is forgotten.
+var MaxRefreshTokens = 1024
+
+// RefreshTokenStore is a credential store that also keeps the OAuth2 refresh tokens token servers issue, so that
+// tokens can be requested with them instead of with the username and password every time. Newer versions of
+// auth.CredentialStore declare the same methods.
+type RefreshTokenStore interface {
+	auth.CredentialStore
+	// RefreshToken returns the refresh token issued by the token server at realm for service, or an empty
+	// string if there is none.
+	RefreshToken(realm *url.URL, service string) string
+	// SetRefreshToken stores the refresh token issued by the token server at realm for service, or forgets it if
+	// token is empty.
+	SetRefreshToken(realm *url.URL, service, token string)
+}
+
+// RefreshTokens holds refresh tokens, so that the stores of many imports can share them. A token is only returned
+// to stores that resolve the credentials it was issued for. Tokens that have not been used for
+// RefreshTokenDuration are forgotten, and at most MaxRefreshTokens are kept.
+type RefreshTokens struct {
+	now func() time.Time
+
+	lock   sync.Mutex
+	tokens map[string]refreshToken
+}
+
+// refreshToken is a refresh token and the time it is forgotten at unless it is used again.
+type refreshToken struct {
+	token   string
+	expires time.Time
+}
+
+// NewRefreshTokens creates an empty set of refresh tokens.
+func NewRefreshTokens() *RefreshTokens {
+	return &RefreshTokens{now: time.Now, tokens: make(map[string]refreshToken)}
+}
+
+// get returns the token stored under key and extends its lifetime, or an empty string if it has expired.
+func (t *RefreshTokens) get(key string) string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.now()
+	token, ok := t.tokens[key]
+	if !ok {
+		return ""
+	}
+	if !now.Before(token.expires) {
+		delete(t.tokens, key)
+		return ""
+	}
+	token.expires = now.Add(RefreshTokenDuration)
+	t.tokens[key] = token
+	return token.token
+}
+
+// set stores token under key, or forgets the token of key if it is empty. Expired tokens are dropped, and the
+// token that was used least recently is forgotten if the set is full.
+func (t *RefreshTokens) set(key, token string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if len(token) == 0 {
+		delete(t.tokens, key)
+		return
+	}
+	now := t.now()
+	for k, v := range t.tokens {
+		if !now.Before(v.expires) {
+			delete(t.tokens, k)
+		}
+	}
+	if _, ok := t.tokens[key]; !ok {
+		for len(t.tokens) > 0 && len(t.tokens) >= MaxRefreshTokens {
+			oldest := ""
+			for k, v := range t.tokens {
+				if len(oldest) == 0 || v.expires.Before(t.tokens[oldest].expires) {
+					oldest = k
+				}
+			}
+			delete(t.tokens, oldest)
+		}
+	}
+	t.tokens[key] = refreshToken{token: token, expires: now.Add(RefreshTokenDuration)}
+}
+
+// NewRefreshTokenCredentials returns a store that provides the credentials of store and keeps the refresh tokens
+// issued for them in tokens.
+func NewRefreshTokenCredentials(store auth.CredentialStore, tokens *RefreshTokens) *RefreshTokenCredentials {
+	return &RefreshTokenCredentials{CredentialStore: store, tokens: tokens}
+}
+
+// RefreshTokenCredentials adds refresh tokens to a credential store. The tokens are keyed by the credentials of
+// the realm, so a token issued for a secret is never used with another one.
+type RefreshTokenCredentials struct {
+	auth.CredentialStore
+	tokens *RefreshTokens
+}
+
+var _ RefreshTokenStore = &RefreshTokenCredentials{}
+
+func (c *RefreshTokenCredentials) RefreshToken(realm *url.URL, service string) string {
+	key, ok := c.key(realm, service)
+	if !ok {
+		return ""
+	}
+	return c.tokens.get(key)
+}
+
+func (c *RefreshTokenCredentials) SetRefreshToken(realm *url.URL, service, token string) {
+	key, ok := c.key(realm, service)
+	if !ok {
+		return
+	}
+	c.tokens.set(key, token)
+}
+
+// PresentedSecret returns the secret whose credentials the wrapped store presented to registry for repository,
+// if it uses secrets.
+func (c *RefreshTokenCredentials) PresentedSecret(registry *url.URL, repository string) string {
+	if source, ok := c.CredentialStore.(PresentedSecretSource); ok {
+		return source.PresentedSecret(registry, repository)
+	}
+	return ""
+}
+
+// ForRepository returns a store with the refresh tokens of c for the credentials the wrapped store provides for
+// repository on registry, if it provides credentials by repository.
+func (c *RefreshTokenCredentials) ForRepository(registry *url.URL, repository string) auth.CredentialStore {
+	if store, ok := c.CredentialStore.(RepositoryCredentialStore); ok {
+		return NewRefreshTokenCredentials(store.ForRepository(registry, repository), c.tokens)
+	}
+	return c
+}
+
+// ForRepositoryRealms returns a store with the refresh tokens of c for the credentials the wrapped store provides
+// for repository on registry and its token servers at realms, if it provides credentials by repository.
+func (c *RefreshTokenCredentials) ForRepositoryRealms(registry *url.URL, repository string, realms []*url.URL) auth.CredentialStore {
+	if store, ok := c.CredentialStore.(RealmCredentialStore); ok {
+		return NewRefreshTokenCredentials(store.ForRepositoryRealms(registry, repository, realms), c.tokens)
+	}
+	return c.ForRepository(registry, repository)
+}
+
+// RecordAuthFailure forwards rejected credentials to the wrapped store, if it keeps track of them.
+func (c *RefreshTokenCredentials) RecordAuthFailure(registry *url.URL, repository string, err error) {
+	if recorder, ok := c.CredentialStore.(AuthFailureRecorder); ok {
+		recorder.RecordAuthFailure(registry, repository, err)
+	}
+}
+
+// Err returns the error of the wrapped store, if it reports errors.
+func (c *RefreshTokenCredentials) Err() error {
+	if errStore, ok := c.CredentialStore.(interface {
+		Err() error
+	}); ok {
+		return errStore.Err()
+	}
+	return nil
+}
+
+// key identifies the refresh token of realm and service issued for the credentials the store has for realm. There
+// is no key without credentials.
+func (c *RefreshTokenCredentials) key(realm *url.URL, service string) (string, bool) {
+	username, password := c.Basic(realm)
+	if len(username) == 0 || len(password) == 0 {
+		return "", false
+	}
+	hash := sha256.Sum256([]byte(username + ":" + password))
+	return strings.Join([]string{realm.Host, realm.Path, service, hex.EncodeToString(hash[:])}, "\x00"), true
+}
+
+// refreshTokenHandler authorizes requests with bearer tokens like the token handler of the Docker distribution
+// client, except that it requests tokens with the OAuth2 password grant and keeps the refresh token the token
+// server returns, so that later tokens are requested with the refresh token. Token servers that reject the OAuth2
+// request, which many that do not support OAuth2 do with a status other than 404, are asked for tokens with basic
+// authentication.
+type refreshTokenHandler struct {
+	transport http.RoundTripper
+	creds     RefreshTokenStore
+	scope     string
+	now       func() time.Time
+
+	lock    sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newRefreshTokenHandler(transport http.RoundTripper, creds RefreshTokenStore, repository string, actions ...string) auth.AuthenticationHandler {
+	return &refreshTokenHandler{
+		transport: transport,
+		creds:     creds,
+		scope:     fmt.Sprintf("repository:%s:%s", repository, strings.Join(actions, ",")),
+		now:       time.Now,
+	}
+}
+
+func (h *refreshTokenHandler) Scheme() string {
+	return "bearer"
+}
+
+func (h *refreshTokenHandler) AuthorizeRequest(req *http.Request, params map[string]string) error {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(h.token) == 0 || !h.now().Before(h.expires) {
+		token, err := h.fetchToken(params)
+		if err != nil {
+			return err
+		}
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		if lifetime < minimumTokenLifetime {
+			lifetime = minimumTokenLifetime
+		}
+		issued := token.IssuedAt
+		if issued.IsZero() {
+			issued = h.now()
+		}
+		h.token, h.expires = token.Token, issued.Add(lifetime)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", h.token))
+	return nil
+}
+
+type refreshTokenResponse struct {
+	Token        string    `json:"token"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresIn    int       `json:"expires_in"`
+	IssuedAt     time.Time `json:"issued_at"`
+}
+
+// errOAuthUnsupported is returned by postToken when the token server rejects the OAuth2 request with any status.
+var errOAuthUnsupported = errors.New("the token server does not support OAuth2")
+
+func (h *refreshTokenHandler) fetchToken(params map[string]string) (*refreshTokenResponse, error) {
+	realm, ok := params["realm"]
+	if !ok {
+		return nil, errors.New("no realm specified for token auth challenge")
+	}
+	realmURL, err := url.Parse(realm)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token auth challenge realm: %v", err)
+	}
+	service := params["service"]
+
+	if refresh := h.creds.RefreshToken(realmURL, service); len(refresh) > 0 {
+		token, err := h.postToken(realmURL, service, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refresh}})
+		if err == nil {
+			return token, nil
+		}
+		// the refresh token may have expired or been revoked, the username and password are tried again
+		glog.V(5).Infof("Unable to get a token for %s with a refresh token: %v", realmURL, err)
+		h.creds.SetRefreshToken(realmURL, service, "")
+	}
+
+	username, password := h.creds.Basic(realmURL)
+	if len(username) > 0 && len(password) > 0 {
+		form := url.Values{
+			"grant_type":  {"password"},
+			"username":    {username},
+			"password":    {password},
+			"access_type": {"offline"},
+		}
+		token, err := h.postToken(realmURL, service, form)
+		if err != errOAuthUnsupported {
+			return token, err
+		}
+	}
+	return h.getToken(realmURL, service, username, password)
+}
+
+// postToken requests a token with the OAuth2 grant in form, and keeps the refresh token of the response.
+func (h *refreshTokenHandler) postToken(realm *url.URL, service string, form url.Values) (*refreshTokenResponse, error) {
+	form.Set("service", service)
+	form.Set("scope", h.scope)
+	form.Set("client_id", RefreshTokenClientID)
+	req, err := http.NewRequest("POST", realm.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	token, status, err := h.do(req)
+	if status != 0 && (status < 200 || status >= 300) {
+		glog.V(5).Infof("The token server %s rejected the OAuth2 request: %v", realm, err)
+		return nil, errOAuthUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(token.RefreshToken) > 0 {
+		h.creds.SetRefreshToken(realm, service, token.RefreshToken)
+	}
+	return token, nil
+}
+
+// getToken requests a token like the Docker distribution client, with basic authentication if there are
+// credentials.
+func (h *refreshTokenHandler) getToken(realm *url.URL, service, username, password string) (*refreshTokenResponse, error) {
+	req, err := http.NewRequest("GET", realm.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	query := req.URL.Query()
+	if len(service) > 0 {
+		query.Add("service", service)
+	}
+	query.Add("scope", h.scope)
+	if len(username) > 0 && len(password) > 0 {
+		query.Add("account", username)
+		req.SetBasicAuth(username, password)
+	}
+	req.URL.RawQuery = query.Encode()
+	token, _, err := h.do(req)
+	return token, err
+}
+
+func (h *refreshTokenHandler) do(req *http.Request) (*refreshTokenResponse, int, error) {
+	client := &http.Client{Transport: h.transport, Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, resp.StatusCode, fmt.Errorf("token auth attempt for registry: %s request failed with status: %d %s", req.URL, resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	token := &refreshTokenResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(token); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("unable to decode token response: %v", err)
+	}
+	if len(token.AccessToken) > 0 {
+		token.Token = token.AccessToken
+	}
+	if len(token.Token) == 0 {
+		return nil, resp.StatusCode, errors.New("authorization server did not include a token in the response")
+	}
+	return token, resp.StatusCode, nil
+}
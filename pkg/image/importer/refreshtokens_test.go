@@ -0,0 +1,137 @@
+package importer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestRefreshTokenHandler(t *testing.T) {
+	passwordGrants, refreshGrants, gets := 0, 0, 0
+	unsupported, revoked := 0, false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET":
+			gets++
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" || r.URL.Query().Get("account") != "user" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"token":"basic"}`)
+		case unsupported != 0:
+			w.WriteHeader(unsupported)
+		case r.PostFormValue("scope") != "repository:library/busybox:pull" || r.PostFormValue("service") != "registry" || r.PostFormValue("client_id") != RefreshTokenClientID:
+			w.WriteHeader(http.StatusBadRequest)
+		case r.PostFormValue("grant_type") == "password" && r.PostFormValue("username") == "user" && r.PostFormValue("password") == "secret":
+			passwordGrants++
+			fmt.Fprintf(w, `{"access_token":"password-%d","refresh_token":"refresh-%d","expires_in":300}`, passwordGrants, passwordGrants)
+		case r.PostFormValue("grant_type") == "refresh_token" && r.PostFormValue("refresh_token") == fmt.Sprintf("refresh-%d", passwordGrants) && !revoked:
+			refreshGrants++
+			fmt.Fprintf(w, `{"access_token":"refreshed-%d"}`, refreshGrants)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	realm, _ := url.Parse(server.URL + "/token")
+	params := map[string]string{"realm": realm.String(), "service": "registry"}
+
+	basic := NewBasicCredentials()
+	basic.Add(&url.URL{Host: realm.Host}, "user", "secret")
+	tokens := NewRefreshTokens()
+	authorize := func(creds RefreshTokenStore) string {
+		req, _ := http.NewRequest("GET", "https://registry.example.com/v2/", nil)
+		if err := newRefreshTokenHandler(http.DefaultTransport, creds, "library/busybox", "pull").AuthorizeRequest(req, params); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return req.Header.Get("Authorization")
+	}
+
+	if header := authorize(NewRefreshTokenCredentials(basic, tokens)); header != "Bearer password-1" {
+		t.Errorf("unexpected header: %s", header)
+	}
+	// the refresh token is reused by other stores with the same credentials
+	if header := authorize(NewRefreshTokenCredentials(basic, tokens)); header != "Bearer refreshed-1" {
+		t.Errorf("unexpected header: %s", header)
+	}
+	other := NewBasicCredentials()
+	other.Add(&url.URL{Host: realm.Host}, "user", "other")
+	if token := NewRefreshTokenCredentials(other, tokens).RefreshToken(realm, "registry"); len(token) != 0 {
+		t.Errorf("expected no refresh token for other credentials, got %s", token)
+	}
+	if token := NewRefreshTokenCredentials(NoCredentials, tokens).RefreshToken(realm, "registry"); len(token) != 0 {
+		t.Errorf("expected no refresh token without credentials, got %s", token)
+	}
+
+	// a revoked refresh token is replaced by asking with the password again
+	revoked = true
+	if header := authorize(NewRefreshTokenCredentials(basic, tokens)); header != "Bearer password-2" {
+		t.Errorf("unexpected header: %s", header)
+	}
+	if token := NewRefreshTokenCredentials(basic, tokens).RefreshToken(realm, "registry"); token != "refresh-2" {
+		t.Errorf("unexpected refresh token: %s", token)
+	}
+
+	// token servers that reject OAuth2 requests with any status are asked with basic authentication
+	for _, status := range []int{http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError} {
+		unsupported = status
+		if header := authorize(NewRefreshTokenCredentials(basic, NewRefreshTokens())); header != "Bearer basic" {
+			t.Errorf("%d: unexpected header: %s", status, header)
+		}
+	}
+	if passwordGrants != 2 || refreshGrants != 1 || gets != 5 {
+		t.Errorf("unexpected requests: %d password grants, %d refresh grants, %d gets", passwordGrants, refreshGrants, gets)
+	}
+}
+
+func TestRefreshTokensExpire(t *testing.T) {
+	defer func(max int) { MaxRefreshTokens = max }(MaxRefreshTokens)
+	MaxRefreshTokens = 2
+	tokens := NewRefreshTokens()
+	now := time.Unix(0, 0)
+	tokens.now = func() time.Time { return now }
+
+	tokens.set("a", "token-a")
+	now = now.Add(time.Minute)
+	tokens.set("b", "token-b")
+	now = now.Add(time.Minute)
+	// using a keeps it, so b is the least recently used token when c is added
+	if token := tokens.get("a"); token != "token-a" {
+		t.Errorf("unexpected token: %s", token)
+	}
+	tokens.set("c", "token-c")
+	if len(tokens.tokens) != 2 || tokens.get("b") != "" || tokens.get("a") != "token-a" || tokens.get("c") != "token-c" {
+		t.Errorf("unexpected tokens: %#v", tokens.tokens)
+	}
+
+	// tokens that are not used for RefreshTokenDuration are forgotten
+	now = now.Add(RefreshTokenDuration)
+	if token := tokens.get("a"); token != "" {
+		t.Errorf("expected the token to expire, got %s", token)
+	}
+	tokens.set("d", "token-d")
+	if len(tokens.tokens) != 1 || tokens.get("d") != "token-d" {
+		t.Errorf("unexpected tokens: %#v", tokens.tokens)
+	}
+}
+
+func TestRefreshTokenCredentialsForwards(t *testing.T) {
+	secrets := NewLazyCredentialsForSecrets(func() ([]kapi.Secret, error) {
+		return nil, fmt.Errorf("unable to list secrets")
+	})
+	creds := NewRefreshTokenCredentials(secrets, NewRefreshTokens())
+	if u, p := creds.Basic(&url.URL{Host: "registry.example.com"}); u != "" || p != "" {
+		t.Errorf("unexpected credentials: %s %s", u, p)
+	}
+	if err := creds.Err(); err == nil || err.Error() != "unable to list secrets" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if secret := creds.PresentedSecret(&url.URL{Host: "registry.example.com"}, "org/repo"); len(secret) != 0 {
+		t.Errorf("unexpected secret: %s", secret)
+	}
+}
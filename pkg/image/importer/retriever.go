@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"net/http"
+	"net/url"
+
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/distribution/registry/client/auth"
+)
+
+// NewRepositoryRetriever returns a RepositoryRetriever that uses transport for secure connections, insecureTransport
+// (if not nil) for registries that are flagged insecure, and credentials to authenticate to the remote registry.
+// Credentials may be nil, in which case no credentials are offered.
+func NewRepositoryRetriever(transport, insecureTransport http.RoundTripper, credentials auth.CredentialStore) RepositoryRetriever {
+	if credentials == nil {
+		credentials = NoCredentials
+	}
+	return NewContext(transport, insecureTransport).WithCredentials(credentials)
+}
+
+// GetManifest returns the manifest identified by reference (a tag or a digest) from the remote repository.
+func (r *repositoryRetriever) GetManifest(ctx gocontext.Context, registry *url.URL, repoName, reference string, insecure bool) (*schema1.SignedManifest, error) {
+	repo, err := r.Repository(ctx, registry, repoName, insecure)
+	if err != nil {
+		return nil, err
+	}
+	return manifestFromRepository(ctx, repo, reference)
+}
+
+// ListTags returns the tags of the remote repository.
+func (r *repositoryRetriever) ListTags(ctx gocontext.Context, registry *url.URL, repoName string, insecure bool) ([]string, error) {
+	repo, err := r.Repository(ctx, registry, repoName, insecure)
+	if err != nil {
+		return nil, err
+	}
+	return tagsFromRepository(ctx, repo)
+}
+
+// GetBlobMeta returns the descriptor of a blob in the remote repository.
+func (r *repositoryRetriever) GetBlobMeta(ctx gocontext.Context, registry *url.URL, repoName string, dgst digest.Digest, insecure bool) (distribution.Descriptor, error) {
+	repo, err := r.Repository(ctx, registry, repoName, insecure)
+	if err != nil {
+		return distribution.Descriptor{}, err
+	}
+	return blobMetaFromRepository(ctx, repo, dgst)
+}
+
+// manifestFromRepository loads a manifest by digest if reference parses as one, and by tag otherwise.
+func manifestFromRepository(ctx gocontext.Context, repo distribution.Repository, reference string) (*schema1.SignedManifest, error) {
+	s, err := repo.Manifests(context.Context(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if d, err := digest.ParseDigest(reference); err == nil {
+		return s.Get(d)
+	}
+	return s.GetByTag(reference)
+}
+
+func tagsFromRepository(ctx gocontext.Context, repo distribution.Repository) ([]string, error) {
+	s, err := repo.Manifests(context.Context(ctx))
+	if err != nil {
+		return nil, err
+	}
+	return s.Tags()
+}
+
+func blobMetaFromRepository(ctx gocontext.Context, repo distribution.Repository, dgst digest.Digest) (distribution.Descriptor, error) {
+	return repo.Blobs(context.Context(ctx)).Stat(context.Context(ctx), dgst)
+}
@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"net/url"
+	"reflect"
+	"sort"
+	"testing"
+
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution/digest"
+
+	registrytest "github.com/openshift/origin/pkg/image/importer/test"
+)
+
+// newTestRetriever returns a retriever for registry and the URL of the registry.
+func newTestRetriever(registry *registrytest.Registry) (RepositoryRetriever, *url.URL) {
+	return NewRepositoryRetriever(registry.Transport(), registry.Transport(), nil), &url.URL{Scheme: "https", Host: registry.Host()}
+}
+
+func TestRetrieverGetManifest(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.Start()
+	defer registry.Close()
+	latest := addSchema1(t, registry, "test/repo", "latest")
+	retriever, u := newTestRetriever(registry)
+	ctx := gocontext.Background()
+
+	m, err := retriever.GetManifest(ctx, u, "test/repo", "latest", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Raw, latest.Content) {
+		t.Errorf("unexpected manifest: %s", m.Raw)
+	}
+
+	m, err = retriever.GetManifest(ctx, u, "test/repo", latest.Digest.String(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m.Raw, latest.Content) {
+		t.Errorf("unexpected manifest: %s", m.Raw)
+	}
+	requests := registry.Requests()
+	if last := requests[len(requests)-1]; last != "GET /v2/test/repo/manifests/"+latest.Digest.String() {
+		t.Errorf("expected the manifest to be requested by digest: %v", requests)
+	}
+
+	if _, err := retriever.GetManifest(ctx, u, "test/repo", "missing", false); err == nil {
+		t.Errorf("expected an error for a missing tag")
+	}
+}
+
+func TestRetrieverListTags(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.Start()
+	defer registry.Close()
+	for _, tag := range []string{"v1", "latest"} {
+		addSchema1(t, registry, "test/repo", tag)
+	}
+	retriever, u := newTestRetriever(registry)
+
+	tags, err := retriever.ListTags(gocontext.Background(), u, "test/repo", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(tags)
+	if !reflect.DeepEqual(tags, []string{"latest", "v1"}) {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+	if _, err := retriever.ListTags(gocontext.Background(), u, "test/missing", false); err == nil {
+		t.Errorf("expected an error for a missing repository")
+	}
+}
+
+func TestRetrieverGetBlobMeta(t *testing.T) {
+	registry := registrytest.NewRegistry()
+	registry.Start()
+	defer registry.Close()
+	content := []byte("layer")
+	d := registry.AddBlob("test/repo", content)
+	retriever, u := newTestRetriever(registry)
+
+	desc, err := retriever.GetBlobMeta(gocontext.Background(), u, "test/repo", d, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc.Digest != d || desc.Size != int64(len(content)) {
+		t.Errorf("unexpected descriptor: %#v", desc)
+	}
+	missing, _ := digest.FromBytes([]byte("missing"))
+	if _, err := retriever.GetBlobMeta(gocontext.Background(), u, "test/repo", missing, false); err == nil {
+		t.Errorf("expected an error for a missing blob")
+	}
+}
@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/golang/glog"
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution"
+)
+
+// TagListLimits control how the tags of a repository are listed during an import, so that repositories with
+// tens of thousands of tags are listed a page at a time and up to a limit instead of in a single request.
+type TagListLimits struct {
+	// PageSize is the number of tags requested at a time with the n parameter of the registry tag list. Zero
+	// lists all the tags in one request.
+	PageSize int
+	// MaxTags is the largest number of tags listed. The listing of repositories with more tags is truncated,
+	// which is recorded on the repository import status. Zero does not limit the listing.
+	MaxTags int
+	// Retries is the number of times a page that could not be retrieved is requested again. The listing resumes
+	// after the last tag received, with the last parameter of the registry tag list.
+	Retries int
+}
+
+// DefaultTagListLimits list tags a thousand at a time, up to ten thousand.
+var DefaultTagListLimits = TagListLimits{PageSize: 1000, MaxTags: 10000, Retries: 2}
+
+// pagedTagRepository is a repository that can list its tags a page at a time.
+type pagedTagRepository interface {
+	// TagPage returns up to n of the tags that sort after last, the value of last that continues the listing,
+	// and whether the registry has more tags.
+	TagPage(ctx gocontext.Context, n int, last string) (tags []string, next string, more bool, err error)
+}
+
+func (r *rawManifestRepository) TagPage(ctx gocontext.Context, n int, last string) ([]string, string, bool, error) {
+	u, err := r.urls.BuildTagsURL(r.name)
+	if err != nil {
+		return nil, "", false, err
+	}
+	query := url.Values{}
+	if n > 0 {
+		query.Set("n", strconv.Itoa(n))
+	}
+	if len(last) > 0 {
+		query.Set("last", last)
+	}
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	resp, err := r.client.Get(u)
+	if err != nil {
+		return nil, "", false, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", false, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, "", false, registryResponseError(resp, body, "the tags")
+	}
+	page := struct {
+		Tags []string `json:"tags"`
+	}{}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, "", false, err
+	}
+	next, more := nextTagPage(resp.Header)
+	if more && len(next) == 0 && len(page.Tags) > 0 {
+		next = page.Tags[len(page.Tags)-1]
+	}
+	return page.Tags, next, more, nil
+}
+
+// nextTagPage returns the last parameter of the next page of a tag list, named by the Link header of the
+// response, and whether there is a next page.
+func nextTagPage(header http.Header) (string, bool) {
+	for _, link := range header["Link"] {
+		for _, value := range strings.Split(link, ",") {
+			parts := strings.Split(value, ";")
+			if len(parts) < 2 {
+				continue
+			}
+			next := false
+			for _, param := range parts[1:] {
+				if p := strings.Replace(strings.TrimSpace(param), " ", "", -1); p == `rel="next"` || p == "rel=next" {
+					next = true
+				}
+			}
+			if !next {
+				continue
+			}
+			target := strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			if u, err := url.Parse(target); err == nil {
+				return u.Query().Get("last"), true
+			}
+			return "", true
+		}
+	}
+	return "", false
+}
+
+// listTags lists the tags of repo within limits, and returns whether the listing was truncated. Repositories
+// that cannot list their tags a page at a time are listed with s. before is called ahead of every request.
+func listTags(ctx gocontext.Context, repo distribution.Repository, s distribution.ManifestService, limits TagListLimits, before func()) ([]string, bool, error) {
+	pager, ok := repo.(pagedTagRepository)
+	if !ok || limits.PageSize <= 0 {
+		before()
+		tags, err := s.Tags()
+		if err != nil {
+			return nil, false, err
+		}
+		if limits.MaxTags > 0 && len(tags) > limits.MaxTags {
+			return tags[:limits.MaxTags], true, nil
+		}
+		return tags, false, nil
+	}
+
+	tags := []string{}
+	last := ""
+	failures := 0
+	for {
+		n := limits.PageSize
+		if limits.MaxTags > 0 && limits.MaxTags-len(tags) < n {
+			n = limits.MaxTags - len(tags)
+		}
+		before()
+		page, next, more, err := pager.TagPage(ctx, n, last)
+		if err != nil {
+			if failures < limits.Retries {
+				failures++
+				glog.V(4).Infof("Retrying the tag list of %s after %q: %v", repo.Name(), last, err)
+				continue
+			}
+			return nil, false, err
+		}
+		failures = 0
+		tags = append(tags, page...)
+		if limits.MaxTags > 0 && len(tags) >= limits.MaxTags {
+			truncated := more || len(tags) > limits.MaxTags
+			return tags[:limits.MaxTags], truncated, nil
+		}
+		if !more || len(page) == 0 || next == last {
+			return tags, false, nil
+		}
+		last = next
+	}
+}
@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"testing"
+
+	gocontext "golang.org/x/net/context"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+// pagedTagRegistry lists the tags of the test repository a page at a time, and fails the requests that continue
+// after the tags in failAfter once.
+type pagedTagRegistry struct {
+	tags      []string
+	failAfter map[string]bool
+	requests  []string
+}
+
+func newPagedTagRegistry(count int) *pagedTagRegistry {
+	r := &pagedTagRegistry{failAfter: make(map[string]bool)}
+	for i := 0; i < count; i++ {
+		r.tags = append(r.tags, fmt.Sprintf("v%03d", i))
+	}
+	return r
+}
+
+func (r *pagedTagRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	switch req.URL.Path {
+	case "/v2/":
+		w.WriteHeader(http.StatusOK)
+	case "/v2/test/tags/list":
+		query := req.URL.Query()
+		r.requests = append(r.requests, query.Encode())
+		last := query.Get("last")
+		if r.failAfter[last] {
+			delete(r.failAfter, last)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		start := sort.SearchStrings(r.tags, last)
+		if start < len(r.tags) && r.tags[start] == last {
+			start++
+		}
+		end := len(r.tags)
+		if n, err := strconv.Atoi(query.Get("n")); err == nil && start+n < end {
+			end = start + n
+			w.Header().Set("Link", fmt.Sprintf(`</v2/test/tags/list?last=%s&n=%d>; rel="next"`, r.tags[end-1], n))
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "test", "tags": r.tags[start:end]})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestListTagPages(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		limits    TagListLimits
+		failAfter []string
+		expect    int
+		truncated bool
+		requests  int
+		expectErr bool
+	}{
+		{name: "single page", count: 25, limits: TagListLimits{}, expect: 25, requests: 1},
+		{name: "pages", count: 25, limits: TagListLimits{PageSize: 10}, expect: 25, requests: 3},
+		{name: "exact pages", count: 20, limits: TagListLimits{PageSize: 10, MaxTags: 20}, expect: 20, requests: 2},
+		{name: "truncated", count: 25, limits: TagListLimits{PageSize: 10, MaxTags: 15}, expect: 15, truncated: true, requests: 2},
+		{name: "truncated single page", count: 25, limits: TagListLimits{MaxTags: 15}, expect: 15, truncated: true, requests: 1},
+		{name: "resumed", count: 25, limits: TagListLimits{PageSize: 10, Retries: 1}, failAfter: []string{"v009"}, expect: 25, requests: 4},
+		{name: "retries exhausted", count: 25, limits: TagListLimits{PageSize: 10}, failAfter: []string{"v009"}, expectErr: true, requests: 2},
+	}
+	for _, test := range tests {
+		registry := newPagedTagRegistry(test.count)
+		for _, last := range test.failAfter {
+			registry.failAfter[last] = true
+		}
+		server := httptest.NewServer(registry)
+		uri, _ := url.Parse(server.URL)
+
+		retriever := NewContext(http.DefaultTransport, http.DefaultTransport).WithCredentials(NoCredentials)
+		repo, err := retriever.Repository(gocontext.Background(), uri, "test", true)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		s, err := repo.Manifests(gocontext.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		calls := 0
+		tags, truncated, err := listTags(gocontext.Background(), repo, s, test.limits, func() { calls++ })
+		server.Close()
+
+		if (err != nil) != test.expectErr {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		}
+		if len(tags) != test.expect || truncated != test.truncated {
+			t.Errorf("%s: unexpected %d tags, truncated %t", test.name, len(tags), truncated)
+		}
+		for i, tag := range tags {
+			if tag != registry.tags[i] {
+				t.Errorf("%s: unexpected tag %d: %s", test.name, i, tag)
+				break
+			}
+		}
+		if len(registry.requests) != test.requests || calls != test.requests {
+			t.Errorf("%s: unexpected requests %d (%d calls): %v", test.name, len(registry.requests), calls, registry.requests)
+		}
+	}
+}
+
+func TestNextTagPage(t *testing.T) {
+	tests := []struct {
+		link string
+		next string
+		more bool
+	}{
+		{link: ""},
+		{link: `</v2/test/tags/list?n=10&last=v009>; rel="next"`, next: "v009", more: true},
+		{link: `</v2/test/tags/list?n=10>; rel=next`, more: true},
+		{link: `</v2/test/tags/list?n=10&last=v009>; rel="prev"`},
+	}
+	for _, test := range tests {
+		header := http.Header{}
+		if len(test.link) > 0 {
+			header.Set("Link", test.link)
+		}
+		next, more := nextTagPage(header)
+		if next != test.next || more != test.more {
+			t.Errorf("%q: unexpected %q %t", test.link, next, more)
+		}
+	}
+}
+
+func TestImportTruncatedRepository(t *testing.T) {
+	registry := newPagedTagRegistry(30)
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	uri, _ := url.Parse(server.URL)
+
+	isi := &api.ImageStreamImport{
+		Spec: api.ImageStreamImportSpec{
+			Repository: &api.RepositoryImportSpec{
+				From:         kapi.ObjectReference{Kind: "DockerImage", Name: uri.Host + "/test"},
+				ImportPolicy: api.TagImportPolicy{Insecure: true},
+			},
+		},
+	}
+	im := NewImageStreamImporter(NewContext(http.DefaultTransport, http.DefaultTransport).WithCredentials(NoCredentials), 5, nil)
+	im = im.WithTagListLimits(TagListLimits{PageSize: 10, MaxTags: 20})
+	if err := im.Import(gocontext.Background(), isi); err != nil {
+		t.Fatal(err)
+	}
+	status := isi.Status.Repository
+	if !status.TagsTruncated {
+		t.Errorf("unexpected status: %#v", status)
+	}
+	// the registry serves no manifests, so the tags that are imported fail
+	if len(status.Images) != 5 || status.Status.Status != unversioned.StatusFailure {
+		t.Errorf("unexpected images: %#v", status.Images)
+	}
+	if len(status.AdditionalTags) != 15 {
+		t.Errorf("unexpected additional tags: %v", status.AdditionalTags)
+	}
+}
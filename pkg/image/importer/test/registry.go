@@ -0,0 +1,430 @@
+// Package test provides a fake Docker v2 registry for exercising the image importer and registry credentials
+// without an external registry.
+package test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/docker/distribution/digest"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/distribution/manifest/schema1"
+	"github.com/docker/libtrust"
+)
+
+// The media types of the manifests a Registry can serve.
+const (
+	MediaTypeSchema1      = "application/vnd.docker.distribution.manifest.v1+prettyjws"
+	MediaTypeSchema2      = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest  = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeImageConfig  = "application/vnd.docker.container.image.v1+json"
+	MediaTypeLayer        = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+// Auth is the authentication a Registry requires.
+type Auth int
+
+const (
+	// AuthNone serves all requests without credentials.
+	AuthNone Auth = iota
+	// AuthBasic challenges for, and requires, basic credentials on every request.
+	AuthBasic
+	// AuthToken challenges for a bearer token, which is issued by the registry and grants access to clients that
+	// presented basic credentials.
+	AuthToken
+)
+
+// Manifest is a manifest served by a Registry.
+type Manifest struct {
+	MediaType string
+	Content   []byte
+	// Digest is the digest the manifest is served under.
+	Digest digest.Digest
+	// Blobs are the configuration and layers the manifest references, which are served with it.
+	Blobs map[digest.Digest][]byte
+	// Schema1, if set, is served instead of the manifest to clients that do not accept its media type, like
+	// registries convert newer manifests for older clients.
+	Schema1 *Manifest
+}
+
+type repository struct {
+	tags      map[string]digest.Digest
+	manifests map[digest.Digest]Manifest
+	blobs     map[digest.Digest][]byte
+}
+
+// Registry is a fake Docker v2 registry. The manifests and blobs it serves are added with AddManifest and AddBlob,
+// and the registry may require authentication or reject manifest requests as rate limited. Set the exported fields
+// before calling Start.
+type Registry struct {
+	// Auth is the authentication the registry requires.
+	Auth Auth
+	// Username and Password are the credentials accepted when Auth is set.
+	Username string
+	Password string
+	// RateLimit is the number of manifest requests answered with 429 Too Many Requests before manifests are served.
+	RateLimit int
+
+	server *httptest.Server
+
+	lock         sync.Mutex
+	repositories map[string]*repository
+	tokens       map[string]bool
+	limited      int
+	requests     []string
+}
+
+// NewRegistry returns a registry with no content that has not been started.
+func NewRegistry() *Registry {
+	return &Registry{
+		repositories: make(map[string]*repository),
+		tokens:       make(map[string]bool),
+	}
+}
+
+// Start serves the registry over TLS with a self signed certificate. Use Transport to connect to it.
+func (r *Registry) Start() {
+	r.server = httptest.NewTLSServer(http.HandlerFunc(r.serveHTTP))
+}
+
+// StartHTTP serves the registry over plain HTTP.
+func (r *Registry) StartHTTP() {
+	r.server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+}
+
+// Close stops the registry.
+func (r *Registry) Close() {
+	if r.server != nil {
+		r.server.Close()
+	}
+}
+
+// URL returns the URL of the started registry.
+func (r *Registry) URL() *url.URL {
+	u, _ := url.Parse(r.server.URL)
+	return u
+}
+
+// Host returns the host and port of the started registry, which is the registry portion of its pull specs.
+func (r *Registry) Host() string {
+	return r.URL().Host
+}
+
+// Transport returns a transport that trusts the certificate of the started registry.
+func (r *Registry) Transport() http.RoundTripper {
+	return r.server.Client().Transport
+}
+
+// Requests returns the method and path of each request the registry has received, in order.
+func (r *Registry) Requests() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.requests...)
+}
+
+// AddManifest serves m in the named repository by its digest and, if tag is not empty, by tag.
+func (r *Registry) AddManifest(name, tag string, m Manifest) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	repo := r.repository(name)
+	repo.manifests[m.Digest] = m
+	for d, content := range m.Blobs {
+		repo.blobs[d] = content
+	}
+	if len(tag) > 0 {
+		repo.tags[tag] = m.Digest
+	}
+}
+
+// AddBlob serves content as a blob in the named repository and returns its digest.
+func (r *Registry) AddBlob(name string, content []byte) digest.Digest {
+	d, _ := digest.FromBytes(content)
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.repository(name).blobs[d] = content
+	return d
+}
+
+func (r *Registry) repository(name string) *repository {
+	repo, ok := r.repositories[name]
+	if !ok {
+		repo = &repository{
+			tags:      make(map[string]digest.Digest),
+			manifests: make(map[digest.Digest]Manifest),
+			blobs:     make(map[digest.Digest][]byte),
+		}
+		r.repositories[name] = repo
+	}
+	return repo
+}
+
+func (r *Registry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.requests = append(r.requests, req.Method+" "+req.URL.Path)
+
+	if req.URL.Path == "/token" {
+		r.serveToken(w, req)
+		return
+	}
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if !strings.HasPrefix(req.URL.Path, "/v2/") {
+		writeError(w, http.StatusNotFound, "UNSUPPORTED", "the path is not part of the v2 API")
+		return
+	}
+	if !r.authorized(req) {
+		switch r.Auth {
+		case AuthBasic:
+			w.Header().Set("WWW-Authenticate", `Basic realm="fake"`)
+		case AuthToken:
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, r.server.URL))
+		}
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "authentication required")
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case len(path) == 0:
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprint(w, "{}")
+	case strings.HasSuffix(path, "/tags/list"):
+		r.serveTags(w, strings.TrimSuffix(path, "/tags/list"))
+	case strings.Contains(path, "/manifests/"):
+		i := strings.LastIndex(path, "/manifests/")
+		r.serveManifest(w, req, path[:i], path[i+len("/manifests/"):])
+	case strings.Contains(path, "/blobs/"):
+		i := strings.LastIndex(path, "/blobs/")
+		r.serveBlob(w, req, path[:i], path[i+len("/blobs/"):])
+	default:
+		writeError(w, http.StatusNotFound, "UNSUPPORTED", "the path is not part of the v2 API")
+	}
+}
+
+// authorized returns true if the request carries the credentials the registry requires.
+func (r *Registry) authorized(req *http.Request) bool {
+	switch r.Auth {
+	case AuthBasic:
+		username, password, ok := req.BasicAuth()
+		return ok && username == r.Username && password == r.Password
+	case AuthToken:
+		value := req.Header.Get("Authorization")
+		return strings.HasPrefix(value, "Bearer ") && r.tokens[strings.TrimPrefix(value, "Bearer ")]
+	}
+	return true
+}
+
+func (r *Registry) serveToken(w http.ResponseWriter, req *http.Request) {
+	// like public registries, anonymous clients are issued a token that grants no access
+	username, password, ok := req.BasicAuth()
+	if ok && (username != r.Username || password != r.Password) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	token := fmt.Sprintf("token-%d", len(r.tokens))
+	r.tokens[token] = ok
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"token": token})
+}
+
+func (r *Registry) serveTags(w http.ResponseWriter, name string) {
+	repo, ok := r.repositories[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", "repository name not known to registry")
+		return
+	}
+	tags := []string{}
+	for tag := range repo.tags {
+		tags = append(tags, tag)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"name": name, "tags": tags})
+}
+
+func (r *Registry) serveManifest(w http.ResponseWriter, req *http.Request, name, reference string) {
+	if r.limited < r.RateLimit {
+		r.limited++
+		w.Header().Set("Retry-After", "1")
+		writeError(w, http.StatusTooManyRequests, "TOOMANYREQUESTS", "too many requests")
+		return
+	}
+	repo, ok := r.repositories[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", "repository name not known to registry")
+		return
+	}
+	d, ok := repo.tags[reference]
+	if !ok {
+		d = digest.Digest(reference)
+	}
+	m, ok := repo.manifests[d]
+	if !ok {
+		writeError(w, http.StatusNotFound, "MANIFEST_UNKNOWN", "manifest unknown")
+		return
+	}
+	if m.Schema1 != nil && !accepts(req, m.MediaType) {
+		m = *m.Schema1
+	}
+	w.Header().Set("Content-Type", m.MediaType)
+	w.Header().Set("Docker-Content-Digest", m.Digest.String())
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(m.Content)))
+	w.WriteHeader(http.StatusOK)
+	if req.Method != "HEAD" {
+		w.Write(m.Content)
+	}
+}
+
+func (r *Registry) serveBlob(w http.ResponseWriter, req *http.Request, name, reference string) {
+	repo, ok := r.repositories[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NAME_UNKNOWN", "repository name not known to registry")
+		return
+	}
+	content, ok := repo.blobs[digest.Digest(reference)]
+	if !ok {
+		writeError(w, http.StatusNotFound, "BLOB_UNKNOWN", "blob unknown to registry")
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Docker-Content-Digest", reference)
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+	w.WriteHeader(http.StatusOK)
+	if req.Method != "HEAD" {
+		w.Write(content)
+	}
+}
+
+// accepts returns true if req lists mediaType in its Accept headers.
+func accepts(req *http.Request, mediaType string) bool {
+	for _, value := range req.Header["Accept"] {
+		for _, accepted := range strings.Split(value, ",") {
+			if i := strings.Index(accepted, ";"); i != -1 {
+				accepted = accepted[:i]
+			}
+			if strings.TrimSpace(accepted) == mediaType {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"errors":[{"code":%q,"message":%q}]}`, code, message)
+}
+
+// Schema1Manifest returns a signed schema1 manifest for the named image with a single layer.
+func Schema1Manifest(name, tag string) (Manifest, error) {
+	key, err := libtrust.GenerateECP256PrivateKey()
+	if err != nil {
+		return Manifest{}, err
+	}
+	layer, _ := digest.FromBytes([]byte("layer " + name + ":" + tag))
+	id := sha256.Sum256([]byte(name + ":" + tag))
+	sm, err := schema1.Sign(&schema1.Manifest{
+		Versioned:    schema1.SchemaVersion,
+		Name:         name,
+		Tag:          tag,
+		Architecture: "amd64",
+		FSLayers:     []schema1.FSLayer{{BlobSum: layer}},
+		History: []schema1.History{{
+			V1Compatibility: fmt.Sprintf(`{"id":%q,"created":"2016-01-01T00:00:00Z","architecture":"amd64","os":"linux","config":{"Env":["PATH=/bin"]},"Size":1024}`, hex.EncodeToString(id[:])),
+		}},
+	}, key)
+	if err != nil {
+		return Manifest{}, err
+	}
+	payload, err := sm.Payload()
+	if err != nil {
+		return Manifest{}, err
+	}
+	d, err := digest.FromBytes(payload)
+	if err != nil {
+		return Manifest{}, err
+	}
+	return Manifest{MediaType: MediaTypeSchema1, Content: sm.Raw, Digest: d}, nil
+}
+
+// descriptor references content from a schema2 or OCI manifest.
+type descriptor struct {
+	MediaType string        `json:"mediaType"`
+	Size      int64         `json:"size"`
+	Digest    digest.Digest `json:"digest"`
+}
+
+// Schema2Manifest returns a schema2 manifest for the named image with a single layer.
+func Schema2Manifest(name, tag string) Manifest {
+	return imageManifest(MediaTypeSchema2, name, tag)
+}
+
+// OCIManifest returns an OCI image manifest for the named image with a single layer.
+func OCIManifest(name, tag string) Manifest {
+	return imageManifest(MediaTypeOCIManifest, name, tag)
+}
+
+func imageManifest(mediaType, name, tag string) Manifest {
+	config := []byte(fmt.Sprintf(`{"architecture":"amd64","os":"linux","config":{"Labels":{"name":%q}}}`, name+":"+tag))
+	layer := []byte("layer " + name + ":" + tag)
+	configDigest, _ := digest.FromBytes(config)
+	layerDigest, _ := digest.FromBytes(layer)
+	m := marshalManifest(mediaType, struct {
+		manifest.Versioned
+		MediaType string       `json:"mediaType"`
+		Config    descriptor   `json:"config"`
+		Layers    []descriptor `json:"layers"`
+	}{
+		Versioned: manifest.Versioned{SchemaVersion: 2},
+		MediaType: mediaType,
+		Config:    descriptor{MediaType: MediaTypeImageConfig, Size: int64(len(config)), Digest: configDigest},
+		Layers:    []descriptor{{MediaType: MediaTypeLayer, Size: int64(len(layer)), Digest: layerDigest}},
+	})
+	m.Blobs = map[digest.Digest][]byte{configDigest: config, layerDigest: layer}
+	return m
+}
+
+// ManifestList returns a manifest list referencing each of manifests as a linux/amd64 image.
+func ManifestList(manifests ...Manifest) Manifest {
+	type platform struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	}
+	type entry struct {
+		descriptor
+		Platform platform `json:"platform"`
+	}
+	entries := []entry{}
+	for _, m := range manifests {
+		entries = append(entries, entry{
+			descriptor: descriptor{MediaType: m.MediaType, Size: int64(len(m.Content)), Digest: m.Digest},
+			Platform:   platform{Architecture: "amd64", OS: "linux"},
+		})
+	}
+	return marshalManifest(MediaTypeManifestList, struct {
+		manifest.Versioned
+		MediaType string  `json:"mediaType"`
+		Manifests []entry `json:"manifests"`
+	}{
+		Versioned: manifest.Versioned{SchemaVersion: 2},
+		MediaType: MediaTypeManifestList,
+		Manifests: entries,
+	})
+}
+
+func marshalManifest(mediaType string, obj interface{}) Manifest {
+	content, err := json.Marshal(obj)
+	if err != nil {
+		panic(err)
+	}
+	d, _ := digest.FromBytes(content)
+	return Manifest{MediaType: mediaType, Content: content, Digest: d}
+}
@@ -0,0 +1,128 @@
+package importer
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/docker/distribution/registry/client/auth"
+	"k8s.io/kubernetes/pkg/util"
+)
+
+// RegistryRateLimiters hands out a rate limiter for each registry host, so that the importers of many imports
+// can share the number of requests a registry receives. The zero value and nil do not limit requests.
+type RegistryRateLimiters struct {
+	qps   float32
+	burst int
+
+	lock     sync.Mutex
+	limiters map[string]util.RateLimiter
+}
+
+// NewRegistryRateLimiters creates limiters that allow requestsPerMinute requests to each registry, in bursts of
+// up to burst requests. A requestsPerMinute of zero or less does not limit requests.
+func NewRegistryRateLimiters(requestsPerMinute, burst int) *RegistryRateLimiters {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RegistryRateLimiters{
+		qps:   float32(requestsPerMinute) / float32(time.Minute/time.Second),
+		burst: burst,
+	}
+}
+
+// For returns the rate limiter of the registry host.
+func (r *RegistryRateLimiters) For(host string) util.RateLimiter {
+	if r == nil || r.qps <= 0 {
+		return util.NewFakeRateLimiter()
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	limiter, ok := r.limiters[host]
+	if !ok {
+		if r.limiters == nil {
+			r.limiters = make(map[string]util.RateLimiter)
+		}
+		limiter = util.NewTokenBucketRateLimiter(r.qps, r.burst)
+		r.limiters[host] = limiter
+	}
+	return limiter
+}
+
+// importLimits bounds the requests an import makes to registries, both in rate and in number at the same time.
+type importLimits struct {
+	// limiter is shared by all the requests of the importer.
+	limiter util.RateLimiter
+	// registries limits the requests to each registry, shared with other importers.
+	registries *RegistryRateLimiters
+	// slots holds a token for each request in progress, if requests are made concurrently.
+	slots chan struct{}
+}
+
+func newImportLimits(limiter util.RateLimiter, registries *RegistryRateLimiters, workers int) *importLimits {
+	if limiter == nil {
+		limiter = util.NewFakeRateLimiter()
+	}
+	limits := &importLimits{limiter: limiter, registries: registries}
+	if workers > 1 {
+		limits.slots = make(chan struct{}, workers)
+	}
+	return limits
+}
+
+// accept returns once a request to registry is allowed by the rate limiters.
+func (l *importLimits) accept(registry *url.URL) {
+	l.limiter.Accept()
+	l.registries.For(registry.Host).Accept()
+}
+
+// do calls fn once fewer requests than the number of workers are in progress. fn must not call do itself.
+func (l *importLimits) do(fn func()) {
+	if l.slots == nil {
+		fn()
+		return
+	}
+	l.slots <- struct{}{}
+	defer func() { <-l.slots }()
+	fn()
+}
+
+// each calls fn with every index below n and returns once all the calls have returned. The calls run
+// concurrently when the import has more than one worker, in which case they bound their requests with do.
+func (l *importLimits) each(n int, fn func(i int)) {
+	if l.slots == nil || n < 2 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// lockedChallengeManager serializes access to a challenge manager, since the challenges of a registry are
+// recorded while requests to other registries read theirs.
+type lockedChallengeManager struct {
+	lock    sync.RWMutex
+	manager auth.ChallengeManager
+}
+
+func (m *lockedChallengeManager) GetChallenges(endpoint string) ([]auth.Challenge, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return m.manager.GetChallenges(endpoint)
+}
+
+func (m *lockedChallengeManager) AddResponse(resp *http.Response) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.manager.AddResponse(resp)
+}
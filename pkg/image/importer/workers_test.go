@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	gocontext "golang.org/x/net/context"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/manifest/schema1"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+// concurrentRepository records the number of manifests retrieved at the same time.
+type concurrentRepository struct {
+	*mockRepository
+
+	lock             sync.Mutex
+	active, maximum  int
+	retrievedPerTags map[string]int
+}
+
+func (r *concurrentRepository) Manifests(ctx context.Context, options ...distribution.ManifestServiceOption) (distribution.ManifestService, error) {
+	return r, nil
+}
+
+func (r *concurrentRepository) GetByTag(tag string, options ...distribution.ManifestServiceOption) (*schema1.SignedManifest, error) {
+	r.lock.Lock()
+	r.active++
+	if r.active > r.maximum {
+		r.maximum = r.active
+	}
+	r.retrievedPerTags[tag]++
+	r.lock.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	r.lock.Lock()
+	r.active--
+	r.lock.Unlock()
+	return r.manifest, nil
+}
+
+// concurrentRetriever returns the repository of the mock retriever without recording the request.
+type concurrentRetriever struct {
+	*mockRetriever
+}
+
+func (r concurrentRetriever) Repository(ctx gocontext.Context, registry *url.URL, repoName string, insecure bool) (distribution.Repository, error) {
+	return r.repo, nil
+}
+
+func TestImportConcurrently(t *testing.T) {
+	m := &schema1.SignedManifest{Raw: []byte(etcdManifest)}
+	if err := json.Unmarshal([]byte(etcdManifest), m); err != nil {
+		t.Fatal(err)
+	}
+	tags := []string{}
+	for i := 0; i < 20; i++ {
+		tags = append(tags, fmt.Sprintf("v%d", i))
+	}
+	repo := &concurrentRepository{mockRepository: &mockRepository{manifest: m, tags: tags}, retrievedPerTags: make(map[string]int)}
+	isi := &api.ImageStreamImport{
+		Spec: api.ImageStreamImportSpec{
+			Repository: &api.RepositoryImportSpec{
+				From: kapi.ObjectReference{Kind: "DockerImage", Name: "registry.io/test"},
+			},
+			Images: []api.ImageImportSpec{
+				{From: kapi.ObjectReference{Kind: "DockerImage", Name: "registry.io/test:v1"}},
+				{From: kapi.ObjectReference{Kind: "DockerImage", Name: "registry.io/other:v1"}},
+			},
+		},
+	}
+	im := NewImageStreamImporter(concurrentRetriever{&mockRetriever{repo: repo}}, -1, nil).WithConcurrency(4, NewRegistryRateLimiters(-1, 0))
+	if err := im.Import(gocontext.Background(), isi); err != nil {
+		t.Fatal(err)
+	}
+
+	if repo.maximum < 2 || repo.maximum > 4 {
+		t.Errorf("expected between 2 and 4 manifests to be retrieved at the same time, got %d", repo.maximum)
+	}
+	for _, image := range isi.Status.Images {
+		if image.Status.Status != unversioned.StatusSuccess || image.Image == nil {
+			t.Errorf("unexpected image status: %#v", image.Status)
+		}
+	}
+	images := isi.Status.Repository.Images
+	// the images are reported in the order of the tags, whenever they were retrieved
+	expected := sets.NewString(tags...).List()
+	api.PrioritizeTags(expected)
+	if len(images) != len(expected) {
+		t.Fatalf("unexpected images: %#v", images)
+	}
+	for i, image := range images {
+		if image.Tag != expected[i] || image.Status.Status != unversioned.StatusSuccess {
+			t.Errorf("unexpected image %d: %s %#v", i, image.Tag, image.Status)
+		}
+	}
+	if len(repo.retrievedPerTags) != len(tags) {
+		t.Errorf("unexpected retrievals: %v", repo.retrievedPerTags)
+	}
+}
+
+func TestRegistryRateLimiters(t *testing.T) {
+	var unlimited *RegistryRateLimiters
+	if !unlimited.For("registry.io").TryAccept() {
+		t.Errorf("expected no limit")
+	}
+	limiters := NewRegistryRateLimiters(60, 2)
+	limiter := limiters.For("registry.io")
+	if limiters.For("registry.io") != limiter {
+		t.Errorf("expected the limiter of a registry to be shared")
+	}
+	if !limiter.TryAccept() || !limiter.TryAccept() || limiter.TryAccept() {
+		t.Errorf("expected a burst of 2 requests")
+	}
+	if !limiters.For("other.io").TryAccept() {
+		t.Errorf("expected the registries to be limited separately")
+	}
+}
@@ -0,0 +1,118 @@
+package prune
+
+import (
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+	"k8s.io/kubernetes/pkg/util"
+	kerrors "k8s.io/kubernetes/pkg/util/errors"
+	"k8s.io/kubernetes/pkg/util/sets"
+)
+
+// DefaultOrphanedImageBatchSize is the number of images deleted per batch when no batch size is specified.
+const DefaultOrphanedImageBatchSize = 50
+
+// OrphanedImagePrunerOptions controls how orphaned imported images are found and deleted.
+type OrphanedImagePrunerOptions struct {
+	// KeepYoungerThan protects images created more recently than this duration, which gives an import that
+	// has created an image but not yet updated the stream time to complete.
+	KeepYoungerThan time.Duration
+	// BatchSize is the number of images deleted before the pruner pauses for BatchInterval. Defaults to
+	// DefaultOrphanedImageBatchSize.
+	BatchSize int
+	// BatchInterval is the time to wait between batches.
+	BatchInterval time.Duration
+	// RateLimiter is optional and is consulted before each image is deleted.
+	RateLimiter util.RateLimiter
+	// Images is the set of images to consider for pruning.
+	Images *imageapi.ImageList
+	// Streams is the set of image streams whose tag history keeps images alive.
+	Streams *imageapi.ImageStreamList
+}
+
+// FindOrphanedImages returns the imported images that are not referenced by any tag event or spec tag in
+// streams and that are older than keepYoungerThan. Images managed by the integrated registry are never
+// returned, since removing them requires the registry to be pruned as well.
+func FindOrphanedImages(images *imageapi.ImageList, streams *imageapi.ImageStreamList, keepYoungerThan time.Duration, now time.Time) []*imageapi.Image {
+	referenced := sets.NewString()
+	for i := range streams.Items {
+		stream := &streams.Items[i]
+		for _, history := range stream.Status.Tags {
+			for _, event := range history.Items {
+				referenced.Insert(event.Image)
+			}
+		}
+		for _, tag := range stream.Spec.Tags {
+			if id := specTagImage(tag); len(id) > 0 {
+				referenced.Insert(id)
+			}
+		}
+	}
+
+	var orphans []*imageapi.Image
+	for i := range images.Items {
+		image := &images.Items[i]
+		if referenced.Has(image.Name) {
+			continue
+		}
+		if image.Annotations[imageapi.ManagedByOpenShiftAnnotation] == "true" {
+			continue
+		}
+		if now.Sub(image.CreationTimestamp.Time) < keepYoungerThan {
+			glog.V(5).Infof("Image %s is orphaned but too young to prune", image.Name)
+			continue
+		}
+		orphans = append(orphans, image)
+	}
+	return orphans
+}
+
+// PruneOrphanedImages finds the orphaned images described by options and deletes them with pruner in
+// batches. All errors are aggregated and returned.
+func PruneOrphanedImages(options OrphanedImagePrunerOptions, pruner ImagePruner) error {
+	batchSize := options.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultOrphanedImageBatchSize
+	}
+	limiter := options.RateLimiter
+	if limiter == nil {
+		limiter = util.NewFakeRateLimiter()
+	}
+
+	orphans := FindOrphanedImages(options.Images, options.Streams, options.KeepYoungerThan, time.Now())
+	glog.V(4).Infof("Found %d orphaned images", len(orphans))
+
+	errs := []error{}
+	for i, image := range orphans {
+		if i > 0 && i%batchSize == 0 && options.BatchInterval > 0 {
+			time.Sleep(options.BatchInterval)
+		}
+		limiter.Accept()
+		if err := pruner.PruneImage(image); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// specTagImage returns the name of the image a spec tag pins by digest, if any. A tag that points to an image
+// stream image or a Docker image by digest keeps that image alive even before it has been imported into the
+// status of the stream.
+func specTagImage(tag imageapi.TagReference) string {
+	if tag.From == nil {
+		return ""
+	}
+	switch tag.From.Kind {
+	case "ImageStreamImage":
+		if parts := strings.SplitN(tag.From.Name, "@", 2); len(parts) == 2 {
+			return parts[1]
+		}
+	case "DockerImage":
+		if ref, err := imageapi.ParseDockerImageReference(tag.From.Name); err == nil {
+			return ref.ID
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package prune
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+type fakeOrphanPruner struct {
+	pruned []string
+	err    error
+}
+
+func (p *fakeOrphanPruner) PruneImage(image *imageapi.Image) error {
+	p.pruned = append(p.pruned, image.Name)
+	return p.err
+}
+
+func importedImage(id, ref string, ageInMinutes int64) imageapi.Image {
+	image := agedImage(id, ref, ageInMinutes)
+	delete(image.Annotations, imageapi.ManagedByOpenShiftAnnotation)
+	return image
+}
+
+func TestFindOrphanedImages(t *testing.T) {
+	images := imageList(
+		importedImage("id1", "registry/foo/bar@id1", 120),
+		importedImage("id2", "registry/foo/bar@id2", 120),
+		importedImage("id3", "registry/foo/bar@id3", 5),
+		agedImage("id4", "registry/foo/bar@id4", 120),
+		importedImage("id5", "registry/foo/bar@id5", 120),
+		importedImage("sha256:0000000000000000000000000000000000000000000000000000000000000006", "registry/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000006", 120),
+	)
+	streams := streamList(
+		stream("registry", "foo", "bar", tags(
+			tag("latest", tagEvent("id1", "registry/foo/bar@id1")),
+		)),
+	)
+
+	streams.Items[0].Spec.Tags = map[string]imageapi.TagReference{
+		"pinned": {From: &kapi.ObjectReference{Kind: "ImageStreamImage", Name: "bar@id5"}},
+		"remote": {From: &kapi.ObjectReference{Kind: "DockerImage", Name: "registry/foo/bar@sha256:0000000000000000000000000000000000000000000000000000000000000006"}},
+		"tagged": {From: &kapi.ObjectReference{Kind: "ImageStreamTag", Name: "bar:latest"}},
+	}
+
+	orphans := FindOrphanedImages(&images, &streams, 60*time.Minute, time.Now())
+	names := sets.NewString()
+	for _, image := range orphans {
+		names.Insert(image.Name)
+	}
+	if e, a := []string{"id2"}, names.List(); !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+}
+
+func TestPruneOrphanedImages(t *testing.T) {
+	images := imageList(
+		importedImage("id1", "registry/foo/bar@id1", 120),
+		importedImage("id2", "registry/foo/bar@id2", 120),
+		importedImage("id3", "registry/foo/bar@id3", 120),
+	)
+	streams := streamList()
+
+	pruner := &fakeOrphanPruner{}
+	err := PruneOrphanedImages(OrphanedImagePrunerOptions{BatchSize: 2, Images: &images, Streams: &streams}, pruner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, a := []string{"id1", "id2", "id3"}, pruner.pruned; !reflect.DeepEqual(e, a) {
+		t.Errorf("expected %v, got %v", e, a)
+	}
+
+	pruner = &fakeOrphanPruner{err: errors.New("failed")}
+	if err := PruneOrphanedImages(OrphanedImagePrunerOptions{Images: &images, Streams: &streams}, pruner); err == nil {
+		t.Errorf("expected error")
+	}
+	if len(pruner.pruned) != 3 {
+		t.Errorf("expected all images to be attempted: %v", pruner.pruned)
+	}
+}
@@ -43,16 +43,22 @@ func (r *REST) Get(ctx kapi.Context, _ string, options runtime.Object) (runtime.
 	if err != nil {
 		return nil, err
 	}
-	filtered := make([]kapi.Secret, 0, len(secrets.Items))
-	for i := range secrets.Items {
-		if secrets.Items[i].Annotations[api.ExcludeImageSecretAnnotation] == "true" {
+	secrets.Items = PullSecrets(secrets.Items)
+	return secrets, nil
+}
+
+// PullSecrets returns the Docker configuration secrets of secrets that are not excluded from image imports by
+// the api.ExcludeImageSecretAnnotation annotation.
+func PullSecrets(secrets []kapi.Secret) []kapi.Secret {
+	filtered := make([]kapi.Secret, 0, len(secrets))
+	for i := range secrets {
+		if secrets[i].Annotations[api.ExcludeImageSecretAnnotation] == "true" {
 			continue
 		}
-		switch secrets.Items[i].Type {
+		switch secrets[i].Type {
 		case kapi.SecretTypeDockercfg, kapi.SecretTypeDockerConfigJson:
-			filtered = append(filtered, secrets.Items[i])
+			filtered = append(filtered, secrets[i])
 		}
 	}
-	secrets.Items = filtered
-	return secrets, nil
+	return filtered
 }
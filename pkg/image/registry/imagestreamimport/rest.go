@@ -1,10 +1,13 @@
 package imagestreamimport
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/docker/distribution/registry/client/auth"
 	"github.com/golang/glog"
 	gocontext "golang.org/x/net/context"
 
@@ -12,6 +15,7 @@ import (
 	kapierrors "k8s.io/kubernetes/pkg/api/errors"
 	"k8s.io/kubernetes/pkg/api/rest"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	kclient "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util"
 	"k8s.io/kubernetes/pkg/util/validation/field"
@@ -20,6 +24,7 @@ import (
 	"github.com/openshift/origin/pkg/dockerregistry"
 	"github.com/openshift/origin/pkg/image/api"
 	"github.com/openshift/origin/pkg/image/importer"
+	"github.com/openshift/origin/pkg/image/registry/imagesecret"
 	"github.com/openshift/origin/pkg/image/registry/imagestream"
 )
 
@@ -27,8 +32,8 @@ import (
 type ImporterFunc func(r importer.RepositoryRetriever) importer.Interface
 
 // ImporterDockerRegistryFunc returns an instance of a docker client that should be used per invocation of import,
-// may be nil if no legacy import capability is required.
-type ImporterDockerRegistryFunc func() dockerregistry.Client
+// sending credentials to the token realms of registries, may be nil if no legacy import capability is required.
+type ImporterDockerRegistryFunc func(credentials auth.CredentialStore) dockerregistry.Client
 
 // REST implements the RESTStorage interface for ImageStreamImport
 type REST struct {
@@ -39,26 +44,59 @@ type REST struct {
 	secrets           client.ImageStreamSecretsNamespacer
 	transport         http.RoundTripper
 	insecureTransport http.RoundTripper
-	clientFn          ImporterDockerRegistryFunc
+	// registryTransports tunes the connections made to the registries it lists
+	registryTransports dockerregistry.RegistryTransportOptions
+	clientFn           ImporterDockerRegistryFunc
+	defaultRegistry    imagestream.DefaultRegistry
+	mirrorer           importer.Mirrorer
+	// refreshTokens are shared by all imports, so that registries are not asked for a new refresh token with the
+	// credentials of secrets on every import.
+	refreshTokens *importer.RefreshTokens
+	// credentials resolves the credentials the legacy client uses for each namespace from its secrets, and are
+	// shared by all imports so that the secrets are not read on every import.
+	credentials *importer.ResolvedCredentials
 }
 
 // NewREST returns a REST storage implementation that handles importing images. The clientFn argument is optional
 // if v1 Docker Registry importing is not required. Insecure transport is optional, and both transports should not
-// include client certs unless you wish to allow the entire cluster to import using those certs.
+// include client certs unless you wish to allow the entire cluster to import using those certs. Registry transports
+// tune the connections made to individual registries. The legacy client reads the pull secrets of a namespace with
+// kubeSecrets, since it is not created for a single image stream. The mirrorer is optional and is used to copy images into
+// the default registry when an import requests mirroring.
 func NewREST(importFn ImporterFunc, streams imagestream.Registry, internalStreams rest.CreaterUpdater,
-	images rest.Creater, secrets client.ImageStreamSecretsNamespacer,
-	transport, insecureTransport http.RoundTripper,
+	images rest.Creater, secrets client.ImageStreamSecretsNamespacer, kubeSecrets kclient.SecretsNamespacer,
+	transport, insecureTransport http.RoundTripper, registryTransports dockerregistry.RegistryTransportOptions,
 	clientFn ImporterDockerRegistryFunc,
+	defaultRegistry imagestream.DefaultRegistry, mirrorer importer.Mirrorer,
 ) *REST {
 	return &REST{
-		importFn:          importFn,
-		streams:           streams,
-		internalStreams:   internalStreams,
-		images:            images,
-		secrets:           secrets,
-		transport:         transport,
-		insecureTransport: insecureTransport,
-		clientFn:          clientFn,
+		importFn:           importFn,
+		streams:            streams,
+		internalStreams:    internalStreams,
+		images:             images,
+		secrets:            secrets,
+		transport:          transport,
+		insecureTransport:  insecureTransport,
+		registryTransports: registryTransports,
+		clientFn:           clientFn,
+		defaultRegistry:    defaultRegistry,
+		mirrorer:           mirrorer,
+		refreshTokens:      importer.NewRefreshTokens(),
+		credentials:        importer.NewResolvedCredentials(namespaceSecretsResolver(kubeSecrets)),
+	}
+}
+
+// namespaceSecretsResolver returns a resolver of the credentials the pull secrets of a namespace hold for a
+// registry.
+func namespaceSecretsResolver(secrets kclient.SecretsNamespacer) importer.CredentialResolver {
+	return func(namespace string, url *url.URL) (string, string, error) {
+		list, err := secrets.Secrets(namespace).List(kapi.ListOptions{})
+		if err != nil {
+			return "", "", err
+		}
+		store := importer.NewCredentialsForSecrets(imagesecret.PullSecrets(list.Items))
+		username, password := store.Basic(url)
+		return username, password, store.Err()
 	}
 }
 
@@ -85,7 +123,7 @@ func (r *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 	}
 
 	if r.clientFn != nil {
-		if client := r.clientFn(); client != nil {
+		if client := r.clientFn(r.credentials.ForNamespace(namespace)); client != nil {
 			ctx = kapi.WithValue(ctx, importer.ContextKeyV1RegistryClient, client)
 		}
 	}
@@ -98,7 +136,10 @@ func (r *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 		}
 		return secrets.Items, nil
 	})
-	importCtx := importer.NewContext(r.transport, r.insecureTransport).WithCredentials(credentials)
+	credentials.OnAuthFailure(func(secret string, registry *url.URL, repository string, err error) {
+		glog.V(4).Infof("Registry %s rejected the credentials of secret %s/%s for %s: %v", registry.Host, namespace, secret, repository, err)
+	})
+	importCtx := importer.NewContext(r.transport, r.insecureTransport).WithRegistryTransportOptions(r.registryTransports).WithCredentials(importer.NewRefreshTokenCredentials(credentials, r.refreshTokens))
 	imports := r.importFn(importCtx)
 	if err := imports.Import(ctx.(gocontext.Context), isi); err != nil {
 		return nil, kapierrors.NewInternalError(err)
@@ -122,9 +163,10 @@ func (r *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 		}
 	}
 
-	// TODO: perform the transformation of the image stream and return it with the ISI if import is false
-	//   so that clients can see what the resulting object would look like.
-	if !isi.Spec.Import {
+	// a dry run performs the transformation of the image stream and returns it with the ISI so that
+	// clients can see what the resulting object would look like, without persisting anything.
+	dryRun := isi.Annotations[api.ImportDryRunAnnotation] == "true"
+	if !isi.Spec.Import && !dryRun {
 		clearManifests(isi)
 		return isi, nil
 	}
@@ -170,6 +212,10 @@ func (r *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 		return nil, err
 	}
 
+	if !dryRun && shouldMirror(isi, stream) {
+		r.mirrorImages(ctx.(gocontext.Context), importCtx, isi, namespace)
+	}
+
 	// walk the retrieved images, ensuring each one exists in etcd
 	importedImages := make(map[string]error)
 	updatedImages := make(map[string]*api.Image)
@@ -199,7 +245,7 @@ func (r *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 				continue
 			}
 
-			if updated, ok := r.importSuccessful(ctx, image, stream, tag, from.Exact(), nextGeneration, now, spec.ImportPolicy, importedImages, updatedImages); ok {
+			if updated, ok := r.importSuccessful(ctx, image, status.ImportSource, stream, tag, from.Exact(), nextGeneration, now, spec.ImportPolicy, importedImages, updatedImages, dryRun); ok {
 				isi.Status.Repository.Images[i].Image = updated
 			}
 		}
@@ -221,7 +267,7 @@ func (r *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 
 		// record success
 		image := status.Image
-		if updated, ok := r.importSuccessful(ctx, image, stream, tag, spec.From.Name, nextGeneration, now, spec.ImportPolicy, importedImages, updatedImages); ok {
+		if updated, ok := r.importSuccessful(ctx, image, status.ImportSource, stream, tag, spec.From.Name, nextGeneration, now, spec.ImportPolicy, importedImages, updatedImages, dryRun); ok {
 			isi.Status.Images[i].Image = updated
 		}
 	}
@@ -235,6 +281,19 @@ func (r *REST) Create(ctx kapi.Context, obj runtime.Object) (runtime.Object, err
 
 	clearManifests(isi)
 
+	if dryRun {
+		changes, err := json.Marshal(api.CompareImageStreamTags(original.(*api.ImageStream), stream))
+		if err != nil {
+			return nil, err
+		}
+		if isi.Annotations == nil {
+			isi.Annotations = make(map[string]string)
+		}
+		isi.Annotations[api.ImportDryRunChangesAnnotation] = string(changes)
+		isi.Status.Import = stream
+		return isi, nil
+	}
+
 	hasChanges := !kapi.Semantic.DeepEqual(original, stream)
 	if create {
 		stream.Annotations[api.DockerImageRepositoryCheckAnnotation] = now.UTC().Format(time.RFC3339)
@@ -313,11 +372,13 @@ func ensureSpecTag(stream *api.ImageStream, tag, from string, importPolicy api.T
 // importSuccessful records a successful import into an image stream, setting the spec tag, status tag or conditions, and ensuring
 // the image is created in etcd. Images are cached so they are not created multiple times in a row (when multiple tags point to the
 // same image), and a failure to persist the image will be summarized before we update the stream. If an image was imported by this
-// operation, it *replaces* the imported image (from the remote repository) with the updated image.
+// operation, it *replaces* the imported image (from the remote repository) with the updated image. The source of the import is
+// recorded on the tag event, which is left unchanged if the tag already points to the image. If dryRun is true the image is not
+// persisted.
 func (r *REST) importSuccessful(
 	ctx kapi.Context,
-	image *api.Image, stream *api.ImageStream, tag string, from string, nextGeneration int64, now unversioned.Time, importPolicy api.TagImportPolicy,
-	importedImages map[string]error, updatedImages map[string]*api.Image,
+	image *api.Image, source *api.TagImportSource, stream *api.ImageStream, tag string, from string, nextGeneration int64, now unversioned.Time, importPolicy api.TagImportPolicy,
+	importedImages map[string]error, updatedImages map[string]*api.Image, dryRun bool,
 ) (*api.Image, bool) {
 
 	pullSpec, _ := api.MostAccuratePullSpec(image.DockerImageReference, image.Name, "")
@@ -326,6 +387,7 @@ func (r *REST) importSuccessful(
 		DockerImageReference: pullSpec,
 		Image:                image.Name,
 		Generation:           nextGeneration,
+		ImportSource:         source,
 	}
 
 	if stream.Spec.Tags == nil {
@@ -359,6 +421,12 @@ func (r *REST) importSuccessful(
 		return nil, false
 	}
 
+	if dryRun {
+		updatedImages[image.Name] = image
+		importedImages[image.Name] = nil
+		return image, true
+	}
+
 	updated, err := r.images.Create(ctx, image)
 	switch {
 	case kapierrors.IsAlreadyExists(err):
@@ -379,6 +447,55 @@ func (r *REST) importSuccessful(
 	return nil, false
 }
 
+// shouldMirror returns true if the import or the stream request that images be mirrored.
+func shouldMirror(isi *api.ImageStreamImport, stream *api.ImageStream) bool {
+	return isi.Annotations[api.MirrorImportAnnotation] == "true" || stream.Annotations[api.MirrorImportAnnotation] == "true"
+}
+
+// mirrorImages copies every successfully imported image into the default registry under the namespace and name
+// of the import, replacing the imported image with the mirrored copy. Images that cannot be mirrored are marked
+// as failed so that the tags referencing them are not updated.
+func (r *REST) mirrorImages(ctx gocontext.Context, retriever importer.RepositoryRetriever, isi *api.ImageStreamImport, namespace string) {
+	if r.mirrorer == nil || r.defaultRegistry == nil {
+		glog.V(4).Infof("Image mirroring requested for %s/%s but is not enabled on this server", namespace, isi.Name)
+		return
+	}
+	registry, ok := r.defaultRegistry.DefaultRegistry()
+	if !ok {
+		glog.V(4).Infof("Image mirroring requested for %s/%s but the default registry is not available", namespace, isi.Name)
+		return
+	}
+	to := api.DockerImageReference{Registry: registry, Namespace: namespace, Name: isi.Name}
+
+	mirrored := make(map[string]*api.Image)
+	mirror := func(status *api.ImageImportStatus, insecure bool) {
+		if status.Image == nil || status.Status.Status != unversioned.StatusSuccess {
+			return
+		}
+		image, ok := mirrored[status.Image.Name]
+		if !ok {
+			var err error
+			image, err = r.mirrorer.Mirror(ctx, retriever, status.Image, insecure, to)
+			if err != nil {
+				glog.V(4).Infof("Unable to mirror image %s: %v", status.Image.DockerImageReference, err)
+				status.Status = kapierrors.NewInternalError(fmt.Errorf("unable to mirror image to %s: %v", to.Exact(), err)).(kapierrors.APIStatus).Status()
+				return
+			}
+			mirrored[status.Image.Name] = image
+		}
+		status.Image = image
+	}
+
+	for i := range isi.Status.Images {
+		mirror(&isi.Status.Images[i], isi.Spec.Images[i].ImportPolicy.Insecure)
+	}
+	if spec := isi.Spec.Repository; spec != nil && isi.Status.Repository != nil {
+		for i := range isi.Status.Repository.Images {
+			mirror(&isi.Status.Repository.Images[i], spec.ImportPolicy.Insecure)
+		}
+	}
+}
+
 // clearManifests unsets the manifest for each object that does not request it
 func clearManifests(isi *api.ImageStreamImport) {
 	for i := range isi.Status.Images {
@@ -0,0 +1,65 @@
+package imagestreamimport
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/testapi"
+	kclient "k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	_ "github.com/openshift/origin/pkg/api/install"
+	"github.com/openshift/origin/pkg/image/api"
+)
+
+func dockercfgSecret(name, host, username string) kapi.Secret {
+	auth := base64.StdEncoding.EncodeToString([]byte(username + ":password"))
+	return kapi.Secret{
+		ObjectMeta: kapi.ObjectMeta{Name: name, Namespace: "test"},
+		Type:       kapi.SecretTypeDockercfg,
+		Data: map[string][]byte{
+			kapi.DockerConfigKey: []byte(fmt.Sprintf(`{%q:{"auth":%q,"email":"test@example.com"}}`, host, auth)),
+		},
+	}
+}
+
+func TestNamespaceSecretsResolver(t *testing.T) {
+	excluded := dockercfgSecret("excluded", "excluded.io", "excluded")
+	excluded.Annotations = map[string]string{api.ExcludeImageSecretAnnotation: "true"}
+	list := &kapi.SecretList{Items: []kapi.Secret{dockercfgSecret("pull", "registry.io", "user"), excluded}}
+	body, err := runtime.Encode(testapi.Default.Codec(), list)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+	client, err := kclient.New(&kclient.Config{Host: server.URL, GroupVersion: testapi.Default.GroupVersion()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolve := namespaceSecretsResolver(client)
+	username, password, err := resolve("test", &url.URL{Host: "registry.io"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if username != "user" || password != "password" {
+		t.Errorf("unexpected credentials: %s %s", username, password)
+	}
+	if len(paths) != 1 || paths[0] != testapi.Default.ResourcePath("secrets", "test", "") {
+		t.Errorf("unexpected requests: %v", paths)
+	}
+	if username, _, err := resolve("test", &url.URL{Host: "excluded.io"}); err != nil || len(username) > 0 {
+		t.Errorf("expected the excluded secret to be ignored: %s %v", username, err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package trigger provides helpers for encoding image triggers on arbitrary object fields as an annotation.
+package trigger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// TriggerAnnotationKey is the annotation on an object that lists the image triggers of that object as a JSON
+// serialized []ObjectFieldTrigger.
+const TriggerAnnotationKey = "image.openshift.io/triggers"
+
+// ObjectReference identifies the image that triggers a change.
+type ObjectReference struct {
+	// Kind is the kind of the referenced object, usually ImageStreamTag.
+	Kind string `json:"kind"`
+	// Name is the name of the referenced object.
+	Name string `json:"name"`
+	// Namespace is the namespace of the referenced object. If empty, the namespace of the annotated object.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ObjectFieldTrigger updates a field of the annotated object when the referenced image changes.
+type ObjectFieldTrigger struct {
+	// From is the image that triggers the change.
+	From ObjectReference `json:"from"`
+	// FieldPath is a JSONPath expression selecting the field of the object that is set to the image.
+	FieldPath string `json:"fieldPath"`
+	// Paused is true if the field should not be updated automatically.
+	Paused bool `json:"paused,omitempty"`
+}
+
+// ContainerImageFieldPath returns the field path of the image of the named container in an object with a
+// pod template.
+func ContainerImageFieldPath(container string) string {
+	return fmt.Sprintf("spec.template.spec.containers[?(@.name==%q)].image", container)
+}
+
+// Encode serializes triggers into the value of TriggerAnnotationKey.
+func Encode(triggers []ObjectFieldTrigger) (string, error) {
+	data, err := json.Marshal(triggers)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Decode parses the value of TriggerAnnotationKey.
+func Decode(value string) ([]ObjectFieldTrigger, error) {
+	var triggers []ObjectFieldTrigger
+	if err := json.Unmarshal([]byte(value), &triggers); err != nil {
+		return nil, fmt.Errorf("the %s annotation is invalid: %v", TriggerAnnotationKey, err)
+	}
+	return triggers, nil
+}
+
+// Set encodes triggers onto the annotations of meta, removing the annotation if there are no triggers.
+func Set(meta *kapi.ObjectMeta, triggers []ObjectFieldTrigger) error {
+	if len(triggers) == 0 {
+		delete(meta.Annotations, TriggerAnnotationKey)
+		return nil
+	}
+	value, err := Encode(triggers)
+	if err != nil {
+		return err
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[TriggerAnnotationKey] = value
+	return nil
+}
@@ -0,0 +1,35 @@
+package trigger
+
+import (
+	"reflect"
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+func TestSetAndDecode(t *testing.T) {
+	triggers := []ObjectFieldTrigger{{
+		From:      ObjectReference{Kind: "ImageStreamTag", Name: "web:latest", Namespace: "other"},
+		FieldPath: ContainerImageFieldPath("web"),
+	}}
+	meta := &kapi.ObjectMeta{}
+	if err := Set(meta, triggers); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := Decode(meta.Annotations[TriggerAnnotationKey])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(triggers, decoded) {
+		t.Errorf("unexpected triggers: %#v", decoded)
+	}
+	if err := Set(meta, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := meta.Annotations[TriggerAnnotationKey]; ok {
+		t.Errorf("expected the annotation to be removed")
+	}
+	if _, err := Decode("{"); err == nil {
+		t.Errorf("expected an error")
+	}
+}
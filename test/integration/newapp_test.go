@@ -33,7 +33,9 @@ import (
 	"github.com/openshift/origin/pkg/dockerregistry"
 	"github.com/openshift/origin/pkg/generate/app"
 	"github.com/openshift/origin/pkg/generate/app/cmd"
+	"github.com/openshift/origin/pkg/generate/app/testsearcher"
 	"github.com/openshift/origin/pkg/generate/dockerfile"
+	generrors "github.com/openshift/origin/pkg/generate/errors"
 	"github.com/openshift/origin/pkg/generate/source"
 	imageapi "github.com/openshift/origin/pkg/image/api"
 	templateapi "github.com/openshift/origin/pkg/template/api"
@@ -245,27 +247,6 @@ func mapContains(a, b map[string]string) bool {
 	return true
 }
 
-// ExactMatchDockerSearcher returns a match with the value that was passed in
-// and a march score of 0.0(exact)
-type ExactMatchDockerSearcher struct {
-	Errs []error
-}
-
-// Search always returns a match for every term passed in
-func (r *ExactMatchDockerSearcher) Search(precise bool, terms ...string) (app.ComponentMatches, []error) {
-	matches := app.ComponentMatches{}
-	for _, value := range terms {
-		matches = append(matches, &app.ComponentMatch{
-			Value:       value,
-			Name:        value,
-			Argument:    fmt.Sprintf("--docker-image=%q", value),
-			Description: fmt.Sprintf("Docker image %q", value),
-			Score:       0.0,
-		})
-	}
-	return matches, r.Errs
-}
-
 func TestNewAppRunAll(t *testing.T) {
 	skipExternalGit(t)
 	dockerSearcher := app.DockerRegistrySearcher{
@@ -435,7 +416,7 @@ func TestNewAppRunAll(t *testing.T) {
 						Image:  dockerBuilderImage(),
 					},
 					Insecure:         true,
-					RegistrySearcher: &ExactMatchDockerSearcher{},
+					RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{},
 				},
 				ImageStreamSearcher: app.ImageStreamSearcher{
 					Client:            &client.Fake{},
@@ -517,7 +498,7 @@ func TestNewAppRunAll(t *testing.T) {
 						Image:  dockerBuilderImage(),
 					},
 					Insecure:         true,
-					RegistrySearcher: &ExactMatchDockerSearcher{},
+					RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{},
 				},
 				ImageStreamSearcher: app.ImageStreamSearcher{
 					Client:            &client.Fake{},
@@ -602,7 +583,7 @@ func TestNewAppRunAll(t *testing.T) {
 							},
 						},
 					},
-					RegistrySearcher: &ExactMatchDockerSearcher{},
+					RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{},
 				},
 				ImageStreamSearcher: app.ImageStreamSearcher{
 					Client:            &client.Fake{},
@@ -632,7 +613,7 @@ func TestNewAppRunAll(t *testing.T) {
 			config: &cmd.AppConfig{
 				DockerImages: []string{"mysql"},
 				DockerSearcher: app.DockerClientSearcher{
-					RegistrySearcher: &ExactMatchDockerSearcher{Errs: []error{errors.NewInternalError(fmt.Errorf("test error"))}},
+					RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{Errs: []error{errors.NewInternalError(fmt.Errorf("test error"))}},
 				},
 				ImageStreamSearcher: app.ImageStreamSearcher{
 					Client: client.NewSimpleFake(&unversioned.Status{
@@ -821,13 +802,29 @@ func TestNewAppRunBuilds(t *testing.T) {
 				SourceRepositories: []string{"https://github.com/openshift/ruby-hello-world"},
 				DockerImages:       []string{"centos/ruby-22-centos7", "centos/mongodb-26-centos7"},
 				OutputDocker:       true,
+				NonBuilderPolicy:   cmd.NonBuilderAsImage,
 			},
 			expected: map[string][]string{
-				// TODO: this test used to silently ignore components that were not builders (i.e. user input)
-				//   That's bad, so the code should either error in this case or be a bit smarter.
-				"buildConfig": {"ruby-hello-world", "ruby-hello-world-1"},
+				"buildConfig": {"ruby-hello-world"},
 				"imageStream": {"mongodb-26-centos7", "ruby-22-centos7"},
 			},
+			checkResult: func(res *cmd.AppResult) error {
+				if len(res.NonBuilderWarnings) != 1 || res.NonBuilderWarnings[0].Image != "centos/mongodb-26-centos7" {
+					return fmt.Errorf("unexpected non-builder warnings: %v", res.NonBuilderWarnings)
+				}
+				return nil
+			},
+		},
+		{
+			name: "non-builder image with source",
+			config: &cmd.AppConfig{
+				SourceRepositories: []string{"https://github.com/openshift/ruby-hello-world"},
+				DockerImages:       []string{"centos/ruby-22-centos7", "centos/mongodb-26-centos7"},
+				OutputDocker:       true,
+			},
+			expectedErr: func(err error) bool {
+				return generrors.CodeOf(err) == generrors.CodeNotABuilder
+			},
 		},
 		{
 			name: "successful build from dockerfile",
@@ -1468,7 +1465,7 @@ func fakeDockerSearcher() app.Searcher {
 			Image:  dockerBuilderImage(),
 		},
 		Insecure:         true,
-		RegistrySearcher: &ExactMatchDockerSearcher{},
+		RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{},
 	}
 }
 
@@ -1483,7 +1480,7 @@ func fakeSimpleDockerSearcher() app.Searcher {
 				},
 			},
 		},
-		RegistrySearcher: &ExactMatchDockerSearcher{},
+		RegistrySearcher: &testsearcher.ExactMatchDockerSearcher{},
 	}
 }
 